@@ -0,0 +1,207 @@
+package capacity
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/internal/app/capacity"
+	"github.com/romberli/das/pkg/message"
+	msgcap "github.com/romberli/das/pkg/message/capacity"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	mysqlServerIDJSON = "mysql_server_id"
+	dbIDJSON          = "db_id"
+	appIDJSON         = "app_id"
+	startTimeJSON     = "start_time"
+	endTimeJSON       = "end_time"
+)
+
+// @Tags capacity
+// @Summary forecast the days until each mount point of the mysql server is full, with the 95% confidence bounds, and the tables which grow fastest
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"mysql_server_id": 1, "disk_forecasts": [{"mount_point": "/data", "sample_num": 720, "seasonal": true, "current_usage": 0.62, "growth_per_day": 0.004, "days_to_full": 95.2, "days_to_full_lower": 80.1, "days_to_full_upper": 117.3, "message": ""}], "table_growths": [{"db_name": "db1", "table_name": "t01", "table_rows": 1000000, "table_size": 536870912, "rows_per_day": 12000.5, "bytes_per_day": 6442450.9}]}}"
+// @Router /api/v1/capacity/forecast/:mysql_server_id [get]
+func GetForecastByMySQLServerID(c *gin.Context) {
+	// get data
+	mysqlServerIDStr := c.Param(mysqlServerIDJSON)
+	if mysqlServerIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, mysqlServerIDJSON)
+		return
+	}
+	mysqlServerID, err := strconv.Atoi(mysqlServerIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := capacity.NewServiceWithDefault()
+	// forecast
+	err = s.ForecastByMySQLServerID(mysqlServerID)
+	if err != nil {
+		resp.ResponseNOK(c, msgcap.ErrCapacityForecastByMySQLServerID, mysqlServerID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgcap.DebugCapacityForecastByMySQLServerID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgcap.InfoCapacityForecastByMySQLServerID, mysqlServerID)
+}
+
+// @Tags capacity
+// @Summary get growth of every table of the mysql server in the time range, the tables are sorted by the growth in bytes descending
+// @Accept	application/json
+// @Param	body body string true "mysql server id and time range" default({"mysql_server_id": "1", "start_time": "2021-01-01 00:00:00", "end_time": "2021-02-01 00:00:00"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": [{"db_name": "db1", "table_name": "t01", "table_rows": 1000000, "table_size": 536870912, "rows_per_day": 12000.5, "bytes_per_day": 6442450.9}]}"
+// @Router /api/v1/capacity/growth/table [post]
+func GetTableGrowthsByMySQLServerID(c *gin.Context) {
+	// get data
+	mysqlServerID, startTime, endTime, ok := getIDAndTimeRange(c, mysqlServerIDJSON)
+	if !ok {
+		return
+	}
+	// init service
+	s := capacity.NewServiceWithDefault()
+	// get entities
+	err := s.GetTableGrowthsByMySQLServerID(mysqlServerID, startTime, endTime)
+	if err != nil {
+		resp.ResponseNOK(c, msgcap.ErrCapacityGetTableGrowthsByMySQLServerID, mysqlServerID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalTableGrowthsJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgcap.DebugCapacityGetTableGrowthsByMySQLServerID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgcap.InfoCapacityGetTableGrowthsByMySQLServerID, mysqlServerID)
+}
+
+// @Tags capacity
+// @Summary get growth of the db in the time range, the size of the db is the sum of the sizes of its tables, only the db which is deployed on a single mysql cluster is supported
+// @Accept	application/json
+// @Param	body body string true "db id and time range" default({"db_id": "1", "start_time": "2021-01-01 00:00:00", "end_time": "2021-02-01 00:00:00"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"db_id": 1, "db_name": "db1", "mysql_server_id": 1, "table_rows": 1000000, "table_size": 536870912, "rows_per_day": 12000.5, "bytes_per_day": 6442450.9, "table_growths": [{"db_name": "db1", "table_name": "t01", "table_rows": 1000000, "table_size": 536870912, "rows_per_day": 12000.5, "bytes_per_day": 6442450.9}]}}"
+// @Router /api/v1/capacity/growth/db [post]
+func GetDBGrowthByID(c *gin.Context) {
+	// get data
+	dbID, startTime, endTime, ok := getIDAndTimeRange(c, dbIDJSON)
+	if !ok {
+		return
+	}
+	// init service
+	s := capacity.NewServiceWithDefault()
+	// get entities
+	err := s.GetDBGrowthByID(dbID, startTime, endTime)
+	if err != nil {
+		resp.ResponseNOK(c, msgcap.ErrCapacityGetDBGrowthByID, dbID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalDBGrowthJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgcap.DebugCapacityGetDBGrowthByID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgcap.InfoCapacityGetDBGrowthByID, dbID)
+}
+
+// @Tags capacity
+// @Summary get growth of the app in the time range, it is the sum of the growth of the dbs of the app
+// @Accept	application/json
+// @Param	body body string true "app id and time range" default({"app_id": "1", "start_time": "2021-01-01 00:00:00", "end_time": "2021-02-01 00:00:00"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"app_id": 1, "app_name": "app1", "table_rows": 1000000, "table_size": 536870912, "rows_per_day": 12000.5, "bytes_per_day": 6442450.9, "db_growths": [{"db_id": 1, "db_name": "db1", "mysql_server_id": 1, "table_rows": 1000000, "table_size": 536870912, "rows_per_day": 12000.5, "bytes_per_day": 6442450.9, "table_growths": []}]}}"
+// @Router /api/v1/capacity/growth/app [post]
+func GetAppGrowthByID(c *gin.Context) {
+	// get data
+	appID, startTime, endTime, ok := getIDAndTimeRange(c, appIDJSON)
+	if !ok {
+		return
+	}
+	// init service
+	s := capacity.NewServiceWithDefault()
+	// get entities
+	err := s.GetAppGrowthByID(appID, startTime, endTime)
+	if err != nil {
+		resp.ResponseNOK(c, msgcap.ErrCapacityGetAppGrowthByID, appID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalAppGrowthJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgcap.DebugCapacityGetAppGrowthByID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msgcap.InfoCapacityGetAppGrowthByID, appID)
+}
+
+// getIDAndTimeRange gets the identity of given json field and the time range from the request body,
+// it responses the error and returns false if any of them is not valid
+func getIDAndTimeRange(c *gin.Context, idJSON string) (int, time.Time, time.Time, bool) {
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+	dataMap := make(map[string]string)
+	err = json.Unmarshal(data, &dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+	idStr, idExists := dataMap[idJSON]
+	if !idExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, idJSON)
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+	startTimeStr, startTimeExists := dataMap[startTimeJSON]
+	if !startTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, startTimeJSON)
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, startTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, startTimeStr)
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+	endTimeStr, endTimeExists := dataMap[endTimeJSON]
+	if !endTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, endTimeJSON)
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, endTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, endTimeStr)
+		return constant.ZeroInt, time.Time{}, time.Time{}, false
+	}
+
+	return id, startTime, endTime, true
+}
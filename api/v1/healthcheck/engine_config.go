@@ -0,0 +1,273 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghealth "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	configVersionJSON          = "config_version"
+	engineConfigOverrideIDJSON = "id"
+)
+
+// engineConfigOverrideRequest is the request body of saving the engine config override,
+// the config is a json object of the overridden fields of the item config
+type engineConfigOverrideRequest struct {
+	ScopeType int             `json:"scope_type"`
+	ScopeID   int             `json:"scope_id"`
+	ItemName  string          `json:"item_name"`
+	Config    json.RawMessage `json:"config"`
+}
+
+// @Tags healthcheck
+// @Summary get the latest healthcheck engine config
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"version": 2, "item_configs": [{"id": 14, "config_version": 2, "item_name": "db_config", "item_weight": 5, "low_watermark": 0, "high_watermark": 0, "unit": 0, "score_deduction_per_unit_high": 10, "max_score_deduction_high": 50, "score_deduction_per_unit_medium": 0, "max_score_deduction_medium": 0, "scoring_strategy": "mean", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/engine-config [get]
+func GetEngineConfig(c *gin.Context) {
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// get entities
+	err := s.GetLatest()
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetEngineConfig, err.Error())
+		return
+	}
+	respEngineConfig(c, s, msghealth.DebugHealthcheckGetEngineConfig, msghealth.InfoHealthcheckGetEngineConfig)
+}
+
+// @Tags healthcheck
+// @Summary get the healthcheck engine config of given version
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"version": 1, "item_configs": [{"id": 1, "config_version": 1, "item_name": "db_config", "item_weight": 5, "low_watermark": 0, "high_watermark": 0, "unit": 0, "score_deduction_per_unit_high": 10, "max_score_deduction_high": 50, "score_deduction_per_unit_medium": 0, "max_score_deduction_medium": 0, "scoring_strategy": "mean", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/engine-config/version/:config_version [get]
+func GetEngineConfigByVersion(c *gin.Context) {
+	// get params
+	versionStr := c.Param(configVersionJSON)
+	if versionStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, configVersionJSON)
+		return
+	}
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// get entities
+	err = s.GetByVersion(version)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetEngineConfig, err.Error())
+		return
+	}
+	respEngineConfig(c, s, msghealth.DebugHealthcheckGetEngineConfig, msghealth.InfoHealthcheckGetEngineConfig)
+}
+
+// @Tags healthcheck
+// @Summary add the healthcheck engine config items, a new engine config version will be created, the weights of all the items must sum to 100
+// @Accept	application/json
+// @Param	body body string true "engine config change" default({"items": [{"item_name": "index_health", "item_weight": 5, "low_watermark": 0, "high_watermark": 0, "unit": 1, "score_deduction_per_unit_high": 10, "max_score_deduction_high": 50, "score_deduction_per_unit_medium": 2, "max_score_deduction_medium": 30, "scoring_strategy": "mean"}]})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"version": 3, "item_configs": [{"id": 28, "config_version": 3, "item_name": "index_health", "item_weight": 5}]}}"
+// @Router /api/v1/healthcheck/engine-config/item [post]
+func AddEngineConfigItems(c *gin.Context) {
+	// get data
+	change, ok := getEngineConfigChange(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// insert into middleware
+	err := s.AddItems(change.GetItemConfigs())
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckAddEngineConfigItems, err.Error())
+		return
+	}
+	respEngineConfig(c, s, msghealth.DebugHealthcheckAddEngineConfigItems, msghealth.InfoHealthcheckAddEngineConfigItems)
+}
+
+// @Tags healthcheck
+// @Summary update the healthcheck engine config items, a new engine config version will be created, the weights of all the items must sum to 100
+// @Accept	application/json
+// @Param	body body string true "engine config change" default({"items": [{"item_name": "cpu_usage", "item_weight": 5, "low_watermark": 50, "high_watermark": 70, "unit": 10, "score_deduction_per_unit_high": 20, "max_score_deduction_high": 100, "score_deduction_per_unit_medium": 10, "max_score_deduction_medium": 50, "scoring_strategy": "p95"}]})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"version": 3, "item_configs": [{"id": 28, "config_version": 3, "item_name": "cpu_usage", "item_weight": 5, "scoring_strategy": "p95"}]}}"
+// @Router /api/v1/healthcheck/engine-config/item/update [post]
+func UpdateEngineConfigItems(c *gin.Context) {
+	// get data
+	change, ok := getEngineConfigChange(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// insert into middleware
+	err := s.UpdateItems(change.GetItemConfigs())
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckUpdateEngineConfigItems, err.Error())
+		return
+	}
+	respEngineConfig(c, s, msghealth.DebugHealthcheckUpdateEngineConfigItems, msghealth.InfoHealthcheckUpdateEngineConfigItems)
+}
+
+// @Tags healthcheck
+// @Summary delete the healthcheck engine config items, a new engine config version will be created, the items are updated in the same version to rebalance the weights
+// @Accept	application/json
+// @Param	body body string true "engine config change" default({"item_names": ["index_health"], "items": [{"item_name": "cpu_usage", "item_weight": 10, "low_watermark": 50, "high_watermark": 70, "unit": 10, "score_deduction_per_unit_high": 20, "max_score_deduction_high": 100, "score_deduction_per_unit_medium": 10, "max_score_deduction_medium": 50, "scoring_strategy": "mean"}]})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"version": 4, "item_configs": [{"id": 41, "config_version": 4, "item_name": "cpu_usage", "item_weight": 10}]}}"
+// @Router /api/v1/healthcheck/engine-config/item/delete [post]
+func DeleteEngineConfigItems(c *gin.Context) {
+	// get data
+	change, ok := getEngineConfigChange(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// insert into middleware
+	err := s.DeleteItems(change.GetItemNames(), change.GetItemConfigs())
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckDeleteEngineConfigItems, err.Error())
+		return
+	}
+	respEngineConfig(c, s, msghealth.DebugHealthcheckDeleteEngineConfigItems, msghealth.InfoHealthcheckDeleteEngineConfigItems)
+}
+
+// @Tags healthcheck
+// @Summary get all the healthcheck engine config overrides, the scope type is 1(env), 2(app level) or 3(mysql cluster)
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"overrides": [{"id": 1, "scope_type": 3, "scope_id": 1, "item_name": "cpu_usage", "config": "{\"low_watermark\": 60, \"high_watermark\": 80}", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/engine-config/override [get]
+func GetEngineConfigOverrides(c *gin.Context) {
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// get entities
+	err := s.GetAllOverrides()
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetEngineConfigOverrides, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalOverrides()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetEngineConfigOverrides, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetEngineConfigOverrides)
+}
+
+// @Tags healthcheck
+// @Summary save the healthcheck engine config override, the existing override of the same scope and item will be replaced, the item weight could not be overridden
+// @Accept	application/json
+// @Param	body body string true "engine config override" default({"scope_type": 3, "scope_id": 1, "item_name": "cpu_usage", "config": {"low_watermark": 60, "high_watermark": 80}})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"overrides": [{"id": 1, "scope_type": 3, "scope_id": 1, "item_name": "cpu_usage", "config": "{\"low_watermark\": 60, \"high_watermark\": 80}", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/engine-config/override [post]
+func SaveEngineConfigOverride(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	req := &engineConfigOverrideRequest{}
+	err = json.Unmarshal(data, req)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// save entity
+	err = s.SaveOverride(req.ScopeType, req.ScopeID, req.ItemName, string(req.Config))
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckSaveEngineConfigOverride, req.ScopeType, req.ScopeID, req.ItemName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalOverrides()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckSaveEngineConfigOverride, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckSaveEngineConfigOverride, req.ScopeType, req.ScopeID, req.ItemName)
+}
+
+// @Tags healthcheck
+// @Summary delete the healthcheck engine config override by id
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "engine config override deleted"}"
+// @Router /api/v1/healthcheck/engine-config/override/delete/:id [post]
+func DeleteEngineConfigOverride(c *gin.Context) {
+	// get params
+	idStr := c.Param(engineConfigOverrideIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, engineConfigOverrideIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewEngineConfigServiceWithDefault()
+	// delete entity
+	err = s.DeleteOverride(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckDeleteEngineConfigOverride, id, err.Error())
+		return
+	}
+	// response
+	respMessage := "engine config override deleted"
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckDeleteEngineConfigOverride, respMessage).Error())
+	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckDeleteEngineConfigOverride, id)
+}
+
+// getEngineConfigChange gets the engine config change from the request body, it responds the error and returns false if the body is not valid
+func getEngineConfigChange(c *gin.Context) (*healthcheck.EngineConfigChange, bool) {
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return nil, false
+	}
+	change := &healthcheck.EngineConfigChange{}
+	err = json.Unmarshal(data, change)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return nil, false
+	}
+
+	return change, true
+}
+
+// respEngineConfig responds the engine config of the service
+func respEngineConfig(c *gin.Context, s *healthcheck.EngineConfigService, debugCode, infoCode int) {
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(debugCode, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, infoCode, s.Version)
+}
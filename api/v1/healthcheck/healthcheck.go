@@ -15,16 +15,33 @@ import (
 )
 
 const (
-	operationIDJSON = "operation_id"
-	serverIDJSON    = "server_id"
-	hostIPJSON      = "host_ip"
-	portNumJSON     = "port_num"
-	startTimeJSON   = "start_time"
-	endTimeJSON     = "end_time"
-	stepJSON        = "step"
-	reviewJSON      = "review"
+	operationIDJSON        = "operation_id"
+	clusterOperationIDJSON = "cluster_operation_id"
+	serverIDJSON           = "server_id"
+	clusterIDJSON          = "cluster_id"
+	hostIPJSON             = "host_ip"
+	portNumJSON            = "port_num"
+	startTimeJSON          = "start_time"
+	endTimeJSON            = "end_time"
+	stepJSON               = "step"
+	reviewJSON             = "review"
+	formatJSON             = "format"
+
+	reportContentTypeHTML     = "text/html; charset=utf-8"
+	reportContentTypeMarkdown = "text/markdown; charset=utf-8"
+
+	baseOperationIDJSON   = "base_operation_id"
+	targetOperationIDJSON = "target_operation_id"
+
+	snapshotJSON = "snapshot"
 )
 
+// snapshotRequest is the request body of checking with the snapshot, the snapshot is the json object of the captured data
+type snapshotRequest struct {
+	ServerID int             `json:"server_id"`
+	Snapshot json.RawMessage `json:"snapshot"`
+}
+
 // @Tags healthcheck
 // @Summary get result by operation id
 // @Produce  application/json
@@ -206,6 +223,337 @@ func CheckByHostInfo(c *gin.Context) {
 	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckCheckByHostInfo)
 }
 
+// @Tags healthcheck
+// @Summary get cluster result by cluster operation id
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {}}"
+// @Router /api/v1/healthcheck/result/cluster/:cluster_operation_id [get]
+func GetClusterResultByOperationID(c *gin.Context) {
+	// get data
+	clusterOperationIDStr := c.Param(clusterOperationIDJSON)
+	if clusterOperationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, clusterOperationIDJSON)
+		return
+	}
+	clusterOperationID, err := strconv.Atoi(clusterOperationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// get entities
+	err = s.GetClusterResultByOperationID(clusterOperationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetClusterResult, clusterOperationID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalClusterResultJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetClusterResult, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetClusterResult, clusterOperationID)
+}
+
+// @Tags healthcheck
+// @Summary get score trend of the mysql server in the time range, the trend includes the weighted average score and the score of every item
+// @Accept	application/json
+// @Param	body body string true "mysql server id and time range" default({"server_id": "1", "start_time": "2021-01-01 00:00:00", "end_time": "2021-02-01 00:00:00"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": [{"operation_id": 1, "check_time": "2021-01-21T10:00:00+08:00", "weighted_average_score": 90, "item_scores": {"cpu_usage": 100, "db_config": 80}}]}"
+// @Router /api/v1/healthcheck/result/trend [post]
+func GetScoreTrendByMySQLServerID(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	dataMap := make(map[string]string)
+	err = json.Unmarshal(data, &dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	mysqlServerIDStr, mysqlServerIDExists := dataMap[serverIDJSON]
+	if !mysqlServerIDExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, serverIDJSON)
+		return
+	}
+	mysqlServerID, err := strconv.Atoi(mysqlServerIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	startTimeStr, startTimeExists := dataMap[startTimeJSON]
+	if !startTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, startTimeJSON)
+		return
+	}
+	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, startTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, startTimeStr)
+		return
+	}
+	endTimeStr, endTimeExists := dataMap[endTimeJSON]
+	if !endTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, endTimeJSON)
+		return
+	}
+	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, endTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, endTimeStr)
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// get entities
+	err = s.GetScoreTrendByMySQLServerID(mysqlServerID, startTime, endTime)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetScoreTrend, mysqlServerID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalScoreTrendJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetScoreTrend, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetScoreTrend, mysqlServerID)
+}
+
+// @Tags healthcheck
+// @Summary compare the result of the target operation with the result of the base operation,
+// @Summary it shows the regressed items, the changed database variables and the grown large tables
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"base_operation_id": 1, "target_operation_id": 2, "score_delta": -5, "item_diffs": [{"item_name": "db_config", "base_score": 90, "target_score": 80, "delta": -10, "regressed": true}], "config_changes": [{"variable_name": "sync_binlog", "base_value": "1", "target_value": "0"}], "table_growths": [{"table_schema": "db1", "table_name": "t01", "base_rows": 100, "target_rows": 200, "rows_delta": 100, "base_size": 1.5, "target_size": 3, "size_delta": 1.5}]}}"
+// @Router /api/v1/healthcheck/result/diff/:base_operation_id/:target_operation_id [get]
+func GetResultDiffByOperationIDs(c *gin.Context) {
+	// get data
+	baseOperationIDStr := c.Param(baseOperationIDJSON)
+	if baseOperationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, baseOperationIDJSON)
+		return
+	}
+	baseOperationID, err := strconv.Atoi(baseOperationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	targetOperationIDStr := c.Param(targetOperationIDJSON)
+	if targetOperationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, targetOperationIDJSON)
+		return
+	}
+	targetOperationID, err := strconv.Atoi(targetOperationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// get entities
+	err = s.GetResultDiffByOperationIDs(baseOperationID, targetOperationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetResultDiff, baseOperationID, targetOperationID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalResultDiffJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetResultDiff, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetResultDiff, baseOperationID, targetOperationID)
+}
+
+// @Tags healthcheck
+// @Summary get the human readable report of the operation, format could be html, markdown or json, default is html
+// @Produce  html
+// @Param	format query string false "html, markdown or json" default(html)
+// @Success 200 {string} string "<!DOCTYPE html><html>...</html>"
+// @Router /api/v1/healthcheck/report/:operation_id [get]
+func GetReportByOperationID(c *gin.Context) {
+	// get data
+	operationIDStr := c.Param(operationIDJSON)
+	if operationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, operationIDJSON)
+		return
+	}
+	operationID, err := strconv.Atoi(operationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	format := c.DefaultQuery(formatJSON, healthcheck.ReportFormatHTML)
+	if format != healthcheck.ReportFormatHTML && format != healthcheck.ReportFormatMarkdown && format != healthcheck.ReportFormatJSON {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckReportFormatInvalid, format)
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// get entities
+	err = s.GetReportByOperationID(operationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetReport, operationID, err.Error())
+		return
+	}
+	// render report
+	switch format {
+	case healthcheck.ReportFormatHTML:
+		htmlBytes, err := s.MarshalReportHTML()
+		if err != nil {
+			resp.ResponseNOK(c, msghealth.ErrHealthcheckGetReport, operationID, err.Error())
+			return
+		}
+		resp.ResponseOKWithContentType(c, reportContentTypeHTML, htmlBytes, msghealth.InfoHealthcheckGetReport, operationID, format)
+	case healthcheck.ReportFormatMarkdown:
+		markdownBytes, err := s.MarshalReportMarkdown()
+		if err != nil {
+			resp.ResponseNOK(c, msghealth.ErrHealthcheckGetReport, operationID, err.Error())
+			return
+		}
+		resp.ResponseOKWithContentType(c, reportContentTypeMarkdown, markdownBytes, msghealth.InfoHealthcheckGetReport, operationID, format)
+	default:
+		jsonBytes, err := s.MarshalReportJSON()
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+			return
+		}
+		jsonStr := string(jsonBytes)
+		log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetReport, jsonStr).Error())
+		resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetReport, operationID, format)
+	}
+}
+
+// @Tags healthcheck
+// @Summary check health of all the mysql servers of the mysql cluster
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "{"cluster_operation_id": 1}"}"
+// @Router /api/v1/healthcheck/check/cluster [post]
+func CheckByMySQLClusterID(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	dataMap := make(map[string]string)
+	err = json.Unmarshal(data, &dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	mysqlClusterIDStr, mysqlClusterIDExists := dataMap[clusterIDJSON]
+	if !mysqlClusterIDExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, clusterIDJSON)
+		return
+	}
+	mysqlClusterID, err := strconv.Atoi(mysqlClusterIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	startTimeStr, startTimeExists := dataMap[startTimeJSON]
+	if !startTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, startTimeJSON)
+		return
+	}
+	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, startTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, startTimeStr)
+		return
+	}
+	endTimeStr, endTimeExists := dataMap[endTimeJSON]
+	if !endTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, endTimeJSON)
+		return
+	}
+	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, endTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, endTimeStr)
+		return
+	}
+	stepStr, stepExists := dataMap[stepJSON]
+	if !stepExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, stepJSON)
+		return
+	}
+	step, err := time.ParseDuration(stepStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeDuration, stepStr)
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// check health
+	err = s.CheckByMySQLClusterID(mysqlClusterID, startTime, endTime, step)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheckByMySQLClusterID, mysqlClusterID, err.Error())
+		return
+	}
+	jsonBytes, err := json.Marshal(map[string]int{clusterOperationIDJSON: s.ClusterOperationID})
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckCheckByMySQLClusterID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckCheckByMySQLClusterID, mysqlClusterID, s.ClusterOperationID)
+}
+
+// @Tags healthcheck
+// @Summary check health of the mysql server with the captured snapshot instead of the live connections, the mysql server must exist in the metadata
+// @Accept	application/json
+// @Param	body body string true "snapshot" default({"server_id": 1, "snapshot": {"start_time": "2021-05-21 10:00:00", "end_time": "2021-05-21 11:00:00", "step": "10s", "mysql": {"variables": [{"variable_name": "max_connections", "variable_value": "1000"}], "mysql_dirs": ["/data/mysql/data"], "tables": [{"table_schema": "das", "table_name": "t01", "table_rows": 50000000, "table_size": 12.5}]}, "prometheus": {"file_systems": [{"mount_point": "/", "device": "/dev/sda1"}], "cpu_usage": [{"timestamp": "2021-05-21 10:00:00", "value": 20.5}]}, "slow_queries": [{"sql_id": "F9A57DD5A41825CA", "fingerprint": "select * from t01 where id = ?", "example": "select * from t01 where id = 1", "db_name": "das", "exec_count": 10, "total_exec_time": 20.5, "avg_exec_time": 2.05, "rows_examined_max": 50000000}]}})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "healthcheck by snapshot started"}"
+// @Router /api/v1/healthcheck/check/snapshot [post]
+func CheckBySnapshot(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	req := &snapshotRequest{}
+	err = json.Unmarshal(data, req)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	if req.ServerID == constant.ZeroInt {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, serverIDJSON)
+		return
+	}
+	if len(req.Snapshot) == constant.ZeroInt {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, snapshotJSON)
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// check health
+	err = s.CheckBySnapshot(req.ServerID, req.Snapshot)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheckBySnapshot, req.ServerID, err.Error())
+		return
+	}
+	respMessage := "healthcheck by snapshot started"
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckCheckBySnapshot, respMessage).Error())
+	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckCheckBySnapshot, req.ServerID)
+}
+
 // @Tags healthcheck
 // @Summary update accuracy review
 // @Produce  application/json
@@ -0,0 +1,133 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghealth "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	middlewareOperationIDJSON = "middleware_operation_id"
+)
+
+// @Tags healthcheck
+// @Summary check connectivity, backends and routing health of all the middleware servers of the middleware cluster
+// @Accept	application/json
+// @Param	body body string true "middleware cluster id" default({"cluster_id": "1"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "{"middleware_operation_id": 1}"}"
+// @Router /api/v1/healthcheck/check/middleware-cluster [post]
+func CheckByMiddlewareClusterID(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	dataMap := make(map[string]string)
+	err = json.Unmarshal(data, &dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	middlewareClusterIDStr, middlewareClusterIDExists := dataMap[clusterIDJSON]
+	if !middlewareClusterIDExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, clusterIDJSON)
+		return
+	}
+	middlewareClusterID, err := strconv.Atoi(middlewareClusterIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// check health
+	err = s.CheckByMiddlewareClusterID(middlewareClusterID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckCheckByMiddlewareClusterID, middlewareClusterID, err.Error())
+		return
+	}
+	jsonBytes, err := json.Marshal(map[string]int{middlewareOperationIDJSON: s.MiddlewareOperationID})
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckCheckByMiddlewareClusterID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckCheckByMiddlewareClusterID, middlewareClusterID, s.MiddlewareOperationID)
+}
+
+// @Tags healthcheck
+// @Summary get middleware result by middleware operation id, the server results contain the backends of each middleware server and the registered mysql servers which they map to
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"id": 1, "middleware_operation_id": 1, "weighted_average_score": 85, "connectivity_score": 100, "backend_score": 50, "routing_score": 100, "server_results": "[{\"middleware_server_id\": 1, \"server_name\": \"proxy01\", \"middleware_role\": 1, \"host_ip\": \"192.168.10.219\", \"port_num\": 6033, \"connected\": true, \"latency\": 1.5, \"admin_available\": true, \"backends\": [{\"hostgroup_id\": 10, \"host_ip\": \"192.168.10.219\", \"port_num\": 3306, \"status\": \"ONLINE\", \"conn_used\": 2, \"conn_free\": 8, \"conn_ok\": 100, \"conn_err\": 0, \"mysql_server_id\": 1, \"mysql_cluster_id\": 1, \"mapping\": \"mapped\"}], \"routing_score\": 100, \"message\": \"\"}]", "missing_backends": "[\"192.168.10.220:3306\"]", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}}"
+// @Router /api/v1/healthcheck/result/middleware/:middleware_operation_id [get]
+func GetMiddlewareResultByOperationID(c *gin.Context) {
+	// get data
+	middlewareOperationIDStr := c.Param(middlewareOperationIDJSON)
+	if middlewareOperationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareOperationIDJSON)
+		return
+	}
+	middlewareOperationID, err := strconv.Atoi(middlewareOperationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// get entities
+	err = s.GetMiddlewareResultByOperationID(middlewareOperationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetMiddlewareResult, middlewareOperationID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalMiddlewareResultJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetMiddlewareResult, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetMiddlewareResult, middlewareOperationID)
+}
+
+// @Tags healthcheck
+// @Summary cancel the middleware operation which is running on this das server
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "middleware healthcheck cancel requested"}"
+// @Router /api/v1/healthcheck/operation/cancel/middleware/:middleware_operation_id [post]
+func CancelMiddlewareOperation(c *gin.Context) {
+	// get params
+	middlewareOperationIDStr := c.Param(middlewareOperationIDJSON)
+	if middlewareOperationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, middlewareOperationIDJSON)
+		return
+	}
+	middlewareOperationID, err := strconv.Atoi(middlewareOperationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// cancel
+	err = s.CancelMiddleware(middlewareOperationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckCancelMiddlewareOperation, middlewareOperationID, err.Error())
+		return
+	}
+	respMessage := "middleware healthcheck cancel requested"
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckCancelMiddlewareOperation, respMessage).Error())
+	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckCancelMiddlewareOperation, middlewareOperationID)
+}
@@ -0,0 +1,167 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghealth "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	notifyRuleIDJSON = "id"
+	ruleNameJSON     = "rule_name"
+)
+
+// notifyRuleRequest is the request body of creating the notify rule
+type notifyRuleRequest struct {
+	RuleName       string `json:"rule_name"`
+	MySQLClusterID int    `json:"mysql_cluster_id"`
+	TriggerType    int    `json:"trigger_type"`
+	Threshold      int    `json:"threshold"`
+	SinkType       int    `json:"sink_type"`
+	Target         string `json:"target"`
+}
+
+// @Tags healthcheck
+// @Summary get all healthcheck notify rules
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"rules": [{"id": 1, "rule_name": "cluster-1-score-below-80", "mysql_cluster_id": 1, "trigger_type": 2, "threshold": 80, "sink_type": 1, "target": "http://127.0.0.1:8080/alert", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/notify-rule [get]
+func GetNotifyRuleAll(c *gin.Context) {
+	// init service
+	s := healthcheck.NewNotifyServiceWithDefault()
+	// get entities
+	err := s.GetAll()
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetNotifyRuleAll, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetNotifyRuleAll, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetNotifyRuleAll)
+}
+
+// @Tags healthcheck
+// @Summary create a healthcheck notify rule, mysql_cluster_id: 0-all mysql clusters, trigger_type: 1-failure, 2-score below threshold, 3-score regression, sink_type: 1-webhook, 2-email, 3-file, the email rule with empty target notifies the owner of the mysql cluster
+// @Accept	application/json
+// @Param	body body string true "notify rule" default({"rule_name": "cluster-1-score-below-80", "mysql_cluster_id": 1, "trigger_type": 2, "threshold": 80, "sink_type": 1, "target": "http://127.0.0.1:8080/alert"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"rules": [{"id": 1, "rule_name": "cluster-1-score-below-80", "mysql_cluster_id": 1, "trigger_type": 2, "threshold": 80, "sink_type": 1, "target": "http://127.0.0.1:8080/alert", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/notify-rule [post]
+func CreateNotifyRule(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	req := &notifyRuleRequest{}
+	err = json.Unmarshal(data, req)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	if req.RuleName == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, ruleNameJSON)
+		return
+	}
+	// init service
+	s := healthcheck.NewNotifyServiceWithDefault()
+	// insert into middleware
+	err = s.Create(req.RuleName, req.MySQLClusterID, req.TriggerType, req.Threshold, req.SinkType, req.Target)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckCreateNotifyRule, req.RuleName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckCreateNotifyRule, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckCreateNotifyRule, req.RuleName)
+}
+
+// @Tags healthcheck
+// @Summary delete the healthcheck notify rule by id
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "notify rule deleted"}"
+// @Router /api/v1/healthcheck/notify-rule/delete/:id [post]
+func DeleteNotifyRuleByID(c *gin.Context) {
+	// get params
+	idStr := c.Param(notifyRuleIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, notifyRuleIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewNotifyServiceWithDefault()
+	// delete entity
+	err = s.Delete(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckDeleteNotifyRule, id, err.Error())
+		return
+	}
+	// response
+	respMessage := "notify rule deleted"
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckDeleteNotifyRule, respMessage).Error())
+	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckDeleteNotifyRule, id)
+}
+
+// @Tags healthcheck
+// @Summary get the notify logs of the healthcheck operation, status: 1-succeeded, 2-failed
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"logs": [{"id": 1, "rule_id": 1, "operation_id": 12, "sink_type": 1, "target": "http://127.0.0.1:8080/alert", "status": 1, "attempts": 1, "message": "", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/notify-log/operation/:operation_id [get]
+func GetNotifyLogsByOperationID(c *gin.Context) {
+	// get params
+	operationIDStr := c.Param(operationIDJSON)
+	if operationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, operationIDJSON)
+		return
+	}
+	operationID, err := strconv.Atoi(operationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewNotifyServiceWithDefault()
+	// get entities
+	err = s.GetLogsByOperationID(operationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetNotifyLogs, operationID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalLogs()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetNotifyLogs, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetNotifyLogs, operationID)
+}
@@ -0,0 +1,203 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghealth "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	statusJSON         = "status"
+	newOperationIDJSON = "new_operation_id"
+
+	// defaultOperationFilterIgnored means the filter of the operations is not specified
+	defaultOperationFilterIgnored = -1
+)
+
+// @Tags healthcheck
+// @Summary get operations which were created in the time range, server_id and status are optional filters
+// @Accept	application/json
+// @Param	body body string true "mysql server id, status and time range" default({"server_id": "1", "status": "3", "start_time": "2021-01-01 00:00:00", "end_time": "2021-02-01 00:00:00"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": [{"id": 1, "cluster_operation_id": 0, "mysql_server_id": 1, "start_time": "2021-01-21T09:00:00+08:00", "end_time": "2021-01-21T10:00:00+08:00", "step": 60, "status": 3, "message": "", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:10+08:00"}]}"
+// @Router /api/v1/healthcheck/operation [post]
+func GetOperations(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	dataMap := make(map[string]string)
+	err = json.Unmarshal(data, &dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	mysqlServerID := defaultOperationFilterIgnored
+	mysqlServerIDStr, mysqlServerIDExists := dataMap[serverIDJSON]
+	if mysqlServerIDExists {
+		mysqlServerID, err = strconv.Atoi(mysqlServerIDStr)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+			return
+		}
+	}
+	status := defaultOperationFilterIgnored
+	statusStr, statusExists := dataMap[statusJSON]
+	if statusExists {
+		status, err = strconv.Atoi(statusStr)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+			return
+		}
+	}
+	startTimeStr, startTimeExists := dataMap[startTimeJSON]
+	if !startTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, startTimeJSON)
+		return
+	}
+	startTime, err := time.ParseInLocation(constant.TimeLayoutSecond, startTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, startTimeStr)
+		return
+	}
+	endTimeStr, endTimeExists := dataMap[endTimeJSON]
+	if !endTimeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, endTimeJSON)
+		return
+	}
+	endTime, err := time.ParseInLocation(constant.TimeLayoutSecond, endTimeStr, time.Local)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeLayout, endTimeStr)
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// get entities
+	err = s.GetOperations(mysqlServerID, status, startTime, endTime)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetOperations, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalOperationsJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetOperations, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetOperations)
+}
+
+// @Tags healthcheck
+// @Summary get status of the operation, the progress of each check item is only available when the operation is running on this das server
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"operation": {"id": 1, "cluster_operation_id": 0, "mysql_server_id": 1, "start_time": "2021-01-21T09:00:00+08:00", "end_time": "2021-01-21T10:00:00+08:00", "step": 60, "status": 1, "message": "", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}, "item_statuses": {"db_config": "completed", "cpu_usage": "running"}}}"
+// @Router /api/v1/healthcheck/operation/status/:operation_id [get]
+func GetOperationStatus(c *gin.Context) {
+	// get params
+	operationID, ok := getOperationID(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// get entity
+	err := s.GetOperationStatus(operationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetOperationStatus, operationID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.MarshalOperationStatusJSON()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetOperationStatus, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetOperationStatus, operationID)
+}
+
+// @Tags healthcheck
+// @Summary cancel the operation which is running on this das server
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "healthcheck cancel requested"}"
+// @Router /api/v1/healthcheck/operation/cancel/:operation_id [post]
+func CancelOperation(c *gin.Context) {
+	// get params
+	operationID, ok := getOperationID(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// cancel
+	err := s.Cancel(operationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckCancelOperation, operationID, err.Error())
+		return
+	}
+	respMessage := "healthcheck cancel requested"
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckCancelOperation, respMessage).Error())
+	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckCancelOperation, operationID)
+}
+
+// @Tags healthcheck
+// @Summary retry the failed or canceled operation with the same mysql server, time range and step
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"new_operation_id": 2}}"
+// @Router /api/v1/healthcheck/operation/retry/:operation_id [post]
+func RetryOperation(c *gin.Context) {
+	// get params
+	operationID, ok := getOperationID(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewServiceWithDefault()
+	// retry
+	err := s.Retry(operationID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckRetryOperation, operationID, err.Error())
+		return
+	}
+	newOperationID := s.GetOperationID()
+	jsonBytes, err := json.Marshal(map[string]int{newOperationIDJSON: newOperationID})
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckRetryOperation, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckRetryOperation, operationID, newOperationID)
+}
+
+// getOperationID gets the operation id from the path, it responds the error and returns false if the operation id is not valid
+func getOperationID(c *gin.Context) (int, bool) {
+	operationIDStr := c.Param(operationIDJSON)
+	if operationIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, operationIDJSON)
+		return constant.ZeroInt, false
+	}
+	operationID, err := strconv.Atoi(operationIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return constant.ZeroInt, false
+	}
+
+	return operationID, true
+}
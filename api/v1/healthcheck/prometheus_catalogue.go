@@ -0,0 +1,134 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghealth "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	monitorSystemIDJSON               = "monitor_system_id"
+	prometheusCatalogueOverrideIDJSON = "id"
+)
+
+// prometheusCatalogueOverrideRequest is the request body of saving the prometheus catalogue override
+type prometheusCatalogueOverrideRequest struct {
+	MonitorSystemID int    `json:"monitor_system_id"`
+	Name            string `json:"name"`
+	Template        string `json:"template"`
+}
+
+// @Tags healthcheck
+// @Summary get the prometheus catalogue of the monitor system, the overrides are applied to the built-in catalogue of the system type, the templates use ${.Field} as the placeholder
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"monitor_system_id": 1, "system_type": 3, "templates": {"node_label": "instance", "node_value": "${.HostIP}:9100", "service_label": "instance", "service_value": "${.HostIP}:9104", "replication_lag": "max by (${.ServiceLabel}) (mysql_slave_status_seconds_behind_master{${.ServiceLabel}=~\"${.ServiceValue}\"})"}, "overrides": [{"id": 1, "monitor_system_id": 1, "name": "node_value", "template": "${.HostIP}:9100", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/prometheus-catalogue/:monitor_system_id [get]
+func GetPrometheusCatalogue(c *gin.Context) {
+	// get params
+	monitorSystemIDStr := c.Param(monitorSystemIDJSON)
+	if monitorSystemIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, monitorSystemIDJSON)
+		return
+	}
+	monitorSystemID, err := strconv.Atoi(monitorSystemIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewPrometheusCatalogueServiceWithDefault()
+	// get entity
+	err = s.GetByMonitorSystemID(monitorSystemID)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetPrometheusCatalogue, monitorSystemID, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetPrometheusCatalogue, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetPrometheusCatalogue, monitorSystemID)
+}
+
+// @Tags healthcheck
+// @Summary save the prometheus catalogue override of the monitor system, the existing override of the same name will be replaced
+// @Accept	application/json
+// @Param	body body string true "prometheus catalogue override" default({"monitor_system_id": 1, "name": "service_value", "template": "${.HostIP}:${.PortNum}"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"monitor_system_id": 1, "system_type": 3, "templates": {"service_value": "${.HostIP}:${.PortNum}"}, "overrides": [{"id": 1, "monitor_system_id": 1, "name": "service_value", "template": "${.HostIP}:${.PortNum}", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/prometheus-catalogue [post]
+func SavePrometheusCatalogueOverride(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	req := &prometheusCatalogueOverrideRequest{}
+	err = json.Unmarshal(data, req)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewPrometheusCatalogueServiceWithDefault()
+	// save entity
+	err = s.SaveOverride(req.MonitorSystemID, req.Name, req.Template)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckSavePrometheusCatalogueOverride, req.MonitorSystemID, req.Name, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckSavePrometheusCatalogueOverride, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckSavePrometheusCatalogueOverride, req.MonitorSystemID, req.Name)
+}
+
+// @Tags healthcheck
+// @Summary delete the prometheus catalogue override by id, the template falls back to the built-in one of the system type
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "prometheus catalogue override deleted"}"
+// @Router /api/v1/healthcheck/prometheus-catalogue/delete/:id [post]
+func DeletePrometheusCatalogueOverride(c *gin.Context) {
+	// get params
+	idStr := c.Param(prometheusCatalogueOverrideIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, prometheusCatalogueOverrideIDJSON)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	// init service
+	s := healthcheck.NewPrometheusCatalogueServiceWithDefault()
+	// delete entity
+	err = s.DeleteOverride(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckDeletePrometheusCatalogueOverride, id, err.Error())
+		return
+	}
+	// response
+	respMessage := "prometheus catalogue override deleted"
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckDeletePrometheusCatalogueOverride, respMessage).Error())
+	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckDeletePrometheusCatalogueOverride, id)
+}
@@ -0,0 +1,276 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghealth "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/das/pkg/resp"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	scheduleIDJSON   = "id"
+	scheduleNameJSON = "schedule_name"
+	targetTypeJSON   = "target_type"
+	targetIDJSON     = "target_id"
+	cronExprJSON     = "cron_expr"
+	windowJSON       = "window"
+)
+
+// @Tags healthcheck
+// @Summary get all healthcheck schedules
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"schedules": [{"id": 1, "schedule_name": "daily-cluster-1", "target_type": 2, "target_id": 1, "cron_expr": "0 8 * * *", "window_seconds": 86400, "step": 60, "status": 1, "last_run_time": "2021-01-21T08:00:00+08:00", "next_run_time": "2021-01-22T08:00:00+08:00", "message": "", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/schedule [get]
+func GetSchedule(c *gin.Context) {
+	// init service
+	s := healthcheck.NewScheduleServiceWithDefault()
+	// get entities
+	err := s.GetAll()
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetScheduleAll, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetScheduleAll, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetScheduleAll)
+}
+
+// @Tags healthcheck
+// @Summary get healthcheck schedule by id
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"schedules": [{"id": 1, "schedule_name": "daily-cluster-1", "target_type": 2, "target_id": 1, "cron_expr": "0 8 * * *", "window_seconds": 86400, "step": 60, "status": 1, "last_run_time": "2021-01-21T08:00:00+08:00", "next_run_time": "2021-01-22T08:00:00+08:00", "message": "", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/schedule/get/:id [get]
+func GetScheduleByID(c *gin.Context) {
+	// get params
+	id, ok := getScheduleID(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewScheduleServiceWithDefault()
+	// get entity
+	err := s.GetByID(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetScheduleByID, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckGetScheduleByID, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckGetScheduleByID, id)
+}
+
+// @Tags healthcheck
+// @Summary create a healthcheck schedule, target_type: 1-mysql server, 2-mysql cluster, 3-env, 4-app
+// @Accept	application/json
+// @Param	body body string true "schedule" default({"schedule_name": "daily-cluster-1", "target_type": "2", "target_id": "1", "cron_expr": "0 8 * * *", "window": "24h", "step": "60s"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"schedules": [{"id": 1, "schedule_name": "daily-cluster-1", "target_type": 2, "target_id": 1, "cron_expr": "0 8 * * *", "window_seconds": 86400, "step": 60, "status": 1, "last_run_time": "1970-01-01T08:00:01+08:00", "next_run_time": "2021-01-22T08:00:00+08:00", "message": "", "del_flag": 0, "create_time": "2021-01-21T10:00:00+08:00", "last_update_time": "2021-01-21T10:00:00+08:00"}]}}"
+// @Router /api/v1/healthcheck/schedule [post]
+func AddSchedule(c *gin.Context) {
+	// get data
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	dataMap := make(map[string]string)
+	err = json.Unmarshal(data, &dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+	scheduleName, scheduleNameExists := dataMap[scheduleNameJSON]
+	if !scheduleNameExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, scheduleNameJSON)
+		return
+	}
+	targetTypeStr, targetTypeExists := dataMap[targetTypeJSON]
+	if !targetTypeExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, targetTypeJSON)
+		return
+	}
+	targetType, err := strconv.Atoi(targetTypeStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	targetIDStr, targetIDExists := dataMap[targetIDJSON]
+	if !targetIDExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, targetIDJSON)
+		return
+	}
+	targetID, err := strconv.Atoi(targetIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return
+	}
+	cronExpr, cronExprExists := dataMap[cronExprJSON]
+	if !cronExprExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, cronExprJSON)
+		return
+	}
+	windowStr, windowExists := dataMap[windowJSON]
+	if !windowExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, windowJSON)
+		return
+	}
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeDuration, windowStr)
+		return
+	}
+	stepStr, stepExists := dataMap[stepJSON]
+	if !stepExists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, stepJSON)
+		return
+	}
+	step, err := time.ParseDuration(stepStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrNotValidTimeDuration, stepStr)
+		return
+	}
+	// init service
+	s := healthcheck.NewScheduleServiceWithDefault()
+	// insert into middleware
+	err = s.Create(scheduleName, targetType, targetID, cronExpr, window, step)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckCreateSchedule, scheduleName, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckCreateSchedule, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, msghealth.InfoHealthcheckCreateSchedule, scheduleName)
+}
+
+// @Tags healthcheck
+// @Summary pause the healthcheck schedule by id
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"schedules": [{"id": 1, "status": 2}]}}"
+// @Router /api/v1/healthcheck/schedule/pause/:id [post]
+func PauseScheduleByID(c *gin.Context) {
+	// get params
+	id, ok := getScheduleID(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewScheduleServiceWithDefault()
+	// update entity
+	err := s.Pause(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckPauseSchedule, id, err.Error())
+		return
+	}
+	respScheduleByID(c, s, id, msghealth.DebugHealthcheckPauseSchedule, msghealth.InfoHealthcheckPauseSchedule)
+}
+
+// @Tags healthcheck
+// @Summary resume the healthcheck schedule by id, the next run time will be recalculated from now
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"schedules": [{"id": 1, "status": 1}]}}"
+// @Router /api/v1/healthcheck/schedule/resume/:id [post]
+func ResumeScheduleByID(c *gin.Context) {
+	// get params
+	id, ok := getScheduleID(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewScheduleServiceWithDefault()
+	// update entity
+	err := s.Resume(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckResumeSchedule, id, err.Error())
+		return
+	}
+	respScheduleByID(c, s, id, msghealth.DebugHealthcheckResumeSchedule, msghealth.InfoHealthcheckResumeSchedule)
+}
+
+// @Tags healthcheck
+// @Summary delete the healthcheck schedule by id
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": "schedule deleted"}"
+// @Router /api/v1/healthcheck/schedule/delete/:id [post]
+func DeleteScheduleByID(c *gin.Context) {
+	// get params
+	id, ok := getScheduleID(c)
+	if !ok {
+		return
+	}
+	// init service
+	s := healthcheck.NewScheduleServiceWithDefault()
+	// delete entity
+	err := s.Delete(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckDeleteSchedule, id, err.Error())
+		return
+	}
+	// response
+	respMessage := "schedule deleted"
+	log.Debug(message.NewMessage(msghealth.DebugHealthcheckDeleteSchedule, respMessage).Error())
+	resp.ResponseOK(c, respMessage, msghealth.InfoHealthcheckDeleteSchedule, id)
+}
+
+// getScheduleID gets the schedule id from the path, it responds the error and returns false if the id is not valid
+func getScheduleID(c *gin.Context) (int, bool) {
+	idStr := c.Param(scheduleIDJSON)
+	if idStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, scheduleIDJSON)
+		return constant.ZeroInt, false
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err.Error())
+		return constant.ZeroInt, false
+	}
+
+	return id, true
+}
+
+// respScheduleByID gets the schedule by id and responds it
+func respScheduleByID(c *gin.Context, s *healthcheck.ScheduleService, id, debugCode, infoCode int) {
+	// get entity
+	err := s.GetByID(id)
+	if err != nil {
+		resp.ResponseNOK(c, msghealth.ErrHealthcheckGetScheduleByID, id, err.Error())
+		return
+	}
+	// marshal service
+	jsonBytes, err := s.Marshal()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	// response
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(debugCode, jsonStr).Error())
+	resp.ResponseOK(c, jsonStr, infoCode, id)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/romberli/das/internal/app/query"
@@ -20,13 +21,15 @@ const (
 	mysqlServerIDJSON  = "mysql_server_id"
 	dbIDJSON           = "db_id"
 	sqlIDJSON          = "sql_id"
+	stepJSON           = "step"
+	splitTimeJSON      = "split_time"
 )
 
 // @Tags query
 // @Summary get slow queries by mysql server id
 // @Produce  application/json
 // @Success 200 {string} string "{"code": 200, "data": []}"
-// @Router /api/v1/query/cluster/:mysqlClusterID [get]
+// @Router /api/v1/query/cluster/:mysql_cluster_id [get]
 func GetByMySQLClusterID(c *gin.Context) {
 	// get data
 	mysqlClusterIDStr := c.Param(mysqlClusterIDJSON)
@@ -89,7 +92,7 @@ func GetByMySQLClusterID(c *gin.Context) {
 // @Summary get slow queries by mysql server id
 // @Produce  application/json
 // @Success 200 {string} string "{"code": 200, "data": []}"
-// @Router /api/v1/query/server/:mysqlServerID [get]
+// @Router /api/v1/query/server/:mysql_server_id [get]
 func GetByMySQLServerID(c *gin.Context) {
 	// get data
 	mysqlServerIDStr := c.Param(mysqlServerIDJSON)
@@ -146,7 +149,7 @@ func GetByMySQLServerID(c *gin.Context) {
 // @Summary get slow queries by db id
 // @Produce  application/json
 // @Success 200 {string} string "{"code": 200, "data": []}"
-// @Router /api/v1/query/db/:dbID [get]
+// @Router /api/v1/query/db/:db_id [get]
 func GetByDBID(c *gin.Context) {
 	// get data
 	dbIDStr := c.Param(dbIDJSON)
@@ -216,7 +219,7 @@ func GetByDBID(c *gin.Context) {
 // @Summary get slow query by query id
 // @Produce  application/json
 // @Success 200 {string} string "{"code": 200, "data": []}"
-// @Router /api/v1/query/:sqlID [get]
+// @Router /api/v1/query/:sql_id [get]
 func GetBySQLID(c *gin.Context) {
 	// get data
 	sqlIDStr := c.Param(sqlIDJSON)
@@ -275,3 +278,91 @@ func GetBySQLID(c *gin.Context) {
 	// response
 	resp.ResponseOK(c, jsonStr, msgquery.InfoQueryGetBySQLID, sqlIDStr)
 }
+
+// @Tags query
+// @Summary get the time-bucketed series of the slow query by sql id and detect the regression of the average execution time, the step is in seconds, the split time is detected from the series if it is not specified
+// @Accept	application/json
+// @Param	body body string true "mysql server id, time range, step and split time" default({"mysql_server_id": "1", "start_time": "2021-01-01 00:00:00", "end_time": "2021-01-08 00:00:00", "step": "3600", "split_time": "2021-01-05 00:00:00"})
+// @Produce  application/json
+// @Success 200 {string} string "{"code": 200, "data": {"sql_id": "999ECD050D719733", "step": 3600, "points": [{"bucket_time": 1609430400, "exec_count": 10, "total_exec_time": 1.5, "avg_exec_time": 0.15, "rows_examined_sum": 1000, "rows_examined_max": 100}], "regression": {"split_time": 1609776000, "detected": false, "before_exec_count": 960, "after_exec_count": 720, "before_avg_exec_time": 0.15, "after_avg_exec_time": 0.31, "ratio": 2.07, "status": "regressed"}}}"
+// @Router /api/v1/query/trend/:sql_id [post]
+func GetTrendBySQLID(c *gin.Context) {
+	// get data
+	sqlIDStr := c.Param(sqlIDJSON)
+	if sqlIDStr == constant.EmptyString {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, sqlIDJSON)
+		return
+	}
+
+	data, err := c.GetRawData()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrGetRawData, err.Error())
+		return
+	}
+	dataMap := make(map[string]string)
+	err = json.Unmarshal(data, &dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+
+	// get mysqlServerID
+	mysqlServerIDStr, exists := dataMap[mysqlServerIDJSON]
+	if !exists {
+		resp.ResponseNOK(c, message.ErrFieldNotExists, mysqlServerIDJSON)
+		return
+	}
+	mysqlServerID, err := strconv.Atoi(mysqlServerIDStr)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrTypeConversion, err)
+		return
+	}
+	// get step, it is determined by the time range if not specified
+	var step time.Duration
+	stepStr, exists := dataMap[stepJSON]
+	if exists {
+		stepSeconds, err := strconv.Atoi(stepStr)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrTypeConversion, err)
+			return
+		}
+		step = time.Duration(stepSeconds) * time.Second
+	}
+	// get split time, it is detected from the series if not specified
+	var splitTime time.Time
+	splitTimeStr, exists := dataMap[splitTimeJSON]
+	if exists {
+		splitTime, err = time.ParseInLocation(constant.TimeLayoutSecond, splitTimeStr, time.Local)
+		if err != nil {
+			resp.ResponseNOK(c, message.ErrNotValidTimeLayout, splitTimeStr)
+			return
+		}
+	}
+
+	// get config
+	config, err := util.GetConfig(dataMap)
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrUnmarshalRawData, err.Error())
+		return
+	}
+
+	// init service
+	service := query.NewServiceWithDefault(config)
+	err = service.GetTrendBySQLID(mysqlServerID, sqlIDStr, step, splitTime)
+	if err != nil {
+		resp.ResponseNOK(c, msgquery.ErrQueryGetTrendBySQLID, mysqlServerID, sqlIDStr, err.Error())
+		return
+	}
+
+	// marshal
+	jsonBytes, err := service.MarshalTrend()
+	if err != nil {
+		resp.ResponseNOK(c, message.ErrMarshalData, err.Error())
+		return
+	}
+	jsonStr := string(jsonBytes)
+	log.Debug(message.NewMessage(msgquery.DebugQueryGetTrendBySQLID, mysqlServerID, sqlIDStr, jsonStr).Error())
+
+	// response
+	resp.ResponseOK(c, jsonStr, msgquery.InfoQueryGetTrendBySQLID, mysqlServerID, sqlIDStr)
+}
@@ -80,6 +80,22 @@ var (
 	sqladvisorSoarProfilingStr string
 	sqladvisorSoarTraceStr     string
 	sqladvisorSoarExplainStr   string
+	// healthcheck
+	healthcheckItemTimeout         int
+	healthcheckNotifyRetryCount    int
+	healthcheckNotifyRetryInterval int
+	healthcheckNotifyTimeout       int
+	healthcheckNotifySMTPAddr      string
+	healthcheckNotifySMTPUser      string
+	healthcheckNotifySMTPPass      string
+	healthcheckNotifySMTPFrom      string
+	healthcheckNotifyFileDir       string
+	healthcheckMiddlewareAdminPort int
+	healthcheckMiddlewareAdminUser string
+	healthcheckMiddlewareAdminPass string
+	// capacity
+	capacityCollectorInterval  int
+	capacityCollectorRetention int
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -169,6 +185,21 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&sqladvisorSoarProfilingStr, "sqladvisor-soar-profiling", constant.DefaultRandomString, fmt.Sprintf("specify if enabling profiling for soar(default: %s)", constant.FalseString))
 	rootCmd.PersistentFlags().StringVar(&sqladvisorSoarTraceStr, "sqladvisor-soar-trace", constant.DefaultRandomString, fmt.Sprintf("specify if enabling trace for soar(default: %s)", constant.FalseString))
 	rootCmd.PersistentFlags().StringVar(&sqladvisorSoarExplainStr, "sqladvisor-soar-explain", constant.DefaultRandomString, fmt.Sprintf("specify if enabling explain for soar(default: %s)", constant.FalseString))
+	// healthcheck
+	rootCmd.PersistentFlags().IntVar(&healthcheckItemTimeout, "healthcheck-item-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify timeout of each healthcheck item(default: %d, unit: seconds)", config.DefaultHealthcheckItemTimeout))
+	rootCmd.PersistentFlags().IntVar(&healthcheckNotifyRetryCount, "healthcheck-notify-retry-count", constant.DefaultRandomInt, fmt.Sprintf("specify retry count of each healthcheck notification delivery(default: %d)", config.DefaultHealthcheckNotifyRetryCount))
+	rootCmd.PersistentFlags().IntVar(&healthcheckNotifyRetryInterval, "healthcheck-notify-retry-interval", constant.DefaultRandomInt, fmt.Sprintf("specify retry interval of the healthcheck notification delivery(default: %d, unit: seconds)", config.DefaultHealthcheckNotifyRetryInterval))
+	rootCmd.PersistentFlags().IntVar(&healthcheckNotifyTimeout, "healthcheck-notify-timeout", constant.DefaultRandomInt, fmt.Sprintf("specify timeout of each healthcheck notification delivery(default: %d, unit: seconds)", config.DefaultHealthcheckNotifyTimeout))
+	rootCmd.PersistentFlags().StringVar(&healthcheckNotifySMTPAddr, "healthcheck-notify-smtp-addr", constant.DefaultRandomString, fmt.Sprintf("specify smtp server address of the healthcheck email notification(default: %s)", config.DefaultHealthcheckNotifySMTPAddr))
+	rootCmd.PersistentFlags().StringVar(&healthcheckNotifySMTPUser, "healthcheck-notify-smtp-user", constant.DefaultRandomString, fmt.Sprintf("specify smtp user of the healthcheck email notification(default: %s)", config.DefaultHealthcheckNotifySMTPUser))
+	rootCmd.PersistentFlags().StringVar(&healthcheckNotifySMTPPass, "healthcheck-notify-smtp-pass", constant.DefaultRandomString, fmt.Sprintf("specify smtp password of the healthcheck email notification(default: %s)", config.DefaultHealthcheckNotifySMTPPass))
+	rootCmd.PersistentFlags().StringVar(&healthcheckNotifySMTPFrom, "healthcheck-notify-smtp-from", constant.DefaultRandomString, fmt.Sprintf("specify from address of the healthcheck email notification(default: %s)", config.DefaultHealthcheckNotifySMTPFrom))
+	rootCmd.PersistentFlags().StringVar(&healthcheckNotifyFileDir, "healthcheck-notify-file-dir", constant.DefaultRandomString, fmt.Sprintf("specify the directory of the healthcheck file notification, the targets of the file sink are relative to it(default: %s)", filepath.Join(config.DefaultBaseDir, config.DefaultHealthcheckNotifyFileDir)))
+	rootCmd.PersistentFlags().IntVar(&healthcheckMiddlewareAdminPort, "healthcheck-middleware-admin-port", constant.DefaultRandomInt, fmt.Sprintf("specify admin port of the middleware servers, 0 means the admin interface is not available(default: %d)", config.DefaultHealthcheckMiddlewareAdminPort))
+	rootCmd.PersistentFlags().StringVar(&healthcheckMiddlewareAdminUser, "healthcheck-middleware-admin-user", constant.DefaultRandomString, fmt.Sprintf("specify admin user of the middleware servers(default: %s)", config.DefaultHealthcheckMiddlewareAdminUser))
+	rootCmd.PersistentFlags().StringVar(&healthcheckMiddlewareAdminPass, "healthcheck-middleware-admin-pass", constant.DefaultRandomString, fmt.Sprintf("specify admin password of the middleware servers(default: %s)", config.DefaultHealthcheckMiddlewareAdminPass))
+	rootCmd.PersistentFlags().IntVar(&capacityCollectorInterval, "capacity-collector-interval", constant.DefaultRandomInt, fmt.Sprintf("specify the interval in seconds of the table size snapshots, 0 means the collector is disabled(default: %d)", config.DefaultCapacityCollectorInterval))
+	rootCmd.PersistentFlags().IntVar(&capacityCollectorRetention, "capacity-collector-retention", constant.DefaultRandomInt, fmt.Sprintf("specify the days to keep the table size snapshots, 0 means the snapshots are kept forever(default: %d)", config.DefaultCapacityCollectorRetention))
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -402,6 +433,52 @@ func OverrideConfig() (err error) {
 		viper.Set(config.SQLAdvisorSoarExplainKey, false)
 	}
 
+	// override healthcheck
+	if healthcheckItemTimeout != constant.DefaultRandomInt {
+		viper.Set(config.HealthcheckItemTimeoutKey, healthcheckItemTimeout)
+	}
+	if healthcheckNotifyRetryCount != constant.DefaultRandomInt {
+		viper.Set(config.HealthcheckNotifyRetryCountKey, healthcheckNotifyRetryCount)
+	}
+	if healthcheckNotifyRetryInterval != constant.DefaultRandomInt {
+		viper.Set(config.HealthcheckNotifyRetryIntervalKey, healthcheckNotifyRetryInterval)
+	}
+	if healthcheckNotifyTimeout != constant.DefaultRandomInt {
+		viper.Set(config.HealthcheckNotifyTimeoutKey, healthcheckNotifyTimeout)
+	}
+	if healthcheckNotifySMTPAddr != constant.DefaultRandomString {
+		viper.Set(config.HealthcheckNotifySMTPAddrKey, healthcheckNotifySMTPAddr)
+	}
+	if healthcheckNotifySMTPUser != constant.DefaultRandomString {
+		viper.Set(config.HealthcheckNotifySMTPUserKey, healthcheckNotifySMTPUser)
+	}
+	if healthcheckNotifySMTPPass != constant.DefaultRandomString {
+		viper.Set(config.HealthcheckNotifySMTPPassKey, healthcheckNotifySMTPPass)
+	}
+	if healthcheckNotifySMTPFrom != constant.DefaultRandomString {
+		viper.Set(config.HealthcheckNotifySMTPFromKey, healthcheckNotifySMTPFrom)
+	}
+	if healthcheckNotifyFileDir != constant.DefaultRandomString {
+		viper.Set(config.HealthcheckNotifyFileDirKey, healthcheckNotifyFileDir)
+	}
+	if healthcheckMiddlewareAdminPort != constant.DefaultRandomInt {
+		viper.Set(config.HealthcheckMiddlewareAdminPortKey, healthcheckMiddlewareAdminPort)
+	}
+	if healthcheckMiddlewareAdminUser != constant.DefaultRandomString {
+		viper.Set(config.HealthcheckMiddlewareAdminUserKey, healthcheckMiddlewareAdminUser)
+	}
+	if healthcheckMiddlewareAdminPass != constant.DefaultRandomString {
+		viper.Set(config.HealthcheckMiddlewareAdminPassKey, healthcheckMiddlewareAdminPass)
+	}
+
+	// override capacity
+	if capacityCollectorInterval != constant.DefaultRandomInt {
+		viper.Set(config.CapacityCollectorIntervalKey, capacityCollectorInterval)
+	}
+	if capacityCollectorRetention != constant.DefaultRandomInt {
+		viper.Set(config.CapacityCollectorRetentionKey, capacityCollectorRetention)
+	}
+
 	// validate configuration
 	err = config.ValidateConfig()
 	if err != nil {
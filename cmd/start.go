@@ -29,6 +29,8 @@ import (
 
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/app/capacity"
+	"github.com/romberli/das/internal/app/healthcheck"
 	"github.com/romberli/das/pkg/message"
 	"github.com/romberli/das/server"
 )
@@ -112,6 +114,12 @@ var startCmd = &cobra.Command{
 				os.Exit(constant.DefaultAbnormalExitCode)
 			}
 
+			// start healthcheck scheduler
+			healthcheck.NewSchedulerWithDefault().Start()
+
+			// start table size collector
+			capacity.NewCollectorWithDefault().Start()
+
 			// start server
 			serverAddr = viper.GetString(config.ServerAddrKey)
 			serverPidFile = viper.GetString(config.ServerPidFileKey)
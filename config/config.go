@@ -84,6 +84,23 @@ func SetDefaultConfig(baseDir string) {
 	viper.SetDefault(SQLAdvisorSoarProfilingKey, false)
 	viper.SetDefault(SQLAdvisorSoarTraceKey, false)
 	viper.SetDefault(SQLAdvisorSoarExplainKey, false)
+	// healthcheck
+	viper.SetDefault(HealthcheckItemTimeoutKey, DefaultHealthcheckItemTimeout)
+	viper.SetDefault(HealthcheckNotifyRetryCountKey, DefaultHealthcheckNotifyRetryCount)
+	viper.SetDefault(HealthcheckNotifyRetryIntervalKey, DefaultHealthcheckNotifyRetryInterval)
+	viper.SetDefault(HealthcheckNotifyTimeoutKey, DefaultHealthcheckNotifyTimeout)
+	viper.SetDefault(HealthcheckNotifySMTPAddrKey, DefaultHealthcheckNotifySMTPAddr)
+	viper.SetDefault(HealthcheckNotifySMTPUserKey, DefaultHealthcheckNotifySMTPUser)
+	viper.SetDefault(HealthcheckNotifySMTPPassKey, DefaultHealthcheckNotifySMTPPass)
+	viper.SetDefault(HealthcheckNotifySMTPFromKey, DefaultHealthcheckNotifySMTPFrom)
+	defaultNotifyFileDir := filepath.Join(baseDir, DefaultHealthcheckNotifyFileDir)
+	viper.SetDefault(HealthcheckNotifyFileDirKey, defaultNotifyFileDir)
+	viper.SetDefault(HealthcheckMiddlewareAdminPortKey, DefaultHealthcheckMiddlewareAdminPort)
+	viper.SetDefault(HealthcheckMiddlewareAdminUserKey, DefaultHealthcheckMiddlewareAdminUser)
+	viper.SetDefault(HealthcheckMiddlewareAdminPassKey, DefaultHealthcheckMiddlewareAdminPass)
+	// capacity
+	viper.SetDefault(CapacityCollectorIntervalKey, DefaultCapacityCollectorInterval)
+	viper.SetDefault(CapacityCollectorRetentionKey, DefaultCapacityCollectorRetention)
 }
 
 // ValidateConfig validates if the configuration is valid
@@ -120,6 +137,18 @@ func ValidateConfig() (err error) {
 		merr = multierror.Append(merr, err)
 	}
 
+	// validate healthcheck section
+	err = ValidateHealthcheck()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
+	// validate capacity section
+	err = ValidateCapacity()
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
 	return merr.ErrorOrNil()
 }
 
@@ -492,6 +521,90 @@ func ValidateSQLAdvisor() error {
 	return merr.ErrorOrNil()
 }
 
+// ValidateHealthcheck validates if healthcheck section is valid
+func ValidateHealthcheck() error {
+	merr := &multierror.Error{}
+
+	// validate healthcheck.itemTimeout
+	itemTimeout, err := cast.ToIntE(viper.Get(HealthcheckItemTimeoutKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if itemTimeout < MinHealthcheckItemTimeout || itemTimeout > MaxHealthcheckItemTimeout {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidHealthcheckItemTimeout].Renew(MinHealthcheckItemTimeout, MaxHealthcheckItemTimeout, itemTimeout))
+	}
+	// validate healthcheck.notify.retryCount
+	notifyRetryCount, err := cast.ToIntE(viper.Get(HealthcheckNotifyRetryCountKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if notifyRetryCount < MinHealthcheckNotifyRetryCount || notifyRetryCount > MaxHealthcheckNotifyRetryCount {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidNotifyRetryCount].Renew(MinHealthcheckNotifyRetryCount, MaxHealthcheckNotifyRetryCount, notifyRetryCount))
+	}
+	// validate healthcheck.notify.retryInterval
+	notifyRetryInterval, err := cast.ToIntE(viper.Get(HealthcheckNotifyRetryIntervalKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if notifyRetryInterval < MinHealthcheckNotifyRetryInterval || notifyRetryInterval > MaxHealthcheckNotifyRetryInterval {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidNotifyRetryInterval].Renew(MinHealthcheckNotifyRetryInterval, MaxHealthcheckNotifyRetryInterval, notifyRetryInterval))
+	}
+	// validate healthcheck.notify.timeout
+	notifyTimeout, err := cast.ToIntE(viper.Get(HealthcheckNotifyTimeoutKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if notifyTimeout < MinHealthcheckNotifyTimeout || notifyTimeout > MaxHealthcheckNotifyTimeout {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidNotifyTimeout].Renew(MinHealthcheckNotifyTimeout, MaxHealthcheckNotifyTimeout, notifyTimeout))
+	}
+	// validate healthcheck.notify.smtp
+	smtpAddr, err := cast.ToStringE(viper.Get(HealthcheckNotifySMTPAddrKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	_, err = cast.ToStringE(viper.Get(HealthcheckNotifySMTPUserKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	_, err = cast.ToStringE(viper.Get(HealthcheckNotifySMTPPassKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	smtpFrom, err := cast.ToStringE(viper.Get(HealthcheckNotifySMTPFromKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if smtpAddr != constant.EmptyString && smtpFrom == constant.EmptyString {
+		merr = multierror.Append(merr, message.Messages[message.ErrEmptyNotifySMTPFrom])
+	}
+	// validate healthcheck.notify.file
+	notifyFileDir, err := cast.ToStringE(viper.Get(HealthcheckNotifyFileDirKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if strings.TrimSpace(notifyFileDir) == constant.EmptyString {
+		merr = multierror.Append(merr, message.Messages[message.ErrEmptyNotifyFileDir])
+	}
+	// validate healthcheck.middleware.admin
+	middlewareAdminPort, err := cast.ToIntE(viper.Get(HealthcheckMiddlewareAdminPortKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if middlewareAdminPort < MinHealthcheckMiddlewareAdminPort || middlewareAdminPort > MaxHealthcheckMiddlewareAdminPort {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidMiddlewareAdminPort].Renew(MinHealthcheckMiddlewareAdminPort, MaxHealthcheckMiddlewareAdminPort, middlewareAdminPort))
+	}
+	_, err = cast.ToStringE(viper.Get(HealthcheckMiddlewareAdminUserKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	_, err = cast.ToStringE(viper.Get(HealthcheckMiddlewareAdminPassKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+
+	return merr.ErrorOrNil()
+}
+
 // TrimSpaceOfArg trims spaces of given argument
 func TrimSpaceOfArg(arg string) string {
 	args := strings.SplitN(arg, "=", 2)
@@ -507,3 +620,27 @@ func TrimSpaceOfArg(arg string) string {
 		return arg
 	}
 }
+
+// ValidateCapacity validates if capacity section is valid
+func ValidateCapacity() error {
+	merr := &multierror.Error{}
+
+	// validate capacity.collector.interval
+	collectorInterval, err := cast.ToIntE(viper.Get(CapacityCollectorIntervalKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if collectorInterval < MinCapacityCollectorInterval || collectorInterval > MaxCapacityCollectorInterval {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidCapacityCollectorInterval].Renew(MinCapacityCollectorInterval, MaxCapacityCollectorInterval, collectorInterval))
+	}
+	// validate capacity.collector.retention
+	collectorRetention, err := cast.ToIntE(viper.Get(CapacityCollectorRetentionKey))
+	if err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if collectorRetention < MinCapacityCollectorRetention || collectorRetention > MaxCapacityCollectorRetention {
+		merr = multierror.Append(merr, message.Messages[message.ErrNotValidCapacityCollectorRetention].Renew(MinCapacityCollectorRetention, MaxCapacityCollectorRetention, collectorRetention))
+	}
+
+	return merr.ErrorOrNil()
+}
@@ -67,6 +67,37 @@ const (
 	DefaultSQLAdvisorSoarBin       = "./soar"
 	DefaultSQLAdvisorSoarConfig    = "./soar.yaml"
 	DefaultSQLAdvisorSoarBlacklist = "./soar.blacklist"
+	DefaultHealthcheckItemTimeout  = 60
+	MinHealthcheckItemTimeout      = 1
+	MaxHealthcheckItemTimeout      = 3600
+	// healthcheck notify
+	DefaultHealthcheckNotifyRetryCount    = 3
+	MinHealthcheckNotifyRetryCount        = 0
+	MaxHealthcheckNotifyRetryCount        = 10
+	DefaultHealthcheckNotifyRetryInterval = 5
+	MinHealthcheckNotifyRetryInterval     = 1
+	MaxHealthcheckNotifyRetryInterval     = 300
+	DefaultHealthcheckNotifyTimeout       = 10
+	MinHealthcheckNotifyTimeout           = 1
+	MaxHealthcheckNotifyTimeout           = 300
+	DefaultHealthcheckNotifySMTPAddr      = ""
+	DefaultHealthcheckNotifySMTPUser      = ""
+	DefaultHealthcheckNotifySMTPPass      = ""
+	DefaultHealthcheckNotifySMTPFrom      = ""
+	DefaultHealthcheckNotifyFileDir       = "./notify"
+	// healthcheck middleware
+	DefaultHealthcheckMiddlewareAdminPort = 6032
+	MinHealthcheckMiddlewareAdminPort     = 0
+	MaxHealthcheckMiddlewareAdminPort     = 65535
+	DefaultHealthcheckMiddlewareAdminUser = "admin"
+	DefaultHealthcheckMiddlewareAdminPass = "admin"
+	// capacity collector
+	DefaultCapacityCollectorInterval  = 3600
+	MinCapacityCollectorInterval      = 0
+	MaxCapacityCollectorInterval      = 604800
+	DefaultCapacityCollectorRetention = 90
+	MinCapacityCollectorRetention     = 0
+	MaxCapacityCollectorRetention     = 3650
 )
 
 // configuration constant
@@ -116,4 +147,22 @@ const (
 	SQLAdvisorSoarProfilingKey = "sqladvisor.soar.profiling"
 	SQLAdvisorSoarTraceKey     = "sqladvisor.soar.trace"
 	SQLAdvisorSoarExplainKey   = "sqladvisor.soar.explain"
+	// healthcheck
+	HealthcheckItemTimeoutKey = "healthcheck.itemTimeout"
+	// healthcheck notify
+	HealthcheckNotifyRetryCountKey    = "healthcheck.notify.retryCount"
+	HealthcheckNotifyRetryIntervalKey = "healthcheck.notify.retryInterval"
+	HealthcheckNotifyTimeoutKey       = "healthcheck.notify.timeout"
+	HealthcheckNotifySMTPAddrKey      = "healthcheck.notify.smtp.addr"
+	HealthcheckNotifySMTPUserKey      = "healthcheck.notify.smtp.user"
+	HealthcheckNotifySMTPPassKey      = "healthcheck.notify.smtp.pass"
+	HealthcheckNotifySMTPFromKey      = "healthcheck.notify.smtp.from"
+	HealthcheckNotifyFileDirKey       = "healthcheck.notify.file.dir"
+	// healthcheck middleware
+	HealthcheckMiddlewareAdminPortKey = "healthcheck.middleware.admin.port"
+	HealthcheckMiddlewareAdminUserKey = "healthcheck.middleware.admin.user"
+	HealthcheckMiddlewareAdminPassKey = "healthcheck.middleware.admin.pass"
+	// capacity collector
+	CapacityCollectorIntervalKey  = "capacity.collector.interval"
+	CapacityCollectorRetentionKey = "capacity.collector.retention"
 )
@@ -1,14 +1,15 @@
 package global
 
 import (
+	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/go-util/middleware/mysql"
 	"github.com/romberli/log"
 	"github.com/spf13/viper"
 )
 
-var DASMySQLPool *mysql.Pool
+var DASMySQLPool middleware.Pool
 
-func InitDASMySQLPool() (err error) {
+func InitDASMySQLPool() error {
 	dbAddr := viper.GetString("db.das.mysql.addr")
 	dbName := viper.GetString("db.das.mysql.name")
 	dbUser := viper.GetString("db.das.mysql.user")
@@ -22,7 +23,12 @@ func InitDASMySQLPool() (err error) {
 	config := mysql.NewConfig(dbAddr, dbName, dbUser, dbPass)
 	poolConfig := mysql.NewPoolConfigWithConfig(config, maxConnections, initConnections, maxIdleConnections, maxIdleTime, keepAliveInterval)
 	log.Debugf("pool config: %v", poolConfig)
-	DASMySQLPool, err = mysql.NewPoolWithPoolConfig(poolConfig)
+	pool, err := mysql.NewPoolWithPoolConfig(poolConfig)
+	if err != nil {
+		return err
+	}
+	// assign only a created pool, a nil *mysql.Pool would make the global pool a non-nil interface
+	DASMySQLPool = pool
 
-	return err
+	return nil
 }
@@ -0,0 +1,127 @@
+package capacity
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/capacity"
+	"github.com/romberli/das/pkg/message"
+	msgcap "github.com/romberli/das/pkg/message/capacity"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+)
+
+// Collector takes the table size snapshots of all the registered mysql servers periodically,
+// a mysql server is skipped if it has been snapshotted within half of the interval,
+// so that multiple das servers or the on-demand snapshots of the forecast will not cause redundant snapshots,
+// the snapshots which are older than the retention are purged
+type Collector struct {
+	capacity.DASRepo
+	interval  time.Duration
+	retention time.Duration
+	stopOnce  sync.Once
+	stopChan  chan struct{}
+}
+
+// NewCollector returns a new *Collector, the snapshots are kept forever if retention is 0
+func NewCollector(repo capacity.DASRepo, interval, retention time.Duration) *Collector {
+	return &Collector{
+		DASRepo:   repo,
+		interval:  interval,
+		retention: retention,
+		stopChan:  make(chan struct{}),
+	}
+}
+
+// NewCollectorWithDefault returns a new *Collector with default repository, the interval and retention are read from the config
+func NewCollectorWithDefault() *Collector {
+	return NewCollector(NewDASRepoWithGlobal(),
+		time.Duration(viper.GetInt(config.CapacityCollectorIntervalKey))*time.Second,
+		time.Duration(viper.GetInt(config.CapacityCollectorRetentionKey))*24*time.Hour)
+}
+
+// Start starts the collector asynchronously, it does nothing if the interval is 0
+func (c *Collector) Start() {
+	if c.interval <= constant.ZeroInt {
+		log.Info(message.NewMessage(msgcap.InfoCapacityCollectorDisabled).Error())
+		return
+	}
+
+	go c.loop()
+}
+
+// Stop stops the collector, the snapshot which is being taken will not be affected
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stopChan)
+	})
+}
+
+// loop collects the snapshots every interval until the collector is stopped
+func (c *Collector) loop() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		c.collect(time.Now())
+
+		select {
+		case <-c.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect purges the expired snapshots and takes the snapshots of the mysql servers one by one,
+// the failure of a mysql server will not affect the others
+func (c *Collector) collect(now time.Time) {
+	if c.retention > constant.ZeroInt {
+		err := c.DASRepo.DeleteTableSizesBefore(now.Add(-c.retention))
+		if err != nil {
+			log.Error(message.NewMessage(msgcap.ErrCapacityCollectorPurge, err.Error()).Error())
+		}
+	}
+
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetAll()
+	if err != nil {
+		log.Error(message.NewMessage(msgcap.ErrCapacityCollectorLoad, err.Error()).Error())
+		return
+	}
+
+	s := NewService(c.DASRepo)
+	collectedNum := constant.ZeroInt
+	for _, mysqlServer := range mysqlServerService.GetMySQLServers() {
+		isDue, err := c.isDue(mysqlServer.Identity(), now)
+		if err != nil {
+			log.Error(message.NewMessage(msgcap.ErrCapacitySnapshotTableSizes, mysqlServer.Identity(), err.Error()).Error())
+			continue
+		}
+		if !isDue {
+			continue
+		}
+		err = s.snapshotTableSizes(mysqlServer.Identity(), fmt.Sprintf("%s:%d", mysqlServer.GetHostIP(), mysqlServer.GetPortNum()))
+		if err != nil {
+			log.Error(message.NewMessage(msgcap.ErrCapacitySnapshotTableSizes, mysqlServer.Identity(), err.Error()).Error())
+			continue
+		}
+		collectedNum++
+	}
+
+	log.Info(message.NewMessage(msgcap.InfoCapacityCollectorCompleted, collectedNum, len(mysqlServerService.GetMySQLServers())).Error())
+}
+
+// isDue returns if the mysql server has not been snapshotted within half of the interval
+func (c *Collector) isDue(mysqlServerID int, now time.Time) (bool, error) {
+	latestSnapshotTime, err := c.DASRepo.GetLatestSnapshotTime(mysqlServerID)
+	if err != nil {
+		return false, err
+	}
+
+	return now.Sub(latestSnapshotTime) >= c.interval/2, nil
+}
@@ -0,0 +1,285 @@
+package capacity
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/capacity"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	// noDaysToFull means the usage never reaches the limit as it does not grow
+	noDaysToFull = -1
+	// defaultFullUsage is the usage ratio of a full mount point
+	defaultFullUsage = 1.0
+	// minDiskSampleNum is the minimum number of the disk capacity usage samples to forecast
+	minDiskSampleNum = 3
+	// minTableSnapshotNum is the minimum number of the table size snapshots to compute the table growth
+	minTableSnapshotNum = 2
+	// defaultTopTableNum is the number of the fastest growing tables in the forecast
+	defaultTopTableNum = 10
+)
+
+var (
+	_ capacity.Forecast     = (*Forecast)(nil)
+	_ capacity.DiskForecast = (*DiskForecast)(nil)
+	_ capacity.TableGrowth  = (*TableGrowth)(nil)
+)
+
+// Forecast is the capacity forecast of a mysql server
+type Forecast struct {
+	MySQLServerID int             `json:"mysql_server_id"`
+	DiskForecasts []*DiskForecast `json:"disk_forecasts"`
+	TableGrowths  []*TableGrowth  `json:"table_growths"`
+}
+
+// NewForecast returns a new *Forecast
+func NewForecast(mysqlServerID int, diskForecasts []*DiskForecast, tableGrowths []*TableGrowth) *Forecast {
+	return &Forecast{
+		MySQLServerID: mysqlServerID,
+		DiskForecasts: diskForecasts,
+		TableGrowths:  tableGrowths,
+	}
+}
+
+// NewEmptyForecast returns a new *Forecast without any disk forecast or table growth
+func NewEmptyForecast() *Forecast {
+	return &Forecast{}
+}
+
+// GetMySQLServerID returns the mysql server id
+func (f *Forecast) GetMySQLServerID() int {
+	return f.MySQLServerID
+}
+
+// GetDiskForecasts returns the days-to-full predictions of the mount points
+func (f *Forecast) GetDiskForecasts() []capacity.DiskForecast {
+	diskForecasts := make([]capacity.DiskForecast, len(f.DiskForecasts))
+	for i := range f.DiskForecasts {
+		diskForecasts[i] = f.DiskForecasts[i]
+	}
+
+	return diskForecasts
+}
+
+// GetTableGrowths returns the growth of the tables which grow fastest
+func (f *Forecast) GetTableGrowths() []capacity.TableGrowth {
+	tableGrowths := make([]capacity.TableGrowth, len(f.TableGrowths))
+	for i := range f.TableGrowths {
+		tableGrowths[i] = f.TableGrowths[i]
+	}
+
+	return tableGrowths
+}
+
+// DiskForecast is the days-to-full prediction of a mount point,
+// the days are counted from the last sample, and the bounds are the 95% confidence interval
+type DiskForecast struct {
+	MountPoint      string  `json:"mount_point"`
+	SampleNum       int     `json:"sample_num"`
+	Seasonal        bool    `json:"seasonal"`
+	CurrentUsage    float64 `json:"current_usage"`
+	GrowthPerDay    float64 `json:"growth_per_day"`
+	DaysToFull      float64 `json:"days_to_full"`
+	DaysToFullLower float64 `json:"days_to_full_lower"`
+	DaysToFullUpper float64 `json:"days_to_full_upper"`
+	Message         string  `json:"message"`
+}
+
+// NewDiskForecastWithPrometheusData fits the trend of the disk capacity usage of the mount point and returns a new *DiskForecast,
+// if there are not enough samples, the days are -1 and the message explains the reason
+func NewDiskForecastWithPrometheusData(mountPoint string, data []healthcheck.PrometheusData) *DiskForecast {
+	df := &DiskForecast{
+		MountPoint:      mountPoint,
+		DaysToFull:      noDaysToFull,
+		DaysToFullLower: noDaysToFull,
+		DaysToFullUpper: noDaysToFull,
+	}
+
+	points := make([]point, constant.ZeroInt, len(data))
+	for _, pd := range data {
+		ts, err := parseTimestamp(pd.GetTimestamp())
+		if err != nil {
+			df.Message = err.Error()
+			return df
+		}
+		if math.IsNaN(pd.GetValue()) {
+			continue
+		}
+		points = append(points, newPoint(ts, pd.GetValue()))
+	}
+	df.SampleNum = len(points)
+	if df.SampleNum < minDiskSampleNum {
+		df.Message = fmt.Sprintf("capacity: forecasting needs at least %d samples, only %d samples found", minDiskSampleNum, df.SampleNum)
+		return df
+	}
+
+	t, err := fitTrend(points, true)
+	if err != nil {
+		df.Message = err.Error()
+		return df
+	}
+	df.Seasonal = t.isSeasonal
+	df.CurrentUsage = t.getLevel()
+	df.GrowthPerDay = t.slope
+	df.DaysToFull, df.DaysToFullLower, df.DaysToFullUpper = t.getDaysToReach(defaultFullUsage)
+
+	return df
+}
+
+// GetMountPoint returns the mount point
+func (df *DiskForecast) GetMountPoint() string {
+	return df.MountPoint
+}
+
+// GetCurrentUsage returns the current usage ratio of the mount point, it is between 0 and 1
+func (df *DiskForecast) GetCurrentUsage() float64 {
+	return df.CurrentUsage
+}
+
+// GetGrowthPerDay returns how much the usage ratio grows per day
+func (df *DiskForecast) GetGrowthPerDay() float64 {
+	return df.GrowthPerDay
+}
+
+// GetDaysToFull returns the predicted days until the mount point is full, it is -1 if the usage does not grow
+func (df *DiskForecast) GetDaysToFull() float64 {
+	return df.DaysToFull
+}
+
+// GetDaysToFullLower returns the lower confidence bound of the days to full
+func (df *DiskForecast) GetDaysToFullLower() float64 {
+	return df.DaysToFullLower
+}
+
+// GetDaysToFullUpper returns the upper confidence bound of the days to full, it is -1 if the usage may not grow
+func (df *DiskForecast) GetDaysToFullUpper() float64 {
+	return df.DaysToFullUpper
+}
+
+// TableGrowth is the growth of a table which is computed from the table size snapshots
+type TableGrowth struct {
+	DBName      string  `json:"db_name"`
+	TableName   string  `json:"table_name"`
+	TableRows   int64   `json:"table_rows"`
+	TableSize   int64   `json:"table_size"`
+	RowsPerDay  float64 `json:"rows_per_day"`
+	BytesPerDay float64 `json:"bytes_per_day"`
+}
+
+// GetDBName returns the db name
+func (tg *TableGrowth) GetDBName() string {
+	return tg.DBName
+}
+
+// GetTableName returns the table name
+func (tg *TableGrowth) GetTableName() string {
+	return tg.TableName
+}
+
+// GetTableRows returns the latest estimated row count of the table
+func (tg *TableGrowth) GetTableRows() int64 {
+	return tg.TableRows
+}
+
+// GetTableSize returns the latest size of the table in bytes, it contains both data and indexes
+func (tg *TableGrowth) GetTableSize() int64 {
+	return tg.TableSize
+}
+
+// GetRowsPerDay returns how many rows the table grows per day
+func (tg *TableGrowth) GetRowsPerDay() float64 {
+	return tg.RowsPerDay
+}
+
+// GetBytesPerDay returns how many bytes the table grows per day
+func (tg *TableGrowth) GetBytesPerDay() float64 {
+	return tg.BytesPerDay
+}
+
+// NewTableGrowthsWithTableSizes computes the linear growth of each table from the table size snapshots,
+// the tables with less than 2 snapshots are ignored, it returns at most top number of tables which grow fastest in bytes,
+// all the tables are returned if top is not positive
+func NewTableGrowthsWithTableSizes(tableSizes []capacity.TableSize, top int) []*TableGrowth {
+	type tableKey struct {
+		dbName    string
+		tableName string
+	}
+
+	var keys []tableKey
+	snapshots := make(map[tableKey][]capacity.TableSize)
+	for _, tableSize := range tableSizes {
+		key := tableKey{dbName: tableSize.GetDBName(), tableName: tableSize.GetTableName()}
+		if _, exists := snapshots[key]; !exists {
+			keys = append(keys, key)
+		}
+		snapshots[key] = append(snapshots[key], tableSize)
+	}
+
+	var tableGrowths []*TableGrowth
+	for _, key := range keys {
+		tableGrowth := newTableGrowth(snapshots[key])
+		if tableGrowth != nil {
+			tableGrowths = append(tableGrowths, tableGrowth)
+		}
+	}
+	sort.SliceStable(tableGrowths, func(i, j int) bool {
+		return tableGrowths[i].BytesPerDay > tableGrowths[j].BytesPerDay
+	})
+	if top > constant.ZeroInt && len(tableGrowths) > top {
+		tableGrowths = tableGrowths[:top]
+	}
+
+	return tableGrowths
+}
+
+// newTableGrowth computes the linear growth of a table from its snapshots, the snapshots are too sparse to fit the seasonal trend,
+// it returns nil if the growth could not be computed
+func newTableGrowth(snapshots []capacity.TableSize) *TableGrowth {
+	if len(snapshots) < minTableSnapshotNum {
+		return nil
+	}
+
+	rowPoints := make([]point, len(snapshots))
+	bytePoints := make([]point, len(snapshots))
+	latest := snapshots[constant.ZeroInt]
+	for i, snapshot := range snapshots {
+		rowPoints[i] = newPoint(snapshot.GetSnapshotTime(), float64(snapshot.GetTableRows()))
+		bytePoints[i] = newPoint(snapshot.GetSnapshotTime(), float64(snapshot.GetDataLength()+snapshot.GetIndexLength()))
+		if snapshot.GetSnapshotTime().After(latest.GetSnapshotTime()) {
+			latest = snapshot
+		}
+	}
+	rowTrend, err := fitTrend(rowPoints, false)
+	if err != nil {
+		return nil
+	}
+	byteTrend, err := fitTrend(bytePoints, false)
+	if err != nil {
+		return nil
+	}
+
+	return &TableGrowth{
+		DBName:      latest.GetDBName(),
+		TableName:   latest.GetTableName(),
+		TableRows:   latest.GetTableRows(),
+		TableSize:   latest.GetDataLength() + latest.GetIndexLength(),
+		RowsPerDay:  rowTrend.slope,
+		BytesPerDay: byteTrend.slope,
+	}
+}
+
+// parseTimestamp parses the timestamp of the prometheus data, it is the unix time in seconds with the fractional milliseconds
+func parseTimestamp(ts string) (time.Time, error) {
+	seconds, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("capacity: parse timestamp failed. timestamp: %s\n%s", ts, err.Error())
+	}
+
+	return time.Unix(constant.ZeroInt, int64(seconds*float64(time.Second))), nil
+}
@@ -0,0 +1,85 @@
+package capacity
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/internal/dependency/capacity"
+	dephc "github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForecastAll(t *testing.T) {
+	TestForecast_NewDiskForecastWithPrometheusData(t)
+	TestForecast_NewTableGrowthsWithTableSizes(t)
+	TestForecast_ParseTimestamp(t)
+}
+
+func TestForecast_NewDiskForecastWithPrometheusData(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	var data []dephc.PrometheusData
+	for i := 0; i < 24; i++ {
+		ts := strconv.FormatFloat(float64(start.Add(time.Duration(i)*time.Hour).Unix()), 'f', -1, 64)
+		data = append(data, healthcheck.NewPrometheusData(ts, 0.5+0.01*float64(i)/24))
+	}
+	df := NewDiskForecastWithPrometheusData("/data", data)
+	asst.Equal(24, df.SampleNum, "test NewDiskForecastWithPrometheusData() failed")
+	asst.InDelta(0.01, df.GetGrowthPerDay(), 1e-9, "test NewDiskForecastWithPrometheusData() failed")
+	asst.InDelta((1-df.GetCurrentUsage())/0.01, df.GetDaysToFull(), 1e-6, "test NewDiskForecastWithPrometheusData() failed")
+
+	// not enough samples
+	df = NewDiskForecastWithPrometheusData("/data", data[:2])
+	asst.Equal(float64(noDaysToFull), df.GetDaysToFull(), "test NewDiskForecastWithPrometheusData() failed")
+	asst.NotEmpty(df.Message, "test NewDiskForecastWithPrometheusData() failed")
+
+	// not growing
+	var flat []dephc.PrometheusData
+	for i := 0; i < 24; i++ {
+		ts := strconv.FormatFloat(float64(start.Add(time.Duration(i)*time.Hour).Unix()), 'f', -1, 64)
+		flat = append(flat, healthcheck.NewPrometheusData(ts, 0.5))
+	}
+	df = NewDiskForecastWithPrometheusData("/data", flat)
+	asst.Equal(float64(noDaysToFull), df.GetDaysToFull(), "test NewDiskForecastWithPrometheusData() failed")
+	asst.Equal(float64(noDaysToFull), df.GetDaysToFullUpper(), "test NewDiskForecastWithPrometheusData() failed")
+}
+
+func TestForecast_NewTableGrowthsWithTableSizes(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	var tableSizes []capacity.TableSize
+	for i := 0; i < 3; i++ {
+		snapshotTime := start.Add(time.Duration(i) * 24 * time.Hour)
+		tableSizes = append(tableSizes,
+			NewTableSize(1, "db1", "t01", 1000+100*int64(i), 1024+1024*int64(i), 0, snapshotTime),
+			NewTableSize(1, "db1", "t02", 1000+1000*int64(i), 1024+10240*int64(i), 1024, snapshotTime),
+		)
+	}
+	// only one snapshot
+	tableSizes = append(tableSizes, NewTableSize(1, "db1", "t03", 1000, 1024, 0, start))
+
+	tableGrowths := NewTableGrowthsWithTableSizes(tableSizes, defaultTopTableNum)
+	asst.Equal(2, len(tableGrowths), "test NewTableGrowthsWithTableSizes() failed")
+	asst.Equal("t02", tableGrowths[0].GetTableName(), "test NewTableGrowthsWithTableSizes() failed")
+	asst.InDelta(10240, tableGrowths[0].GetBytesPerDay(), 1e-6, "test NewTableGrowthsWithTableSizes() failed")
+	asst.InDelta(1000, tableGrowths[0].GetRowsPerDay(), 1e-6, "test NewTableGrowthsWithTableSizes() failed")
+	asst.Equal(int64(3000), tableGrowths[0].GetTableRows(), "test NewTableGrowthsWithTableSizes() failed")
+	asst.Equal(int64(1024+10240*2+1024), tableGrowths[0].GetTableSize(), "test NewTableGrowthsWithTableSizes() failed")
+
+	tableGrowths = NewTableGrowthsWithTableSizes(tableSizes, 1)
+	asst.Equal(1, len(tableGrowths), "test NewTableGrowthsWithTableSizes() failed")
+}
+
+func TestForecast_ParseTimestamp(t *testing.T) {
+	asst := assert.New(t)
+
+	ts, err := parseTimestamp("1609430400.5")
+	asst.Nil(err, "test parseTimestamp() failed")
+	asst.Equal(int64(1609430400500), ts.UnixNano()/int64(time.Millisecond), "test parseTimestamp() failed")
+	_, err = parseTimestamp("abc")
+	asst.NotNil(err, "test parseTimestamp() failed")
+}
@@ -0,0 +1,194 @@
+package capacity
+
+import (
+	"time"
+
+	"github.com/romberli/das/internal/dependency/capacity"
+	"github.com/romberli/go-util/constant"
+)
+
+var (
+	_ capacity.DBGrowth  = (*DBGrowth)(nil)
+	_ capacity.AppGrowth = (*AppGrowth)(nil)
+)
+
+// DBGrowth is the growth of a db, the size of the db is the sum of the sizes of its tables at each snapshot
+type DBGrowth struct {
+	DBID          int            `json:"db_id"`
+	DBName        string         `json:"db_name"`
+	MySQLServerID int            `json:"mysql_server_id"`
+	TableRows     int64          `json:"table_rows"`
+	TableSize     int64          `json:"table_size"`
+	RowsPerDay    float64        `json:"rows_per_day"`
+	BytesPerDay   float64        `json:"bytes_per_day"`
+	TableGrowths  []*TableGrowth `json:"table_growths"`
+}
+
+// NewDBGrowthWithTableSizes computes the growth of the db from the table size snapshots of the mysql server,
+// the growth is 0 if there are less than 2 snapshots
+func NewDBGrowthWithTableSizes(dbID int, dbName string, mysqlServerID int, tableSizes []capacity.TableSize) *DBGrowth {
+	dg := &DBGrowth{
+		DBID:          dbID,
+		DBName:        dbName,
+		MySQLServerID: mysqlServerID,
+		TableGrowths:  NewTableGrowthsWithTableSizes(tableSizes, defaultTopTableNum),
+	}
+
+	// sum up the tables of each snapshot, the snapshots are keyed by the unix nano of the snapshot time
+	var snapshotTimes []time.Time
+	rows := make(map[int64]int64)
+	sizes := make(map[int64]int64)
+	for _, tableSize := range tableSizes {
+		key := tableSize.GetSnapshotTime().UnixNano()
+		if _, exists := rows[key]; !exists {
+			snapshotTimes = append(snapshotTimes, tableSize.GetSnapshotTime())
+		}
+		rows[key] += tableSize.GetTableRows()
+		sizes[key] += tableSize.GetDataLength() + tableSize.GetIndexLength()
+	}
+	if len(snapshotTimes) == constant.ZeroInt {
+		return dg
+	}
+
+	rowPoints := make([]point, len(snapshotTimes))
+	bytePoints := make([]point, len(snapshotTimes))
+	latest := snapshotTimes[constant.ZeroInt]
+	for i, snapshotTime := range snapshotTimes {
+		rowPoints[i] = newPoint(snapshotTime, float64(rows[snapshotTime.UnixNano()]))
+		bytePoints[i] = newPoint(snapshotTime, float64(sizes[snapshotTime.UnixNano()]))
+		if snapshotTime.After(latest) {
+			latest = snapshotTime
+		}
+	}
+	dg.TableRows = rows[latest.UnixNano()]
+	dg.TableSize = sizes[latest.UnixNano()]
+	if len(snapshotTimes) < minTableSnapshotNum {
+		return dg
+	}
+
+	rowTrend, err := fitTrend(rowPoints, false)
+	if err != nil {
+		return dg
+	}
+	byteTrend, err := fitTrend(bytePoints, false)
+	if err != nil {
+		return dg
+	}
+	dg.RowsPerDay = rowTrend.slope
+	dg.BytesPerDay = byteTrend.slope
+
+	return dg
+}
+
+// GetDBID returns the db id
+func (dg *DBGrowth) GetDBID() int {
+	return dg.DBID
+}
+
+// GetDBName returns the db name
+func (dg *DBGrowth) GetDBName() string {
+	return dg.DBName
+}
+
+// GetMySQLServerID returns the id of the mysql server whose snapshots are used
+func (dg *DBGrowth) GetMySQLServerID() int {
+	return dg.MySQLServerID
+}
+
+// GetTableRows returns the latest estimated row count of the db
+func (dg *DBGrowth) GetTableRows() int64 {
+	return dg.TableRows
+}
+
+// GetTableSize returns the latest size of the db in bytes
+func (dg *DBGrowth) GetTableSize() int64 {
+	return dg.TableSize
+}
+
+// GetRowsPerDay returns how many rows the db grows per day
+func (dg *DBGrowth) GetRowsPerDay() float64 {
+	return dg.RowsPerDay
+}
+
+// GetBytesPerDay returns how many bytes the db grows per day
+func (dg *DBGrowth) GetBytesPerDay() float64 {
+	return dg.BytesPerDay
+}
+
+// GetTableGrowths returns the growth of the tables of the db which grow fastest
+func (dg *DBGrowth) GetTableGrowths() []capacity.TableGrowth {
+	tableGrowths := make([]capacity.TableGrowth, len(dg.TableGrowths))
+	for i := range dg.TableGrowths {
+		tableGrowths[i] = dg.TableGrowths[i]
+	}
+
+	return tableGrowths
+}
+
+// AppGrowth is the growth of an app, it is the sum of the growth of the dbs of the app
+type AppGrowth struct {
+	AppID       int         `json:"app_id"`
+	AppName     string      `json:"app_name"`
+	TableRows   int64       `json:"table_rows"`
+	TableSize   int64       `json:"table_size"`
+	RowsPerDay  float64     `json:"rows_per_day"`
+	BytesPerDay float64     `json:"bytes_per_day"`
+	DBGrowths   []*DBGrowth `json:"db_growths"`
+}
+
+// NewAppGrowthWithDBGrowths returns a new *AppGrowth which sums up the growth of the dbs
+func NewAppGrowthWithDBGrowths(appID int, appName string, dbGrowths []*DBGrowth) *AppGrowth {
+	ag := &AppGrowth{
+		AppID:     appID,
+		AppName:   appName,
+		DBGrowths: dbGrowths,
+	}
+	for _, dbGrowth := range dbGrowths {
+		ag.TableRows += dbGrowth.TableRows
+		ag.TableSize += dbGrowth.TableSize
+		ag.RowsPerDay += dbGrowth.RowsPerDay
+		ag.BytesPerDay += dbGrowth.BytesPerDay
+	}
+
+	return ag
+}
+
+// GetAppID returns the app id
+func (ag *AppGrowth) GetAppID() int {
+	return ag.AppID
+}
+
+// GetAppName returns the app name
+func (ag *AppGrowth) GetAppName() string {
+	return ag.AppName
+}
+
+// GetTableRows returns the latest estimated row count of the app
+func (ag *AppGrowth) GetTableRows() int64 {
+	return ag.TableRows
+}
+
+// GetTableSize returns the latest size of the app in bytes
+func (ag *AppGrowth) GetTableSize() int64 {
+	return ag.TableSize
+}
+
+// GetRowsPerDay returns how many rows the app grows per day
+func (ag *AppGrowth) GetRowsPerDay() float64 {
+	return ag.RowsPerDay
+}
+
+// GetBytesPerDay returns how many bytes the app grows per day
+func (ag *AppGrowth) GetBytesPerDay() float64 {
+	return ag.BytesPerDay
+}
+
+// GetDBGrowths returns the growth of the dbs of the app
+func (ag *AppGrowth) GetDBGrowths() []capacity.DBGrowth {
+	dbGrowths := make([]capacity.DBGrowth, len(ag.DBGrowths))
+	for i := range ag.DBGrowths {
+		dbGrowths[i] = ag.DBGrowths[i]
+	}
+
+	return dbGrowths
+}
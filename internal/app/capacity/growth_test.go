@@ -0,0 +1,60 @@
+package capacity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/capacity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrowthAll(t *testing.T) {
+	TestGrowth_NewDBGrowthWithTableSizes(t)
+	TestGrowth_NewAppGrowthWithDBGrowths(t)
+}
+
+func TestGrowth_NewDBGrowthWithTableSizes(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	var tableSizes []capacity.TableSize
+	for i := 0; i < 3; i++ {
+		snapshotTime := start.Add(time.Duration(i) * 24 * time.Hour)
+		tableSizes = append(tableSizes,
+			NewTableSize(1, "db1", "t01", 1000+100*int64(i), 1024+1024*int64(i), 0, snapshotTime),
+			NewTableSize(1, "db1", "t02", 1000+1000*int64(i), 1024+10240*int64(i), 1024, snapshotTime),
+		)
+	}
+
+	dg := NewDBGrowthWithTableSizes(1, "db1", 1, tableSizes)
+	asst.Equal(int64(1200+3000), dg.GetTableRows(), "test NewDBGrowthWithTableSizes() failed")
+	asst.Equal(int64(1024+2048+1024+20480+1024), dg.GetTableSize(), "test NewDBGrowthWithTableSizes() failed")
+	asst.InDelta(1100, dg.GetRowsPerDay(), 1e-6, "test NewDBGrowthWithTableSizes() failed")
+	asst.InDelta(11264, dg.GetBytesPerDay(), 1e-6, "test NewDBGrowthWithTableSizes() failed")
+	asst.Equal(2, len(dg.GetTableGrowths()), "test NewDBGrowthWithTableSizes() failed")
+
+	// only one snapshot
+	dg = NewDBGrowthWithTableSizes(1, "db1", 1, tableSizes[:2])
+	asst.Equal(int64(2000), dg.GetTableRows(), "test NewDBGrowthWithTableSizes() failed")
+	asst.Equal(float64(0), dg.GetBytesPerDay(), "test NewDBGrowthWithTableSizes() failed")
+
+	// no snapshot
+	dg = NewDBGrowthWithTableSizes(1, "db1", 1, nil)
+	asst.Equal(int64(0), dg.GetTableSize(), "test NewDBGrowthWithTableSizes() failed")
+}
+
+func TestGrowth_NewAppGrowthWithDBGrowths(t *testing.T) {
+	asst := assert.New(t)
+
+	dbGrowths := []*DBGrowth{
+		{DBID: 1, DBName: "db1", TableRows: 100, TableSize: 1024, RowsPerDay: 10, BytesPerDay: 100},
+		{DBID: 2, DBName: "db2", TableRows: 200, TableSize: 2048, RowsPerDay: 20, BytesPerDay: 200},
+	}
+	ag := NewAppGrowthWithDBGrowths(1, "app1", dbGrowths)
+	asst.Equal("app1", ag.GetAppName(), "test NewAppGrowthWithDBGrowths() failed")
+	asst.Equal(int64(300), ag.GetTableRows(), "test NewAppGrowthWithDBGrowths() failed")
+	asst.Equal(int64(3072), ag.GetTableSize(), "test NewAppGrowthWithDBGrowths() failed")
+	asst.Equal(float64(30), ag.GetRowsPerDay(), "test NewAppGrowthWithDBGrowths() failed")
+	asst.Equal(float64(300), ag.GetBytesPerDay(), "test NewAppGrowthWithDBGrowths() failed")
+	asst.Equal(2, len(ag.GetDBGrowths()), "test NewAppGrowthWithDBGrowths() failed")
+}
@@ -0,0 +1,274 @@
+package capacity
+
+import (
+	"time"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/capacity"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/log"
+)
+
+const (
+	applicationMySQLTableSizes = `
+		select table_schema as db_name,
+			   table_name,
+			   ifnull(table_rows, 0) as table_rows,
+			   ifnull(data_length, 0) as data_length,
+			   ifnull(index_length, 0) as index_length
+		from information_schema.tables
+		where table_type = 'BASE TABLE'
+		  and table_schema not in ('mysql', 'sys', 'information_schema', 'performance_schema')
+		order by table_schema, table_name;
+	`
+)
+
+var (
+	_ capacity.DASRepo              = (*DASRepo)(nil)
+	_ capacity.ApplicationMySQLRepo = (*ApplicationMySQLRepo)(nil)
+)
+
+// DASRepo for capacity
+type DASRepo struct {
+	Database middleware.Pool
+}
+
+// NewDASRepo returns *DASRepo with given middleware.Pool
+func NewDASRepo(db middleware.Pool) *DASRepo {
+	return newDASRepo(db)
+}
+
+// NewDASRepoWithGlobal returns *DASRepo with global mysql pool
+func NewDASRepoWithGlobal() *DASRepo {
+	return NewDASRepo(global.DASMySQLPool)
+}
+
+// newDASRepo returns *DASRepo with given middleware.Pool
+func newDASRepo(db middleware.Pool) *DASRepo {
+	return &DASRepo{Database: db}
+}
+
+// Execute executes given command and placeholders on the middleware
+func (dr *DASRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	conn, err := dr.Database.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("capacity DASRepo.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	return conn.Execute(command, args...)
+}
+
+// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+func (dr *DASRepo) Transaction() (middleware.Transaction, error) {
+	return dr.Database.Transaction()
+}
+
+// SaveTableSizes saves the table size snapshot of the mysql server in the middleware,
+// all the tables of the snapshot are saved in a transaction, so that the snapshot is either complete or absent
+func (dr *DASRepo) SaveTableSizes(mysqlServerID int, snapshotTime time.Time, tableSizes []capacity.TableSize) error {
+	tx, err := dr.Transaction()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = tx.Close()
+		if err != nil {
+			log.Errorf("capacity DASRepo.SaveTableSizes(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	err = tx.Begin()
+	if err != nil {
+		return err
+	}
+	sql := `
+		insert into t_cap_table_snapshot(mysql_server_id, db_name, table_name, table_rows, data_length, index_length, snapshot_time)
+		values(?, ?, ?, ?, ?, ?, ?);
+	`
+	for _, tableSize := range tableSizes {
+		log.Debugf("capacity DASRepo.SaveTableSizes() insert sql: \n%s\nplaceholders: %d, %s, %s, %d, %d, %d, %s",
+			sql, mysqlServerID, tableSize.GetDBName(), tableSize.GetTableName(), tableSize.GetTableRows(),
+			tableSize.GetDataLength(), tableSize.GetIndexLength(), snapshotTime.Format(constant.DefaultTimeLayout))
+		_, err = tx.Execute(sql, mysqlServerID, tableSize.GetDBName(), tableSize.GetTableName(), tableSize.GetTableRows(),
+			tableSize.GetDataLength(), tableSize.GetIndexLength(), snapshotTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetTableSizesByMySQLServerID gets the table size snapshots of the mysql server which were taken in given time range
+func (dr *DASRepo) GetTableSizesByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) ([]capacity.TableSize, error) {
+	sql := `
+		select mysql_server_id, db_name, table_name, table_rows, data_length, index_length, snapshot_time
+		from t_cap_table_snapshot
+		where del_flag = 0
+		and mysql_server_id = ?
+		and snapshot_time >= ?
+		and snapshot_time <= ?
+		order by snapshot_time;
+	`
+	log.Debugf("capacity DASRepo.GetTableSizesByMySQLServerID() select sql: \n%s\nplaceholders: %d, %s, %s",
+		sql, mysqlServerID, startTime.Format(constant.DefaultTimeLayout), endTime.Format(constant.DefaultTimeLayout))
+
+	result, err := dr.Execute(sql, mysqlServerID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	// init []*TableSize
+	tableSizeList := make([]*TableSize, result.RowNumber())
+	for i := range tableSizeList {
+		tableSizeList[i] = NewEmptyTableSize()
+	}
+	// map to struct
+	err = result.MapToStructSlice(tableSizeList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []capacity.TableSize
+	tableSizes := make([]capacity.TableSize, len(tableSizeList))
+	for i := range tableSizes {
+		tableSizes[i] = tableSizeList[i]
+	}
+
+	return tableSizes, nil
+}
+
+// GetTableSizesByDBName gets the table size snapshots of the db on the mysql server which were taken in given time range
+func (dr *DASRepo) GetTableSizesByDBName(mysqlServerID int, dbName string, startTime, endTime time.Time) ([]capacity.TableSize, error) {
+	sql := `
+		select mysql_server_id, db_name, table_name, table_rows, data_length, index_length, snapshot_time
+		from t_cap_table_snapshot
+		where del_flag = 0
+		and mysql_server_id = ?
+		and db_name = ?
+		and snapshot_time >= ?
+		and snapshot_time <= ?
+		order by snapshot_time;
+	`
+	log.Debugf("capacity DASRepo.GetTableSizesByDBName() select sql: \n%s\nplaceholders: %d, %s, %s, %s",
+		sql, mysqlServerID, dbName, startTime.Format(constant.DefaultTimeLayout), endTime.Format(constant.DefaultTimeLayout))
+
+	result, err := dr.Execute(sql, mysqlServerID, dbName, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	// init []*TableSize
+	tableSizeList := make([]*TableSize, result.RowNumber())
+	for i := range tableSizeList {
+		tableSizeList[i] = NewEmptyTableSize()
+	}
+	// map to struct
+	err = result.MapToStructSlice(tableSizeList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []capacity.TableSize
+	tableSizes := make([]capacity.TableSize, len(tableSizeList))
+	for i := range tableSizes {
+		tableSizes[i] = tableSizeList[i]
+	}
+
+	return tableSizes, nil
+}
+
+// GetLatestSnapshotTime gets the time of the latest table size snapshot of the mysql server, it returns zero time if there is no snapshot
+func (dr *DASRepo) GetLatestSnapshotTime(mysqlServerID int) (time.Time, error) {
+	sql := `
+		select snapshot_time
+		from t_cap_table_snapshot
+		where del_flag = 0
+		and mysql_server_id = ?
+		order by snapshot_time desc
+		limit 1;
+	`
+	log.Debugf("capacity DASRepo.GetLatestSnapshotTime() select sql: \n%s\nplaceholders: %d", sql, mysqlServerID)
+
+	result, err := dr.Execute(sql, mysqlServerID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return time.Time{}, nil
+	}
+	tableSize := NewEmptyTableSize()
+	err = result.MapToStructByRowIndex(tableSize, constant.ZeroInt, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return tableSize.GetSnapshotTime(), nil
+}
+
+// DeleteTableSizesBefore deletes the table size snapshots which were taken before given time
+func (dr *DASRepo) DeleteTableSizesBefore(snapshotTime time.Time) error {
+	sql := `delete from t_cap_table_snapshot where snapshot_time < ?;`
+	log.Debugf("capacity DASRepo.DeleteTableSizesBefore() delete sql: \n%s\nplaceholders: %s", sql, snapshotTime.Format(constant.DefaultTimeLayout))
+
+	_, err := dr.Execute(sql, snapshotTime)
+
+	return err
+}
+
+// ApplicationMySQLRepo reads the table sizes of the application mysql server
+type ApplicationMySQLRepo struct {
+	mysqlServerID int
+	conn          *mysql.Conn
+}
+
+// NewApplicationMySQLRepo returns a new *ApplicationMySQLRepo
+func NewApplicationMySQLRepo(mysqlServerID int, conn *mysql.Conn) *ApplicationMySQLRepo {
+	return &ApplicationMySQLRepo{
+		mysqlServerID: mysqlServerID,
+		conn:          conn,
+	}
+}
+
+// getConnection returns the connection
+func (amr *ApplicationMySQLRepo) getConnection() *mysql.Conn {
+	return amr.conn
+}
+
+// Close closes the application mysql connection
+func (amr *ApplicationMySQLRepo) Close() error {
+	return amr.getConnection().Close()
+}
+
+// GetTableSizes gets the current size of all the user tables from information_schema.tables
+func (amr *ApplicationMySQLRepo) GetTableSizes() ([]capacity.TableSize, error) {
+	log.Debugf("capacity ApplicationMySQLRepo.GetTableSizes() select sql: \n%s", applicationMySQLTableSizes)
+	result, err := amr.getConnection().Execute(applicationMySQLTableSizes)
+	if err != nil {
+		return nil, err
+	}
+	// init []*TableSize
+	tableSizeList := make([]*TableSize, result.RowNumber())
+	for i := range tableSizeList {
+		tableSizeList[i] = NewEmptyTableSize()
+	}
+	// map to struct
+	err = result.MapToStructSlice(tableSizeList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotTime := time.Now()
+	tableSizes := make([]capacity.TableSize, len(tableSizeList))
+	for i := range tableSizes {
+		tableSizeList[i].MySQLServerID = amr.mysqlServerID
+		tableSizeList[i].SnapshotTime = snapshotTime
+		tableSizes[i] = tableSizeList[i]
+	}
+
+	return tableSizes, nil
+}
@@ -0,0 +1,317 @@
+package capacity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/healthcheck"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/capacity"
+	"github.com/romberli/das/pkg/message"
+	msgcap "github.com/romberli/das/pkg/message/capacity"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultHistoryWindow is how far back the history is used to forecast
+	defaultHistoryWindow = 30 * 24 * time.Hour
+	// defaultHistoryStep is the step of the disk capacity usage series
+	defaultHistoryStep = time.Hour
+	// dbClusterTypeSingle means the database is deployed on a single mysql cluster, the cluster id of the database is the mysql cluster id
+	dbClusterTypeSingle = 1
+)
+
+var _ capacity.Service = (*Service)(nil)
+
+// Service of capacity
+type Service struct {
+	capacity.DASRepo
+	Forecast     *Forecast      `json:"forecast"`
+	TableGrowths []*TableGrowth `json:"table_growths"`
+	DBGrowth     *DBGrowth      `json:"db_growth"`
+	AppGrowth    *AppGrowth     `json:"app_growth"`
+}
+
+// NewService returns a new *Service
+func NewService(repo capacity.DASRepo) *Service {
+	return newService(repo)
+}
+
+// NewServiceWithDefault returns a new *Service with default repository
+func NewServiceWithDefault() *Service {
+	return newService(NewDASRepoWithGlobal())
+}
+
+// newService returns a new *Service
+func newService(repo capacity.DASRepo) *Service {
+	return &Service{
+		DASRepo:  repo,
+		Forecast: NewEmptyForecast(),
+	}
+}
+
+// GetDASRepo returns the das repository
+func (s *Service) GetDASRepo() capacity.DASRepo {
+	return s.DASRepo
+}
+
+// GetForecast returns the capacity forecast
+func (s *Service) GetForecast() capacity.Forecast {
+	return s.Forecast
+}
+
+// GetTableGrowths returns the table growths
+func (s *Service) GetTableGrowths() []capacity.TableGrowth {
+	tableGrowths := make([]capacity.TableGrowth, len(s.TableGrowths))
+	for i := range s.TableGrowths {
+		tableGrowths[i] = s.TableGrowths[i]
+	}
+
+	return tableGrowths
+}
+
+// GetDBGrowth returns the db growth
+func (s *Service) GetDBGrowth() capacity.DBGrowth {
+	return s.DBGrowth
+}
+
+// GetAppGrowth returns the app growth
+func (s *Service) GetAppGrowth() capacity.AppGrowth {
+	return s.AppGrowth
+}
+
+// ForecastByMySQLServerID forecasts the disk space usage of the mysql server,
+// it fits the trend of the disk capacity usage of each mount point in the history window,
+// and computes the table growths from the table size snapshots which are taken by the collector,
+// it only reads the stored data and does not connect to the application mysql
+func (s *Service) ForecastByMySQLServerID(mysqlServerID int) error {
+	endTime := time.Now()
+	startTime := endTime.Add(-defaultHistoryWindow)
+	// get mysql server
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetByID(mysqlServerID)
+	if err != nil {
+		return err
+	}
+	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
+	// get monitor system
+	monitorSystem, err := mysqlServer.GetMonitorSystem()
+	if err != nil {
+		return err
+	}
+	// forecast disk capacity usage
+	operationInfo := healthcheck.NewOperationInfo(constant.ZeroInt, mysqlServer, monitorSystem, startTime, endTime, defaultHistoryStep)
+	diskForecasts, err := s.forecastDisks(operationInfo)
+	if err != nil {
+		return err
+	}
+	// compute table growths
+	tableSizes, err := s.GetDASRepo().GetTableSizesByMySQLServerID(mysqlServerID, startTime, endTime)
+	if err != nil {
+		return err
+	}
+
+	s.Forecast = NewForecast(mysqlServerID, diskForecasts, NewTableGrowthsWithTableSizes(tableSizes, defaultTopTableNum))
+
+	return nil
+}
+
+// GetTableGrowthsByMySQLServerID gets the growth of every table of the mysql server in given time range,
+// the tables are sorted by the growth in bytes descending
+func (s *Service) GetTableGrowthsByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) error {
+	tableSizes, err := s.GetDASRepo().GetTableSizesByMySQLServerID(mysqlServerID, startTime, endTime)
+	if err != nil {
+		return err
+	}
+
+	s.TableGrowths = NewTableGrowthsWithTableSizes(tableSizes, constant.ZeroInt)
+
+	return nil
+}
+
+// GetDBGrowthByID gets the growth of the db in given time range
+func (s *Service) GetDBGrowthByID(dbID int, startTime, endTime time.Time) error {
+	dbService := metadata.NewDBServiceWithDefault()
+	err := dbService.GetByID(dbID)
+	if err != nil {
+		return err
+	}
+	db := dbService.GetDBs()[constant.ZeroInt]
+	if db.GetClusterType() != dbClusterTypeSingle {
+		return message.NewMessage(msgcap.ErrCapacityDBClusterTypeNotSupported, dbID, db.GetClusterType())
+	}
+
+	s.DBGrowth, err = s.getDBGrowth(db.Identity(), db.GetDBName(), db.GetClusterID(), startTime, endTime)
+
+	return err
+}
+
+// GetAppGrowthByID gets the growth of the app in given time range, it is the sum of the growth of the dbs of the app,
+// the dbs which are deployed on multiple clusters are not supported and will be ignored
+func (s *Service) GetAppGrowthByID(appID int, startTime, endTime time.Time) error {
+	appService := metadata.NewAppServiceWithDefault()
+	err := appService.GetByID(appID)
+	if err != nil {
+		return err
+	}
+	err = appService.GetDBIDList(appID)
+	if err != nil {
+		return err
+	}
+
+	var dbGrowths []*DBGrowth
+	for _, dbID := range appService.DBIDList {
+		dbService := metadata.NewDBServiceWithDefault()
+		err = dbService.GetByID(dbID)
+		if err != nil {
+			return err
+		}
+		db := dbService.GetDBs()[constant.ZeroInt]
+		if db.GetClusterType() != dbClusterTypeSingle {
+			continue
+		}
+		dbGrowth, err := s.getDBGrowth(db.Identity(), db.GetDBName(), db.GetClusterID(), startTime, endTime)
+		if err != nil {
+			return err
+		}
+		dbGrowths = append(dbGrowths, dbGrowth)
+	}
+
+	s.AppGrowth = NewAppGrowthWithDBGrowths(appID, appService.GetApps()[constant.ZeroInt].GetAppName(), dbGrowths)
+
+	return nil
+}
+
+// getDBGrowth computes the growth of the db which is deployed on the mysql cluster,
+// all the mysql servers of the cluster hold the same tables, so the mysql server which has the most snapshots of the db is used
+func (s *Service) getDBGrowth(dbID int, dbName string, mysqlClusterID int, startTime, endTime time.Time) (*DBGrowth, error) {
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err := mysqlServerService.GetByClusterID(mysqlClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlServerID := constant.ZeroInt
+	var tableSizes []capacity.TableSize
+	maxSnapshotNum := constant.ZeroInt
+	for _, mysqlServer := range mysqlServerService.GetMySQLServers() {
+		sizes, err := s.GetDASRepo().GetTableSizesByDBName(mysqlServer.Identity(), dbName, startTime, endTime)
+		if err != nil {
+			return nil, err
+		}
+		snapshotNum := getSnapshotNum(sizes)
+		if mysqlServerID == constant.ZeroInt || snapshotNum > maxSnapshotNum {
+			mysqlServerID = mysqlServer.Identity()
+			tableSizes = sizes
+			maxSnapshotNum = snapshotNum
+		}
+	}
+
+	return NewDBGrowthWithTableSizes(dbID, dbName, mysqlServerID, tableSizes), nil
+}
+
+// forecastDisks forecasts each mount point of the mysql server of the operation,
+// the disk capacity usage is queried mount point by mount point, as the series of different mount points are merged in a single query
+func (s *Service) forecastDisks(operationInfo *healthcheck.OperationInfo) ([]*DiskForecast, error) {
+	prometheusRepo, err := healthcheck.NewPrometheusRepoWithOperationInfo(operationInfo)
+	if err != nil {
+		return nil, err
+	}
+	fileSystems, err := prometheusRepo.GetFileSystems()
+	if err != nil {
+		return nil, err
+	}
+
+	var diskForecasts []*DiskForecast
+	mountPoints := make(map[string]bool)
+	for _, fileSystem := range fileSystems {
+		mountPoint := fileSystem.GetMountPoint()
+		if mountPoints[mountPoint] {
+			continue
+		}
+		mountPoints[mountPoint] = true
+
+		data, err := prometheusRepo.GetDiskCapacityUsage([]string{mountPoint})
+		if err != nil {
+			return nil, err
+		}
+		diskForecasts = append(diskForecasts, NewDiskForecastWithPrometheusData(mountPoint, data))
+	}
+
+	return diskForecasts, nil
+}
+
+// snapshotTableSizes takes a table size snapshot of the application mysql server and saves it
+func (s *Service) snapshotTableSizes(mysqlServerID int, mysqlServerAddr string) error {
+	conn, err := mysql.NewConn(mysqlServerAddr, constant.EmptyString, s.getApplicationMySQLUser(), s.getApplicationMySQLPass())
+	if err != nil {
+		return err
+	}
+	applicationMySQLRepo := NewApplicationMySQLRepo(mysqlServerID, conn)
+	defer func() {
+		err = applicationMySQLRepo.Close()
+		if err != nil {
+			log.Errorf("capacity Service.snapshotTableSizes(): close application mysql connection failed.\n%s", err.Error())
+		}
+	}()
+
+	tableSizes, err := applicationMySQLRepo.GetTableSizes()
+	if err != nil {
+		return err
+	}
+	if len(tableSizes) == constant.ZeroInt {
+		return nil
+	}
+
+	return s.GetDASRepo().SaveTableSizes(mysqlServerID, tableSizes[constant.ZeroInt].GetSnapshotTime(), tableSizes)
+}
+
+// getApplicationMySQLUser returns application mysql username
+func (s *Service) getApplicationMySQLUser() string {
+	return viper.GetString(config.DBApplicationMySQLUserKey)
+}
+
+// getApplicationMySQLPass returns application mysql password
+func (s *Service) getApplicationMySQLPass() string {
+	return viper.GetString(config.DBApplicationMySQLPassKey)
+}
+
+// Marshal marshals Service.Forecast to json bytes
+func (s *Service) Marshal() ([]byte, error) {
+	return json.Marshal(s.Forecast)
+}
+
+// MarshalTableGrowthsJSON marshals the table growths of the Service to json bytes
+func (s *Service) MarshalTableGrowthsJSON() ([]byte, error) {
+	return json.Marshal(s.TableGrowths)
+}
+
+// MarshalDBGrowthJSON marshals the db growth of the Service to json bytes
+func (s *Service) MarshalDBGrowthJSON() ([]byte, error) {
+	return json.Marshal(s.DBGrowth)
+}
+
+// MarshalAppGrowthJSON marshals the app growth of the Service to json bytes
+func (s *Service) MarshalAppGrowthJSON() ([]byte, error) {
+	return json.Marshal(s.AppGrowth)
+}
+
+// MarshalWithFields marshals only specified fields of the Service to json bytes
+func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(s, fields...)
+}
+
+// getSnapshotNum returns the number of the distinct snapshots of the table sizes
+func getSnapshotNum(tableSizes []capacity.TableSize) int {
+	snapshotTimes := make(map[int64]bool)
+	for _, tableSize := range tableSizes {
+		snapshotTimes[tableSize.GetSnapshotTime().UnixNano()] = true
+	}
+
+	return len(snapshotTimes)
+}
@@ -0,0 +1,73 @@
+package capacity
+
+import (
+	"time"
+
+	"github.com/romberli/das/internal/dependency/capacity"
+)
+
+var _ capacity.TableSize = (*TableSize)(nil)
+
+// TableSize is the size of a table at the snapshot time
+type TableSize struct {
+	MySQLServerID int       `middleware:"mysql_server_id" json:"mysql_server_id"`
+	DBName        string    `middleware:"db_name" json:"db_name"`
+	TableName     string    `middleware:"table_name" json:"table_name"`
+	TableRows     int64     `middleware:"table_rows" json:"table_rows"`
+	DataLength    int64     `middleware:"data_length" json:"data_length"`
+	IndexLength   int64     `middleware:"index_length" json:"index_length"`
+	SnapshotTime  time.Time `middleware:"snapshot_time" json:"snapshot_time"`
+}
+
+// NewTableSize returns a new *TableSize
+func NewTableSize(mysqlServerID int, dbName, tableName string, tableRows, dataLength, indexLength int64, snapshotTime time.Time) *TableSize {
+	return &TableSize{
+		MySQLServerID: mysqlServerID,
+		DBName:        dbName,
+		TableName:     tableName,
+		TableRows:     tableRows,
+		DataLength:    dataLength,
+		IndexLength:   indexLength,
+		SnapshotTime:  snapshotTime,
+	}
+}
+
+// NewEmptyTableSize returns a new empty *TableSize
+func NewEmptyTableSize() *TableSize {
+	return &TableSize{}
+}
+
+// GetMySQLServerID returns the mysql server id
+func (ts *TableSize) GetMySQLServerID() int {
+	return ts.MySQLServerID
+}
+
+// GetDBName returns the db name
+func (ts *TableSize) GetDBName() string {
+	return ts.DBName
+}
+
+// GetTableName returns the table name
+func (ts *TableSize) GetTableName() string {
+	return ts.TableName
+}
+
+// GetTableRows returns the estimated row count of the table
+func (ts *TableSize) GetTableRows() int64 {
+	return ts.TableRows
+}
+
+// GetDataLength returns the data length of the table in bytes
+func (ts *TableSize) GetDataLength() int64 {
+	return ts.DataLength
+}
+
+// GetIndexLength returns the index length of the table in bytes
+func (ts *TableSize) GetIndexLength() int64 {
+	return ts.IndexLength
+}
+
+// GetSnapshotTime returns the time when the snapshot was taken
+func (ts *TableSize) GetSnapshotTime() time.Time {
+	return ts.SnapshotTime
+}
@@ -0,0 +1,201 @@
+package capacity
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	// seasonalPeriod is the period of the seasonal trend, the disk usage usually changes daily because of the batch jobs and log rotation
+	seasonalPeriod = 24 * time.Hour
+	// seasonalBucketNum is the number of the buckets of a seasonal period, each bucket is an hour
+	seasonalBucketNum = 24
+	// minSeasonalPeriodNum is the minimum number of the periods which the samples must cover to fit the seasonal trend
+	minSeasonalPeriodNum = 2
+	// confidenceZ is the z score of the 95% confidence bounds
+	confidenceZ = 1.96
+)
+
+// point is a sample of a time series
+type point struct {
+	Time  time.Time
+	Value float64
+}
+
+// newPoint returns a new point
+func newPoint(t time.Time, value float64) point {
+	return point{Time: t, Value: value}
+}
+
+// trend is the linear trend with an optional daily seasonal component fitted from a time series,
+// the time unit of the trend is day
+type trend struct {
+	intercept   float64
+	slope       float64
+	slopeErr    float64
+	residualErr float64
+	seasonal    []float64
+	firstTime   time.Time
+	lastTime    time.Time
+	sampleNum   int
+	isSeasonal  bool
+}
+
+// fitTrend fits the linear trend of the points by the least squares method,
+// if seasonal is true and the points cover at least 2 seasonal periods, the seasonal component is removed before fitting the linear trend,
+// the points must contain at least 2 samples of different time
+func fitTrend(points []point, seasonal bool) (*trend, error) {
+	if len(points) < 2 {
+		return nil, errors.New("capacity: fitting the trend needs at least 2 samples")
+	}
+	sorted := make([]point, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	t := &trend{
+		firstTime: sorted[constant.ZeroInt].Time,
+		lastTime:  sorted[len(sorted)-1].Time,
+		sampleNum: len(sorted),
+		seasonal:  make([]float64, seasonalBucketNum),
+	}
+	xs := make([]float64, len(sorted))
+	ys := make([]float64, len(sorted))
+	for i, p := range sorted {
+		xs[i] = t.getX(p.Time)
+		ys[i] = p.Value
+	}
+	err := t.fitLinear(xs, ys)
+	if err != nil {
+		return nil, err
+	}
+
+	if !seasonal || t.lastTime.Sub(t.firstTime) < minSeasonalPeriodNum*seasonalPeriod {
+		return t, nil
+	}
+	// the seasonal component of each bucket is the average residual of the samples in the bucket
+	sums := make([]float64, seasonalBucketNum)
+	counts := make([]int, seasonalBucketNum)
+	for i, p := range sorted {
+		bucket := t.getBucket(p.Time)
+		sums[bucket] += ys[i] - t.predictLinear(xs[i])
+		counts[bucket]++
+	}
+	for i := range t.seasonal {
+		if counts[i] > constant.ZeroInt {
+			t.seasonal[i] = sums[i] / float64(counts[i])
+		}
+	}
+	// refit the linear trend without the seasonal component
+	for i, p := range sorted {
+		ys[i] -= t.seasonal[t.getBucket(p.Time)]
+	}
+	err = t.fitLinear(xs, ys)
+	if err != nil {
+		return nil, err
+	}
+	t.isSeasonal = true
+
+	return t, nil
+}
+
+// fitLinear fits y = intercept + slope * x by the least squares method, and computes the standard errors
+func (t *trend) fitLinear(xs, ys []float64) error {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy float64
+	for i := range xs {
+		sxx += (xs[i] - meanX) * (xs[i] - meanX)
+		sxy += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	if sxx == constant.ZeroInt {
+		return errors.New("capacity: fitting the trend needs the samples of at least 2 different time")
+	}
+	t.slope = sxy / sxx
+	t.intercept = meanY - t.slope*meanX
+
+	t.residualErr, t.slopeErr = constant.ZeroInt, constant.ZeroInt
+	if len(xs) > 2 {
+		var sse float64
+		for i := range xs {
+			residual := ys[i] - t.predictLinear(xs[i])
+			sse += residual * residual
+		}
+		t.residualErr = math.Sqrt(sse / (n - 2))
+		t.slopeErr = t.residualErr / math.Sqrt(sxx)
+	}
+
+	return nil
+}
+
+// getX returns the days from the first sample to given time
+func (t *trend) getX(tm time.Time) float64 {
+	return float64(tm.Sub(t.firstTime)) / float64(seasonalPeriod)
+}
+
+// getBucket returns the seasonal bucket of given time
+func (t *trend) getBucket(tm time.Time) int {
+	offset := tm.Sub(t.firstTime) % seasonalPeriod
+
+	return int(offset / (seasonalPeriod / seasonalBucketNum))
+}
+
+// predictLinear returns the value of the linear trend at x
+func (t *trend) predictLinear(x float64) float64 {
+	return t.intercept + t.slope*x
+}
+
+// getLevel returns the value of the linear trend at the last sample
+func (t *trend) getLevel() float64 {
+	return t.predictLinear(t.getX(t.lastTime))
+}
+
+// getSeasonalPeak returns the maximum seasonal component, it is 0 if the seasonal trend is not fitted
+func (t *trend) getSeasonalPeak() float64 {
+	var peak float64
+	for _, value := range t.seasonal {
+		peak = math.Max(peak, value)
+	}
+
+	return peak
+}
+
+// getDaysToReach returns the days from the last sample until the trend reaches the limit, the seasonal peak is taken into account,
+// it returns the estimated days, the lower bound and the upper bound of the 95% confidence interval,
+// the days are -1 if the trend never reaches the limit
+func (t *trend) getDaysToReach(limit float64) (float64, float64, float64) {
+	headroom := limit - t.getSeasonalPeak()
+	level := t.getLevel()
+
+	days := getDaysToConsume(headroom-level, t.slope)
+	// the earliest case is the higher level with the faster growth
+	lower := getDaysToConsume(headroom-level-confidenceZ*t.residualErr, t.slope+confidenceZ*t.slopeErr)
+	// the latest case is the lower level with the slower growth
+	upper := getDaysToConsume(headroom-level+confidenceZ*t.residualErr, t.slope-confidenceZ*t.slopeErr)
+
+	return days, lower, upper
+}
+
+// getDaysToConsume returns the days to consume the remaining with given growth per day,
+// it returns 0 if nothing remains, and returns -1 if it does not grow
+func getDaysToConsume(remaining, growthPerDay float64) float64 {
+	if remaining <= constant.ZeroInt {
+		return constant.ZeroInt
+	}
+	if growthPerDay <= constant.ZeroInt {
+		return noDaysToFull
+	}
+
+	return remaining / growthPerDay
+}
@@ -0,0 +1,84 @@
+package capacity
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrendAll(t *testing.T) {
+	TestTrend_FitTrend(t)
+	TestTrend_FitTrendSeasonal(t)
+	TestTrend_GetDaysToReach(t)
+}
+
+func TestTrend_FitTrend(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	// grows 0.01 per day from 0.5
+	var points []point
+	for i := 10; i >= 0; i-- {
+		points = append(points, newPoint(start.Add(time.Duration(i)*time.Hour), 0.5+0.01*float64(i)/24))
+	}
+	tr, err := fitTrend(points, true)
+	asst.Nil(err, "test fitTrend() failed")
+	asst.InDelta(0.01, tr.slope, 1e-9, "test fitTrend() failed")
+	asst.InDelta(0.5+0.01*10/24, tr.getLevel(), 1e-9, "test fitTrend() failed")
+	asst.InDelta(0, tr.residualErr, 1e-9, "test fitTrend() failed")
+	asst.False(tr.isSeasonal, "test fitTrend() failed")
+
+	_, err = fitTrend(points[:1], true)
+	asst.NotNil(err, "test fitTrend() failed")
+	_, err = fitTrend([]point{newPoint(start, 0.1), newPoint(start, 0.2)}, true)
+	asst.NotNil(err, "test fitTrend() failed")
+}
+
+func TestTrend_FitTrendSeasonal(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	// grows 0.01 per day, and the usage is 0.05 higher during the nightly batch at 2 am
+	var points []point
+	for i := 0; i < 7*24; i++ {
+		value := 0.5 + 0.01*float64(i)/24
+		if i%24 == 2 {
+			value += 0.05
+		}
+		points = append(points, newPoint(start.Add(time.Duration(i)*time.Hour), value))
+	}
+	tr, err := fitTrend(points, true)
+	asst.Nil(err, "test fitTrend() failed")
+	asst.True(tr.isSeasonal, "test fitTrend() failed")
+	asst.InDelta(0.01, tr.slope, 1e-3, "test fitTrend() failed")
+	asst.InDelta(0.05, tr.getSeasonalPeak(), 5e-3, "test fitTrend() failed")
+
+	tr, err = fitTrend(points, false)
+	asst.Nil(err, "test fitTrend() failed")
+	asst.False(tr.isSeasonal, "test fitTrend() failed")
+	asst.Equal(float64(0), tr.getSeasonalPeak(), "test fitTrend() failed")
+}
+
+func TestTrend_GetDaysToReach(t *testing.T) {
+	asst := assert.New(t)
+
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	// grows 0.01 per day with some noise
+	var points []point
+	for i := 0; i < 48; i++ {
+		noise := 0.002 * math.Sin(float64(i))
+		points = append(points, newPoint(start.Add(time.Duration(i)*time.Hour), 0.5+0.01*float64(i)/24+noise))
+	}
+	tr, err := fitTrend(points, false)
+	asst.Nil(err, "test getDaysToReach() failed")
+	days, lower, upper := tr.getDaysToReach(defaultFullUsage)
+	asst.InDelta((1-tr.getLevel())/tr.slope, days, 1e-9, "test getDaysToReach() failed")
+	asst.True(lower <= days, "test getDaysToReach() failed")
+	asst.True(upper == noDaysToFull || upper >= days, "test getDaysToReach() failed")
+
+	asst.Equal(float64(noDaysToFull), getDaysToConsume(0.5, 0), "test getDaysToConsume() failed")
+	asst.Equal(float64(0), getDaysToConsume(-0.1, 0.01), "test getDaysToConsume() failed")
+	asst.InDelta(50, getDaysToConsume(0.5, 0.01), 1e-9, "test getDaysToConsume() failed")
+}
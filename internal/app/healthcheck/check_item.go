@@ -0,0 +1,158 @@
+package healthcheck
+
+import (
+	"sync"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+)
+
+const (
+	defaultItemStatusAvailable   = "available"
+	defaultItemStatusUnavailable = "unavailable"
+)
+
+var defaultCheckItemRegistry = NewCheckItemRegistry()
+
+// CheckItemCreator creates a check item which collects data with the resources of given engine
+type CheckItemCreator func(de *DefaultEngine) healthcheck.CheckItem
+
+// CheckItemRegistry keeps the creators of the check items in the registration order
+type CheckItemRegistry struct {
+	mutex    sync.RWMutex
+	names    []string
+	creators map[string]CheckItemCreator
+}
+
+// NewCheckItemRegistry returns a new empty *CheckItemRegistry
+func NewCheckItemRegistry() *CheckItemRegistry {
+	return &CheckItemRegistry{
+		creators: make(map[string]CheckItemCreator),
+	}
+}
+
+// Register registers the check item creator with given item name,
+// the item name must be identical to the item name in the engine config
+func (cir *CheckItemRegistry) Register(name string, creator CheckItemCreator) error {
+	cir.mutex.Lock()
+	defer cir.mutex.Unlock()
+
+	_, exists := cir.creators[name]
+	if exists {
+		return message.NewMessage(msghc.ErrCheckItemAlreadyRegistered, name)
+	}
+
+	cir.names = append(cir.names, name)
+	cir.creators[name] = creator
+
+	return nil
+}
+
+// GetNames returns the registered item names in the registration order
+func (cir *CheckItemRegistry) GetNames() []string {
+	cir.mutex.RLock()
+	defer cir.mutex.RUnlock()
+
+	names := make([]string, len(cir.names))
+	copy(names, cir.names)
+
+	return names
+}
+
+// CreateItems creates all the registered check items with given engine in the registration order
+func (cir *CheckItemRegistry) CreateItems(de *DefaultEngine) []healthcheck.CheckItem {
+	cir.mutex.RLock()
+	defer cir.mutex.RUnlock()
+
+	items := make([]healthcheck.CheckItem, len(cir.names))
+	for i, name := range cir.names {
+		items[i] = cir.creators[name](de)
+	}
+
+	return items
+}
+
+// RegisterCheckItem registers the check item creator to the default registry,
+// it is usually called in the init() function of the package which implements the check item
+func RegisterCheckItem(name string, creator CheckItemCreator) error {
+	return defaultCheckItemRegistry.Register(name, creator)
+}
+
+// GetCheckItemRegistry returns the default check item registry
+func GetCheckItemRegistry() *CheckItemRegistry {
+	return defaultCheckItemRegistry
+}
+
+// ItemResult is the result of a single check item
+type ItemResult struct {
+	ItemName string `json:"item_name"`
+	Status   string `json:"status"`
+	Score    int    `json:"score"`
+	Data     string `json:"data"`
+	High     string `json:"high"`
+	Advice   string `json:"advice"`
+	Error    string `json:"error"`
+}
+
+// NewItemResult returns a new available *ItemResult
+func NewItemResult(itemName string, score int, data, high, advice string) *ItemResult {
+	return &ItemResult{
+		ItemName: itemName,
+		Status:   defaultItemStatusAvailable,
+		Score:    score,
+		Data:     data,
+		High:     high,
+		Advice:   advice,
+	}
+}
+
+// NewUnavailableItemResult returns a new unavailable *ItemResult with given error,
+// it is used when the check item failed or timed out
+func NewUnavailableItemResult(itemName string, err error) *ItemResult {
+	return &ItemResult{
+		ItemName: itemName,
+		Status:   defaultItemStatusUnavailable,
+		Error:    err.Error(),
+	}
+}
+
+// GetItemName returns the item name
+func (ir *ItemResult) GetItemName() string {
+	return ir.ItemName
+}
+
+// GetStatus returns the status
+func (ir *ItemResult) GetStatus() string {
+	return ir.Status
+}
+
+// IsAvailable returns if the check item completed successfully
+func (ir *ItemResult) IsAvailable() bool {
+	return ir.Status == defaultItemStatusAvailable
+}
+
+// GetScore returns the score
+func (ir *ItemResult) GetScore() int {
+	return ir.Score
+}
+
+// GetData returns the collected data
+func (ir *ItemResult) GetData() string {
+	return ir.Data
+}
+
+// GetHigh returns the data which is higher than the watermark
+func (ir *ItemResult) GetHigh() string {
+	return ir.High
+}
+
+// GetAdvice returns the advice
+func (ir *ItemResult) GetAdvice() string {
+	return ir.Advice
+}
+
+// GetError returns the error message of the unavailable item
+func (ir *ItemResult) GetError() string {
+	return ir.Error
+}
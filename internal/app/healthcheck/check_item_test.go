@@ -0,0 +1,76 @@
+package healthcheck
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const testCheckItemName = "test_item"
+
+func TestCheckItemAll(t *testing.T) {
+	TestCheckItemRegistry_Register(t)
+	TestCheckItemRegistry_CreateItems(t)
+	TestCheckItem_setItemResult(t)
+}
+
+func TestCheckItemRegistry_Register(t *testing.T) {
+	asst := assert.New(t)
+
+	registry := NewCheckItemRegistry()
+	err := registry.Register(testCheckItemName, NewDBConfigItem)
+	asst.Nil(err, "test Register() failed")
+	err = registry.Register(testCheckItemName, NewDBConfigItem)
+	asst.NotNil(err, "test Register() failed")
+	asst.Equal([]string{testCheckItemName}, registry.GetNames(), "test Register() failed")
+}
+
+func TestCheckItemRegistry_CreateItems(t *testing.T) {
+	asst := assert.New(t)
+
+	names := GetCheckItemRegistry().GetNames()
+	asst.Equal(defaultDBConfigItemName, names[0], "test CreateItems() failed")
+	asst.Equal(defaultIndexHealthItemName, names[len(names)-1], "test CreateItems() failed")
+
+	snapshot, err := NewSnapshotWithFile(filepath.Join(testDataDir, testSnapshotFile))
+	asst.Nil(err, common.CombineMessageWithError("test CreateItems() failed", err))
+	de := NewDefaultEngine(nil, nil, nil, NewSnapshotPrometheusRepo(snapshot.Prometheus), nil)
+	items := GetCheckItemRegistry().CreateItems(de)
+	asst.Equal(len(names), len(items), "test CreateItems() failed")
+	for i, item := range items {
+		asst.Equal(names[i], item.GetName(), "test CreateItems() failed")
+	}
+}
+
+func TestCheckItem_setItemResult(t *testing.T) {
+	asst := assert.New(t)
+
+	result := NewEmptyResult()
+	asst.True(result.setItemResult(NewItemResult(defaultCPUUsageItemName, 90, "[]", "[]", "")), "test setItemResult() failed")
+	asst.Equal(90, result.GetCPUUsageScore(), "test setItemResult() failed")
+	asst.False(result.setItemResult(NewItemResult(testCheckItemName, 90, "[]", "[]", "")), "test setItemResult() failed")
+	asst.Equal(2, len(result.GetItems()), "test setItemResult() failed")
+	asst.Equal(1, len(result.OtherItems), "test setItemResult() failed")
+	asst.Equal(testCheckItemName, result.OtherItems[0].GetItemName(), "test setItemResult() failed")
+	// the item which has the same item name will be replaced
+	asst.True(result.setItemResult(NewItemResult(defaultCPUUsageItemName, 80, "[]", "[]", "")), "test setItemResult() failed")
+	asst.Equal(2, len(result.GetItems()), "test setItemResult() failed")
+	asst.Equal(80, result.GetCPUUsageScore(), "test setItemResult() failed")
+	// the unavailable item does not set the legacy fields
+	asst.False(result.setItemResult(NewUnavailableItemResult(defaultIOUtilItemName, errors.New("test error"))), "test setItemResult() failed")
+	asst.Equal(3, len(result.GetItems()), "test setItemResult() failed")
+	asst.False(result.GetItems()[2].IsAvailable(), "test setItemResult() failed")
+	asst.Equal("test error", result.GetItems()[2].GetError(), "test setItemResult() failed")
+	// the replication item only goes through the generic items
+	asst.False(result.setItemResult(NewItemResult(defaultReplicationItemName, 90, "[]", "[]", "")), "test setItemResult() failed")
+	asst.Equal(defaultReplicationItemName, result.OtherItems[len(result.OtherItems)-1].GetItemName(), "test setItemResult() failed")
+	// the innodb lock item only goes through the generic items
+	asst.False(result.setItemResult(NewItemResult(defaultInnoDBLockItemName, 90, "[]", "[]", "")), "test setItemResult() failed")
+	asst.Equal(defaultInnoDBLockItemName, result.OtherItems[len(result.OtherItems)-1].GetItemName(), "test setItemResult() failed")
+	// the index health item only goes through the generic items
+	asst.False(result.setItemResult(NewItemResult(defaultIndexHealthItemName, 90, "[]", "[]", "")), "test setItemResult() failed")
+	asst.Equal(defaultIndexHealthItemName, result.OtherItems[len(result.OtherItems)-1].GetItemName(), "test setItemResult() failed")
+}
@@ -0,0 +1,261 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+var (
+	_ healthcheck.ClusterResult = (*ClusterResult)(nil)
+
+	// clusterConfigDriftExcludedVariables are the variables which are expected to be different between the mysql servers
+	clusterConfigDriftExcludedVariables = map[string]bool{
+		dbConfigReportHost: true,
+		dbConfigReportPort: true,
+	}
+)
+
+// ClusterResult is the healthcheck result of the mysql cluster
+type ClusterResult struct {
+	ID                 int       `middleware:"id" json:"id"`
+	ClusterOperationID int       `middleware:"cluster_operation_id" json:"cluster_operation_id"`
+	WorstScore         int       `middleware:"worst_score" json:"worst_score"`
+	WorstMySQLServerID int       `middleware:"worst_mysql_server_id" json:"worst_mysql_server_id"`
+	ServerResults      string    `middleware:"server_results" json:"server_results"`
+	ConfigDrift        string    `middleware:"config_drift" json:"config_drift"`
+	DelFlag            int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime         time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime     time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewEmptyClusterResult returns a new empty *ClusterResult
+func NewEmptyClusterResult() *ClusterResult {
+	return &ClusterResult{}
+}
+
+// Identity returns the identity
+func (cr *ClusterResult) Identity() int {
+	return cr.ID
+}
+
+// GetClusterOperationID returns the cluster operation id
+func (cr *ClusterResult) GetClusterOperationID() int {
+	return cr.ClusterOperationID
+}
+
+// GetWorstScore returns the worst weighted average score of the mysql servers
+func (cr *ClusterResult) GetWorstScore() int {
+	return cr.WorstScore
+}
+
+// GetWorstMySQLServerID returns the mysql server id which has the worst score
+func (cr *ClusterResult) GetWorstMySQLServerID() int {
+	return cr.WorstMySQLServerID
+}
+
+// GetServerResults returns the results of the mysql servers as a json string
+func (cr *ClusterResult) GetServerResults() string {
+	return cr.ServerResults
+}
+
+// GetConfigDrift returns the database configuration drift between the mysql servers as a json string
+func (cr *ClusterResult) GetConfigDrift() string {
+	return cr.ConfigDrift
+}
+
+// GetDelFlag returns the delete flag
+func (cr *ClusterResult) GetDelFlag() int {
+	return cr.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (cr *ClusterResult) GetCreateTime() time.Time {
+	return cr.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (cr *ClusterResult) GetLastUpdateTime() time.Time {
+	return cr.LastUpdateTime
+}
+
+// MarshalJSON marshals ClusterResult to json bytes
+func (cr *ClusterResult) MarshalJSON() ([]byte, error) {
+	return common.MarshalStructWithTag(cr, constant.DefaultMarshalTag)
+}
+
+// ServerResult is the healthcheck result of a mysql server which belongs to the mysql cluster
+type ServerResult struct {
+	MySQLServerID        int    `json:"mysql_server_id"`
+	HostIP               string `json:"host_ip"`
+	PortNum              int    `json:"port_num"`
+	OperationID          int    `json:"operation_id"`
+	Status               int    `json:"status"`
+	WeightedAverageScore int    `json:"weighted_average_score"`
+	Message              string `json:"message"`
+	dbConfigData         string
+}
+
+// NewServerResult returns a new *ServerResult
+func NewServerResult(mysqlServerID int, hostIP string, portNum int, operationID int) *ServerResult {
+	return &ServerResult{
+		MySQLServerID: mysqlServerID,
+		HostIP:        hostIP,
+		PortNum:       portNum,
+		OperationID:   operationID,
+		Status:        defaultFailedStatus,
+	}
+}
+
+// GetAddr returns the address of the mysql server, format: host_ip:port_num
+func (sr *ServerResult) GetAddr() string {
+	return fmt.Sprintf("%s:%d", sr.HostIP, sr.PortNum)
+}
+
+// IsSucceeded returns if the healthcheck of the mysql server completed successfully
+func (sr *ServerResult) IsSucceeded() bool {
+	return sr.Status == defaultSuccessStatus
+}
+
+// setResult sets the healthcheck result of the mysql server
+func (sr *ServerResult) setResult(result healthcheck.Result) {
+	sr.Status = defaultSuccessStatus
+	sr.WeightedAverageScore = result.GetWeightedAverageScore()
+	sr.dbConfigData = result.GetDBConfigData()
+}
+
+// setError marks the healthcheck of the mysql server as failed
+func (sr *ServerResult) setError(err error) {
+	sr.Status = defaultFailedStatus
+	sr.Message = err.Error()
+}
+
+// getAllFailedError returns the error which contains the messages of all the mysql servers if none of them succeeded,
+// otherwise, it returns nil
+func getAllFailedError(clusterOperationID int, serverResults []*ServerResult) error {
+	messages := make([]string, len(serverResults))
+	for i, serverResult := range serverResults {
+		if serverResult.IsSucceeded() {
+			return nil
+		}
+		messages[i] = fmt.Sprintf("mysql_server_id: %d, operation_id: %d, message: %s",
+			serverResult.MySQLServerID, serverResult.OperationID, serverResult.Message)
+	}
+
+	return message.NewMessage(msghc.ErrHealthcheckMySQLClusterAllFailed, clusterOperationID, strings.Join(messages, constant.CRLFString))
+}
+
+// ConfigDrift is a database variable whose value differs between the mysql servers of the mysql cluster
+type ConfigDrift struct {
+	VariableName string            `json:"variable_name"`
+	Values       map[string]string `json:"values"`
+}
+
+// NewClusterResultWithServerResults summarizes the results of the mysql servers to a new *ClusterResult,
+// the mysql server which failed will be kept in the server results but will not take part in the summary
+func NewClusterResultWithServerResults(clusterOperationID int, serverResults []*ServerResult) (*ClusterResult, error) {
+	cr := &ClusterResult{
+		ClusterOperationID: clusterOperationID,
+		WorstScore:         defaultMaxScore,
+	}
+
+	var succeeded []*ServerResult
+	for _, serverResult := range serverResults {
+		if !serverResult.IsSucceeded() {
+			continue
+		}
+		succeeded = append(succeeded, serverResult)
+		if serverResult.WeightedAverageScore <= cr.WorstScore {
+			cr.WorstScore = serverResult.WeightedAverageScore
+			cr.WorstMySQLServerID = serverResult.MySQLServerID
+		}
+	}
+	if len(succeeded) == constant.ZeroInt {
+		cr.WorstScore = defaultMinScore
+	}
+
+	jsonBytes, err := json.Marshal(serverResults)
+	if err != nil {
+		return nil, err
+	}
+	cr.ServerResults = string(jsonBytes)
+
+	configDrift, err := getConfigDrift(succeeded)
+	if err != nil {
+		return nil, err
+	}
+	jsonBytes, err = json.Marshal(configDrift)
+	if err != nil {
+		return nil, err
+	}
+	cr.ConfigDrift = string(jsonBytes)
+
+	return cr, nil
+}
+
+// getConfigDrift compares the database variables of the mysql servers and returns the variables which have different values,
+// only the variables which are reported by all the mysql servers are compared, as the db config rules could apply to specific roles,
+// e.g. the replica only rules, the mysql server whose database configuration was not collected will be ignored
+func getConfigDrift(serverResults []*ServerResult) ([]*ConfigDrift, error) {
+	var serverNum int
+	valuesMap := make(map[string]map[string]string)
+	for _, serverResult := range serverResults {
+		if serverResult.dbConfigData == constant.EmptyString {
+			continue
+		}
+		var variables []*GlobalVariable
+		err := json.Unmarshal([]byte(serverResult.dbConfigData), &variables)
+		if err != nil {
+			return nil, err
+		}
+		serverNum++
+		for _, variable := range variables {
+			if clusterConfigDriftExcludedVariables[variable.GetName()] {
+				continue
+			}
+			_, exists := valuesMap[variable.GetName()]
+			if !exists {
+				valuesMap[variable.GetName()] = make(map[string]string)
+			}
+			valuesMap[variable.GetName()][serverResult.GetAddr()] = variable.GetValue()
+		}
+	}
+
+	configDrift := make([]*ConfigDrift, constant.ZeroInt)
+	for variableName, values := range valuesMap {
+		if len(values) < serverNum || isIdentical(values) {
+			continue
+		}
+		configDrift = append(configDrift, &ConfigDrift{VariableName: variableName, Values: values})
+	}
+	sort.Slice(configDrift, func(i, j int) bool {
+		return configDrift[i].VariableName < configDrift[j].VariableName
+	})
+
+	return configDrift, nil
+}
+
+// isIdentical returns if all the values of the map are identical
+func isIdentical(values map[string]string) bool {
+	var first string
+	isFirst := true
+	for _, value := range values {
+		if isFirst {
+			first, isFirst = value, false
+			continue
+		}
+		if value != first {
+			return false
+		}
+	}
+
+	return true
+}
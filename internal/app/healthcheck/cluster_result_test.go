@@ -0,0 +1,86 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestServerResult(mysqlServerID int, portNum int, score int, variables ...*GlobalVariable) *ServerResult {
+	sr := NewServerResult(mysqlServerID, "127.0.0.1", portNum, mysqlServerID)
+	jsonBytes, _ := json.Marshal(variables)
+	sr.Status = defaultSuccessStatus
+	sr.WeightedAverageScore = score
+	sr.dbConfigData = string(jsonBytes)
+
+	return sr
+}
+
+func TestClusterResultAll(t *testing.T) {
+	TestClusterResult_NewClusterResultWithServerResults(t)
+	TestClusterResult_getConfigDrift(t)
+	TestClusterResult_getAllFailedError(t)
+}
+
+func TestClusterResult_NewClusterResultWithServerResults(t *testing.T) {
+	asst := assert.New(t)
+
+	failed := NewServerResult(3, "127.0.0.1", 3308, 3)
+	serverResults := []*ServerResult{
+		newTestServerResult(1, 3306, 90),
+		newTestServerResult(2, 3307, 70),
+		failed,
+	}
+	cr, err := NewClusterResultWithServerResults(1, serverResults)
+	asst.Nil(err, common.CombineMessageWithError("test NewClusterResultWithServerResults() failed", err))
+	asst.Equal(70, cr.GetWorstScore(), "test NewClusterResultWithServerResults() failed")
+	asst.Equal(2, cr.GetWorstMySQLServerID(), "test NewClusterResultWithServerResults() failed")
+
+	var results []*ServerResult
+	err = json.Unmarshal([]byte(cr.GetServerResults()), &results)
+	asst.Nil(err, common.CombineMessageWithError("test NewClusterResultWithServerResults() failed", err))
+	asst.Equal(3, len(results), "test NewClusterResultWithServerResults() failed")
+	asst.Equal(defaultFailedStatus, results[2].Status, "test NewClusterResultWithServerResults() failed")
+}
+
+func TestClusterResult_getConfigDrift(t *testing.T) {
+	asst := assert.New(t)
+
+	serverResults := []*ServerResult{
+		newTestServerResult(1, 3306, 90,
+			&GlobalVariable{VariableName: dbConfigSyncBinlog, VariableValue: "1"},
+			&GlobalVariable{VariableName: dbConfigLogBin, VariableValue: "ON"},
+			&GlobalVariable{VariableName: dbConfigReportPort, VariableValue: "3306"}),
+		newTestServerResult(2, 3307, 70,
+			&GlobalVariable{VariableName: dbConfigSyncBinlog, VariableValue: "0"},
+			&GlobalVariable{VariableName: dbConfigLogBin, VariableValue: "ON"},
+			&GlobalVariable{VariableName: dbConfigReportPort, VariableValue: "3307"},
+			// the replica only variables are not reported by the primary
+			&GlobalVariable{VariableName: "slave_parallel_type", VariableValue: "LOGICAL_CLOCK"},
+			&GlobalVariable{VariableName: "slave_parallel_workers", VariableValue: "16"}),
+	}
+	configDrift, err := getConfigDrift(serverResults)
+	asst.Nil(err, common.CombineMessageWithError("test getConfigDrift() failed", err))
+	asst.Equal(1, len(configDrift), "test getConfigDrift() failed")
+	asst.Equal(dbConfigSyncBinlog, configDrift[0].VariableName, "test getConfigDrift() failed")
+	asst.Equal("0", configDrift[0].Values["127.0.0.1:3307"], "test getConfigDrift() failed")
+}
+
+func TestClusterResult_getAllFailedError(t *testing.T) {
+	asst := assert.New(t)
+
+	failed1 := NewServerResult(1, "127.0.0.1", 3306, 1)
+	failed1.setError(errors.New("connection refused"))
+	failed2 := NewServerResult(2, "127.0.0.1", 3307, 2)
+	failed2.setError(errors.New("check item timeout"))
+
+	err := getAllFailedError(1, []*ServerResult{failed1, newTestServerResult(3, 3308, 90)})
+	asst.Nil(err, common.CombineMessageWithError("test getAllFailedError() failed", err))
+	err = getAllFailedError(1, []*ServerResult{failed1, failed2})
+	asst.NotNil(err, "test getAllFailedError() failed")
+	asst.Contains(err.Error(), "connection refused", "test getAllFailedError() failed")
+	asst.Contains(err.Error(), "check item timeout", "test getAllFailedError() failed")
+}
@@ -0,0 +1,168 @@
+package healthcheck
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	cronFieldNum      = 5
+	cronAnyValue      = "*"
+	cronRangeSep      = "-"
+	cronStepSep       = "/"
+	cronMaxSearchYear = 5
+)
+
+// cronFieldBounds are the minimum and maximum values of minute, hour, day of month, month and day of week
+var cronFieldBounds = [cronFieldNum][2]int{
+	{0, 59},
+	{0, 23},
+	{1, 31},
+	{1, 12},
+	{0, 6},
+}
+
+// CronExpr is a standard cron expression with 5 fields: minute, hour, day of month, month and day of week,
+// each field supports *, single value, list(1,2), range(1-5) and step(*/5, 1-30/5)
+type CronExpr struct {
+	expr        string
+	minutes     map[int]bool
+	hours       map[int]bool
+	daysOfMonth map[int]bool
+	months      map[int]bool
+	daysOfWeek  map[int]bool
+	domAny      bool
+	dowAny      bool
+}
+
+// ParseCronExpr parses the cron expression and returns a *CronExpr
+func ParseCronExpr(expr string) (*CronExpr, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != cronFieldNum {
+		return nil, message.NewMessage(msghc.ErrScheduleCronExprInvalid, expr)
+	}
+
+	values := make([]map[int]bool, cronFieldNum)
+	for i, field := range fields {
+		value, err := parseCronField(field, cronFieldBounds[i][0], cronFieldBounds[i][1])
+		if err != nil {
+			return nil, message.NewMessage(msghc.ErrScheduleCronExprInvalid, expr)
+		}
+		values[i] = value
+	}
+
+	return &CronExpr{
+		expr:        expr,
+		minutes:     values[0],
+		hours:       values[1],
+		daysOfMonth: values[2],
+		months:      values[3],
+		daysOfWeek:  values[4],
+		domAny:      fields[2] == cronAnyValue,
+		dowAny:      fields[4] == cronAnyValue,
+	}, nil
+}
+
+// String returns the original cron expression
+func (ce *CronExpr) String() string {
+	return ce.expr
+}
+
+// Next returns the first time which matches the cron expression and is later than given time,
+// it returns zero time if there is no matched time in the following years
+func (ce *CronExpr) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(cronMaxSearchYear, constant.ZeroInt, constant.ZeroInt)
+
+	for t.Before(limit) {
+		if !ce.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !ce.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !ce.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !ce.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// matchDay returns if the day of given time matches the cron expression,
+// as the standard cron does, if both day of month and day of week are restricted, matching either of them is enough
+func (ce *CronExpr) matchDay(t time.Time) bool {
+	domMatched := ce.daysOfMonth[t.Day()]
+	dowMatched := ce.daysOfWeek[int(t.Weekday())]
+
+	switch {
+	case ce.domAny && ce.dowAny:
+		return true
+	case ce.domAny:
+		return dowMatched
+	case ce.dowAny:
+		return domMatched
+	default:
+		return domMatched || dowMatched
+	}
+}
+
+// parseCronField parses a field of the cron expression and returns the valid values of the field
+func parseCronField(field string, min, max int) (map[int]bool, error) {
+	values := make(map[int]bool)
+
+	for _, part := range strings.Split(field, constant.CommaString) {
+		rangeStr, step := part, 1
+		if strings.Contains(part, cronStepSep) {
+			list := strings.SplitN(part, cronStepSep, 2)
+			s, err := strconv.Atoi(list[1])
+			if err != nil || s <= constant.ZeroInt {
+				return nil, message.NewMessage(msghc.ErrScheduleCronExprInvalid, field)
+			}
+			rangeStr, step = list[0], s
+		}
+
+		start, end := min, max
+		if rangeStr != cronAnyValue {
+			list := strings.SplitN(rangeStr, cronRangeSep, 2)
+			var err error
+			start, err = strconv.Atoi(list[0])
+			if err != nil {
+				return nil, message.NewMessage(msghc.ErrScheduleCronExprInvalid, field)
+			}
+			end = start
+			if len(list) == 2 {
+				end, err = strconv.Atoi(list[1])
+				if err != nil {
+					return nil, message.NewMessage(msghc.ErrScheduleCronExprInvalid, field)
+				}
+			} else if step > 1 {
+				// 5/10 means from 5 to max every 10
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return nil, message.NewMessage(msghc.ErrScheduleCronExprInvalid, field)
+		}
+
+		for i := start; i <= end; i += step {
+			values[i] = true
+		}
+	}
+
+	return values, nil
+}
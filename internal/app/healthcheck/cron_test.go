@@ -0,0 +1,59 @@
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronAll(t *testing.T) {
+	TestCron_ParseCronExpr(t)
+	TestCron_Next(t)
+}
+
+func TestCron_ParseCronExpr(t *testing.T) {
+	asst := assert.New(t)
+
+	validExprs := []string{"* * * * *", "*/5 * * * *", "0 8 * * 1-5", "0,30 8-18/2 1,15 * *", "5/10 0 * 1-6 0"}
+	for _, expr := range validExprs {
+		_, err := ParseCronExpr(expr)
+		asst.Nil(err, common.CombineMessageWithError("test ParseCronExpr() failed", err))
+	}
+
+	invalidExprs := []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "* * * * 7", "*/0 * * * *", "5-1 * * * *", "a * * * *"}
+	for _, expr := range invalidExprs {
+		_, err := ParseCronExpr(expr)
+		asst.NotNil(err, "test ParseCronExpr() failed, %s should not be valid", expr)
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	asst := assert.New(t)
+
+	// 2021-01-21 is thursday
+	now := time.Date(2021, 1, 21, 10, 7, 30, 0, time.Local)
+	cases := []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"* * * * *", time.Date(2021, 1, 21, 10, 8, 0, 0, time.Local)},
+		{"*/15 * * * *", time.Date(2021, 1, 21, 10, 15, 0, 0, time.Local)},
+		{"0 8 * * *", time.Date(2021, 1, 22, 8, 0, 0, 0, time.Local)},
+		{"0 8 * * 1", time.Date(2021, 1, 25, 8, 0, 0, 0, time.Local)},
+		{"0 0 1 * *", time.Date(2021, 2, 1, 0, 0, 0, 0, time.Local)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.Local)},
+		// day of month or day of week
+		{"0 0 31 * 6", time.Date(2021, 1, 23, 0, 0, 0, 0, time.Local)},
+	}
+	for _, c := range cases {
+		ce, err := ParseCronExpr(c.expr)
+		asst.Nil(err, common.CombineMessageWithError("test Next() failed", err))
+		asst.True(c.expected.Equal(ce.Next(now)), "test Next() failed, expr: %s, expected: %s, actual: %s", c.expr, c.expected, ce.Next(now))
+	}
+
+	ce, err := ParseCronExpr("0 0 31 2 *")
+	asst.Nil(err, common.CombineMessageWithError("test Next() failed", err))
+	asst.True(ce.Next(now).IsZero(), "test Next() failed")
+}
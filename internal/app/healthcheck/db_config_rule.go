@@ -0,0 +1,318 @@
+package healthcheck
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-version"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	DBConfigRuleOperatorEq    = "eq"
+	DBConfigRuleOperatorGte   = "gte"
+	DBConfigRuleOperatorIn    = "in"
+	DBConfigRuleOperatorRegex = "regex"
+
+	DBConfigRuleRoleAll     = 0
+	DBConfigRuleRolePrimary = 1
+	DBConfigRuleRoleReplica = 2
+
+	DBConfigRuleSeverityLow    = 1
+	DBConfigRuleSeverityMedium = 2
+	DBConfigRuleSeverityHigh   = 3
+
+	// the placeholders in the expected value will be replaced with the address of the mysql server which is being checked
+	dbConfigRuleHostIPPlaceholder  = "${host_ip}"
+	dbConfigRulePortNumPlaceholder = "${port_num}"
+	dbConfigRuleInSeparator        = ","
+)
+
+var (
+	_ healthcheck.DBConfigRule = (*DBConfigRule)(nil)
+
+	// dbConfigSeverityFactors are the factors of the score deduction of the invalid variables,
+	// the low severity rule is only an advice, it does not deduct the score
+	dbConfigSeverityFactors = map[int]float64{
+		DBConfigRuleSeverityLow:    0,
+		DBConfigRuleSeverityMedium: 0.5,
+		DBConfigRuleSeverityHigh:   1,
+	}
+)
+
+// DBConfigRule is a rule which checks the value of a database variable
+type DBConfigRule struct {
+	ID              int       `middleware:"id" json:"id"`
+	RuleSetVersion  int       `middleware:"rule_set_version" json:"rule_set_version"`
+	VariableName    string    `middleware:"variable_name" json:"variable_name"`
+	Operator        string    `middleware:"operator" json:"operator"`
+	ExpectedValue   string    `middleware:"expected_value" json:"expected_value"`
+	MinMySQLVersion string    `middleware:"min_mysql_version" json:"min_mysql_version"`
+	MaxMySQLVersion string    `middleware:"max_mysql_version" json:"max_mysql_version"`
+	EnvID           int       `middleware:"env_id" json:"env_id"`
+	Role            int       `middleware:"role" json:"role"`
+	Severity        int       `middleware:"severity" json:"severity"`
+	Advice          string    `middleware:"advice" json:"advice"`
+	DelFlag         int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime      time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime  time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewEmptyDBConfigRule returns a new empty *DBConfigRule
+func NewEmptyDBConfigRule() *DBConfigRule {
+	return &DBConfigRule{}
+}
+
+// NewDBConfigRule returns a new *DBConfigRule which applies to all the mysql versions, envs and roles
+func NewDBConfigRule(variableName, operator, expectedValue string, severity int, advice string) *DBConfigRule {
+	return &DBConfigRule{
+		VariableName:  variableName,
+		Operator:      operator,
+		ExpectedValue: expectedValue,
+		Severity:      severity,
+		Advice:        advice,
+	}
+}
+
+// Identity returns the identity
+func (dcr *DBConfigRule) Identity() int {
+	return dcr.ID
+}
+
+// GetRuleSetVersion returns the version of the rule set which the rule belongs to
+func (dcr *DBConfigRule) GetRuleSetVersion() int {
+	return dcr.RuleSetVersion
+}
+
+// GetVariableName returns the variable name
+func (dcr *DBConfigRule) GetVariableName() string {
+	return dcr.VariableName
+}
+
+// GetOperator returns the comparison operator
+func (dcr *DBConfigRule) GetOperator() string {
+	return dcr.Operator
+}
+
+// GetExpectedValue returns the expected value
+func (dcr *DBConfigRule) GetExpectedValue() string {
+	return dcr.ExpectedValue
+}
+
+// GetMinMySQLVersion returns the minimum mysql version which the rule applies to, it is empty if there is no lower limit
+func (dcr *DBConfigRule) GetMinMySQLVersion() string {
+	return dcr.MinMySQLVersion
+}
+
+// GetMaxMySQLVersion returns the maximum mysql version which the rule applies to, it is empty if there is no upper limit
+func (dcr *DBConfigRule) GetMaxMySQLVersion() string {
+	return dcr.MaxMySQLVersion
+}
+
+// GetEnvID returns the env id which the rule applies to, it is 0 if the rule applies to all the envs
+func (dcr *DBConfigRule) GetEnvID() int {
+	return dcr.EnvID
+}
+
+// GetRole returns the role of the mysql server which the rule applies to, it is 0 if the rule applies to all the roles
+func (dcr *DBConfigRule) GetRole() int {
+	return dcr.Role
+}
+
+// GetSeverity returns the severity
+func (dcr *DBConfigRule) GetSeverity() int {
+	return dcr.Severity
+}
+
+// GetAdvice returns the advice
+func (dcr *DBConfigRule) GetAdvice() string {
+	return dcr.Advice
+}
+
+// GetDelFlag returns the delete flag
+func (dcr *DBConfigRule) GetDelFlag() int {
+	return dcr.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (dcr *DBConfigRule) GetCreateTime() time.Time {
+	return dcr.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (dcr *DBConfigRule) GetLastUpdateTime() time.Time {
+	return dcr.LastUpdateTime
+}
+
+// Validate validates if the operator, severity and role of the rule are valid
+func (dcr *DBConfigRule) Validate() error {
+	switch dcr.GetOperator() {
+	case DBConfigRuleOperatorEq, DBConfigRuleOperatorGte, DBConfigRuleOperatorIn, DBConfigRuleOperatorRegex:
+	default:
+		return message.NewMessage(msghc.ErrDBConfigRuleOperatorInvalid, dcr.GetOperator(), dcr.Identity())
+	}
+	_, exists := dbConfigSeverityFactors[dcr.GetSeverity()]
+	if !exists {
+		return message.NewMessage(msghc.ErrDBConfigRuleSeverityInvalid, dcr.GetSeverity(), dcr.Identity())
+	}
+	if dcr.GetRole() < DBConfigRuleRoleAll || dcr.GetRole() > DBConfigRuleRoleReplica {
+		return message.NewMessage(msghc.ErrDBConfigRuleRoleInvalid, dcr.GetRole(), dcr.Identity())
+	}
+
+	return nil
+}
+
+// IsApplicable returns if the rule applies to the mysql server with given mysql version, env id and role,
+// the minimum mysql version is inclusive and the maximum mysql version is exclusive
+func (dcr *DBConfigRule) IsApplicable(mysqlVersion string, envID, role int) (bool, error) {
+	if dcr.GetEnvID() != constant.ZeroInt && dcr.GetEnvID() != envID {
+		return false, nil
+	}
+	if dcr.GetRole() != DBConfigRuleRoleAll && dcr.GetRole() != role {
+		return false, nil
+	}
+	if dcr.GetMinMySQLVersion() == constant.EmptyString && dcr.GetMaxMySQLVersion() == constant.EmptyString {
+		return true, nil
+	}
+
+	serverVersion, err := version.NewVersion(mysqlVersion)
+	if err != nil {
+		return false, err
+	}
+	if dcr.GetMinMySQLVersion() != constant.EmptyString {
+		minVersion, err := version.NewVersion(dcr.GetMinMySQLVersion())
+		if err != nil {
+			return false, err
+		}
+		if serverVersion.LessThan(minVersion) {
+			return false, nil
+		}
+	}
+	if dcr.GetMaxMySQLVersion() != constant.EmptyString {
+		maxVersion, err := version.NewVersion(dcr.GetMaxMySQLVersion())
+		if err != nil {
+			return false, err
+		}
+		if !serverVersion.LessThan(maxVersion) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Match returns if the value satisfies the rule, the eq and in operators are case-insensitive,
+// the regex operator matches the whole value, and the value which is not a number never satisfies the gte operator
+func (dcr *DBConfigRule) Match(value, expectedValue string) (bool, error) {
+	switch dcr.GetOperator() {
+	case DBConfigRuleOperatorEq:
+		return strings.EqualFold(value, expectedValue), nil
+	case DBConfigRuleOperatorGte:
+		expected, err := strconv.ParseFloat(expectedValue, 64)
+		if err != nil {
+			return false, err
+		}
+		actual, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false, nil
+		}
+
+		return actual >= expected, nil
+	case DBConfigRuleOperatorIn:
+		for _, expected := range strings.Split(expectedValue, dbConfigRuleInSeparator) {
+			if strings.EqualFold(value, strings.TrimSpace(expected)) {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	case DBConfigRuleOperatorRegex:
+		re, err := regexp.Compile("^(?:" + expectedValue + ")$")
+		if err != nil {
+			return false, err
+		}
+
+		return re.MatchString(value), nil
+	default:
+		return false, message.NewMessage(msghc.ErrDBConfigRuleOperatorInvalid, dcr.GetOperator(), dcr.Identity())
+	}
+}
+
+// MarshalJSON marshals DBConfigRule to json bytes
+func (dcr *DBConfigRule) MarshalJSON() ([]byte, error) {
+	return common.MarshalStructWithTag(dcr, constant.DefaultMarshalTag)
+}
+
+// getDBConfigRuleSpecificity returns how specific the scope of the rule is,
+// when there are multiple applicable rules of the same variable, the most specific one takes effect
+func getDBConfigRuleSpecificity(rule healthcheck.DBConfigRule) int {
+	var specificity int
+	if rule.GetEnvID() != constant.ZeroInt {
+		specificity += 4
+	}
+	if rule.GetRole() != DBConfigRuleRoleAll {
+		specificity += 2
+	}
+	if rule.GetMinMySQLVersion() != constant.EmptyString || rule.GetMaxMySQLVersion() != constant.EmptyString {
+		specificity++
+	}
+
+	return specificity
+}
+
+// getDBConfigRuleExpectedValue returns the expected value of the rule,
+// the placeholders will be replaced with given host ip and port number
+func getDBConfigRuleExpectedValue(rule healthcheck.DBConfigRule, hostIP string, portNum int) string {
+	return strings.NewReplacer(
+		dbConfigRuleHostIPPlaceholder, hostIP,
+		dbConfigRulePortNumPlaceholder, strconv.Itoa(portNum),
+	).Replace(rule.GetExpectedValue())
+}
+
+// filterDBConfigRules returns the rules which apply to the mysql server with given mysql version, env id and role,
+// if there are multiple applicable rules of the same variable, only the most specific one will be kept,
+// the returned rules are sorted by the variable name
+func filterDBConfigRules(rules []healthcheck.DBConfigRule, mysqlVersion string, envID, role int) ([]healthcheck.DBConfigRule, error) {
+	ruleMap := make(map[string]healthcheck.DBConfigRule)
+	for _, rule := range rules {
+		err := rule.Validate()
+		if err != nil {
+			return nil, err
+		}
+		isApplicable, err := rule.IsApplicable(mysqlVersion, envID, role)
+		if err != nil {
+			return nil, err
+		}
+		if !isApplicable {
+			continue
+		}
+
+		variableName := strings.ToLower(rule.GetVariableName())
+		existing, exists := ruleMap[variableName]
+		if exists {
+			existingSpecificity := getDBConfigRuleSpecificity(existing)
+			specificity := getDBConfigRuleSpecificity(rule)
+			if existingSpecificity > specificity || existingSpecificity == specificity && existing.Identity() > rule.Identity() {
+				continue
+			}
+		}
+		ruleMap[variableName] = rule
+	}
+
+	applicableRules := make([]healthcheck.DBConfigRule, constant.ZeroInt, len(ruleMap))
+	for _, rule := range ruleMap {
+		applicableRules = append(applicableRules, rule)
+	}
+	sort.Slice(applicableRules, func(i, j int) bool {
+		return applicableRules[i].GetVariableName() < applicableRules[j].GetVariableName()
+	})
+
+	return applicableRules, nil
+}
@@ -0,0 +1,118 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBConfigRuleAll(t *testing.T) {
+	TestDBConfigRule_Validate(t)
+	TestDBConfigRule_IsApplicable(t)
+	TestDBConfigRule_Match(t)
+	TestDBConfigRule_filterDBConfigRules(t)
+}
+
+func TestDBConfigRule_Validate(t *testing.T) {
+	asst := assert.New(t)
+
+	rule := NewDBConfigRule(dbConfigLogBin, DBConfigRuleOperatorEq, "ON", DBConfigRuleSeverityHigh, "")
+	err := rule.Validate()
+	asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
+
+	rule = NewDBConfigRule(dbConfigLogBin, "ne", "ON", DBConfigRuleSeverityHigh, "")
+	asst.NotNil(rule.Validate(), "test Validate() failed")
+	rule = NewDBConfigRule(dbConfigLogBin, DBConfigRuleOperatorEq, "ON", 4, "")
+	asst.NotNil(rule.Validate(), "test Validate() failed")
+	rule = NewDBConfigRule(dbConfigLogBin, DBConfigRuleOperatorEq, "ON", DBConfigRuleSeverityHigh, "")
+	rule.Role = 3
+	asst.NotNil(rule.Validate(), "test Validate() failed")
+}
+
+func TestDBConfigRule_IsApplicable(t *testing.T) {
+	asst := assert.New(t)
+
+	rule := NewDBConfigRule(dbConfigLogBin, DBConfigRuleOperatorEq, "ON", DBConfigRuleSeverityHigh, "")
+	rule.MinMySQLVersion = "5.7"
+	rule.MaxMySQLVersion = "8.0.23"
+	rule.EnvID = 1
+	rule.Role = DBConfigRuleRoleReplica
+
+	cases := []struct {
+		mysqlVersion string
+		envID        int
+		role         int
+		expected     bool
+	}{
+		{"5.7.21", 1, DBConfigRuleRoleReplica, true},
+		{"8.0.22", 1, DBConfigRuleRoleReplica, true},
+		{"8.0.23", 1, DBConfigRuleRoleReplica, false},
+		{"5.6.40", 1, DBConfigRuleRoleReplica, false},
+		{"5.7.21", 2, DBConfigRuleRoleReplica, false},
+		{"5.7.21", 1, DBConfigRuleRolePrimary, false},
+	}
+	for _, c := range cases {
+		isApplicable, err := rule.IsApplicable(c.mysqlVersion, c.envID, c.role)
+		asst.Nil(err, common.CombineMessageWithError("test IsApplicable() failed", err))
+		asst.Equal(c.expected, isApplicable, "test IsApplicable() failed, version: %s, env_id: %d, role: %d", c.mysqlVersion, c.envID, c.role)
+	}
+}
+
+func TestDBConfigRule_Match(t *testing.T) {
+	asst := assert.New(t)
+
+	cases := []struct {
+		operator      string
+		value         string
+		expectedValue string
+		expected      bool
+	}{
+		{DBConfigRuleOperatorEq, "on", "ON", true},
+		{DBConfigRuleOperatorEq, "OFF", "ON", false},
+		{DBConfigRuleOperatorGte, "2000", "2000", true},
+		{DBConfigRuleOperatorGte, "1000", "2000", false},
+		{DBConfigRuleOperatorGte, "abc", "2000", false},
+		{DBConfigRuleOperatorIn, "mixed", "ROW, MIXED", true},
+		{DBConfigRuleOperatorIn, "STATEMENT", "ROW, MIXED", false},
+		{DBConfigRuleOperatorRegex, "O_DIRECT_NO_FSYNC", "O_DIRECT.*", true},
+		{DBConfigRuleOperatorRegex, "fsync", "O_DIRECT.*", false},
+	}
+	for _, c := range cases {
+		rule := NewDBConfigRule(dbConfigLogBin, c.operator, c.expectedValue, DBConfigRuleSeverityHigh, "")
+		ok, err := rule.Match(c.value, c.expectedValue)
+		asst.Nil(err, common.CombineMessageWithError("test Match() failed", err))
+		asst.Equal(c.expected, ok, "test Match() failed, operator: %s, value: %s, expected value: %s", c.operator, c.value, c.expectedValue)
+	}
+
+	rule := NewDBConfigRule(dbConfigLogBin, DBConfigRuleOperatorRegex, "(", DBConfigRuleSeverityHigh, "")
+	_, err := rule.Match("ON", rule.GetExpectedValue())
+	asst.NotNil(err, "test Match() failed")
+}
+
+func TestDBConfigRule_filterDBConfigRules(t *testing.T) {
+	asst := assert.New(t)
+
+	globalRule := NewDBConfigRule(dbConfigSyncBinlog, DBConfigRuleOperatorEq, "1", DBConfigRuleSeverityHigh, "")
+	globalRule.ID = 1
+	envRule := NewDBConfigRule(dbConfigSyncBinlog, DBConfigRuleOperatorIn, "0, 1", DBConfigRuleSeverityLow, "")
+	envRule.ID = 2
+	envRule.EnvID = 2
+	replicaRule := NewDBConfigRule(dbConfigReportHost, DBConfigRuleOperatorEq, dbConfigRuleHostIPPlaceholder, DBConfigRuleSeverityMedium, "")
+	replicaRule.ID = 3
+	replicaRule.Role = DBConfigRuleRoleReplica
+	rules := []healthcheck.DBConfigRule{globalRule, envRule, replicaRule}
+
+	applicableRules, err := filterDBConfigRules(rules, "5.7.21", 1, DBConfigRuleRolePrimary)
+	asst.Nil(err, common.CombineMessageWithError("test filterDBConfigRules() failed", err))
+	asst.Equal(1, len(applicableRules), "test filterDBConfigRules() failed")
+	asst.Equal(globalRule.Identity(), applicableRules[0].Identity(), "test filterDBConfigRules() failed")
+
+	applicableRules, err = filterDBConfigRules(rules, "5.7.21", 2, DBConfigRuleRoleReplica)
+	asst.Nil(err, common.CombineMessageWithError("test filterDBConfigRules() failed", err))
+	asst.Equal(2, len(applicableRules), "test filterDBConfigRules() failed")
+	asst.Equal(replicaRule.Identity(), applicableRules[0].Identity(), "test filterDBConfigRules() failed")
+	asst.Equal(envRule.Identity(), applicableRules[1].Identity(), "test filterDBConfigRules() failed")
+	asst.Equal("192.168.10.210", getDBConfigRuleExpectedValue(applicableRules[0], "192.168.10.210", 3306), "test filterDBConfigRules() failed")
+}
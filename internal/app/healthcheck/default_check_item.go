@@ -0,0 +1,464 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/app/sqladvisor"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	depquery "github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+var (
+	_ healthcheck.CheckItem = (*DBConfigItem)(nil)
+	_ healthcheck.CheckItem = (*PrometheusItem)(nil)
+	_ healthcheck.CheckItem = (*TableRowsItem)(nil)
+	_ healthcheck.CheckItem = (*TableSizeItem)(nil)
+	_ healthcheck.CheckItem = (*SlowQueryItem)(nil)
+)
+
+func init() {
+	registerDefaultCheckItems()
+}
+
+// registerDefaultCheckItems registers the built-in check items, the registration order is the execution order
+func registerDefaultCheckItems() {
+	creators := []struct {
+		name    string
+		creator CheckItemCreator
+	}{
+		{defaultDBConfigItemName, NewDBConfigItem},
+		{defaultCPUUsageItemName, func(de *DefaultEngine) healthcheck.CheckItem {
+			return NewPrometheusItem(defaultCPUUsageItemName, de.GetPrometheusRepo().GetCPUUsage)
+		}},
+		{defaultIOUtilItemName, func(de *DefaultEngine) healthcheck.CheckItem {
+			return NewPrometheusItem(defaultIOUtilItemName, de.GetPrometheusRepo().GetIOUtil)
+		}},
+		{defaultDiskCapacityUsageItemName, func(de *DefaultEngine) healthcheck.CheckItem {
+			return NewPrometheusItem(defaultDiskCapacityUsageItemName, func() ([]healthcheck.PrometheusData, error) {
+				return de.GetPrometheusRepo().GetDiskCapacityUsage(de.GetMountPoints())
+			})
+		}},
+		{defaultConnectionUsageItemName, func(de *DefaultEngine) healthcheck.CheckItem {
+			return NewPrometheusItem(defaultConnectionUsageItemName, de.GetPrometheusRepo().GetConnectionUsage)
+		}},
+		{defaultAverageActiveSessionPercentsItemName, func(de *DefaultEngine) healthcheck.CheckItem {
+			return NewPrometheusItem(defaultAverageActiveSessionPercentsItemName, de.GetPrometheusRepo().GetAverageActiveSessionPercents)
+		}},
+		{defaultCacheMissRatioItemName, func(de *DefaultEngine) healthcheck.CheckItem {
+			return NewPrometheusItem(defaultCacheMissRatioItemName, de.GetPrometheusRepo().GetCacheMissRatio)
+		}},
+		{defaultTableRowsItemName, NewTableRowsItem},
+		{defaultTableSizeItemName, NewTableSizeItem},
+		{defaultSlowQueryRowsExaminedItemName, NewSlowQueryItem},
+		{defaultReplicationItemName, NewReplicationItem},
+		{defaultInnoDBLockItemName, NewInnoDBLockItem},
+		{defaultIndexHealthItemName, NewIndexHealthItem},
+	}
+
+	for _, c := range creators {
+		err := RegisterCheckItem(c.name, c.creator)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// DBConfigItem checks the database configuration
+type DBConfigItem struct {
+	engine          *DefaultEngine
+	globalVariables []healthcheck.Variable
+	variables       []*Variable
+}
+
+// NewDBConfigItem returns a new healthcheck.CheckItem which checks the database configuration
+func NewDBConfigItem(de *DefaultEngine) healthcheck.CheckItem {
+	return &DBConfigItem{engine: de}
+}
+
+// GetName returns the item name
+func (dci *DBConfigItem) GetName() string {
+	return defaultDBConfigItemName
+}
+
+// Collect collects the global variables and finds out the ones which do not satisfy the db config rules,
+// only the rules which apply to the mysql version, env and role of the mysql server are checked
+func (dci *DBConfigItem) Collect() error {
+	// load db config rules
+	rules, err := dci.getApplicableRules()
+	if err != nil {
+		return err
+	}
+	ruleMap := make(map[string]healthcheck.DBConfigRule, len(rules))
+	configItems := make([]string, len(rules))
+	for i, rule := range rules {
+		ruleMap[strings.ToLower(rule.GetVariableName())] = rule
+		configItems[i] = rule.GetVariableName()
+	}
+
+	globalVariables, err := dci.engine.GetApplicationMySQLRepo().GetVariables(configItems)
+	if err != nil {
+		return err
+	}
+	dci.globalVariables = globalVariables
+
+	mysqlServer := dci.engine.GetOperationInfo().GetMySQLServer()
+	for _, globalVariable := range globalVariables {
+		rule, exists := ruleMap[strings.ToLower(globalVariable.GetName())]
+		if !exists {
+			continue
+		}
+		expectedValue := getDBConfigRuleExpectedValue(rule, mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
+		ok, err := rule.Match(globalVariable.GetValue(), expectedValue)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			dci.variables = append(dci.variables, NewInvalidVariable(rule, globalVariable.GetValue(), expectedValue))
+		}
+	}
+
+	return nil
+}
+
+// getApplicableRules returns the db config rules which apply to the mysql server
+func (dci *DBConfigItem) getApplicableRules() ([]healthcheck.DBConfigRule, error) {
+	rules, err := dci.engine.GetDASRepo().GetDBConfigRules()
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == constant.ZeroInt {
+		return nil, message.NewMessage(msghc.ErrDBConfigRuleEmpty)
+	}
+	// get env id
+	mysqlServer := dci.engine.GetOperationInfo().GetMySQLServer()
+	mcs := metadata.NewMySQLClusterServiceWithDefault()
+	err = mcs.GetByID(mysqlServer.GetClusterID())
+	if err != nil {
+		return nil, err
+	}
+	envID := mcs.GetMySQLClusters()[constant.ZeroInt].GetEnvID()
+	// get role
+	role := DBConfigRuleRolePrimary
+	isReplica, err := dci.engine.GetApplicationMySQLRepo().IsReplica()
+	if err != nil {
+		return nil, err
+	}
+	if isReplica {
+		role = DBConfigRuleRoleReplica
+	}
+
+	return filterDBConfigRules(rules, mysqlServer.GetVersion(), envID, role)
+}
+
+// Score scores the database configuration, every invalid variable deducts the score by the severity of the rule,
+// the variable of the high severity rule deducts the score deduction per unit high,
+// the variable of the medium severity rule deducts half of it, and the variable of the low severity rule does not deduct the score
+func (dci *DBConfigItem) Score(config healthcheck.ItemConfig) (int, error) {
+	// database config score deduction
+	var units float64
+	for _, variable := range dci.variables {
+		units += dbConfigSeverityFactors[variable.Severity]
+	}
+	scoreDeduction := units * config.GetScoreDeductionPerUnitHigh()
+	if scoreDeduction > config.GetMaxScoreDeductionHigh() {
+		scoreDeduction = config.GetMaxScoreDeductionHigh()
+	}
+	score := int(defaultMaxScore - scoreDeduction)
+	if score < constant.ZeroInt {
+		score = constant.ZeroInt
+	}
+
+	return score, nil
+}
+
+// Advice returns the global variables and the advice of the invalid variables
+func (dci *DBConfigItem) Advice() (string, string, string, error) {
+	// database config data
+	jsonBytesTotal, err := json.Marshal(dci.globalVariables)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	// database config advice
+	jsonBytesVariables, err := json.Marshal(dci.variables)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	return string(jsonBytesTotal), constant.EmptyString, string(jsonBytesVariables), nil
+}
+
+// PrometheusItem checks the time series which are collected from the prometheus
+type PrometheusItem struct {
+	name      string
+	getDatas  func() ([]healthcheck.PrometheusData, error)
+	datas     []healthcheck.PrometheusData
+	highDatas []healthcheck.PrometheusData
+}
+
+// NewPrometheusItem returns a new *PrometheusItem which collects the data with given function
+func NewPrometheusItem(name string, getDatas func() ([]healthcheck.PrometheusData, error)) *PrometheusItem {
+	return &PrometheusItem{
+		name:     name,
+		getDatas: getDatas,
+	}
+}
+
+// GetName returns the item name
+func (pi *PrometheusItem) GetName() string {
+	return pi.name
+}
+
+// Collect collects the time series from the prometheus
+func (pi *PrometheusItem) Collect() error {
+	var err error
+
+	pi.datas, err = pi.getDatas()
+
+	return err
+}
+
+// Score scores the time series with the watermarks by the scoring strategy of the item
+func (pi *PrometheusItem) Score(config healthcheck.ItemConfig) (int, error) {
+	for _, data := range pi.datas {
+		if data.GetValue() >= config.GetHighWatermark() {
+			pi.highDatas = append(pi.highDatas, data)
+		}
+	}
+
+	scoreDeductionHigh, scoreDeductionMedium := getSeriesScoreDeductions(getPrometheusSeries(pi.datas), config)
+	// calculate score
+	score := int(defaultMaxScore - scoreDeductionHigh - scoreDeductionMedium)
+	if score < constant.ZeroInt {
+		score = constant.ZeroInt
+	}
+
+	return score, nil
+}
+
+// Advice returns the time series and the data which are higher than the high watermark
+func (pi *PrometheusItem) Advice() (string, string, string, error) {
+	jsonBytesTotal, err := json.Marshal(pi.datas)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	jsonBytesHigh, err := json.Marshal(pi.highDatas)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	return string(jsonBytesTotal), string(jsonBytesHigh), constant.EmptyString, nil
+}
+
+// TableRowsItem checks the table rows
+type TableRowsItem struct {
+	engine     *DefaultEngine
+	tables     []healthcheck.Table
+	highTables []healthcheck.Table
+}
+
+// NewTableRowsItem returns a new healthcheck.CheckItem which checks the table rows
+func NewTableRowsItem(de *DefaultEngine) healthcheck.CheckItem {
+	return &TableRowsItem{engine: de}
+}
+
+// GetName returns the item name
+func (tri *TableRowsItem) GetName() string {
+	return defaultTableRowsItemName
+}
+
+// Collect collects the large tables
+func (tri *TableRowsItem) Collect() error {
+	var err error
+
+	tri.tables, err = tri.engine.GetApplicationMySQLRepo().GetLargeTables()
+
+	return err
+}
+
+// Score scores the table rows with the watermarks
+func (tri *TableRowsItem) Score(config healthcheck.ItemConfig) (int, error) {
+	values := make([]float64, len(tri.tables))
+	for i, table := range tri.tables {
+		values[i] = float64(table.GetRows())
+		if float64(table.GetRows()) >= config.GetHighWatermark() {
+			tri.highTables = append(tri.highTables, table)
+		}
+	}
+
+	scoreDeductionHigh, scoreDeductionMedium := getScoreDeductions(values, config)
+	// table rows score
+	score := int(defaultMaxScore - scoreDeductionHigh - scoreDeductionMedium)
+	if score < constant.ZeroInt {
+		score = constant.ZeroInt
+	}
+
+	return score, nil
+}
+
+// Advice returns the large tables and the tables of which the rows are higher than the high watermark
+func (tri *TableRowsItem) Advice() (string, string, string, error) {
+	return marshalTables(tri.tables, tri.highTables)
+}
+
+// TableSizeItem checks the table sizes
+type TableSizeItem struct {
+	engine     *DefaultEngine
+	tables     []healthcheck.Table
+	highTables []healthcheck.Table
+}
+
+// NewTableSizeItem returns a new healthcheck.CheckItem which checks the table sizes
+func NewTableSizeItem(de *DefaultEngine) healthcheck.CheckItem {
+	return &TableSizeItem{engine: de}
+}
+
+// GetName returns the item name
+func (tsi *TableSizeItem) GetName() string {
+	return defaultTableSizeItemName
+}
+
+// Collect collects the large tables
+func (tsi *TableSizeItem) Collect() error {
+	var err error
+
+	tsi.tables, err = tsi.engine.GetApplicationMySQLRepo().GetLargeTables()
+
+	return err
+}
+
+// Score scores the table sizes with the watermarks
+func (tsi *TableSizeItem) Score(config healthcheck.ItemConfig) (int, error) {
+	values := make([]float64, len(tsi.tables))
+	for i, table := range tsi.tables {
+		values[i] = table.GetSize()
+		if table.GetSize() >= config.GetHighWatermark() {
+			tsi.highTables = append(tsi.highTables, table)
+		}
+	}
+
+	scoreDeductionHigh, scoreDeductionMedium := getScoreDeductions(values, config)
+	// table size score
+	score := int(defaultMaxScore - scoreDeductionHigh - scoreDeductionMedium)
+	if score < constant.ZeroInt {
+		score = constant.ZeroInt
+	}
+
+	return score, nil
+}
+
+// Advice returns the large tables and the tables of which the sizes are higher than the high watermark
+func (tsi *TableSizeItem) Advice() (string, string, string, error) {
+	return marshalTables(tsi.tables, tsi.highTables)
+}
+
+// marshalTables marshals the tables and the high tables to json strings
+func marshalTables(tables, highTables []healthcheck.Table) (string, string, string, error) {
+	jsonBytesTotal, err := json.Marshal(tables)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	jsonBytesHigh, err := json.Marshal(highTables)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	return string(jsonBytesTotal), string(jsonBytesHigh), constant.EmptyString, nil
+}
+
+// SlowQueryItem checks the slow queries
+type SlowQueryItem struct {
+	engine      *DefaultEngine
+	slowQueries []depquery.Query
+}
+
+// NewSlowQueryItem returns a new healthcheck.CheckItem which checks the slow queries
+func NewSlowQueryItem(de *DefaultEngine) healthcheck.CheckItem {
+	return &SlowQueryItem{engine: de}
+}
+
+// GetName returns the item name
+func (sqi *SlowQueryItem) GetName() string {
+	return defaultSlowQueryRowsExaminedItemName
+}
+
+// Collect collects the slow queries
+func (sqi *SlowQueryItem) Collect() error {
+	var err error
+
+	sqi.slowQueries, err = sqi.engine.GetQueryRepo().GetSlowQuery()
+
+	return err
+}
+
+// Score scores the slow queries with the watermarks of the rows examined
+func (sqi *SlowQueryItem) Score(config healthcheck.ItemConfig) (int, error) {
+	values := make([]float64, len(sqi.slowQueries))
+	for i, slowQuery := range sqi.slowQueries {
+		values[i] = float64(slowQuery.GetRowsExaminedMax())
+	}
+
+	scoreDeductionHigh, scoreDeductionMedium := getScoreDeductions(values, config)
+	// slow query score
+	score := int(defaultMaxScore - scoreDeductionHigh - scoreDeductionMedium)
+	if score < defaultMinScore {
+		score = defaultMinScore
+	}
+
+	return score, nil
+}
+
+// Advice returns the slow queries and the tuning advice of the top slow queries
+func (sqi *SlowQueryItem) Advice() (string, string, string, error) {
+	// slow query data
+	jsonBytesSlowQueries, err := json.Marshal(sqi.slowQueries)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	topSQLList := sqi.slowQueries
+	if len(topSQLList) > defaultSlowQueryTopSQLNum {
+		topSQLList = topSQLList[:defaultSlowQueryTopSQLNum]
+	}
+
+	// sql tuning
+	clusterID := sqi.engine.GetOperationInfo().GetMySQLServer().GetClusterID()
+	// init db service
+	dbService := metadata.NewDBServiceWithDefault()
+
+	var adviceList []string
+	for _, sql := range topSQLList {
+		var advice string
+
+		// get db info
+		if sql.GetDBName() != constant.EmptyString {
+			err = dbService.GetByNameAndClusterInfo(sql.GetDBName(), clusterID, defaultClusterType)
+			if err != nil {
+				return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+			}
+			// get db id
+			dbID := dbService.GetDBs()[constant.ZeroInt].Identity()
+			// init sql advisor service
+			advisorService := sqladvisor.NewServiceWithDefault()
+			// get advice
+			advice, err = advisorService.Advise(dbID, sql.GetExample())
+			if err != nil {
+				return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+			}
+		} else {
+			jsonBytes, err := json.Marshal(sql)
+			if err != nil {
+				return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+			}
+			advice = string(jsonBytes)
+		}
+
+		adviceList = append(adviceList, advice)
+	}
+
+	return string(jsonBytesSlowQueries), constant.EmptyString, strings.Join(adviceList, constant.CommaString), nil
+}
@@ -1,21 +1,24 @@
 package healthcheck
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/das/config"
 	"github.com/romberli/das/internal/app/metadata"
-	"github.com/romberli/das/internal/app/sqladvisor"
 	"github.com/romberli/das/internal/dependency/healthcheck"
-	depquery "github.com/romberli/das/internal/dependency/query"
 	"github.com/romberli/das/pkg/message"
 	msghc "github.com/romberli/das/pkg/message/healthcheck"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/linux"
+	"github.com/romberli/go-util/middleware/mysql"
 	"github.com/romberli/log"
+	"github.com/spf13/viper"
 )
 
 const (
@@ -33,6 +36,9 @@ const (
 	defaultTableRowsItemName                    = "table_rows"
 	defaultTableSizeItemName                    = "table_size"
 	defaultSlowQueryRowsExaminedItemName        = "slow_query_rows_examined"
+	defaultReplicationItemName                  = "replication"
+	defaultInnoDBLockItemName                   = "innodb_lock"
+	defaultIndexHealthItemName                  = "index_health"
 	defaultSlowQueryTopSQLNum                   = 3
 	defaultClusterType                          = 1
 )
@@ -43,15 +49,22 @@ var (
 
 // DefaultEngine work for health check module
 type DefaultEngine struct {
-	operationInfo        *OperationInfo
-	engineConfig         DefaultEngineConfig
-	result               *Result
-	mountPoints          []string
-	devices              []string
-	dasRepo              healthcheck.DASRepo
-	applicationMySQLRepo healthcheck.ApplicationMySQLRepo
-	prometheusRepo       healthcheck.PrometheusRepo
-	queryRepo            healthcheck.QueryRepo
+	operationInfo          *OperationInfo
+	engineConfig           DefaultEngineConfig
+	result                 *Result
+	mountPoints            []string
+	devices                []string
+	dasRepo                healthcheck.DASRepo
+	applicationMySQLRepo   healthcheck.ApplicationMySQLRepo
+	prometheusRepo         healthcheck.PrometheusRepo
+	queryRepo              healthcheck.QueryRepo
+	getPrimaryGTIDExecuted func(hostIP string, portNum int) (string, error)
+	checkItemRegistry      *CheckItemRegistry
+	itemTimeout            time.Duration
+	itemResults            []*ItemResult
+	itemWaitGroup          sync.WaitGroup
+	itemStatuses           map[string]string
+	itemStatusesMutex      sync.RWMutex
 }
 
 // NewDefaultEngine returns a new *DefaultEngine
@@ -60,7 +73,12 @@ func NewDefaultEngine(operationInfo *OperationInfo,
 	applicationMySQLRepo healthcheck.ApplicationMySQLRepo,
 	prometheusRepo healthcheck.PrometheusRepo,
 	queryRepo healthcheck.QueryRepo) *DefaultEngine {
-	return &DefaultEngine{
+	itemTimeout := viper.GetInt(config.HealthcheckItemTimeoutKey)
+	if itemTimeout <= constant.ZeroInt {
+		itemTimeout = config.DefaultHealthcheckItemTimeout
+	}
+
+	de := &DefaultEngine{
 		operationInfo:        operationInfo,
 		engineConfig:         NewEmptyDefaultEngineConfig(),
 		result:               NewEmptyResult(),
@@ -68,11 +86,17 @@ func NewDefaultEngine(operationInfo *OperationInfo,
 		applicationMySQLRepo: applicationMySQLRepo,
 		prometheusRepo:       prometheusRepo,
 		queryRepo:            queryRepo,
+		checkItemRegistry:    GetCheckItemRegistry(),
+		itemTimeout:          time.Duration(itemTimeout) * time.Second,
+		itemStatuses:         make(map[string]string),
 	}
+	de.getPrimaryGTIDExecuted = de.getPrimaryGTIDExecutedFromPrimary
+
+	return de
 }
 
-// getOperationInfo returns the operation information
-func (de *DefaultEngine) getOperationInfo() *OperationInfo {
+// GetOperationInfo returns the operation information
+func (de *DefaultEngine) GetOperationInfo() *OperationInfo {
 	return de.operationInfo
 }
 
@@ -86,33 +110,69 @@ func (de *DefaultEngine) getResult() *Result {
 	return de.result
 }
 
-// getMountPoints returns the mount points
-func (de *DefaultEngine) getMountPoints() []string {
+// getCheckItemRegistry returns the check item registry
+func (de *DefaultEngine) getCheckItemRegistry() *CheckItemRegistry {
+	return de.checkItemRegistry
+}
+
+// getItemTimeout returns the timeout of each check item
+func (de *DefaultEngine) getItemTimeout() time.Duration {
+	return de.itemTimeout
+}
+
+// GetItemResults returns the results of all the check items
+func (de *DefaultEngine) GetItemResults() []*ItemResult {
+	return de.itemResults
+}
+
+// GetItemStatuses returns a copy of the progress of each check item, the key is the item name
+func (de *DefaultEngine) GetItemStatuses() map[string]string {
+	de.itemStatusesMutex.RLock()
+	defer de.itemStatusesMutex.RUnlock()
+
+	itemStatuses := make(map[string]string, len(de.itemStatuses))
+	for itemName, status := range de.itemStatuses {
+		itemStatuses[itemName] = status
+	}
+
+	return itemStatuses
+}
+
+// setItemStatus sets the progress of the check item
+func (de *DefaultEngine) setItemStatus(itemName, status string) {
+	de.itemStatusesMutex.Lock()
+	defer de.itemStatusesMutex.Unlock()
+
+	de.itemStatuses[itemName] = status
+}
+
+// GetMountPoints returns the mount points
+func (de *DefaultEngine) GetMountPoints() []string {
 	return de.mountPoints
 }
 
-// getDevices returns the disk devices
-func (de *DefaultEngine) getDevices() []string {
+// GetDevices returns the disk devices
+func (de *DefaultEngine) GetDevices() []string {
 	return de.devices
 }
 
-// getDASRepo returns the das repository
-func (de *DefaultEngine) getDASRepo() healthcheck.DASRepo {
+// GetDASRepo returns the das repository
+func (de *DefaultEngine) GetDASRepo() healthcheck.DASRepo {
 	return de.dasRepo
 }
 
-// getApplicationMySQLRepo returns the application mysql repository
-func (de *DefaultEngine) getApplicationMySQLRepo() healthcheck.ApplicationMySQLRepo {
+// GetApplicationMySQLRepo returns the application mysql repository
+func (de *DefaultEngine) GetApplicationMySQLRepo() healthcheck.ApplicationMySQLRepo {
 	return de.applicationMySQLRepo
 }
 
-// getPrometheusRepo returns the prometheus repository
-func (de *DefaultEngine) getPrometheusRepo() healthcheck.PrometheusRepo {
+// GetPrometheusRepo returns the prometheus repository
+func (de *DefaultEngine) GetPrometheusRepo() healthcheck.PrometheusRepo {
 	return de.prometheusRepo
 }
 
-// getQueryRepo returns the query repository
-func (de *DefaultEngine) getQueryRepo() healthcheck.QueryRepo {
+// GetQueryRepo returns the query repository
+func (de *DefaultEngine) GetQueryRepo() healthcheck.QueryRepo {
 	return de.queryRepo
 }
 
@@ -122,7 +182,7 @@ func (de *DefaultEngine) getItemConfig(item string) healthcheck.ItemConfig {
 }
 
 // Run runs healthcheck
-func (de *DefaultEngine) Run() {
+func (de *DefaultEngine) Run(ctx context.Context) {
 	defer func() {
 		err := de.closeConnections()
 		if err != nil {
@@ -131,98 +191,74 @@ func (de *DefaultEngine) Run() {
 	}()
 
 	// run
-	err := de.run()
+	err := de.run(ctx)
 	if err != nil {
+		status := defaultFailedStatus
+		if ctx.Err() != nil {
+			// the operation was canceled, the error of the items does not matter
+			status = defaultCanceledStatus
+			err = message.NewMessage(msghc.ErrHealthcheckOperationCanceled, de.operationInfo.operationID)
+		}
 		log.Error(message.NewMessage(msghc.ErrHealthcheckDefaultEngineRun, err.Error()).Error())
 		// update status
-		updateErr := de.getDASRepo().UpdateOperationStatus(de.operationInfo.operationID, defaultFailedStatus, err.Error())
+		updateErr := de.GetDASRepo().UpdateOperationStatus(de.operationInfo.operationID, status, err.Error())
 		if updateErr != nil {
 			log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
 		}
+
+		return
 	}
 
 	// update operation status
 	msg := fmt.Sprintf("healthcheck completed successfully. engine: default, operation_id: %d", de.operationInfo.operationID)
-	updateErr := de.getDASRepo().UpdateOperationStatus(de.operationInfo.operationID, defaultSuccessStatus, msg)
+	unavailableItems := de.getUnavailableItemNames()
+	if len(unavailableItems) > constant.ZeroInt {
+		msg = fmt.Sprintf("healthcheck completed with unavailable items. engine: default, operation_id: %d, unavailable items: %s",
+			de.operationInfo.operationID, strings.Join(unavailableItems, constant.CommaString))
+	}
+	updateErr := de.GetDASRepo().UpdateOperationStatus(de.operationInfo.operationID, defaultSuccessStatus, msg)
 	if updateErr != nil {
 		log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
 	}
 }
 
-// run executes the healthcheck
-func (de *DefaultEngine) run() error {
-	// init MonitorRepo
-
+// run executes the healthcheck, it checks the context between the steps, so the canceled operation stops as soon as possible
+func (de *DefaultEngine) run(ctx context.Context) error {
 	// pre run
 	err := de.preRun()
 	if err != nil {
 		return err
 	}
-	// check db config
-	err = de.checkDBConfig()
-	if err != nil {
-		return err
-	}
-	// check cpu usage
-	err = de.checkCPUUsage()
-	if err != nil {
-		return err
-	}
-	// check io util
-	err = de.checkIOUtil()
-	if err != nil {
-		return err
-	}
-	// check disk capacity usage
-	err = de.checkDiskCapacityUsage()
-	if err != nil {
-		return err
-	}
-	// check connection usage
-	err = de.checkConnectionUsage()
-	if err != nil {
-		return err
-	}
-	// check active session number
-	err = de.checkAverageActiveSessionPercents()
-	if err != nil {
-		return err
-	}
-	// check cache miss ratio
-	err = de.checkCacheMissRatio()
-	if err != nil {
-		return err
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
-	// check table rows
-	err = de.checkTableRows()
-	if err != nil {
-		return err
-	}
-	// check table size
-	err = de.checkTableSize()
-	if err != nil {
-		return err
+	// check all the registered items concurrently
+	de.checkItems(ctx, de.getCheckItemRegistry().CreateItems(de))
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
-	// check slow query
-	err = de.checkSlowQuery()
+	// summarize
+	err = de.summarize()
 	if err != nil {
 		return err
 	}
-	// summarize
-	de.summarize()
 	// post run
 	return de.postRun()
 }
 
+// closeConnections closes the connections of the application mysql and the query repositories,
+// it waits for the abandoned items which may still be using the connections to return
 func (de *DefaultEngine) closeConnections() error {
+	de.itemWaitGroup.Wait()
+
 	merr := &multierror.Error{}
 
-	err := de.getApplicationMySQLRepo().Close()
+	err := de.GetApplicationMySQLRepo().Close()
 	if err != nil {
 		merr = multierror.Append(merr, err)
 	}
 
-	err = de.getQueryRepo().Close()
+	err = de.GetQueryRepo().Close()
 	if err != nil {
 		merr = multierror.Append(merr, err)
 	}
@@ -237,8 +273,13 @@ func (de *DefaultEngine) preRun() error {
 	if err != nil {
 		return err
 	}
+	// record the engine config version, so that the result could be traced back to the config it was scored with
+	err = de.GetDASRepo().UpdateOperationEngineConfigVersion(de.operationInfo.operationID, de.getEngineConfig().GetVersion())
+	if err != nil {
+		return err
+	}
 	// get file systems
-	fileSystems, err := de.getPrometheusRepo().GetFileSystems()
+	fileSystems, err := de.GetPrometheusRepo().GetFileSystems()
 	if err != nil {
 		return err
 	}
@@ -248,7 +289,7 @@ func (de *DefaultEngine) preRun() error {
 		mountPoints = append(mountPoints, fileSystem.GetMountPoint())
 	}
 	// get mysql directories
-	dirs, err := de.getApplicationMySQLRepo().GetMySQLDirs()
+	dirs, err := de.GetApplicationMySQLRepo().GetMySQLDirs()
 	if err != nil {
 		return err
 	}
@@ -268,9 +309,6 @@ func (de *DefaultEngine) preRun() error {
 			}
 		}
 	}
-	// init default report host and port
-	dbConfigVariableNames[dbConfigReportHost] = de.getOperationInfo().GetMySQLServer().GetHostIP()
-	dbConfigVariableNames[dbConfigReportPort] = strconv.Itoa(de.getOperationInfo().GetMySQLServer().GetPortNum())
 
 	return nil
 }
@@ -279,15 +317,16 @@ func (de *DefaultEngine) preRun() error {
 func (de *DefaultEngine) loadEngineConfig() error {
 	// load config
 	sql := `
-		select id, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
-		score_deduction_per_unit_medium, max_score_deduction_medium, del_flag, create_time, last_update_time
+		select id, config_version, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
+		score_deduction_per_unit_medium, max_score_deduction_medium, scoring_strategy, del_flag, create_time, last_update_time
 		from t_hc_default_engine_config
-		where del_flag = 0;
+		where del_flag = 0
+		and config_version = (select max(config_version) from t_hc_default_engine_config where del_flag = 0);
 	`
 	log.Debugf("healthcheck DASRepo.loadEngineConfig() sql: \n%s\n", sql)
-	result, err := de.getDASRepo().Execute(sql)
+	result, err := de.GetDASRepo().Execute(sql)
 	if err != nil {
-		return nil
+		return err
 	}
 	// init []*DefaultItemConfig
 	defaultEngineConfigList := make([]*DefaultItemConfig, result.RowNumber())
@@ -303,473 +342,210 @@ func (de *DefaultEngine) loadEngineConfig() error {
 	for _, defaultEngineConfig := range defaultEngineConfigList {
 		de.engineConfig[defaultEngineConfig.ItemName] = defaultEngineConfig
 	}
-	// validate config
-	return de.engineConfig.Validate()
-}
-
-// checkDBConfig checks database configuration
-func (de *DefaultEngine) checkDBConfig() error {
-	// load database config
-	var configItems []string
-	for item := range dbConfigVariableNames {
-		configItems = append(configItems, item)
-	}
-
-	globalVariables, err := de.getApplicationMySQLRepo().GetVariables(configItems)
+	// layer the overrides of the mysql server on the default engine config
+	err = de.applyEngineConfigOverrides()
 	if err != nil {
 		return err
 	}
-
-	dbConfigConfig := de.getItemConfig(defaultDBConfigItemName)
-
-	var (
-		dbConfigCount int
-		variables     []*Variable
-	)
-
-	for _, globalVariable := range globalVariables {
-		name := globalVariable.GetName()
-		value := globalVariable.GetValue()
-
-		switch name {
-		// max_user_connection
-		case dbConfigMaxUserConnection:
-			maxUserConnection, err := strconv.Atoi(value)
-			if err != nil {
-				return err
-			}
-			if maxUserConnection < dbConfigMaxUserConnectionValid {
-				dbConfigCount++
-				variables = append(variables, NewVariable(dbConfigMaxUserConnection, value, strconv.Itoa(dbConfigMaxUserConnectionValid)))
-			}
-			// others
-		case dbConfigLogBin, dbConfigBinlogFormat, dbConfigBinlogRowImage, dbConfigSyncBinlog,
-			dbConfigInnodbFlushLogAtTrxCommit, dbConfigGTIDMode, dbConfigEnforceGTIDConsistency,
-			dbConfigSlaveParallelType, dbConfigSlaveParallelWorkers, dbConfigMasterInfoRepository,
-			dbConfigRelayLogInfoRepository, dbConfigReportHost, dbConfigReportPort, dbConfigInnodbFlushMethod,
-			dbConfigInnodbMonitorEnable, dbConfigInnodbPrintAllDeadlocks, dbConfigSlowQueryLog, dbConfigPerformanceSchema:
-			if strings.ToUpper(value) != dbConfigVariableNames[name] {
-				dbConfigCount++
-				variables = append(variables, NewVariable(name, value, dbConfigVariableNames[name]))
-			}
-		}
-	}
-
-	// database config data
-	jsonBytesTotal, err := json.Marshal(globalVariables)
-	if err != nil {
-		return nil
-	}
-	de.result.DBConfigData = string(jsonBytesTotal)
-	// database config advice
-	jsonBytesVariables, err := json.Marshal(variables)
-	if err != nil {
-		return nil
-	}
-	de.result.DBConfigAdvice = string(jsonBytesVariables)
-	// database config score deduction
-	dbConfigScoreDeduction := float64(dbConfigCount) * dbConfigConfig.GetScoreDeductionPerUnitHigh()
-	if dbConfigScoreDeduction > dbConfigConfig.GetMaxScoreDeductionHigh() {
-		dbConfigScoreDeduction = dbConfigConfig.GetMaxScoreDeductionHigh()
-	}
-	de.result.DBConfigScore = int(defaultMaxScore - dbConfigScoreDeduction)
-	if de.result.DBConfigScore < constant.ZeroInt {
-		de.result.DBConfigScore = constant.ZeroInt
-	}
-
-	return nil
+	// validate config
+	return de.engineConfig.Validate()
 }
 
-// checkCPUUsage checks cpu usage
-func (de *DefaultEngine) checkCPUUsage() error {
-	// get data
-	datas, err := de.getPrometheusRepo().GetCPUUsage()
+// applyEngineConfigOverrides resolves the engine config of the mysql server
+// by layering the overrides of the env, the app level and the mysql cluster on the default engine config
+func (de *DefaultEngine) applyEngineConfigOverrides() error {
+	mysqlClusterID := de.GetOperationInfo().GetMySQLServer().GetClusterID()
+	// get env id
+	mcs := metadata.NewMySQLClusterServiceWithDefault()
+	err := mcs.GetByID(mysqlClusterID)
 	if err != nil {
 		return err
 	}
-	// parse data
-	de.result.CPUUsageScore, de.result.CPUUsageData, de.result.CPUUsageHigh, err = de.parsePrometheusDatas(defaultCPUUsageItemName, datas)
+	envID := mcs.GetMySQLClusters()[constant.ZeroInt].GetEnvID()
+	// get app level
+	appLevel, err := de.GetDASRepo().GetAppLevelByMySQLClusterID(mysqlClusterID)
 	if err != nil {
 		return err
 	}
-
-	return nil
-}
-
-// checkIOUtil check io util
-func (de *DefaultEngine) checkIOUtil() error {
-	// get data
-	datas, err := de.getPrometheusRepo().GetIOUtil()
+	// get overrides
+	overrides, err := de.GetDASRepo().GetEngineConfigOverrides(envID, appLevel, mysqlClusterID)
 	if err != nil {
 		return err
 	}
-	// parse data
-	de.result.IOUtilScore, de.result.IOUtilData, de.result.IOUtilHigh, err = de.parsePrometheusDatas(defaultIOUtilItemName, datas)
-	if err != nil {
-		return err
+	if len(overrides) == constant.ZeroInt {
+		return nil
 	}
 
-	return nil
-}
-
-// checkDiskCapacityUsage checks disk capacity usage
-func (de *DefaultEngine) checkDiskCapacityUsage() error {
-	// get data
-	datas, err := de.getPrometheusRepo().GetDiskCapacityUsage(de.getMountPoints())
-	if err != nil {
-		return err
-	}
-	// parse data
-	de.result.DiskCapacityUsageScore, de.result.DiskCapacityUsageData, de.result.DiskCapacityUsageHigh, err = de.parsePrometheusDatas(defaultDiskCapacityUsageItemName, datas)
-	if err != nil {
-		return err
-	}
+	de.engineConfig, err = applyEngineConfigOverrides(de.engineConfig, overrides)
 
-	return nil
+	return err
 }
 
-// checkConnectionUsage checks connection usage
-func (de *DefaultEngine) checkConnectionUsage() error {
-	// get data
-	datas, err := de.getPrometheusRepo().GetConnectionUsage()
-	if err != nil {
-		return err
-	}
-	// parse data
-	de.result.ConnectionUsageScore, de.result.ConnectionUsageData, de.result.ConnectionUsageHigh, err = de.parsePrometheusDatas(defaultConnectionUsageItemName, datas)
-	if err != nil {
-		return err
+// checkItems runs all the check items concurrently and waits for them to complete,
+// the item which failed or timed out will be recorded as unavailable
+func (de *DefaultEngine) checkItems(ctx context.Context, items []healthcheck.CheckItem) {
+	de.itemResults = make([]*ItemResult, len(items))
+	for _, item := range items {
+		de.setItemStatus(item.GetName(), defaultItemStatusPending)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(items))
+	for i, item := range items {
+		go func(i int, item healthcheck.CheckItem) {
+			defer wg.Done()
+			de.setItemStatus(item.GetName(), defaultItemStatusRunning)
+			de.itemResults[i] = de.checkWithTimeout(ctx, item)
+			switch {
+			case de.itemResults[i].IsAvailable():
+				de.setItemStatus(item.GetName(), defaultItemStatusCompleted)
+			case ctx.Err() != nil:
+				de.setItemStatus(item.GetName(), defaultItemStatusCanceled)
+			default:
+				de.setItemStatus(item.GetName(), defaultItemStatusUnavailable)
+			}
+		}(i, item)
 	}
+	wg.Wait()
 
-	return nil
-}
-
-// checkAverageActiveSessionPercents check active session number
-func (de *DefaultEngine) checkAverageActiveSessionPercents() error {
-	// get data
-	datas, err := de.getPrometheusRepo().GetAverageActiveSessionPercents()
-	if err != nil {
-		return err
-	}
-	// parse data
-	de.result.AverageActiveSessionPercentsScore, de.result.AverageActiveSessionPercentsData, de.result.AverageActiveSessionPercentsHigh, err = de.parsePrometheusDatas(defaultAverageActiveSessionPercentsItemName, datas)
-	if err != nil {
-		return err
+	for _, itemResult := range de.GetItemResults() {
+		de.getResult().setItemResult(itemResult)
 	}
-
-	return nil
 }
 
-// checkCacheMissRatio checks cache miss ratio
-func (de *DefaultEngine) checkCacheMissRatio() error {
-	// get data
-	datas, err := de.getPrometheusRepo().GetCacheMissRatio()
-	if err != nil {
-		return err
-	}
-	// parse data
-	de.result.CacheMissRatioScore, de.result.CacheMissRatioData, de.result.CacheMissRatioHigh, err = de.parsePrometheusDatas(defaultCacheMissRatioItemName, datas)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
+// checkWithTimeout checks the item under the item timeout, the item will also be abandoned if the operation is canceled,
+// note that the item which timed out will keep running in the background until it returns, but its result will be discarded,
+// the connections will not be closed until all the abandoned items returned
+func (de *DefaultEngine) checkWithTimeout(parent context.Context, item healthcheck.CheckItem) *ItemResult {
+	ctx, cancel := context.WithTimeout(parent, de.getItemTimeout())
+	defer cancel()
+
+	type checkResult struct {
+		itemResult *ItemResult
+		err        error
+	}
+	// the channel is buffered, so the goroutine of the timed out item will not be blocked
+	resultChan := make(chan checkResult, 1)
+	de.itemWaitGroup.Add(1)
+	go func() {
+		defer de.itemWaitGroup.Done()
+		itemResult, err := de.check(item)
+		resultChan <- checkResult{itemResult: itemResult, err: err}
+	}()
 
-// checkTableSize checks table rows
-func (de *DefaultEngine) checkTableRows() error {
-	// get tables
-	tables, err := de.getApplicationMySQLRepo().GetLargeTables()
-	if err != nil {
-		return err
+	var err error
+	select {
+	case cr := <-resultChan:
+		if cr.err == nil {
+			return cr.itemResult
+		}
+		err = cr.err
+	case <-ctx.Done():
+		err = message.NewMessage(msghc.ErrCheckItemTimeout, item.GetName(), int(de.getItemTimeout().Seconds()))
+		if parent.Err() != nil {
+			err = message.NewMessage(msghc.ErrCheckItemCanceled, item.GetName())
+		}
 	}
 
-	tableRowsConfig := de.getItemConfig(defaultTableRowsItemName)
-
-	var (
-		tableRowsHighSum     int
-		tableRowsHighCount   int
-		tableRowsMediumSum   int
-		tableRowsMediumCount int
+	log.Error(message.NewMessage(msghc.ErrCheckItemUnavailable, item.GetName(), de.GetOperationInfo().GetOperationID(), err.Error()).Error())
 
-		tableRowsHigh []healthcheck.Table
-	)
+	return NewUnavailableItemResult(item.GetName(), err)
+}
 
-	for _, table := range tables {
-		switch {
-		case float64(table.GetRows()) >= tableRowsConfig.GetHighWatermark():
-			tableRowsHigh = append(tableRowsHigh, table)
-			tableRowsHighSum += table.GetRows()
-			tableRowsHighCount++
-		case float64(table.GetRows()) >= tableRowsConfig.GetLowWatermark():
-			tableRowsMediumSum += table.GetRows()
-			tableRowsMediumCount++
-		}
+// check collects, scores and advises the check item, and then returns the item result
+func (de *DefaultEngine) check(item healthcheck.CheckItem) (*ItemResult, error) {
+	itemName := item.GetName()
+	// get item config
+	itemConfig := de.getEngineConfig().getItemConfig(itemName)
+	if itemConfig == nil {
+		return nil, message.NewMessage(msghc.ErrItemConfigNotFound, itemName)
 	}
-
-	// table rows data
-	jsonBytesTotal, err := json.Marshal(tables)
+	// collect
+	err := item.Collect()
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	de.result.TableRowsData = string(jsonBytesTotal)
-	// table rows high
-	jsonBytesHigh, err := json.Marshal(tableRowsHigh)
+	// score
+	score, err := item.Score(itemConfig)
 	if err != nil {
-		return nil
-	}
-	de.result.TableRowsHigh = string(jsonBytesHigh)
-
-	// table rows high score deduction
-	tableRowsScoreDeductionHigh := (float64(tableRowsHighSum)/float64(tableRowsHighCount) - tableRowsConfig.GetHighWatermark()) / tableRowsConfig.GetUnit() * tableRowsConfig.GetScoreDeductionPerUnitHigh()
-	if tableRowsScoreDeductionHigh > tableRowsConfig.GetMaxScoreDeductionHigh() {
-		tableRowsScoreDeductionHigh = tableRowsConfig.GetMaxScoreDeductionHigh()
-	}
-	// table rows medium score deduction
-	tableRowsScoreDeductionMedium := (float64(tableRowsMediumSum)/float64(tableRowsMediumCount) - tableRowsConfig.GetLowWatermark()) / tableRowsConfig.GetUnit() * tableRowsConfig.GetScoreDeductionPerUnitMedium()
-	if tableRowsScoreDeductionMedium > tableRowsConfig.GetMaxScoreDeductionMedium() {
-		tableRowsScoreDeductionMedium = tableRowsConfig.GetMaxScoreDeductionMedium()
+		return nil, err
 	}
-	// table rows score
-	de.result.TableRowsScore = int(defaultMaxScore - tableRowsScoreDeductionHigh - tableRowsScoreDeductionMedium)
-	if de.result.TableRowsScore < constant.ZeroInt {
-		de.result.TableRowsScore = constant.ZeroInt
-	}
-
-	return nil
-}
-
-// checkTableSize checks table sizes
-func (de *DefaultEngine) checkTableSize() error {
-	// get tables
-	tables, err := de.getApplicationMySQLRepo().GetLargeTables()
+	// advice
+	data, high, advice, err := item.Advice()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	tableSizeConfig := de.getItemConfig(defaultTableSizeItemName)
-
-	var (
-		tableSizeHighSum     float64
-		tableSizeHighCount   int
-		tableSizeMediumSum   float64
-		tableSizeMediumCount int
-
-		tableSizeHigh []healthcheck.Table
-	)
+	return NewItemResult(itemName, score, data, high, advice), nil
+}
 
-	for _, table := range tables {
-		switch {
-		case table.GetSize() >= tableSizeConfig.GetHighWatermark():
-			tableSizeHigh = append(tableSizeHigh, table)
-			tableSizeHighSum += table.GetSize()
-			tableSizeHighCount++
-		case table.GetSize() >= tableSizeConfig.GetLowWatermark():
-			tableSizeMediumSum += table.GetSize()
-			tableSizeMediumCount++
+// getUnavailableItemNames returns the names of the unavailable items
+func (de *DefaultEngine) getUnavailableItemNames() []string {
+	var names []string
+	for _, itemResult := range de.GetItemResults() {
+		if !itemResult.IsAvailable() {
+			names = append(names, itemResult.GetItemName())
 		}
 	}
 
-	// table size data
-	jsonBytesTotal, err := json.Marshal(tables)
-	if err != nil {
-		return nil
-	}
-	de.result.TableSizeData = string(jsonBytesTotal)
-	// table size high
-	jsonBytesHigh, err := json.Marshal(tableSizeHigh)
-	if err != nil {
-		return nil
-	}
-	de.result.TableSizeHigh = string(jsonBytesHigh)
-
-	// table size high score deduction
-	tableSizeScoreDeductionHigh := (tableSizeHighSum/float64(tableSizeHighCount) - tableSizeConfig.GetHighWatermark()) / tableSizeConfig.GetUnit() * tableSizeConfig.GetScoreDeductionPerUnitHigh()
-	if tableSizeScoreDeductionHigh > tableSizeConfig.GetMaxScoreDeductionHigh() {
-		tableSizeScoreDeductionHigh = tableSizeConfig.GetMaxScoreDeductionHigh()
-	}
-	// table size medium score deduction
-	tableSizeScoreDeductionMedium := (tableSizeMediumSum/float64(tableSizeMediumCount) - tableSizeConfig.GetLowWatermark()) / tableSizeConfig.GetUnit() * tableSizeConfig.GetScoreDeductionPerUnitMedium()
-	if tableSizeScoreDeductionMedium > tableSizeConfig.GetMaxScoreDeductionMedium() {
-		tableSizeScoreDeductionMedium = tableSizeConfig.GetMaxScoreDeductionMedium()
-	}
-	// table size score
-	de.result.TableSizeScore = int(defaultMaxScore - tableSizeScoreDeductionHigh - tableSizeScoreDeductionMedium)
-	if de.result.TableSizeScore < constant.ZeroInt {
-		de.result.TableSizeScore = constant.ZeroInt
-	}
-
-	return nil
+	return names
 }
 
-// checkSlowQuery checks slow query
-func (de *DefaultEngine) checkSlowQuery() error {
-	// check slow query execution time
-	slowQueries, err := de.getQueryRepo().GetSlowQuery()
-	if err != nil {
-		return err
-	}
-
+// summarize summarizes the scores of the available items with weight,
+// the weights are renormalised over the available items, so the unavailable items do not drag the score down
+func (de *DefaultEngine) summarize() error {
 	var (
-		slowQueryRowsExaminedHighSum     int
-		slowQueryRowsExaminedHighCount   int
-		slowQueryRowsExaminedMediumSum   int
-		slowQueryRowsExaminedMediumCount int
+		weightedScore int
+		totalWeight   int
 	)
-
-	// slow query data
-	jsonBytesRowsExamined, err := json.Marshal(slowQueries)
-	if err != nil {
-		return err
-	}
-	de.result.SlowQueryData = string(jsonBytesRowsExamined)
-
-	slowQueryRowsExaminedConfig := de.getItemConfig(defaultSlowQueryRowsExaminedItemName)
-	topSQLList := make([]depquery.Query, defaultSlowQueryTopSQLNum)
-
-	for i, slowQuery := range slowQueries {
-		if i < defaultSlowQueryTopSQLNum {
-			topSQLList[i] = slowQuery
-		}
-		if slowQuery.GetRowsExaminedMax() >= int(slowQueryRowsExaminedConfig.GetHighWatermark()) {
-			// slow query rows examined high
-			slowQueryRowsExaminedHighSum += slowQuery.GetRowsExaminedMax()
-			slowQueryRowsExaminedHighCount++
+	for _, itemResult := range de.GetItemResults() {
+		if !itemResult.IsAvailable() {
 			continue
 		}
-		if slowQuery.GetRowsExaminedMax() >= int(slowQueryRowsExaminedConfig.GetLowWatermark()) {
-			// slow query rows examined medium
-			slowQueryRowsExaminedMediumSum += slowQuery.GetRowsExaminedMax()
-			slowQueryRowsExaminedMediumCount++
-		}
+		itemWeight := de.getItemConfig(itemResult.GetItemName()).GetItemWeight()
+		weightedScore += itemResult.GetScore() * itemWeight
+		totalWeight += itemWeight
 	}
-	// slow query rows examined high score
-	slowQueryRowsExaminedHighScore := (float64(slowQueryRowsExaminedHighSum)/float64(slowQueryRowsExaminedHighCount) - slowQueryRowsExaminedConfig.GetHighWatermark()) / slowQueryRowsExaminedConfig.GetUnit() * slowQueryRowsExaminedConfig.GetScoreDeductionPerUnitHigh()
-	if slowQueryRowsExaminedHighScore > slowQueryRowsExaminedConfig.GetMaxScoreDeductionHigh() {
-		slowQueryRowsExaminedHighScore = slowQueryRowsExaminedConfig.GetMaxScoreDeductionHigh()
-	}
-	// slow query rows examined medium score
-	slowQueryRowsExaminedMediumScore := (float64(slowQueryRowsExaminedMediumSum)/float64(slowQueryRowsExaminedMediumCount) - slowQueryRowsExaminedConfig.GetLowWatermark()) / slowQueryRowsExaminedConfig.GetUnit() * slowQueryRowsExaminedConfig.GetScoreDeductionPerUnitMedium()
-	if slowQueryRowsExaminedMediumScore > slowQueryRowsExaminedConfig.GetMaxScoreDeductionMedium() {
-		slowQueryRowsExaminedMediumScore = slowQueryRowsExaminedConfig.GetMaxScoreDeductionMedium()
-	}
-	// slow query score
-	de.result.SlowQueryScore = int(defaultMaxScore - slowQueryRowsExaminedHighScore - slowQueryRowsExaminedMediumScore)
-	if de.result.SlowQueryScore < defaultMinScore {
-		de.result.SlowQueryScore = defaultMinScore
-	}
-
-	// sql tuning
-	clusterID := de.operationInfo.mysqlServer.GetClusterID()
-	// init db service
-	dbService := metadata.NewDBServiceWithDefault()
-	for _, sql := range topSQLList {
-		var advice string
-
-		// get db info
-		if sql.GetDBName() != constant.EmptyString {
-			err = dbService.GetByNameAndClusterInfo(sql.GetDBName(), clusterID, defaultClusterType)
-			if err != nil {
-				return err
-			}
-			// get db id
-			dbID := dbService.GetDBs()[constant.ZeroInt].Identity()
-			// init sql advisor service
-			advisorService := sqladvisor.NewServiceWithDefault()
-			// get advice
-			advice, err = advisorService.Advise(dbID, sql.GetExample())
-			if err != nil {
-				return err
-			}
-		} else {
-			jsonBytes, err := json.Marshal(sql)
-			if err != nil {
-				return err
-			}
-			advice = string(jsonBytes)
-		}
-
-		de.result.SlowQueryAdvice += advice + constant.CommaString
+	if totalWeight == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrAllCheckItemsUnavailable)
 	}
 
-	de.result.SlowQueryAdvice = strings.Trim(de.result.SlowQueryAdvice, constant.CommaString)
-
-	return nil
-}
-
-// summarize summarizes all item scores with weight
-func (de *DefaultEngine) summarize() {
-	de.result.WeightedAverageScore = (de.result.DBConfigScore*de.getItemConfig(defaultDBConfigItemName).GetItemWeight() +
-		de.result.CPUUsageScore*de.getItemConfig(defaultCPUUsageItemName).GetItemWeight() +
-		de.result.IOUtilScore*de.getItemConfig(defaultIOUtilItemName).GetItemWeight() +
-		de.result.DiskCapacityUsageScore*de.getItemConfig(defaultDiskCapacityUsageItemName).GetItemWeight() +
-		de.result.ConnectionUsageScore*de.getItemConfig(defaultConnectionUsageItemName).GetItemWeight() +
-		de.result.AverageActiveSessionPercentsScore*de.getItemConfig(defaultAverageActiveSessionPercentsItemName).GetItemWeight() +
-		de.result.CacheMissRatioScore*de.getItemConfig(defaultCacheMissRatioItemName).GetItemWeight() +
-		de.result.TableRowsScore*de.getItemConfig(defaultTableRowsItemName).GetItemWeight() +
-		de.result.TableSizeScore*de.getItemConfig(defaultTableSizeItemName).GetItemWeight() +
-		de.result.SlowQueryScore*(de.getItemConfig(defaultSlowQueryRowsExaminedItemName).GetItemWeight())) /
-		constant.MaxPercentage
-
+	de.result.WeightedAverageScore = weightedScore / totalWeight
 	if de.result.WeightedAverageScore < defaultMinScore {
 		de.result.WeightedAverageScore = defaultMinScore
 	}
+
+	return nil
 }
 
 // postRun performs post-run actions, for now, it ony saves healthcheck result to the middleware
 func (de *DefaultEngine) postRun() error {
+	de.result.OperationID = de.GetOperationInfo().GetOperationID()
+	// keep the resolved engine config with the result, so that the result could be explained even if the overrides are changed
+	engineConfig, err := json.Marshal(de.getEngineConfig())
+	if err != nil {
+		return err
+	}
+	de.result.EngineConfig = string(engineConfig)
 	// save result
-	return de.getDASRepo().SaveResult(de.result)
+	return de.GetDASRepo().SaveResult(de.result)
 }
 
-func (de *DefaultEngine) parsePrometheusDatas(item string, datas []healthcheck.PrometheusData) (int, string, string, error) {
-	config := de.getItemConfig(item)
-
-	var (
-		highSum     float64
-		highCount   int
-		mediumSum   float64
-		mediumCount int
-
-		highDatas []healthcheck.PrometheusData
-	)
-	// parse monitor data
-	for _, data := range datas {
-		switch {
-		case data.GetValue() >= config.GetHighWatermark():
-			highDatas = append(highDatas, data)
-			highSum += data.GetValue()
-			highCount++
-		case data.GetValue() >= config.GetLowWatermark():
-			mediumSum += data.GetValue()
-			mediumCount++
-		}
-	}
-
-	// high score deduction
-	scoreDeductionHigh := (highSum/float64(highCount) - config.GetHighWatermark()) / config.GetUnit() * config.GetScoreDeductionPerUnitHigh()
-	if scoreDeductionHigh > config.GetMaxScoreDeductionHigh() {
-		scoreDeductionHigh = config.GetMaxScoreDeductionHigh()
-	}
-	// medium score deduction
-	scoreDeductionMedium := (mediumSum/float64(mediumCount) - config.GetLowWatermark()) / config.GetUnit() * config.GetScoreDeductionPerUnitMedium()
-	if scoreDeductionMedium > config.GetMaxScoreDeductionMedium() {
-		scoreDeductionMedium = config.GetMaxScoreDeductionMedium()
-	}
-	// calculate score
-	score := int(defaultMaxScore - scoreDeductionHigh - scoreDeductionMedium)
-	if score < constant.ZeroInt {
-		score = constant.ZeroInt
-	}
+// setPrimaryGTIDExecuted sets the function which returns the executed gtid set of the primary of the replica
+func (de *DefaultEngine) setPrimaryGTIDExecuted(getPrimaryGTIDExecuted func(hostIP string, portNum int) (string, error)) {
+	de.getPrimaryGTIDExecuted = getPrimaryGTIDExecuted
+}
 
-	jsonBytesTotal, err := json.Marshal(datas)
-	if err != nil {
-		return constant.ZeroInt, constant.EmptyString, constant.EmptyString, err
-	}
-	jsonBytesHigh, err := json.Marshal(highDatas)
+// getPrimaryGTIDExecutedFromPrimary connects to the primary and returns its executed gtid set
+func (de *DefaultEngine) getPrimaryGTIDExecutedFromPrimary(hostIP string, portNum int) (string, error) {
+	addr := fmt.Sprintf("%s:%d", hostIP, portNum)
+	conn, err := mysql.NewConn(addr, constant.EmptyString,
+		viper.GetString(config.DBApplicationMySQLUserKey), viper.GetString(config.DBApplicationMySQLPassKey))
 	if err != nil {
-		return constant.ZeroInt, constant.EmptyString, constant.EmptyString, err
+		return constant.EmptyString, err
 	}
+	primaryRepo := NewApplicationMySQLRepo(de.GetOperationInfo(), conn)
+	defer func() { _ = primaryRepo.Close() }()
 
-	return score, string(jsonBytesTotal), string(jsonBytesHigh), nil
+	return primaryRepo.GetGTIDExecuted()
 }
@@ -1,7 +1,9 @@
 package healthcheck
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,38 +12,74 @@ import (
 	"github.com/romberli/das/internal/dependency/healthcheck"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
-	"github.com/romberli/go-util/middleware/clickhouse"
-	"github.com/romberli/go-util/middleware/mysql"
-	"github.com/romberli/go-util/middleware/prometheus"
 	"github.com/stretchr/testify/assert"
 )
 
 const (
-	defaultEngineConfigDBUser = "root"
-	defaultEngineConfigDBPass = "root"
-
-	applicationMysqlAddr   = "192.168.10.210:3306"
-	applicationMysqlDBName = "performance_schema"
-	applicationMysqlDBUser = "root"
-	applicationMysqlDBPass = "mysql123"
-
 	defaultMySQLServerID = 1
 	defaultStep          = time.Minute
+
+	testSlowItemName   = "test_slow_item"
+	testFailedItemName = "test_failed_item"
+
+	// the golden file which is captured by the recorder
+	testDataDir      = "testdata"
+	testSnapshotFile = "snapshot.json"
 )
 
+type testCheckItem struct {
+	name  string
+	score int
+	delay time.Duration
+	err   error
+}
+
+func (tci *testCheckItem) GetName() string {
+	return tci.name
+}
+
+func (tci *testCheckItem) Collect() error {
+	time.Sleep(tci.delay)
+
+	return tci.err
+}
+
+func (tci *testCheckItem) Score(config healthcheck.ItemConfig) (int, error) {
+	return tci.score, nil
+}
+
+func (tci *testCheckItem) Advice() (string, string, string, error) {
+	return constant.EmptyString, constant.EmptyString, constant.EmptyString, nil
+}
+
+func newTestDefaultEngine(itemNames ...string) *DefaultEngine {
+	de := NewDefaultEngine(NewOperationInfo(constant.ZeroInt, nil, nil, time.Now(), time.Now(), defaultStep), nil, nil, nil, nil)
+	de.itemTimeout = 100 * time.Millisecond
+	for _, itemName := range itemNames {
+		de.engineConfig[itemName] = &DefaultItemConfig{ItemName: itemName, ItemWeight: 50}
+	}
+
+	return de
+}
+
 func TestDefaultEngineAll(t *testing.T) {
 	TestDefaultEngineConfig_Validate(t)
 	TestDefaultEngine_Run(t)
+	TestDefaultEngine_checkItemsWithGoldenFile(t)
+	TestDefaultEngine_checkItems(t)
+	TestDefaultEngine_summarize(t)
 }
 
 func TestDefaultEngineConfig_Validate(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 	// load config
 	sql := `
-		select id, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
-		score_deduction_per_unit_medium, max_score_deduction_medium, del_flag, create_time, last_update_time
+		select id, config_version, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
+		score_deduction_per_unit_medium, max_score_deduction_medium, scoring_strategy, del_flag, create_time, last_update_time
 		from t_hc_default_engine_config
-		where del_flag = 0;
+		where del_flag = 0
+		and config_version = (select max(config_version) from t_hc_default_engine_config where del_flag = 0);
 	`
 	result, err := testDASRepo.Execute(sql)
 	asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
@@ -62,67 +100,109 @@ func TestDefaultEngineConfig_Validate(t *testing.T) {
 }
 
 func TestDefaultEngine_Run(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
-	startTime := time.Now().Add(-constant.Week)
-	endTime := time.Now()
 
-	id, err := testDASRepo.InitOperation(defaultMySQLServerID, startTime, endTime, defaultStep)
+	// the application mysql, prometheus and query repositories replay the golden file
+	snapshot, err := NewSnapshotWithFile(filepath.Join(testDataDir, testSnapshotFile))
+	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
+
+	id, err := testDASRepo.InitOperation(defaultMySQLServerID, snapshot.GetStartTime(), snapshot.GetEndTime(), snapshot.GetStep())
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 
 	mysqlServerService := metadata.NewMySQLServerService(metadata.NewMySQLServerRepo(global.DASMySQLPool))
-	err = mysqlServerService.GetByID(1)
+	err = mysqlServerService.GetByID(defaultMySQLServerID)
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
-	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 	monitorSystem, err := mysqlServer.GetMonitorSystem()
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
 
-	operationInfo := NewOperationInfo(id, mysqlServer, monitorSystem, startTime, endTime, defaultStep)
-
-	// init application mysql connection
-	applicationMySQLConn, err := mysql.NewConn(fmt.Sprintf("%s:%d", mysqlServer.GetHostIP(), mysqlServer.GetPortNum()), applicationMysqlDBName, applicationMysqlDBUser, applicationMysqlDBPass)
+	operationInfo := NewOperationInfo(id, mysqlServer, monitorSystem, snapshot.GetStartTime(), snapshot.GetEndTime(), snapshot.GetStep())
+	defaultEngine := NewSnapshotEngine(operationInfo, testDASRepo, snapshot)
+	err = defaultEngine.run(context.Background())
+	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
+	// delete, so that the recorded golden files do not depend on the order of the tests
+	err = deleteHCResultByOperationID(id)
+	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
+	err = deleteOperationInfoByID(id)
 	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
-	// init application mysql repository
-	applicationMySQLRepo := NewApplicationMySQLRepo(operationInfo, applicationMySQLConn)
-
-	var (
-		prometheusConfig prometheus.Config
-		queryRepo        healthcheck.QueryRepo
-	)
-
-	prometheusAddr := fmt.Sprintf("%s:%d%s", monitorSystem.GetHostIP(), monitorSystem.GetPortNum(), monitorSystem.GetBaseURL())
-	queryAddr := fmt.Sprintf("%s:%d", monitorSystem.GetHostIP(), monitorSystem.GetPortNumSlow())
-
-	switch monitorSystem.GetSystemType() {
-	case 1:
-		// pmm 1.x
-		// init prometheus config
-		prometheusConfig = prometheus.NewConfig(prometheusAddr, prometheus.DefaultRoundTripper)
-		asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
-		// init mysql connection
-		mysqlConn, err := mysql.NewConn(queryAddr, defaultMonitorMySQLDBName, defaultEngineConfigDBUser, defaultEngineConfigDBPass)
-		asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
-		// init mysql query repository
-		queryRepo = NewMySQLQueryRepo(operationInfo, mysqlConn)
-	case 2:
-		// pmm 2.x
-		// init prometheus config
-		prometheusConfig = prometheus.NewConfigWithBasicAuth(prometheusAddr, defaultPrometheusUser, defaultPrometheusPass)
-		asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
-		// init clickhouse connection
-		clickhouseConn, err := clickhouse.NewConnWithDefault(queryAddr, defaultMonitorClickhouseDBName, constant.EmptyString, constant.EmptyString)
-		asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
-		// init clickhouse query repository
-		queryRepo = NewClickhouseQueryRepo(operationInfo, clickhouseConn)
+}
+
+func TestDefaultEngine_checkItemsWithGoldenFile(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot, err := NewSnapshotWithFile(filepath.Join(testDataDir, testSnapshotFile))
+	asst.Nil(err, common.CombineMessageWithError("test checkItems() with golden file failed", err))
+	de := newTestSnapshotEngine(snapshot)
+	de.engineConfig[defaultIOUtilItemName] = NewDefaultItemConfig(defaultIOUtilItemName, 10, 50, 70, 10, 20, 100, 10, 50)
+	de.engineConfig[defaultDiskCapacityUsageItemName] = NewDefaultItemConfig(defaultDiskCapacityUsageItemName, 10, 70, 80, 5, 20, 100, 10, 50)
+	de.engineConfig[defaultConnectionUsageItemName] = NewDefaultItemConfig(defaultConnectionUsageItemName, 10, 50, 70, 10, 20, 100, 10, 50)
+	de.engineConfig[defaultAverageActiveSessionPercentsItemName] = NewDefaultItemConfig(defaultAverageActiveSessionPercentsItemName, 10, 50, 70, 10, 20, 100, 10, 50)
+	de.engineConfig[defaultCacheMissRatioItemName] = NewDefaultItemConfig(defaultCacheMissRatioItemName, 10, 0.1, 0.2, 0.05, 20, 100, 10, 50)
+	de.engineConfig[defaultReplicationItemName] = newTestReplicationConfig()
+	de.engineConfig[defaultInnoDBLockItemName] = newTestInnoDBLockConfig()
+	de.mountPoints = []string{constant.RootDir, "/mysqldata"}
+
+	// the db config and the slow query items look up the das metadata, they are checked by TestDefaultEngine_Run()
+	var items []healthcheck.CheckItem
+	for _, item := range GetCheckItemRegistry().CreateItems(de) {
+		if item.GetName() == defaultDBConfigItemName || item.GetName() == defaultSlowQueryRowsExaminedItemName {
+			continue
+		}
+		items = append(items, item)
+	}
+	de.checkItems(context.Background(), items)
+	itemResults := de.GetItemResults()
+	asst.Equal(len(items), len(itemResults), "test checkItems() with golden file failed")
+	for _, itemResult := range itemResults {
+		asst.True(itemResult.IsAvailable(), "test checkItems() with golden file failed, item: %s, error: %s", itemResult.GetItemName(), itemResult.GetError())
 	}
+	err = de.summarize()
+	asst.Nil(err, common.CombineMessageWithError("test checkItems() with golden file failed", err))
+	asst.True(de.getResult().WeightedAverageScore < defaultHundred, "test checkItems() with golden file failed")
+}
 
-	// init prometheus connection
-	prometheusConn, err := prometheus.NewConnWithConfig(prometheusConfig)
-	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
-	// init prometheus repository
-	prometheusRepo := NewPrometheusRepo(operationInfo, prometheusConn)
+func TestDefaultEngine_checkItems(t *testing.T) {
+	asst := assert.New(t)
 
-	defaultEngine := NewDefaultEngine(operationInfo, testDASRepo, applicationMySQLRepo, prometheusRepo, queryRepo)
-	err = defaultEngine.run()
-	asst.Nil(err, common.CombineMessageWithError("test Run() failed", err))
+	de := newTestDefaultEngine(testCheckItemName, testSlowItemName, testFailedItemName)
+	de.checkItems(context.Background(), []healthcheck.CheckItem{
+		&testCheckItem{name: testCheckItemName, score: 80},
+		&testCheckItem{name: testSlowItemName, score: 80, delay: time.Second},
+		&testCheckItem{name: testFailedItemName, score: 80, err: errors.New("test error")},
+	})
+	itemResults := de.GetItemResults()
+	asst.Equal(3, len(itemResults), "test checkItems() failed")
+	asst.True(itemResults[0].IsAvailable(), "test checkItems() failed")
+	asst.Equal(80, itemResults[0].GetScore(), "test checkItems() failed")
+	asst.False(itemResults[1].IsAvailable(), "test checkItems() failed")
+	asst.False(itemResults[2].IsAvailable(), "test checkItems() failed")
+	asst.Equal("test error", itemResults[2].GetError(), "test checkItems() failed")
+	asst.Equal([]string{testSlowItemName, testFailedItemName}, de.getUnavailableItemNames(), "test checkItems() failed")
+	// the unavailable items are kept in the result with their errors
+	items := de.getResult().GetItems()
+	asst.Equal(3, len(items), "test checkItems() failed")
+	asst.Equal(defaultItemStatusUnavailable, items[2].GetStatus(), "test checkItems() failed")
+	asst.Equal("test error", items[2].GetError(), "test checkItems() failed")
+	// the abandoned item is still running, the connections must not be closed until it returns
+	start := time.Now()
+	de.itemWaitGroup.Wait()
+	asst.True(time.Since(start) > 500*time.Millisecond, "test checkItems() failed")
+}
+
+func TestDefaultEngine_summarize(t *testing.T) {
+	asst := assert.New(t)
+
+	de := newTestDefaultEngine(testCheckItemName, testFailedItemName)
+	de.itemResults = []*ItemResult{
+		NewItemResult(testCheckItemName, 80, constant.EmptyString, constant.EmptyString, constant.EmptyString),
+		NewUnavailableItemResult(testFailedItemName, errors.New("test error")),
+	}
+	err := de.summarize()
+	asst.Nil(err, common.CombineMessageWithError("test summarize() failed", err))
+	asst.Equal(80, de.getResult().WeightedAverageScore, "test summarize() failed")
+
+	de.itemResults = []*ItemResult{NewUnavailableItemResult(testFailedItemName, errors.New("test error"))}
+	err = de.summarize()
+	asst.NotNil(err, "test summarize() failed")
 }
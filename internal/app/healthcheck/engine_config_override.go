@@ -0,0 +1,185 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/log"
+)
+
+const (
+	// EngineConfigOverrideScopeEnv overrides the engine config of the mysql servers in the env
+	EngineConfigOverrideScopeEnv = 1
+	// EngineConfigOverrideScopeAppLevel overrides the engine config of the mysql servers which are used by the apps of the level,
+	// if the mysql cluster is used by multiple apps, the highest level(1-A) is used
+	EngineConfigOverrideScopeAppLevel = 2
+	// EngineConfigOverrideScopeMySQLCluster overrides the engine config of the mysql servers in the mysql cluster
+	EngineConfigOverrideScopeMySQLCluster = 3
+)
+
+var _ healthcheck.EngineConfigOverride = (*EngineConfigOverride)(nil)
+
+// EngineConfigOverride overrides the item config of the default engine config in the scope,
+// the overrides are layered in the order of env, app level and mysql cluster, so the narrower scope wins
+type EngineConfigOverride struct {
+	ID             int       `middleware:"id" json:"id"`
+	ScopeType      int       `middleware:"scope_type" json:"scope_type"`
+	ScopeID        int       `middleware:"scope_id" json:"scope_id"`
+	ItemName       string    `middleware:"item_name" json:"item_name"`
+	Config         string    `middleware:"config" json:"config"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewEngineConfigOverride returns a new *EngineConfigOverride
+func NewEngineConfigOverride(scopeType, scopeID int, itemName, config string) *EngineConfigOverride {
+	return &EngineConfigOverride{
+		ScopeType: scopeType,
+		ScopeID:   scopeID,
+		ItemName:  itemName,
+		Config:    config,
+	}
+}
+
+// NewEmptyEngineConfigOverride returns a new empty *EngineConfigOverride
+func NewEmptyEngineConfigOverride() *EngineConfigOverride {
+	return &EngineConfigOverride{}
+}
+
+// Identity returns the identity
+func (eco *EngineConfigOverride) Identity() int {
+	return eco.ID
+}
+
+// GetScopeType returns the scope type
+func (eco *EngineConfigOverride) GetScopeType() int {
+	return eco.ScopeType
+}
+
+// GetScopeID returns the scope id, it is the env id, the app level or the mysql cluster id depending on the scope type
+func (eco *EngineConfigOverride) GetScopeID() int {
+	return eco.ScopeID
+}
+
+// GetItemName returns the item name
+func (eco *EngineConfigOverride) GetItemName() string {
+	return eco.ItemName
+}
+
+// GetConfig returns the overridden item config as a json string
+func (eco *EngineConfigOverride) GetConfig() string {
+	return eco.Config
+}
+
+// GetDelFlag returns the delete flag
+func (eco *EngineConfigOverride) GetDelFlag() int {
+	return eco.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (eco *EngineConfigOverride) GetCreateTime() time.Time {
+	return eco.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (eco *EngineConfigOverride) GetLastUpdateTime() time.Time {
+	return eco.LastUpdateTime
+}
+
+// isValidEngineConfigOverrideScopeType returns if the scope type is supported
+func isValidEngineConfigOverrideScopeType(scopeType int) bool {
+	return scopeType == EngineConfigOverrideScopeEnv ||
+		scopeType == EngineConfigOverrideScopeAppLevel ||
+		scopeType == EngineConfigOverrideScopeMySQLCluster
+}
+
+// itemConfigOverride is the fields of the item config which could be overridden, the nil fields are not overridden,
+// the item weight could not be overridden, so the weighted average scores of the mysql servers are always comparable
+type itemConfigOverride struct {
+	LowWatermark                *float64 `json:"low_watermark"`
+	HighWatermark               *float64 `json:"high_watermark"`
+	Unit                        *float64 `json:"unit"`
+	ScoreDeductionPerUnitHigh   *float64 `json:"score_deduction_per_unit_high"`
+	MaxScoreDeductionHigh       *float64 `json:"max_score_deduction_high"`
+	ScoreDeductionPerUnitMedium *float64 `json:"score_deduction_per_unit_medium"`
+	MaxScoreDeductionMedium     *float64 `json:"max_score_deduction_medium"`
+	ScoringStrategy             *string  `json:"scoring_strategy"`
+}
+
+// parseItemConfigOverride parses the config of the override, the unknown fields are not allowed
+func parseItemConfigOverride(config string) (*itemConfigOverride, error) {
+	ico := &itemConfigOverride{}
+	decoder := json.NewDecoder(bytes.NewBufferString(config))
+	decoder.DisallowUnknownFields()
+	err := decoder.Decode(ico)
+	if err != nil {
+		return nil, message.NewMessage(msghc.ErrEngineConfigOverrideConfigInvalid, config, err.Error())
+	}
+
+	return ico, nil
+}
+
+// apply overrides the fields of the item config
+func (ico *itemConfigOverride) apply(dic *DefaultItemConfig) {
+	if ico.LowWatermark != nil {
+		dic.LowWatermark = *ico.LowWatermark
+	}
+	if ico.HighWatermark != nil {
+		dic.HighWatermark = *ico.HighWatermark
+	}
+	if ico.Unit != nil {
+		dic.Unit = *ico.Unit
+	}
+	if ico.ScoreDeductionPerUnitHigh != nil {
+		dic.ScoreDeductionPerUnitHigh = *ico.ScoreDeductionPerUnitHigh
+	}
+	if ico.MaxScoreDeductionHigh != nil {
+		dic.MaxScoreDeductionHigh = *ico.MaxScoreDeductionHigh
+	}
+	if ico.ScoreDeductionPerUnitMedium != nil {
+		dic.ScoreDeductionPerUnitMedium = *ico.ScoreDeductionPerUnitMedium
+	}
+	if ico.MaxScoreDeductionMedium != nil {
+		dic.MaxScoreDeductionMedium = *ico.MaxScoreDeductionMedium
+	}
+	if ico.ScoringStrategy != nil {
+		dic.ScoringStrategy = *ico.ScoringStrategy
+	}
+}
+
+// applyEngineConfigOverrides returns a new engine config which layers the overrides on the engine config,
+// the overrides are applied in the order of the scope type, so the narrower scope wins,
+// the override of the item which does not exist in the engine config is ignored
+func applyEngineConfigOverrides(engineConfig DefaultEngineConfig, overrides []healthcheck.EngineConfigOverride) (DefaultEngineConfig, error) {
+	resolved := NewEmptyDefaultEngineConfig()
+	for itemName, defaultItemConfig := range engineConfig {
+		itemConfig := *defaultItemConfig
+		resolved[itemName] = &itemConfig
+	}
+
+	sorted := make([]healthcheck.EngineConfigOverride, len(overrides))
+	copy(sorted, overrides)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetScopeType() < sorted[j].GetScopeType()
+	})
+	for _, override := range sorted {
+		itemConfig := resolved.getItemConfig(override.GetItemName())
+		if itemConfig == nil {
+			log.Warn(message.NewMessage(msghc.ErrEngineConfigOverrideItemIgnored, override.Identity(), override.GetItemName()).Error())
+			continue
+		}
+		ico, err := parseItemConfigOverride(override.GetConfig())
+		if err != nil {
+			return nil, err
+		}
+		ico.apply(itemConfig)
+	}
+
+	return resolved, nil
+}
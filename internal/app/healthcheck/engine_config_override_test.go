@@ -0,0 +1,54 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngineConfigOverrideAll(t *testing.T) {
+	TestEngineConfigOverride_parseItemConfigOverride(t)
+	TestEngineConfigOverride_applyEngineConfigOverrides(t)
+}
+
+func TestEngineConfigOverride_parseItemConfigOverride(t *testing.T) {
+	asst := assert.New(t)
+
+	ico, err := parseItemConfigOverride(`{"high_watermark": 90, "scoring_strategy": "p95"}`)
+	asst.Nil(err, common.CombineMessageWithError("test parseItemConfigOverride() failed", err))
+	asst.Nil(ico.LowWatermark, "test parseItemConfigOverride() failed")
+	asst.Equal(90.0, *ico.HighWatermark, "test parseItemConfigOverride() failed")
+	asst.Equal(ScoringStrategyP95, *ico.ScoringStrategy, "test parseItemConfigOverride() failed")
+	// item weight could not be overridden
+	_, err = parseItemConfigOverride(`{"item_weight": 10}`)
+	asst.NotNil(err, "test parseItemConfigOverride() failed")
+	// not a json object
+	_, err = parseItemConfigOverride(`[60]`)
+	asst.NotNil(err, "test parseItemConfigOverride() failed")
+}
+
+func TestEngineConfigOverride_applyEngineConfigOverrides(t *testing.T) {
+	asst := assert.New(t)
+
+	engineConfig := NewEmptyDefaultEngineConfig()
+	engineConfig[defaultCPUUsageItemName] = NewDefaultItemConfig(defaultCPUUsageItemName, 100, 50, 70, 10, 20, 100, 10, 50)
+	// the narrower scope wins no matter the order of the overrides
+	overrides := []healthcheck.EngineConfigOverride{
+		&EngineConfigOverride{ID: 1, ScopeType: EngineConfigOverrideScopeMySQLCluster, ScopeID: 1, ItemName: defaultCPUUsageItemName, Config: `{"low_watermark": 60}`},
+		&EngineConfigOverride{ID: 2, ScopeType: EngineConfigOverrideScopeAppLevel, ScopeID: 1, ItemName: defaultCPUUsageItemName, Config: `{"low_watermark": 40, "high_watermark": 80}`},
+		&EngineConfigOverride{ID: 3, ScopeType: EngineConfigOverrideScopeEnv, ScopeID: 1, ItemName: defaultCPUUsageItemName, Config: `{"low_watermark": 30, "unit": 5}`},
+		&EngineConfigOverride{ID: 4, ScopeType: EngineConfigOverrideScopeEnv, ScopeID: 1, ItemName: "unknown_item", Config: `{"low_watermark": 30}`},
+	}
+	resolved, err := applyEngineConfigOverrides(engineConfig, overrides)
+	asst.Nil(err, common.CombineMessageWithError("test applyEngineConfigOverrides() failed", err))
+	itemConfig := resolved.getItemConfig(defaultCPUUsageItemName)
+	asst.Equal(60.0, itemConfig.GetLowWatermark(), "test applyEngineConfigOverrides() failed")
+	asst.Equal(80.0, itemConfig.GetHighWatermark(), "test applyEngineConfigOverrides() failed")
+	asst.Equal(5.0, itemConfig.GetUnit(), "test applyEngineConfigOverrides() failed")
+	asst.Equal(100, itemConfig.GetItemWeight(), "test applyEngineConfigOverrides() failed")
+	asst.Nil(resolved.getItemConfig("unknown_item"), "test applyEngineConfigOverrides() failed")
+	// the engine config is not changed
+	asst.Equal(50.0, engineConfig.getItemConfig(defaultCPUUsageItemName).GetLowWatermark(), "test applyEngineConfigOverrides() failed")
+}
@@ -0,0 +1,240 @@
+package healthcheck
+
+import (
+	"fmt"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+)
+
+var _ healthcheck.EngineConfigRepo = (*EngineConfigRepo)(nil)
+
+// EngineConfigRepo is the repository of the versioned default engine configs,
+// the item configs of a version are never changed once they are created, any change creates a new version
+type EngineConfigRepo struct {
+	Database middleware.Pool
+}
+
+// NewEngineConfigRepo returns *EngineConfigRepo with given middleware.Pool
+func NewEngineConfigRepo(db middleware.Pool) *EngineConfigRepo {
+	return &EngineConfigRepo{Database: db}
+}
+
+// NewEngineConfigRepoWithGlobal returns *EngineConfigRepo with global mysql pool
+func NewEngineConfigRepoWithGlobal() *EngineConfigRepo {
+	return NewEngineConfigRepo(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (ecr *EngineConfigRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	conn, err := ecr.Database.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("healthcheck EngineConfigRepo.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	return conn.Execute(command, args...)
+}
+
+// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+func (ecr *EngineConfigRepo) Transaction() (middleware.Transaction, error) {
+	return ecr.Database.Transaction()
+}
+
+// GetLatestVersion gets the latest engine config version from the middleware, it returns 0 if there is no config
+func (ecr *EngineConfigRepo) GetLatestVersion() (int, error) {
+	sql := `select ifnull(max(config_version), 0) from t_hc_default_engine_config where del_flag = 0;`
+	log.Debugf("healthCheck EngineConfigRepo.GetLatestVersion() select sql: \n%s", sql)
+
+	result, err := ecr.Execute(sql)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return result.GetInt(constant.ZeroInt, constant.ZeroInt)
+}
+
+// GetByVersion gets the item configs of given engine config version from the middleware
+func (ecr *EngineConfigRepo) GetByVersion(version int) ([]healthcheck.ItemConfig, error) {
+	sql := `
+		select id, config_version, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
+		score_deduction_per_unit_medium, max_score_deduction_medium, scoring_strategy, del_flag, create_time, last_update_time
+		from t_hc_default_engine_config
+		where del_flag = 0
+		and config_version = ?
+		order by id;
+	`
+	log.Debugf("healthCheck EngineConfigRepo.GetByVersion() select sql: \n%s\nplaceholders: %s", sql, version)
+
+	result, err := ecr.Execute(sql, version)
+	if err != nil {
+		return nil, err
+	}
+	// init []*DefaultItemConfig
+	defaultItemConfigs := make([]*DefaultItemConfig, result.RowNumber())
+	for i := range defaultItemConfigs {
+		defaultItemConfigs[i] = NewEmptyDefaultItemConfig()
+	}
+	// map to struct
+	err = result.MapToStructSlice(defaultItemConfigs, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.ItemConfig
+	itemConfigs := make([]healthcheck.ItemConfig, result.RowNumber())
+	for i := range itemConfigs {
+		itemConfigs[i] = defaultItemConfigs[i]
+	}
+
+	return itemConfigs, nil
+}
+
+// Create creates a new engine config version with given item configs in the middleware, it returns the new version,
+// the latest version is locked in the transaction, so the concurrent changes will not get the same version
+func (ecr *EngineConfigRepo) Create(itemConfigs []healthcheck.ItemConfig) (int, error) {
+	tx, err := ecr.Transaction()
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	defer func() {
+		err = tx.Close()
+		if err != nil {
+			log.Errorf("healthcheck EngineConfigRepo.Create(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	err = tx.Begin()
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	sql := `select ifnull(max(config_version), 0) from t_hc_default_engine_config for update;`
+	log.Debugf("healthCheck EngineConfigRepo.Create() select sql: \n%s", sql)
+	result, err := tx.Execute(sql)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	latestVersion, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	version := latestVersion + 1
+
+	sql = `
+		insert into t_hc_default_engine_config(config_version, item_name, item_weight, low_watermark, high_watermark, unit,
+		score_deduction_per_unit_high, max_score_deduction_high, score_deduction_per_unit_medium, max_score_deduction_medium, scoring_strategy)
+		values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	for _, itemConfig := range itemConfigs {
+		log.Debugf("healthCheck EngineConfigRepo.Create() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s",
+			sql, version, itemConfig.GetItemName(), itemConfig.GetItemWeight(), itemConfig.GetLowWatermark(), itemConfig.GetHighWatermark(),
+			itemConfig.GetUnit(), itemConfig.GetScoreDeductionPerUnitHigh(), itemConfig.GetMaxScoreDeductionHigh(),
+			itemConfig.GetScoreDeductionPerUnitMedium(), itemConfig.GetMaxScoreDeductionMedium(), itemConfig.GetScoringStrategy())
+		_, err = tx.Execute(sql, version, itemConfig.GetItemName(), itemConfig.GetItemWeight(), itemConfig.GetLowWatermark(), itemConfig.GetHighWatermark(),
+			itemConfig.GetUnit(), itemConfig.GetScoreDeductionPerUnitHigh(), itemConfig.GetMaxScoreDeductionHigh(),
+			itemConfig.GetScoreDeductionPerUnitMedium(), itemConfig.GetMaxScoreDeductionMedium(), itemConfig.GetScoringStrategy())
+		if err != nil {
+			return constant.ZeroInt, err
+		}
+	}
+
+	return version, tx.Commit()
+}
+
+// GetOverrides gets all the engine config overrides from the middleware
+func (ecr *EngineConfigRepo) GetOverrides() ([]healthcheck.EngineConfigOverride, error) {
+	sql := `
+		select id, scope_type, scope_id, item_name, config, del_flag, create_time, last_update_time
+		from t_hc_engine_config_override
+		where del_flag = 0
+		order by scope_type, scope_id, id;
+	`
+	log.Debugf("healthCheck EngineConfigRepo.GetOverrides() select sql: \n%s", sql)
+
+	result, err := ecr.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	return getEngineConfigOverridesFromResult(result)
+}
+
+// GetOverrideByID gets the engine config override by the identity from the middleware
+func (ecr *EngineConfigRepo) GetOverrideByID(id int) (healthcheck.EngineConfigOverride, error) {
+	sql := `
+		select id, scope_type, scope_id, item_name, config, del_flag, create_time, last_update_time
+		from t_hc_engine_config_override
+		where del_flag = 0
+		and id = ?;
+	`
+	log.Debugf("healthCheck EngineConfigRepo.GetOverrideByID() select sql: \n%s\nplaceholders: %s", sql, id)
+
+	result, err := ecr.Execute(sql, id)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := getEngineConfigOverridesFromResult(result)
+	if err != nil {
+		return nil, err
+	}
+	switch len(overrides) {
+	case 0:
+		return nil, fmt.Errorf("healthCheck EngineConfigRepo.GetOverrideByID(): data does not exists, id: %d", id)
+	case 1:
+		return overrides[constant.ZeroInt], nil
+	default:
+		return nil, fmt.Errorf("healthCheck EngineConfigRepo.GetOverrideByID(): duplicate key exists, id: %d", id)
+	}
+}
+
+// SaveOverride saves the engine config override in the middleware,
+// it replaces the config of the existing override which has the same scope and item name
+func (ecr *EngineConfigRepo) SaveOverride(override healthcheck.EngineConfigOverride) error {
+	sql := `
+		insert into t_hc_engine_config_override(scope_type, scope_id, item_name, config)
+		values(?, ?, ?, ?)
+		on duplicate key update config = values(config), del_flag = 0;
+	`
+	log.Debugf("healthCheck EngineConfigRepo.SaveOverride() insert sql: \n%s\nplaceholders: %s, %s, %s, %s",
+		sql, override.GetScopeType(), override.GetScopeID(), override.GetItemName(), override.GetConfig())
+	_, err := ecr.Execute(sql, override.GetScopeType(), override.GetScopeID(), override.GetItemName(), override.GetConfig())
+
+	return err
+}
+
+// DeleteOverride deletes the engine config override in the middleware
+func (ecr *EngineConfigRepo) DeleteOverride(id int) error {
+	sql := `delete from t_hc_engine_config_override where id = ?;`
+	log.Debugf("healthCheck EngineConfigRepo.DeleteOverride() delete sql: \n%s\nplaceholders: %s", sql, id)
+	_, err := ecr.Execute(sql, id)
+
+	return err
+}
+
+// getEngineConfigOverridesFromResult maps the result to the engine config overrides
+func getEngineConfigOverridesFromResult(result middleware.Result) ([]healthcheck.EngineConfigOverride, error) {
+	// init []*EngineConfigOverride
+	overrideList := make([]*EngineConfigOverride, result.RowNumber())
+	for i := range overrideList {
+		overrideList[i] = NewEmptyEngineConfigOverride()
+	}
+	// map to struct
+	err := result.MapToStructSlice(overrideList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.EngineConfigOverride
+	overrides := make([]healthcheck.EngineConfigOverride, result.RowNumber())
+	for i := range overrides {
+		overrides[i] = overrideList[i]
+	}
+
+	return overrides, nil
+}
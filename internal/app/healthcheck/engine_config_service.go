@@ -0,0 +1,303 @@
+package healthcheck
+
+import (
+	"strings"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	engineConfigVersionStruct     = "Version"
+	engineConfigItemConfigsStruct = "ItemConfigs"
+	engineConfigOverridesStruct   = "Overrides"
+)
+
+var _ healthcheck.EngineConfigService = (*EngineConfigService)(nil)
+
+// EngineConfigChange is the change of the engine config items which is submitted by the api
+type EngineConfigChange struct {
+	ItemNames []string             `json:"item_names"`
+	Items     []*DefaultItemConfig `json:"items"`
+}
+
+// GetItemNames returns the item names
+func (ecc *EngineConfigChange) GetItemNames() []string {
+	return ecc.ItemNames
+}
+
+// GetItemConfigs returns the item configs
+func (ecc *EngineConfigChange) GetItemConfigs() []healthcheck.ItemConfig {
+	itemConfigs := make([]healthcheck.ItemConfig, len(ecc.Items))
+	for i, item := range ecc.Items {
+		itemConfigs[i] = item
+	}
+
+	return itemConfigs
+}
+
+// EngineConfigService of the healthcheck default engine config
+type EngineConfigService struct {
+	healthcheck.EngineConfigRepo
+	Version     int                                `json:"version"`
+	ItemConfigs []healthcheck.ItemConfig           `json:"item_configs"`
+	Overrides   []healthcheck.EngineConfigOverride `json:"overrides"`
+}
+
+// NewEngineConfigService returns a new *EngineConfigService
+func NewEngineConfigService(repo healthcheck.EngineConfigRepo) *EngineConfigService {
+	return &EngineConfigService{repo, constant.ZeroInt, []healthcheck.ItemConfig{}, []healthcheck.EngineConfigOverride{}}
+}
+
+// NewEngineConfigServiceWithDefault returns a new *EngineConfigService with default repository
+func NewEngineConfigServiceWithDefault() *EngineConfigService {
+	return NewEngineConfigService(NewEngineConfigRepoWithGlobal())
+}
+
+// GetItemConfigs returns the item configs of the service
+func (ecs *EngineConfigService) GetItemConfigs() []healthcheck.ItemConfig {
+	return ecs.ItemConfigs
+}
+
+// GetLatest gets the item configs of the latest engine config version from the middleware
+func (ecs *EngineConfigService) GetLatest() error {
+	version, err := ecs.EngineConfigRepo.GetLatestVersion()
+	if err != nil {
+		return err
+	}
+
+	return ecs.GetByVersion(version)
+}
+
+// GetByVersion gets the item configs of given engine config version from the middleware
+func (ecs *EngineConfigService) GetByVersion(version int) error {
+	itemConfigs, err := ecs.EngineConfigRepo.GetByVersion(version)
+	if err != nil {
+		return err
+	}
+	if len(itemConfigs) == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrEngineConfigVersionNotExists, version)
+	}
+
+	ecs.Version = version
+	ecs.ItemConfigs = itemConfigs
+
+	return nil
+}
+
+// AddItems adds the item configs as a new engine config version, the items must not exist in the latest version
+func (ecs *EngineConfigService) AddItems(itemConfigs []healthcheck.ItemConfig) error {
+	if len(itemConfigs) == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrEngineConfigChangeEmpty)
+	}
+	engineConfig, err := ecs.getLatestEngineConfig()
+	if err != nil {
+		return err
+	}
+	for _, itemConfig := range itemConfigs {
+		if engineConfig.getItemConfig(itemConfig.GetItemName()) != nil {
+			return message.NewMessage(msghc.ErrEngineConfigItemAlreadyExists, itemConfig.GetItemName())
+		}
+	}
+
+	return ecs.save(engineConfig, itemConfigs, nil)
+}
+
+// UpdateItems updates the item configs as a new engine config version, the items must exist in the latest version
+func (ecs *EngineConfigService) UpdateItems(itemConfigs []healthcheck.ItemConfig) error {
+	if len(itemConfigs) == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrEngineConfigChangeEmpty)
+	}
+	engineConfig, err := ecs.getLatestEngineConfig()
+	if err != nil {
+		return err
+	}
+	for _, itemConfig := range itemConfigs {
+		if engineConfig.getItemConfig(itemConfig.GetItemName()) == nil {
+			return message.NewMessage(msghc.ErrEngineConfigItemNotExists, itemConfig.GetItemName())
+		}
+	}
+
+	return ecs.save(engineConfig, itemConfigs, nil)
+}
+
+// DeleteItems deletes the items as a new engine config version, the item configs will be updated in the same version,
+// so that the weights of the remaining items could be rebalanced
+func (ecs *EngineConfigService) DeleteItems(itemNames []string, itemConfigs []healthcheck.ItemConfig) error {
+	if len(itemNames) == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrEngineConfigChangeEmpty)
+	}
+	engineConfig, err := ecs.getLatestEngineConfig()
+	if err != nil {
+		return err
+	}
+	for _, itemConfig := range itemConfigs {
+		if engineConfig.getItemConfig(itemConfig.GetItemName()) == nil {
+			return message.NewMessage(msghc.ErrEngineConfigItemNotExists, itemConfig.GetItemName())
+		}
+	}
+
+	return ecs.save(engineConfig, itemConfigs, itemNames)
+}
+
+// GetOverrides returns the engine config overrides of the service
+func (ecs *EngineConfigService) GetOverrides() []healthcheck.EngineConfigOverride {
+	return ecs.Overrides
+}
+
+// GetAllOverrides gets all the engine config overrides from the middleware
+func (ecs *EngineConfigService) GetAllOverrides() error {
+	overrides, err := ecs.EngineConfigRepo.GetOverrides()
+	if err != nil {
+		return err
+	}
+
+	ecs.Overrides = overrides
+
+	return nil
+}
+
+// SaveOverride validates and saves the engine config override, the item must exist in the latest version,
+// and the item config which is overridden must be still valid
+func (ecs *EngineConfigService) SaveOverride(scopeType, scopeID int, itemName, config string) error {
+	if !isValidEngineConfigOverrideScopeType(scopeType) {
+		return message.NewMessage(msghc.ErrEngineConfigOverrideScopeTypeInvalid, scopeType)
+	}
+	engineConfig, err := ecs.getLatestEngineConfig()
+	if err != nil {
+		return err
+	}
+	if engineConfig.getItemConfig(itemName) == nil {
+		return message.NewMessage(msghc.ErrEngineConfigItemNotExists, itemName)
+	}
+	override := NewEngineConfigOverride(scopeType, scopeID, itemName, config)
+	engineConfig, err = applyEngineConfigOverrides(engineConfig, []healthcheck.EngineConfigOverride{override})
+	if err != nil {
+		return err
+	}
+	err = engineConfig.Validate()
+	if err != nil {
+		return err
+	}
+	err = ecs.EngineConfigRepo.SaveOverride(override)
+	if err != nil {
+		return err
+	}
+
+	return ecs.GetAllOverrides()
+}
+
+// DeleteOverride deletes the engine config override
+func (ecs *EngineConfigService) DeleteOverride(id int) error {
+	_, err := ecs.EngineConfigRepo.GetOverrideByID(id)
+	if err != nil {
+		return err
+	}
+	err = ecs.EngineConfigRepo.DeleteOverride(id)
+	if err != nil {
+		return err
+	}
+
+	return ecs.GetAllOverrides()
+}
+
+// Marshal marshals EngineConfigService.Version and EngineConfigService.ItemConfigs to json bytes
+func (ecs *EngineConfigService) Marshal() ([]byte, error) {
+	return ecs.MarshalWithFields(engineConfigVersionStruct, engineConfigItemConfigsStruct)
+}
+
+// MarshalOverrides marshals EngineConfigService.Overrides to json bytes
+func (ecs *EngineConfigService) MarshalOverrides() ([]byte, error) {
+	return ecs.MarshalWithFields(engineConfigOverridesStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the EngineConfigService to json bytes
+func (ecs *EngineConfigService) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(ecs, fields...)
+}
+
+// getLatestEngineConfig returns a copy of the latest engine config, it is empty if there is no config
+func (ecs *EngineConfigService) getLatestEngineConfig() (DefaultEngineConfig, error) {
+	version, err := ecs.EngineConfigRepo.GetLatestVersion()
+	if err != nil {
+		return nil, err
+	}
+	itemConfigs, err := ecs.EngineConfigRepo.GetByVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	engineConfig := NewEmptyDefaultEngineConfig()
+	for _, itemConfig := range itemConfigs {
+		engineConfig[itemConfig.GetItemName()] = newDefaultItemConfigWithItemConfig(itemConfig)
+	}
+
+	return engineConfig, nil
+}
+
+// save applies the changes to the engine config, validates it and creates a new engine config version with it,
+// the item configs of the service will be the new version once it is saved
+func (ecs *EngineConfigService) save(engineConfig DefaultEngineConfig, itemConfigs []healthcheck.ItemConfig, deletedItemNames []string) error {
+	changedItemNames := make(map[string]bool)
+	// delete items
+	for _, itemName := range deletedItemNames {
+		if changedItemNames[itemName] {
+			return message.NewMessage(msghc.ErrEngineConfigItemDuplicated, itemName)
+		}
+		changedItemNames[itemName] = true
+		if engineConfig.getItemConfig(itemName) == nil {
+			return message.NewMessage(msghc.ErrEngineConfigItemNotExists, itemName)
+		}
+		delete(engineConfig, itemName)
+	}
+	// add or update items
+	for _, itemConfig := range itemConfigs {
+		if changedItemNames[itemConfig.GetItemName()] {
+			return message.NewMessage(msghc.ErrEngineConfigItemDuplicated, itemConfig.GetItemName())
+		}
+		changedItemNames[itemConfig.GetItemName()] = true
+		engineConfig[itemConfig.GetItemName()] = newDefaultItemConfigWithItemConfig(itemConfig)
+	}
+	// validate
+	registeredItemNames := GetCheckItemRegistry().GetNames()
+	for itemName := range engineConfig {
+		if !common.StringInSlice(registeredItemNames, itemName) {
+			return message.NewMessage(msghc.ErrEngineConfigItemNameInvalid, strings.Join(registeredItemNames, constant.CommaString), itemName)
+		}
+	}
+	err := engineConfig.Validate()
+	if err != nil {
+		return err
+	}
+	// save the item configs in the registration order
+	newItemConfigs := make([]healthcheck.ItemConfig, constant.ZeroInt, len(engineConfig))
+	for _, itemName := range registeredItemNames {
+		itemConfig := engineConfig.getItemConfig(itemName)
+		if itemConfig != nil {
+			newItemConfigs = append(newItemConfigs, itemConfig)
+		}
+	}
+	version, err := ecs.EngineConfigRepo.Create(newItemConfigs)
+	if err != nil {
+		return err
+	}
+
+	return ecs.GetByVersion(version)
+}
+
+// newDefaultItemConfigWithItemConfig returns a new *DefaultItemConfig with the values of given item config,
+// the empty scoring strategy will be replaced by the default scoring strategy
+func newDefaultItemConfigWithItemConfig(itemConfig healthcheck.ItemConfig) *DefaultItemConfig {
+	defaultItemConfig := NewDefaultItemConfig(itemConfig.GetItemName(), itemConfig.GetItemWeight(), itemConfig.GetLowWatermark(),
+		itemConfig.GetHighWatermark(), itemConfig.GetUnit(), itemConfig.GetScoreDeductionPerUnitHigh(), itemConfig.GetMaxScoreDeductionHigh(),
+		itemConfig.GetScoreDeductionPerUnitMedium(), itemConfig.GetMaxScoreDeductionMedium())
+	defaultItemConfig.ScoringStrategy = itemConfig.GetScoringStrategy()
+	if defaultItemConfig.ScoringStrategy == constant.EmptyString {
+		defaultItemConfig.ScoringStrategy = defaultScoringStrategy
+	}
+
+	return defaultItemConfig
+}
@@ -0,0 +1,278 @@
+package healthcheck
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+// testEngineConfigRepo keeps the engine config versions in memory
+type testEngineConfigRepo struct {
+	versions  [][]healthcheck.ItemConfig
+	overrides []healthcheck.EngineConfigOverride
+}
+
+func newTestEngineConfigRepo() *testEngineConfigRepo {
+	itemConfigs := []healthcheck.ItemConfig{
+		NewDefaultItemConfig(defaultDBConfigItemName, 5, 0, 0, 0, 10, 50, 0, 0),
+		NewDefaultItemConfig(defaultCPUUsageItemName, 5, 50, 70, 10, 20, 100, 10, 50),
+		NewDefaultItemConfig(defaultIOUtilItemName, 5, 50, 70, 10, 20, 100, 10, 20),
+		NewDefaultItemConfig(defaultDiskCapacityUsageItemName, 15, 50, 80, 10, 40, 100, 10, 50),
+		NewDefaultItemConfig(defaultConnectionUsageItemName, 15, 50, 80, 10, 40, 100, 10, 50),
+		NewDefaultItemConfig(defaultAverageActiveSessionPercentsItemName, 5, 10, 20, 5, 10, 50, 5, 50),
+		NewDefaultItemConfig(defaultCacheMissRatioItemName, 5, 0.5, 2, 0.1, 20, 50, 10, 50),
+		NewDefaultItemConfig(defaultTableRowsItemName, 5, 10000000, 30000000, 1000000, 10, 50, 10, 50),
+		NewDefaultItemConfig(defaultTableSizeItemName, 5, 10, 30, 5, 10, 50, 10, 30),
+		NewDefaultItemConfig(defaultSlowQueryRowsExaminedItemName, 15, 100000, 1000000, 100000, 10, 100, 5, 50),
+		NewDefaultItemConfig(defaultReplicationItemName, 10, 60, 300, 60, 20, 100, 10, 50),
+		NewDefaultItemConfig(defaultInnoDBLockItemName, 5, 1, 5, 1, 20, 100, 10, 50),
+		NewDefaultItemConfig(defaultIndexHealthItemName, 5, 0, 0, 1, 10, 50, 2, 30),
+	}
+	repo := &testEngineConfigRepo{}
+	_, _ = repo.Create(itemConfigs)
+
+	return repo
+}
+
+func (r *testEngineConfigRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	return nil, nil
+}
+
+func (r *testEngineConfigRepo) Transaction() (middleware.Transaction, error) {
+	return nil, nil
+}
+
+func (r *testEngineConfigRepo) GetLatestVersion() (int, error) {
+	return len(r.versions), nil
+}
+
+func (r *testEngineConfigRepo) GetByVersion(version int) ([]healthcheck.ItemConfig, error) {
+	if version <= constant.ZeroInt || version > len(r.versions) {
+		return nil, nil
+	}
+
+	return r.versions[version-1], nil
+}
+
+func (r *testEngineConfigRepo) Create(itemConfigs []healthcheck.ItemConfig) (int, error) {
+	version := len(r.versions) + 1
+	versionItemConfigs := make([]healthcheck.ItemConfig, len(itemConfigs))
+	for i, itemConfig := range itemConfigs {
+		defaultItemConfig := newDefaultItemConfigWithItemConfig(itemConfig)
+		defaultItemConfig.ConfigVersion = version
+		versionItemConfigs[i] = defaultItemConfig
+	}
+	r.versions = append(r.versions, versionItemConfigs)
+
+	return version, nil
+}
+
+func (r *testEngineConfigRepo) GetOverrides() ([]healthcheck.EngineConfigOverride, error) {
+	return r.overrides, nil
+}
+
+func (r *testEngineConfigRepo) GetOverrideByID(id int) (healthcheck.EngineConfigOverride, error) {
+	for _, override := range r.overrides {
+		if override.Identity() == id {
+			return override, nil
+		}
+	}
+
+	return nil, fmt.Errorf("data does not exists, id: %d", id)
+}
+
+func (r *testEngineConfigRepo) SaveOverride(override healthcheck.EngineConfigOverride) error {
+	for i, existing := range r.overrides {
+		if existing.GetScopeType() == override.GetScopeType() && existing.GetScopeID() == override.GetScopeID() &&
+			existing.GetItemName() == override.GetItemName() {
+			r.overrides[i] = &EngineConfigOverride{
+				ID:        existing.Identity(),
+				ScopeType: existing.GetScopeType(),
+				ScopeID:   existing.GetScopeID(),
+				ItemName:  existing.GetItemName(),
+				Config:    override.GetConfig(),
+			}
+			return nil
+		}
+	}
+	r.overrides = append(r.overrides, &EngineConfigOverride{
+		ID:        len(r.overrides) + 1,
+		ScopeType: override.GetScopeType(),
+		ScopeID:   override.GetScopeID(),
+		ItemName:  override.GetItemName(),
+		Config:    override.GetConfig(),
+	})
+
+	return nil
+}
+
+func (r *testEngineConfigRepo) DeleteOverride(id int) error {
+	for i, override := range r.overrides {
+		if override.Identity() == id {
+			r.overrides = append(r.overrides[:i], r.overrides[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func getTestItemConfig(itemConfigs []healthcheck.ItemConfig, itemName string) healthcheck.ItemConfig {
+	for _, itemConfig := range itemConfigs {
+		if itemConfig.GetItemName() == itemName {
+			return itemConfig
+		}
+	}
+
+	return nil
+}
+
+func TestEngineConfigServiceAll(t *testing.T) {
+	TestEngineConfigService_GetLatest(t)
+	TestEngineConfigService_AddItems(t)
+	TestEngineConfigService_UpdateItems(t)
+	TestEngineConfigService_DeleteItems(t)
+	TestEngineConfigService_SaveOverride(t)
+	TestEngineConfigService_DeleteOverride(t)
+}
+
+func TestEngineConfigService_GetLatest(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewEngineConfigService(newTestEngineConfigRepo())
+	err := s.GetLatest()
+	asst.Nil(err, common.CombineMessageWithError("test GetLatest() failed", err))
+	asst.Equal(1, s.Version, "test GetLatest() failed")
+	asst.Equal(13, len(s.GetItemConfigs()), "test GetLatest() failed")
+	asst.Equal(ScoringStrategyMean, s.GetItemConfigs()[0].GetScoringStrategy(), "test GetLatest() failed")
+	err = s.GetByVersion(2)
+	asst.NotNil(err, "test GetByVersion() failed")
+}
+
+func TestEngineConfigService_AddItems(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := newTestEngineConfigRepo()
+	s := NewEngineConfigService(repo)
+	// existing item
+	err := s.AddItems([]healthcheck.ItemConfig{NewDefaultItemConfig(defaultCPUUsageItemName, 5, 50, 70, 10, 20, 100, 10, 50)})
+	asst.NotNil(err, "test AddItems() failed")
+	// unknown item
+	_, err = repo.Create(nil)
+	asst.Nil(err, common.CombineMessageWithError("test AddItems() failed", err))
+	err = s.AddItems([]healthcheck.ItemConfig{NewDefaultItemConfig("unknown_item", 100, 50, 70, 10, 20, 100, 10, 50)})
+	asst.NotNil(err, "test AddItems() failed")
+	// registered item with valid weight
+	err = s.AddItems([]healthcheck.ItemConfig{NewDefaultItemConfig(defaultCPUUsageItemName, 100, 50, 70, 10, 20, 100, 10, 50)})
+	asst.Nil(err, common.CombineMessageWithError("test AddItems() failed", err))
+	asst.Equal(3, s.Version, "test AddItems() failed")
+	asst.Equal(1, len(s.GetItemConfigs()), "test AddItems() failed")
+}
+
+func TestEngineConfigService_UpdateItems(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := newTestEngineConfigRepo()
+	s := NewEngineConfigService(repo)
+	// weights do not sum to 100
+	err := s.UpdateItems([]healthcheck.ItemConfig{NewDefaultItemConfig(defaultCPUUsageItemName, 10, 50, 70, 10, 20, 100, 10, 50)})
+	asst.NotNil(err, "test UpdateItems() failed")
+	// low watermark is not below high watermark
+	err = s.UpdateItems([]healthcheck.ItemConfig{NewDefaultItemConfig(defaultCPUUsageItemName, 5, 70, 70, 10, 20, 100, 10, 50)})
+	asst.NotNil(err, "test UpdateItems() failed")
+	// duplicated item
+	err = s.UpdateItems([]healthcheck.ItemConfig{
+		NewDefaultItemConfig(defaultCPUUsageItemName, 5, 50, 70, 10, 20, 100, 10, 50),
+		NewDefaultItemConfig(defaultCPUUsageItemName, 5, 50, 70, 10, 20, 100, 10, 50),
+	})
+	asst.NotNil(err, "test UpdateItems() failed")
+	// invalid changes must not create any version
+	version, err := repo.GetLatestVersion()
+	asst.Nil(err, common.CombineMessageWithError("test UpdateItems() failed", err))
+	asst.Equal(1, version, "test UpdateItems() failed")
+	// rebalance the weights
+	err = s.UpdateItems([]healthcheck.ItemConfig{
+		NewDefaultItemConfig(defaultCPUUsageItemName, 10, 60, 80, 10, 20, 100, 10, 50),
+		NewDefaultItemConfig(defaultIOUtilItemName, 0, 50, 70, 10, 20, 100, 10, 20),
+	})
+	asst.Nil(err, common.CombineMessageWithError("test UpdateItems() failed", err))
+	asst.Equal(2, s.Version, "test UpdateItems() failed")
+	asst.Equal(60.0, getTestItemConfig(s.GetItemConfigs(), defaultCPUUsageItemName).GetLowWatermark(), "test UpdateItems() failed")
+	// the old version is not changed
+	err = s.GetByVersion(1)
+	asst.Nil(err, common.CombineMessageWithError("test UpdateItems() failed", err))
+	asst.Equal(50.0, getTestItemConfig(s.GetItemConfigs(), defaultCPUUsageItemName).GetLowWatermark(), "test UpdateItems() failed")
+}
+
+func TestEngineConfigService_DeleteItems(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewEngineConfigService(newTestEngineConfigRepo())
+	// weights do not sum to 100
+	err := s.DeleteItems([]string{defaultIndexHealthItemName}, nil)
+	asst.NotNil(err, "test DeleteItems() failed")
+	// not existing item
+	err = s.DeleteItems([]string{"unknown_item"}, nil)
+	asst.NotNil(err, "test DeleteItems() failed")
+	// deleted and updated in the same change
+	err = s.DeleteItems([]string{defaultIndexHealthItemName},
+		[]healthcheck.ItemConfig{NewDefaultItemConfig(defaultIndexHealthItemName, 5, 0, 0, 1, 10, 50, 2, 30)})
+	asst.NotNil(err, "test DeleteItems() failed")
+	// rebalance the weights
+	err = s.DeleteItems([]string{defaultIndexHealthItemName},
+		[]healthcheck.ItemConfig{NewDefaultItemConfig(defaultCPUUsageItemName, 10, 50, 70, 10, 20, 100, 10, 50)})
+	asst.Nil(err, common.CombineMessageWithError("test DeleteItems() failed", err))
+	asst.Equal(2, s.Version, "test DeleteItems() failed")
+	asst.Equal(12, len(s.GetItemConfigs()), "test DeleteItems() failed")
+	asst.Nil(getTestItemConfig(s.GetItemConfigs(), defaultIndexHealthItemName), "test DeleteItems() failed")
+}
+
+func TestEngineConfigService_SaveOverride(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewEngineConfigService(newTestEngineConfigRepo())
+	// invalid scope type
+	err := s.SaveOverride(4, 1, defaultCPUUsageItemName, `{"low_watermark": 60}`)
+	asst.NotNil(err, "test SaveOverride() failed")
+	// not existing item
+	err = s.SaveOverride(EngineConfigOverrideScopeEnv, 1, "unknown_item", `{"low_watermark": 60}`)
+	asst.NotNil(err, "test SaveOverride() failed")
+	// item weight could not be overridden
+	err = s.SaveOverride(EngineConfigOverrideScopeEnv, 1, defaultCPUUsageItemName, `{"item_weight": 10}`)
+	asst.NotNil(err, "test SaveOverride() failed")
+	// overridden low watermark is not below high watermark
+	err = s.SaveOverride(EngineConfigOverrideScopeEnv, 1, defaultCPUUsageItemName, `{"low_watermark": 80}`)
+	asst.NotNil(err, "test SaveOverride() failed")
+	// invalid scoring strategy
+	err = s.SaveOverride(EngineConfigOverrideScopeEnv, 1, defaultCPUUsageItemName, `{"scoring_strategy": "max"}`)
+	asst.NotNil(err, "test SaveOverride() failed")
+	asst.Equal(0, len(s.GetOverrides()), "test SaveOverride() failed")
+	// valid override
+	err = s.SaveOverride(EngineConfigOverrideScopeEnv, 1, defaultCPUUsageItemName, `{"low_watermark": 60}`)
+	asst.Nil(err, common.CombineMessageWithError("test SaveOverride() failed", err))
+	asst.Equal(1, len(s.GetOverrides()), "test SaveOverride() failed")
+	// the override of the same scope and item is replaced
+	err = s.SaveOverride(EngineConfigOverrideScopeEnv, 1, defaultCPUUsageItemName, `{"low_watermark": 65}`)
+	asst.Nil(err, common.CombineMessageWithError("test SaveOverride() failed", err))
+	asst.Equal(1, len(s.GetOverrides()), "test SaveOverride() failed")
+	asst.Equal(`{"low_watermark": 65}`, s.GetOverrides()[0].GetConfig(), "test SaveOverride() failed")
+}
+
+func TestEngineConfigService_DeleteOverride(t *testing.T) {
+	asst := assert.New(t)
+
+	s := NewEngineConfigService(newTestEngineConfigRepo())
+	err := s.SaveOverride(EngineConfigOverrideScopeMySQLCluster, 1, defaultCPUUsageItemName, `{"low_watermark": 60}`)
+	asst.Nil(err, common.CombineMessageWithError("test DeleteOverride() failed", err))
+	// not existing override
+	err = s.DeleteOverride(2)
+	asst.NotNil(err, "test DeleteOverride() failed")
+	err = s.DeleteOverride(1)
+	asst.Nil(err, common.CombineMessageWithError("test DeleteOverride() failed", err))
+	asst.Equal(0, len(s.GetOverrides()), "test DeleteOverride() failed")
+}
@@ -0,0 +1,207 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	IndexFindingTypeUnusedIndex       = "unused_index"
+	IndexFindingTypeRedundantIndex    = "redundant_index"
+	IndexFindingTypeMissingPrimaryKey = "missing_primary_key"
+	IndexFindingTypeNonInnoDBTable    = "non_innodb_table"
+
+	indexHealthPrimaryKeyColumn = "id"
+)
+
+var (
+	_ healthcheck.IndexFinding = (*IndexFinding)(nil)
+	_ healthcheck.CheckItem    = (*IndexHealthItem)(nil)
+
+	// the findings of the high severity types deduct the score deduction per unit high,
+	// and the others deduct the score deduction per unit medium
+	indexFindingHighSeverityTypes = map[string]bool{
+		IndexFindingTypeMissingPrimaryKey: true,
+		IndexFindingTypeNonInnoDBTable:    true,
+	}
+)
+
+// IndexFinding is an index or table which should be optimized
+type IndexFinding struct {
+	FindingType       string `middleware:"finding_type" json:"finding_type"`
+	TableSchema       string `middleware:"table_schema" json:"table_schema"`
+	TableName         string `middleware:"table_name" json:"table_name"`
+	IndexName         string `middleware:"index_name" json:"index_name"`
+	Columns           string `middleware:"columns" json:"columns"`
+	DominantIndexName string `middleware:"dominant_index_name" json:"dominant_index_name"`
+	Engine            string `middleware:"engine" json:"engine"`
+}
+
+// NewEmptyIndexFinding returns a new empty *IndexFinding
+func NewEmptyIndexFinding() *IndexFinding {
+	return &IndexFinding{}
+}
+
+// GetFindingType returns the finding type
+func (inf *IndexFinding) GetFindingType() string {
+	return inf.FindingType
+}
+
+// GetTableSchema returns the table schema
+func (inf *IndexFinding) GetTableSchema() string {
+	return inf.TableSchema
+}
+
+// GetTableName returns the table name
+func (inf *IndexFinding) GetTableName() string {
+	return inf.TableName
+}
+
+// GetIndexName returns the index name, it is empty if the finding is about the table
+func (inf *IndexFinding) GetIndexName() string {
+	return inf.IndexName
+}
+
+// GetColumns returns the columns of the index
+func (inf *IndexFinding) GetColumns() string {
+	return inf.Columns
+}
+
+// GetDominantIndexName returns the name of the index which covers the redundant index
+func (inf *IndexFinding) GetDominantIndexName() string {
+	return inf.DominantIndexName
+}
+
+// GetEngine returns the storage engine of the table
+func (inf *IndexFinding) GetEngine() string {
+	return inf.Engine
+}
+
+// IndexAdvice is the advice of an index finding
+type IndexAdvice struct {
+	FindingType string `json:"finding_type"`
+	TableSchema string `json:"table_schema"`
+	TableName   string `json:"table_name"`
+	IndexName   string `json:"index_name"`
+	Reason      string `json:"reason"`
+	DDL         string `json:"ddl"`
+}
+
+// quoteIdentifier quotes the identifier with backticks
+func quoteIdentifier(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// newIndexAdvice returns the advice of the index finding, the ddl is only a suggestion,
+// it should be reviewed before being executed, for example, the unused index may be used by the queries which run rarely
+func newIndexAdvice(finding healthcheck.IndexFinding) *IndexAdvice {
+	tableName := quoteIdentifier(finding.GetTableSchema()) + constant.DotString + quoteIdentifier(finding.GetTableName())
+	advice := &IndexAdvice{
+		FindingType: finding.GetFindingType(),
+		TableSchema: finding.GetTableSchema(),
+		TableName:   finding.GetTableName(),
+		IndexName:   finding.GetIndexName(),
+	}
+
+	switch finding.GetFindingType() {
+	case IndexFindingTypeUnusedIndex:
+		advice.Reason = "the index has not been used since the mysql server started"
+		advice.DDL = fmt.Sprintf("alter table %s drop index %s;", tableName, quoteIdentifier(finding.GetIndexName()))
+	case IndexFindingTypeRedundantIndex:
+		advice.Reason = fmt.Sprintf("the index on (%s) is covered by index %s", finding.GetColumns(), finding.GetDominantIndexName())
+		advice.DDL = fmt.Sprintf("alter table %s drop index %s;", tableName, quoteIdentifier(finding.GetIndexName()))
+	case IndexFindingTypeMissingPrimaryKey:
+		advice.Reason = "the table does not have a primary key, it slows down the replication and may cause the replication lag"
+		advice.DDL = fmt.Sprintf("alter table %s add column %s bigint unsigned not null auto_increment primary key first;",
+			tableName, quoteIdentifier(indexHealthPrimaryKeyColumn))
+	case IndexFindingTypeNonInnoDBTable:
+		advice.Reason = fmt.Sprintf("the storage engine of the table is %s, it does not support the transaction and the crash recovery", finding.GetEngine())
+		advice.DDL = fmt.Sprintf("alter table %s engine = InnoDB;", tableName)
+	}
+
+	return advice
+}
+
+// IndexHealthItem checks the unused indexes, the redundant indexes, the tables without primary key and the non-innodb tables
+type IndexHealthItem struct {
+	engine   *DefaultEngine
+	findings []healthcheck.IndexFinding
+	advices  []*IndexAdvice
+}
+
+// NewIndexHealthItem returns a new healthcheck.CheckItem which checks the index health
+func NewIndexHealthItem(de *DefaultEngine) healthcheck.CheckItem {
+	return &IndexHealthItem{engine: de}
+}
+
+// GetName returns the item name
+func (ihi *IndexHealthItem) GetName() string {
+	return defaultIndexHealthItemName
+}
+
+// Collect collects the index findings and generates the advice of each finding
+func (ihi *IndexHealthItem) Collect() error {
+	repo := ihi.engine.GetApplicationMySQLRepo()
+	for _, getFindings := range []func() ([]healthcheck.IndexFinding, error){
+		repo.GetTablesWithoutPrimaryKey,
+		repo.GetNonInnoDBTables,
+		repo.GetRedundantIndexes,
+		repo.GetUnusedIndexes,
+	} {
+		findings, err := getFindings()
+		if err != nil {
+			return err
+		}
+		ihi.findings = append(ihi.findings, findings...)
+	}
+
+	for _, finding := range ihi.findings {
+		ihi.advices = append(ihi.advices, newIndexAdvice(finding))
+	}
+
+	return nil
+}
+
+// Score scores the index health, the tables without primary key and the non-innodb tables deduct the score deduction per unit high,
+// the unused indexes and the redundant indexes deduct the score deduction per unit medium
+func (ihi *IndexHealthItem) Score(config healthcheck.ItemConfig) (int, error) {
+	var (
+		highCount   int
+		mediumCount int
+	)
+	for _, finding := range ihi.findings {
+		if indexFindingHighSeverityTypes[finding.GetFindingType()] {
+			highCount++
+			continue
+		}
+		mediumCount++
+	}
+
+	scoreDeductionHigh := math.Min(float64(highCount)*config.GetScoreDeductionPerUnitHigh(), config.GetMaxScoreDeductionHigh())
+	scoreDeductionMedium := math.Min(float64(mediumCount)*config.GetScoreDeductionPerUnitMedium(), config.GetMaxScoreDeductionMedium())
+	score := int(defaultMaxScore - scoreDeductionHigh - scoreDeductionMedium)
+	if score < constant.ZeroInt {
+		score = constant.ZeroInt
+	}
+
+	return score, nil
+}
+
+// Advice returns the index findings and the ddl suggestions
+func (ihi *IndexHealthItem) Advice() (string, string, string, error) {
+	jsonBytesTotal, err := json.Marshal(ihi.findings)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	jsonBytesAdvice, err := json.Marshal(ihi.advices)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	return string(jsonBytesTotal), constant.EmptyString, string(jsonBytesAdvice), nil
+}
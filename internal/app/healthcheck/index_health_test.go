@@ -0,0 +1,89 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIndexHealthConfig() *DefaultItemConfig {
+	return &DefaultItemConfig{
+		ItemName:                    defaultIndexHealthItemName,
+		ItemWeight:                  5,
+		Unit:                        1,
+		ScoreDeductionPerUnitHigh:   10,
+		MaxScoreDeductionHigh:       50,
+		ScoreDeductionPerUnitMedium: 2,
+		MaxScoreDeductionMedium:     30,
+	}
+}
+
+func newTestIndexFindings() []healthcheck.IndexFinding {
+	return []healthcheck.IndexFinding{
+		&IndexFinding{FindingType: IndexFindingTypeMissingPrimaryKey, TableSchema: "db1", TableName: "t01", Engine: "InnoDB"},
+		&IndexFinding{FindingType: IndexFindingTypeNonInnoDBTable, TableSchema: "db1", TableName: "t02", Engine: "MyISAM"},
+		&IndexFinding{FindingType: IndexFindingTypeRedundantIndex, TableSchema: "db1", TableName: "t03", IndexName: "idx01_col1", Columns: "col1", DominantIndexName: "idx02_col1_col2"},
+		&IndexFinding{FindingType: IndexFindingTypeUnusedIndex, TableSchema: "db1", TableName: "t`04", IndexName: "idx03_col3"},
+	}
+}
+
+func TestIndexHealthAll(t *testing.T) {
+	TestIndexHealth_newIndexAdvice(t)
+	TestIndexHealthItem_Score(t)
+	TestIndexHealthItem_Advice(t)
+}
+
+func TestIndexHealth_newIndexAdvice(t *testing.T) {
+	asst := assert.New(t)
+
+	expected := []string{
+		"alter table `db1`.`t01` add column `id` bigint unsigned not null auto_increment primary key first;",
+		"alter table `db1`.`t02` engine = InnoDB;",
+		"alter table `db1`.`t03` drop index `idx01_col1`;",
+		"alter table `db1`.`t``04` drop index `idx03_col3`;",
+	}
+	for i, finding := range newTestIndexFindings() {
+		advice := newIndexAdvice(finding)
+		asst.Equal(expected[i], advice.DDL, "test newIndexAdvice() failed")
+		asst.NotEmpty(advice.Reason, "test newIndexAdvice() failed")
+	}
+}
+
+func TestIndexHealthItem_Score(t *testing.T) {
+	asst := assert.New(t)
+
+	ihi := &IndexHealthItem{findings: newTestIndexFindings()}
+	score, err := ihi.Score(newTestIndexHealthConfig())
+	asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+	asst.Equal(76, score, "test Score() failed")
+
+	var findings []healthcheck.IndexFinding
+	for i := 0; i < 10; i++ {
+		findings = append(findings, newTestIndexFindings()...)
+	}
+	ihi = &IndexHealthItem{findings: findings}
+	score, err = ihi.Score(newTestIndexHealthConfig())
+	asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+	asst.Equal(20, score, "test Score() failed")
+
+	ihi = &IndexHealthItem{}
+	score, err = ihi.Score(newTestIndexHealthConfig())
+	asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+	asst.Equal(100, score, "test Score() failed")
+}
+
+func TestIndexHealthItem_Advice(t *testing.T) {
+	asst := assert.New(t)
+
+	ihi := &IndexHealthItem{findings: newTestIndexFindings()}
+	for _, finding := range ihi.findings {
+		ihi.advices = append(ihi.advices, newIndexAdvice(finding))
+	}
+	data, high, advice, err := ihi.Advice()
+	asst.Nil(err, common.CombineMessageWithError("test Advice() failed", err))
+	asst.Contains(data, `"finding_type":"missing_primary_key"`, "test Advice() failed")
+	asst.Empty(high, "test Advice() failed")
+	asst.Contains(advice, "engine = InnoDB", "test Advice() failed")
+}
@@ -0,0 +1,289 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	innoDBStatusColumn         = "Status"
+	innoDBStatusDeadlockHeader = "LATEST DETECTED DEADLOCK"
+	// the detected time of the deadlock is printed as 2006-01-02 15:04:05 since mysql 5.7, and 060102 15:04:05 before
+	innoDBStatusTimeLayout    = "2006-01-02 15:04:05"
+	innoDBStatusTimeLayoutOld = "060102 15:04:05"
+
+	defaultLockTopBlockingNum = 5
+)
+
+var (
+	_ healthcheck.LockWait  = (*LockWait)(nil)
+	_ healthcheck.CheckItem = (*InnoDBLockItem)(nil)
+
+	// innoDBStatusSectionRegexp matches the header of the sections of show engine innodb status, such as
+	// ------------
+	// TRANSACTIONS
+	// ------------
+	innoDBStatusSectionRegexp = regexp.MustCompile(`\n-+\n[A-Z][A-Z /]+\n-+\n`)
+)
+
+// LockWait is an innodb lock wait which is waiting now
+type LockWait struct {
+	WaitAgeSecs   int    `middleware:"wait_age_secs" json:"wait_age_secs"`
+	LockedTable   string `middleware:"locked_table" json:"locked_table"`
+	LockedIndex   string `middleware:"locked_index" json:"locked_index"`
+	LockedType    string `middleware:"locked_type" json:"locked_type"`
+	WaitingPID    int    `middleware:"waiting_pid" json:"waiting_pid"`
+	WaitingQuery  string `middleware:"waiting_query" json:"waiting_query"`
+	BlockingPID   int    `middleware:"blocking_pid" json:"blocking_pid"`
+	BlockingQuery string `middleware:"blocking_query" json:"blocking_query"`
+}
+
+// NewEmptyLockWait returns a new empty *LockWait
+func NewEmptyLockWait() *LockWait {
+	return &LockWait{}
+}
+
+// GetWaitAgeSecs returns how many seconds the lock has been waited for
+func (lw *LockWait) GetWaitAgeSecs() int {
+	return lw.WaitAgeSecs
+}
+
+// GetLockedTable returns the locked table
+func (lw *LockWait) GetLockedTable() string {
+	return lw.LockedTable
+}
+
+// GetLockedIndex returns the locked index
+func (lw *LockWait) GetLockedIndex() string {
+	return lw.LockedIndex
+}
+
+// GetLockedType returns the lock type
+func (lw *LockWait) GetLockedType() string {
+	return lw.LockedType
+}
+
+// GetWaitingPID returns the processlist id of the waiting session
+func (lw *LockWait) GetWaitingPID() int {
+	return lw.WaitingPID
+}
+
+// GetWaitingQuery returns the waiting query
+func (lw *LockWait) GetWaitingQuery() string {
+	return lw.WaitingQuery
+}
+
+// GetBlockingPID returns the processlist id of the blocking session
+func (lw *LockWait) GetBlockingPID() int {
+	return lw.BlockingPID
+}
+
+// GetBlockingQuery returns the blocking query, it is empty if the blocking session is idle in the transaction
+func (lw *LockWait) GetBlockingQuery() string {
+	return lw.BlockingQuery
+}
+
+// BlockingStatement is a statement which blocks other sessions
+type BlockingStatement struct {
+	BlockingPID    int      `json:"blocking_pid"`
+	BlockingQuery  string   `json:"blocking_query"`
+	BlockedCount   int      `json:"blocked_count"`
+	MaxWaitAgeSecs int      `json:"max_wait_age_secs"`
+	LockedTables   []string `json:"locked_tables"`
+}
+
+// getWorstBlockingStatements groups the lock waits by the blocking session and returns the statements which block the most sessions,
+// the statements which block the sessions for a longer time come first if they block the same number of sessions
+func getWorstBlockingStatements(lockWaits []healthcheck.LockWait, num int) []*BlockingStatement {
+	var statements []*BlockingStatement
+	statementMap := make(map[int]*BlockingStatement)
+	for _, lockWait := range lockWaits {
+		statement, exists := statementMap[lockWait.GetBlockingPID()]
+		if !exists {
+			statement = &BlockingStatement{
+				BlockingPID:   lockWait.GetBlockingPID(),
+				BlockingQuery: lockWait.GetBlockingQuery(),
+			}
+			statementMap[lockWait.GetBlockingPID()] = statement
+			statements = append(statements, statement)
+		}
+		statement.BlockedCount++
+		if lockWait.GetWaitAgeSecs() > statement.MaxWaitAgeSecs {
+			statement.MaxWaitAgeSecs = lockWait.GetWaitAgeSecs()
+		}
+		if !common.StringInSlice(statement.LockedTables, lockWait.GetLockedTable()) {
+			statement.LockedTables = append(statement.LockedTables, lockWait.GetLockedTable())
+		}
+	}
+
+	sort.SliceStable(statements, func(i, j int) bool {
+		if statements[i].BlockedCount != statements[j].BlockedCount {
+			return statements[i].BlockedCount > statements[j].BlockedCount
+		}
+		return statements[i].MaxWaitAgeSecs > statements[j].MaxWaitAgeSecs
+	})
+	if len(statements) > num {
+		statements = statements[:num]
+	}
+
+	return statements
+}
+
+// Deadlock is the latest detected deadlock
+type Deadlock struct {
+	DetectedTime time.Time `json:"detected_time"`
+	Detail       string    `json:"detail"`
+}
+
+// parseLatestDeadlock parses the latest detected deadlock from the output of show engine innodb status,
+// it returns nil if there is no deadlock since the mysql server started,
+// and the detected time will be zero if it could not be parsed
+func parseLatestDeadlock(innoDBStatus string) *Deadlock {
+	index := strings.Index(innoDBStatus, innoDBStatusDeadlockHeader)
+	if index < constant.ZeroInt {
+		return nil
+	}
+	// skip the header line and the dash line under the header
+	section := strings.TrimLeft(innoDBStatus[index+len(innoDBStatusDeadlockHeader):], "-\n")
+	loc := innoDBStatusSectionRegexp.FindStringIndex(section)
+	if loc != nil {
+		section = section[:loc[0]]
+	}
+	section = strings.TrimSpace(section)
+
+	deadlock := &Deadlock{Detail: section}
+	for _, layout := range []string{innoDBStatusTimeLayout, innoDBStatusTimeLayoutOld} {
+		if len(section) < len(layout) {
+			continue
+		}
+		detectedTime, err := time.ParseInLocation(layout, section[:len(layout)], time.Local)
+		if err == nil {
+			deadlock.DetectedTime = detectedTime
+			break
+		}
+	}
+
+	return deadlock
+}
+
+// innoDBLockData is the data of the innodb lock item which will be saved in the result
+type innoDBLockData struct {
+	RowLockWaits   []healthcheck.PrometheusData `json:"row_lock_waits"`
+	RowLockTime    []healthcheck.PrometheusData `json:"row_lock_time"`
+	LockWaits      []healthcheck.LockWait       `json:"lock_waits"`
+	LatestDeadlock *Deadlock                    `json:"latest_deadlock"`
+}
+
+// innoDBLockAdvice is the advice of the innodb lock item which will be saved in the result
+type innoDBLockAdvice struct {
+	BlockingStatements []*BlockingStatement `json:"blocking_statements"`
+	Deadlock           *Deadlock            `json:"deadlock"`
+}
+
+// InnoDBLockItem checks the innodb row lock waits and deadlocks
+type InnoDBLockItem struct {
+	engine             *DefaultEngine
+	rowLockWaits       *PrometheusItem
+	rowLockTime        []healthcheck.PrometheusData
+	lockWaits          []healthcheck.LockWait
+	latestDeadlock     *Deadlock
+	blockingStatements []*BlockingStatement
+	deadlockInRange    bool
+}
+
+// NewInnoDBLockItem returns a new healthcheck.CheckItem which checks the innodb row lock waits and deadlocks
+func NewInnoDBLockItem(de *DefaultEngine) healthcheck.CheckItem {
+	return &InnoDBLockItem{
+		engine:       de,
+		rowLockWaits: NewPrometheusItem(defaultInnoDBLockItemName, de.GetPrometheusRepo().GetInnoDBRowLockWaits),
+	}
+}
+
+// GetName returns the item name
+func (ili *InnoDBLockItem) GetName() string {
+	return defaultInnoDBLockItemName
+}
+
+// Collect collects the row lock series from the prometheus, the lock waits and the latest deadlock from the application mysql
+func (ili *InnoDBLockItem) Collect() error {
+	err := ili.rowLockWaits.Collect()
+	if err != nil {
+		return err
+	}
+	ili.rowLockTime, err = ili.engine.GetPrometheusRepo().GetInnoDBRowLockTime()
+	if err != nil {
+		return err
+	}
+
+	ili.lockWaits, err = ili.engine.GetApplicationMySQLRepo().GetLockWaits()
+	if err != nil {
+		return err
+	}
+	ili.blockingStatements = getWorstBlockingStatements(ili.lockWaits, defaultLockTopBlockingNum)
+
+	innoDBStatus, err := ili.engine.GetApplicationMySQLRepo().GetInnoDBStatus()
+	if err != nil {
+		return err
+	}
+	ili.latestDeadlock = parseLatestDeadlock(innoDBStatus)
+	if ili.latestDeadlock != nil && !ili.latestDeadlock.DetectedTime.IsZero() {
+		operationInfo := ili.engine.GetOperationInfo()
+		ili.deadlockInRange = !ili.latestDeadlock.DetectedTime.Before(operationInfo.GetStartTime()) &&
+			!ili.latestDeadlock.DetectedTime.After(operationInfo.GetEndTime())
+	}
+
+	return nil
+}
+
+// Score scores the row lock waits per second with the watermarks,
+// and the deadlock which was detected in the time range of the operation deducts the max score deduction medium
+func (ili *InnoDBLockItem) Score(config healthcheck.ItemConfig) (int, error) {
+	score, err := ili.rowLockWaits.Score(config)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	if ili.deadlockInRange {
+		score -= int(config.GetMaxScoreDeductionMedium())
+	}
+	if score < constant.ZeroInt {
+		score = constant.ZeroInt
+	}
+
+	return score, nil
+}
+
+// Advice returns the lock data, the row lock waits which are higher than the high watermark,
+// and the worst blocking statements with the deadlock which was detected in the time range of the operation
+func (ili *InnoDBLockItem) Advice() (string, string, string, error) {
+	data := &innoDBLockData{
+		RowLockWaits:   ili.rowLockWaits.datas,
+		RowLockTime:    ili.rowLockTime,
+		LockWaits:      ili.lockWaits,
+		LatestDeadlock: ili.latestDeadlock,
+	}
+	jsonBytesTotal, err := json.Marshal(data)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	jsonBytesHigh, err := json.Marshal(ili.rowLockWaits.highDatas)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	advice := &innoDBLockAdvice{BlockingStatements: ili.blockingStatements}
+	if ili.deadlockInRange {
+		advice.Deadlock = ili.latestDeadlock
+	}
+	jsonBytesAdvice, err := json.Marshal(advice)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	return string(jsonBytesTotal), string(jsonBytesHigh), string(jsonBytesAdvice), nil
+}
@@ -0,0 +1,121 @@
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const testInnoDBStatus = `
+=====================================
+2021-01-21 10:05:00 0x7f2b4c1b1700 INNODB MONITOR OUTPUT
+=====================================
+------------------------
+LATEST DETECTED DEADLOCK
+------------------------
+2021-01-21 09:30:00 0x7f2b4c1b1700
+*** (1) TRANSACTION:
+TRANSACTION 1001, ACTIVE 5 sec starting index read
+update t01 set col1 = 1 where id = 1
+*** (2) TRANSACTION:
+TRANSACTION 1002, ACTIVE 4 sec starting index read
+update t01 set col1 = 2 where id = 2
+*** WE ROLL BACK TRANSACTION (2)
+------------
+TRANSACTIONS
+------------
+Trx id counter 1003
+`
+
+func newTestInnoDBLockConfig() *DefaultItemConfig {
+	return &DefaultItemConfig{
+		ItemName:                    defaultInnoDBLockItemName,
+		ItemWeight:                  5,
+		LowWatermark:                1,
+		HighWatermark:               5,
+		Unit:                        1,
+		ScoreDeductionPerUnitHigh:   20,
+		MaxScoreDeductionHigh:       100,
+		ScoreDeductionPerUnitMedium: 10,
+		MaxScoreDeductionMedium:     50,
+	}
+}
+
+func TestInnoDBLockAll(t *testing.T) {
+	TestInnoDBLock_parseLatestDeadlock(t)
+	TestInnoDBLock_getWorstBlockingStatements(t)
+	TestInnoDBLockItem_Score(t)
+	TestInnoDBLockItem_Advice(t)
+}
+
+func TestInnoDBLock_parseLatestDeadlock(t *testing.T) {
+	asst := assert.New(t)
+
+	deadlock := parseLatestDeadlock(testInnoDBStatus)
+	asst.NotNil(deadlock, "test parseLatestDeadlock() failed")
+	asst.Equal(time.Date(2021, 1, 21, 9, 30, 0, 0, time.Local), deadlock.DetectedTime, "test parseLatestDeadlock() failed")
+	asst.Contains(deadlock.Detail, "WE ROLL BACK TRANSACTION (2)", "test parseLatestDeadlock() failed")
+	asst.NotContains(deadlock.Detail, "Trx id counter", "test parseLatestDeadlock() failed")
+
+	asst.Nil(parseLatestDeadlock("------------\nTRANSACTIONS\n------------\n"), "test parseLatestDeadlock() failed")
+}
+
+func TestInnoDBLock_getWorstBlockingStatements(t *testing.T) {
+	asst := assert.New(t)
+
+	lockWaits := []healthcheck.LockWait{
+		&LockWait{WaitAgeSecs: 10, LockedTable: "`db1`.`t01`", WaitingPID: 11, BlockingPID: 1, BlockingQuery: "update t01 set col1 = 1"},
+		&LockWait{WaitAgeSecs: 30, LockedTable: "`db1`.`t02`", WaitingPID: 12, BlockingPID: 2},
+		&LockWait{WaitAgeSecs: 20, LockedTable: "`db1`.`t01`", WaitingPID: 13, BlockingPID: 1, BlockingQuery: "update t01 set col1 = 1"},
+		&LockWait{WaitAgeSecs: 5, LockedTable: "`db1`.`t03`", WaitingPID: 14, BlockingPID: 3},
+	}
+	statements := getWorstBlockingStatements(lockWaits, 2)
+	asst.Equal(2, len(statements), "test getWorstBlockingStatements() failed")
+	asst.Equal(1, statements[0].BlockingPID, "test getWorstBlockingStatements() failed")
+	asst.Equal(2, statements[0].BlockedCount, "test getWorstBlockingStatements() failed")
+	asst.Equal(20, statements[0].MaxWaitAgeSecs, "test getWorstBlockingStatements() failed")
+	asst.Equal([]string{"`db1`.`t01`"}, statements[0].LockedTables, "test getWorstBlockingStatements() failed")
+	asst.Equal(2, statements[1].BlockingPID, "test getWorstBlockingStatements() failed")
+}
+
+func TestInnoDBLockItem_Score(t *testing.T) {
+	asst := assert.New(t)
+
+	// the average of the high data is 7, and the average of the medium data is 3
+	rowLockWaits := NewPrometheusItem(defaultInnoDBLockItemName, nil)
+	rowLockWaits.datas = newTestPrometheusDatas(0.5, 3, 3, 7)
+	ili := &InnoDBLockItem{rowLockWaits: rowLockWaits}
+	score, err := ili.Score(newTestInnoDBLockConfig())
+	asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+	asst.Equal(40, score, "test Score() failed")
+
+	rowLockWaits = NewPrometheusItem(defaultInnoDBLockItemName, nil)
+	rowLockWaits.datas = newTestPrometheusDatas(0.5, 3, 3, 7)
+	ili = &InnoDBLockItem{rowLockWaits: rowLockWaits, deadlockInRange: true}
+	score, err = ili.Score(newTestInnoDBLockConfig())
+	asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+	asst.Equal(0, score, "test Score() failed")
+}
+
+func TestInnoDBLockItem_Advice(t *testing.T) {
+	asst := assert.New(t)
+
+	rowLockWaits := NewPrometheusItem(defaultInnoDBLockItemName, nil)
+	rowLockWaits.datas = newTestPrometheusDatas(0.5, 3, 3, 7)
+	ili := &InnoDBLockItem{
+		rowLockWaits:       rowLockWaits,
+		latestDeadlock:     parseLatestDeadlock(testInnoDBStatus),
+		blockingStatements: []*BlockingStatement{{BlockingPID: 1, BlockingQuery: "update t01 set col1 = 1", BlockedCount: 1}},
+	}
+	_, err := ili.Score(newTestInnoDBLockConfig())
+	asst.Nil(err, common.CombineMessageWithError("test Advice() failed", err))
+	data, high, advice, err := ili.Advice()
+	asst.Nil(err, common.CombineMessageWithError("test Advice() failed", err))
+	asst.Contains(data, "WE ROLL BACK TRANSACTION", "test Advice() failed")
+	asst.Equal(`[{"timestamp":"3","value":7}]`, high, "test Advice() failed")
+	asst.Contains(advice, `"blocking_pid":1`, "test Advice() failed")
+	asst.Contains(advice, `"deadlock":null`, "test Advice() failed")
+}
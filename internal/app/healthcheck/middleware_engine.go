@@ -0,0 +1,217 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+var _ healthcheck.Engine = (*MiddlewareEngine)(nil)
+
+// middlewareTarget is a middleware server and the repository which probes it
+type middlewareTarget struct {
+	server depmeta.MiddlewareServer
+	repo   healthcheck.MiddlewareProxyRepo
+}
+
+// MiddlewareEngine is the engine variant which checks the middleware servers of a middleware cluster,
+// it probes the connectivity through each middleware server, collects the backends from the admin interface,
+// maps the backends to the registered mysql servers and scores the routing health
+type MiddlewareEngine struct {
+	middlewareOperationID  int
+	dasRepo                healthcheck.DASRepo
+	targets                []*middlewareTarget
+	registeredMySQLServers map[string]depmeta.MySQLServer
+	mysqlClusterIDs        map[int]bool
+	serverStatuses         map[string]string
+	serverStatusesMutex    sync.RWMutex
+}
+
+// NewMiddlewareEngine returns a new *MiddlewareEngine,
+// proxy repos must be in the same order as the middleware servers,
+// mysql servers are all the registered mysql servers, mysql cluster ids are the mysql clusters which are behind the middleware cluster
+func NewMiddlewareEngine(middlewareOperationID int, dasRepo healthcheck.DASRepo, middlewareServers []depmeta.MiddlewareServer,
+	proxyRepos []healthcheck.MiddlewareProxyRepo, mysqlServers []depmeta.MySQLServer, mysqlClusterIDs []int) *MiddlewareEngine {
+	me := &MiddlewareEngine{
+		middlewareOperationID:  middlewareOperationID,
+		dasRepo:                dasRepo,
+		registeredMySQLServers: make(map[string]depmeta.MySQLServer, len(mysqlServers)),
+		mysqlClusterIDs:        make(map[int]bool, len(mysqlClusterIDs)),
+		serverStatuses:         make(map[string]string, len(middlewareServers)),
+	}
+	for i, middlewareServer := range middlewareServers {
+		me.targets = append(me.targets, &middlewareTarget{server: middlewareServer, repo: proxyRepos[i]})
+	}
+	for _, mysqlServer := range mysqlServers {
+		me.registeredMySQLServers[fmt.Sprintf("%s:%d", mysqlServer.GetHostIP(), mysqlServer.GetPortNum())] = mysqlServer
+	}
+	for _, mysqlClusterID := range mysqlClusterIDs {
+		me.mysqlClusterIDs[mysqlClusterID] = true
+	}
+
+	return me
+}
+
+// GetItemStatuses returns a copy of the progress of each middleware server, the key is the middleware server address
+func (me *MiddlewareEngine) GetItemStatuses() map[string]string {
+	me.serverStatusesMutex.RLock()
+	defer me.serverStatusesMutex.RUnlock()
+
+	serverStatuses := make(map[string]string, len(me.serverStatuses))
+	for addr, status := range me.serverStatuses {
+		serverStatuses[addr] = status
+	}
+
+	return serverStatuses
+}
+
+// setServerStatus sets the progress of the middleware server
+func (me *MiddlewareEngine) setServerStatus(addr, status string) {
+	me.serverStatusesMutex.Lock()
+	defer me.serverStatusesMutex.Unlock()
+
+	me.serverStatuses[addr] = status
+}
+
+// Run checks the middleware servers, saves the middleware result and updates the middleware operation status
+func (me *MiddlewareEngine) Run(ctx context.Context) {
+	status := defaultSuccessStatus
+	msg := fmt.Sprintf("middleware healthcheck completed successfully. middleware_operation_id: %d", me.middlewareOperationID)
+
+	middlewareResult, err := me.run(ctx)
+	if err == nil {
+		err = me.dasRepo.SaveMiddlewareResult(middlewareResult)
+	}
+	if err != nil {
+		status = defaultFailedStatus
+		if ctx.Err() != nil {
+			status = defaultCanceledStatus
+		}
+		msg = err.Error()
+		log.Error(message.NewMessage(msghc.ErrHealthcheckSummarizeMiddleware, me.middlewareOperationID, err.Error()).Error())
+	}
+	// update middleware operation status
+	updateErr := me.dasRepo.UpdateMiddlewareOperationStatus(me.middlewareOperationID, status, msg)
+	if updateErr != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
+	}
+}
+
+// run probes the middleware servers and summarizes the middleware result
+func (me *MiddlewareEngine) run(ctx context.Context) (*MiddlewareResult, error) {
+	serverResults := me.probe(ctx)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return NewMiddlewareResultWithServerResults(me.middlewareOperationID, serverResults, me.getMissingBackends(serverResults))
+}
+
+// probe probes the middleware servers concurrently, the failure of a middleware server is recorded in its result
+func (me *MiddlewareEngine) probe(ctx context.Context) []*MiddlewareServerResult {
+	serverResults := make([]*MiddlewareServerResult, len(me.targets))
+	for i, target := range me.targets {
+		serverResults[i] = NewMiddlewareServerResult(target.server.Identity(), target.server.GetServerName(),
+			target.server.GetMiddlewareRole(), target.server.GetHostIP(), target.server.GetPortNum())
+		me.setServerStatus(serverResults[i].GetAddr(), defaultItemStatusPending)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(len(me.targets))
+	for i, target := range me.targets {
+		go func(target *middlewareTarget, serverResult *MiddlewareServerResult) {
+			defer wg.Done()
+
+			if ctx.Err() != nil {
+				me.setServerStatus(serverResult.GetAddr(), defaultItemStatusCanceled)
+				return
+			}
+			me.setServerStatus(serverResult.GetAddr(), defaultItemStatusRunning)
+			me.probeServer(target, serverResult)
+			me.setServerStatus(serverResult.GetAddr(), defaultItemStatusCompleted)
+		}(target, serverResults[i])
+	}
+	wg.Wait()
+
+	return serverResults
+}
+
+// probeServer probes the connectivity through the middleware server and collects its backends,
+// the admin interface is considered as not available if the backends could not be collected
+func (me *MiddlewareEngine) probeServer(target *middlewareTarget, serverResult *MiddlewareServerResult) {
+	latency, err := target.repo.Ping()
+	if err != nil {
+		serverResult.setError(err)
+	} else {
+		serverResult.setConnected(latency)
+	}
+
+	if !target.repo.IsAdminAvailable() {
+		return
+	}
+	backends, err := target.repo.GetBackends()
+	if err != nil {
+		serverResult.setError(err)
+		return
+	}
+	serverResult.AdminAvailable = true
+	for _, backend := range backends {
+		serverResult.Backends = append(serverResult.Backends, me.mapBackend(backend))
+	}
+}
+
+// mapBackend maps the backend to the registered mysql server,
+// the backend is mapped if the mysql server belongs to the mysql clusters which are behind the middleware cluster,
+// it is foreign if the mysql server belongs to other mysql clusters, otherwise it is unregistered
+func (me *MiddlewareEngine) mapBackend(backend healthcheck.MiddlewareBackend) *MiddlewareBackendResult {
+	backendResult := NewMiddlewareBackendResult(backend)
+	mysqlServer, exists := me.registeredMySQLServers[backendResult.GetAddr()]
+	if !exists {
+		return backendResult
+	}
+
+	backendResult.MySQLServerID = mysqlServer.Identity()
+	backendResult.MySQLClusterID = mysqlServer.GetClusterID()
+	backendResult.Mapping = middlewareBackendForeign
+	if me.mysqlClusterIDs[mysqlServer.GetClusterID()] {
+		backendResult.Mapping = middlewareBackendMapped
+	}
+
+	return backendResult
+}
+
+// getMissingBackends returns the addresses of the mysql servers which are behind the middleware cluster
+// but are not configured as a backend of any middleware server,
+// it returns nil if none of the admin interfaces is available, as the backends are unknown
+func (me *MiddlewareEngine) getMissingBackends(serverResults []*MiddlewareServerResult) []string {
+	backendAddrs := make(map[string]bool)
+	adminAvailable := false
+	for _, serverResult := range serverResults {
+		if !serverResult.AdminAvailable {
+			continue
+		}
+		adminAvailable = true
+		for _, backend := range serverResult.Backends {
+			backendAddrs[backend.GetAddr()] = true
+		}
+	}
+	if !adminAvailable {
+		return nil
+	}
+
+	missingBackends := make([]string, constant.ZeroInt)
+	for addr, mysqlServer := range me.registeredMySQLServers {
+		if me.mysqlClusterIDs[mysqlServer.GetClusterID()] && !backendAddrs[addr] {
+			missingBackends = append(missingBackends, addr)
+		}
+	}
+
+	return missingBackends
+}
@@ -0,0 +1,120 @@
+package healthcheck
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// testMiddlewareProxyRepo is the middleware proxy repository which returns the prepared data
+type testMiddlewareProxyRepo struct {
+	pingErr        error
+	adminAvailable bool
+	backends       []healthcheck.MiddlewareBackend
+}
+
+func (tmpr *testMiddlewareProxyRepo) Ping() (time.Duration, error) {
+	if tmpr.pingErr != nil {
+		return 0, tmpr.pingErr
+	}
+
+	return time.Millisecond, nil
+}
+
+func (tmpr *testMiddlewareProxyRepo) IsAdminAvailable() bool {
+	return tmpr.adminAvailable
+}
+
+func (tmpr *testMiddlewareProxyRepo) GetBackends() ([]healthcheck.MiddlewareBackend, error) {
+	return tmpr.backends, nil
+}
+
+func newTestMiddlewareEngine() *MiddlewareEngine {
+	middlewareServers := []depmeta.MiddlewareServer{
+		&metadata.MiddlewareServerInfo{ID: 1, ClusterID: 1, ServerName: "proxy01", MiddlewareRole: 1, HostIP: "192.168.10.101", PortNum: 6033},
+		&metadata.MiddlewareServerInfo{ID: 2, ClusterID: 1, ServerName: "proxy02", MiddlewareRole: 2, HostIP: "192.168.10.102", PortNum: 6033},
+	}
+	proxyRepos := []healthcheck.MiddlewareProxyRepo{
+		&testMiddlewareProxyRepo{
+			adminAvailable: true,
+			backends: []healthcheck.MiddlewareBackend{
+				&MiddlewareBackend{HostGroupID: 10, HostIP: "192.168.10.1", PortNum: 3306, Status: middlewareBackendStatusOnline, ConnOK: 90, ConnErr: 10},
+				&MiddlewareBackend{HostGroupID: 20, HostIP: "192.168.10.3", PortNum: 3306, Status: middlewareBackendStatusOnline},
+				&MiddlewareBackend{HostGroupID: 20, HostIP: "192.168.10.4", PortNum: 3306, Status: "SHUNNED"},
+			},
+		},
+		&testMiddlewareProxyRepo{pingErr: errors.New("connection refused")},
+	}
+	mysqlServers := []depmeta.MySQLServer{
+		&metadata.MySQLServerInfo{ID: 1, ClusterID: 1, HostIP: "192.168.10.1", PortNum: 3306},
+		&metadata.MySQLServerInfo{ID: 2, ClusterID: 1, HostIP: "192.168.10.2", PortNum: 3306},
+		&metadata.MySQLServerInfo{ID: 3, ClusterID: 2, HostIP: "192.168.10.3", PortNum: 3306},
+	}
+
+	return NewMiddlewareEngine(1, nil, middlewareServers, proxyRepos, mysqlServers, []int{1})
+}
+
+func TestMiddlewareEngineAll(t *testing.T) {
+	TestMiddlewareEngine_probe(t)
+	TestMiddlewareEngine_run(t)
+}
+
+func TestMiddlewareEngine_probe(t *testing.T) {
+	asst := assert.New(t)
+
+	me := newTestMiddlewareEngine()
+	serverResults := me.probe(context.Background())
+	asst.Equal(2, len(serverResults), "test probe() failed")
+	// the first middleware server is connected and the backends are mapped
+	asst.True(serverResults[0].Connected, "test probe() failed")
+	asst.True(serverResults[0].AdminAvailable, "test probe() failed")
+	asst.Equal(3, len(serverResults[0].Backends), "test probe() failed")
+	asst.Equal(middlewareBackendMapped, serverResults[0].Backends[0].Mapping, "test probe() failed")
+	asst.Equal(1, serverResults[0].Backends[0].MySQLServerID, "test probe() failed")
+	asst.Equal(middlewareBackendForeign, serverResults[0].Backends[1].Mapping, "test probe() failed")
+	asst.Equal(2, serverResults[0].Backends[1].MySQLClusterID, "test probe() failed")
+	asst.Equal(middlewareBackendUnregistered, serverResults[0].Backends[2].Mapping, "test probe() failed")
+	// the second middleware server could not be connected
+	asst.False(serverResults[1].Connected, "test probe() failed")
+	asst.False(serverResults[1].AdminAvailable, "test probe() failed")
+	asst.Equal("connection refused", serverResults[1].Message, "test probe() failed")
+	// the progress of all the middleware servers are completed
+	for addr, status := range me.GetItemStatuses() {
+		asst.Equal(defaultItemStatusCompleted, status, "test probe() failed, addr: %s", addr)
+	}
+	// the mysql server of the mysql cluster which is not routed by any middleware server is missing
+	asst.Equal([]string{"192.168.10.2:3306"}, me.getMissingBackends(serverResults), "test getMissingBackends() failed")
+	asst.Nil(me.getMissingBackends(serverResults[1:]), "test getMissingBackends() failed")
+}
+
+func TestMiddlewareEngine_run(t *testing.T) {
+	asst := assert.New(t)
+
+	me := newTestMiddlewareEngine()
+	mr, err := me.run(context.Background())
+	asst.Nil(err, common.CombineMessageWithError("test run() failed", err))
+	asst.Equal(50, mr.GetConnectivityScore(), "test run() failed")
+	asst.Equal(33, mr.GetBackendScore(), "test run() failed")
+	asst.Equal(90, mr.GetRoutingScore(), "test run() failed")
+	asst.Equal(56, mr.GetWeightedAverageScore(), "test run() failed")
+	asst.Equal(`["192.168.10.2:3306"]`, mr.GetMissingBackends(), "test run() failed")
+
+	var serverResults []*MiddlewareServerResult
+	err = json.Unmarshal([]byte(mr.GetServerResults()), &serverResults)
+	asst.Nil(err, common.CombineMessageWithError("test run() failed", err))
+	asst.Equal(90, serverResults[0].RoutingScore, "test run() failed")
+
+	// the canceled operation is not summarized
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = newTestMiddlewareEngine().run(ctx)
+	asst.NotNil(err, "test run() failed")
+}
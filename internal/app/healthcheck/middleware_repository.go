@@ -0,0 +1,97 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/log"
+)
+
+var _ healthcheck.MiddlewareProxyRepo = (*MiddlewareProxyRepo)(nil)
+
+// MiddlewareProxyRepo is the repository of a middleware server which speaks the mysql protocol,
+// the connection pool and backend status are collected from the admin interface which is compatible with proxysql
+type MiddlewareProxyRepo struct {
+	addr      string
+	user      string
+	pass      string
+	adminAddr string
+	adminUser string
+	adminPass string
+}
+
+// NewMiddlewareProxyRepo returns a new *MiddlewareProxyRepo,
+// the admin interface is considered as not available if the admin address is empty
+func NewMiddlewareProxyRepo(addr, user, pass, adminAddr, adminUser, adminPass string) *MiddlewareProxyRepo {
+	return &MiddlewareProxyRepo{
+		addr:      addr,
+		user:      user,
+		pass:      pass,
+		adminAddr: adminAddr,
+		adminUser: adminUser,
+		adminPass: adminPass,
+	}
+}
+
+// Ping connects to the middleware server and executes a simple query through it, it returns the round trip latency
+func (mpr *MiddlewareProxyRepo) Ping() (time.Duration, error) {
+	startTime := time.Now()
+	conn, err := mysql.NewConn(mpr.addr, constant.EmptyString, mpr.user, mpr.pass)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	defer mpr.close(conn)
+
+	_, err = conn.Execute(middlewarePing)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return time.Since(startTime), nil
+}
+
+// IsAdminAvailable returns if the admin interface of the middleware server is available
+func (mpr *MiddlewareProxyRepo) IsAdminAvailable() bool {
+	return mpr.adminAddr != constant.EmptyString
+}
+
+// GetBackends gets the connection pool and status of the backends from the admin interface,
+// it returns nil if the admin interface is not available
+func (mpr *MiddlewareProxyRepo) GetBackends() ([]healthcheck.MiddlewareBackend, error) {
+	if !mpr.IsAdminAvailable() {
+		return nil, nil
+	}
+
+	conn, err := mysql.NewConn(mpr.adminAddr, constant.EmptyString, mpr.adminUser, mpr.adminPass)
+	if err != nil {
+		return nil, err
+	}
+	defer mpr.close(conn)
+
+	result, err := conn.Execute(middlewareBackends)
+	if err != nil {
+		return nil, err
+	}
+	backends := make([]healthcheck.MiddlewareBackend, result.RowNumber())
+	for i := range backends {
+		backends[i] = NewEmptyMiddlewareBackend()
+	}
+	err = result.MapToStructSlice(backends, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return backends, nil
+}
+
+// close closes the connection, the error is only logged
+func (mpr *MiddlewareProxyRepo) close(conn *mysql.Conn) {
+	err := conn.Close()
+	if err != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckCloseConnection, err.Error()).Error())
+	}
+}
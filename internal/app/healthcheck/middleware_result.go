@@ -0,0 +1,346 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	// the weights of the middleware scores, the sum of the weights is 100
+	middlewareConnectivityWeight = 40
+	middlewareBackendWeight      = 30
+	middlewareRoutingWeight      = 30
+	// the routing score of the middleware server is deducted by the connection error ratio, but at most by this value
+	middlewareMaxConnErrDeduction = 50.0
+
+	middlewareBackendStatusOnline = "ONLINE"
+
+	// the mapping of the backend to the registered mysql servers
+	middlewareBackendMapped       = "mapped"
+	middlewareBackendForeign      = "foreign"
+	middlewareBackendUnregistered = "unregistered"
+)
+
+var _ healthcheck.MiddlewareResult = (*MiddlewareResult)(nil)
+
+// MiddlewareResult is the healthcheck result of the middleware cluster
+type MiddlewareResult struct {
+	ID                    int       `middleware:"id" json:"id"`
+	MiddlewareOperationID int       `middleware:"middleware_operation_id" json:"middleware_operation_id"`
+	WeightedAverageScore  int       `middleware:"weighted_average_score" json:"weighted_average_score"`
+	ConnectivityScore     int       `middleware:"connectivity_score" json:"connectivity_score"`
+	BackendScore          int       `middleware:"backend_score" json:"backend_score"`
+	RoutingScore          int       `middleware:"routing_score" json:"routing_score"`
+	ServerResults         string    `middleware:"server_results" json:"server_results"`
+	MissingBackends       string    `middleware:"missing_backends" json:"missing_backends"`
+	DelFlag               int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime            time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime        time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewEmptyMiddlewareResult returns a new empty *MiddlewareResult
+func NewEmptyMiddlewareResult() *MiddlewareResult {
+	return &MiddlewareResult{}
+}
+
+// Identity returns the identity
+func (mr *MiddlewareResult) Identity() int {
+	return mr.ID
+}
+
+// GetMiddlewareOperationID returns the middleware operation id
+func (mr *MiddlewareResult) GetMiddlewareOperationID() int {
+	return mr.MiddlewareOperationID
+}
+
+// GetWeightedAverageScore returns the weighted average score of the middleware cluster
+func (mr *MiddlewareResult) GetWeightedAverageScore() int {
+	return mr.WeightedAverageScore
+}
+
+// GetConnectivityScore returns the score of the connectivity through the middleware servers
+func (mr *MiddlewareResult) GetConnectivityScore() int {
+	return mr.ConnectivityScore
+}
+
+// GetBackendScore returns the score of the backend status and mapping
+func (mr *MiddlewareResult) GetBackendScore() int {
+	return mr.BackendScore
+}
+
+// GetRoutingScore returns the score of the routing health
+func (mr *MiddlewareResult) GetRoutingScore() int {
+	return mr.RoutingScore
+}
+
+// GetServerResults returns the results of the middleware servers as a json string
+func (mr *MiddlewareResult) GetServerResults() string {
+	return mr.ServerResults
+}
+
+// GetMissingBackends returns the registered mysql servers which are not routed by any middleware server as a json string
+func (mr *MiddlewareResult) GetMissingBackends() string {
+	return mr.MissingBackends
+}
+
+// GetDelFlag returns the delete flag
+func (mr *MiddlewareResult) GetDelFlag() int {
+	return mr.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (mr *MiddlewareResult) GetCreateTime() time.Time {
+	return mr.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (mr *MiddlewareResult) GetLastUpdateTime() time.Time {
+	return mr.LastUpdateTime
+}
+
+// MarshalJSON marshals MiddlewareResult to json bytes
+func (mr *MiddlewareResult) MarshalJSON() ([]byte, error) {
+	return common.MarshalStructWithTag(mr, constant.DefaultMarshalTag)
+}
+
+// MiddlewareServerResult is the healthcheck result of a middleware server which belongs to the middleware cluster
+type MiddlewareServerResult struct {
+	MiddlewareServerID int                        `json:"middleware_server_id"`
+	ServerName         string                     `json:"server_name"`
+	MiddlewareRole     int                        `json:"middleware_role"`
+	HostIP             string                     `json:"host_ip"`
+	PortNum            int                        `json:"port_num"`
+	Connected          bool                       `json:"connected"`
+	Latency            float64                    `json:"latency"`
+	AdminAvailable     bool                       `json:"admin_available"`
+	Backends           []*MiddlewareBackendResult `json:"backends"`
+	RoutingScore       int                        `json:"routing_score"`
+	Message            string                     `json:"message"`
+}
+
+// NewMiddlewareServerResult returns a new *MiddlewareServerResult
+func NewMiddlewareServerResult(middlewareServerID int, serverName string, middlewareRole int, hostIP string, portNum int) *MiddlewareServerResult {
+	return &MiddlewareServerResult{
+		MiddlewareServerID: middlewareServerID,
+		ServerName:         serverName,
+		MiddlewareRole:     middlewareRole,
+		HostIP:             hostIP,
+		PortNum:            portNum,
+		Backends:           []*MiddlewareBackendResult{},
+	}
+}
+
+// GetAddr returns the address of the middleware server, format: host_ip:port_num
+func (msr *MiddlewareServerResult) GetAddr() string {
+	return fmt.Sprintf("%s:%d", msr.HostIP, msr.PortNum)
+}
+
+// setConnected marks the middleware server as connected with given round trip latency
+func (msr *MiddlewareServerResult) setConnected(latency time.Duration) {
+	msr.Connected = true
+	msr.Latency = float64(latency) / float64(time.Millisecond)
+}
+
+// setError appends the error to the message of the middleware server
+func (msr *MiddlewareServerResult) setError(err error) {
+	if msr.Message != constant.EmptyString {
+		msr.Message += constant.CRLFString
+	}
+	msr.Message += err.Error()
+}
+
+// getRoutingScore scores the routing health of the middleware server,
+// the middleware server which does not route to any online backend gets the minimum score,
+// otherwise the score is deducted by the connection error ratio of the backends
+func (msr *MiddlewareServerResult) getRoutingScore() int {
+	var connOK, connErr int
+	hasOnline := false
+	for _, backend := range msr.Backends {
+		if backend.IsOnline() {
+			hasOnline = true
+		}
+		connOK += backend.ConnOK
+		connErr += backend.ConnErr
+	}
+	if !hasOnline {
+		return defaultMinScore
+	}
+	if connOK+connErr == constant.ZeroInt {
+		return defaultMaxScore
+	}
+
+	deduction := math.Min(float64(connErr)/float64(connOK+connErr)*defaultMaxScore, middlewareMaxConnErrDeduction)
+
+	return int(defaultMaxScore - deduction)
+}
+
+// MiddlewareBackend is the connection pool and status of a backend which is collected from the admin interface of the middleware server
+type MiddlewareBackend struct {
+	HostGroupID int    `middleware:"hostgroup_id" json:"hostgroup_id"`
+	HostIP      string `middleware:"host_ip" json:"host_ip"`
+	PortNum     int    `middleware:"port_num" json:"port_num"`
+	Status      string `middleware:"status" json:"status"`
+	ConnUsed    int    `middleware:"conn_used" json:"conn_used"`
+	ConnFree    int    `middleware:"conn_free" json:"conn_free"`
+	ConnOK      int    `middleware:"conn_ok" json:"conn_ok"`
+	ConnErr     int    `middleware:"conn_err" json:"conn_err"`
+}
+
+// NewEmptyMiddlewareBackend returns a new empty *MiddlewareBackend
+func NewEmptyMiddlewareBackend() *MiddlewareBackend {
+	return &MiddlewareBackend{}
+}
+
+// GetHostGroupID returns the host group id which the backend belongs to
+func (mb *MiddlewareBackend) GetHostGroupID() int {
+	return mb.HostGroupID
+}
+
+// GetHostIP returns the host ip of the backend
+func (mb *MiddlewareBackend) GetHostIP() string {
+	return mb.HostIP
+}
+
+// GetPortNum returns the port number of the backend
+func (mb *MiddlewareBackend) GetPortNum() int {
+	return mb.PortNum
+}
+
+// GetStatus returns the status of the backend
+func (mb *MiddlewareBackend) GetStatus() string {
+	return mb.Status
+}
+
+// GetConnUsed returns the number of the connections which are in use
+func (mb *MiddlewareBackend) GetConnUsed() int {
+	return mb.ConnUsed
+}
+
+// GetConnFree returns the number of the idle connections in the connection pool
+func (mb *MiddlewareBackend) GetConnFree() int {
+	return mb.ConnFree
+}
+
+// GetConnOK returns the number of the connections which were established successfully
+func (mb *MiddlewareBackend) GetConnOK() int {
+	return mb.ConnOK
+}
+
+// GetConnErr returns the number of the connections which were failed to establish
+func (mb *MiddlewareBackend) GetConnErr() int {
+	return mb.ConnErr
+}
+
+// MiddlewareBackendResult is a backend of the middleware server and the registered mysql server which it maps to
+type MiddlewareBackendResult struct {
+	HostGroupID    int    `json:"hostgroup_id"`
+	HostIP         string `json:"host_ip"`
+	PortNum        int    `json:"port_num"`
+	Status         string `json:"status"`
+	ConnUsed       int    `json:"conn_used"`
+	ConnFree       int    `json:"conn_free"`
+	ConnOK         int    `json:"conn_ok"`
+	ConnErr        int    `json:"conn_err"`
+	MySQLServerID  int    `json:"mysql_server_id"`
+	MySQLClusterID int    `json:"mysql_cluster_id"`
+	Mapping        string `json:"mapping"`
+}
+
+// NewMiddlewareBackendResult returns a new *MiddlewareBackendResult with given backend, the backend is not mapped yet
+func NewMiddlewareBackendResult(backend healthcheck.MiddlewareBackend) *MiddlewareBackendResult {
+	return &MiddlewareBackendResult{
+		HostGroupID: backend.GetHostGroupID(),
+		HostIP:      backend.GetHostIP(),
+		PortNum:     backend.GetPortNum(),
+		Status:      backend.GetStatus(),
+		ConnUsed:    backend.GetConnUsed(),
+		ConnFree:    backend.GetConnFree(),
+		ConnOK:      backend.GetConnOK(),
+		ConnErr:     backend.GetConnErr(),
+		Mapping:     middlewareBackendUnregistered,
+	}
+}
+
+// GetAddr returns the address of the backend, format: host_ip:port_num
+func (mbr *MiddlewareBackendResult) GetAddr() string {
+	return fmt.Sprintf("%s:%d", mbr.HostIP, mbr.PortNum)
+}
+
+// IsOnline returns if the backend is online
+func (mbr *MiddlewareBackendResult) IsOnline() bool {
+	return mbr.Status == middlewareBackendStatusOnline
+}
+
+// IsHealthy returns if the backend is online and maps to a registered mysql server of the middleware cluster
+func (mbr *MiddlewareBackendResult) IsHealthy() bool {
+	return mbr.IsOnline() && mbr.Mapping == middlewareBackendMapped
+}
+
+// NewMiddlewareResultWithServerResults summarizes the results of the middleware servers to a new *MiddlewareResult,
+// the connectivity score is the percentage of the middleware servers which could be connected through,
+// the backend score is the percentage of the backends which are online and map to the registered mysql servers of the middleware cluster,
+// the routing score is the average routing score of the middleware servers whose admin interface is available,
+// the backend score and the routing score are the maximum score if none of the admin interfaces is available
+func NewMiddlewareResultWithServerResults(middlewareOperationID int, serverResults []*MiddlewareServerResult, missingBackends []string) (*MiddlewareResult, error) {
+	mr := &MiddlewareResult{
+		MiddlewareOperationID: middlewareOperationID,
+		ConnectivityScore:     defaultMinScore,
+		BackendScore:          defaultMaxScore,
+		RoutingScore:          defaultMaxScore,
+	}
+
+	var connectedNum, backendNum, healthyNum, adminNum, routingScoreSum int
+	for _, serverResult := range serverResults {
+		if serverResult.Connected {
+			connectedNum++
+		}
+		if !serverResult.AdminAvailable {
+			continue
+		}
+		adminNum++
+		for _, backend := range serverResult.Backends {
+			backendNum++
+			if backend.IsHealthy() {
+				healthyNum++
+			}
+		}
+		serverResult.RoutingScore = serverResult.getRoutingScore()
+		routingScoreSum += serverResult.RoutingScore
+	}
+	if len(serverResults) > constant.ZeroInt {
+		mr.ConnectivityScore = connectedNum * defaultHundred / len(serverResults)
+	}
+	if backendNum > constant.ZeroInt {
+		mr.BackendScore = healthyNum * defaultHundred / backendNum
+	}
+	if adminNum > constant.ZeroInt {
+		mr.RoutingScore = routingScoreSum / adminNum
+	}
+	mr.WeightedAverageScore = (mr.ConnectivityScore*middlewareConnectivityWeight +
+		mr.BackendScore*middlewareBackendWeight + mr.RoutingScore*middlewareRoutingWeight) / defaultHundred
+
+	jsonBytes, err := json.Marshal(serverResults)
+	if err != nil {
+		return nil, err
+	}
+	mr.ServerResults = string(jsonBytes)
+
+	if missingBackends == nil {
+		missingBackends = []string{}
+	}
+	sort.Strings(missingBackends)
+	jsonBytes, err = json.Marshal(missingBackends)
+	if err != nil {
+		return nil, err
+	}
+	mr.MissingBackends = string(jsonBytes)
+
+	return mr, nil
+}
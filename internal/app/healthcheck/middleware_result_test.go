@@ -0,0 +1,51 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareResultAll(t *testing.T) {
+	TestMiddlewareResult_NewMiddlewareResultWithServerResults(t)
+	TestMiddlewareResult_getRoutingScore(t)
+}
+
+func TestMiddlewareResult_NewMiddlewareResultWithServerResults(t *testing.T) {
+	asst := assert.New(t)
+
+	// none of the admin interfaces is available, only the connectivity is scored
+	connected := NewMiddlewareServerResult(1, "proxy01", 1, "192.168.10.101", 6033)
+	connected.Connected = true
+	serverResults := []*MiddlewareServerResult{connected, NewMiddlewareServerResult(2, "proxy02", 2, "192.168.10.102", 6033)}
+	mr, err := NewMiddlewareResultWithServerResults(1, serverResults, nil)
+	asst.Nil(err, common.CombineMessageWithError("test NewMiddlewareResultWithServerResults() failed", err))
+	asst.Equal(50, mr.GetConnectivityScore(), "test NewMiddlewareResultWithServerResults() failed")
+	asst.Equal(defaultHundred, mr.GetBackendScore(), "test NewMiddlewareResultWithServerResults() failed")
+	asst.Equal(defaultHundred, mr.GetRoutingScore(), "test NewMiddlewareResultWithServerResults() failed")
+	asst.Equal(80, mr.GetWeightedAverageScore(), "test NewMiddlewareResultWithServerResults() failed")
+	asst.Equal("[]", mr.GetMissingBackends(), "test NewMiddlewareResultWithServerResults() failed")
+	// empty middleware cluster
+	mr, err = NewMiddlewareResultWithServerResults(1, nil, nil)
+	asst.Nil(err, common.CombineMessageWithError("test NewMiddlewareResultWithServerResults() failed", err))
+	asst.Equal(defaultMinScore, mr.GetConnectivityScore(), "test NewMiddlewareResultWithServerResults() failed")
+}
+
+func TestMiddlewareResult_getRoutingScore(t *testing.T) {
+	asst := assert.New(t)
+
+	serverResult := NewMiddlewareServerResult(1, "proxy01", 1, "192.168.10.101", 6033)
+	// no online backend
+	serverResult.Backends = []*MiddlewareBackendResult{
+		{HostIP: "192.168.10.1", PortNum: 3306, Status: "OFFLINE_HARD", ConnOK: 100},
+	}
+	asst.Equal(defaultMinScore, serverResult.getRoutingScore(), "test getRoutingScore() failed")
+	// no connection has been established yet
+	serverResult.Backends[0].Status = middlewareBackendStatusOnline
+	serverResult.Backends[0].ConnOK = 0
+	asst.Equal(defaultHundred, serverResult.getRoutingScore(), "test getRoutingScore() failed")
+	// the deduction of the connection errors is limited
+	serverResult.Backends[0].ConnErr = 100
+	asst.Equal(50, serverResult.getRoutingScore(), "test getRoutingScore() failed")
+}
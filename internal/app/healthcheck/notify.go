@@ -0,0 +1,308 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	NotifyTriggerTypeFailure    = 1
+	NotifyTriggerTypeScoreBelow = 2
+	NotifyTriggerTypeRegression = 3
+
+	NotifySinkTypeWebhook = 1
+	NotifySinkTypeEmail   = 2
+	NotifySinkTypeFile    = 3
+
+	NotifyLogStatusSucceeded = 1
+	NotifyLogStatusFailed    = 2
+
+	notifyWebhookSchemeHTTP  = "http"
+	notifyWebhookSchemeHTTPS = "https"
+)
+
+var (
+	_ healthcheck.NotifyRule   = (*NotifyRule)(nil)
+	_ healthcheck.NotifyLog    = (*NotifyLog)(nil)
+	_ healthcheck.Notification = (*Notification)(nil)
+)
+
+// NotifyRule decides when and where the outcome of the healthcheck operation is notified,
+// the rule fires on the failure, on the score below the threshold, or on the score regression from the previous run
+type NotifyRule struct {
+	ID             int       `middleware:"id" json:"id"`
+	RuleName       string    `middleware:"rule_name" json:"rule_name"`
+	MySQLClusterID int       `middleware:"mysql_cluster_id" json:"mysql_cluster_id"`
+	TriggerType    int       `middleware:"trigger_type" json:"trigger_type"`
+	Threshold      int       `middleware:"threshold" json:"threshold"`
+	SinkType       int       `middleware:"sink_type" json:"sink_type"`
+	Target         string    `middleware:"target" json:"target"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewNotifyRule returns a new *NotifyRule, it validates the arguments,
+// the email rule with empty target will notify the owner of the mysql cluster
+func NewNotifyRule(ruleName string, mysqlClusterID, triggerType, threshold, sinkType int, target string) (*NotifyRule, error) {
+	// the rule name is a part of the email subject
+	if hasLineBreak(ruleName) {
+		return nil, message.NewMessage(msghc.ErrNotifyRuleNameInvalid, ruleName)
+	}
+
+	switch triggerType {
+	case NotifyTriggerTypeFailure:
+	case NotifyTriggerTypeScoreBelow, NotifyTriggerTypeRegression:
+		if threshold <= constant.ZeroInt || threshold > defaultHundred {
+			return nil, message.NewMessage(msghc.ErrNotifyRuleThresholdInvalid, threshold)
+		}
+	default:
+		return nil, message.NewMessage(msghc.ErrNotifyRuleTriggerTypeInvalid, triggerType)
+	}
+
+	switch sinkType {
+	case NotifySinkTypeWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != notifyWebhookSchemeHTTP && u.Scheme != notifyWebhookSchemeHTTPS) || u.Host == constant.EmptyString {
+			return nil, message.NewMessage(msghc.ErrNotifyRuleTargetInvalid, target)
+		}
+	case NotifySinkTypeEmail:
+		// empty target notifies the owner of the mysql cluster
+		if target != constant.EmptyString && !isValidNotifyEmailTarget(target) {
+			return nil, message.NewMessage(msghc.ErrNotifyRuleTargetInvalid, target)
+		}
+	case NotifySinkTypeFile:
+		if !isValidNotifyFileTarget(target) {
+			return nil, message.NewMessage(msghc.ErrNotifyRuleTargetInvalid, target)
+		}
+	default:
+		return nil, message.NewMessage(msghc.ErrNotifyRuleSinkTypeInvalid, sinkType)
+	}
+
+	return &NotifyRule{
+		RuleName:       ruleName,
+		MySQLClusterID: mysqlClusterID,
+		TriggerType:    triggerType,
+		Threshold:      threshold,
+		SinkType:       sinkType,
+		Target:         target,
+	}, nil
+}
+
+// NewEmptyNotifyRule returns a new empty *NotifyRule
+func NewEmptyNotifyRule() *NotifyRule {
+	return &NotifyRule{}
+}
+
+// Identity returns the identity
+func (nr *NotifyRule) Identity() int {
+	return nr.ID
+}
+
+// GetRuleName returns the rule name
+func (nr *NotifyRule) GetRuleName() string {
+	return nr.RuleName
+}
+
+// GetMySQLClusterID returns the mysql cluster id, 0 means the rule applies to all the mysql clusters
+func (nr *NotifyRule) GetMySQLClusterID() int {
+	return nr.MySQLClusterID
+}
+
+// GetTriggerType returns the trigger type
+func (nr *NotifyRule) GetTriggerType() int {
+	return nr.TriggerType
+}
+
+// GetThreshold returns the threshold
+func (nr *NotifyRule) GetThreshold() int {
+	return nr.Threshold
+}
+
+// GetSinkType returns the sink type
+func (nr *NotifyRule) GetSinkType() int {
+	return nr.SinkType
+}
+
+// GetTarget returns the target
+func (nr *NotifyRule) GetTarget() string {
+	return nr.Target
+}
+
+// GetDelFlag returns the delete flag
+func (nr *NotifyRule) GetDelFlag() int {
+	return nr.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (nr *NotifyRule) GetCreateTime() time.Time {
+	return nr.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (nr *NotifyRule) GetLastUpdateTime() time.Time {
+	return nr.LastUpdateTime
+}
+
+// NotifyLog is the delivery log of the notification
+type NotifyLog struct {
+	ID             int       `middleware:"id" json:"id"`
+	RuleID         int       `middleware:"rule_id" json:"rule_id"`
+	OperationID    int       `middleware:"operation_id" json:"operation_id"`
+	SinkType       int       `middleware:"sink_type" json:"sink_type"`
+	Target         string    `middleware:"target" json:"target"`
+	Status         int       `middleware:"status" json:"status"`
+	Attempts       int       `middleware:"attempts" json:"attempts"`
+	Message        string    `middleware:"message" json:"message"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewNotifyLog returns a new *NotifyLog
+func NewNotifyLog(ruleID, operationID, sinkType int, target string, status, attempts int, message string) *NotifyLog {
+	return &NotifyLog{
+		RuleID:      ruleID,
+		OperationID: operationID,
+		SinkType:    sinkType,
+		Target:      target,
+		Status:      status,
+		Attempts:    attempts,
+		Message:     message,
+	}
+}
+
+// NewEmptyNotifyLog returns a new empty *NotifyLog
+func NewEmptyNotifyLog() *NotifyLog {
+	return &NotifyLog{}
+}
+
+// Identity returns the identity
+func (nl *NotifyLog) Identity() int {
+	return nl.ID
+}
+
+// GetRuleID returns the rule id
+func (nl *NotifyLog) GetRuleID() int {
+	return nl.RuleID
+}
+
+// GetOperationID returns the operation id
+func (nl *NotifyLog) GetOperationID() int {
+	return nl.OperationID
+}
+
+// GetSinkType returns the sink type
+func (nl *NotifyLog) GetSinkType() int {
+	return nl.SinkType
+}
+
+// GetTarget returns the target which the notification was delivered to
+func (nl *NotifyLog) GetTarget() string {
+	return nl.Target
+}
+
+// GetStatus returns the delivery status
+func (nl *NotifyLog) GetStatus() int {
+	return nl.Status
+}
+
+// GetAttempts returns the number of the delivery attempts, including the retries
+func (nl *NotifyLog) GetAttempts() int {
+	return nl.Attempts
+}
+
+// GetMessage returns the error message of the last failed attempt
+func (nl *NotifyLog) GetMessage() string {
+	return nl.Message
+}
+
+// GetDelFlag returns the delete flag
+func (nl *NotifyLog) GetDelFlag() int {
+	return nl.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (nl *NotifyLog) GetCreateTime() time.Time {
+	return nl.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (nl *NotifyLog) GetLastUpdateTime() time.Time {
+	return nl.LastUpdateTime
+}
+
+// Notification is the outcome of the healthcheck operation which is sent to the sinks
+type Notification struct {
+	RuleID                       int    `json:"rule_id"`
+	RuleName                     string `json:"rule_name"`
+	TriggerType                  int    `json:"trigger_type"`
+	Reason                       string `json:"reason"`
+	OperationID                  int    `json:"operation_id"`
+	MySQLClusterID               int    `json:"mysql_cluster_id"`
+	MySQLServerID                int    `json:"mysql_server_id"`
+	HostIP                       string `json:"host_ip"`
+	PortNum                      int    `json:"port_num"`
+	Status                       int    `json:"status"`
+	Message                      string `json:"message"`
+	WeightedAverageScore         int    `json:"weighted_average_score"`
+	PreviousWeightedAverageScore int    `json:"previous_weighted_average_score"`
+	hasPrevious                  bool
+}
+
+// GetSubject returns the subject of the notification
+func (n *Notification) GetSubject() string {
+	return fmt.Sprintf("[das healthcheck] %s: %s:%d", n.RuleName, n.HostIP, n.PortNum)
+}
+
+// GetContent returns the human readable content of the notification
+func (n *Notification) GetContent() string {
+	return fmt.Sprintf("rule: %s\nreason: %s\noperation_id: %d\nmysql_cluster_id: %d\nmysql_server: %s:%d\nstatus: %d\nweighted_average_score: %d\nmessage: %s\n",
+		n.RuleName, n.Reason, n.OperationID, n.MySQLClusterID, n.HostIP, n.PortNum, n.Status, n.WeightedAverageScore, n.Message)
+}
+
+// Marshal marshals the notification to json bytes
+func (n *Notification) Marshal() ([]byte, error) {
+	return json.Marshal(n)
+}
+
+// match returns if the rule fires on the outcome of the operation, it returns a copy of the notification which is bound to the rule
+func (n *Notification) match(rule healthcheck.NotifyRule) (*Notification, bool) {
+	var reason string
+
+	switch rule.GetTriggerType() {
+	case NotifyTriggerTypeFailure:
+		if n.Status != defaultFailedStatus {
+			return nil, false
+		}
+		reason = "healthcheck failed"
+	case NotifyTriggerTypeScoreBelow:
+		if n.Status != defaultSuccessStatus || n.WeightedAverageScore >= rule.GetThreshold() {
+			return nil, false
+		}
+		reason = fmt.Sprintf("weighted average score %d is below the threshold %d", n.WeightedAverageScore, rule.GetThreshold())
+	case NotifyTriggerTypeRegression:
+		if n.Status != defaultSuccessStatus || !n.hasPrevious || n.PreviousWeightedAverageScore-n.WeightedAverageScore < rule.GetThreshold() {
+			return nil, false
+		}
+		reason = fmt.Sprintf("weighted average score dropped from %d to %d, which is not less than the threshold %d",
+			n.PreviousWeightedAverageScore, n.WeightedAverageScore, rule.GetThreshold())
+	default:
+		return nil, false
+	}
+
+	notification := *n
+	notification.RuleID = rule.Identity()
+	notification.RuleName = rule.GetRuleName()
+	notification.TriggerType = rule.GetTriggerType()
+	notification.Reason = reason
+
+	return &notification, true
+}
@@ -0,0 +1,264 @@
+package healthcheck
+
+import (
+	"fmt"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+)
+
+var _ healthcheck.NotifyRepo = (*NotifyRepo)(nil)
+
+// NotifyRepo is the repository of the notify rules and the notify logs
+type NotifyRepo struct {
+	Database middleware.Pool
+}
+
+// NewNotifyRepo returns *NotifyRepo with given middleware.Pool
+func NewNotifyRepo(db middleware.Pool) *NotifyRepo {
+	return &NotifyRepo{Database: db}
+}
+
+// NewNotifyRepoWithGlobal returns *NotifyRepo with global mysql pool
+func NewNotifyRepoWithGlobal() *NotifyRepo {
+	return NewNotifyRepo(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (nr *NotifyRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	conn, err := nr.Database.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("healthcheck NotifyRepo.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	return conn.Execute(command, args...)
+}
+
+// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+func (nr *NotifyRepo) Transaction() (middleware.Transaction, error) {
+	return nr.Database.Transaction()
+}
+
+// GetAll gets all the notify rules from the middleware
+func (nr *NotifyRepo) GetAll() ([]healthcheck.NotifyRule, error) {
+	sql := `
+		select id, rule_name, mysql_cluster_id, trigger_type, threshold, sink_type, target, del_flag, create_time, last_update_time
+		from t_hc_notify_rule
+		where del_flag = 0
+		order by id;
+	`
+	log.Debugf("healthCheck NotifyRepo.GetAll() select sql: \n%s", sql)
+
+	return nr.getRules(sql)
+}
+
+// GetByID gets the notify rule by the identity from the middleware
+func (nr *NotifyRepo) GetByID(id int) (healthcheck.NotifyRule, error) {
+	sql := `
+		select id, rule_name, mysql_cluster_id, trigger_type, threshold, sink_type, target, del_flag, create_time, last_update_time
+		from t_hc_notify_rule
+		where del_flag = 0
+		and id = ?;
+	`
+	log.Debugf("healthCheck NotifyRepo.GetByID() select sql: \n%s\nplaceholders: %s", sql, id)
+
+	rules, err := nr.getRules(sql, id)
+	if err != nil {
+		return nil, err
+	}
+	switch len(rules) {
+	case 0:
+		return nil, fmt.Errorf("healthCheck NotifyRepo.GetByID(): data does not exists, id: %d", id)
+	case 1:
+		return rules[constant.ZeroInt], nil
+	default:
+		return nil, fmt.Errorf("healthCheck NotifyRepo.GetByID(): duplicate key exists, id: %d", id)
+	}
+}
+
+// GetByMySQLClusterID gets the notify rules which apply to the mysql cluster from the middleware,
+// the rules whose mysql cluster id is 0 apply to all the mysql clusters
+func (nr *NotifyRepo) GetByMySQLClusterID(mysqlClusterID int) ([]healthcheck.NotifyRule, error) {
+	sql := `
+		select id, rule_name, mysql_cluster_id, trigger_type, threshold, sink_type, target, del_flag, create_time, last_update_time
+		from t_hc_notify_rule
+		where del_flag = 0
+		and mysql_cluster_id in (0, ?)
+		order by id;
+	`
+	log.Debugf("healthCheck NotifyRepo.GetByMySQLClusterID() select sql: \n%s\nplaceholders: %s", sql, mysqlClusterID)
+
+	return nr.getRules(sql, mysqlClusterID)
+}
+
+// Create creates a notify rule in the middleware
+func (nr *NotifyRepo) Create(rule healthcheck.NotifyRule) (healthcheck.NotifyRule, error) {
+	sql := `
+		insert into t_hc_notify_rule(rule_name, mysql_cluster_id, trigger_type, threshold, sink_type, target)
+		values(?, ?, ?, ?, ?, ?);
+	`
+	log.Debugf("healthCheck NotifyRepo.Create() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s",
+		sql, rule.GetRuleName(), rule.GetMySQLClusterID(), rule.GetTriggerType(), rule.GetThreshold(), rule.GetSinkType(), rule.GetTarget())
+
+	_, err := nr.Execute(sql, rule.GetRuleName(), rule.GetMySQLClusterID(), rule.GetTriggerType(), rule.GetThreshold(), rule.GetSinkType(), rule.GetTarget())
+	if err != nil {
+		return nil, err
+	}
+	// get id
+	sql = `select id from t_hc_notify_rule where del_flag = 0 and rule_name = ?;`
+	log.Debugf("healthCheck NotifyRepo.Create() select sql: \n%s\nplaceholders: %s", sql, rule.GetRuleName())
+	result, err := nr.Execute(sql, rule.GetRuleName())
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return nil, err
+	}
+	// get entity
+	return nr.GetByID(id)
+}
+
+// Delete deletes the notify rule in the middleware
+func (nr *NotifyRepo) Delete(id int) error {
+	sql := `delete from t_hc_notify_rule where id = ?;`
+	log.Debugf("healthCheck NotifyRepo.Delete() delete sql: \n%s\nplaceholders: %s", sql, id)
+	_, err := nr.Execute(sql, id)
+
+	return err
+}
+
+// GetOwnerEmail gets the email of the owner of the mysql cluster from the middleware, it returns empty string if there is no owner
+func (nr *NotifyRepo) GetOwnerEmail(mysqlClusterID int) (string, error) {
+	sql := `
+		select ifnull(max(ui.email), '')
+		from t_meta_mysql_cluster_info mci
+			inner join t_meta_user_info ui on mci.owner_id = ui.id
+		where mci.del_flag = 0
+		and ui.del_flag = 0
+		and mci.id = ?;
+	`
+	log.Debugf("healthCheck NotifyRepo.GetOwnerEmail() select sql: \n%s\nplaceholders: %s", sql, mysqlClusterID)
+
+	result, err := nr.Execute(sql, mysqlClusterID)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return result.GetString(constant.ZeroInt, constant.ZeroInt)
+}
+
+// GetPreviousScore gets the weighted average score of the last succeeded operation of the mysql server before given operation,
+// it returns false if there is no such operation
+func (nr *NotifyRepo) GetPreviousScore(mysqlServerID, operationID int) (int, bool, error) {
+	sql := `
+		select r.weighted_average_score
+		from t_hc_result r
+			inner join t_hc_operation_info oi on r.operation_id = oi.id
+		where r.del_flag = 0
+		and oi.del_flag = 0
+		and oi.mysql_server_id = ?
+		and oi.status = ?
+		and oi.id < ?
+		order by oi.id desc
+		limit 1;
+	`
+	log.Debugf("healthCheck NotifyRepo.GetPreviousScore() select sql: \n%s\nplaceholders: %s, %s, %s", sql, mysqlServerID, defaultSuccessStatus, operationID)
+
+	result, err := nr.Execute(sql, mysqlServerID, defaultSuccessStatus, operationID)
+	if err != nil {
+		return constant.ZeroInt, false, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return constant.ZeroInt, false, nil
+	}
+	score, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return constant.ZeroInt, false, err
+	}
+
+	return score, true, nil
+}
+
+// GetLogsByOperationID gets the notify logs of the operation from the middleware
+func (nr *NotifyRepo) GetLogsByOperationID(operationID int) ([]healthcheck.NotifyLog, error) {
+	sql := `
+		select id, rule_id, operation_id, sink_type, target, status, attempts, message, del_flag, create_time, last_update_time
+		from t_hc_notify_log
+		where del_flag = 0
+		and operation_id = ?
+		order by id;
+	`
+	log.Debugf("healthCheck NotifyRepo.GetLogsByOperationID() select sql: \n%s\nplaceholders: %s", sql, operationID)
+
+	result, err := nr.Execute(sql, operationID)
+	if err != nil {
+		return nil, err
+	}
+	// init []*NotifyLog
+	logList := make([]*NotifyLog, result.RowNumber())
+	for i := range logList {
+		logList[i] = NewEmptyNotifyLog()
+	}
+	// map to struct
+	err = result.MapToStructSlice(logList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.NotifyLog
+	logs := make([]healthcheck.NotifyLog, result.RowNumber())
+	for i := range logs {
+		logs[i] = logList[i]
+	}
+
+	return logs, nil
+}
+
+// SaveLog saves the notify log in the middleware
+func (nr *NotifyRepo) SaveLog(notifyLog healthcheck.NotifyLog) error {
+	sql := `
+		insert into t_hc_notify_log(rule_id, operation_id, sink_type, target, status, attempts, message)
+		values(?, ?, ?, ?, ?, ?, ?);
+	`
+	log.Debugf("healthCheck NotifyRepo.SaveLog() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s, %s",
+		sql, notifyLog.GetRuleID(), notifyLog.GetOperationID(), notifyLog.GetSinkType(), notifyLog.GetTarget(),
+		notifyLog.GetStatus(), notifyLog.GetAttempts(), notifyLog.GetMessage())
+	_, err := nr.Execute(sql, notifyLog.GetRuleID(), notifyLog.GetOperationID(), notifyLog.GetSinkType(), notifyLog.GetTarget(),
+		notifyLog.GetStatus(), notifyLog.GetAttempts(), notifyLog.GetMessage())
+
+	return err
+}
+
+// getRules executes given select sql and maps the result to the notify rules
+func (nr *NotifyRepo) getRules(sql string, args ...interface{}) ([]healthcheck.NotifyRule, error) {
+	result, err := nr.Execute(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	// init []*NotifyRule
+	ruleList := make([]*NotifyRule, result.RowNumber())
+	for i := range ruleList {
+		ruleList[i] = NewEmptyNotifyRule()
+	}
+	// map to struct
+	err = result.MapToStructSlice(ruleList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.NotifyRule
+	rules := make([]healthcheck.NotifyRule, result.RowNumber())
+	for i := range rules {
+		rules[i] = ruleList[i]
+	}
+
+	return rules, nil
+}
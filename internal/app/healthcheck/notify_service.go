@@ -0,0 +1,277 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+	"github.com/spf13/viper"
+)
+
+const (
+	notifyRulesStruct = "Rules"
+	notifyLogsStruct  = "Logs"
+)
+
+var _ healthcheck.NotifyService = (*NotifyService)(nil)
+
+// NotifyService of the healthcheck notify rules
+type NotifyService struct {
+	healthcheck.NotifyRepo
+	Rules []healthcheck.NotifyRule `json:"rules"`
+	Logs  []healthcheck.NotifyLog  `json:"logs"`
+}
+
+// NewNotifyService returns a new *NotifyService
+func NewNotifyService(repo healthcheck.NotifyRepo) *NotifyService {
+	return &NotifyService{repo, []healthcheck.NotifyRule{}, []healthcheck.NotifyLog{}}
+}
+
+// NewNotifyServiceWithDefault returns a new *NotifyService with default repository
+func NewNotifyServiceWithDefault() *NotifyService {
+	return NewNotifyService(NewNotifyRepoWithGlobal())
+}
+
+// GetRules returns the notify rules of the service
+func (ns *NotifyService) GetRules() []healthcheck.NotifyRule {
+	return ns.Rules
+}
+
+// GetLogs returns the notify logs of the service
+func (ns *NotifyService) GetLogs() []healthcheck.NotifyLog {
+	return ns.Logs
+}
+
+// GetAll gets all the notify rules from the middleware
+func (ns *NotifyService) GetAll() error {
+	var err error
+
+	ns.Rules, err = ns.NotifyRepo.GetAll()
+
+	return err
+}
+
+// Create creates a notify rule in the middleware
+func (ns *NotifyService) Create(ruleName string, mysqlClusterID, triggerType, threshold, sinkType int, target string) error {
+	rule, err := NewNotifyRule(ruleName, mysqlClusterID, triggerType, threshold, sinkType, target)
+	if err != nil {
+		return err
+	}
+	entity, err := ns.NotifyRepo.Create(rule)
+	if err != nil {
+		return err
+	}
+
+	ns.Rules = append(ns.Rules, entity)
+
+	return nil
+}
+
+// Delete deletes the notify rule
+func (ns *NotifyService) Delete(id int) error {
+	return ns.NotifyRepo.Delete(id)
+}
+
+// GetLogsByOperationID gets the notify logs of the operation from the middleware
+func (ns *NotifyService) GetLogsByOperationID(operationID int) error {
+	var err error
+
+	ns.Logs, err = ns.NotifyRepo.GetLogsByOperationID(operationID)
+
+	return err
+}
+
+// Marshal marshals NotifyService.Rules to json bytes
+func (ns *NotifyService) Marshal() ([]byte, error) {
+	return ns.MarshalWithFields(notifyRulesStruct)
+}
+
+// MarshalLogs marshals NotifyService.Logs to json bytes
+func (ns *NotifyService) MarshalLogs() ([]byte, error) {
+	return ns.MarshalWithFields(notifyLogsStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the NotifyService to json bytes
+func (ns *NotifyService) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(ns, fields...)
+}
+
+// Notifier notifies the outcome of the healthcheck operation to the sinks of the matched notify rules,
+// every delivery is retried on failure and logged in the middleware
+type Notifier struct {
+	notifyRepo    healthcheck.NotifyRepo
+	dasRepo       healthcheck.DASRepo
+	sinks         map[int]healthcheck.NotifySink
+	retryCount    int
+	retryInterval time.Duration
+}
+
+// NewNotifier returns a new *Notifier
+func NewNotifier(notifyRepo healthcheck.NotifyRepo, dasRepo healthcheck.DASRepo, sinks map[int]healthcheck.NotifySink,
+	retryCount int, retryInterval time.Duration) *Notifier {
+	return &Notifier{
+		notifyRepo:    notifyRepo,
+		dasRepo:       dasRepo,
+		sinks:         sinks,
+		retryCount:    retryCount,
+		retryInterval: retryInterval,
+	}
+}
+
+// NewNotifierWithDefault returns a new *Notifier with default repositories and the sinks of the configuration
+func NewNotifierWithDefault() *Notifier {
+	timeout := time.Duration(viper.GetInt(config.HealthcheckNotifyTimeoutKey)) * time.Second
+	if timeout <= constant.ZeroInt {
+		timeout = config.DefaultHealthcheckNotifyTimeout * time.Second
+	}
+	sinks := map[int]healthcheck.NotifySink{
+		NotifySinkTypeWebhook: NewWebhookSink(timeout),
+		NotifySinkTypeEmail: NewEmailSink(
+			viper.GetString(config.HealthcheckNotifySMTPAddrKey),
+			viper.GetString(config.HealthcheckNotifySMTPUserKey),
+			viper.GetString(config.HealthcheckNotifySMTPPassKey),
+			viper.GetString(config.HealthcheckNotifySMTPFromKey),
+			timeout,
+		),
+		NotifySinkTypeFile: NewFileSink(viper.GetString(config.HealthcheckNotifyFileDirKey)),
+	}
+
+	return NewNotifier(NewNotifyRepoWithGlobal(), NewDASRepoWithGlobal(), sinks,
+		viper.GetInt(config.HealthcheckNotifyRetryCountKey), time.Duration(viper.GetInt(config.HealthcheckNotifyRetryIntervalKey))*time.Second)
+}
+
+// Notify notifies the outcome of the operation, only the succeeded and failed operations are notified
+func (n *Notifier) Notify(operationID int) error {
+	operation, err := n.dasRepo.GetOperationByID(operationID)
+	if err != nil {
+		return err
+	}
+	if operation.GetStatus() != defaultSuccessStatus && operation.GetStatus() != defaultFailedStatus {
+		return nil
+	}
+	// get mysql server
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err = mysqlServerService.GetByID(operation.GetMySQLServerID())
+	if err != nil {
+		return err
+	}
+	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
+	// get rules
+	rules, err := n.notifyRepo.GetByMySQLClusterID(mysqlServer.GetClusterID())
+	if err != nil {
+		return err
+	}
+	if len(rules) == constant.ZeroInt {
+		return nil
+	}
+
+	notification := &Notification{
+		OperationID:    operationID,
+		MySQLClusterID: mysqlServer.GetClusterID(),
+		MySQLServerID:  mysqlServer.Identity(),
+		HostIP:         mysqlServer.GetHostIP(),
+		PortNum:        mysqlServer.GetPortNum(),
+		Status:         operation.GetStatus(),
+		Message:        operation.GetMessage(),
+	}
+	if operation.GetStatus() == defaultSuccessStatus {
+		// get scores
+		result, err := n.dasRepo.GetResultByOperationID(operationID)
+		if err != nil {
+			return err
+		}
+		notification.WeightedAverageScore = result.GetWeightedAverageScore()
+		notification.PreviousWeightedAverageScore, notification.hasPrevious, err = n.notifyRepo.GetPreviousScore(mysqlServer.Identity(), operationID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.dispatch(rules, notification)
+}
+
+// dispatch delivers the notification to the sinks of the matched rules, the failure of a rule does not stop the others
+func (n *Notifier) dispatch(rules []healthcheck.NotifyRule, notification *Notification) error {
+	merr := &multierror.Error{}
+
+	for _, rule := range rules {
+		ruleNotification, ok := notification.match(rule)
+		if !ok {
+			continue
+		}
+		err := n.deliver(rule, ruleNotification)
+		if err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+
+	return merr.ErrorOrNil()
+}
+
+// deliver delivers the notification to the sink of the rule with retries and saves the notify log
+func (n *Notifier) deliver(rule healthcheck.NotifyRule, notification *Notification) error {
+	sink, ok := n.sinks[rule.GetSinkType()]
+	if !ok {
+		return message.NewMessage(msghc.ErrNotifySinkNotExists, rule.GetSinkType())
+	}
+	target, err := n.getTarget(rule, notification)
+	if err != nil {
+		return n.saveLog(rule, notification, target, constant.ZeroInt, err)
+	}
+
+	attempts := constant.ZeroInt
+	for {
+		attempts++
+		err = sink.Send(target, notification)
+		if err == nil || attempts > n.retryCount {
+			break
+		}
+		time.Sleep(n.retryInterval)
+	}
+
+	return n.saveLog(rule, notification, target, attempts, err)
+}
+
+// getTarget returns the target of the rule, the email rule with empty target notifies the owner of the mysql cluster
+func (n *Notifier) getTarget(rule healthcheck.NotifyRule, notification *Notification) (string, error) {
+	if rule.GetSinkType() != NotifySinkTypeEmail || rule.GetTarget() != constant.EmptyString {
+		return rule.GetTarget(), nil
+	}
+
+	email, err := n.notifyRepo.GetOwnerEmail(notification.MySQLClusterID)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if email == constant.EmptyString {
+		return constant.EmptyString, message.NewMessage(msghc.ErrNotifyOwnerEmailNotExists, notification.MySQLClusterID)
+	}
+
+	return email, nil
+}
+
+// saveLog saves the delivery log, it returns the delivery error if the delivery failed
+func (n *Notifier) saveLog(rule healthcheck.NotifyRule, notification *Notification, target string, attempts int, deliverErr error) error {
+	status := NotifyLogStatusSucceeded
+	msg := constant.EmptyString
+	if deliverErr != nil {
+		status = NotifyLogStatusFailed
+		msg = deliverErr.Error()
+		deliverErr = message.NewMessage(msghc.ErrHealthcheckNotifyDeliver, rule.Identity(), notification.OperationID, target, attempts, msg)
+	} else {
+		log.Info(message.NewMessage(msghc.InfoHealthcheckNotifyDelivered, rule.Identity(), notification.OperationID, target, attempts).Error())
+	}
+
+	err := n.notifyRepo.SaveLog(NewNotifyLog(rule.Identity(), notification.OperationID, rule.GetSinkType(), target, status, attempts, msg))
+	if err != nil {
+		return multierror.Append(deliverErr, err)
+	}
+
+	return deliverErr
+}
@@ -0,0 +1,259 @@
+package healthcheck
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	notifyWebhookContentType   = "application/json"
+	notifyWebhookMaxBodyLength = 1024
+	notifyFileMode             = 0644
+	notifyFileDirMode          = 0755
+	notifyFileSeparator        = "\n"
+)
+
+var (
+	_ healthcheck.NotifySink = (*WebhookSink)(nil)
+	_ healthcheck.NotifySink = (*EmailSink)(nil)
+	_ healthcheck.NotifySink = (*FileSink)(nil)
+)
+
+// WebhookSink posts the notification as json to the webhook url
+type WebhookSink struct {
+	client *http.Client
+}
+
+// NewWebhookSink returns a new *WebhookSink
+func NewWebhookSink(timeout time.Duration) *WebhookSink {
+	return &WebhookSink{client: &http.Client{Timeout: timeout}}
+}
+
+// Send posts the notification to the webhook url, the response status code must be 2xx
+func (ws *WebhookSink) Send(target string, notification healthcheck.Notification) error {
+	data, err := notification.Marshal()
+	if err != nil {
+		return err
+	}
+	resp, err := ws.client.Post(target, notifyWebhookContentType, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = resp.Body.Close()
+		if err != nil {
+			log.Errorf("healthcheck WebhookSink.Send(): close response body failed.\n%s", err.Error())
+		}
+	}()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, notifyWebhookMaxBodyLength))
+		return message.NewMessage(msghc.ErrNotifyWebhookStatusCodeInvalid, target, resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+// EmailSink sends the notification as a plain text email through the smtp server
+type EmailSink struct {
+	addr    string
+	user    string
+	pass    string
+	from    string
+	timeout time.Duration
+}
+
+// NewEmailSink returns a new *EmailSink, the smtp server will not be authenticated if the user is empty
+func NewEmailSink(addr, user, pass, from string, timeout time.Duration) *EmailSink {
+	return &EmailSink{
+		addr:    addr,
+		user:    user,
+		pass:    pass,
+		from:    from,
+		timeout: timeout,
+	}
+}
+
+// Send sends the notification to the email address, the connection is upgraded to tls if the smtp server supports it
+func (es *EmailSink) Send(target string, notification healthcheck.Notification) error {
+	if es.addr == constant.EmptyString {
+		return message.NewMessage(msghc.ErrNotifySMTPAddrEmpty)
+	}
+	// the target could be the email of the mysql cluster owner, which is not validated by the rule
+	if !isValidNotifyEmailTarget(target) {
+		return message.NewMessage(msghc.ErrNotifyRuleTargetInvalid, target)
+	}
+	msg, err := es.getMessage(target, notification)
+	if err != nil {
+		return err
+	}
+	host, _, err := net.SplitHostPort(es.addr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", es.addr, es.timeout)
+	if err != nil {
+		return err
+	}
+	err = conn.SetDeadline(time.Now().Add(es.timeout))
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	defer func() {
+		err = client.Close()
+		if err != nil {
+			log.Errorf("healthcheck EmailSink.Send(): close smtp connection failed.\n%s", err.Error())
+		}
+	}()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		err = client.StartTLS(&tls.Config{ServerName: host})
+		if err != nil {
+			return err
+		}
+	}
+	if es.user != constant.EmptyString {
+		err = client.Auth(smtp.PlainAuth(constant.EmptyString, es.user, es.pass, host))
+		if err != nil {
+			return err
+		}
+	}
+	err = client.Mail(es.from)
+	if err != nil {
+		return err
+	}
+	err = client.Rcpt(target)
+	if err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(msg)
+	if err != nil {
+		return err
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// getMessage returns the email message of the notification, the header values must not contain line breaks,
+// otherwise, the extra headers could be injected
+func (es *EmailSink) getMessage(target string, notification healthcheck.Notification) ([]byte, error) {
+	headers := []struct {
+		name  string
+		value string
+	}{
+		{"From", es.from},
+		{"To", target},
+		{"Subject", notification.GetSubject()},
+	}
+	for _, header := range headers {
+		if hasLineBreak(header.value) {
+			return nil, message.NewMessage(msghc.ErrNotifyEmailHeaderInvalid, header.name, header.value)
+		}
+	}
+
+	return []byte(fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s",
+		es.from, target, notification.GetSubject(), notification.GetContent())), nil
+}
+
+// FileSink appends the notification as a json line to the file in the base directory
+type FileSink struct {
+	baseDir string
+	mutex   sync.Mutex
+}
+
+// NewFileSink returns a new *FileSink, the targets are resolved relative to the base directory
+func NewFileSink(baseDir string) *FileSink {
+	return &FileSink{baseDir: baseDir}
+}
+
+// Send appends the notification to the file, the file will be created if it does not exist
+func (fs *FileSink) Send(target string, notification healthcheck.Notification) error {
+	if !isValidNotifyFileTarget(target) {
+		return message.NewMessage(msghc.ErrNotifyRuleTargetInvalid, target)
+	}
+	data, err := notification.Marshal()
+	if err != nil {
+		return err
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	fileName := filepath.Join(fs.baseDir, filepath.Clean(target))
+	err = os.MkdirAll(filepath.Dir(fileName), notifyFileDirMode)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(fileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, notifyFileMode)
+	if err != nil {
+		return err
+	}
+	_, err = file.WriteString(string(data) + notifyFileSeparator)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// isValidNotifyEmailTarget checks if the target of the email sink is a bare email address
+func isValidNotifyEmailTarget(target string) bool {
+	addr, err := mail.ParseAddress(target)
+	if err != nil {
+		return false
+	}
+
+	return addr.Address == target
+}
+
+// hasLineBreak returns if the value contains the carriage return or the line feed
+func hasLineBreak(value string) bool {
+	return strings.ContainsAny(value, "\r\n")
+}
+
+// isValidNotifyFileTarget checks if the target of the file sink is a relative path which stays in the base directory
+func isValidNotifyFileTarget(target string) bool {
+	if strings.TrimSpace(target) == constant.EmptyString || filepath.IsAbs(target) {
+		return false
+	}
+	for _, elem := range strings.Split(filepath.ToSlash(target), "/") {
+		if elem == ".." {
+			return false
+		}
+	}
+
+	return filepath.Clean(target) != "."
+}
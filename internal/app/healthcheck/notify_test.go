@@ -0,0 +1,198 @@
+package healthcheck
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+type testNotifyRepo struct {
+	healthcheck.NotifyRepo
+	ownerEmail string
+	logs       []healthcheck.NotifyLog
+}
+
+func (tnr *testNotifyRepo) GetOwnerEmail(mysqlClusterID int) (string, error) {
+	return tnr.ownerEmail, nil
+}
+
+func (tnr *testNotifyRepo) SaveLog(notifyLog healthcheck.NotifyLog) error {
+	tnr.logs = append(tnr.logs, notifyLog)
+
+	return nil
+}
+
+type testNotifySink struct {
+	failures int
+	targets  []string
+}
+
+func (tns *testNotifySink) Send(target string, notification healthcheck.Notification) error {
+	tns.targets = append(tns.targets, target)
+	if tns.failures > constant.ZeroInt {
+		tns.failures--
+		return errors.New("test notify sink failed")
+	}
+
+	return nil
+}
+
+func TestNotifyAll(t *testing.T) {
+	TestNotify_NewNotifyRule(t)
+	TestNotify_match(t)
+	TestNotify_dispatch(t)
+	TestNotify_FileSink(t)
+	TestNotify_EmailSinkGetMessage(t)
+}
+
+func TestNotify_NewNotifyRule(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := NewNotifyRule("failure", 1, NotifyTriggerTypeFailure, constant.ZeroInt, NotifySinkTypeWebhook, "https://127.0.0.1:8080/alert")
+	asst.Nil(err, common.CombineMessageWithError("test NewNotifyRule() failed", err))
+	// email rule with empty target notifies the owner
+	_, err = NewNotifyRule("owner", 1, NotifyTriggerTypeScoreBelow, 80, NotifySinkTypeEmail, constant.EmptyString)
+	asst.Nil(err, common.CombineMessageWithError("test NewNotifyRule() failed", err))
+	_, err = NewNotifyRule("owner", 1, NotifyTriggerTypeScoreBelow, 80, NotifySinkTypeEmail, "dba@example.com")
+	asst.Nil(err, common.CombineMessageWithError("test NewNotifyRule() failed", err))
+	// header injection
+	_, err = NewNotifyRule("owner", 1, NotifyTriggerTypeScoreBelow, 80, NotifySinkTypeEmail, "dba@example.com\r\nBcc: evil@example.com")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("owner", 1, NotifyTriggerTypeScoreBelow, 80, NotifySinkTypeEmail, "DBA <dba@example.com>")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("owner\r\nBcc: evil@example.com", 1, NotifyTriggerTypeScoreBelow, 80, NotifySinkTypeEmail, constant.EmptyString)
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("threshold", 1, NotifyTriggerTypeRegression, constant.ZeroInt, NotifySinkTypeFile, "/tmp/hc.log")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("threshold", 1, NotifyTriggerTypeScoreBelow, 101, NotifySinkTypeFile, "/tmp/hc.log")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("trigger", 1, 4, 80, NotifySinkTypeFile, "/tmp/hc.log")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("sink", 1, NotifyTriggerTypeFailure, constant.ZeroInt, 4, "/tmp/hc.log")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("webhook", 1, NotifyTriggerTypeFailure, constant.ZeroInt, NotifySinkTypeWebhook, "ftp://127.0.0.1/alert")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("file", 1, NotifyTriggerTypeFailure, constant.ZeroInt, NotifySinkTypeFile, " ")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("file", 1, NotifyTriggerTypeFailure, constant.ZeroInt, NotifySinkTypeFile, "/tmp/hc.log")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("file", 1, NotifyTriggerTypeFailure, constant.ZeroInt, NotifySinkTypeFile, "../hc.log")
+	asst.NotNil(err, "test NewNotifyRule() failed")
+	_, err = NewNotifyRule("file", 1, NotifyTriggerTypeFailure, constant.ZeroInt, NotifySinkTypeFile, "hc/hc.log")
+	asst.Nil(err, common.CombineMessageWithError("test NewNotifyRule() failed", err))
+}
+
+func TestNotify_match(t *testing.T) {
+	asst := assert.New(t)
+
+	failureRule := &NotifyRule{ID: 1, RuleName: "failure", TriggerType: NotifyTriggerTypeFailure}
+	belowRule := &NotifyRule{ID: 2, RuleName: "below", TriggerType: NotifyTriggerTypeScoreBelow, Threshold: 80}
+	regressionRule := &NotifyRule{ID: 3, RuleName: "regression", TriggerType: NotifyTriggerTypeRegression, Threshold: 10}
+
+	failed := &Notification{OperationID: 1, Status: defaultFailedStatus}
+	n, ok := failed.match(failureRule)
+	asst.True(ok, "test match() failed")
+	asst.Equal(failureRule.ID, n.RuleID, "test match() failed")
+	asst.Equal(failureRule.RuleName, n.RuleName, "test match() failed")
+	asst.Equal(constant.EmptyString, failed.RuleName, "test match() failed")
+	_, ok = failed.match(belowRule)
+	asst.False(ok, "test match() failed")
+	_, ok = failed.match(regressionRule)
+	asst.False(ok, "test match() failed")
+
+	succeeded := &Notification{OperationID: 2, Status: defaultSuccessStatus, WeightedAverageScore: 75, PreviousWeightedAverageScore: 90, hasPrevious: true}
+	_, ok = succeeded.match(failureRule)
+	asst.False(ok, "test match() failed")
+	_, ok = succeeded.match(belowRule)
+	asst.True(ok, "test match() failed")
+	_, ok = succeeded.match(regressionRule)
+	asst.True(ok, "test match() failed")
+	// no previous score
+	succeeded.hasPrevious = false
+	_, ok = succeeded.match(regressionRule)
+	asst.False(ok, "test match() failed")
+	// small drop and high score
+	succeeded = &Notification{OperationID: 3, Status: defaultSuccessStatus, WeightedAverageScore: 85, PreviousWeightedAverageScore: 90, hasPrevious: true}
+	_, ok = succeeded.match(belowRule)
+	asst.False(ok, "test match() failed")
+	_, ok = succeeded.match(regressionRule)
+	asst.False(ok, "test match() failed")
+}
+
+func TestNotify_dispatch(t *testing.T) {
+	asst := assert.New(t)
+
+	repo := &testNotifyRepo{ownerEmail: "owner@example.com"}
+	webhookSink := &testNotifySink{failures: 2}
+	emailSink := &testNotifySink{failures: 5}
+	n := NewNotifier(repo, nil, map[int]healthcheck.NotifySink{
+		NotifySinkTypeWebhook: webhookSink,
+		NotifySinkTypeEmail:   emailSink,
+	}, 2, constant.ZeroInt)
+
+	rules := []healthcheck.NotifyRule{
+		&NotifyRule{ID: 1, RuleName: "webhook", TriggerType: NotifyTriggerTypeFailure, SinkType: NotifySinkTypeWebhook, Target: "http://127.0.0.1:8080/alert"},
+		&NotifyRule{ID: 2, RuleName: "email", TriggerType: NotifyTriggerTypeFailure, SinkType: NotifySinkTypeEmail},
+		&NotifyRule{ID: 3, RuleName: "below", TriggerType: NotifyTriggerTypeScoreBelow, Threshold: 80, SinkType: NotifySinkTypeWebhook, Target: "http://127.0.0.1:8080/alert"},
+	}
+	err := n.dispatch(rules, &Notification{OperationID: 1, MySQLClusterID: 1, Status: defaultFailedStatus})
+	// the email sink fails more than the retry count
+	asst.NotNil(err, "test dispatch() failed")
+	asst.Equal(2, len(repo.logs), "test dispatch() failed")
+	// the webhook succeeds on the last retry
+	asst.Equal(1, repo.logs[0].GetRuleID(), "test dispatch() failed")
+	asst.Equal(NotifyLogStatusSucceeded, repo.logs[0].GetStatus(), "test dispatch() failed")
+	asst.Equal(3, repo.logs[0].GetAttempts(), "test dispatch() failed")
+	// the email is sent to the owner
+	asst.Equal(2, repo.logs[1].GetRuleID(), "test dispatch() failed")
+	asst.Equal(NotifyLogStatusFailed, repo.logs[1].GetStatus(), "test dispatch() failed")
+	asst.Equal(3, repo.logs[1].GetAttempts(), "test dispatch() failed")
+	asst.Equal("owner@example.com", repo.logs[1].GetTarget(), "test dispatch() failed")
+	asst.Equal([]string{"owner@example.com", "owner@example.com", "owner@example.com"}, emailSink.targets, "test dispatch() failed")
+}
+
+func TestNotify_FileSink(t *testing.T) {
+	asst := assert.New(t)
+
+	baseDir := t.TempDir()
+	sink := NewFileSink(baseDir)
+	notification := &Notification{RuleName: "failure", OperationID: 1, Status: defaultFailedStatus}
+	for i := 0; i < 2; i++ {
+		err := sink.Send("hc/notify.log", notification)
+		asst.Nil(err, common.CombineMessageWithError("test FileSink.Send() failed", err))
+	}
+	err := sink.Send(filepath.Join(baseDir, "notify.log"), notification)
+	asst.NotNil(err, "test FileSink.Send() failed")
+	err = sink.Send("hc/../../notify.log", notification)
+	asst.NotNil(err, "test FileSink.Send() failed")
+	data, err := ioutil.ReadFile(filepath.Join(baseDir, "hc", "notify.log"))
+	asst.Nil(err, common.CombineMessageWithError("test FileSink.Send() failed", err))
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	asst.Equal(2, len(lines), "test FileSink.Send() failed")
+	asst.Contains(lines[0], `"rule_name":"failure"`, "test FileSink.Send() failed")
+}
+
+func TestNotify_EmailSinkGetMessage(t *testing.T) {
+	asst := assert.New(t)
+
+	sink := NewEmailSink("127.0.0.1:25", constant.EmptyString, constant.EmptyString, "das@example.com", time.Second)
+	notification := &Notification{RuleName: "owner", HostIP: "192.168.10.219", PortNum: 3306}
+	msg, err := sink.getMessage("dba@example.com", notification)
+	asst.Nil(err, common.CombineMessageWithError("test getMessage() failed", err))
+	asst.True(strings.Contains(string(msg), "Subject: [das healthcheck] owner: 192.168.10.219:3306\r\n"), "test getMessage() failed")
+	// the subject must not contain line breaks
+	notification.RuleName = "owner\nBcc: evil@example.com"
+	_, err = sink.getMessage("dba@example.com", notification)
+	asst.NotNil(err, "test getMessage() failed")
+	// the owner email is only validated when it is sent
+	err = sink.Send("dba@example.com\r\nBcc: evil@example.com", notification)
+	asst.NotNil(err, "test getMessage() failed")
+}
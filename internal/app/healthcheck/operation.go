@@ -0,0 +1,182 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	defaultItemStatusPending   = "pending"
+	defaultItemStatusRunning   = "running"
+	defaultItemStatusCompleted = "completed"
+	defaultItemStatusCanceled  = "canceled"
+)
+
+var (
+	_ healthcheck.Operation = (*Operation)(nil)
+
+	// runningOperations are the operations which are running on this das server
+	runningOperations = newOperationRegistry()
+	// runningMiddlewareOperations are the middleware operations which are running on this das server,
+	// they are kept apart from the mysql operations as the ids come from a different table
+	runningMiddlewareOperations = newOperationRegistry()
+)
+
+// Operation is a healthcheck operation of a mysql server
+type Operation struct {
+	ID                  int       `middleware:"id" json:"id"`
+	ClusterOperationID  int       `middleware:"cluster_operation_id" json:"cluster_operation_id"`
+	MySQLServerID       int       `middleware:"mysql_server_id" json:"mysql_server_id"`
+	StartTime           time.Time `middleware:"start_time" json:"start_time"`
+	EndTime             time.Time `middleware:"end_time" json:"end_time"`
+	Step                int       `middleware:"step" json:"step"`
+	EngineConfigVersion int       `middleware:"engine_config_version" json:"engine_config_version"`
+	Status              int       `middleware:"status" json:"status"`
+	Message             string    `middleware:"message" json:"message"`
+	DelFlag             int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime          time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime      time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewEmptyOperation returns a new empty *Operation
+func NewEmptyOperation() *Operation {
+	return &Operation{}
+}
+
+// Identity returns the identity
+func (o *Operation) Identity() int {
+	return o.ID
+}
+
+// GetClusterOperationID returns the cluster operation id, it is 0 if the operation does not belong to any cluster operation
+func (o *Operation) GetClusterOperationID() int {
+	return o.ClusterOperationID
+}
+
+// GetMySQLServerID returns the mysql server id
+func (o *Operation) GetMySQLServerID() int {
+	return o.MySQLServerID
+}
+
+// GetStartTime returns the start time of the check range
+func (o *Operation) GetStartTime() time.Time {
+	return o.StartTime
+}
+
+// GetEndTime returns the end time of the check range
+func (o *Operation) GetEndTime() time.Time {
+	return o.EndTime
+}
+
+// GetStep returns the step
+func (o *Operation) GetStep() time.Duration {
+	return time.Duration(o.Step) * time.Second
+}
+
+// GetEngineConfigVersion returns the version of the engine config which the operation was scored with,
+// it is 0 if the engine config has not been loaded
+func (o *Operation) GetEngineConfigVersion() int {
+	return o.EngineConfigVersion
+}
+
+// GetStatus returns the status
+func (o *Operation) GetStatus() int {
+	return o.Status
+}
+
+// GetMessage returns the message
+func (o *Operation) GetMessage() string {
+	return o.Message
+}
+
+// GetDelFlag returns the delete flag
+func (o *Operation) GetDelFlag() int {
+	return o.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (o *Operation) GetCreateTime() time.Time {
+	return o.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (o *Operation) GetLastUpdateTime() time.Time {
+	return o.LastUpdateTime
+}
+
+// MarshalJSON marshals Operation to json bytes
+func (o *Operation) MarshalJSON() ([]byte, error) {
+	return common.MarshalStructWithTag(o, constant.DefaultMarshalTag)
+}
+
+// OperationStatus is the status of the operation and the progress of each check item,
+// the item statuses are only available when the operation is running on this das server
+type OperationStatus struct {
+	Operation    healthcheck.Operation `json:"operation"`
+	ItemStatuses map[string]string     `json:"item_statuses"`
+}
+
+// runningOperation is an operation which is running on this das server
+type runningOperation struct {
+	engine healthcheck.Engine
+	cancel context.CancelFunc
+}
+
+// operationRegistry keeps the running operations, so that they could be inspected and canceled
+type operationRegistry struct {
+	mutex      sync.RWMutex
+	operations map[int]*runningOperation
+}
+
+// newOperationRegistry returns a new *operationRegistry
+func newOperationRegistry() *operationRegistry {
+	return &operationRegistry{operations: make(map[int]*runningOperation)}
+}
+
+// register registers the running operation
+func (or *operationRegistry) register(operationID int, engine healthcheck.Engine, cancel context.CancelFunc) {
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+
+	or.operations[operationID] = &runningOperation{engine: engine, cancel: cancel}
+}
+
+// unregister unregisters the operation
+func (or *operationRegistry) unregister(operationID int) {
+	or.mutex.Lock()
+	defer or.mutex.Unlock()
+
+	delete(or.operations, operationID)
+}
+
+// getEngine returns the engine of the running operation, it returns false if the operation is not running on this das server
+func (or *operationRegistry) getEngine(operationID int) (healthcheck.Engine, bool) {
+	or.mutex.RLock()
+	defer or.mutex.RUnlock()
+
+	ro, exists := or.operations[operationID]
+	if !exists {
+		return nil, false
+	}
+
+	return ro.engine, true
+}
+
+// cancel cancels the running operation, it returns false if the operation is not running on this das server
+func (or *operationRegistry) cancel(operationID int) bool {
+	or.mutex.RLock()
+	defer or.mutex.RUnlock()
+
+	ro, exists := or.operations[operationID]
+	if !exists {
+		return false
+	}
+	ro.cancel()
+
+	return true
+}
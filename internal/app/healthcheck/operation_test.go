@@ -0,0 +1,69 @@
+package healthcheck
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/stretchr/testify/assert"
+)
+
+const testOperationID = 1
+
+func TestOperationAll(t *testing.T) {
+	TestOperationRegistry_register(t)
+	TestOperationRegistry_cancel(t)
+	TestDefaultEngine_checkItemsCanceled(t)
+}
+
+func TestOperationRegistry_register(t *testing.T) {
+	asst := assert.New(t)
+
+	or := newOperationRegistry()
+	de := newTestDefaultEngine(testCheckItemName)
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	or.register(testOperationID, de, cancel)
+	engine, isRunning := or.getEngine(testOperationID)
+	asst.True(isRunning, "test register() failed")
+	asst.Equal(de, engine, "test register() failed")
+
+	or.unregister(testOperationID)
+	_, isRunning = or.getEngine(testOperationID)
+	asst.False(isRunning, "test register() failed")
+}
+
+func TestOperationRegistry_cancel(t *testing.T) {
+	asst := assert.New(t)
+
+	or := newOperationRegistry()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	asst.False(or.cancel(testOperationID), "test cancel() failed")
+	or.register(testOperationID, newTestDefaultEngine(testCheckItemName), cancel)
+	asst.True(or.cancel(testOperationID), "test cancel() failed")
+	asst.NotNil(ctx.Err(), "test cancel() failed")
+}
+
+func TestDefaultEngine_checkItemsCanceled(t *testing.T) {
+	asst := assert.New(t)
+
+	de := newTestDefaultEngine(testCheckItemName, testSlowItemName)
+	de.itemTimeout = 5 * time.Second
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	de.checkItems(ctx, []healthcheck.CheckItem{
+		&testCheckItem{name: testCheckItemName, score: 80},
+		&testCheckItem{name: testSlowItemName, score: 80, delay: time.Second},
+	})
+	asst.True(time.Since(start) < time.Second, "test checkItems() failed")
+	itemStatuses := de.GetItemStatuses()
+	asst.Equal(defaultItemStatusCompleted, itemStatuses[testCheckItemName], "test checkItems() failed")
+	asst.Equal(defaultItemStatusCanceled, itemStatuses[testSlowItemName], "test checkItems() failed")
+	asst.False(de.GetItemResults()[1].IsAvailable(), "test checkItems() failed")
+}
@@ -0,0 +1,374 @@
+package healthcheck
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	// MonitorSystemTypePMMV1 is the monitor system type of pmm 1.x
+	MonitorSystemTypePMMV1 = 1
+	// MonitorSystemTypePMMV2 is the monitor system type of pmm 2.x
+	MonitorSystemTypePMMV2 = 2
+	// MonitorSystemTypePrometheus is the monitor system type of the plain prometheus which scrapes node_exporter and mysqld_exporter,
+	// there is no query analytics, so the slow queries are not available
+	MonitorSystemTypePrometheus = 3
+
+	// PrometheusCatalogueNodeLabel is the name of the label which identifies the node in the node metrics
+	PrometheusCatalogueNodeLabel = "node_label"
+	// PrometheusCatalogueNodeValue is the name of the label value which identifies the node in the node metrics
+	PrometheusCatalogueNodeValue = "node_value"
+	// PrometheusCatalogueServiceLabel is the name of the label which identifies the mysql server in the mysql metrics
+	PrometheusCatalogueServiceLabel = "service_label"
+	// PrometheusCatalogueServiceValue is the name of the label value which identifies the mysql server in the mysql metrics
+	PrometheusCatalogueServiceValue = "service_value"
+
+	PrometheusCatalogueFileSystem                   = "file_system"
+	PrometheusCatalogueCPUUsage                     = "cpu_usage"
+	PrometheusCatalogueIOUtil                       = "io_util"
+	PrometheusCatalogueDiskCapacityUsage            = "disk_capacity_usage"
+	PrometheusCatalogueConnectionUsage              = "connection_usage"
+	PrometheusCatalogueAverageActiveSessionPercents = "average_active_session_percents"
+	PrometheusCatalogueCacheMissRatio               = "cache_miss_ratio"
+	PrometheusCatalogueReplicationLag               = "replication_lag"
+	PrometheusCatalogueInnoDBRowLockWaits           = "innodb_row_lock_waits"
+	PrometheusCatalogueInnoDBRowLockTime            = "innodb_row_lock_time"
+
+	// the templates use ${.Field} instead of {{.Field}}, as the label matchers of promql are enclosed in braces
+	prometheusCatalogueLeftDelim  = "${"
+	prometheusCatalogueRightDelim = "}"
+	prometheusCatalogueOption     = "missingkey=error"
+
+	prometheusCatalogueValidateHostIP      = "127.0.0.1"
+	prometheusCatalogueValidatePortNum     = 3306
+	prometheusCatalogueValidateServiceName = "127-0-0-1-3306"
+	prometheusCatalogueValidateMountPoints = "/|/data"
+	prometheusCatalogueValidateStep        = time.Minute
+
+	// prometheusMinRange is the minimum range of the range vector selectors, such as rate(), it covers several scrape intervals
+	prometheusMinRange = 5 * time.Minute
+	// prometheusMinShortRange is the minimum range of the range vector selectors which prefer the high resolution
+	prometheusMinShortRange = 20 * time.Second
+	// prometheusMaxPoints is the maximum points of a series which prometheus returns for a range query,
+	// the step is enlarged if the time window contains more points
+	prometheusMaxPoints = 11000
+)
+
+// prometheusCatalogueLabelNames are the names of the label mappings, they are rendered before the queries,
+// so that the queries could refer to the rendered labels
+var prometheusCatalogueLabelNames = []string{
+	PrometheusCatalogueNodeLabel,
+	PrometheusCatalogueNodeValue,
+	PrometheusCatalogueServiceLabel,
+	PrometheusCatalogueServiceValue,
+}
+
+// defaultPrometheusCatalogues are the built-in catalogues of the monitor system types
+var defaultPrometheusCatalogues = map[int]map[string]string{
+	MonitorSystemTypePMMV1: {
+		PrometheusCatalogueNodeLabel:                    "instance",
+		PrometheusCatalogueNodeValue:                    "${.NodeName}",
+		PrometheusCatalogueServiceLabel:                 "instance",
+		PrometheusCatalogueServiceValue:                 "${.ServiceName}",
+		PrometheusCatalogueFileSystem:                   prometheusFileSystemV1,
+		PrometheusCatalogueCPUUsage:                     prometheusCPUUsageV1,
+		PrometheusCatalogueIOUtil:                       prometheusIOUtilV1,
+		PrometheusCatalogueDiskCapacityUsage:            prometheusDiskCapacityV1,
+		PrometheusCatalogueConnectionUsage:              prometheusConnectionUsageV1,
+		PrometheusCatalogueAverageActiveSessionPercents: prometheusAverageActiveSessionPercentsV1,
+		PrometheusCatalogueCacheMissRatio:               prometheusCacheMissRatioV1,
+		PrometheusCatalogueReplicationLag:               prometheusReplicationLagV1,
+		PrometheusCatalogueInnoDBRowLockWaits:           prometheusInnoDBRowLockWaitsV1,
+		PrometheusCatalogueInnoDBRowLockTime:            prometheusInnoDBRowLockTimeV1,
+	},
+	MonitorSystemTypePMMV2: {
+		PrometheusCatalogueNodeLabel:                    "node_name",
+		PrometheusCatalogueNodeValue:                    "${.NodeName}",
+		PrometheusCatalogueServiceLabel:                 "service_name",
+		PrometheusCatalogueServiceValue:                 "${.ServiceName}",
+		PrometheusCatalogueFileSystem:                   prometheusFileSystemV2,
+		PrometheusCatalogueCPUUsage:                     prometheusCPUUsageV2,
+		PrometheusCatalogueIOUtil:                       prometheusIOUtilV2,
+		PrometheusCatalogueDiskCapacityUsage:            prometheusDiskCapacityV2,
+		PrometheusCatalogueConnectionUsage:              prometheusConnectionUsageV2,
+		PrometheusCatalogueAverageActiveSessionPercents: prometheusAverageActiveSessionPercentsV2,
+		PrometheusCatalogueCacheMissRatio:               prometheusCacheMissRatioV2,
+		PrometheusCatalogueReplicationLag:               prometheusReplicationLagV2,
+		PrometheusCatalogueInnoDBRowLockWaits:           prometheusInnoDBRowLockWaitsV2,
+		PrometheusCatalogueInnoDBRowLockTime:            prometheusInnoDBRowLockTimeV2,
+	},
+	MonitorSystemTypePrometheus: {
+		// the default ports of node_exporter and mysqld_exporter
+		PrometheusCatalogueNodeLabel:                    "instance",
+		PrometheusCatalogueNodeValue:                    "${.HostIP}:9100",
+		PrometheusCatalogueServiceLabel:                 "instance",
+		PrometheusCatalogueServiceValue:                 "${.HostIP}:9104",
+		PrometheusCatalogueFileSystem:                   prometheusFileSystemPlain,
+		PrometheusCatalogueCPUUsage:                     prometheusCPUUsagePlain,
+		PrometheusCatalogueIOUtil:                       prometheusIOUtilPlain,
+		PrometheusCatalogueDiskCapacityUsage:            prometheusDiskCapacityPlain,
+		PrometheusCatalogueConnectionUsage:              prometheusConnectionUsagePlain,
+		PrometheusCatalogueAverageActiveSessionPercents: prometheusAverageActiveSessionPercentsPlain,
+		PrometheusCatalogueCacheMissRatio:               prometheusCacheMissRatioPlain,
+		PrometheusCatalogueReplicationLag:               prometheusReplicationLagPlain,
+		PrometheusCatalogueInnoDBRowLockWaits:           prometheusInnoDBRowLockWaitsPlain,
+		PrometheusCatalogueInnoDBRowLockTime:            prometheusInnoDBRowLockTimePlain,
+	},
+}
+
+var _ healthcheck.PrometheusCatalogueOverride = (*PrometheusCatalogueOverride)(nil)
+
+// PrometheusCatalogueOverride overrides the template of the label mapping or the query in the catalogue of the monitor system
+type PrometheusCatalogueOverride struct {
+	ID              int       `middleware:"id" json:"id"`
+	MonitorSystemID int       `middleware:"monitor_system_id" json:"monitor_system_id"`
+	Name            string    `middleware:"name" json:"name"`
+	Template        string    `middleware:"template" json:"template"`
+	DelFlag         int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime      time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime  time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewPrometheusCatalogueOverride returns a new *PrometheusCatalogueOverride
+func NewPrometheusCatalogueOverride(monitorSystemID int, name, template string) *PrometheusCatalogueOverride {
+	return &PrometheusCatalogueOverride{
+		MonitorSystemID: monitorSystemID,
+		Name:            name,
+		Template:        template,
+	}
+}
+
+// NewEmptyPrometheusCatalogueOverride returns a new empty *PrometheusCatalogueOverride
+func NewEmptyPrometheusCatalogueOverride() *PrometheusCatalogueOverride {
+	return &PrometheusCatalogueOverride{}
+}
+
+// Identity returns the identity
+func (pco *PrometheusCatalogueOverride) Identity() int {
+	return pco.ID
+}
+
+// GetMonitorSystemID returns the monitor system id
+func (pco *PrometheusCatalogueOverride) GetMonitorSystemID() int {
+	return pco.MonitorSystemID
+}
+
+// GetName returns the name of the label mapping or the query in the catalogue
+func (pco *PrometheusCatalogueOverride) GetName() string {
+	return pco.Name
+}
+
+// GetTemplate returns the overridden template
+func (pco *PrometheusCatalogueOverride) GetTemplate() string {
+	return pco.Template
+}
+
+// GetDelFlag returns the delete flag
+func (pco *PrometheusCatalogueOverride) GetDelFlag() int {
+	return pco.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (pco *PrometheusCatalogueOverride) GetCreateTime() time.Time {
+	return pco.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (pco *PrometheusCatalogueOverride) GetLastUpdateTime() time.Time {
+	return pco.LastUpdateTime
+}
+
+// prometheusCatalogueParams are the fields which could be referred by the templates
+type prometheusCatalogueParams struct {
+	HostIP       string
+	PortNum      int
+	ServiceName  string
+	NodeName     string
+	MountPoints  string
+	Range        string
+	ShortRange   string
+	NodeLabel    string
+	NodeValue    string
+	ServiceLabel string
+	ServiceValue string
+}
+
+// newPrometheusCatalogueParams returns a new *prometheusCatalogueParams of the mysql server,
+// the ranges of the range vector selectors are derived from the step, so that every sample in the step is covered
+func newPrometheusCatalogueParams(mysqlServer depmeta.MySQLServer, step time.Duration, mountPoints []string) *prometheusCatalogueParams {
+	return &prometheusCatalogueParams{
+		HostIP:      mysqlServer.GetHostIP(),
+		PortNum:     mysqlServer.GetPortNum(),
+		ServiceName: mysqlServer.GetServiceName(),
+		NodeName:    getPrometheusNodeName(mysqlServer.GetServiceName()),
+		MountPoints: common.ConvertStringSliceToString(mountPoints, constant.VerticalBarString),
+		Range:       getPrometheusRange(step, prometheusMinRange),
+		ShortRange:  getPrometheusRange(step, prometheusMinShortRange),
+	}
+}
+
+// PrometheusCatalogue is the templates of the label mappings and the queries of a monitor system,
+// it is the built-in catalogue of the monitor system type with the overrides of the monitor system applied
+type PrometheusCatalogue struct {
+	systemType int
+	templates  map[string]string
+}
+
+// NewPrometheusCatalogue returns a new *PrometheusCatalogue of the system type with given overrides applied,
+// the override of the unknown name is ignored
+func NewPrometheusCatalogue(systemType int, overrides []healthcheck.PrometheusCatalogueOverride) (*PrometheusCatalogue, error) {
+	defaultTemplates, ok := defaultPrometheusCatalogues[systemType]
+	if !ok {
+		return nil, message.NewMessage(msghc.ErrPrometheusCatalogueSystemTypeInvalid, systemType)
+	}
+
+	templates := make(map[string]string, len(defaultTemplates))
+	for name, tmpl := range defaultTemplates {
+		templates[name] = tmpl
+	}
+	for _, override := range overrides {
+		if !isValidPrometheusCatalogueName(override.GetName()) {
+			log.Warn(message.NewMessage(msghc.ErrPrometheusCatalogueNameInvalid, override.GetName()).Error())
+			continue
+		}
+		templates[override.GetName()] = override.GetTemplate()
+	}
+
+	return &PrometheusCatalogue{
+		systemType: systemType,
+		templates:  templates,
+	}, nil
+}
+
+// NewPrometheusCatalogueWithDefault returns the built-in *PrometheusCatalogue of the system type
+func NewPrometheusCatalogueWithDefault(systemType int) (*PrometheusCatalogue, error) {
+	return NewPrometheusCatalogue(systemType, nil)
+}
+
+// GetSystemType returns the monitor system type
+func (pc *PrometheusCatalogue) GetSystemType() int {
+	return pc.systemType
+}
+
+// GetTemplates returns the templates of the catalogue
+func (pc *PrometheusCatalogue) GetTemplates() map[string]string {
+	return pc.templates
+}
+
+// Render renders the query of given name for the mysql server with the step of the range query,
+// the mount points are only used by the disk capacity usage
+func (pc *PrometheusCatalogue) Render(name string, mysqlServer depmeta.MySQLServer, step time.Duration, mountPoints []string) (string, error) {
+	return pc.render(name, newPrometheusCatalogueParams(mysqlServer, step, mountPoints))
+}
+
+// Validate renders all the templates with sample params, it returns an error if any template could not be rendered
+func (pc *PrometheusCatalogue) Validate() error {
+	params := &prometheusCatalogueParams{
+		HostIP:      prometheusCatalogueValidateHostIP,
+		PortNum:     prometheusCatalogueValidatePortNum,
+		ServiceName: prometheusCatalogueValidateServiceName,
+		NodeName:    getPrometheusNodeName(prometheusCatalogueValidateServiceName),
+		MountPoints: prometheusCatalogueValidateMountPoints,
+		Range:       getPrometheusRange(prometheusCatalogueValidateStep, prometheusMinRange),
+		ShortRange:  getPrometheusRange(prometheusCatalogueValidateStep, prometheusMinShortRange),
+	}
+	for name := range pc.GetTemplates() {
+		_, err := pc.render(name, params)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// render renders the label mappings at first, and then renders the template of given name with the params
+func (pc *PrometheusCatalogue) render(name string, params *prometheusCatalogueParams) (string, error) {
+	labels := make([]string, len(prometheusCatalogueLabelNames))
+	for i, labelName := range prometheusCatalogueLabelNames {
+		label, err := pc.execute(labelName, params)
+		if err != nil {
+			return constant.EmptyString, err
+		}
+		labels[i] = label
+	}
+	params.NodeLabel, params.NodeValue, params.ServiceLabel, params.ServiceValue = labels[0], labels[1], labels[2], labels[3]
+
+	return pc.execute(name, params)
+}
+
+// execute executes the template of given name with the params
+func (pc *PrometheusCatalogue) execute(name string, params *prometheusCatalogueParams) (string, error) {
+	tmplStr, ok := pc.GetTemplates()[name]
+	if !ok {
+		return constant.EmptyString, message.NewMessage(msghc.ErrPrometheusCatalogueNameInvalid, name)
+	}
+	tmpl, err := template.New(name).
+		Delims(prometheusCatalogueLeftDelim, prometheusCatalogueRightDelim).
+		Option(prometheusCatalogueOption).
+		Parse(tmplStr)
+	if err != nil {
+		return constant.EmptyString, message.NewMessage(msghc.ErrPrometheusCatalogueTemplateInvalid, name, tmplStr, err.Error())
+	}
+
+	buffer := bytes.Buffer{}
+	err = tmpl.Execute(&buffer, params)
+	if err != nil {
+		return constant.EmptyString, message.NewMessage(msghc.ErrPrometheusCatalogueTemplateInvalid, name, tmplStr, err.Error())
+	}
+
+	return buffer.String(), nil
+}
+
+// isValidPrometheusCatalogueName returns if the name is a label mapping or a query of the catalogue,
+// all the system types have the same names
+func isValidPrometheusCatalogueName(name string) bool {
+	_, ok := defaultPrometheusCatalogues[MonitorSystemTypePMMV2][name]
+
+	return ok
+}
+
+// getPrometheusNodeName returns the node name of the service name
+func getPrometheusNodeName(serviceName string) string {
+	strList := strings.Split(serviceName, constant.ColonString)
+	if len(strList) > constant.ZeroInt {
+		return strList[constant.ZeroInt]
+	}
+
+	return serviceName[:strings.LastIndex(serviceName, constant.DashString)]
+}
+
+// getPrometheusRange returns the range of the range vector selector, it is the step if the step is larger than the minimum range,
+// the range is formatted in seconds, such as 300s
+func getPrometheusRange(step, minRange time.Duration) string {
+	if step < minRange {
+		step = minRange
+	}
+
+	return fmt.Sprintf("%ds", int64(step.Seconds()))
+}
+
+// getPrometheusStep returns the step of the range query, the step is enlarged if the time window contains too many points,
+// so the whole time window is always covered and the series are downsampled with the same step
+func getPrometheusStep(startTime, endTime time.Time, step time.Duration) time.Duration {
+	minStep := endTime.Sub(startTime) / prometheusMaxPoints
+	if minStep%time.Second != constant.ZeroInt {
+		minStep = minStep.Truncate(time.Second) + time.Second
+	}
+	if step < minStep {
+		return minStep
+	}
+
+	return step
+}
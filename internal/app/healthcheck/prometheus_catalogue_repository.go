@@ -0,0 +1,141 @@
+package healthcheck
+
+import (
+	"fmt"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+)
+
+var _ healthcheck.PrometheusCatalogueRepo = (*PrometheusCatalogueRepo)(nil)
+
+// PrometheusCatalogueRepo is the repository of the prometheus catalogue overrides of the monitor systems
+type PrometheusCatalogueRepo struct {
+	Database middleware.Pool
+}
+
+// NewPrometheusCatalogueRepo returns *PrometheusCatalogueRepo with given middleware.Pool
+func NewPrometheusCatalogueRepo(db middleware.Pool) *PrometheusCatalogueRepo {
+	return &PrometheusCatalogueRepo{Database: db}
+}
+
+// NewPrometheusCatalogueRepoWithGlobal returns *PrometheusCatalogueRepo with global mysql pool
+func NewPrometheusCatalogueRepoWithGlobal() *PrometheusCatalogueRepo {
+	return NewPrometheusCatalogueRepo(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (pcr *PrometheusCatalogueRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	conn, err := pcr.Database.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("healthcheck PrometheusCatalogueRepo.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	return conn.Execute(command, args...)
+}
+
+// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+func (pcr *PrometheusCatalogueRepo) Transaction() (middleware.Transaction, error) {
+	return pcr.Database.Transaction()
+}
+
+// GetOverridesByMonitorSystemID gets the prometheus catalogue overrides of given monitor system from the middleware
+func (pcr *PrometheusCatalogueRepo) GetOverridesByMonitorSystemID(monitorSystemID int) ([]healthcheck.PrometheusCatalogueOverride, error) {
+	sql := `
+		select id, monitor_system_id, name, template, del_flag, create_time, last_update_time
+		from t_hc_prometheus_catalogue_override
+		where del_flag = 0
+		and monitor_system_id = ?
+		order by id;
+	`
+	log.Debugf("healthCheck PrometheusCatalogueRepo.GetOverridesByMonitorSystemID() select sql: \n%s\nplaceholders: %s", sql, monitorSystemID)
+
+	result, err := pcr.Execute(sql, monitorSystemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getPrometheusCatalogueOverridesFromResult(result)
+}
+
+// GetOverrideByID gets the prometheus catalogue override by the identity from the middleware
+func (pcr *PrometheusCatalogueRepo) GetOverrideByID(id int) (healthcheck.PrometheusCatalogueOverride, error) {
+	sql := `
+		select id, monitor_system_id, name, template, del_flag, create_time, last_update_time
+		from t_hc_prometheus_catalogue_override
+		where del_flag = 0
+		and id = ?;
+	`
+	log.Debugf("healthCheck PrometheusCatalogueRepo.GetOverrideByID() select sql: \n%s\nplaceholders: %s", sql, id)
+
+	result, err := pcr.Execute(sql, id)
+	if err != nil {
+		return nil, err
+	}
+	overrides, err := getPrometheusCatalogueOverridesFromResult(result)
+	if err != nil {
+		return nil, err
+	}
+	switch len(overrides) {
+	case 0:
+		return nil, fmt.Errorf("healthCheck PrometheusCatalogueRepo.GetOverrideByID(): data does not exists, id: %d", id)
+	case 1:
+		return overrides[constant.ZeroInt], nil
+	default:
+		return nil, fmt.Errorf("healthCheck PrometheusCatalogueRepo.GetOverrideByID(): duplicate key exists, id: %d", id)
+	}
+}
+
+// SaveOverride saves the prometheus catalogue override in the middleware,
+// it replaces the template of the existing override which has the same monitor system and name
+func (pcr *PrometheusCatalogueRepo) SaveOverride(override healthcheck.PrometheusCatalogueOverride) error {
+	sql := `
+		insert into t_hc_prometheus_catalogue_override(monitor_system_id, name, template)
+		values(?, ?, ?)
+		on duplicate key update template = values(template), del_flag = 0;
+	`
+	log.Debugf("healthCheck PrometheusCatalogueRepo.SaveOverride() insert sql: \n%s\nplaceholders: %s, %s, %s",
+		sql, override.GetMonitorSystemID(), override.GetName(), override.GetTemplate())
+	_, err := pcr.Execute(sql, override.GetMonitorSystemID(), override.GetName(), override.GetTemplate())
+
+	return err
+}
+
+// DeleteOverride deletes the prometheus catalogue override in the middleware
+func (pcr *PrometheusCatalogueRepo) DeleteOverride(id int) error {
+	sql := `delete from t_hc_prometheus_catalogue_override where id = ?;`
+	log.Debugf("healthCheck PrometheusCatalogueRepo.DeleteOverride() delete sql: \n%s\nplaceholders: %s", sql, id)
+	_, err := pcr.Execute(sql, id)
+
+	return err
+}
+
+// getPrometheusCatalogueOverridesFromResult maps the result to the prometheus catalogue overrides
+func getPrometheusCatalogueOverridesFromResult(result middleware.Result) ([]healthcheck.PrometheusCatalogueOverride, error) {
+	// init []*PrometheusCatalogueOverride
+	overrideList := make([]*PrometheusCatalogueOverride, result.RowNumber())
+	for i := range overrideList {
+		overrideList[i] = NewEmptyPrometheusCatalogueOverride()
+	}
+	// map to struct
+	err := result.MapToStructSlice(overrideList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.PrometheusCatalogueOverride
+	overrides := make([]healthcheck.PrometheusCatalogueOverride, result.RowNumber())
+	for i := range overrides {
+		overrides[i] = overrideList[i]
+	}
+
+	return overrides, nil
+}
@@ -0,0 +1,153 @@
+package healthcheck
+
+import (
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	prometheusCatalogueMonitorSystemIDStruct = "MonitorSystemID"
+	prometheusCatalogueSystemTypeStruct      = "SystemType"
+	prometheusCatalogueTemplatesStruct       = "Templates"
+	prometheusCatalogueOverridesStruct       = "Overrides"
+)
+
+var _ healthcheck.PrometheusCatalogueService = (*PrometheusCatalogueService)(nil)
+
+// PrometheusCatalogueService of the prometheus catalogue of the monitor systems
+type PrometheusCatalogueService struct {
+	healthcheck.PrometheusCatalogueRepo
+	MonitorSystemID int                                       `json:"monitor_system_id"`
+	SystemType      int                                       `json:"system_type"`
+	Templates       map[string]string                         `json:"templates"`
+	Overrides       []healthcheck.PrometheusCatalogueOverride `json:"overrides"`
+	catalogue       *PrometheusCatalogue
+}
+
+// NewPrometheusCatalogueService returns a new *PrometheusCatalogueService
+func NewPrometheusCatalogueService(repo healthcheck.PrometheusCatalogueRepo) *PrometheusCatalogueService {
+	return &PrometheusCatalogueService{
+		PrometheusCatalogueRepo: repo,
+		Templates:               make(map[string]string),
+		Overrides:               []healthcheck.PrometheusCatalogueOverride{},
+	}
+}
+
+// NewPrometheusCatalogueServiceWithDefault returns a new *PrometheusCatalogueService with default repository
+func NewPrometheusCatalogueServiceWithDefault() *PrometheusCatalogueService {
+	return NewPrometheusCatalogueService(NewPrometheusCatalogueRepoWithGlobal())
+}
+
+// GetTemplates returns the templates of the catalogue, the overrides are already applied
+func (pcs *PrometheusCatalogueService) GetTemplates() map[string]string {
+	return pcs.Templates
+}
+
+// GetOverrides returns the prometheus catalogue overrides of the service
+func (pcs *PrometheusCatalogueService) GetOverrides() []healthcheck.PrometheusCatalogueOverride {
+	return pcs.Overrides
+}
+
+// GetCatalogue returns the catalogue of the service
+func (pcs *PrometheusCatalogueService) GetCatalogue() *PrometheusCatalogue {
+	return pcs.catalogue
+}
+
+// GetByMonitorSystemID gets the catalogue of given monitor system, the overrides are applied to the default catalogue of the system type
+func (pcs *PrometheusCatalogueService) GetByMonitorSystemID(monitorSystemID int) error {
+	monitorSystem, err := pcs.getMonitorSystem(monitorSystemID)
+	if err != nil {
+		return err
+	}
+
+	return pcs.getByMonitorSystem(monitorSystem)
+}
+
+// SaveOverride validates and saves the prometheus catalogue override,
+// the catalogue of the monitor system must be still valid after the override is applied
+func (pcs *PrometheusCatalogueService) SaveOverride(monitorSystemID int, name, template string) error {
+	if !isValidPrometheusCatalogueName(name) {
+		return message.NewMessage(msghc.ErrPrometheusCatalogueNameInvalid, name)
+	}
+	err := pcs.GetByMonitorSystemID(monitorSystemID)
+	if err != nil {
+		return err
+	}
+
+	override := NewPrometheusCatalogueOverride(monitorSystemID, name, template)
+	catalogue, err := NewPrometheusCatalogue(pcs.SystemType, append(pcs.GetOverrides(), override))
+	if err != nil {
+		return err
+	}
+	err = catalogue.Validate()
+	if err != nil {
+		return err
+	}
+	err = pcs.PrometheusCatalogueRepo.SaveOverride(override)
+	if err != nil {
+		return err
+	}
+
+	return pcs.GetByMonitorSystemID(monitorSystemID)
+}
+
+// DeleteOverride deletes the prometheus catalogue override, the template falls back to the default of the system type
+func (pcs *PrometheusCatalogueService) DeleteOverride(id int) error {
+	override, err := pcs.PrometheusCatalogueRepo.GetOverrideByID(id)
+	if err != nil {
+		return err
+	}
+	err = pcs.PrometheusCatalogueRepo.DeleteOverride(id)
+	if err != nil {
+		return err
+	}
+
+	return pcs.GetByMonitorSystemID(override.GetMonitorSystemID())
+}
+
+// Marshal marshals PrometheusCatalogueService to json bytes
+func (pcs *PrometheusCatalogueService) Marshal() ([]byte, error) {
+	return pcs.MarshalWithFields(prometheusCatalogueMonitorSystemIDStruct, prometheusCatalogueSystemTypeStruct,
+		prometheusCatalogueTemplatesStruct, prometheusCatalogueOverridesStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the PrometheusCatalogueService to json bytes
+func (pcs *PrometheusCatalogueService) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(pcs, fields...)
+}
+
+// getMonitorSystem gets the monitor system from the metadata
+func (pcs *PrometheusCatalogueService) getMonitorSystem(monitorSystemID int) (depmeta.MonitorSystem, error) {
+	monitorSystemService := metadata.NewMonitorSystemServiceWithDefault()
+	err := monitorSystemService.GetByID(monitorSystemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return monitorSystemService.GetMonitorSystems()[constant.ZeroInt], nil
+}
+
+// getByMonitorSystem gets the catalogue of given monitor system
+func (pcs *PrometheusCatalogueService) getByMonitorSystem(monitorSystem depmeta.MonitorSystem) error {
+	overrides, err := pcs.PrometheusCatalogueRepo.GetOverridesByMonitorSystemID(monitorSystem.Identity())
+	if err != nil {
+		return err
+	}
+	catalogue, err := NewPrometheusCatalogue(monitorSystem.GetSystemType(), overrides)
+	if err != nil {
+		return err
+	}
+
+	pcs.MonitorSystemID = monitorSystem.Identity()
+	pcs.SystemType = monitorSystem.GetSystemType()
+	pcs.Templates = catalogue.GetTemplates()
+	pcs.Overrides = overrides
+	pcs.catalogue = catalogue
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package healthcheck
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPrometheusCatalogueMySQLServer() *metadata.MySQLServerInfo {
+	return &metadata.MySQLServerInfo{
+		ServiceName: "192-168-10-219-3306",
+		HostIP:      "192.168.10.219",
+		PortNum:     3306,
+	}
+}
+
+func TestPrometheusCatalogueAll(t *testing.T) {
+	TestPrometheusCatalogue_NewPrometheusCatalogue(t)
+	TestPrometheusCatalogue_Render(t)
+	TestPrometheusCatalogue_Validate(t)
+	TestPrometheusCatalogue_getPrometheusRange(t)
+	TestPrometheusCatalogue_getPrometheusStep(t)
+}
+
+func TestPrometheusCatalogue_NewPrometheusCatalogue(t *testing.T) {
+	asst := assert.New(t)
+
+	for _, systemType := range []int{MonitorSystemTypePMMV1, MonitorSystemTypePMMV2, MonitorSystemTypePrometheus} {
+		catalogue, err := NewPrometheusCatalogueWithDefault(systemType)
+		asst.Nil(err, common.CombineMessageWithError("test NewPrometheusCatalogueWithDefault() failed", err))
+		asst.Equal(len(defaultPrometheusCatalogues[MonitorSystemTypePMMV2]), len(catalogue.GetTemplates()),
+			"test NewPrometheusCatalogueWithDefault() failed, system type: %d", systemType)
+		asst.Nil(catalogue.Validate(), "test NewPrometheusCatalogueWithDefault() failed, system type: %d", systemType)
+	}
+	_, err := NewPrometheusCatalogueWithDefault(4)
+	asst.NotNil(err, "test NewPrometheusCatalogueWithDefault() failed")
+
+	// the override of the unknown name is ignored, and the default catalogue is not changed
+	catalogue, err := NewPrometheusCatalogue(MonitorSystemTypePrometheus, []healthcheck.PrometheusCatalogueOverride{
+		NewPrometheusCatalogueOverride(1, PrometheusCatalogueServiceValue, "${.HostIP}:${.PortNum}"),
+		NewPrometheusCatalogueOverride(1, "unknown", "up"),
+	})
+	asst.Nil(err, common.CombineMessageWithError("test NewPrometheusCatalogue() failed", err))
+	asst.Equal("${.HostIP}:${.PortNum}", catalogue.GetTemplates()[PrometheusCatalogueServiceValue], "test NewPrometheusCatalogue() failed")
+	asst.NotContains(catalogue.GetTemplates(), "unknown", "test NewPrometheusCatalogue() failed")
+	asst.Equal("${.HostIP}:9104", defaultPrometheusCatalogues[MonitorSystemTypePrometheus][PrometheusCatalogueServiceValue],
+		"test NewPrometheusCatalogue() failed")
+}
+
+func TestPrometheusCatalogue_Render(t *testing.T) {
+	asst := assert.New(t)
+
+	mysqlServer := newTestPrometheusCatalogueMySQLServer()
+	// pmm 2.x
+	catalogue, err := NewPrometheusCatalogueWithDefault(MonitorSystemTypePMMV2)
+	asst.Nil(err, common.CombineMessageWithError("test Render() failed", err))
+	query, err := catalogue.Render(PrometheusCatalogueReplicationLag, mysqlServer, time.Minute, nil)
+	asst.Nil(err, common.CombineMessageWithError("test Render() failed", err))
+	asst.True(strings.Contains(query, `mysql_slave_status_seconds_behind_master{service_name=~"192-168-10-219-3306"}`), "test Render() failed, query: %s", query)
+	query, err = catalogue.Render(PrometheusCatalogueDiskCapacityUsage, mysqlServer, time.Minute, []string{"/", "/data"})
+	asst.Nil(err, common.CombineMessageWithError("test Render() failed", err))
+	asst.True(strings.Contains(query, `node_name=~"192-168-10-219-3306", mountpoint=~"(/|/data)"`), "test Render() failed, query: %s", query)
+	// plain prometheus with the overridden labels
+	catalogue, err = NewPrometheusCatalogue(MonitorSystemTypePrometheus, []healthcheck.PrometheusCatalogueOverride{
+		NewPrometheusCatalogueOverride(1, PrometheusCatalogueServiceLabel, "target"),
+		NewPrometheusCatalogueOverride(1, PrometheusCatalogueServiceValue, "${.HostIP}:${.PortNum}"),
+	})
+	asst.Nil(err, common.CombineMessageWithError("test Render() failed", err))
+	query, err = catalogue.Render(PrometheusCatalogueConnectionUsage, mysqlServer, time.Minute, nil)
+	asst.Nil(err, common.CombineMessageWithError("test Render() failed", err))
+	asst.True(strings.Contains(query, `avg by (target) (max_over_time(mysql_global_status_threads_connected{target=~"192.168.10.219:3306"}[60s])`),
+		"test Render() failed, query: %s", query)
+	query, err = catalogue.Render(PrometheusCatalogueCPUUsage, mysqlServer, time.Minute, nil)
+	asst.Nil(err, common.CombineMessageWithError("test Render() failed", err))
+	asst.True(strings.Contains(query, `node_cpu_seconds_total{instance=~"192.168.10.219:9100"`), "test Render() failed, query: %s", query)
+	_, err = catalogue.Render("unknown", mysqlServer, time.Minute, nil)
+	asst.NotNil(err, "test Render() failed")
+}
+
+func TestPrometheusCatalogue_Validate(t *testing.T) {
+	asst := assert.New(t)
+
+	for _, tmpl := range []string{
+		// unknown field
+		`up{instance=~"${.Unknown}"}`,
+		// not closed
+		`up{instance=~"${.HostIP"}`,
+	} {
+		catalogue, err := NewPrometheusCatalogue(MonitorSystemTypePrometheus, []healthcheck.PrometheusCatalogueOverride{
+			NewPrometheusCatalogueOverride(1, PrometheusCatalogueCPUUsage, tmpl),
+		})
+		asst.Nil(err, common.CombineMessageWithError("test Validate() failed", err))
+		asst.NotNil(catalogue.Validate(), "test Validate() failed, template: %s", tmpl)
+	}
+}
+
+func TestPrometheusCatalogue_getPrometheusRange(t *testing.T) {
+	asst := assert.New(t)
+
+	asst.Equal("300s", getPrometheusRange(10*time.Second, prometheusMinRange), "test getPrometheusRange() failed")
+	asst.Equal("20s", getPrometheusRange(10*time.Second, prometheusMinShortRange), "test getPrometheusRange() failed")
+	asst.Equal("3600s", getPrometheusRange(time.Hour, prometheusMinRange), "test getPrometheusRange() failed")
+	asst.Equal("3600s", getPrometheusRange(time.Hour, prometheusMinShortRange), "test getPrometheusRange() failed")
+}
+
+func TestPrometheusCatalogue_getPrometheusStep(t *testing.T) {
+	asst := assert.New(t)
+
+	endTime := time.Now()
+	// the step is kept if the points do not exceed the limit
+	asst.Equal(10*time.Second, getPrometheusStep(endTime.Add(-time.Hour), endTime, 10*time.Second), "test getPrometheusStep() failed")
+	// 7 days with 10 seconds step contains 60480 points, the step is enlarged to cover the whole time window
+	step := getPrometheusStep(endTime.Add(-7*24*time.Hour), endTime, 10*time.Second)
+	asst.Equal(55*time.Second, step, "test getPrometheusStep() failed")
+	asst.True(int64(7*24*time.Hour/step) <= prometheusMaxPoints, "test getPrometheusStep() failed")
+}
@@ -6,6 +6,77 @@ const (
 		select variable_name, variable_value
 		from %s.global_variables
 		where variable_name in (%s);
+    `
+	applicationMySQLSlaveStatus  = `show slave status;`
+	applicationMySQLGTIDExecuted = `select @@global.gtid_executed as gtid_executed;`
+	applicationMySQLLockWaits    = `
+		select wait_age_secs,
+			   locked_table,
+			   ifnull(locked_index, '')    as locked_index,
+			   locked_type,
+			   waiting_pid,
+			   ifnull(waiting_query, '')   as waiting_query,
+			   blocking_pid,
+			   ifnull(blocking_query, '')  as blocking_query
+		from sys.innodb_lock_waits
+		order by wait_age_secs desc;
+    `
+	applicationMySQLInnoDBStatus  = `show engine innodb status;`
+	applicationMySQLUnusedIndexes = `
+		select 'unused_index' as finding_type,
+			   object_schema  as table_schema,
+			   object_name    as table_name,
+			   index_name,
+			   ''             as columns,
+			   ''             as dominant_index_name,
+			   ''             as engine
+		from sys.schema_unused_indexes
+		where object_schema not in ('mysql', 'sys', 'information_schema', 'performance_schema')
+		order by object_schema, object_name, index_name;
+    `
+	applicationMySQLRedundantIndexes = `
+		select 'redundant_index'       as finding_type,
+			   table_schema,
+			   table_name,
+			   redundant_index_name    as index_name,
+			   redundant_index_columns as columns,
+			   dominant_index_name,
+			   ''                      as engine
+		from sys.schema_redundant_indexes
+		where table_schema not in ('mysql', 'sys', 'information_schema', 'performance_schema')
+		order by table_schema, table_name, redundant_index_name;
+    `
+	applicationMySQLTablesWithoutPrimaryKey = `
+		select 'missing_primary_key' as finding_type,
+			   t.table_schema,
+			   t.table_name,
+			   ''                    as index_name,
+			   ''                    as columns,
+			   ''                    as dominant_index_name,
+			   ifnull(t.engine, '')  as engine
+		from information_schema.tables t
+			left join information_schema.table_constraints tc
+			on t.table_schema = tc.table_schema
+				and t.table_name = tc.table_name
+				and tc.constraint_type = 'PRIMARY KEY'
+		where t.table_type = 'BASE TABLE'
+		  and tc.constraint_name is null
+		  and t.table_schema not in ('mysql', 'sys', 'information_schema', 'performance_schema')
+		order by t.table_schema, t.table_name;
+    `
+	applicationMySQLNonInnoDBTables = `
+		select 'non_innodb_table' as finding_type,
+			   table_schema,
+			   table_name,
+			   ''                 as index_name,
+			   ''                 as columns,
+			   ''                 as dominant_index_name,
+			   engine
+		from information_schema.tables
+		where table_type = 'BASE TABLE'
+		  and engine <> 'InnoDB'
+		  and table_schema not in ('mysql', 'sys', 'information_schema', 'performance_schema')
+		order by table_schema, table_name;
     `
 	applicationMySQLTableSize = `
     	select table_schema,
@@ -17,90 +88,162 @@ const (
 		  and table_rows > ?
 		order by table_rows desc;
     `
-	// prometheus
-	PrometheusCPUUsageV1 = `
+	// prometheus, the queries are the templates of the prometheus catalogue, see prometheus_catalogue.go
+	prometheusCPUUsageV1 = `
 		clamp_max(sum by () ((avg by (mode) (
-		(clamp_max(rate(node_cpu{instance=~"%s",mode!="idle",mode!="iowait"}[5m]),1)) or
-		(clamp_max(irate(node_cpu{instance=~"%s",mode!="idle",mode!="iowait"}[5m]),1)) )) *100 or
+		(clamp_max(rate(node_cpu{${.NodeLabel}=~"${.NodeValue}",mode!="idle",mode!="iowait"}[${.Range}]),1)) or
+		(clamp_max(irate(node_cpu{${.NodeLabel}=~"${.NodeValue}",mode!="idle",mode!="iowait"}[${.Range}]),1)) )) *100 or
 		sum by () (
-		avg_over_time(node_cpu_average{instance=~"%s",mode!="total",mode!="idle"}[5m]) or
-		avg_over_time(node_cpu_average{instance=~"%s",mode!="total",mode!="idle"}[5m])) unless
-		(avg_over_time(node_cpu_average{instance=~"%s",mode="total",job="rds-basic"}[5m]) or
-		avg_over_time(node_cpu_average{instance=~"%s",mode="total",job="rds-basic"}[5m]))
+		avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode!="total",mode!="idle"}[${.Range}]) or
+		avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode!="total",mode!="idle"}[${.Range}])) unless
+		(avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode="total",job="rds-basic"}[${.Range}]) or
+		avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode="total",job="rds-basic"}[${.Range}]))
 		),100)
     `
-	PrometheusCPUUsageV2 = `
+	prometheusCPUUsageV2 = `
 		clamp_max(sum by () ((avg by (mode) ( 
-		(clamp_max(rate(node_cpu_seconds_total{node_name=~"%s",mode!="idle",mode!="iowait"}[5m]),1)) or 
-		(clamp_max(irate(node_cpu_seconds_total{node_name=~"%s",mode!="idle",mode!="iowait"}[5m]),1)) )) *100 or 
+		(clamp_max(rate(node_cpu_seconds_total{${.NodeLabel}=~"${.NodeValue}",mode!="idle",mode!="iowait"}[${.Range}]),1)) or 
+		(clamp_max(irate(node_cpu_seconds_total{${.NodeLabel}=~"${.NodeValue}",mode!="idle",mode!="iowait"}[${.Range}]),1)) )) *100 or 
 		sum by () (
-		avg_over_time(node_cpu_average{node_name=~"%s",mode!="total",mode!="idle"}[5m]) or 
-		avg_over_time(node_cpu_average{node_name=~"%s",mode!="total",mode!="idle"}[5m])) unless
-		(avg_over_time(node_cpu_average{node_name=~"%s",mode="total",job="rds-basic"}[5m]) or 
-		avg_over_time(node_cpu_average{node_name=~"%s",mode="total",job="rds-basic"}[5m]))
+		avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode!="total",mode!="idle"}[${.Range}]) or 
+		avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode!="total",mode!="idle"}[${.Range}])) unless
+		(avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode="total",job="rds-basic"}[${.Range}]) or 
+		avg_over_time(node_cpu_average{${.NodeLabel}=~"${.NodeValue}",mode="total",job="rds-basic"}[${.Range}]))
 		),100)
     `
-	PrometheusFileSystemV1 = `
-		node_filesystem_files{instance=~"%s",fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}
+	prometheusFileSystemV1 = `
+		node_filesystem_files{${.NodeLabel}=~"${.NodeValue}",fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}
     `
-	PrometheusFileSystemV2 = `
-		node_filesystem_files{node_name=~"%s",fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}
+	prometheusFileSystemV2 = `
+		node_filesystem_files{${.NodeLabel}=~"${.NodeValue}",fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}
     `
-	PrometheusIOUtilV1 = `
-		avg by (instance) (rate(node_disk_io_time_ms{instance=~"%s"}[20s])/1000 or
-		irate(node_disk_io_time_ms{instance=~"%s"}[5m])/1000)
+	prometheusIOUtilV1 = `
+		avg by (${.NodeLabel}) (rate(node_disk_io_time_ms{${.NodeLabel}=~"${.NodeValue}"}[${.ShortRange}])/1000 or
+		irate(node_disk_io_time_ms{${.NodeLabel}=~"${.NodeValue}"}[${.Range}])/1000)
     `
-	PrometheusIOUtilV2 = `
-		avg by (node_name) (rate(node_disk_io_time_seconds_total{node_name=~"%s"}[20s]) or
-		irate(node_disk_io_time_seconds_total{node_name=~"%s"}[5m]) or
-		(max_over_time(rdsosmetrics_diskIO_util{node_name=~"%s"}[20s]) or
-		max_over_time(rdsosmetrics_diskIO_util{node_name=~"%s"}[5m]))/100)
+	prometheusIOUtilV2 = `
+		avg by (${.NodeLabel}) (rate(node_disk_io_time_seconds_total{${.NodeLabel}=~"${.NodeValue}"}[${.ShortRange}]) or
+		irate(node_disk_io_time_seconds_total{${.NodeLabel}=~"${.NodeValue}"}[${.Range}]) or
+		(max_over_time(rdsosmetrics_diskIO_util{${.NodeLabel}=~"${.NodeValue}"}[${.ShortRange}]) or
+		max_over_time(rdsosmetrics_diskIO_util{${.NodeLabel}=~"${.NodeValue}"}[${.Range}]))/100)
     `
-	PrometheusDiskCapacityV1 = `
-		1 - node_filesystem_free{instance=~"%s", mountpoint=~"(%s)", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"} /
-		node_filesystem_size{instance=~"%s", mountpoint=~"(%s)", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}
+	prometheusDiskCapacityV1 = `
+		1 - node_filesystem_free{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"} /
+		node_filesystem_size{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}
     `
-	PrometheusDiskCapacityV2 = `
-		avg by (node_name, mountpoint) (1 - (max_over_time(node_filesystem_free_bytes{node_name=~"%s", mountpoint=~"(%s)", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[20s]) or
-		max_over_time(node_filesystem_free_bytes{node_name=~"%s", mountpoint=~"(%s)", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[5m])) /
-		(max_over_time(node_filesystem_size_bytes{node_name=~"%s", mountpoint=~"(%s)", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[20s]) or
-		max_over_time(node_filesystem_size_bytes{node_name=~"%s", mountpoint=~"(%s)", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[5m])))
+	prometheusDiskCapacityV2 = `
+		avg by (${.NodeLabel}, mountpoint) (1 - (max_over_time(node_filesystem_free_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.ShortRange}]) or
+		max_over_time(node_filesystem_free_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.Range}])) /
+		(max_over_time(node_filesystem_size_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.ShortRange}]) or
+		max_over_time(node_filesystem_size_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.Range}])))
    `
-	PrometheusConnectionUsageV1 = `
-		avg by (instance) (max(max_over_time(mysql_global_status_threads_connected{instance=~"%s"}[20s]) or
-		max_over_time(mysql_global_status_threads_connected{instance=~"%s"}[5m]))) /
-		avg by (instance) (max(max_over_time(mysql_global_variables_max_connections{instance=~"%s"}[20s]) or
-		max_over_time(mysql_global_variables_max_connections{instance=~"%s"}[5m])))
-    `
-	PrometheusConnectionUsageV2 = `
-		avg by (service_name) (max(max_over_time(mysql_global_status_threads_connected{service_name=~"%s"}[20s]) or
-		max_over_time(mysql_global_status_threads_connected{service_name=~"%s"}[5m]))) /
-		avg by (service_name) (max_over_time(mysql_global_variables_max_connections{service_name=~"%s"}[20s]) or
-		max_over_time(mysql_global_variables_max_connections{service_name=~"%s"}[5m]))
-    `
-	PrometheusAverageActiveSessionPercentsV1 = `
-		avg by (instance) (avg_over_time(mysql_global_status_threads_running{instance=~"%s"}[20s]) or
-		avg_over_time(mysql_global_status_threads_running{instance=~"%s"}[5m]))/
-		avg by (instance) (max_over_time(mysql_global_status_threads_connected{instance=~"%s"}[20s]) or
-		max_over_time(mysql_global_status_threads_connected{instance=~"%s"}[5m]))
-    `
-	PrometheusAverageActiveSessionPercentsV2 = `
-		avg by (service_name) (avg_over_time(mysql_global_status_threads_running{service_name=~"%s"}[20s]) or
-		avg_over_time(mysql_global_status_threads_running{service_name=~"%s"}[5m]))/
-		avg by (service_name) (max_over_time(mysql_global_status_threads_connected{service_name=~"%s"}[20s]) or
-		max_over_time(mysql_global_status_threads_connected{service_name=~"%s"}[5m]))
-    `
-	PrometheusCacheMissRatioV1 = `
-		avg by (instance) ((rate(mysql_global_status_innodb_buffer_pool_reads{instance=~"%s"}[5m]) or
-		irate(mysql_global_status_innodb_buffer_pool_reads{instance=~"%s"}[5m])) /
-		(rate(mysql_global_status_innodb_buffer_pool_read_requests{instance=~"%s"}[5m]) or
-		irate(mysql_global_status_innodb_buffer_pool_read_requests{instance=~"%s"}[5m])))
-    `
-	PrometheusCacheMissRatioV2 = `
-		avg by (service_name) ((rate(mysql_global_status_innodb_buffer_pool_reads{service_name=~"%s"}[5m]) or
-		irate(mysql_global_status_innodb_buffer_pool_reads{service_name=~"%s"}[5m])) /
-		(rate(mysql_global_status_innodb_buffer_pool_read_requests{service_name=~"%s"}[5m]) or
-		irate(mysql_global_status_innodb_buffer_pool_read_requests{service_name=~"%s"}[5m])))
+	prometheusConnectionUsageV1 = `
+		avg by (${.ServiceLabel}) (max(max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))) /
+		avg by (${.ServiceLabel}) (max(max_over_time(mysql_global_variables_max_connections{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_variables_max_connections{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])))
+    `
+	prometheusConnectionUsageV2 = `
+		avg by (${.ServiceLabel}) (max(max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))) /
+		avg by (${.ServiceLabel}) (max_over_time(mysql_global_variables_max_connections{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_variables_max_connections{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))
+    `
+	prometheusAverageActiveSessionPercentsV1 = `
+		avg by (${.ServiceLabel}) (avg_over_time(mysql_global_status_threads_running{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		avg_over_time(mysql_global_status_threads_running{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))/
+		avg by (${.ServiceLabel}) (max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))
+    `
+	prometheusAverageActiveSessionPercentsV2 = `
+		avg by (${.ServiceLabel}) (avg_over_time(mysql_global_status_threads_running{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		avg_over_time(mysql_global_status_threads_running{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))/
+		avg by (${.ServiceLabel}) (max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))
+    `
+	prometheusCacheMissRatioV1 = `
+		avg by (${.ServiceLabel}) ((rate(mysql_global_status_innodb_buffer_pool_reads{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_buffer_pool_reads{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])) /
+		(rate(mysql_global_status_innodb_buffer_pool_read_requests{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_buffer_pool_read_requests{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])))
+    `
+	prometheusCacheMissRatioV2 = `
+		avg by (${.ServiceLabel}) ((rate(mysql_global_status_innodb_buffer_pool_reads{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_buffer_pool_reads{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])) /
+		(rate(mysql_global_status_innodb_buffer_pool_read_requests{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_buffer_pool_read_requests{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])))
+    `
+	prometheusReplicationLagV1 = `
+		max by (${.ServiceLabel}) (max_over_time(mysql_slave_status_seconds_behind_master{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		mysql_slave_status_seconds_behind_master{${.ServiceLabel}=~"${.ServiceValue}"})
+    `
+	prometheusReplicationLagV2 = `
+		max by (${.ServiceLabel}) (max_over_time(mysql_slave_status_seconds_behind_master{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		mysql_slave_status_seconds_behind_master{${.ServiceLabel}=~"${.ServiceValue}"})
+    `
+	prometheusInnoDBRowLockWaitsV1 = `
+		rate(mysql_global_status_innodb_row_lock_waits{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_row_lock_waits{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])
+    `
+	prometheusInnoDBRowLockWaitsV2 = `
+		rate(mysql_global_status_innodb_row_lock_waits{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_row_lock_waits{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])
+    `
+	prometheusInnoDBRowLockTimeV1 = `
+		rate(mysql_global_status_innodb_row_lock_time{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_row_lock_time{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])
+    `
+	prometheusInnoDBRowLockTimeV2 = `
+		rate(mysql_global_status_innodb_row_lock_time{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_row_lock_time{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])
+    `
+	prometheusCPUUsagePlain = `
+		clamp_max(sum by () (avg by (mode) (
+		clamp_max(rate(node_cpu_seconds_total{${.NodeLabel}=~"${.NodeValue}",mode!="idle",mode!="iowait"}[${.Range}]),1) or
+		clamp_max(irate(node_cpu_seconds_total{${.NodeLabel}=~"${.NodeValue}",mode!="idle",mode!="iowait"}[${.Range}]),1))) *100,100)
+    `
+	prometheusFileSystemPlain = `
+		node_filesystem_files{${.NodeLabel}=~"${.NodeValue}",fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}
+    `
+	prometheusIOUtilPlain = `
+		avg by (${.NodeLabel}) (rate(node_disk_io_time_seconds_total{${.NodeLabel}=~"${.NodeValue}"}[${.ShortRange}]) or
+		irate(node_disk_io_time_seconds_total{${.NodeLabel}=~"${.NodeValue}"}[${.Range}]))
+    `
+	prometheusDiskCapacityPlain = `
+		avg by (${.NodeLabel}, mountpoint) (1 - (max_over_time(node_filesystem_avail_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.ShortRange}]) or
+		max_over_time(node_filesystem_avail_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.Range}])) /
+		(max_over_time(node_filesystem_size_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.ShortRange}]) or
+		max_over_time(node_filesystem_size_bytes{${.NodeLabel}=~"${.NodeValue}", mountpoint=~"(${.MountPoints})", fstype!~"rootfs|selinuxfs|autofs|rpc_pipefs|tmpfs"}[${.Range}])))
+    `
+	prometheusConnectionUsagePlain = `
+		avg by (${.ServiceLabel}) (max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])) /
+		avg by (${.ServiceLabel}) (max_over_time(mysql_global_variables_max_connections{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_variables_max_connections{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))
+    `
+	prometheusAverageActiveSessionPercentsPlain = `
+		avg by (${.ServiceLabel}) (avg_over_time(mysql_global_status_threads_running{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		avg_over_time(mysql_global_status_threads_running{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))/
+		avg by (${.ServiceLabel}) (max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.ShortRange}]) or
+		max_over_time(mysql_global_status_threads_connected{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]))
+    `
+	prometheusCacheMissRatioPlain = `
+		avg by (${.ServiceLabel}) ((rate(mysql_global_status_innodb_buffer_pool_reads{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_buffer_pool_reads{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])) /
+		(rate(mysql_global_status_innodb_buffer_pool_read_requests{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_buffer_pool_read_requests{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])))
+    `
+	prometheusReplicationLagPlain = `
+		max by (${.ServiceLabel}) (max_over_time(mysql_slave_status_seconds_behind_master{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		mysql_slave_status_seconds_behind_master{${.ServiceLabel}=~"${.ServiceValue}"})
+    `
+	prometheusInnoDBRowLockWaitsPlain = `
+		rate(mysql_global_status_innodb_row_lock_waits{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_row_lock_waits{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])
+    `
+	prometheusInnoDBRowLockTimePlain = `
+		rate(mysql_global_status_innodb_row_lock_time{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}]) or
+		irate(mysql_global_status_innodb_row_lock_time{${.ServiceLabel}=~"${.ServiceValue}"}[${.Range}])
     `
 	// query
 	MonitorMySQLQuery = `
@@ -168,4 +311,19 @@ const (
 							group by queryid) m
 						   on sm.sql_id = m.sql_id;
     `
+
+	// middleware
+	middlewarePing     = `select 1;`
+	middlewareBackends = `
+		select hostgroup as hostgroup_id,
+			   srv_host  as host_ip,
+			   srv_port  as port_num,
+			   status,
+			   ConnUsed  as conn_used,
+			   ConnFree  as conn_free,
+			   ConnOK    as conn_ok,
+			   ConnERR   as conn_err
+		from stats_mysql_connection_pool
+		order by hostgroup, srv_host, srv_port;
+    `
 )
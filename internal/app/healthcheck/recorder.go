@@ -0,0 +1,514 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/romberli/das/internal/app/query"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	depquery "github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	recorderGoldenFileMode = 0644
+	recorderJSONIndent     = "  "
+)
+
+var (
+	_ healthcheck.ApplicationMySQLRepo = (*RecordingApplicationMySQLRepo)(nil)
+	_ healthcheck.PrometheusRepo       = (*RecordingPrometheusRepo)(nil)
+	_ healthcheck.QueryRepo            = (*RecordingQueryRepo)(nil)
+)
+
+// Recorder records the responses of the application mysql, prometheus and query repositories to a snapshot,
+// the snapshot could be saved as a golden file and replayed with the snapshot repositories later
+type Recorder struct {
+	startTime            time.Time
+	endTime              time.Time
+	step                 time.Duration
+	applicationMySQLRepo *RecordingApplicationMySQLRepo
+	prometheusRepo       *RecordingPrometheusRepo
+	queryRepo            *RecordingQueryRepo
+}
+
+// NewRecorder returns a new *Recorder which decorates given repositories
+func NewRecorder(startTime, endTime time.Time, step time.Duration, applicationMySQLRepo healthcheck.ApplicationMySQLRepo,
+	prometheusRepo healthcheck.PrometheusRepo, queryRepo healthcheck.QueryRepo) *Recorder {
+	return &Recorder{
+		startTime:            startTime,
+		endTime:              endTime,
+		step:                 step,
+		applicationMySQLRepo: NewRecordingApplicationMySQLRepo(applicationMySQLRepo),
+		prometheusRepo:       NewRecordingPrometheusRepo(prometheusRepo),
+		queryRepo:            NewRecordingQueryRepo(queryRepo),
+	}
+}
+
+// GetApplicationMySQLRepo returns the recording application mysql repository
+func (r *Recorder) GetApplicationMySQLRepo() *RecordingApplicationMySQLRepo {
+	return r.applicationMySQLRepo
+}
+
+// GetPrometheusRepo returns the recording prometheus repository
+func (r *Recorder) GetPrometheusRepo() *RecordingPrometheusRepo {
+	return r.prometheusRepo
+}
+
+// GetQueryRepo returns the recording query repository
+func (r *Recorder) GetQueryRepo() *RecordingQueryRepo {
+	return r.queryRepo
+}
+
+// RecordPrimaryGTIDExecuted decorates the function which returns the executed gtid set of the primary,
+// so that the executed gtid set of the primary could be recorded too
+func (r *Recorder) RecordPrimaryGTIDExecuted(getPrimaryGTIDExecuted func(hostIP string, portNum int) (string, error),
+) func(hostIP string, portNum int) (string, error) {
+	return func(hostIP string, portNum int) (string, error) {
+		primaryGTIDExecuted, err := getPrimaryGTIDExecuted(hostIP, portNum)
+		if err != nil {
+			return constant.EmptyString, err
+		}
+
+		r.GetApplicationMySQLRepo().recordPrimaryGTIDExecuted(primaryGTIDExecuted)
+
+		return primaryGTIDExecuted, nil
+	}
+}
+
+// Snapshot returns the snapshot of the recorded responses
+func (r *Recorder) Snapshot() *Snapshot {
+	return &Snapshot{
+		StartTime:   r.startTime.Format(constant.TimeLayoutSecond),
+		EndTime:     r.endTime.Format(constant.TimeLayoutSecond),
+		Step:        r.step.String(),
+		MySQL:       r.GetApplicationMySQLRepo().getSnapshot(),
+		Prometheus:  r.GetPrometheusRepo().getSnapshot(),
+		SlowQueries: r.GetQueryRepo().getSlowQueries(),
+		startTime:   r.startTime,
+		endTime:     r.endTime,
+		step:        r.step,
+	}
+}
+
+// Save saves the snapshot of the recorded responses to the golden file
+func (r *Recorder) Save(fileName string) error {
+	data, err := json.MarshalIndent(r.Snapshot(), constant.EmptyString, recorderJSONIndent)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fileName, data, recorderGoldenFileMode)
+}
+
+// NewSnapshotWithFile reads the golden file and returns a new *Snapshot, it is used to replay the recorded responses
+func NewSnapshotWithFile(fileName string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewSnapshotWithJSON(data)
+}
+
+// RecordingApplicationMySQLRepo records the responses of the application mysql repository
+type RecordingApplicationMySQLRepo struct {
+	repo     healthcheck.ApplicationMySQLRepo
+	mutex    sync.Mutex
+	snapshot *MySQLSnapshot
+}
+
+// NewRecordingApplicationMySQLRepo returns a new *RecordingApplicationMySQLRepo
+func NewRecordingApplicationMySQLRepo(repo healthcheck.ApplicationMySQLRepo) *RecordingApplicationMySQLRepo {
+	return &RecordingApplicationMySQLRepo{
+		repo:     repo,
+		snapshot: &MySQLSnapshot{},
+	}
+}
+
+// getSnapshot returns the recorded responses
+func (ramr *RecordingApplicationMySQLRepo) getSnapshot() *MySQLSnapshot {
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	snapshot := *ramr.snapshot
+
+	return &snapshot
+}
+
+// Close closes the decorated repository
+func (ramr *RecordingApplicationMySQLRepo) Close() error {
+	return ramr.repo.Close()
+}
+
+// GetVariables gets the database variables by items and records them,
+// the variables of multiple calls are merged as the replay filters them by the items
+func (ramr *RecordingApplicationMySQLRepo) GetVariables(items []string) ([]healthcheck.Variable, error) {
+	variables, err := ramr.repo.GetVariables(items)
+	if err != nil {
+		return nil, err
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	for _, variable := range variables {
+		exists := false
+		for _, recorded := range ramr.snapshot.Variables {
+			if strings.EqualFold(recorded.GetName(), variable.GetName()) {
+				recorded.VariableValue = variable.GetValue()
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			ramr.snapshot.Variables = append(ramr.snapshot.Variables, NewGlobalVariable(variable.GetName(), variable.GetValue()))
+		}
+	}
+
+	return variables, nil
+}
+
+// GetMySQLDirs gets the mysql data directory and binlog directory and records them
+func (ramr *RecordingApplicationMySQLRepo) GetMySQLDirs() ([]string, error) {
+	dirs, err := ramr.repo.GetMySQLDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	ramr.snapshot.MySQLDirs = dirs
+
+	return dirs, nil
+}
+
+// IsReplica returns if the mysql server is a replica, the replay derives it from the replication status
+func (ramr *RecordingApplicationMySQLRepo) IsReplica() (bool, error) {
+	return ramr.repo.IsReplica()
+}
+
+// GetReplicationStatus returns the replication status and records it
+func (ramr *RecordingApplicationMySQLRepo) GetReplicationStatus() (healthcheck.ReplicationStatus, error) {
+	rs, err := ramr.repo.GetReplicationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	ramr.snapshot.ReplicationStatus = nil
+	if rs != nil {
+		ramr.snapshot.ReplicationStatus = &ReplicationStatus{
+			MasterHost:          rs.GetMasterHost(),
+			MasterPort:          rs.GetMasterPort(),
+			IOThreadRunning:     rs.IsIOThreadRunning(),
+			SQLThreadRunning:    rs.IsSQLThreadRunning(),
+			SecondsBehindMaster: rs.GetSecondsBehindMaster(),
+			LastIOError:         rs.GetLastIOError(),
+			LastSQLError:        rs.GetLastSQLError(),
+			ExecutedGTIDSet:     rs.GetExecutedGTIDSet(),
+		}
+	}
+
+	return rs, nil
+}
+
+// GetGTIDExecuted returns the executed gtid set and records it
+func (ramr *RecordingApplicationMySQLRepo) GetGTIDExecuted() (string, error) {
+	gtidExecuted, err := ramr.repo.GetGTIDExecuted()
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	ramr.snapshot.GTIDExecuted = gtidExecuted
+
+	return gtidExecuted, nil
+}
+
+// recordPrimaryGTIDExecuted records the executed gtid set of the primary
+func (ramr *RecordingApplicationMySQLRepo) recordPrimaryGTIDExecuted(primaryGTIDExecuted string) {
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	ramr.snapshot.PrimaryGTIDExecuted = primaryGTIDExecuted
+}
+
+// GetLockWaits returns the innodb lock waits and records them
+func (ramr *RecordingApplicationMySQLRepo) GetLockWaits() ([]healthcheck.LockWait, error) {
+	lockWaits, err := ramr.repo.GetLockWaits()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*LockWait, len(lockWaits))
+	for i, lw := range lockWaits {
+		recorded[i] = &LockWait{
+			WaitAgeSecs:   lw.GetWaitAgeSecs(),
+			LockedTable:   lw.GetLockedTable(),
+			LockedIndex:   lw.GetLockedIndex(),
+			LockedType:    lw.GetLockedType(),
+			WaitingPID:    lw.GetWaitingPID(),
+			WaitingQuery:  lw.GetWaitingQuery(),
+			BlockingPID:   lw.GetBlockingPID(),
+			BlockingQuery: lw.GetBlockingQuery(),
+		}
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	ramr.snapshot.LockWaits = recorded
+
+	return lockWaits, nil
+}
+
+// GetInnoDBStatus returns the output of show engine innodb status and records it
+func (ramr *RecordingApplicationMySQLRepo) GetInnoDBStatus() (string, error) {
+	innodbStatus, err := ramr.repo.GetInnoDBStatus()
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	ramr.snapshot.InnoDBStatus = innodbStatus
+
+	return innodbStatus, nil
+}
+
+// GetUnusedIndexes returns the unused indexes and records them
+func (ramr *RecordingApplicationMySQLRepo) GetUnusedIndexes() ([]healthcheck.IndexFinding, error) {
+	return ramr.recordIndexFindings(ramr.repo.GetUnusedIndexes, &ramr.snapshot.UnusedIndexes)
+}
+
+// GetRedundantIndexes returns the redundant indexes and records them
+func (ramr *RecordingApplicationMySQLRepo) GetRedundantIndexes() ([]healthcheck.IndexFinding, error) {
+	return ramr.recordIndexFindings(ramr.repo.GetRedundantIndexes, &ramr.snapshot.RedundantIndexes)
+}
+
+// GetTablesWithoutPrimaryKey returns the tables which do not have a primary key and records them
+func (ramr *RecordingApplicationMySQLRepo) GetTablesWithoutPrimaryKey() ([]healthcheck.IndexFinding, error) {
+	return ramr.recordIndexFindings(ramr.repo.GetTablesWithoutPrimaryKey, &ramr.snapshot.TablesWithoutPrimaryKey)
+}
+
+// GetNonInnoDBTables returns the tables of which the storage engine is not innodb and records them
+func (ramr *RecordingApplicationMySQLRepo) GetNonInnoDBTables() ([]healthcheck.IndexFinding, error) {
+	return ramr.recordIndexFindings(ramr.repo.GetNonInnoDBTables, &ramr.snapshot.NonInnoDBTables)
+}
+
+// GetLargeTables returns the large tables and records them
+func (ramr *RecordingApplicationMySQLRepo) GetLargeTables() ([]healthcheck.Table, error) {
+	tables, err := ramr.repo.GetLargeTables()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*Table, len(tables))
+	for i, table := range tables {
+		recorded[i] = NewTable(table.GetSchema(), table.GetName(), table.GetRows(), table.GetSize())
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	ramr.snapshot.Tables = recorded
+
+	return tables, nil
+}
+
+// recordIndexFindings gets the index findings with given function and records them to the destination
+func (ramr *RecordingApplicationMySQLRepo) recordIndexFindings(getFindings func() ([]healthcheck.IndexFinding, error),
+	dest *[]*IndexFinding) ([]healthcheck.IndexFinding, error) {
+	findings, err := getFindings()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*IndexFinding, len(findings))
+	for i, finding := range findings {
+		recorded[i] = &IndexFinding{
+			FindingType:       finding.GetFindingType(),
+			TableSchema:       finding.GetTableSchema(),
+			TableName:         finding.GetTableName(),
+			IndexName:         finding.GetIndexName(),
+			Columns:           finding.GetColumns(),
+			DominantIndexName: finding.GetDominantIndexName(),
+			Engine:            finding.GetEngine(),
+		}
+	}
+
+	ramr.mutex.Lock()
+	defer ramr.mutex.Unlock()
+
+	*dest = recorded
+
+	return findings, nil
+}
+
+// RecordingPrometheusRepo records the responses of the prometheus repository
+type RecordingPrometheusRepo struct {
+	repo     healthcheck.PrometheusRepo
+	mutex    sync.Mutex
+	snapshot *PrometheusSnapshot
+}
+
+// NewRecordingPrometheusRepo returns a new *RecordingPrometheusRepo
+func NewRecordingPrometheusRepo(repo healthcheck.PrometheusRepo) *RecordingPrometheusRepo {
+	return &RecordingPrometheusRepo{
+		repo:     repo,
+		snapshot: &PrometheusSnapshot{},
+	}
+}
+
+// getSnapshot returns the recorded responses
+func (rpr *RecordingPrometheusRepo) getSnapshot() *PrometheusSnapshot {
+	rpr.mutex.Lock()
+	defer rpr.mutex.Unlock()
+
+	snapshot := *rpr.snapshot
+
+	return &snapshot
+}
+
+// GetFileSystems gets the file systems and records them
+func (rpr *RecordingPrometheusRepo) GetFileSystems() ([]healthcheck.FileSystem, error) {
+	fileSystems, err := rpr.repo.GetFileSystems()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*FileSystem, len(fileSystems))
+	for i, fileSystem := range fileSystems {
+		recorded[i] = NewFileSystem(fileSystem.GetMountPoint(), fileSystem.GetDevice())
+	}
+
+	rpr.mutex.Lock()
+	defer rpr.mutex.Unlock()
+
+	rpr.snapshot.FileSystems = recorded
+
+	return fileSystems, nil
+}
+
+// GetCPUUsage gets the cpu usage and records it
+func (rpr *RecordingPrometheusRepo) GetCPUUsage() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetCPUUsage, &rpr.snapshot.CPUUsage)
+}
+
+// GetIOUtil gets the io util and records it
+func (rpr *RecordingPrometheusRepo) GetIOUtil() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetIOUtil, &rpr.snapshot.IOUtil)
+}
+
+// GetDiskCapacityUsage gets the disk capacity usage of the mount points and records it
+func (rpr *RecordingPrometheusRepo) GetDiskCapacityUsage(mountPoints []string) ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(func() ([]healthcheck.PrometheusData, error) {
+		return rpr.repo.GetDiskCapacityUsage(mountPoints)
+	}, &rpr.snapshot.DiskCapacityUsage)
+}
+
+// GetConnectionUsage gets the connection usage and records it
+func (rpr *RecordingPrometheusRepo) GetConnectionUsage() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetConnectionUsage, &rpr.snapshot.ConnectionUsage)
+}
+
+// GetAverageActiveSessionPercents gets the average active session percents and records them
+func (rpr *RecordingPrometheusRepo) GetAverageActiveSessionPercents() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetAverageActiveSessionPercents, &rpr.snapshot.AverageActiveSessionPercents)
+}
+
+// GetCacheMissRatio gets the cache miss ratio and records it
+func (rpr *RecordingPrometheusRepo) GetCacheMissRatio() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetCacheMissRatio, &rpr.snapshot.CacheMissRatio)
+}
+
+// GetReplicationLag gets the replication lag and records it
+func (rpr *RecordingPrometheusRepo) GetReplicationLag() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetReplicationLag, &rpr.snapshot.ReplicationLag)
+}
+
+// GetInnoDBRowLockWaits gets the innodb row lock waits per second and records them
+func (rpr *RecordingPrometheusRepo) GetInnoDBRowLockWaits() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetInnoDBRowLockWaits, &rpr.snapshot.InnoDBRowLockWaits)
+}
+
+// GetInnoDBRowLockTime gets the innodb row lock time per second in milliseconds and records it
+func (rpr *RecordingPrometheusRepo) GetInnoDBRowLockTime() ([]healthcheck.PrometheusData, error) {
+	return rpr.recordPrometheusDatas(rpr.repo.GetInnoDBRowLockTime, &rpr.snapshot.InnoDBRowLockTime)
+}
+
+// recordPrometheusDatas gets the prometheus data with given function and records them to the destination
+func (rpr *RecordingPrometheusRepo) recordPrometheusDatas(getDatas func() ([]healthcheck.PrometheusData, error),
+	dest *[]*PrometheusData) ([]healthcheck.PrometheusData, error) {
+	datas, err := getDatas()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*PrometheusData, len(datas))
+	for i, data := range datas {
+		recorded[i] = NewPrometheusDataWithSeries(data.GetSeries(), data.GetTimestamp(), data.GetValue())
+	}
+
+	rpr.mutex.Lock()
+	defer rpr.mutex.Unlock()
+
+	*dest = recorded
+
+	return datas, nil
+}
+
+// RecordingQueryRepo records the responses of the query repository
+type RecordingQueryRepo struct {
+	repo        healthcheck.QueryRepo
+	mutex       sync.Mutex
+	slowQueries []*query.Query
+}
+
+// NewRecordingQueryRepo returns a new *RecordingQueryRepo
+func NewRecordingQueryRepo(repo healthcheck.QueryRepo) *RecordingQueryRepo {
+	return &RecordingQueryRepo{repo: repo}
+}
+
+// getSlowQueries returns the recorded slow queries
+func (rqr *RecordingQueryRepo) getSlowQueries() []*query.Query {
+	rqr.mutex.Lock()
+	defer rqr.mutex.Unlock()
+
+	return rqr.slowQueries
+}
+
+// Close closes the decorated repository
+func (rqr *RecordingQueryRepo) Close() error {
+	return rqr.repo.Close()
+}
+
+// GetSlowQuery gets the slow queries and records them
+func (rqr *RecordingQueryRepo) GetSlowQuery() ([]depquery.Query, error) {
+	queries, err := rqr.repo.GetSlowQuery()
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*query.Query, len(queries))
+	for i, q := range queries {
+		recorded[i] = query.NewQueryWithQuery(q)
+	}
+
+	rqr.mutex.Lock()
+	defer rqr.mutex.Unlock()
+
+	rqr.slowQueries = recorded
+
+	return queries, nil
+}
@@ -0,0 +1,63 @@
+package healthcheck
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorderAll(t *testing.T) {
+	TestRecorder_RecordAndReplay(t)
+	TestRecorder_RecordPrimaryGTIDExecuted(t)
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot, err := NewSnapshotWithJSON([]byte(testSnapshot))
+	asst.Nil(err, common.CombineMessageWithError("test Recorder failed", err))
+	// record the responses of the snapshot repositories
+	recorder := NewRecorder(snapshot.GetStartTime(), snapshot.GetEndTime(), snapshot.GetStep(),
+		NewSnapshotApplicationMySQLRepo(snapshot.MySQL),
+		NewSnapshotPrometheusRepo(snapshot.Prometheus),
+		NewSnapshotQueryRepo(snapshot.SlowQueries))
+	de := NewDefaultEngine(NewOperationInfo(1, nil, nil, snapshot.GetStartTime(), snapshot.GetEndTime(), snapshot.GetStep()), nil,
+		recorder.GetApplicationMySQLRepo(), recorder.GetPrometheusRepo(), recorder.GetQueryRepo())
+	de.engineConfig = newTestSnapshotEngine(snapshot).engineConfig
+	de.checkItems(context.Background(), newTestSnapshotItems(de))
+	expected := de.GetItemResults()
+
+	fileName := filepath.Join(t.TempDir(), "snapshot.json")
+	err = recorder.Save(fileName)
+	asst.Nil(err, common.CombineMessageWithError("test Save() failed", err))
+
+	// replay the golden file
+	replayed, err := NewSnapshotWithFile(fileName)
+	asst.Nil(err, common.CombineMessageWithError("test NewSnapshotWithFile() failed", err))
+	de = newTestSnapshotEngine(replayed)
+	de.checkItems(context.Background(), newTestSnapshotItems(de))
+	asst.Equal(expected, de.GetItemResults(), "test replay with NewSnapshotWithFile() failed")
+}
+
+func TestRecorder_RecordPrimaryGTIDExecuted(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot, err := NewSnapshotWithJSON([]byte(testSnapshot))
+	asst.Nil(err, common.CombineMessageWithError("test RecordPrimaryGTIDExecuted() failed", err))
+	recorder := NewRecorder(snapshot.GetStartTime(), snapshot.GetEndTime(), snapshot.GetStep(),
+		NewSnapshotApplicationMySQLRepo(snapshot.MySQL),
+		NewSnapshotPrometheusRepo(snapshot.Prometheus),
+		NewSnapshotQueryRepo(snapshot.SlowQueries))
+	primaryGTIDExecuted := testReplicationSourceUUID1 + ":1-100"
+	getPrimaryGTIDExecuted := recorder.RecordPrimaryGTIDExecuted(func(hostIP string, portNum int) (string, error) {
+		return primaryGTIDExecuted, nil
+	})
+
+	result, err := getPrimaryGTIDExecuted("192.168.10.219", 3306)
+	asst.Nil(err, common.CombineMessageWithError("test RecordPrimaryGTIDExecuted() failed", err))
+	asst.Equal(primaryGTIDExecuted, result, "test RecordPrimaryGTIDExecuted() failed")
+	asst.Equal(primaryGTIDExecuted, recorder.Snapshot().MySQL.PrimaryGTIDExecuted, "test RecordPrimaryGTIDExecuted() failed")
+}
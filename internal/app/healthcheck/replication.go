@@ -0,0 +1,509 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/mysql"
+)
+
+const (
+	// the column names of the result of show slave status
+	replicationMasterHostColumn          = "Master_Host"
+	replicationMasterPortColumn          = "Master_Port"
+	replicationIORunningColumn           = "Slave_IO_Running"
+	replicationSQLRunningColumn          = "Slave_SQL_Running"
+	replicationSecondsBehindMasterColumn = "Seconds_Behind_Master"
+	replicationLastIOErrorColumn         = "Last_IO_Error"
+	replicationLastSQLErrorColumn        = "Last_SQL_Error"
+	replicationExecutedGTIDSetColumn     = "Executed_Gtid_Set"
+
+	replicationThreadRunning         = "Yes"
+	replicationSecondsBehindMasterNA = -1
+
+	gtidSetSeparator      = ","
+	gtidIntervalSeparator = ":"
+	gtidRangeSeparator    = "-"
+)
+
+var (
+	_ healthcheck.ReplicationStatus = (*ReplicationStatus)(nil)
+	_ healthcheck.CheckItem         = (*ReplicationItem)(nil)
+)
+
+// ReplicationStatus is the replication status of a replica
+type ReplicationStatus struct {
+	MasterHost          string `json:"master_host"`
+	MasterPort          int    `json:"master_port"`
+	IOThreadRunning     bool   `json:"io_thread_running"`
+	SQLThreadRunning    bool   `json:"sql_thread_running"`
+	SecondsBehindMaster int    `json:"seconds_behind_master"`
+	LastIOError         string `json:"last_io_error"`
+	LastSQLError        string `json:"last_sql_error"`
+	ExecutedGTIDSet     string `json:"executed_gtid_set"`
+}
+
+// newReplicationStatusWithResult returns a new *ReplicationStatus with the first row of the result of show slave status,
+// if the replica has multiple channels, only the first channel will be checked
+func newReplicationStatusWithResult(result *mysql.Result) (*ReplicationStatus, error) {
+	rs := &ReplicationStatus{}
+
+	var err error
+	rs.MasterHost, err = result.GetStringByName(constant.ZeroInt, replicationMasterHostColumn)
+	if err != nil {
+		return nil, err
+	}
+	rs.MasterPort, err = result.GetIntByName(constant.ZeroInt, replicationMasterPortColumn)
+	if err != nil {
+		return nil, err
+	}
+	ioRunning, err := result.GetStringByName(constant.ZeroInt, replicationIORunningColumn)
+	if err != nil {
+		return nil, err
+	}
+	rs.IOThreadRunning = strings.EqualFold(ioRunning, replicationThreadRunning)
+	sqlRunning, err := result.GetStringByName(constant.ZeroInt, replicationSQLRunningColumn)
+	if err != nil {
+		return nil, err
+	}
+	rs.SQLThreadRunning = strings.EqualFold(sqlRunning, replicationThreadRunning)
+	// seconds behind master is null if the sql thread is not running
+	rs.SecondsBehindMaster = replicationSecondsBehindMasterNA
+	isNull, err := result.IsNullByName(constant.ZeroInt, replicationSecondsBehindMasterColumn)
+	if err != nil {
+		return nil, err
+	}
+	if !isNull {
+		rs.SecondsBehindMaster, err = result.GetIntByName(constant.ZeroInt, replicationSecondsBehindMasterColumn)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rs.LastIOError, err = result.GetStringByName(constant.ZeroInt, replicationLastIOErrorColumn)
+	if err != nil {
+		return nil, err
+	}
+	rs.LastSQLError, err = result.GetStringByName(constant.ZeroInt, replicationLastSQLErrorColumn)
+	if err != nil {
+		return nil, err
+	}
+	rs.ExecutedGTIDSet, err = result.GetStringByName(constant.ZeroInt, replicationExecutedGTIDSetColumn)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// GetMasterHost returns the host of the primary
+func (rs *ReplicationStatus) GetMasterHost() string {
+	return rs.MasterHost
+}
+
+// GetMasterPort returns the port of the primary
+func (rs *ReplicationStatus) GetMasterPort() int {
+	return rs.MasterPort
+}
+
+// IsIOThreadRunning returns if the io thread is running
+func (rs *ReplicationStatus) IsIOThreadRunning() bool {
+	return rs.IOThreadRunning
+}
+
+// IsSQLThreadRunning returns if the sql thread is running
+func (rs *ReplicationStatus) IsSQLThreadRunning() bool {
+	return rs.SQLThreadRunning
+}
+
+// GetSecondsBehindMaster returns the seconds behind the primary, it returns -1 if the sql thread is not running
+func (rs *ReplicationStatus) GetSecondsBehindMaster() int {
+	return rs.SecondsBehindMaster
+}
+
+// GetLastIOError returns the last error of the io thread
+func (rs *ReplicationStatus) GetLastIOError() string {
+	return rs.LastIOError
+}
+
+// GetLastSQLError returns the last error of the sql thread
+func (rs *ReplicationStatus) GetLastSQLError() string {
+	return rs.LastSQLError
+}
+
+// GetExecutedGTIDSet returns the executed gtid set
+func (rs *ReplicationStatus) GetExecutedGTIDSet() string {
+	return rs.ExecutedGTIDSet
+}
+
+// gtidInterval is a closed interval of the transaction numbers
+type gtidInterval struct {
+	start int64
+	end   int64
+}
+
+// gtidSet is the parsed gtid set, the key is the source uuid(with the tag if exists),
+// the intervals of each source are sorted and merged
+type gtidSet map[string][]gtidInterval
+
+// parseGTIDSet parses the gtid set string, such as "uuid1:1-10:12,uuid2:1-5"
+func parseGTIDSet(s string) (gtidSet, error) {
+	gs := make(gtidSet)
+	// the gtid set returned by mysql may contain line breaks
+	s = strings.Join(strings.Fields(s), constant.EmptyString)
+	if s == constant.EmptyString {
+		return gs, nil
+	}
+
+	for _, member := range strings.Split(s, gtidSetSeparator) {
+		parts := strings.Split(member, gtidIntervalSeparator)
+		if len(parts) < 2 || parts[constant.ZeroInt] == constant.EmptyString {
+			return nil, message.NewMessage(msghc.ErrReplicationGTIDSetInvalid, s)
+		}
+		source := strings.ToLower(parts[constant.ZeroInt])
+		for _, part := range parts[1:] {
+			if part == constant.EmptyString {
+				return nil, message.NewMessage(msghc.ErrReplicationGTIDSetInvalid, s)
+			}
+			if part[constant.ZeroInt] < '0' || part[constant.ZeroInt] > '9' {
+				// tagged gtid, the intervals after the tag belong to the uuid with the tag
+				source = strings.ToLower(parts[constant.ZeroInt]) + gtidIntervalSeparator + strings.ToLower(part)
+				continue
+			}
+			interval, err := parseGTIDInterval(part)
+			if err != nil {
+				return nil, message.NewMessage(msghc.ErrReplicationGTIDSetInvalid, s)
+			}
+			gs[source] = append(gs[source], interval)
+		}
+	}
+
+	for source, intervals := range gs {
+		gs[source] = mergeGTIDIntervals(intervals)
+	}
+
+	return gs, nil
+}
+
+// parseGTIDInterval parses the interval string, such as "1-10" or "12"
+func parseGTIDInterval(s string) (gtidInterval, error) {
+	bounds := strings.SplitN(s, gtidRangeSeparator, 2)
+	start, err := strconv.ParseInt(bounds[constant.ZeroInt], 10, 64)
+	if err != nil {
+		return gtidInterval{}, err
+	}
+	end := start
+	if len(bounds) == 2 {
+		end, err = strconv.ParseInt(bounds[1], 10, 64)
+		if err != nil {
+			return gtidInterval{}, err
+		}
+	}
+	if start <= constant.ZeroInt || end < start {
+		return gtidInterval{}, fmt.Errorf("gtid interval %s is not valid", s)
+	}
+
+	return gtidInterval{start: start, end: end}, nil
+}
+
+// mergeGTIDIntervals sorts the intervals and merges the overlapping and adjacent ones
+func mergeGTIDIntervals(intervals []gtidInterval) []gtidInterval {
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start < intervals[j].start
+	})
+
+	var merged []gtidInterval
+	for _, interval := range intervals {
+		last := len(merged) - 1
+		if last >= constant.ZeroInt && interval.start <= merged[last].end+1 {
+			if interval.end > merged[last].end {
+				merged[last].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	return merged
+}
+
+// subtract returns the transactions which are in gs but not in other
+func (gs gtidSet) subtract(other gtidSet) gtidSet {
+	diff := make(gtidSet)
+	for source, intervals := range gs {
+		remaining := intervals
+		for _, o := range other[source] {
+			var next []gtidInterval
+			for _, interval := range remaining {
+				if o.end < interval.start || o.start > interval.end {
+					next = append(next, interval)
+					continue
+				}
+				if interval.start < o.start {
+					next = append(next, gtidInterval{start: interval.start, end: o.start - 1})
+				}
+				if interval.end > o.end {
+					next = append(next, gtidInterval{start: o.end + 1, end: interval.end})
+				}
+			}
+			remaining = next
+		}
+		if len(remaining) > constant.ZeroInt {
+			diff[source] = remaining
+		}
+	}
+
+	return diff
+}
+
+// count returns the number of the transactions
+func (gs gtidSet) count() int64 {
+	var count int64
+	for _, intervals := range gs {
+		for _, interval := range intervals {
+			count += interval.end - interval.start + 1
+		}
+	}
+
+	return count
+}
+
+// String returns the gtid set string, the sources are sorted
+func (gs gtidSet) String() string {
+	sources := make([]string, constant.ZeroInt, len(gs))
+	for source := range gs {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	members := make([]string, len(sources))
+	for i, source := range sources {
+		member := source
+		for _, interval := range gs[source] {
+			member += gtidIntervalSeparator + strconv.FormatInt(interval.start, 10)
+			if interval.end != interval.start {
+				member += gtidRangeSeparator + strconv.FormatInt(interval.end, 10)
+			}
+		}
+		members[i] = member
+	}
+
+	return strings.Join(members, gtidSetSeparator)
+}
+
+// getPercentile returns the percentile of the sorted values with the nearest rank method
+func getPercentile(sortedValues []float64, percent float64) float64 {
+	if len(sortedValues) == constant.ZeroInt {
+		return constant.ZeroInt
+	}
+	rank := int(math.Ceil(percent / defaultMaxScore * float64(len(sortedValues))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sortedValues[rank-1]
+}
+
+// ReplicationLagPercentiles are the percentiles of the replication lag in seconds
+type ReplicationLagPercentiles struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// newReplicationLagPercentiles returns the percentiles of the replication lag
+func newReplicationLagPercentiles(lags []healthcheck.PrometheusData) *ReplicationLagPercentiles {
+	values := make([]float64, len(lags))
+	for i, lag := range lags {
+		values[i] = lag.GetValue()
+	}
+	sort.Float64s(values)
+
+	return &ReplicationLagPercentiles{
+		P50: getPercentile(values, 50),
+		P95: getPercentile(values, 95),
+		P99: getPercentile(values, 99),
+		Max: getPercentile(values, defaultMaxScore),
+	}
+}
+
+// replicationData is the data of the replication item which will be saved in the result
+type replicationData struct {
+	IsReplica      bool                          `json:"is_replica"`
+	Status         healthcheck.ReplicationStatus `json:"status"`
+	LagPercentiles *ReplicationLagPercentiles    `json:"lag_percentiles"`
+	Lags           []healthcheck.PrometheusData  `json:"lags"`
+	MissingGTIDSet string                        `json:"missing_gtid_set"`
+	GTIDGap        int64                         `json:"gtid_gap"`
+	PrimaryError   string                        `json:"primary_error"`
+	Issues         []string                      `json:"issues"`
+}
+
+// ReplicationItem checks the replication health of the replica, the primary server scores full marks
+type ReplicationItem struct {
+	engine                 *DefaultEngine
+	getPrimaryGTIDExecuted func(hostIP string, portNum int) (string, error)
+	status                 healthcheck.ReplicationStatus
+	lags                   []healthcheck.PrometheusData
+	highLags               []healthcheck.PrometheusData
+	lagPercentiles         *ReplicationLagPercentiles
+	missingGTIDSet         gtidSet
+	primaryError           string
+	issues                 []string
+}
+
+// NewReplicationItem returns a new healthcheck.CheckItem which checks the replication health
+func NewReplicationItem(de *DefaultEngine) healthcheck.CheckItem {
+	return &ReplicationItem{engine: de, getPrimaryGTIDExecuted: de.getPrimaryGTIDExecuted}
+}
+
+// GetName returns the item name
+func (ri *ReplicationItem) GetName() string {
+	return defaultReplicationItemName
+}
+
+// Collect collects the replication status, the replication lag and the gtid gap against the primary,
+// the primary could be unreachable, in that case, the gtid gap will not be checked
+func (ri *ReplicationItem) Collect() error {
+	status, err := ri.engine.GetApplicationMySQLRepo().GetReplicationStatus()
+	if err != nil {
+		return err
+	}
+	if status == nil {
+		// not a replica
+		return nil
+	}
+	ri.status = status
+
+	ri.lags, err = ri.engine.GetPrometheusRepo().GetReplicationLag()
+	if err != nil {
+		return err
+	}
+
+	if status.GetExecutedGTIDSet() == constant.EmptyString {
+		// gtid is not enabled
+		return nil
+	}
+	primaryGTIDExecuted, err := ri.getPrimaryGTIDExecuted(status.GetMasterHost(), status.GetMasterPort())
+	if err != nil {
+		ri.primaryError = err.Error()
+		return nil
+	}
+	primaryGTIDSet, err := parseGTIDSet(primaryGTIDExecuted)
+	if err != nil {
+		return err
+	}
+	replicaGTIDSet, err := parseGTIDSet(status.GetExecutedGTIDSet())
+	if err != nil {
+		return err
+	}
+	ri.missingGTIDSet = primaryGTIDSet.subtract(replicaGTIDSet)
+
+	return nil
+}
+
+// Score scores the replication, the stopped io thread or sql thread deducts the max score deduction high,
+// the p95 replication lag is scored with the watermarks,
+// and the transactions which were executed on the primary but not on the replica deduct the max score deduction medium
+func (ri *ReplicationItem) Score(config healthcheck.ItemConfig) (int, error) {
+	if ri.status == nil {
+		return int(defaultMaxScore), nil
+	}
+
+	var scoreDeduction float64
+	// thread state
+	if !ri.status.IsIOThreadRunning() {
+		scoreDeduction += config.GetMaxScoreDeductionHigh()
+		ri.issues = append(ri.issues, fmt.Sprintf("io thread is not running, last io error: %s", ri.status.GetLastIOError()))
+	}
+	if !ri.status.IsSQLThreadRunning() {
+		scoreDeduction += config.GetMaxScoreDeductionHigh()
+		ri.issues = append(ri.issues, fmt.Sprintf("sql thread is not running, last sql error: %s", ri.status.GetLastSQLError()))
+	}
+	// replication lag
+	lags := ri.lags
+	if len(lags) == constant.ZeroInt && ri.status.GetSecondsBehindMaster() != replicationSecondsBehindMasterNA {
+		// the monitor system has no lag series, use the current lag instead
+		lags = []healthcheck.PrometheusData{NewPrometheusData(constant.EmptyString, float64(ri.status.GetSecondsBehindMaster()))}
+	}
+	for _, lag := range lags {
+		if lag.GetValue() >= config.GetHighWatermark() {
+			ri.highLags = append(ri.highLags, lag)
+		}
+	}
+	ri.lagPercentiles = newReplicationLagPercentiles(lags)
+	p95 := ri.lagPercentiles.P95
+	switch {
+	case len(lags) == constant.ZeroInt:
+	case p95 >= config.GetHighWatermark():
+		scoreDeduction += ri.getLagScoreDeduction(p95-config.GetHighWatermark(), config.GetUnit(),
+			config.GetScoreDeductionPerUnitHigh(), config.GetMaxScoreDeductionHigh())
+		ri.issues = append(ri.issues, fmt.Sprintf("p95 replication lag %.0f seconds is higher than the high watermark %.0f seconds", p95, config.GetHighWatermark()))
+	case p95 >= config.GetLowWatermark():
+		scoreDeduction += ri.getLagScoreDeduction(p95-config.GetLowWatermark(), config.GetUnit(),
+			config.GetScoreDeductionPerUnitMedium(), config.GetMaxScoreDeductionMedium())
+		ri.issues = append(ri.issues, fmt.Sprintf("p95 replication lag %.0f seconds is higher than the low watermark %.0f seconds", p95, config.GetLowWatermark()))
+	}
+	// gtid gap
+	if gap := ri.missingGTIDSet.count(); gap > constant.ZeroInt {
+		scoreDeduction += config.GetMaxScoreDeductionMedium()
+		ri.issues = append(ri.issues, fmt.Sprintf("%d transactions executed on the primary are missing on the replica", gap))
+	}
+	if ri.primaryError != constant.EmptyString {
+		ri.issues = append(ri.issues, fmt.Sprintf("could not get the executed gtid set of the primary, gtid gap is not checked: %s", ri.primaryError))
+	}
+
+	score := int(defaultMaxScore - scoreDeduction)
+	if score < constant.ZeroInt {
+		score = constant.ZeroInt
+	}
+
+	return score, nil
+}
+
+// getLagScoreDeduction returns the score deduction of the lag which exceeds the watermark,
+// the lag exceeds the watermark counts as one unit at least, so the unit could not be zero
+func (ri *ReplicationItem) getLagScoreDeduction(exceeded, unit, scoreDeductionPerUnit, maxScoreDeduction float64) float64 {
+	units := 1.0
+	if unit > constant.ZeroInt {
+		units = math.Max(units, exceeded/unit)
+	}
+	scoreDeduction := units * scoreDeductionPerUnit
+	if scoreDeduction > maxScoreDeduction {
+		scoreDeduction = maxScoreDeduction
+	}
+
+	return scoreDeduction
+}
+
+// Advice returns the replication data and the lag points which are higher than the high watermark
+func (ri *ReplicationItem) Advice() (string, string, string, error) {
+	data := &replicationData{
+		IsReplica:      ri.status != nil,
+		Status:         ri.status,
+		LagPercentiles: ri.lagPercentiles,
+		Lags:           ri.lags,
+		MissingGTIDSet: ri.missingGTIDSet.String(),
+		GTIDGap:        ri.missingGTIDSet.count(),
+		PrimaryError:   ri.primaryError,
+		Issues:         ri.issues,
+	}
+	jsonBytesTotal, err := json.Marshal(data)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+	jsonBytesHigh, err := json.Marshal(ri.highLags)
+	if err != nil {
+		return constant.EmptyString, constant.EmptyString, constant.EmptyString, err
+	}
+
+	return string(jsonBytesTotal), string(jsonBytesHigh), constant.EmptyString, nil
+}
@@ -0,0 +1,193 @@
+package healthcheck
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testReplicationSourceUUID1 = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	testReplicationSourceUUID2 = "4f22ab58-82db-22f2-af44-d91bb0530673"
+)
+
+func newTestReplicationConfig() *DefaultItemConfig {
+	return &DefaultItemConfig{
+		ItemName:                    defaultReplicationItemName,
+		ItemWeight:                  10,
+		LowWatermark:                60,
+		HighWatermark:               300,
+		Unit:                        60,
+		ScoreDeductionPerUnitHigh:   20,
+		MaxScoreDeductionHigh:       100,
+		ScoreDeductionPerUnitMedium: 10,
+		MaxScoreDeductionMedium:     50,
+	}
+}
+
+func newTestPrometheusDatas(values ...float64) []healthcheck.PrometheusData {
+	lags := make([]healthcheck.PrometheusData, len(values))
+	for i, value := range values {
+		lags[i] = NewPrometheusData(strconv.Itoa(i), value)
+	}
+
+	return lags
+}
+
+func TestReplicationAll(t *testing.T) {
+	TestReplication_parseGTIDSet(t)
+	TestReplication_subtract(t)
+	TestReplication_getPercentile(t)
+	TestReplicationItem_Score(t)
+	TestReplicationItem_Advice(t)
+	TestReplicationItem_CollectWithSnapshot(t)
+}
+
+func TestReplication_parseGTIDSet(t *testing.T) {
+	asst := assert.New(t)
+
+	gs, err := parseGTIDSet(testReplicationSourceUUID1 + ":1-5:6-10:12,\n" + testReplicationSourceUUID2 + ":1-3")
+	asst.Nil(err, common.CombineMessageWithError("test parseGTIDSet() failed", err))
+	asst.Equal(int64(14), gs.count(), "test parseGTIDSet() failed")
+	asst.Equal(testReplicationSourceUUID1+":1-10:12,"+testReplicationSourceUUID2+":1-3", gs.String(), "test parseGTIDSet() failed")
+
+	gs, err = parseGTIDSet(testReplicationSourceUUID1 + ":1-5:tag1:1-3")
+	asst.Nil(err, common.CombineMessageWithError("test parseGTIDSet() failed", err))
+	asst.Equal(int64(8), gs.count(), "test parseGTIDSet() failed")
+
+	gs, err = parseGTIDSet("")
+	asst.Nil(err, common.CombineMessageWithError("test parseGTIDSet() failed", err))
+	asst.Equal(int64(0), gs.count(), "test parseGTIDSet() failed")
+
+	for _, invalid := range []string{testReplicationSourceUUID1, testReplicationSourceUUID1 + ":5-1", testReplicationSourceUUID1 + ":0", ":1-5"} {
+		_, err = parseGTIDSet(invalid)
+		asst.NotNil(err, "test parseGTIDSet() failed, gtid set: %s", invalid)
+	}
+}
+
+func TestReplication_subtract(t *testing.T) {
+	asst := assert.New(t)
+
+	primary, err := parseGTIDSet(testReplicationSourceUUID1 + ":1-100," + testReplicationSourceUUID2 + ":1-10")
+	asst.Nil(err, common.CombineMessageWithError("test subtract() failed", err))
+	replica, err := parseGTIDSet(testReplicationSourceUUID1 + ":1-40:51-90," + testReplicationSourceUUID2 + ":1-10")
+	asst.Nil(err, common.CombineMessageWithError("test subtract() failed", err))
+
+	missing := primary.subtract(replica)
+	asst.Equal(testReplicationSourceUUID1+":41-50:91-100", missing.String(), "test subtract() failed")
+	asst.Equal(int64(20), missing.count(), "test subtract() failed")
+	asst.Equal(int64(0), replica.subtract(primary).count(), "test subtract() failed")
+}
+
+func TestReplication_getPercentile(t *testing.T) {
+	asst := assert.New(t)
+
+	percentiles := newReplicationLagPercentiles(newTestPrometheusDatas(10, 1, 9, 2, 8, 3, 7, 4, 6, 5))
+	asst.Equal(5.0, percentiles.P50, "test getPercentile() failed")
+	asst.Equal(10.0, percentiles.P95, "test getPercentile() failed")
+	asst.Equal(10.0, percentiles.Max, "test getPercentile() failed")
+	asst.Equal(0.0, getPercentile(nil, 95), "test getPercentile() failed")
+}
+
+func TestReplicationItem_Score(t *testing.T) {
+	asst := assert.New(t)
+
+	cases := []struct {
+		name     string
+		status   *ReplicationStatus
+		lags     []float64
+		missing  string
+		expected int
+	}{
+		{"primary", nil, nil, "", 100},
+		{"healthy", &ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}, []float64{0, 1, 2}, "", 100},
+		{"medium lag", &ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}, []float64{180, 180, 180}, "", 80},
+		{"high lag", &ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}, []float64{600, 600, 600}, "", 0},
+		{"current lag", &ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true, SecondsBehindMaster: 120}, nil, "", 90},
+		{"io thread stopped", &ReplicationStatus{SQLThreadRunning: true, SecondsBehindMaster: 0}, nil, "", 0},
+		{"gtid gap", &ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true}, []float64{0}, testReplicationSourceUUID1 + ":11-20", 50},
+	}
+	for _, c := range cases {
+		ri := &ReplicationItem{lags: newTestPrometheusDatas(c.lags...)}
+		if c.status != nil {
+			ri.status = c.status
+		}
+		missing, err := parseGTIDSet(c.missing)
+		asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+		ri.missingGTIDSet = missing
+		score, err := ri.Score(newTestReplicationConfig())
+		asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+		asst.Equal(c.expected, score, "test Score() failed, case: %s", c.name)
+	}
+}
+
+func TestReplicationItem_Advice(t *testing.T) {
+	asst := assert.New(t)
+
+	ri := &ReplicationItem{
+		status:       &ReplicationStatus{IOThreadRunning: true, SQLThreadRunning: true},
+		lags:         newTestPrometheusDatas(100, 400),
+		primaryError: "connection refused",
+	}
+	_, err := ri.Score(newTestReplicationConfig())
+	asst.Nil(err, common.CombineMessageWithError("test Advice() failed", err))
+	data, high, _, err := ri.Advice()
+	asst.Nil(err, common.CombineMessageWithError("test Advice() failed", err))
+	asst.Contains(data, `"is_replica":true`, "test Advice() failed")
+	asst.Contains(data, "connection refused", "test Advice() failed")
+	asst.Equal(`[{"timestamp":"1","value":400}]`, high, "test Advice() failed")
+}
+
+func TestReplicationItem_CollectWithSnapshot(t *testing.T) {
+	asst := assert.New(t)
+
+	// the primary in the snapshot points to the listener, the engine must never connect to it
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	asst.Nil(err, common.CombineMessageWithError("test Collect() with snapshot failed", err))
+	defer func() { _ = listener.Close() }()
+	primaryAddr := listener.Addr().(*net.TCPAddr)
+
+	cases := []struct {
+		name                string
+		primaryGTIDExecuted string
+		missing             string
+	}{
+		{"captured", testReplicationSourceUUID1 + ":1-100", testReplicationSourceUUID1 + ":91-100"},
+		{"not captured", "", ""},
+	}
+	for _, c := range cases {
+		data := fmt.Sprintf(`{
+			"start_time": "2021-05-21 10:00:00", "end_time": "2021-05-21 11:00:00", "step": "10s",
+			"mysql": {
+				"replication_status": {"master_host": "%s", "master_port": %d, "io_thread_running": true, "sql_thread_running": true, "executed_gtid_set": "%s:1-90"},
+				"primary_gtid_executed": "%s"
+			},
+			"prometheus": {}
+		}`, primaryAddr.IP.String(), primaryAddr.Port, testReplicationSourceUUID1, c.primaryGTIDExecuted)
+		snapshot, err := NewSnapshotWithJSON([]byte(data))
+		asst.Nil(err, common.CombineMessageWithError("test Collect() with snapshot failed", err))
+
+		ri := NewReplicationItem(newTestSnapshotEngine(snapshot)).(*ReplicationItem)
+		err = ri.Collect()
+		asst.Nil(err, common.CombineMessageWithError("test Collect() with snapshot failed", err))
+		asst.Equal(c.missing, ri.missingGTIDSet.String(), "test Collect() with snapshot failed, case: %s", c.name)
+		if c.primaryGTIDExecuted == "" {
+			asst.Contains(ri.primaryError, "not captured", "test Collect() with snapshot failed, case: %s", c.name)
+		}
+
+		// a connection to the primary would be waiting in the backlog
+		err = listener.(*net.TCPListener).SetDeadline(time.Now().Add(100 * time.Millisecond))
+		asst.Nil(err, common.CombineMessageWithError("test Collect() with snapshot failed", err))
+		conn, err := listener.Accept()
+		if err == nil {
+			_ = conn.Close()
+		}
+		asst.NotNil(err, "test Collect() with snapshot failed, the primary was connected, case: %s", c.name)
+	}
+}
@@ -0,0 +1,392 @@
+package healthcheck
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/romberli/das/internal/app/query"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	ReportFormatHTML     = "html"
+	ReportFormatMarkdown = "markdown"
+	ReportFormatJSON     = "json"
+
+	reportSparklineWidth  = 240
+	reportSparklineHeight = 40
+	reportTimeLayout      = "2006-01-02 15:04:05"
+)
+
+// reportFuncs are the functions which could be used in the report templates
+var reportFuncs = map[string]interface{}{
+	"formatTime": func(t time.Time) string { return t.Format(reportTimeLayout) },
+	"severity":   getSeverityName,
+	"cell":       escapeMarkdownCell,
+}
+
+// ReportItem is the check item which will be shown in the report
+type ReportItem struct {
+	ItemName string            `json:"item_name"`
+	Score    int               `json:"score"`
+	Error    string            `json:"error"`
+	Series   []*PrometheusData `json:"series"`
+	Breaches []*PrometheusData `json:"breaches"`
+	High     string            `json:"high"`
+}
+
+// newReportItem returns a new *ReportItem, the data and the high data will be parsed as the prometheus series if possible,
+// otherwise, the high data will be kept as the indented json string
+func newReportItem(item healthcheck.ResultItem) *ReportItem {
+	ri := &ReportItem{
+		ItemName: item.GetItemName(),
+		Score:    item.GetScore(),
+	}
+	if !item.IsAvailable() {
+		// the unavailable item has no data, keep the error instead of showing a score of 0
+		ri.Error = item.GetError()
+		return ri
+	}
+	ri.Series, _ = unmarshalPrometheusDatas(item.GetData())
+	breaches, ok := unmarshalPrometheusDatas(item.GetHigh())
+	if ok {
+		ri.Breaches = breaches
+		return ri
+	}
+	ri.High = indentJSON(item.GetHigh())
+
+	return ri
+}
+
+// Sparkline returns the sparkline of the series as an svg image, it returns an empty string if there are less than 2 points
+func (ri *ReportItem) Sparkline() string {
+	if len(ri.Series) < 2 {
+		return constant.EmptyString
+	}
+
+	minValue, maxValue := ri.Series[constant.ZeroInt].Value, ri.Series[constant.ZeroInt].Value
+	for _, data := range ri.Series {
+		if data.Value < minValue {
+			minValue = data.Value
+		}
+		if data.Value > maxValue {
+			maxValue = data.Value
+		}
+	}
+	valueRange := maxValue - minValue
+	if valueRange == constant.ZeroInt {
+		valueRange = 1
+	}
+
+	points := make([]string, len(ri.Series))
+	stepX := float64(reportSparklineWidth) / float64(len(ri.Series)-1)
+	for i, data := range ri.Series {
+		x := float64(i) * stepX
+		// the y axis of svg points down, leave 1 pixel for the stroke
+		y := 1 + (maxValue-data.Value)/valueRange*float64(reportSparklineHeight-2)
+		points[i] = fmt.Sprintf("%.1f,%.1f", x, y)
+	}
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+
+		`<polyline fill="none" stroke="#1f77b4" stroke-width="1.5" points="%s"/></svg>`,
+		reportSparklineWidth, reportSparklineHeight, reportSparklineWidth, reportSparklineHeight, strings.Join(points, constant.SpaceString))
+}
+
+// SparklineHTML returns the sparkline which could be embedded in the html template
+func (ri *ReportItem) SparklineHTML() htmltemplate.HTML {
+	// the svg only consists of the numbers generated above, so it is safe to be embedded
+	return htmltemplate.HTML(ri.Sparkline())
+}
+
+// SparklineDataURI returns the sparkline as a data uri, so that it could be embedded in the markdown as an image
+func (ri *ReportItem) SparklineDataURI() string {
+	sparkline := ri.Sparkline()
+	if sparkline == constant.EmptyString {
+		return constant.EmptyString
+	}
+
+	return "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString([]byte(sparkline))
+}
+
+// Report is the human readable report of a healthcheck operation
+type Report struct {
+	OperationID          int            `json:"operation_id"`
+	MySQLServerID        int            `json:"mysql_server_id"`
+	StartTime            time.Time      `json:"start_time"`
+	EndTime              time.Time      `json:"end_time"`
+	WeightedAverageScore int            `json:"weighted_average_score"`
+	Items                []*ReportItem  `json:"items"`
+	ConfigAdvices        []*Variable    `json:"config_advices"`
+	TopSlowQueries       []*query.Query `json:"top_slow_queries"`
+	SlowQueryAdvice      string         `json:"slow_query_advice"`
+}
+
+// NewReport returns a new *Report of given operation and result
+func NewReport(operation healthcheck.Operation, result healthcheck.Result) *Report {
+	r := &Report{
+		OperationID:          result.GetOperationID(),
+		MySQLServerID:        operation.GetMySQLServerID(),
+		StartTime:            operation.GetStartTime(),
+		EndTime:              operation.GetEndTime(),
+		WeightedAverageScore: result.GetWeightedAverageScore(),
+	}
+
+	for _, item := range result.GetItems() {
+		r.Items = append(r.Items, newReportItem(item))
+
+		switch item.GetItemName() {
+		case defaultDBConfigItemName:
+			// the advice which could not be parsed will be ignored
+			_ = json.Unmarshal([]byte(item.GetAdvice()), &r.ConfigAdvices)
+		case defaultSlowQueryRowsExaminedItemName:
+			_ = json.Unmarshal([]byte(item.GetData()), &r.TopSlowQueries)
+			if len(r.TopSlowQueries) > defaultSlowQueryTopSQLNum {
+				r.TopSlowQueries = r.TopSlowQueries[:defaultSlowQueryTopSQLNum]
+			}
+			r.SlowQueryAdvice = item.GetAdvice()
+		}
+	}
+
+	return r
+}
+
+// RenderHTML renders the report as a self-contained html page
+func (r *Report) RenderHTML() ([]byte, error) {
+	tmpl, err := htmltemplate.New(ReportFormatHTML).Funcs(reportFuncs).Parse(reportHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// RenderMarkdown renders the report as a markdown document, the sparklines are embedded as the data uri images
+func (r *Report) RenderMarkdown() ([]byte, error) {
+	tmpl, err := template.New(ReportFormatMarkdown).Funcs(reportFuncs).Parse(reportMarkdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	err = tmpl.Execute(&buffer, r)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// unmarshalPrometheusDatas unmarshals the json string as the prometheus series,
+// it returns false if the json string is not an array of which each element only has the timestamp and the value
+func unmarshalPrometheusDatas(s string) ([]*PrometheusData, bool) {
+	var elements []map[string]json.RawMessage
+	err := json.Unmarshal([]byte(s), &elements)
+	if err != nil || len(elements) == constant.ZeroInt {
+		return nil, false
+	}
+	for _, element := range elements {
+		_, timestampExists := element["timestamp"]
+		_, valueExists := element["value"]
+		if len(element) != 2 || !timestampExists || !valueExists {
+			return nil, false
+		}
+	}
+
+	var datas []*PrometheusData
+	err = json.Unmarshal([]byte(s), &datas)
+	if err != nil {
+		return nil, false
+	}
+
+	return datas, true
+}
+
+// indentJSON returns the indented json string, it returns the input string if it is not a valid json
+func indentJSON(s string) string {
+	if s == constant.EmptyString || s == "null" || s == "[]" {
+		return constant.EmptyString
+	}
+
+	var buffer bytes.Buffer
+	err := json.Indent(&buffer, []byte(s), constant.EmptyString, "  ")
+	if err != nil {
+		return s
+	}
+
+	return buffer.String()
+}
+
+// escapeMarkdownCell escapes the pipes and the line breaks, so that the string could be shown in a cell of the markdown table
+func escapeMarkdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\r\n", constant.SpaceString, "\n", constant.SpaceString).Replace(s)
+}
+
+// getSeverityName returns the name of the db config rule severity
+func getSeverityName(severity int) string {
+	switch severity {
+	case DBConfigRuleSeverityLow:
+		return "low"
+	case DBConfigRuleSeverityMedium:
+		return "medium"
+	case DBConfigRuleSeverityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+const reportHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Healthcheck Report - Operation {{.OperationID}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 24px; color: #24292e; }
+table { border-collapse: collapse; margin-bottom: 24px; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+pre { background: #f6f8fa; padding: 8px; overflow-x: auto; white-space: pre-wrap; }
+.low { color: #cf222e; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>Healthcheck Report</h1>
+<table>
+<tr><th>Operation ID</th><td>{{.OperationID}}</td></tr>
+<tr><th>MySQL Server ID</th><td>{{.MySQLServerID}}</td></tr>
+<tr><th>Check Range</th><td>{{formatTime .StartTime}} ~ {{formatTime .EndTime}}</td></tr>
+<tr><th>Weighted Average Score</th><td{{if lt .WeightedAverageScore 60}} class="low"{{end}}>{{.WeightedAverageScore}}</td></tr>
+</table>
+<h2>Item Scores</h2>
+<table>
+<tr><th>Item</th><th>Score</th><th>Trend</th></tr>
+{{- range .Items}}
+{{- if .Error}}
+<tr><td>{{.ItemName}}</td><td class="low">unavailable</td><td>{{.Error}}</td></tr>
+{{- else}}
+<tr><td>{{.ItemName}}</td><td{{if lt .Score 60}} class="low"{{end}}>{{.Score}}</td><td>{{.SparklineHTML}}</td></tr>
+{{- end}}
+{{- end}}
+</table>
+<h2>Watermark Breaches</h2>
+{{- range .Items}}
+{{- if .Breaches}}
+<h3>{{.ItemName}}</h3>
+<table>
+<tr><th>Timestamp</th><th>Value</th></tr>
+{{- range .Breaches}}
+<tr><td>{{.Timestamp}}</td><td>{{.Value}}</td></tr>
+{{- end}}
+</table>
+{{- else if .High}}
+<h3>{{.ItemName}}</h3>
+<pre>{{.High}}</pre>
+{{- end}}
+{{- end}}
+<h2>Config Advice</h2>
+{{- if .ConfigAdvices}}
+<table>
+<tr><th>Variable</th><th>Current Value</th><th>Expected Value</th><th>Severity</th><th>Advice</th></tr>
+{{- range .ConfigAdvices}}
+<tr><td>{{.Name}}</td><td>{{.Value}}</td><td>{{.ExpectedValue}}</td><td>{{severity .Severity}}</td><td>{{.Advice}}</td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>All the variables satisfy the rules.</p>
+{{- end}}
+<h2>Top Slow Queries</h2>
+{{- if .TopSlowQueries}}
+<table>
+<tr><th>SQL ID</th><th>DB Name</th><th>Exec Count</th><th>Avg Exec Time</th><th>Rows Examined Max</th><th>Fingerprint</th></tr>
+{{- range .TopSlowQueries}}
+<tr><td>{{.SQLID}}</td><td>{{.DBName}}</td><td>{{.ExecCount}}</td><td>{{.AvgExecTime}}</td><td>{{.RowsExaminedMax}}</td><td><code>{{.Fingerprint}}</code></td></tr>
+{{- end}}
+</table>
+{{- else}}
+<p>No slow query was found.</p>
+{{- end}}
+{{- if .SlowQueryAdvice}}
+<h3>Slow Query Advice</h3>
+<pre>{{.SlowQueryAdvice}}</pre>
+{{- end}}
+</body>
+</html>
+`
+
+const reportMarkdownTemplate = `# Healthcheck Report
+
+| Operation ID | MySQL Server ID | Check Range | Weighted Average Score |
+| --- | --- | --- | --- |
+| {{.OperationID}} | {{.MySQLServerID}} | {{formatTime .StartTime}} ~ {{formatTime .EndTime}} | {{.WeightedAverageScore}} |
+
+## Item Scores
+
+| Item | Score | Trend |
+| --- | --- | --- |
+{{- range .Items}}
+{{- if .Error}}
+| {{.ItemName}} | unavailable | {{cell .Error}} |
+{{- else}}
+| {{.ItemName}} | {{.Score}} | {{with .SparklineDataURI}}![trend]({{.}}){{end}} |
+{{- end}}
+{{- end}}
+
+## Watermark Breaches
+{{range .Items}}
+{{- if .Breaches}}
+### {{.ItemName}}
+
+| Timestamp | Value |
+| --- | --- |
+{{- range .Breaches}}
+| {{.Timestamp}} | {{.Value}} |
+{{- end}}
+{{else if .High}}
+### {{.ItemName}}
+
+` + "```json" + `
+{{.High}}
+` + "```" + `
+{{end}}
+{{- end}}
+## Config Advice
+{{if .ConfigAdvices}}
+| Variable | Current Value | Expected Value | Severity | Advice |
+| --- | --- | --- | --- | --- |
+{{- range .ConfigAdvices}}
+| {{cell .Name}} | {{cell .Value}} | {{cell .ExpectedValue}} | {{severity .Severity}} | {{cell .Advice}} |
+{{- end}}
+{{else}}
+All the variables satisfy the rules.
+{{end}}
+## Top Slow Queries
+{{if .TopSlowQueries}}
+| SQL ID | DB Name | Exec Count | Avg Exec Time | Rows Examined Max | Fingerprint |
+| --- | --- | --- | --- | --- | --- |
+{{- range .TopSlowQueries}}
+| {{.SQLID}} | {{cell .DBName}} | {{.ExecCount}} | {{.AvgExecTime}} | {{.RowsExaminedMax}} | ` + "`{{cell .Fingerprint}}`" + ` |
+{{- end}}
+{{else}}
+No slow query was found.
+{{end}}
+{{- if .SlowQueryAdvice}}
+### Slow Query Advice
+
+` + "```" + `
+{{.SlowQueryAdvice}}
+` + "```" + `
+{{- end}}
+`
@@ -0,0 +1,96 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReport() *Report {
+	result := NewEmptyResult()
+	result.OperationID = 1
+	result.WeightedAverageScore = 75
+	result.setItemResult(NewItemResult(defaultDBConfigItemName, 80, "[]", "",
+		`[{"name":"sync_binlog","value":"0","advice":"1","expected_value":"1","severity":3}]`))
+	result.setItemResult(NewItemResult(defaultCPUUsageItemName, 70,
+		`[{"timestamp":"1","value":10},{"timestamp":"2","value":95},{"timestamp":"3","value":20}]`,
+		`[{"timestamp":"2","value":95}]`, ""))
+	result.setItemResult(NewItemResult(defaultTableRowsItemName, 90, "[]",
+		`[{"table_schema":"db1","table_name":"t01","table_rows":10000000}]`, ""))
+	result.setItemResult(NewItemResult(defaultSlowQueryRowsExaminedItemName, 60,
+		`[{"sql_id":"A1","fingerprint":"select * from t01 where a | b = ?","db_name":"db1","exec_count":10}]`, "", "add index on t01(a)"))
+	result.setItemResult(NewUnavailableItemResult(defaultReplicationItemName, errors.New("replication timed out")))
+
+	operation := &Operation{
+		MySQLServerID: 2,
+		StartTime:     time.Date(2021, 1, 21, 9, 0, 0, 0, time.Local),
+		EndTime:       time.Date(2021, 1, 21, 10, 0, 0, 0, time.Local),
+	}
+
+	return NewReport(operation, result)
+}
+
+func TestReportAll(t *testing.T) {
+	TestReport_unmarshalPrometheusDatas(t)
+	TestReport_NewReport(t)
+	TestReport_RenderHTML(t)
+	TestReport_RenderMarkdown(t)
+}
+
+func TestReport_unmarshalPrometheusDatas(t *testing.T) {
+	asst := assert.New(t)
+
+	datas, ok := unmarshalPrometheusDatas(`[{"timestamp":"1","value":10}]`)
+	asst.True(ok, "test unmarshalPrometheusDatas() failed")
+	asst.Equal(10.0, datas[0].Value, "test unmarshalPrometheusDatas() failed")
+	_, ok = unmarshalPrometheusDatas(`[{"table_schema":"db1","table_name":"t01"}]`)
+	asst.False(ok, "test unmarshalPrometheusDatas() failed")
+	_, ok = unmarshalPrometheusDatas(`{"is_replica":false}`)
+	asst.False(ok, "test unmarshalPrometheusDatas() failed")
+	_, ok = unmarshalPrometheusDatas("[]")
+	asst.False(ok, "test unmarshalPrometheusDatas() failed")
+}
+
+func TestReport_NewReport(t *testing.T) {
+	asst := assert.New(t)
+
+	report := newTestReport()
+	asst.Equal(2, report.MySQLServerID, "test NewReport() failed")
+	asst.Equal(5, len(report.Items), "test NewReport() failed")
+	asst.Equal("replication timed out", report.Items[4].Error, "test NewReport() failed")
+	asst.Equal(3, len(report.Items[1].Series), "test NewReport() failed")
+	asst.Equal(1, len(report.Items[1].Breaches), "test NewReport() failed")
+	asst.Contains(report.Items[2].High, `"table_name": "t01"`, "test NewReport() failed")
+	asst.Equal("sync_binlog", report.ConfigAdvices[0].Name, "test NewReport() failed")
+	asst.Equal("A1", report.TopSlowQueries[0].GetSQLID(), "test NewReport() failed")
+	_, err := json.Marshal(report)
+	asst.Nil(err, common.CombineMessageWithError("test NewReport() failed", err))
+}
+
+func TestReport_RenderHTML(t *testing.T) {
+	asst := assert.New(t)
+
+	html, err := newTestReport().RenderHTML()
+	asst.Nil(err, common.CombineMessageWithError("test RenderHTML() failed", err))
+	asst.Contains(string(html), "<polyline", "test RenderHTML() failed")
+	asst.Contains(string(html), "2021-01-21 09:00:00 ~ 2021-01-21 10:00:00", "test RenderHTML() failed")
+	asst.Contains(string(html), "<td>sync_binlog</td>", "test RenderHTML() failed")
+	asst.Contains(string(html), "add index on t01(a)", "test RenderHTML() failed")
+	asst.Contains(string(html), "<td>replication</td><td class=\"low\">unavailable</td><td>replication timed out</td>", "test RenderHTML() failed")
+}
+
+func TestReport_RenderMarkdown(t *testing.T) {
+	asst := assert.New(t)
+
+	markdown, err := newTestReport().RenderMarkdown()
+	asst.Nil(err, common.CombineMessageWithError("test RenderMarkdown() failed", err))
+	asst.Contains(string(markdown), "![trend](data:image/svg+xml;base64,", "test RenderMarkdown() failed")
+	asst.Contains(string(markdown), "| 2 | 95 |", "test RenderMarkdown() failed")
+	asst.Contains(string(markdown), `a \| b`, "test RenderMarkdown() failed")
+	asst.Contains(string(markdown), "| sync_binlog | 0 | 1 | high | 1 |", "test RenderMarkdown() failed")
+	asst.Contains(string(markdown), "| replication | unavailable | replication timed out |", "test RenderMarkdown() failed")
+}
@@ -2,7 +2,7 @@ package healthcheck
 
 import (
 	"fmt"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/go-version"
@@ -10,8 +10,6 @@ import (
 	"github.com/romberli/das/internal/app/query"
 	"github.com/romberli/das/internal/dependency/healthcheck"
 	depquery "github.com/romberli/das/internal/dependency/query"
-	"github.com/romberli/das/pkg/message"
-	msghc "github.com/romberli/das/pkg/message/healthcheck"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -42,6 +40,7 @@ var (
 	_ healthcheck.PrometheusRepo       = (*PrometheusRepo)(nil)
 	_ healthcheck.QueryRepo            = (*MySQLQueryRepo)(nil)
 	_ healthcheck.QueryRepo            = (*ClickhouseQueryRepo)(nil)
+	_ healthcheck.QueryRepo            = (*EmptyQueryRepo)(nil)
 )
 
 // DASRepo for health check
@@ -89,10 +88,11 @@ func (dr *DASRepo) Transaction() (middleware.Transaction, error) {
 func (dr *DASRepo) LoadEngineConfig() (healthcheck.EngineConfig, error) {
 	// load config
 	sql := `
-		select id, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
-		score_deduction_per_unit_medium, max_score_deduction_medium, del_flag, create_time, last_update_time
+		select id, config_version, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
+		score_deduction_per_unit_medium, max_score_deduction_medium, scoring_strategy, del_flag, create_time, last_update_time
 		from t_hc_default_engine_config
-		where del_flag = 0;
+		where del_flag = 0
+		and config_version = (select max(config_version) from t_hc_default_engine_config where del_flag = 0);
 	`
 	log.Debugf("healthcheck DASRepo.loadEngineConfig() sql: \n%s\n", sql)
 	result, err := dr.Execute(sql)
@@ -117,21 +117,90 @@ func (dr *DASRepo) LoadEngineConfig() (healthcheck.EngineConfig, error) {
 	return defaultEngineConfig, nil
 }
 
+// GetAppLevelByMySQLClusterID returns the highest level of the apps which use the mysql cluster, it returns 0 if there is no app,
+// as 1 means level A, the highest level is the minimum value
+func (dr *DASRepo) GetAppLevelByMySQLClusterID(mysqlClusterID int) (int, error) {
+	sql := `
+		select ifnull(min(ai.level), 0)
+		from t_meta_app_info ai
+			inner join t_meta_app_db_map adm on ai.id = adm.app_id
+			inner join t_meta_db_info di on adm.db_id = di.id
+		where ai.del_flag = 0
+		and adm.del_flag = 0
+		and di.del_flag = 0
+		and di.cluster_id = ?
+		and di.cluster_type = 1;
+	`
+	log.Debugf("healthCheck DASRepo.GetAppLevelByMySQLClusterID() select sql: \n%s\nplaceholders: %s", sql, mysqlClusterID)
+
+	result, err := dr.Execute(sql, mysqlClusterID)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return result.GetInt(constant.ZeroInt, constant.ZeroInt)
+}
+
+// GetEngineConfigOverrides returns the engine config overrides of given env, app level and mysql cluster in the layering order
+func (dr *DASRepo) GetEngineConfigOverrides(envID, appLevel, mysqlClusterID int) ([]healthcheck.EngineConfigOverride, error) {
+	sql := `
+		select id, scope_type, scope_id, item_name, config, del_flag, create_time, last_update_time
+		from t_hc_engine_config_override
+		where del_flag = 0
+		and ((scope_type = ? and scope_id = ?) or (scope_type = ? and scope_id = ?) or (scope_type = ? and scope_id = ?))
+		order by scope_type, id;
+	`
+	log.Debugf("healthCheck DASRepo.GetEngineConfigOverrides() select sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s",
+		sql, EngineConfigOverrideScopeEnv, envID, EngineConfigOverrideScopeAppLevel, appLevel, EngineConfigOverrideScopeMySQLCluster, mysqlClusterID)
+
+	result, err := dr.Execute(sql, EngineConfigOverrideScopeEnv, envID, EngineConfigOverrideScopeAppLevel, appLevel,
+		EngineConfigOverrideScopeMySQLCluster, mysqlClusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getEngineConfigOverridesFromResult(result)
+}
+
+// GetDBConfigRules gets the database config rules of the latest rule set version from the middleware
+func (dr *DASRepo) GetDBConfigRules() ([]healthcheck.DBConfigRule, error) {
+	sql := `
+		select id, rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version,
+		env_id, role, severity, advice, del_flag, create_time, last_update_time
+		from t_hc_db_config_rule
+		where del_flag = 0
+		and rule_set_version = (select max(rule_set_version) from t_hc_db_config_rule where del_flag = 0)
+		order by id;
+	`
+	log.Debugf("healthCheck DASRepo.GetDBConfigRules() select sql: \n%s\n", sql)
+
+	result, err := dr.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+	// init []*DBConfigRule
+	ruleList := make([]*DBConfigRule, result.RowNumber())
+	for i := range ruleList {
+		ruleList[i] = NewEmptyDBConfigRule()
+	}
+	// map to struct
+	err = result.MapToStructSlice(ruleList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.DBConfigRule
+	rules := make([]healthcheck.DBConfigRule, result.RowNumber())
+	for i := range rules {
+		rules[i] = ruleList[i]
+	}
+
+	return rules, nil
+}
+
 // GetResultByOperationID gets a Result by the operationID from the middleware
 func (dr *DASRepo) GetResultByOperationID(operationID int) (healthcheck.Result, error) {
 	sql := `
-		select id, operation_id, weighted_average_score,
-		db_config_score, db_config_data, db_config_advice,
-		cpu_usage_score, cpu_usage_data, cpu_usage_high,
-		io_util_score, io_util_data, io_util_high,
-		disk_capacity_usage_score, disk_capacity_usage_data, disk_capacity_usage_high,
-		connection_usage_score, connection_usage_data, connection_usage_high,
-		average_active_session_percents_score, average_active_session_percents_data, average_active_session_percents_high,
-		cache_miss_ratio_score, cache_miss_ratio_data, cache_miss_ratio_high,
-		table_rows_score, table_rows_data, table_rows_high,
-		table_size_score, table_size_data, table_size_high,
-		slow_query_score, slow_query_data, slow_query_advice,
-		accuracy_review, del_flag, create_time, last_update_time
+		select id, operation_id, weighted_average_score, engine_config, accuracy_review, del_flag, create_time, last_update_time
 		from t_hc_result
 		where del_flag = 0
 		and operation_id = ? 
@@ -153,6 +222,11 @@ func (dr *DASRepo) GetResultByOperationID(operationID int) (healthcheck.Result,
 		if err != nil {
 			return nil, err
 		}
+		// set the item results
+		err = dr.setResultItems(hcInfo)
+		if err != nil {
+			return nil, err
+		}
 
 		return hcInfo, nil
 	default:
@@ -160,6 +234,197 @@ func (dr *DASRepo) GetResultByOperationID(operationID int) (healthcheck.Result,
 	}
 }
 
+// GetResultsByMySQLServerID gets the results of the mysql server which were checked in given time range from the middleware
+func (dr *DASRepo) GetResultsByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) ([]healthcheck.Result, error) {
+	startTimeStr := startTime.Format(constant.TimeLayoutSecond)
+	endTimeStr := endTime.Format(constant.TimeLayoutSecond)
+
+	sql := `
+		select hr.id, hr.operation_id, hr.weighted_average_score, hr.engine_config,
+		hr.accuracy_review, hr.del_flag, hr.create_time, hr.last_update_time
+		from t_hc_result hr
+		inner join t_hc_operation_info hoi on hr.operation_id = hoi.id
+		where hr.del_flag = 0
+		and hoi.del_flag = 0
+		and hoi.mysql_server_id = ?
+		and hr.create_time >= ?
+		and hr.create_time <= ?
+		order by hr.create_time;
+	`
+	log.Debugf("healthCheck DASRepo.GetResultsByMySQLServerID() select sql: \n%s\nplaceholders: %s, %s, %s", sql, mysqlServerID, startTimeStr, endTimeStr)
+
+	result, err := dr.Execute(sql, mysqlServerID, startTimeStr, endTimeStr)
+	if err != nil {
+		return nil, err
+	}
+	// init []*Result
+	resultList := make([]*Result, result.RowNumber())
+	for i := range resultList {
+		resultList[i] = NewEmptyResultWithRepo(dr)
+	}
+	// map to struct
+	err = result.MapToStructSlice(resultList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// set the item results
+	err = dr.setResultItems(resultList...)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.Result
+	results := make([]healthcheck.Result, result.RowNumber())
+	for i := range results {
+		results[i] = resultList[i]
+	}
+
+	return results, nil
+}
+
+// GetResultItemsByOperationIDs gets the item results of given operations from the middleware
+func (dr *DASRepo) GetResultItemsByOperationIDs(operationIDs ...int) ([]healthcheck.ResultItem, error) {
+	if len(operationIDs) == constant.ZeroInt {
+		return nil, nil
+	}
+
+	interfaces, err := common.ConvertInterfaceToSliceInterface(operationIDs)
+	if err != nil {
+		return nil, err
+	}
+	inClause, err := middleware.ConvertSliceToString(interfaces...)
+	if err != nil {
+		return nil, err
+	}
+	sql := fmt.Sprintf(`
+		select id, operation_id, item_name, score, data, high, advice, status, error, del_flag, create_time, last_update_time
+		from t_hc_result_item
+		where del_flag = 0
+		and operation_id in (%s)
+		order by operation_id, id;
+	`, inClause)
+	log.Debugf("healthCheck DASRepo.GetResultItemsByOperationIDs() select sql: \n%s", sql)
+
+	result, err := dr.Execute(sql)
+	if err != nil {
+		return nil, err
+	}
+	// init []*ResultItem
+	itemList := make([]*ResultItem, result.RowNumber())
+	for i := range itemList {
+		itemList[i] = NewEmptyResultItem()
+	}
+	// map to struct
+	err = result.MapToStructSlice(itemList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.ResultItem
+	items := make([]healthcheck.ResultItem, result.RowNumber())
+	for i := range items {
+		items[i] = itemList[i]
+	}
+
+	return items, nil
+}
+
+// setResultItems gets the item results of given results from the middleware and sets them to the results
+func (dr *DASRepo) setResultItems(results ...*Result) error {
+	operationIDs := make([]int, len(results))
+	resultMap := make(map[int]*Result, len(results))
+	for i, r := range results {
+		operationIDs[i] = r.GetOperationID()
+		resultMap[r.GetOperationID()] = r
+	}
+
+	items, err := dr.GetResultItemsByOperationIDs(operationIDs...)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		r, ok := resultMap[item.GetOperationID()]
+		if ok {
+			r.setResultItem(item.(*ResultItem))
+		}
+	}
+
+	return nil
+}
+
+// GetOperationByID gets the operation by the operation id from the middleware
+func (dr *DASRepo) GetOperationByID(operationID int) (healthcheck.Operation, error) {
+	sql := `
+		select id, cluster_operation_id, mysql_server_id, start_time, end_time, step, engine_config_version, status, message,
+		del_flag, create_time, last_update_time
+		from t_hc_operation_info
+		where del_flag = 0
+		and id = ?;
+	`
+	log.Debugf("healthCheck DASRepo.GetOperationByID() select sql: \n%s\nplaceholders: %s", sql, operationID)
+
+	result, err := dr.Execute(sql, operationID)
+	if err != nil {
+		return nil, err
+	}
+	switch result.RowNumber() {
+	case 0:
+		return nil, fmt.Errorf("healthCheck DASRepo.GetOperationByID(): data does not exists, operation_id: %d", operationID)
+	case 1:
+		operation := NewEmptyOperation()
+		// map to struct
+		err = result.MapToStructByRowIndex(operation, constant.ZeroInt, constant.DefaultMiddlewareTag)
+		if err != nil {
+			return nil, err
+		}
+
+		return operation, nil
+	default:
+		return nil, fmt.Errorf("healthCheck DASRepo.GetOperationByID(): duplicate key exists, operation_id: %d", operationID)
+	}
+}
+
+// GetOperations gets the operations which were created in given time range from the middleware,
+// mysql server id and status are optional filters, they will be ignored if they are negative
+func (dr *DASRepo) GetOperations(mysqlServerID, status int, startTime, endTime time.Time) ([]healthcheck.Operation, error) {
+	startTimeStr := startTime.Format(constant.TimeLayoutSecond)
+	endTimeStr := endTime.Format(constant.TimeLayoutSecond)
+
+	sql := `
+		select id, cluster_operation_id, mysql_server_id, start_time, end_time, step, engine_config_version, status, message,
+		del_flag, create_time, last_update_time
+		from t_hc_operation_info
+		where del_flag = 0
+		and create_time >= ?
+		and create_time <= ?
+		and (? < 0 or mysql_server_id = ?)
+		and (? < 0 or status = ?)
+		order by id desc;
+	`
+	log.Debugf("healthCheck DASRepo.GetOperations() select sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s",
+		sql, startTimeStr, endTimeStr, mysqlServerID, mysqlServerID, status, status)
+
+	result, err := dr.Execute(sql, startTimeStr, endTimeStr, mysqlServerID, mysqlServerID, status, status)
+	if err != nil {
+		return nil, err
+	}
+	// init []*Operation
+	operationList := make([]*Operation, result.RowNumber())
+	for i := range operationList {
+		operationList[i] = NewEmptyOperation()
+	}
+	// map to struct
+	err = result.MapToStructSlice(operationList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.Operation
+	operations := make([]healthcheck.Operation, result.RowNumber())
+	for i := range operations {
+		operations[i] = operationList[i]
+	}
+
+	return operations, nil
+}
+
 // IsRunning gets status by the mysqlServerID from the middleware
 func (dr *DASRepo) IsRunning(mysqlServerID int) (bool, error) {
 	sql := `select count(1) from t_hc_operation_info where del_flag = 0 and mysql_server_id = ? and status = 1;`
@@ -176,27 +441,39 @@ func (dr *DASRepo) IsRunning(mysqlServerID int) (bool, error) {
 
 // InitOperation creates a testOperationInfo in the middleware
 func (dr *DASRepo) InitOperation(mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error) {
+	return dr.initOperation(constant.ZeroInt, mysqlServerID, startTime, endTime, step)
+}
+
+// InitMemberOperation creates an operation which belongs to the cluster operation in the middleware
+func (dr *DASRepo) InitMemberOperation(clusterOperationID, mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error) {
+	return dr.initOperation(clusterOperationID, mysqlServerID, startTime, endTime, step)
+}
+
+// initOperation creates an operation in the middleware, the status of the new operation is running,
+// cluster operation id is 0 if the operation does not belong to any cluster operation
+func (dr *DASRepo) initOperation(clusterOperationID, mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error) {
 	startTimeStr := startTime.Format(constant.TimeLayoutSecond)
 	endTimeStr := endTime.Format(constant.TimeLayoutSecond)
 	stepInt := int(step.Seconds())
 
-	sql := `insert into t_hc_operation_info(mysql_server_id, start_time, end_time, step) values(?, ?, ?, ?);`
-	log.Debugf("healthCheck DASRepo.InitOperation() insert sql: \n%s\nplaceholders: %s, %s, %s, %s",
-		sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	sql := `insert into t_hc_operation_info(cluster_operation_id, mysql_server_id, start_time, end_time, step, status) values(?, ?, ?, ?, ?, ?);`
+	log.Debugf("healthCheck DASRepo.initOperation() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s",
+		sql, clusterOperationID, mysqlServerID, startTimeStr, endTimeStr, stepInt, defaultRunningStatus)
 
-	_, err := dr.Execute(sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	_, err := dr.Execute(sql, clusterOperationID, mysqlServerID, startTimeStr, endTimeStr, stepInt, defaultRunningStatus)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
 
 	sql = `
 		select id from t_hc_operation_info where del_flag = 0 and 
-		mysql_server_id = ? and start_time = ? and end_time = ? and step = ?;
+		cluster_operation_id = ? and mysql_server_id = ? and start_time = ? and end_time = ? and step = ?
+		order by id desc limit 1;
 	`
-	log.Debugf("healthCheck DASRepo.InitOperation() select sql: \n%s\nplaceholders: %s, %s, %s, %s",
-		sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	log.Debugf("healthCheck DASRepo.initOperation() select sql: \n%s\nplaceholders: %s, %s, %s, %s, %s",
+		sql, clusterOperationID, mysqlServerID, startTimeStr, endTimeStr, stepInt)
 
-	result, err := dr.Execute(sql, mysqlServerID, startTimeStr, endTimeStr, stepInt)
+	result, err := dr.Execute(sql, clusterOperationID, mysqlServerID, startTimeStr, endTimeStr, stepInt)
 	if err != nil {
 		return constant.ZeroInt, err
 	}
@@ -213,48 +490,54 @@ func (dr *DASRepo) UpdateOperationStatus(operationID int, status int, message st
 	return err
 }
 
-// SaveResult saves the result in the middleware
-func (dr *DASRepo) SaveResult(result healthcheck.Result) error {
-	sql := `insert into t_hc_result(operation_id, weighted_average_score, db_config_score, db_config_data, 
-		db_config_advice, cpu_usage_score, cpu_usage_data, cpu_usage_high, io_util_score,
-		io_util_data, io_util_high, disk_capacity_usage_score, disk_capacity_usage_data, 
-		disk_capacity_usage_high, connection_usage_score, connection_usage_data, 
-		connection_usage_high, average_active_session_percents_score, average_active_session_percents_data,
-		average_active_session_percents_high, cache_miss_ratio_score, cache_miss_ratio_data, 
-		cache_miss_ratio_high, table_rows_score, table_rows_data, table_rows_high,
-		table_size_score, table_size_data, table_size_high, slow_query_score,
-		slow_query_data, slow_query_advice, accuracy_review)
-		values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
-	`
-	log.Debugf("healthCheck DASRepo.SaveResult() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %ss, %s, %s, %s",
-		sql, result.GetOperationID(), result.GetWeightedAverageScore(),
-		result.GetDBConfigScore(), result.GetDBConfigData(), result.GetDBConfigAdvice(),
-		result.GetCPUUsageScore(), result.GetCPUUsageData(), result.GetCPUUsageHigh(),
-		result.GetIOUtilScore(), result.GetIOUtilData(), result.GetIOUtilHigh(),
-		result.GetDiskCapacityUsageScore(), result.GetDiskCapacityUsageData(), result.GetDiskCapacityUsageHigh(),
-		result.GetConnectionUsageScore(), result.GetConnectionUsageData(), result.GetConnectionUsageHigh(),
-		result.GetAverageActiveSessionPercentsScore(), result.GetAverageActiveSessionPercentsData(), result.GetAverageActiveSessionPercentsHigh(),
-		result.GetCacheMissRatioScore(), result.GetCacheMissRatioData(), result.GetCacheMissRatioHigh(),
-		result.GetTableRowsScore(), result.GetTableRowsData(), result.GetTableRowsHigh(),
-		result.GetTableSizeScore(), result.GetTableSizeData(), result.GetTableSizeHigh(),
-		result.GetSlowQueryScore(), result.GetSlowQueryData(), result.GetSlowQueryAdvice(), result.GetAccuracyReview())
-
-	// execute
-	_, err := dr.Execute(sql, result.GetOperationID(), result.GetWeightedAverageScore(),
-		result.GetDBConfigScore(), result.GetDBConfigData(), result.GetDBConfigAdvice(),
-		result.GetCPUUsageScore(), result.GetCPUUsageData(), result.GetCPUUsageHigh(),
-		result.GetIOUtilScore(), result.GetIOUtilData(), result.GetIOUtilHigh(),
-		result.GetDiskCapacityUsageScore(), result.GetDiskCapacityUsageData(), result.GetDiskCapacityUsageHigh(),
-		result.GetConnectionUsageScore(), result.GetConnectionUsageData(), result.GetConnectionUsageHigh(),
-		result.GetAverageActiveSessionPercentsScore(), result.GetAverageActiveSessionPercentsData(), result.GetAverageActiveSessionPercentsHigh(),
-		result.GetCacheMissRatioScore(), result.GetCacheMissRatioData(), result.GetCacheMissRatioHigh(),
-		result.GetTableRowsScore(), result.GetTableRowsData(), result.GetTableRowsHigh(),
-		result.GetTableSizeScore(), result.GetTableSizeData(), result.GetTableSizeHigh(),
-		result.GetSlowQueryScore(), result.GetSlowQueryData(), result.GetSlowQueryAdvice(), result.GetAccuracyReview())
+// UpdateOperationEngineConfigVersion updates the version of the engine config which the operation is scored with in the middleware
+func (dr *DASRepo) UpdateOperationEngineConfigVersion(operationID int, version int) error {
+	sql := `update t_hc_operation_info set engine_config_version = ? where id = ?;`
+	log.Debugf("healthCheck DASRepo.UpdateOperationEngineConfigVersion() update sql: \n%s\nplaceholders: %s, %s", sql, version, operationID)
+	_, err := dr.Execute(sql, version, operationID)
 
 	return err
 }
 
+// SaveResult saves the result and the item results in the middleware
+func (dr *DASRepo) SaveResult(result healthcheck.Result) error {
+	tx, err := dr.Transaction()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = tx.Close()
+		if err != nil {
+			log.Errorf("healthcheck DASRepo.SaveResult(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	err = tx.Begin()
+	if err != nil {
+		return err
+	}
+	sql := `insert into t_hc_result(operation_id, weighted_average_score, engine_config, accuracy_review) values(?, ?, ?, ?);`
+	log.Debugf("healthCheck DASRepo.SaveResult() insert sql: \n%s\nplaceholders: %s, %s, %s, %s",
+		sql, result.GetOperationID(), result.GetWeightedAverageScore(), result.GetEngineConfig(), result.GetAccuracyReview())
+	_, err = tx.Execute(sql, result.GetOperationID(), result.GetWeightedAverageScore(), result.GetEngineConfig(), result.GetAccuracyReview())
+	if err != nil {
+		return err
+	}
+
+	sql = `insert into t_hc_result_item(operation_id, item_name, score, data, high, advice, status, error) values(?, ?, ?, ?, ?, ?, ?, ?);`
+	for _, item := range result.GetItems() {
+		log.Debugf("healthCheck DASRepo.SaveResult() insert sql: \n%s\nplaceholders: %s, %s, %s, %s",
+			sql, result.GetOperationID(), item.GetItemName(), item.GetScore(), item.GetStatus())
+		_, err = tx.Execute(sql, result.GetOperationID(), item.GetItemName(), item.GetScore(), item.GetData(), item.GetHigh(), item.GetAdvice(),
+			item.GetStatus(), item.GetError())
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
 // UpdateAccuracyReviewByOperationID updates the accuracyReview by the operationID in the middleware
 func (dr *DASRepo) UpdateAccuracyReviewByOperationID(operationID int, review int) error {
 	sql := `update t_hc_result set accuracy_review = ? where operation_id = ?;`
@@ -264,14 +547,209 @@ func (dr *DASRepo) UpdateAccuracyReviewByOperationID(operationID int, review int
 	return err
 }
 
+// IsClusterRunning returns if the healthcheck of given mysql cluster is still running
+func (dr *DASRepo) IsClusterRunning(mysqlClusterID int) (bool, error) {
+	sql := `select count(1) from t_hc_cluster_operation_info where del_flag = 0 and mysql_cluster_id = ? and status = 1;`
+	log.Debugf("healthCheck DASRepo.IsClusterRunning() select sql: \n%s\nplaceholders: %s", sql, mysqlClusterID)
+
+	result, err := dr.Execute(sql, mysqlClusterID)
+	if err != nil {
+		return false, err
+	}
+	count, _ := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+
+	return count != 0, nil
+}
+
+// InitClusterOperation creates a cluster operation in the middleware, the status of the new cluster operation is running
+func (dr *DASRepo) InitClusterOperation(mysqlClusterID int, startTime, endTime time.Time, step time.Duration) (int, error) {
+	startTimeStr := startTime.Format(constant.TimeLayoutSecond)
+	endTimeStr := endTime.Format(constant.TimeLayoutSecond)
+	stepInt := int(step.Seconds())
+
+	sql := `insert into t_hc_cluster_operation_info(mysql_cluster_id, start_time, end_time, step, status) values(?, ?, ?, ?, ?);`
+	log.Debugf("healthCheck DASRepo.InitClusterOperation() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s",
+		sql, mysqlClusterID, startTimeStr, endTimeStr, stepInt, defaultRunningStatus)
+
+	_, err := dr.Execute(sql, mysqlClusterID, startTimeStr, endTimeStr, stepInt, defaultRunningStatus)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	sql = `
+		select id from t_hc_cluster_operation_info where del_flag = 0 and 
+		mysql_cluster_id = ? and start_time = ? and end_time = ? and step = ?
+		order by id desc limit 1;
+	`
+	log.Debugf("healthCheck DASRepo.InitClusterOperation() select sql: \n%s\nplaceholders: %s, %s, %s, %s",
+		sql, mysqlClusterID, startTimeStr, endTimeStr, stepInt)
+
+	result, err := dr.Execute(sql, mysqlClusterID, startTimeStr, endTimeStr, stepInt)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return result.GetInt(constant.ZeroInt, constant.ZeroInt)
+}
+
+// UpdateClusterOperationStatus updates the status and message by the cluster operation id in the middleware
+func (dr *DASRepo) UpdateClusterOperationStatus(clusterOperationID int, status int, message string) error {
+	sql := `update t_hc_cluster_operation_info set status = ?, message = ? where id = ?;`
+	log.Debugf("healthCheck DASRepo.UpdateClusterOperationStatus() update sql: \n%s\nplaceholders: %s, %s, %s", sql, status, message, clusterOperationID)
+	_, err := dr.Execute(sql, status, message, clusterOperationID)
+
+	return err
+}
+
+// GetClusterResultByOperationID gets the cluster result by the cluster operation id from the middleware
+func (dr *DASRepo) GetClusterResultByOperationID(clusterOperationID int) (healthcheck.ClusterResult, error) {
+	sql := `
+		select id, cluster_operation_id, worst_score, worst_mysql_server_id, server_results, config_drift,
+		del_flag, create_time, last_update_time
+		from t_hc_cluster_result
+		where del_flag = 0
+		and cluster_operation_id = ?;
+	`
+	log.Debugf("healthCheck DASRepo.GetClusterResultByOperationID() select sql: \n%s\nplaceholders: %s", sql, clusterOperationID)
+
+	result, err := dr.Execute(sql, clusterOperationID)
+	if err != nil {
+		return nil, err
+	}
+	switch result.RowNumber() {
+	case 0:
+		return nil, fmt.Errorf("healthCheck DASRepo.GetClusterResultByOperationID(): data does not exists, cluster_operation_id: %d", clusterOperationID)
+	case 1:
+		clusterResult := NewEmptyClusterResult()
+		// map to struct
+		err = result.MapToStructByRowIndex(clusterResult, constant.ZeroInt, constant.DefaultMiddlewareTag)
+		if err != nil {
+			return nil, err
+		}
+
+		return clusterResult, nil
+	default:
+		return nil, fmt.Errorf("healthCheck DASRepo.GetClusterResultByOperationID(): duplicate key exists, cluster_operation_id: %d", clusterOperationID)
+	}
+}
+
+// SaveClusterResult saves the cluster result in the middleware
+func (dr *DASRepo) SaveClusterResult(clusterResult healthcheck.ClusterResult) error {
+	sql := `insert into t_hc_cluster_result(cluster_operation_id, worst_score, worst_mysql_server_id, server_results, config_drift)
+		values(?, ?, ?, ?, ?);`
+	log.Debugf("healthCheck DASRepo.SaveClusterResult() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s",
+		sql, clusterResult.GetClusterOperationID(), clusterResult.GetWorstScore(), clusterResult.GetWorstMySQLServerID(),
+		clusterResult.GetServerResults(), clusterResult.GetConfigDrift())
+
+	_, err := dr.Execute(sql, clusterResult.GetClusterOperationID(), clusterResult.GetWorstScore(), clusterResult.GetWorstMySQLServerID(),
+		clusterResult.GetServerResults(), clusterResult.GetConfigDrift())
+
+	return err
+}
+
+// IsMiddlewareClusterRunning checks whether the healthcheck of given middleware cluster is still running
+func (dr *DASRepo) IsMiddlewareClusterRunning(middlewareClusterID int) (bool, error) {
+	sql := `select count(1) from t_hc_middleware_operation_info where del_flag = 0 and middleware_cluster_id = ? and status = 1;`
+	log.Debugf("healthCheck DASRepo.IsMiddlewareClusterRunning() select sql: \n%s\nplaceholders: %s", sql, middlewareClusterID)
+
+	result, err := dr.Execute(sql, middlewareClusterID)
+	if err != nil {
+		return false, err
+	}
+	count, _ := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+
+	return count != 0, nil
+}
+
+// InitMiddlewareOperation creates a middleware operation in the middleware, the status of the new middleware operation is running
+func (dr *DASRepo) InitMiddlewareOperation(middlewareClusterID int) (int, error) {
+	sql := `insert into t_hc_middleware_operation_info(middleware_cluster_id, status) values(?, ?);`
+	log.Debugf("healthCheck DASRepo.InitMiddlewareOperation() insert sql: \n%s\nplaceholders: %s, %s", sql, middlewareClusterID, defaultRunningStatus)
+
+	_, err := dr.Execute(sql, middlewareClusterID, defaultRunningStatus)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	sql = `
+		select id from t_hc_middleware_operation_info where del_flag = 0 and
+		middleware_cluster_id = ? and status = ?
+		order by id desc limit 1;
+	`
+	log.Debugf("healthCheck DASRepo.InitMiddlewareOperation() select sql: \n%s\nplaceholders: %s, %s", sql, middlewareClusterID, defaultRunningStatus)
+
+	result, err := dr.Execute(sql, middlewareClusterID, defaultRunningStatus)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return result.GetInt(constant.ZeroInt, constant.ZeroInt)
+}
+
+// UpdateMiddlewareOperationStatus updates the status and message by the middleware operation id in the middleware
+func (dr *DASRepo) UpdateMiddlewareOperationStatus(middlewareOperationID int, status int, message string) error {
+	sql := `update t_hc_middleware_operation_info set status = ?, message = ? where id = ?;`
+	log.Debugf("healthCheck DASRepo.UpdateMiddlewareOperationStatus() update sql: \n%s\nplaceholders: %s, %s, %s", sql, status, message, middlewareOperationID)
+	_, err := dr.Execute(sql, status, message, middlewareOperationID)
+
+	return err
+}
+
+// GetMiddlewareResultByOperationID gets the middleware result by the middleware operation id from the middleware
+func (dr *DASRepo) GetMiddlewareResultByOperationID(middlewareOperationID int) (healthcheck.MiddlewareResult, error) {
+	sql := `
+		select id, middleware_operation_id, weighted_average_score, connectivity_score, backend_score, routing_score,
+		server_results, missing_backends, del_flag, create_time, last_update_time
+		from t_hc_middleware_result
+		where del_flag = 0
+		and middleware_operation_id = ?;
+	`
+	log.Debugf("healthCheck DASRepo.GetMiddlewareResultByOperationID() select sql: \n%s\nplaceholders: %s", sql, middlewareOperationID)
+
+	result, err := dr.Execute(sql, middlewareOperationID)
+	if err != nil {
+		return nil, err
+	}
+	switch result.RowNumber() {
+	case 0:
+		return nil, fmt.Errorf("healthCheck DASRepo.GetMiddlewareResultByOperationID(): data does not exists, middleware_operation_id: %d", middlewareOperationID)
+	case 1:
+		middlewareResult := NewEmptyMiddlewareResult()
+		// map to struct
+		err = result.MapToStructByRowIndex(middlewareResult, constant.ZeroInt, constant.DefaultMiddlewareTag)
+		if err != nil {
+			return nil, err
+		}
+
+		return middlewareResult, nil
+	default:
+		return nil, fmt.Errorf("healthCheck DASRepo.GetMiddlewareResultByOperationID(): duplicate key exists, middleware_operation_id: %d", middlewareOperationID)
+	}
+}
+
+// SaveMiddlewareResult saves the middleware result in the middleware
+func (dr *DASRepo) SaveMiddlewareResult(middlewareResult healthcheck.MiddlewareResult) error {
+	sql := `insert into t_hc_middleware_result(middleware_operation_id, weighted_average_score, connectivity_score, backend_score, routing_score,
+		server_results, missing_backends) values(?, ?, ?, ?, ?, ?, ?);`
+	log.Debugf("healthCheck DASRepo.SaveMiddlewareResult() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s, %s",
+		sql, middlewareResult.GetMiddlewareOperationID(), middlewareResult.GetWeightedAverageScore(), middlewareResult.GetConnectivityScore(),
+		middlewareResult.GetBackendScore(), middlewareResult.GetRoutingScore(), middlewareResult.GetServerResults(), middlewareResult.GetMissingBackends())
+
+	_, err := dr.Execute(sql, middlewareResult.GetMiddlewareOperationID(), middlewareResult.GetWeightedAverageScore(), middlewareResult.GetConnectivityScore(),
+		middlewareResult.GetBackendScore(), middlewareResult.GetRoutingScore(), middlewareResult.GetServerResults(), middlewareResult.GetMissingBackends())
+
+	return err
+}
+
 // loadEngineConfig loads engine config from the middleware
 func (dr *DASRepo) loadEngineConfig() (DefaultEngineConfig, error) {
 	// load config
 	sql := `
-		select id, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
-		score_deduction_per_unit_medium, max_score_deduction_medium, del_flag, create_time, last_update_time
+		select id, config_version, item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high,
+		score_deduction_per_unit_medium, max_score_deduction_medium, scoring_strategy, del_flag, create_time, last_update_time
 		from t_hc_default_engine_config
-		where del_flag = 0;
+		where del_flag = 0
+		and config_version = (select max(config_version) from t_hc_default_engine_config where del_flag = 0);
 	`
 	log.Debugf("healthcheck DASRepo.loadEngineConfig() sql: \n%s\n", sql)
 	result, err := dr.Execute(sql)
@@ -299,6 +777,7 @@ func (dr *DASRepo) loadEngineConfig() (DefaultEngineConfig, error) {
 type ApplicationMySQLRepo struct {
 	operationInfo *OperationInfo
 	conn          *mysql.Conn
+	mutex         sync.Mutex
 }
 
 // NewApplicationMySQLRepo returns a new *ApplicationMySQLRepo
@@ -324,6 +803,15 @@ func (amr *ApplicationMySQLRepo) Close() error {
 	return amr.getConnection().Close()
 }
 
+// execute executes the sql with given args, as the check items run concurrently and the connection is not concurrency safe,
+// it serializes the executions on the connection
+func (amr *ApplicationMySQLRepo) execute(sql string, args ...interface{}) (*mysql.Result, error) {
+	amr.mutex.Lock()
+	defer amr.mutex.Unlock()
+
+	return amr.getConnection().Execute(sql, args...)
+}
+
 // GetVariables gets db config with given items
 func (amr *ApplicationMySQLRepo) GetVariables(items []string) ([]healthcheck.Variable, error) {
 	// prepare args
@@ -350,7 +838,7 @@ func (amr *ApplicationMySQLRepo) GetVariables(items []string) ([]healthcheck.Var
 		sql = fmt.Sprintf(applicationMySQLVariables, informationSchema, inClause)
 	}
 	// get result
-	result, err := amr.getConnection().Execute(sql)
+	result, err := amr.execute(sql)
 	if err != nil {
 		return nil, err
 	}
@@ -381,9 +869,111 @@ func (amr *ApplicationMySQLRepo) GetMySQLDirs() ([]string, error) {
 	return dirs, nil
 }
 
+// IsReplica returns if the mysql server is a replica, the mysql server which has replication configured is considered as a replica
+func (amr *ApplicationMySQLRepo) IsReplica() (bool, error) {
+	result, err := amr.execute(applicationMySQLSlaveStatus)
+	if err != nil {
+		return false, err
+	}
+
+	return result.RowNumber() > constant.ZeroInt, nil
+}
+
+// GetReplicationStatus returns the replication status, it returns nil if the mysql server is not a replica
+func (amr *ApplicationMySQLRepo) GetReplicationStatus() (healthcheck.ReplicationStatus, error) {
+	result, err := amr.execute(applicationMySQLSlaveStatus)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return nil, nil
+	}
+
+	return newReplicationStatusWithResult(result)
+}
+
+// GetGTIDExecuted returns the executed gtid set of the mysql server
+func (amr *ApplicationMySQLRepo) GetGTIDExecuted() (string, error) {
+	result, err := amr.execute(applicationMySQLGTIDExecuted)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return result.GetString(constant.ZeroInt, constant.ZeroInt)
+}
+
+// GetLockWaits returns the innodb lock waits which are waiting now, the longest waits come first
+func (amr *ApplicationMySQLRepo) GetLockWaits() ([]healthcheck.LockWait, error) {
+	result, err := amr.execute(applicationMySQLLockWaits)
+	if err != nil {
+		return nil, err
+	}
+	lockWaits := make([]healthcheck.LockWait, result.RowNumber())
+	for i := range lockWaits {
+		lockWaits[i] = NewEmptyLockWait()
+	}
+	err = result.MapToStructSlice(lockWaits, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return lockWaits, nil
+}
+
+// GetInnoDBStatus returns the output of show engine innodb status
+func (amr *ApplicationMySQLRepo) GetInnoDBStatus() (string, error) {
+	result, err := amr.execute(applicationMySQLInnoDBStatus)
+	if err != nil {
+		return constant.EmptyString, err
+	}
+	if result.RowNumber() == constant.ZeroInt {
+		return constant.EmptyString, nil
+	}
+
+	return result.GetStringByName(constant.ZeroInt, innoDBStatusColumn)
+}
+
+// GetUnusedIndexes returns the indexes which have not been used since the mysql server started
+func (amr *ApplicationMySQLRepo) GetUnusedIndexes() ([]healthcheck.IndexFinding, error) {
+	return amr.getIndexFindings(applicationMySQLUnusedIndexes)
+}
+
+// GetRedundantIndexes returns the indexes which are covered by other indexes
+func (amr *ApplicationMySQLRepo) GetRedundantIndexes() ([]healthcheck.IndexFinding, error) {
+	return amr.getIndexFindings(applicationMySQLRedundantIndexes)
+}
+
+// GetTablesWithoutPrimaryKey returns the tables which do not have a primary key
+func (amr *ApplicationMySQLRepo) GetTablesWithoutPrimaryKey() ([]healthcheck.IndexFinding, error) {
+	return amr.getIndexFindings(applicationMySQLTablesWithoutPrimaryKey)
+}
+
+// GetNonInnoDBTables returns the tables of which the storage engine is not innodb
+func (amr *ApplicationMySQLRepo) GetNonInnoDBTables() ([]healthcheck.IndexFinding, error) {
+	return amr.getIndexFindings(applicationMySQLNonInnoDBTables)
+}
+
+// getIndexFindings executes the sql and maps the result to the index findings
+func (amr *ApplicationMySQLRepo) getIndexFindings(sql string) ([]healthcheck.IndexFinding, error) {
+	result, err := amr.execute(sql)
+	if err != nil {
+		return nil, err
+	}
+	indexFindings := make([]healthcheck.IndexFinding, result.RowNumber())
+	for i := range indexFindings {
+		indexFindings[i] = NewEmptyIndexFinding()
+	}
+	err = result.MapToStructSlice(indexFindings, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	return indexFindings, nil
+}
+
 // GetLargeTables gets the large tables
 func (amr *ApplicationMySQLRepo) GetLargeTables() ([]healthcheck.Table, error) {
-	result, err := amr.getConnection().Execute(applicationMySQLTableSize, minTableRows)
+	result, err := amr.execute(applicationMySQLTableSize, minTableRows)
 	if err != nil {
 		return nil, err
 	}
@@ -402,13 +992,20 @@ func (amr *ApplicationMySQLRepo) GetLargeTables() ([]healthcheck.Table, error) {
 type PrometheusRepo struct {
 	operationInfo *OperationInfo
 	conn          *prometheus.Conn
+	catalogue     *PrometheusCatalogue
 }
 
-// NewPrometheusRepo returns a new *PrometheusRepo
+// NewPrometheusRepo returns a new *PrometheusRepo, it uses the built-in catalogue of the monitor system type
 func NewPrometheusRepo(operationInfo *OperationInfo, conn *prometheus.Conn) *PrometheusRepo {
+	return NewPrometheusRepoWithCatalogue(operationInfo, conn, nil)
+}
+
+// NewPrometheusRepoWithCatalogue returns a new *PrometheusRepo which renders the queries with given catalogue
+func NewPrometheusRepoWithCatalogue(operationInfo *OperationInfo, conn *prometheus.Conn, catalogue *PrometheusCatalogue) *PrometheusRepo {
 	return &PrometheusRepo{
 		operationInfo: operationInfo,
 		conn:          conn,
+		catalogue:     catalogue,
 	}
 }
 
@@ -424,24 +1021,14 @@ func (pr *PrometheusRepo) getConnection() *prometheus.Conn {
 
 // GetFileSystems gets the file systems from the prometheus
 func (pr *PrometheusRepo) GetFileSystems() ([]healthcheck.FileSystem, error) {
-	var prometheusQuery string
-
 	// prepare query
-	switch pr.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = PrometheusFileSystemV1
-	case 2:
-		// pmm 2.x
-		prometheusQuery = PrometheusFileSystemV2
-	default:
-		return nil, message.NewMessage(msghc.ErrPmmVersionInvalid)
+	prometheusQuery, err := pr.render(PrometheusCatalogueFileSystem, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	prometheusQuery = fmt.Sprintf(prometheusQuery, pr.getNodeName())
 	log.Debugf("healthcheck PrometheusRepo.GetFileSystems() query: \n%s\n", prometheusQuery)
-	// get data
-	result, err := pr.getConnection().Execute(prometheusQuery)
+	// get data, the file systems are the ones at the end of the time window
+	result, err := pr.getConnection().Execute(prometheusQuery, pr.GetOperationInfo().GetEndTime())
 	if err != nil {
 		return nil, err
 	}
@@ -461,45 +1048,23 @@ func (pr *PrometheusRepo) GetFileSystems() ([]healthcheck.FileSystem, error) {
 
 // GetCPUUsage gets the cpu usage
 func (pr *PrometheusRepo) GetCPUUsage() ([]healthcheck.PrometheusData, error) {
-	var prometheusQuery string
-
 	// prepare query
-	switch pr.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = PrometheusCPUUsageV1
-	case 2:
-		// pmm 2.x
-		prometheusQuery = PrometheusCPUUsageV2
-	default:
-		return nil, message.NewMessage(msghc.ErrPmmVersionInvalid)
+	prometheusQuery, err := pr.render(PrometheusCatalogueCPUUsage, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	nodeName := pr.getNodeName()
-	prometheusQuery = fmt.Sprintf(prometheusQuery, nodeName, nodeName, nodeName, nodeName, nodeName, nodeName)
 	log.Debugf("healthcheck PrometheusRepo.GetCPUUsage() query: \n%s\n", prometheusQuery)
-
+	// get data
 	return pr.execute(prometheusQuery)
 }
 
 // GetIOUtil gets the io util
 func (pr *PrometheusRepo) GetIOUtil() ([]healthcheck.PrometheusData, error) {
-	var prometheusQuery string
-
-	nodeName := pr.getNodeName()
-
 	// prepare query
-	switch pr.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = fmt.Sprintf(PrometheusIOUtilV1, nodeName, nodeName)
-	case 2:
-		// pmm 2.x
-		prometheusQuery = fmt.Sprintf(PrometheusIOUtilV2, nodeName, nodeName, nodeName, nodeName)
-	default:
-		return nil, message.NewMessage(msghc.ErrPmmVersionInvalid)
+	prometheusQuery, err := pr.render(PrometheusCatalogueIOUtil, nil)
+	if err != nil {
+		return nil, err
 	}
-
 	log.Debugf("healthcheck PrometheusRepo.GetIOUtil() query: \n%s\n", prometheusQuery)
 	// get data
 	return pr.execute(prometheusQuery)
@@ -507,23 +1072,11 @@ func (pr *PrometheusRepo) GetIOUtil() ([]healthcheck.PrometheusData, error) {
 
 // GetDiskCapacityUsage gets the disk capacity usage
 func (pr *PrometheusRepo) GetDiskCapacityUsage(mountPoints []string) ([]healthcheck.PrometheusData, error) {
-	var prometheusQuery string
-
-	mps := common.ConvertStringSliceToString(mountPoints, constant.VerticalBarString)
-	nodeName := pr.getNodeName()
-
 	// prepare query
-	switch pr.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = fmt.Sprintf(PrometheusDiskCapacityV1, nodeName, mps, nodeName, mps)
-	case 2:
-		// pmm 2.x
-		prometheusQuery = fmt.Sprintf(PrometheusDiskCapacityV2, nodeName, mps, nodeName, mps, nodeName, mps, nodeName, mps)
-	default:
-		return nil, message.NewMessage(msghc.ErrPmmVersionInvalid)
+	prometheusQuery, err := pr.render(PrometheusCatalogueDiskCapacityUsage, mountPoints)
+	if err != nil {
+		return nil, err
 	}
-
 	log.Debugf("healthcheck PrometheusRepo.GetDiskCapacityUsage() query: \n%s\n", prometheusQuery)
 	// get data
 	return pr.execute(prometheusQuery)
@@ -531,45 +1084,23 @@ func (pr *PrometheusRepo) GetDiskCapacityUsage(mountPoints []string) ([]healthch
 
 // GetConnectionUsage gets the connection usage
 func (pr *PrometheusRepo) GetConnectionUsage() ([]healthcheck.PrometheusData, error) {
-	var prometheusQuery string
-
 	// prepare query
-	switch pr.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = PrometheusConnectionUsageV1
-	case 2:
-		// pmm 2.x
-		prometheusQuery = PrometheusConnectionUsageV2
-	default:
-		return nil, message.NewMessage(msghc.ErrPmmVersionInvalid)
+	prometheusQuery, err := pr.render(PrometheusCatalogueConnectionUsage, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	serviceName := pr.getServiceName()
-	prometheusQuery = fmt.Sprintf(prometheusQuery, serviceName, serviceName, serviceName, serviceName)
 	log.Debugf("healthcheck PrometheusRepo.GetConnectionUsage() query: \n%s\n", prometheusQuery)
 	// get data
 	return pr.execute(prometheusQuery)
 }
 
-// GetActiveSessionPercents gets the active session number
+// GetAverageActiveSessionPercents gets the average active session percents
 func (pr *PrometheusRepo) GetAverageActiveSessionPercents() ([]healthcheck.PrometheusData, error) {
-	var prometheusQuery string
-
 	// prepare query
-	switch pr.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = PrometheusAverageActiveSessionPercentsV1
-	case 2:
-		// pmm 2.x
-		prometheusQuery = PrometheusAverageActiveSessionPercentsV2
-	default:
-		return nil, message.NewMessage(msghc.ErrPmmVersionInvalid)
+	prometheusQuery, err := pr.render(PrometheusCatalogueAverageActiveSessionPercents, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	serviceName := pr.getServiceName()
-	prometheusQuery = fmt.Sprintf(prometheusQuery, serviceName, serviceName, serviceName, serviceName)
 	log.Debugf("healthcheck PrometheusRepo.GetAverageActiveSessionPercents() query: \n%s\n", prometheusQuery)
 	// get data
 	return pr.execute(prometheusQuery)
@@ -577,23 +1108,48 @@ func (pr *PrometheusRepo) GetAverageActiveSessionPercents() ([]healthcheck.Prome
 
 // GetCacheMissRatio gets the cache miss ratio
 func (pr *PrometheusRepo) GetCacheMissRatio() ([]healthcheck.PrometheusData, error) {
-	var prometheusQuery string
+	// prepare query
+	prometheusQuery, err := pr.render(PrometheusCatalogueCacheMissRatio, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("healthcheck PrometheusRepo.GetCacheMissRatio() query: \n%s\n", prometheusQuery)
+	// get data
+	return pr.execute(prometheusQuery)
+}
 
+// GetReplicationLag gets the replication lag
+func (pr *PrometheusRepo) GetReplicationLag() ([]healthcheck.PrometheusData, error) {
 	// prepare query
-	switch pr.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = PrometheusCacheMissRatioV1
-	case 2:
-		// pmm 2.x
-		prometheusQuery = PrometheusCacheMissRatioV2
-	default:
-		return nil, message.NewMessage(msghc.ErrPmmVersionInvalid)
+	prometheusQuery, err := pr.render(PrometheusCatalogueReplicationLag, nil)
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("healthcheck PrometheusRepo.GetReplicationLag() query: \n%s\n", prometheusQuery)
+	// get data
+	return pr.execute(prometheusQuery)
+}
 
-	serviceName := pr.getServiceName()
-	prometheusQuery = fmt.Sprintf(prometheusQuery, serviceName, serviceName, serviceName, serviceName)
-	log.Debugf("healthcheck PrometheusRepo.getCacheMissRatio() query: \n%s\n", prometheusQuery)
+// GetInnoDBRowLockWaits gets the innodb row lock waits per second
+func (pr *PrometheusRepo) GetInnoDBRowLockWaits() ([]healthcheck.PrometheusData, error) {
+	// prepare query
+	prometheusQuery, err := pr.render(PrometheusCatalogueInnoDBRowLockWaits, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("healthcheck PrometheusRepo.GetInnoDBRowLockWaits() query: \n%s\n", prometheusQuery)
+	// get data
+	return pr.execute(prometheusQuery)
+}
+
+// GetInnoDBRowLockTime gets the innodb row lock time per second in milliseconds
+func (pr *PrometheusRepo) GetInnoDBRowLockTime() ([]healthcheck.PrometheusData, error) {
+	// prepare query
+	prometheusQuery, err := pr.render(PrometheusCatalogueInnoDBRowLockTime, nil)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("healthcheck PrometheusRepo.GetInnoDBRowLockTime() query: \n%s\n", prometheusQuery)
 	// get data
 	return pr.execute(prometheusQuery)
 }
@@ -605,13 +1161,7 @@ func (pr *PrometheusRepo) getServiceName() string {
 
 // getNodeName returns the node name
 func (pr *PrometheusRepo) getNodeName() string {
-	serviceName := pr.getServiceName()
-	strList := strings.Split(serviceName, constant.ColonString)
-	if len(strList) > constant.ZeroInt {
-		return strList[constant.ZeroInt]
-	}
-
-	return serviceName[:strings.LastIndex(serviceName, constant.DashString)]
+	return getPrometheusNodeName(pr.getServiceName())
 }
 
 // getPMMVersion returns the pmm version
@@ -619,11 +1169,34 @@ func (pr *PrometheusRepo) getPMMVersion() int {
 	return pr.GetOperationInfo().GetMonitorSystem().GetSystemType()
 }
 
+// getStep returns the step of the range query, it may be larger than the step of the operation if the time window is too long
+func (pr *PrometheusRepo) getStep() time.Duration {
+	return getPrometheusStep(pr.GetOperationInfo().GetStartTime(), pr.GetOperationInfo().GetEndTime(), pr.GetOperationInfo().GetStep())
+}
+
+// getCatalogue returns the catalogue, it returns the built-in catalogue of the monitor system type if the catalogue is not specified
+func (pr *PrometheusRepo) getCatalogue() (*PrometheusCatalogue, error) {
+	if pr.catalogue != nil {
+		return pr.catalogue, nil
+	}
+
+	return NewPrometheusCatalogueWithDefault(pr.getPMMVersion())
+}
+
+// render renders the query of given name in the catalogue
+func (pr *PrometheusRepo) render(name string, mountPoints []string) (string, error) {
+	catalogue, err := pr.getCatalogue()
+	if err != nil {
+		return constant.EmptyString, err
+	}
+
+	return catalogue.Render(name, pr.GetOperationInfo().GetMySQLServer(), pr.getStep(), mountPoints)
+}
+
 // execute executes the given query
 func (pr *PrometheusRepo) execute(query string) ([]healthcheck.PrometheusData, error) {
-	// execute query
-	result, err := pr.getConnection().Execute(query, pr.GetOperationInfo().GetStartTime(),
-		pr.GetOperationInfo().GetEndTime(), pr.GetOperationInfo().GetStep())
+	// execute range query, all the series are sampled with the same step over the whole time window
+	result, err := pr.getConnection().Execute(query, pr.GetOperationInfo().GetStartTime(), pr.GetOperationInfo().GetEndTime(), pr.getStep())
 	if err != nil {
 		return nil, err
 	}
@@ -635,8 +1208,9 @@ func (pr *PrometheusRepo) execute(query string) ([]healthcheck.PrometheusData, e
 		return nil, err
 	}
 	for _, sampleStream := range matrix {
+		series := sampleStream.Metric.String()
 		for _, samplePair := range sampleStream.Values {
-			datas = append(datas, NewPrometheusData(samplePair.Timestamp.String(), float64(samplePair.Value)))
+			datas = append(datas, NewPrometheusDataWithSeries(series, samplePair.Timestamp.String(), float64(samplePair.Value)))
 		}
 	}
 
@@ -761,3 +1335,22 @@ func (cqr *ClickhouseQueryRepo) getServiceName() string {
 func (cqr *ClickhouseQueryRepo) getPMMVersion() int {
 	return cqr.GetOperationInfo().GetMonitorSystem().GetSystemType()
 }
+
+// EmptyQueryRepo is the query repository of the monitor system which has no query analytics, such as the plain prometheus,
+// it always returns no slow query
+type EmptyQueryRepo struct{}
+
+// NewEmptyQueryRepo returns a new *EmptyQueryRepo
+func NewEmptyQueryRepo() *EmptyQueryRepo {
+	return &EmptyQueryRepo{}
+}
+
+// Close does nothing, there is no connection to close
+func (eqr *EmptyQueryRepo) Close() error {
+	return nil
+}
+
+// GetSlowQuery returns no slow query
+func (eqr *EmptyQueryRepo) GetSlowQuery() ([]depquery.Query, error) {
+	return nil, nil
+}
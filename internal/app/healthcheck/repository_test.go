@@ -1,8 +1,11 @@
 package healthcheck
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +13,7 @@ import (
 	"github.com/romberli/das/global"
 	"github.com/romberli/das/internal/app/metadata"
 	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/util/replay"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/linux"
@@ -34,6 +38,7 @@ const (
 
 	defaultOperationID      = 1
 	defaultMysqlServerID    = 1
+	defaultMySQLClusterID   = 1
 	newResultStatus         = 1
 	accuracyReviewStruct    = "AccuracyReview"
 	newResultAccuracyReview = 1
@@ -42,19 +47,143 @@ const (
 	defaultVariableValue = "/mysqldata/mysql3306/data"
 
 	defaultFileSystemNum     = 2
-	defaultPrometheusDataNum = 10081
+	defaultPrometheusDataNum = 61
+
+	// the golden files of the das database and the repositories
+	testDASCassetteDir = "das"
+	testMainCassette   = "TestMain"
+	testRepositoryFile = "repository.json"
+	// testOperationRange is the check range of the test operation, the recorded series have 61 samples with the default step
+	testOperationRange = time.Hour
 )
 
 var (
-	testOperationInfo        = initOperationInfo()
-	testDASRepo              = initDASRepo()
-	testApplicationMySQLRepo = initApplicationMySQLRepo()
-	testPrometheusRepo       = initPrometheusRepo()
-	testQueryRepo            = initQueryRepo()
-	testMountPoints, _       = initFileSystems()
+	// record runs the tests against the live databases and records the golden files,
+	// otherwise the tests replay the golden files, so that they could run offline,
+	// e.g.: go test -v ./internal/app/healthcheck/ -record
+	record = flag.Bool("record", false, "run the tests against the live databases and record the golden files")
+
+	testDASPool              replay.CassettePool
+	testRecorder             *Recorder
+	testOperationInfo        *OperationInfo
+	testDASRepo              *DASRepo
+	testApplicationMySQLRepo healthcheck.ApplicationMySQLRepo
+	testPrometheusRepo       healthcheck.PrometheusRepo
+	testQueryRepo            healthcheck.QueryRepo
+	testScheduleRepo         *ScheduleRepo
+	testMountPoints          []string
 )
 
-func initGlobalMySQLPool() error {
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	err := initTestRepos()
+	if err != nil {
+		log.Error(common.CombineMessageWithError("TestMain() failed", err))
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	if *record {
+		err = saveGoldenFiles()
+		if err != nil {
+			log.Error(common.CombineMessageWithError("TestMain() failed", err))
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
+// initTestRepos initializes the repositories which replay the golden files, or record them in record mode
+func initTestRepos() error {
+	err := initDASPool()
+	if err != nil {
+		return err
+	}
+	// the das repositories and the operation information are initialized with the cassette of TestMain
+	err = testDASPool.Insert(testMainCassette)
+	if err != nil {
+		return err
+	}
+	testDASRepo = initDASRepo()
+	testScheduleRepo = initScheduleRepo()
+	testOperationInfo, err = initOperationInfo()
+	if err != nil {
+		return err
+	}
+	err = testDASPool.Eject()
+	if err != nil {
+		return err
+	}
+
+	if *record {
+		err = initRecordingRepos()
+	} else {
+		err = initReplayRepos()
+	}
+	if err != nil {
+		return err
+	}
+	testMountPoints, _, err = initFileSystems()
+
+	return err
+}
+
+// initDASPool initializes the das pool which replays the cassettes, or records them in record mode
+func initDASPool() error {
+	dir := filepath.Join(testDataDir, testDASCassetteDir)
+	if !*record {
+		testDASPool = replay.NewPool(dir)
+		global.DASMySQLPool = testDASPool
+
+		return nil
+	}
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	pool, err := initDASMySQLPool()
+	if err != nil {
+		return err
+	}
+	testDASPool = replay.NewRecordingPool(pool, dir)
+	global.DASMySQLPool = testDASPool
+
+	return nil
+}
+
+// insertDASCassette inserts the cassette of the calling test into the das pool and ejects it when the test finishes,
+// the tests are also called by the TestXxxAll tests with the same *testing.T, so the cassette is named after the calling function
+func insertDASCassette(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(1)
+	name := runtime.FuncForPC(pc).Name()
+	name = name[strings.LastIndex(name, constant.DotString)+1:]
+
+	err := testDASPool.Insert(name)
+	if err != nil {
+		t.Fatal(common.CombineMessageWithError("insert das cassette failed", err))
+	}
+	t.Cleanup(func() {
+		err := testDASPool.Eject()
+		if err != nil {
+			t.Error(common.CombineMessageWithError("eject das cassette failed", err))
+		}
+	})
+}
+
+// saveGoldenFiles saves the cassette which is still inserted and the recorded responses of the repositories
+func saveGoldenFiles() error {
+	err := testDASPool.Eject()
+	if err != nil {
+		return err
+	}
+
+	return testRecorder.Save(filepath.Join(testDataDir, testRepositoryFile))
+}
+
+func initDASMySQLPool() (*mysql.Pool, error) {
 	dbAddr := defaultDASAddr
 	dbName := defaultDASDBName
 	dbUser := defaultDBUser
@@ -68,57 +197,85 @@ func initGlobalMySQLPool() error {
 	config := mysql.NewConfig(dbAddr, dbName, dbUser, dbPass)
 	poolConfig := mysql.NewPoolConfigWithConfig(config, maxConnections, initConnections, maxIdleConnections, maxIdleTime, keepAliveInterval)
 	log.Debugf("pool config: %v", poolConfig)
-	var err error
-	global.DASMySQLPool, err = mysql.NewPoolWithPoolConfig(poolConfig)
 
-	return err
+	return mysql.NewPoolWithPoolConfig(poolConfig)
 }
 
 func initDASRepo() *DASRepo {
 	return NewDASRepoWithGlobal()
 }
 
-func initOperationInfo() *OperationInfo {
-	err := initGlobalMySQLPool()
-	if err != nil {
-		log.Error(common.CombineMessageWithError("initOperationInfo() failed", err))
-		os.Exit(1)
-	}
-
+func initOperationInfo() (*OperationInfo, error) {
 	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
-	err = mysqlServerService.GetByID(defaultMysqlServerID)
+	err := mysqlServerService.GetByID(defaultMysqlServerID)
 	if err != nil {
-		log.Error(common.CombineMessageWithError("initOperationInfo() failed", err))
-		os.Exit(1)
+		return nil, err
 	}
 	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
 	monitorSystem, err := mysqlServer.GetMonitorSystem()
 	if err != nil {
-		log.Error(common.CombineMessageWithError("initOperationInfo() failed", err))
-		os.Exit(1)
+		return nil, err
 	}
 
 	return &OperationInfo{
 		operationID:   defaultOperationID,
 		mysqlServer:   mysqlServer,
 		monitorSystem: monitorSystem,
-		startTime:     time.Now().Add(-constant.Week),
+		startTime:     time.Now().Add(-testOperationRange),
 		endTime:       time.Now(),
 		step:          defaultStep,
+	}, nil
+}
+
+// initReplayRepos initializes the application mysql, prometheus and query repositories which replay the golden file
+func initReplayRepos() error {
+	snapshot, err := NewSnapshotWithFile(filepath.Join(testDataDir, testRepositoryFile))
+	if err != nil {
+		return err
 	}
+
+	testApplicationMySQLRepo = NewSnapshotApplicationMySQLRepo(snapshot.MySQL)
+	testPrometheusRepo = NewSnapshotPrometheusRepo(snapshot.Prometheus)
+	testQueryRepo = NewSnapshotQueryRepo(snapshot.SlowQueries)
+
+	return nil
 }
 
-func initApplicationMySQLRepo() *ApplicationMySQLRepo {
+// initRecordingRepos initializes the live application mysql, prometheus and query repositories,
+// their responses are recorded and saved as the golden file when the tests finish
+func initRecordingRepos() error {
+	applicationMySQLRepo, err := initApplicationMySQLRepo()
+	if err != nil {
+		return err
+	}
+	prometheusRepo, err := initPrometheusRepo()
+	if err != nil {
+		return err
+	}
+	queryRepo, err := initQueryRepo()
+	if err != nil {
+		return err
+	}
+
+	testRecorder = NewRecorder(testOperationInfo.GetStartTime(), testOperationInfo.GetEndTime(), testOperationInfo.GetStep(),
+		applicationMySQLRepo, prometheusRepo, queryRepo)
+	testApplicationMySQLRepo = testRecorder.GetApplicationMySQLRepo()
+	testPrometheusRepo = testRecorder.GetPrometheusRepo()
+	testQueryRepo = testRecorder.GetQueryRepo()
+
+	return nil
+}
+
+func initApplicationMySQLRepo() (*ApplicationMySQLRepo, error) {
 	conn, err := mysql.NewConn(defaultDASAddr, constant.EmptyString, defaultDBUser, defaultDBPass)
 	if err != nil {
-		log.Error(common.CombineMessageWithError("initApplicationMySQLRepo() failed", err))
-		os.Exit(1)
+		return nil, err
 	}
 
-	return NewApplicationMySQLRepo(testOperationInfo, conn)
+	return NewApplicationMySQLRepo(testOperationInfo, conn), nil
 }
 
-func initPrometheusRepo() *PrometheusRepo {
+func initPrometheusRepo() (*PrometheusRepo, error) {
 	var config prometheus.Config
 
 	addr := fmt.Sprintf("%s:%d/%s", testOperationInfo.GetMonitorSystem().GetHostIP(),
@@ -132,14 +289,13 @@ func initPrometheusRepo() *PrometheusRepo {
 
 	conn, err := prometheus.NewConnWithConfig(config)
 	if err != nil {
-		log.Error(common.CombineMessageWithError("initPrometheusRepo() failed", err))
-		os.Exit(1)
+		return nil, err
 	}
 
-	return NewPrometheusRepo(testOperationInfo, conn)
+	return NewPrometheusRepo(testOperationInfo, conn), nil
 }
 
-func initQueryRepo() healthcheck.QueryRepo {
+func initQueryRepo() (healthcheck.QueryRepo, error) {
 	var queryRepo healthcheck.QueryRepo
 
 	addr := fmt.Sprintf("%s:%d", testOperationInfo.GetMonitorSystem().GetHostIP(), testOperationInfo.GetMonitorSystem().GetPortNumSlow())
@@ -147,23 +303,21 @@ func initQueryRepo() healthcheck.QueryRepo {
 	case 1:
 		conn, err := mysql.NewConn(addr, defaultQueryDBName, defaultDBUser, defaultDBPass)
 		if err != nil {
-			log.Error(common.CombineMessageWithError("initQueryRepo() failed", err))
-			os.Exit(1)
+			return nil, err
 		}
 		queryRepo = NewMySQLQueryRepo(testOperationInfo, conn)
 	case 2:
 		conn, err := clickhouse.NewConnWithDefault(addr, defaultQueryDBName, constant.EmptyString, constant.EmptyString)
 		if err != nil {
-			log.Error(common.CombineMessageWithError("initQueryRepo() failed", err))
-			os.Exit(1)
+			return nil, err
 		}
 		queryRepo = NewClickhouseQueryRepo(testOperationInfo, conn)
 	}
 
-	return queryRepo
+	return queryRepo, nil
 }
 
-func initFileSystems() ([]string, []string) {
+func initFileSystems() ([]string, []string, error) {
 	var (
 		systemMountPoints []string
 		mysqlMountPoints  []string
@@ -173,8 +327,7 @@ func initFileSystems() ([]string, []string) {
 	// get file systems
 	fileSystems, err := testPrometheusRepo.GetFileSystems()
 	if err != nil {
-		log.Error(common.CombineMessageWithError("initFileSystems() failed", err))
-		os.Exit(1)
+		return nil, nil, err
 	}
 	// get mount points
 	for _, fileSystem := range fileSystems {
@@ -183,8 +336,7 @@ func initFileSystems() ([]string, []string) {
 	// get mysql directories
 	dirs, err := testApplicationMySQLRepo.GetMySQLDirs()
 	if err != nil {
-		log.Error(common.CombineMessageWithError("initFileSystems() failed", err))
-		os.Exit(1)
+		return nil, nil, err
 	}
 
 	dirs = append(dirs, constant.RootDir)
@@ -192,8 +344,7 @@ func initFileSystems() ([]string, []string) {
 	for _, dir := range dirs {
 		mountPoint, err := linux.MatchMountPoint(dir, systemMountPoints)
 		if err != nil {
-			log.Error(common.CombineMessageWithError("initFileSystems() failed", err))
-			os.Exit(1)
+			return nil, nil, err
 		}
 
 		mysqlMountPoints = append(mysqlMountPoints, mountPoint)
@@ -205,7 +356,7 @@ func initFileSystems() ([]string, []string) {
 		}
 	}
 
-	return mysqlMountPoints, devices
+	return mysqlMountPoints, devices, nil
 }
 
 func createResult() error {
@@ -220,8 +371,13 @@ func createResult() error {
 }
 
 func deleteResultByID(id int) error {
-	sql := `delete from t_hc_result where id = ?`
+	sql := `delete from t_hc_result_item where operation_id in (select operation_id from t_hc_result where id = ?)`
 	_, err := testDASRepo.Execute(sql, id)
+	if err != nil {
+		return err
+	}
+	sql = `delete from t_hc_result where id = ?`
+	_, err = testDASRepo.Execute(sql, id)
 	return err
 }
 
@@ -231,18 +387,47 @@ func deleteOperationInfoByID(id int) error {
 	return err
 }
 
+func deleteClusterOperationInfoByID(id int) error {
+	sql := `delete from t_hc_cluster_operation_info where id = ?`
+	_, err := testDASRepo.Execute(sql, id)
+	return err
+}
+
+func deleteClusterResultByOperationID(clusterOperationID int) error {
+	sql := `delete from t_hc_cluster_result where cluster_operation_id = ?`
+	_, err := testDASRepo.Execute(sql, clusterOperationID)
+	return err
+}
+
 func TestRepositoryAll(t *testing.T) {
 	// das repository
 	TestDASRepo_Execute(t)
+	TestDASRepo_GetDBConfigRules(t)
 	TestDASRepo_GetResultByOperationID(t)
+	TestDASRepo_GetResultsByMySQLServerID(t)
+	TestDASRepo_GetResultItemsByOperationIDs(t)
 	TestDASRepo_IsRunning(t)
 	TestDASRepo_InitOperation(t)
 	TestDASRepo_UpdateOperationStatus(t)
+	TestDASRepo_GetOperationByID(t)
+	TestDASRepo_GetOperations(t)
 	TestDASRepo_SaveResult(t)
 	TestDASRepo_UpdateAccuracyReviewByOperationID(t)
+	TestDASRepo_InitClusterOperation(t)
+	TestDASRepo_InitMemberOperation(t)
+	TestDASRepo_SaveClusterResult(t)
 	// application mysql repository
 	TestApplicationMySQLRepo_GetVariables(t)
 	TestApplicationMySQLRepo_GetMySQLDirs(t)
+	TestApplicationMySQLRepo_IsReplica(t)
+	TestApplicationMySQLRepo_GetReplicationStatus(t)
+	TestApplicationMySQLRepo_GetGTIDExecuted(t)
+	TestApplicationMySQLRepo_GetLockWaits(t)
+	TestApplicationMySQLRepo_GetInnoDBStatus(t)
+	TestApplicationMySQLRepo_GetUnusedIndexes(t)
+	TestApplicationMySQLRepo_GetRedundantIndexes(t)
+	TestApplicationMySQLRepo_GetTablesWithoutPrimaryKey(t)
+	TestApplicationMySQLRepo_GetNonInnoDBTables(t)
 	TestApplicationMySQLRepo_GetLargeTables(t)
 	// prometheus repository
 	TestPrometheusRepo_GetFileSystems(t)
@@ -252,6 +437,9 @@ func TestRepositoryAll(t *testing.T) {
 	TestPrometheusRepo_GetConnectionUsage(t)
 	TestPrometheusRepo_GetAverageActiveSessionPercents(t)
 	TestPrometheusRepo_GetCacheMissRatio(t)
+	TestPrometheusRepo_GetReplicationLag(t)
+	TestPrometheusRepo_GetInnoDBRowLockWaits(t)
+	TestPrometheusRepo_GetInnoDBRowLockTime(t)
 	TestPrometheusRepo_getServiceName(t)
 	TestPrometheusRepo_getPMMVersion(t)
 	TestPrometheusRepo_execute(t)
@@ -266,6 +454,7 @@ func TestRepositoryAll(t *testing.T) {
 }
 
 func TestDASRepo_Execute(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	sql := "select 1;"
@@ -276,32 +465,30 @@ func TestDASRepo_Execute(t *testing.T) {
 	asst.Equal(1, r, "test Execute() failed")
 }
 
+func TestDASRepo_GetDBConfigRules(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	rules, err := testDASRepo.GetDBConfigRules()
+	asst.Nil(err, common.CombineMessageWithError("test GetDBConfigRules() failed", err))
+	asst.NotZero(len(rules), "test GetDBConfigRules() failed")
+	for _, rule := range rules {
+		asst.Equal(rules[constant.ZeroInt].GetRuleSetVersion(), rule.GetRuleSetVersion(), "test GetDBConfigRules() failed")
+		err = rule.Validate()
+		asst.Nil(err, common.CombineMessageWithError("test GetDBConfigRules() failed", err))
+	}
+}
+
 func TestDASRepo_Transaction(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
-	sql := `insert into t_hc_result(operation_id, weighted_average_score, db_config_score, db_config_data,
-		db_config_advice, cpu_usage_score, cpu_usage_data, cpu_usage_high, io_util_score,
-		io_util_data, io_util_high, disk_capacity_usage_score, disk_capacity_usage_data,
-		disk_capacity_usage_high, connection_usage_score, connection_usage_data,
-		connection_usage_high, average_active_session_percents_score, average_active_session_percents_data,
-		average_active_session_percents_high, cache_miss_ratio_score, cache_miss_ratio_data,
-		cache_miss_ratio_high, table_size_score, table_size_data, table_size_high, slow_query_score,
-		slow_query_data, slow_query_advice, accuracy_review) values(?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
-	?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
-	`
+	sql := `insert into t_hc_result(operation_id, weighted_average_score, accuracy_review) values(?, ?, ?);`
 	tx, err := testDASRepo.Transaction()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	err = tx.Begin()
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
-	_, err = tx.Execute(sql, defaultResultOperationID, defaultResultWeightedAverageScore, defaultResultDBConfigScore,
-		defaultResultDBConfigData, defaultResultDBConfigAdvice, defaultResultCPUUsageScore, defaultResultCPUUsageData,
-		defaultResultCPUUsageHigh, defaultResultIOUtilScore, defaultResultIOUtilData, defaultResultIOUtilHigh,
-		defaultResultDiskCapacityUsageScore, defaultResultDiskCapacityUsageData, defaultResultDiskCapacityUsageHigh,
-		defaultResultConnectionUsageScore, defaultResultConnectionUsageData, defaultResultConnectionUsageHigh,
-		defaultResultAverageActiveSessionPercentsScore, defaultResultAverageActiveSessionPercentsData, defaultResultAverageActiveSessionPercentsHigh,
-		defaultResultCacheMissRatioScore, defaultResultCacheMissRatioData, defaultResultCacheMissRatioHigh,
-		defaultResultTableSizeScore, defaultResultTableSizeData, defaultResultTableSizeHigh, defaultResultSlowQueryScore,
-		defaultResultSlowQueryData, defaultResultSlowQueryAdvice, defaultResultAccuracyReview)
+	_, err = tx.Execute(sql, defaultResultOperationID, defaultResultWeightedAverageScore, defaultResultAccuracyReview)
 	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
 	// check if inserted
 	sql = `select operation_id from t_hc_result where operation_id = ?`
@@ -322,6 +509,7 @@ func TestDASRepo_Transaction(t *testing.T) {
 }
 
 func TestDASRepo_GetResultByOperationID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	err := createResult()
@@ -336,7 +524,45 @@ func TestDASRepo_GetResultByOperationID(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
 }
 
+func TestDASRepo_GetResultsByMySQLServerID(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	err := createResult()
+	asst.Nil(err, common.CombineMessageWithError("test GetResultsByMySQLServerID() failed", err))
+	results, err := testDASRepo.GetResultsByMySQLServerID(defaultMysqlServerID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	asst.Nil(err, common.CombineMessageWithError("test GetResultsByMySQLServerID() failed", err))
+	var id int
+	for _, result := range results {
+		if result.GetOperationID() == defaultResultOperationID {
+			id = result.Identity()
+		}
+	}
+	asst.NotZero(id, "test GetResultsByMySQLServerID() failed")
+	// delete
+	err = deleteResultByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test GetResultsByMySQLServerID() failed", err))
+}
+
+func TestDASRepo_GetResultItemsByOperationIDs(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	err := createResult()
+	asst.Nil(err, common.CombineMessageWithError("test GetResultItemsByOperationIDs() failed", err))
+	items, err := testDASRepo.GetResultItemsByOperationIDs(defaultResultOperationID)
+	asst.Nil(err, common.CombineMessageWithError("test GetResultItemsByOperationIDs() failed", err))
+	asst.Equal(10, len(items), "test GetResultItemsByOperationIDs() failed")
+	result, err := testDASRepo.GetResultByOperationID(defaultResultOperationID)
+	asst.Nil(err, common.CombineMessageWithError("test GetResultItemsByOperationIDs() failed", err))
+	asst.Equal(defaultResultCPUUsageScore, result.GetCPUUsageScore(), "test GetResultItemsByOperationIDs() failed")
+	// delete
+	err = deleteResultByID(result.Identity())
+	asst.Nil(err, common.CombineMessageWithError("test GetResultItemsByOperationIDs() failed", err))
+}
+
 func TestDASRepo_IsRunning(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	sql := `insert into t_hc_operation_info(mysql_server_id, start_time, end_time, step) values(?, ?, ?, ?);`
@@ -357,6 +583,7 @@ func TestDASRepo_IsRunning(t *testing.T) {
 }
 
 func TestDASRepo_InitOperation(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	id, err := testDASRepo.InitOperation(defaultMysqlServerID, time.Now().Add(-constant.Week), time.Now(), defaultStep)
@@ -373,6 +600,7 @@ func TestDASRepo_InitOperation(t *testing.T) {
 }
 
 func TestDASRepo_UpdateOperationStatus(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	id, err := testDASRepo.InitOperation(defaultMysqlServerID, time.Now().Add(-constant.Week), time.Now(), defaultStep)
@@ -390,7 +618,46 @@ func TestDASRepo_UpdateOperationStatus(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test UpdateOperationStatus() failed", err))
 }
 
+func TestDASRepo_GetOperationByID(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := testDASRepo.InitOperation(defaultMysqlServerID, time.Now().Add(-constant.Week), time.Now(), defaultStep)
+	asst.Nil(err, common.CombineMessageWithError("test GetOperationByID() failed", err))
+	operation, err := testDASRepo.GetOperationByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test GetOperationByID() failed", err))
+	asst.Equal(defaultMysqlServerID, operation.GetMySQLServerID(), "test GetOperationByID() failed")
+	asst.Equal(defaultRunningStatus, operation.GetStatus(), "test GetOperationByID() failed")
+	asst.Equal(defaultStep, operation.GetStep(), "test GetOperationByID() failed")
+	// delete
+	err = deleteOperationInfoByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test GetOperationByID() failed", err))
+}
+
+func TestDASRepo_GetOperations(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := testDASRepo.InitOperation(defaultMysqlServerID, time.Now().Add(-constant.Week), time.Now(), defaultStep)
+	asst.Nil(err, common.CombineMessageWithError("test GetOperations() failed", err))
+	err = testDASRepo.UpdateOperationStatus(id, defaultFailedStatus, "test")
+	asst.Nil(err, common.CombineMessageWithError("test GetOperations() failed", err))
+	operations, err := testDASRepo.GetOperations(defaultMysqlServerID, defaultFailedStatus, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	asst.Nil(err, common.CombineMessageWithError("test GetOperations() failed", err))
+	asst.NotZero(len(operations), "test GetOperations() failed")
+	asst.Equal(id, operations[constant.ZeroInt].Identity(), "test GetOperations() failed")
+	operations, err = testDASRepo.GetOperations(defaultMysqlServerID, defaultCanceledStatus, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	asst.Nil(err, common.CombineMessageWithError("test GetOperations() failed", err))
+	for _, operation := range operations {
+		asst.NotEqual(id, operation.Identity(), "test GetOperations() failed")
+	}
+	// delete
+	err = deleteOperationInfoByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test GetOperations() failed", err))
+}
+
 func TestDASRepo_SaveResult(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	err := createResult()
@@ -404,6 +671,7 @@ func TestDASRepo_SaveResult(t *testing.T) {
 }
 
 func TestDASRepo_UpdateAccuracyReviewByOperationID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	err := createResult()
@@ -420,6 +688,60 @@ func TestDASRepo_UpdateAccuracyReviewByOperationID(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test UpdateAccuracyReviewByOperationID() failed", err))
 }
 
+func TestDASRepo_InitClusterOperation(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := testDASRepo.InitClusterOperation(defaultMySQLClusterID, time.Now().Add(-constant.Week), time.Now(), defaultStep)
+	asst.Nil(err, common.CombineMessageWithError("test InitClusterOperation() failed", err))
+	isRunning, err := testDASRepo.IsClusterRunning(defaultMySQLClusterID)
+	asst.Nil(err, common.CombineMessageWithError("test InitClusterOperation() failed", err))
+	asst.True(isRunning, "test InitClusterOperation() failed")
+	err = testDASRepo.UpdateClusterOperationStatus(id, defaultSuccessStatus, "test")
+	asst.Nil(err, common.CombineMessageWithError("test InitClusterOperation() failed", err))
+	isRunning, err = testDASRepo.IsClusterRunning(defaultMySQLClusterID)
+	asst.Nil(err, common.CombineMessageWithError("test InitClusterOperation() failed", err))
+	asst.False(isRunning, "test InitClusterOperation() failed")
+	// delete
+	err = deleteClusterOperationInfoByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test InitClusterOperation() failed", err))
+}
+
+func TestDASRepo_InitMemberOperation(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := testDASRepo.InitMemberOperation(defaultOperationID, defaultMysqlServerID, time.Now().Add(-constant.Week), time.Now(), defaultStep)
+	asst.Nil(err, common.CombineMessageWithError("test InitMemberOperation() failed", err))
+	sql := `select cluster_operation_id from t_hc_operation_info where id = ?;`
+	result, err := testDASRepo.Execute(sql, id)
+	asst.Nil(err, common.CombineMessageWithError("test InitMemberOperation() failed", err))
+	clusterOperationID, err := result.GetInt(0, 0)
+	asst.Nil(err, common.CombineMessageWithError("test InitMemberOperation() failed", err))
+	asst.Equal(defaultOperationID, clusterOperationID, "test InitMemberOperation() failed")
+	// delete
+	err = deleteOperationInfoByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test InitMemberOperation() failed", err))
+}
+
+func TestDASRepo_SaveClusterResult(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	clusterResult, err := NewClusterResultWithServerResults(defaultOperationID, []*ServerResult{
+		{MySQLServerID: defaultMysqlServerID, Status: defaultSuccessStatus, WeightedAverageScore: 80},
+	})
+	asst.Nil(err, common.CombineMessageWithError("test SaveClusterResult() failed", err))
+	err = testDASRepo.SaveClusterResult(clusterResult)
+	asst.Nil(err, common.CombineMessageWithError("test SaveClusterResult() failed", err))
+	result, err := testDASRepo.GetClusterResultByOperationID(defaultOperationID)
+	asst.Nil(err, common.CombineMessageWithError("test SaveClusterResult() failed", err))
+	asst.Equal(80, result.GetWorstScore(), "test SaveClusterResult() failed")
+	// delete
+	err = deleteClusterResultByOperationID(defaultOperationID)
+	asst.Nil(err, common.CombineMessageWithError("test SaveClusterResult() failed", err))
+}
+
 func TestApplicationMySQLRepo_GetVariables(t *testing.T) {
 	asst := assert.New(t)
 
@@ -440,6 +762,86 @@ func TestApplicationMySQLRepo_GetMySQLDirs(t *testing.T) {
 	asst.Equal(strings.TrimRight(defaultVariableValue, constant.SlashString), strings.TrimRight(value, constant.SlashString), "test TestApplicationMySQLRepo_GetMySQLDirs() failed")
 }
 
+func TestApplicationMySQLRepo_IsReplica(t *testing.T) {
+	asst := assert.New(t)
+
+	isReplica, err := testApplicationMySQLRepo.IsReplica()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_IsReplica() failed", err))
+	asst.False(isReplica, "test TestApplicationMySQLRepo_IsReplica() failed")
+}
+
+func TestApplicationMySQLRepo_GetReplicationStatus(t *testing.T) {
+	asst := assert.New(t)
+
+	status, err := testApplicationMySQLRepo.GetReplicationStatus()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetReplicationStatus() failed", err))
+	asst.Nil(status, "test TestApplicationMySQLRepo_GetReplicationStatus() failed")
+}
+
+func TestApplicationMySQLRepo_GetGTIDExecuted(t *testing.T) {
+	asst := assert.New(t)
+
+	gtidExecuted, err := testApplicationMySQLRepo.GetGTIDExecuted()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetGTIDExecuted() failed", err))
+	_, err = parseGTIDSet(gtidExecuted)
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetGTIDExecuted() failed", err))
+}
+
+func TestApplicationMySQLRepo_GetLockWaits(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := testApplicationMySQLRepo.GetLockWaits()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetLockWaits() failed", err))
+}
+
+func TestApplicationMySQLRepo_GetInnoDBStatus(t *testing.T) {
+	asst := assert.New(t)
+
+	innoDBStatus, err := testApplicationMySQLRepo.GetInnoDBStatus()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetInnoDBStatus() failed", err))
+	asst.Contains(innoDBStatus, "INNODB MONITOR OUTPUT", "test TestApplicationMySQLRepo_GetInnoDBStatus() failed")
+}
+
+func TestApplicationMySQLRepo_GetUnusedIndexes(t *testing.T) {
+	asst := assert.New(t)
+
+	findings, err := testApplicationMySQLRepo.GetUnusedIndexes()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetUnusedIndexes() failed", err))
+	for _, finding := range findings {
+		asst.Equal(IndexFindingTypeUnusedIndex, finding.GetFindingType(), "test TestApplicationMySQLRepo_GetUnusedIndexes() failed")
+	}
+}
+
+func TestApplicationMySQLRepo_GetRedundantIndexes(t *testing.T) {
+	asst := assert.New(t)
+
+	findings, err := testApplicationMySQLRepo.GetRedundantIndexes()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetRedundantIndexes() failed", err))
+	for _, finding := range findings {
+		asst.Equal(IndexFindingTypeRedundantIndex, finding.GetFindingType(), "test TestApplicationMySQLRepo_GetRedundantIndexes() failed")
+	}
+}
+
+func TestApplicationMySQLRepo_GetTablesWithoutPrimaryKey(t *testing.T) {
+	asst := assert.New(t)
+
+	findings, err := testApplicationMySQLRepo.GetTablesWithoutPrimaryKey()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetTablesWithoutPrimaryKey() failed", err))
+	for _, finding := range findings {
+		asst.Equal(IndexFindingTypeMissingPrimaryKey, finding.GetFindingType(), "test TestApplicationMySQLRepo_GetTablesWithoutPrimaryKey() failed")
+	}
+}
+
+func TestApplicationMySQLRepo_GetNonInnoDBTables(t *testing.T) {
+	asst := assert.New(t)
+
+	findings, err := testApplicationMySQLRepo.GetNonInnoDBTables()
+	asst.Nil(err, common.CombineMessageWithError("test TestApplicationMySQLRepo_GetNonInnoDBTables() failed", err))
+	for _, finding := range findings {
+		asst.Equal(IndexFindingTypeNonInnoDBTable, finding.GetFindingType(), "test TestApplicationMySQLRepo_GetNonInnoDBTables() failed")
+	}
+}
+
 func TestApplicationMySQLRepo_GetLargeTables(t *testing.T) {
 	asst := assert.New(t)
 
@@ -504,39 +906,56 @@ func TestPrometheusRepo_GetCacheMissRatio(t *testing.T) {
 	asst.Equal(defaultPrometheusDataNum, len(datas), "test TestPrometheusRepo_GetCacheMissRatio() failed")
 }
 
+func TestPrometheusRepo_GetReplicationLag(t *testing.T) {
+	asst := assert.New(t)
+
+	_, err := testPrometheusRepo.GetReplicationLag()
+	asst.Nil(err, common.CombineMessageWithError("test TestPrometheusRepo_GetReplicationLag() failed", err))
+}
+
+func TestPrometheusRepo_GetInnoDBRowLockWaits(t *testing.T) {
+	asst := assert.New(t)
+
+	datas, err := testPrometheusRepo.GetInnoDBRowLockWaits()
+	asst.Nil(err, common.CombineMessageWithError("test TestPrometheusRepo_GetInnoDBRowLockWaits() failed", err))
+	asst.Equal(defaultPrometheusDataNum, len(datas), "test TestPrometheusRepo_GetInnoDBRowLockWaits() failed")
+}
+
+func TestPrometheusRepo_GetInnoDBRowLockTime(t *testing.T) {
+	asst := assert.New(t)
+
+	datas, err := testPrometheusRepo.GetInnoDBRowLockTime()
+	asst.Nil(err, common.CombineMessageWithError("test TestPrometheusRepo_GetInnoDBRowLockTime() failed", err))
+	asst.Equal(defaultPrometheusDataNum, len(datas), "test TestPrometheusRepo_GetInnoDBRowLockTime() failed")
+}
+
 func TestPrometheusRepo_getServiceName(t *testing.T) {
 	asst := assert.New(t)
 
-	asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), testPrometheusRepo.getServiceName(),
+	// the service name comes from the operation information, so the repository does not need a connection
+	prometheusRepo := NewPrometheusRepo(testOperationInfo, nil)
+	asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), prometheusRepo.getServiceName(),
 		"test TestPrometheusRepo_getServiceName() failed")
 }
 
 func TestPrometheusRepo_getPMMVersion(t *testing.T) {
 	asst := assert.New(t)
 
-	asst.Equal(testOperationInfo.GetMonitorSystem().GetSystemType(), testPrometheusRepo.getPMMVersion(),
+	// the pmm version comes from the operation information, so the repository does not need a connection
+	prometheusRepo := NewPrometheusRepo(testOperationInfo, nil)
+	asst.Equal(testOperationInfo.GetMonitorSystem().GetSystemType(), prometheusRepo.getPMMVersion(),
 		"test TestPrometheusRepo_getPMMVersion() failed")
 }
 
 func TestPrometheusRepo_execute(t *testing.T) {
 	asst := assert.New(t)
 
-	var prometheusQuery string
-
-	// prepare query
-	switch testPrometheusRepo.getPMMVersion() {
-	case 1:
-		// pmm 1.x
-		prometheusQuery = PrometheusCPUUsageV1
-	case 2:
-		// pmm 2.x
-		prometheusQuery = PrometheusCPUUsageV2
-	}
-
-	nodeName := testPrometheusRepo.getNodeName()
-	prometheusQuery = fmt.Sprintf(prometheusQuery, nodeName, nodeName, nodeName, nodeName, nodeName, nodeName)
-
-	datas, err := testPrometheusRepo.execute(prometheusQuery)
+	prometheusRepo := NewPrometheusRepo(testOperationInfo, nil)
+	prometheusQuery, err := prometheusRepo.render(PrometheusCatalogueCPUUsage, nil)
+	asst.Nil(err, common.CombineMessageWithError("test TestPrometheusRepo_execute() failed", err))
+	asst.Contains(prometheusQuery, prometheusRepo.getNodeName(), "test TestPrometheusRepo_execute() failed")
+	// the rendered query is executed by GetCPUUsage(), it is recorded in record mode and replayed otherwise
+	datas, err := testPrometheusRepo.GetCPUUsage()
 	asst.Nil(err, common.CombineMessageWithError("test TestPrometheusRepo_execute() failed", err))
 	asst.Equal(defaultPrometheusDataNum, len(datas), "test TestPrometheusRepo_execute() failed")
 }
@@ -545,7 +964,7 @@ func TestMySQLQueryRepo_GetSlowQuery(t *testing.T) {
 	asst := assert.New(t)
 
 	if testOperationInfo.GetMonitorSystem().GetSystemType() == 1 {
-		queries, err := testQueryRepo.(*MySQLQueryRepo).GetSlowQuery()
+		queries, err := testQueryRepo.GetSlowQuery()
 		asst.Nil(err, common.CombineMessageWithError("test TestMySQLQueryRepo_GetSlowQuery() failed", err))
 		asst.LessOrEqual(constant.ZeroInt, len(queries), "test TestMySQLQueryRepo_GetSlowQuery() failed")
 	}
@@ -555,7 +974,7 @@ func TestMySQLQueryRepo_getServiceName(t *testing.T) {
 	asst := assert.New(t)
 
 	if testOperationInfo.GetMonitorSystem().GetSystemType() == 1 {
-		asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), testQueryRepo.(*MySQLQueryRepo).getServiceName(),
+		asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), NewMySQLQueryRepo(testOperationInfo, nil).getServiceName(),
 			"test TestMySQLQueryRepo_getServiceName() failed")
 	}
 }
@@ -564,7 +983,7 @@ func TestMySQLQueryRepo_getPMMVersion(t *testing.T) {
 	asst := assert.New(t)
 
 	if testOperationInfo.GetMonitorSystem().GetSystemType() == 1 {
-		asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), testQueryRepo.(*MySQLQueryRepo).getPMMVersion(),
+		asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), NewMySQLQueryRepo(testOperationInfo, nil).getPMMVersion(),
 			"test TestMySQLQueryRepo_getPMMVersion() failed")
 	}
 }
@@ -573,7 +992,7 @@ func TestClickhouseQueryRepo_GetSlowQuery(t *testing.T) {
 	asst := assert.New(t)
 
 	if testOperationInfo.GetMonitorSystem().GetSystemType() == 2 {
-		queries, err := testQueryRepo.(*ClickhouseQueryRepo).GetSlowQuery()
+		queries, err := testQueryRepo.GetSlowQuery()
 		asst.Nil(err, common.CombineMessageWithError("test TestClickhouseQueryRepo_GetSlowQuery() failed", err))
 		asst.LessOrEqual(constant.ZeroInt, len(queries), "test TestClickhouseQueryRepo_GetSlowQuery() failed")
 	}
@@ -583,7 +1002,7 @@ func TestClickhouseQueryRepo_getServiceName(t *testing.T) {
 	asst := assert.New(t)
 
 	if testOperationInfo.GetMonitorSystem().GetSystemType() == 2 {
-		asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), testQueryRepo.(*ClickhouseQueryRepo).getServiceName(),
+		asst.Equal(testOperationInfo.GetMySQLServer().GetServiceName(), NewClickhouseQueryRepo(testOperationInfo, nil).getServiceName(),
 			"test TestClickhouseQueryRepo_getServiceName() failed")
 	}
 }
@@ -592,7 +1011,7 @@ func TestClickhouseQueryRepo_getPMMVersion(t *testing.T) {
 	asst := assert.New(t)
 
 	if testOperationInfo.GetMonitorSystem().GetSystemType() == 2 {
-		asst.Equal(testOperationInfo.GetMonitorSystem().GetSystemType(), testQueryRepo.(*ClickhouseQueryRepo).getPMMVersion(),
+		asst.Equal(testOperationInfo.GetMonitorSystem().GetSystemType(), NewClickhouseQueryRepo(testOperationInfo, nil).getPMMVersion(),
 			"test TestClickhouseQueryRepo_getServiceName() failed")
 	}
 }
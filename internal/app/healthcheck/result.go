@@ -9,42 +9,48 @@ import (
 	"github.com/romberli/go-util/constant"
 )
 
-// Result include all data needed in healthcheck
+// Result include all data needed in healthcheck,
+// the results of the check items are saved in t_hc_result_item,
+// the item fields are kept so that the result could still be marshaled as the legacy json,
+// and the items which do not have a legacy field will be marshaled as other_items
 type Result struct {
 	healthcheck.DASRepo
-	ID                                int       `middleware:"id" json:"id"`
-	OperationID                       int       `middleware:"operation_id" json:"operation_id"`
-	WeightedAverageScore              int       `middleware:"weighted_average_score" json:"weighted_average_score"`
-	DBConfigScore                     int       `middleware:"db_config_score" json:"db_config_score"`
-	DBConfigData                      string    `middleware:"db_config_data" json:"db_config_data"`
-	DBConfigAdvice                    string    `middleware:"db_config_advice" json:"db_config_advice"`
-	CPUUsageScore                     int       `middleware:"cpu_usage_score" json:"cpu_usage_score"`
-	CPUUsageData                      string    `middleware:"cpu_usage_data" json:"cpu_usage_data"`
-	CPUUsageHigh                      string    `middleware:"cpu_usage_high" json:"cpu_usage_high"`
-	IOUtilScore                       int       `middleware:"io_util_score" json:"io_util_score"`
-	IOUtilData                        string    `middleware:"io_util_data" json:"io_util_data"`
-	IOUtilHigh                        string    `middleware:"io_util_high" json:"io_util_high"`
-	DiskCapacityUsageScore            int       `middleware:"disk_capacity_usage_score" json:"disk_capacity_usage_score"`
-	DiskCapacityUsageData             string    `middleware:"disk_capacity_usage_data" json:"disk_capacity_usage_data"`
-	DiskCapacityUsageHigh             string    `middleware:"disk_capacity_usage_high" json:"disk_capacity_usage_high"`
-	ConnectionUsageScore              int       `middleware:"connection_usage_score" json:"connection_usage_score"`
-	ConnectionUsageData               string    `middleware:"connection_usage_data" json:"connection_usage_data"`
-	ConnectionUsageHigh               string    `middleware:"connection_usage_high" json:"connection_usage_high"`
-	AverageActiveSessionPercentsScore int       `middleware:"average_active_session_percents_score" json:"average_active_session_percents_score"`
-	AverageActiveSessionPercentsData  string    `middleware:"average_active_session_percents_data" json:"average_active_session_percents_data"`
-	AverageActiveSessionPercentsHigh  string    `middleware:"average_active_session_percents_high" json:"average_active_session_percents_high"`
-	CacheMissRatioScore               int       `middleware:"cache_miss_ratio_score" json:"cache_miss_ratio_score"`
-	CacheMissRatioData                string    `middleware:"cache_miss_ratio_data" json:"cache_miss_ratio_data"`
-	CacheMissRatioHigh                string    `middleware:"cache_miss_ratio_high" json:"cache_miss_ratio_high"`
-	TableRowsScore                    int       `middleware:"table_rows_score" json:"table_rows_score"`
-	TableRowsData                     string    `middleware:"table_rows_data" json:"table_rows_data"`
-	TableRowsHigh                     string    `middleware:"table_rows_high" json:"table_rows_high"`
-	TableSizeScore                    int       `middleware:"table_size_score" json:"table_size_score"`
-	TableSizeData                     string    `middleware:"table_size_data" json:"table_size_data"`
-	TableSizeHigh                     string    `middleware:"table_size_high" json:"table_size_high"`
-	SlowQueryScore                    int       `middleware:"slow_query_score" json:"slow_query_score"`
-	SlowQueryData                     string    `middleware:"slow_query_data" json:"slow_query_data"`
-	SlowQueryAdvice                   string    `middleware:"slow_query_advice" json:"slow_query_advice"`
+	ID                                int           `middleware:"id" json:"id"`
+	OperationID                       int           `middleware:"operation_id" json:"operation_id"`
+	WeightedAverageScore              int           `middleware:"weighted_average_score" json:"weighted_average_score"`
+	EngineConfig                      string        `middleware:"engine_config" json:"engine_config"`
+	DBConfigScore                     int           `json:"db_config_score"`
+	DBConfigData                      string        `json:"db_config_data"`
+	DBConfigAdvice                    string        `json:"db_config_advice"`
+	CPUUsageScore                     int           `json:"cpu_usage_score"`
+	CPUUsageData                      string        `json:"cpu_usage_data"`
+	CPUUsageHigh                      string        `json:"cpu_usage_high"`
+	IOUtilScore                       int           `json:"io_util_score"`
+	IOUtilData                        string        `json:"io_util_data"`
+	IOUtilHigh                        string        `json:"io_util_high"`
+	DiskCapacityUsageScore            int           `json:"disk_capacity_usage_score"`
+	DiskCapacityUsageData             string        `json:"disk_capacity_usage_data"`
+	DiskCapacityUsageHigh             string        `json:"disk_capacity_usage_high"`
+	ConnectionUsageScore              int           `json:"connection_usage_score"`
+	ConnectionUsageData               string        `json:"connection_usage_data"`
+	ConnectionUsageHigh               string        `json:"connection_usage_high"`
+	AverageActiveSessionPercentsScore int           `json:"average_active_session_percents_score"`
+	AverageActiveSessionPercentsData  string        `json:"average_active_session_percents_data"`
+	AverageActiveSessionPercentsHigh  string        `json:"average_active_session_percents_high"`
+	CacheMissRatioScore               int           `json:"cache_miss_ratio_score"`
+	CacheMissRatioData                string        `json:"cache_miss_ratio_data"`
+	CacheMissRatioHigh                string        `json:"cache_miss_ratio_high"`
+	TableRowsScore                    int           `json:"table_rows_score"`
+	TableRowsData                     string        `json:"table_rows_data"`
+	TableRowsHigh                     string        `json:"table_rows_high"`
+	TableSizeScore                    int           `json:"table_size_score"`
+	TableSizeData                     string        `json:"table_size_data"`
+	TableSizeHigh                     string        `json:"table_size_high"`
+	SlowQueryScore                    int           `json:"slow_query_score"`
+	SlowQueryData                     string        `json:"slow_query_data"`
+	SlowQueryAdvice                   string        `json:"slow_query_advice"`
+	OtherItems                        []*ResultItem `json:"other_items"`
+	items                             []*ResultItem
 	AccuracyReview                    int       `middleware:"accuracy_review" json:"accuracy_review"`
 	DelFlag                           int       `middleware:"del_flag" json:"del_flag"`
 	CreateTime                        time.Time `middleware:"create_time" json:"create_time"`
@@ -61,41 +67,24 @@ func NewResult(repo healthcheck.DASRepo, operationID int, weightedAverageScore i
 	tableRowsScore int, tableRowsData string, tableRowsHigh string,
 	tableSizeScore int, tableSizeData string, tableSizeHigh string,
 	slowQueryScore int, slowQueryData string, slowQueryAdvice string) *Result {
-	return &Result{
-		DASRepo:                           repo,
-		OperationID:                       operationID,
-		WeightedAverageScore:              weightedAverageScore,
-		DBConfigScore:                     dbConfigScore,
-		DBConfigData:                      dbConfigData,
-		DBConfigAdvice:                    dbConfigAdvice,
-		CPUUsageScore:                     cpuUsageScore,
-		CPUUsageData:                      cpuUsageData,
-		CPUUsageHigh:                      cpuUsageHigh,
-		IOUtilScore:                       ioUtilScore,
-		IOUtilData:                        ioUtilData,
-		IOUtilHigh:                        ioUtilHigh,
-		DiskCapacityUsageScore:            diskCapacityUsageScore,
-		DiskCapacityUsageData:             diskCapacityUsageData,
-		DiskCapacityUsageHigh:             diskCapacityUsageHigh,
-		ConnectionUsageScore:              connectionUsageScore,
-		ConnectionUsageData:               connectionUsageData,
-		ConnectionUsageHigh:               connectionUsageHigh,
-		AverageActiveSessionPercentsScore: averageActiveSessionPercentsScore,
-		AverageActiveSessionPercentsData:  averageActiveSessionPercentsData,
-		AverageActiveSessionPercentsHigh:  averageActiveSessionPercentsHigh,
-		CacheMissRatioScore:               cacheMissRatioScore,
-		CacheMissRatioData:                cacheMissRatioData,
-		CacheMissRatioHigh:                cacheMissRatioHigh,
-		TableRowsScore:                    tableRowsScore,
-		TableRowsData:                     tableRowsData,
-		TableRowsHigh:                     tableRowsHigh,
-		TableSizeScore:                    tableSizeScore,
-		TableSizeData:                     tableSizeData,
-		TableSizeHigh:                     tableSizeHigh,
-		SlowQueryScore:                    slowQueryScore,
-		SlowQueryData:                     slowQueryData,
-		SlowQueryAdvice:                   slowQueryAdvice,
+	r := &Result{
+		DASRepo:              repo,
+		OperationID:          operationID,
+		WeightedAverageScore: weightedAverageScore,
 	}
+	r.setResultItem(NewResultItem(operationID, defaultDBConfigItemName, dbConfigScore, dbConfigData, constant.EmptyString, dbConfigAdvice))
+	r.setResultItem(NewResultItem(operationID, defaultCPUUsageItemName, cpuUsageScore, cpuUsageData, cpuUsageHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultIOUtilItemName, ioUtilScore, ioUtilData, ioUtilHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultDiskCapacityUsageItemName, diskCapacityUsageScore, diskCapacityUsageData, diskCapacityUsageHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultConnectionUsageItemName, connectionUsageScore, connectionUsageData, connectionUsageHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultAverageActiveSessionPercentsItemName, averageActiveSessionPercentsScore,
+		averageActiveSessionPercentsData, averageActiveSessionPercentsHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultCacheMissRatioItemName, cacheMissRatioScore, cacheMissRatioData, cacheMissRatioHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultTableRowsItemName, tableRowsScore, tableRowsData, tableRowsHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultTableSizeItemName, tableSizeScore, tableSizeData, tableSizeHigh, constant.EmptyString))
+	r.setResultItem(NewResultItem(operationID, defaultSlowQueryRowsExaminedItemName, slowQueryScore, slowQueryData, constant.EmptyString, slowQueryAdvice))
+
+	return r
 }
 
 // NewEmptyResultWithRepo return a new Result
@@ -113,42 +102,20 @@ func NewResultWithDefault(operationID int, weightedAverageScore int, dbConfigSco
 	cpuUsageScore int, ioUtilScore int, diskCapacityUsageScore int, connectionUsageScore int,
 	averageActiveSessionPercentsScore int, cacheMissRatioScore int, tableRowsScore int, tableSizeScore int,
 	slowQueryScore int, accuracyReview int) *Result {
-	return &Result{
-		DASRepo:                           NewDASRepoWithGlobal(),
-		OperationID:                       operationID,
-		WeightedAverageScore:              weightedAverageScore,
-		DBConfigScore:                     dbConfigScore,
-		DBConfigData:                      constant.DefaultRandomString,
-		DBConfigAdvice:                    constant.DefaultRandomString,
-		CPUUsageScore:                     cpuUsageScore,
-		CPUUsageData:                      constant.DefaultRandomString,
-		CPUUsageHigh:                      constant.DefaultRandomString,
-		IOUtilScore:                       ioUtilScore,
-		IOUtilData:                        constant.DefaultRandomString,
-		IOUtilHigh:                        constant.DefaultRandomString,
-		DiskCapacityUsageScore:            diskCapacityUsageScore,
-		DiskCapacityUsageData:             constant.DefaultRandomString,
-		DiskCapacityUsageHigh:             constant.DefaultRandomString,
-		ConnectionUsageScore:              connectionUsageScore,
-		ConnectionUsageData:               constant.DefaultRandomString,
-		ConnectionUsageHigh:               constant.DefaultRandomString,
-		AverageActiveSessionPercentsScore: averageActiveSessionPercentsScore,
-		AverageActiveSessionPercentsData:  constant.DefaultRandomString,
-		AverageActiveSessionPercentsHigh:  constant.DefaultRandomString,
-		CacheMissRatioScore:               cacheMissRatioScore,
-		CacheMissRatioData:                constant.DefaultRandomString,
-		CacheMissRatioHigh:                constant.DefaultRandomString,
-		TableRowsScore:                    tableRowsScore,
-		TableRowsData:                     constant.DefaultRandomString,
-		TableRowsHigh:                     constant.DefaultRandomString,
-		TableSizeScore:                    tableSizeScore,
-		TableSizeData:                     constant.DefaultRandomString,
-		TableSizeHigh:                     constant.DefaultRandomString,
-		SlowQueryScore:                    slowQueryScore,
-		SlowQueryData:                     constant.DefaultRandomString,
-		SlowQueryAdvice:                   constant.DefaultRandomString,
-		AccuracyReview:                    accuracyReview,
-	}
+	r := NewResult(NewDASRepoWithGlobal(), operationID, weightedAverageScore,
+		dbConfigScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		cpuUsageScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		ioUtilScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		diskCapacityUsageScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		connectionUsageScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		averageActiveSessionPercentsScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		cacheMissRatioScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		tableRowsScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		tableSizeScore, constant.DefaultRandomString, constant.DefaultRandomString,
+		slowQueryScore, constant.DefaultRandomString, constant.DefaultRandomString)
+	r.AccuracyReview = accuracyReview
+
+	return r
 }
 
 // NewEmptyResult return a empty Result
@@ -171,6 +138,11 @@ func (r *Result) GetWeightedAverageScore() int {
 	return r.WeightedAverageScore
 }
 
+// GetEngineConfig returns the item configs which the result was scored with, the overrides have been applied
+func (r *Result) GetEngineConfig() string {
+	return r.EngineConfig
+}
+
 // GetDBConfigScore returns the DBConfigScore
 func (r *Result) GetDBConfigScore() int {
 	return r.DBConfigScore
@@ -321,6 +293,16 @@ func (r *Result) GetSlowQueryAdvice() string {
 	return r.SlowQueryAdvice
 }
 
+// GetItems returns the results of all the check items
+func (r *Result) GetItems() []healthcheck.ResultItem {
+	items := make([]healthcheck.ResultItem, len(r.items))
+	for i := range items {
+		items[i] = r.items[i]
+	}
+
+	return items
+}
+
 // GetAccuracyReview returns the AccuracyReview
 func (r *Result) GetAccuracyReview() int {
 	return r.AccuracyReview
@@ -362,3 +344,66 @@ func (r *Result) MarshalJSON() ([]byte, error) {
 func (r *Result) MarshalJSONWithFields(fields ...string) ([]byte, error) {
 	return common.MarshalStructWithFields(r, fields...)
 }
+
+// setItemResult adds the item result to the result,
+// it returns false if the item does not have legacy fields or the item is unavailable
+func (r *Result) setItemResult(itemResult *ItemResult) bool {
+	return r.setResultItem(NewResultItemWithItemResult(r.GetOperationID(), itemResult))
+}
+
+// setResultItem adds the item to the result, the item replaces the one which has the same item name,
+// it also sets the legacy fields of the item, and returns false if the item does not have legacy fields,
+// in that case, the item will be marshaled as one of the other items,
+// the unavailable item does not set the legacy fields, so that it could not be taken as a real score of 0
+func (r *Result) setResultItem(item *ResultItem) bool {
+	r.items = replaceResultItem(r.items, item)
+	if !item.IsAvailable() {
+		r.OtherItems = replaceResultItem(r.OtherItems, item)
+		return false
+	}
+
+	score := item.GetScore()
+	data := item.GetData()
+	high := item.GetHigh()
+	advice := item.GetAdvice()
+
+	switch item.GetItemName() {
+	case defaultDBConfigItemName:
+		r.DBConfigScore, r.DBConfigData, r.DBConfigAdvice = score, data, advice
+	case defaultCPUUsageItemName:
+		r.CPUUsageScore, r.CPUUsageData, r.CPUUsageHigh = score, data, high
+	case defaultIOUtilItemName:
+		r.IOUtilScore, r.IOUtilData, r.IOUtilHigh = score, data, high
+	case defaultDiskCapacityUsageItemName:
+		r.DiskCapacityUsageScore, r.DiskCapacityUsageData, r.DiskCapacityUsageHigh = score, data, high
+	case defaultConnectionUsageItemName:
+		r.ConnectionUsageScore, r.ConnectionUsageData, r.ConnectionUsageHigh = score, data, high
+	case defaultAverageActiveSessionPercentsItemName:
+		r.AverageActiveSessionPercentsScore, r.AverageActiveSessionPercentsData, r.AverageActiveSessionPercentsHigh = score, data, high
+	case defaultCacheMissRatioItemName:
+		r.CacheMissRatioScore, r.CacheMissRatioData, r.CacheMissRatioHigh = score, data, high
+	case defaultTableRowsItemName:
+		r.TableRowsScore, r.TableRowsData, r.TableRowsHigh = score, data, high
+	case defaultTableSizeItemName:
+		r.TableSizeScore, r.TableSizeData, r.TableSizeHigh = score, data, high
+	case defaultSlowQueryRowsExaminedItemName:
+		r.SlowQueryScore, r.SlowQueryData, r.SlowQueryAdvice = score, data, advice
+	default:
+		r.OtherItems = replaceResultItem(r.OtherItems, item)
+		return false
+	}
+
+	return true
+}
+
+// replaceResultItem replaces the item which has the same item name in the items, or appends the item if it does not exist
+func replaceResultItem(items []*ResultItem, item *ResultItem) []*ResultItem {
+	for i := range items {
+		if items[i].GetItemName() == item.GetItemName() {
+			items[i] = item
+			return items
+		}
+	}
+
+	return append(items, item)
+}
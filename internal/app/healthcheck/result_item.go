@@ -0,0 +1,123 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+)
+
+var _ healthcheck.ResultItem = (*ResultItem)(nil)
+
+// ResultItem is the result of a check item, it is saved as a row of t_hc_result_item
+type ResultItem struct {
+	ID             int       `middleware:"id" json:"id"`
+	OperationID    int       `middleware:"operation_id" json:"operation_id"`
+	ItemName       string    `middleware:"item_name" json:"item_name"`
+	Score          int       `middleware:"score" json:"score"`
+	Data           string    `middleware:"data" json:"data"`
+	High           string    `middleware:"high" json:"high"`
+	Advice         string    `middleware:"advice" json:"advice"`
+	Status         string    `middleware:"status" json:"status"`
+	Error          string    `middleware:"error" json:"error"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewResultItem returns a new *ResultItem
+func NewResultItem(operationID int, itemName string, score int, data, high, advice string) *ResultItem {
+	return &ResultItem{
+		OperationID: operationID,
+		ItemName:    itemName,
+		Score:       score,
+		Data:        data,
+		High:        high,
+		Advice:      advice,
+		Status:      defaultItemStatusAvailable,
+	}
+}
+
+// NewResultItemWithItemResult returns a new *ResultItem with given item result,
+// the unavailable item result will be kept with its error
+func NewResultItemWithItemResult(operationID int, itemResult *ItemResult) *ResultItem {
+	return &ResultItem{
+		OperationID: operationID,
+		ItemName:    itemResult.GetItemName(),
+		Score:       itemResult.GetScore(),
+		Data:        itemResult.GetData(),
+		High:        itemResult.GetHigh(),
+		Advice:      itemResult.GetAdvice(),
+		Status:      itemResult.GetStatus(),
+		Error:       itemResult.GetError(),
+	}
+}
+
+// NewEmptyResultItem returns a new empty *ResultItem
+func NewEmptyResultItem() *ResultItem {
+	return &ResultItem{}
+}
+
+// Identity returns the identity
+func (ri *ResultItem) Identity() int {
+	return ri.ID
+}
+
+// GetOperationID returns the operation id
+func (ri *ResultItem) GetOperationID() int {
+	return ri.OperationID
+}
+
+// GetItemName returns the item name
+func (ri *ResultItem) GetItemName() string {
+	return ri.ItemName
+}
+
+// GetScore returns the score of the item
+func (ri *ResultItem) GetScore() int {
+	return ri.Score
+}
+
+// GetData returns the data of the item
+func (ri *ResultItem) GetData() string {
+	return ri.Data
+}
+
+// GetHigh returns the high data of the item
+func (ri *ResultItem) GetHigh() string {
+	return ri.High
+}
+
+// GetAdvice returns the advice of the item
+func (ri *ResultItem) GetAdvice() string {
+	return ri.Advice
+}
+
+// GetStatus returns the status of the item, it is one of available and unavailable
+func (ri *ResultItem) GetStatus() string {
+	return ri.Status
+}
+
+// GetError returns the error message of the unavailable item
+func (ri *ResultItem) GetError() string {
+	return ri.Error
+}
+
+// IsAvailable returns if the check item completed successfully
+func (ri *ResultItem) IsAvailable() bool {
+	return ri.Status != defaultItemStatusUnavailable
+}
+
+// GetDelFlag returns the delete flag
+func (ri *ResultItem) GetDelFlag() int {
+	return ri.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (ri *ResultItem) GetCreateTime() time.Time {
+	return ri.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (ri *ResultItem) GetLastUpdateTime() time.Time {
+	return ri.LastUpdateTime
+}
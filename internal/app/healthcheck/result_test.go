@@ -70,8 +70,13 @@ func rCreateService() (*Service, error) {
 }
 
 func rDeleteHCResultByOperationID(operationID int) error {
-	sql := `delete from t_hc_result where operation_id = ?`
+	sql := `delete from t_hc_result_item where operation_id = ?`
 	_, err := testDASRepo.Execute(sql, operationID)
+	if err != nil {
+		return err
+	}
+	sql = `delete from t_hc_result where operation_id = ?`
+	_, err = testDASRepo.Execute(sql, operationID)
 	return err
 }
 
@@ -119,6 +124,7 @@ func TestResultAll(t *testing.T) {
 }
 
 func TestResult_Identity(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -134,6 +140,7 @@ func TestResult_Identity(t *testing.T) {
 }
 
 func TestResult_GetOperationID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -149,6 +156,7 @@ func TestResult_GetOperationID(t *testing.T) {
 }
 
 func TestResult_GetWeightedAverageScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -164,6 +172,7 @@ func TestResult_GetWeightedAverageScore(t *testing.T) {
 }
 
 func TestResult_GetDBConfigScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -179,6 +188,7 @@ func TestResult_GetDBConfigScore(t *testing.T) {
 }
 
 func TestResult_GetDBConfigData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -194,6 +204,7 @@ func TestResult_GetDBConfigData(t *testing.T) {
 }
 
 func TestResult_GetDBConfigAdvice(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -209,6 +220,7 @@ func TestResult_GetDBConfigAdvice(t *testing.T) {
 }
 
 func TestResult_GetCPUUsageScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -224,6 +236,7 @@ func TestResult_GetCPUUsageScore(t *testing.T) {
 }
 
 func TestResult_GetCPUUsageData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -239,6 +252,7 @@ func TestResult_GetCPUUsageData(t *testing.T) {
 }
 
 func TestResult_GetCPUUsageHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -254,6 +268,7 @@ func TestResult_GetCPUUsageHigh(t *testing.T) {
 }
 
 func TestResult_GetIOUtilScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -269,6 +284,7 @@ func TestResult_GetIOUtilScore(t *testing.T) {
 }
 
 func TestResult_GetIOUtilData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -284,6 +300,7 @@ func TestResult_GetIOUtilData(t *testing.T) {
 }
 
 func TestResult_GetIOUtilHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -299,6 +316,7 @@ func TestResult_GetIOUtilHigh(t *testing.T) {
 }
 
 func TestResult_GetDiskCapacityUsageScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -314,6 +332,7 @@ func TestResult_GetDiskCapacityUsageScore(t *testing.T) {
 }
 
 func TestResult_GetDiskCapacityUsageData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -329,6 +348,7 @@ func TestResult_GetDiskCapacityUsageData(t *testing.T) {
 }
 
 func TestResult_GetDiskCapacityUsageHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -344,6 +364,7 @@ func TestResult_GetDiskCapacityUsageHigh(t *testing.T) {
 }
 
 func TestResult_GetConnectionUsageScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -359,6 +380,7 @@ func TestResult_GetConnectionUsageScore(t *testing.T) {
 }
 
 func TestResult_GetConnectionUsageData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -374,6 +396,7 @@ func TestResult_GetConnectionUsageData(t *testing.T) {
 }
 
 func TestResult_GetConnectionUsageHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -389,6 +412,7 @@ func TestResult_GetConnectionUsageHigh(t *testing.T) {
 }
 
 func TestResult_GetAverageActiveSessionPercentsScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -404,6 +428,7 @@ func TestResult_GetAverageActiveSessionPercentsScore(t *testing.T) {
 }
 
 func TestResult_GetAverageActiveSessionPercentsData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -419,6 +444,7 @@ func TestResult_GetAverageActiveSessionPercentsData(t *testing.T) {
 }
 
 func TestResult_GetAverageActiveSessionPercentsHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -434,6 +460,7 @@ func TestResult_GetAverageActiveSessionPercentsHigh(t *testing.T) {
 }
 
 func TestResult_GetCacheMissRatioScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -449,6 +476,7 @@ func TestResult_GetCacheMissRatioScore(t *testing.T) {
 }
 
 func TestResult_GetCacheMissRatioData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -464,6 +492,7 @@ func TestResult_GetCacheMissRatioData(t *testing.T) {
 }
 
 func TestResult_GetCacheMissRatioHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -479,6 +508,7 @@ func TestResult_GetCacheMissRatioHigh(t *testing.T) {
 }
 
 func TestResult_GetTableRowsScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -494,6 +524,7 @@ func TestResult_GetTableRowsScore(t *testing.T) {
 }
 
 func TestResult_GetTableRowsData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -509,6 +540,7 @@ func TestResult_GetTableRowsData(t *testing.T) {
 }
 
 func TestResult_GetTableRowsHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -524,6 +556,7 @@ func TestResult_GetTableRowsHigh(t *testing.T) {
 }
 
 func TestResult_GetTableSizeScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -539,6 +572,7 @@ func TestResult_GetTableSizeScore(t *testing.T) {
 }
 
 func TestResult_GetTableSizeData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -554,6 +588,7 @@ func TestResult_GetTableSizeData(t *testing.T) {
 }
 
 func TestResult_GetTableSizeHigh(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -569,6 +604,7 @@ func TestResult_GetTableSizeHigh(t *testing.T) {
 }
 
 func TestResult_GetSlowQueryScore(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -584,6 +620,7 @@ func TestResult_GetSlowQueryScore(t *testing.T) {
 }
 
 func TestResult_GetSlowQueryData(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -599,6 +636,7 @@ func TestResult_GetSlowQueryData(t *testing.T) {
 }
 
 func TestResult_GetSlowQueryAdvice(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -614,6 +652,7 @@ func TestResult_GetSlowQueryAdvice(t *testing.T) {
 }
 
 func TestResult_GetAccuracyReview(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -629,6 +668,7 @@ func TestResult_GetAccuracyReview(t *testing.T) {
 }
 
 func TestResult_GetDelFlag(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -644,6 +684,7 @@ func TestResult_GetDelFlag(t *testing.T) {
 }
 
 func TestResult_GetCreateTime(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -659,6 +700,7 @@ func TestResult_GetCreateTime(t *testing.T) {
 }
 
 func TestResult_GetLastUpdateTime(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -674,6 +716,7 @@ func TestResult_GetLastUpdateTime(t *testing.T) {
 }
 
 func TestResult_Set(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -700,6 +743,7 @@ func TestResult_Set(t *testing.T) {
 }
 
 func TestResult_MarshalJSON(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
@@ -715,6 +759,7 @@ func TestResult_MarshalJSON(t *testing.T) {
 }
 
 func TestResult_MarshalJSONWithFields(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := rCreateService()
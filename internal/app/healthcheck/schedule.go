@@ -0,0 +1,166 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	ScheduleTargetTypeMySQLServer  = 1
+	ScheduleTargetTypeMySQLCluster = 2
+	ScheduleTargetTypeEnv          = 3
+	ScheduleTargetTypeApp          = 4
+
+	ScheduleStatusActive = 1
+	ScheduleStatusPaused = 2
+
+	minScheduleStep = time.Second
+)
+
+var _ healthcheck.Schedule = (*Schedule)(nil)
+
+// Schedule is a periodic healthcheck, it will check the mysql servers of the target every time the cron expression is matched,
+// the time range of the check ends at the trigger time and lasts for the window
+type Schedule struct {
+	ID             int       `middleware:"id" json:"id"`
+	ScheduleName   string    `middleware:"schedule_name" json:"schedule_name"`
+	TargetType     int       `middleware:"target_type" json:"target_type"`
+	TargetID       int       `middleware:"target_id" json:"target_id"`
+	CronExpr       string    `middleware:"cron_expr" json:"cron_expr"`
+	WindowSeconds  int       `middleware:"window_seconds" json:"window_seconds"`
+	Step           int       `middleware:"step" json:"step"`
+	Status         int       `middleware:"status" json:"status"`
+	LastRunTime    time.Time `middleware:"last_run_time" json:"last_run_time"`
+	NextRunTime    time.Time `middleware:"next_run_time" json:"next_run_time"`
+	Message        string    `middleware:"message" json:"message"`
+	DelFlag        int       `middleware:"del_flag" json:"del_flag"`
+	CreateTime     time.Time `middleware:"create_time" json:"create_time"`
+	LastUpdateTime time.Time `middleware:"last_update_time" json:"last_update_time"`
+}
+
+// NewSchedule returns a new *Schedule, it validates the arguments and calculates the first run time from now
+func NewSchedule(scheduleName string, targetType, targetID int, cronExpr string, window, step time.Duration) (*Schedule, error) {
+	if targetType < ScheduleTargetTypeMySQLServer || targetType > ScheduleTargetTypeApp {
+		return nil, message.NewMessage(msghc.ErrScheduleTargetTypeInvalid, targetType)
+	}
+	if step < minScheduleStep {
+		return nil, message.NewMessage(msghc.ErrScheduleStepInvalid, step.String())
+	}
+	if window <= step {
+		return nil, message.NewMessage(msghc.ErrScheduleWindowInvalid, window.String(), step.String())
+	}
+	nextRunTime, err := getNextRunTime(cronExpr, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Schedule{
+		ScheduleName:  scheduleName,
+		TargetType:    targetType,
+		TargetID:      targetID,
+		CronExpr:      cronExpr,
+		WindowSeconds: int(window.Seconds()),
+		Step:          int(step.Seconds()),
+		Status:        ScheduleStatusActive,
+		NextRunTime:   nextRunTime,
+	}, nil
+}
+
+// NewEmptySchedule returns a new empty *Schedule
+func NewEmptySchedule() *Schedule {
+	return &Schedule{}
+}
+
+// Identity returns the identity
+func (s *Schedule) Identity() int {
+	return s.ID
+}
+
+// GetScheduleName returns the schedule name
+func (s *Schedule) GetScheduleName() string {
+	return s.ScheduleName
+}
+
+// GetTargetType returns the target type
+func (s *Schedule) GetTargetType() int {
+	return s.TargetType
+}
+
+// GetTargetID returns the target id
+func (s *Schedule) GetTargetID() int {
+	return s.TargetID
+}
+
+// GetCronExpr returns the cron expression
+func (s *Schedule) GetCronExpr() string {
+	return s.CronExpr
+}
+
+// GetWindow returns the time range of the healthcheck
+func (s *Schedule) GetWindow() time.Duration {
+	return time.Duration(s.WindowSeconds) * time.Second
+}
+
+// GetStep returns the step of the healthcheck
+func (s *Schedule) GetStep() time.Duration {
+	return time.Duration(s.Step) * time.Second
+}
+
+// GetStatus returns the status
+func (s *Schedule) GetStatus() int {
+	return s.Status
+}
+
+// GetLastRunTime returns the last run time
+func (s *Schedule) GetLastRunTime() time.Time {
+	return s.LastRunTime
+}
+
+// GetNextRunTime returns the next run time
+func (s *Schedule) GetNextRunTime() time.Time {
+	return s.NextRunTime
+}
+
+// GetMessage returns the message of the last run
+func (s *Schedule) GetMessage() string {
+	return s.Message
+}
+
+// GetDelFlag returns the delete flag
+func (s *Schedule) GetDelFlag() int {
+	return s.DelFlag
+}
+
+// GetCreateTime returns the create time
+func (s *Schedule) GetCreateTime() time.Time {
+	return s.CreateTime
+}
+
+// GetLastUpdateTime returns the last update time
+func (s *Schedule) GetLastUpdateTime() time.Time {
+	return s.LastUpdateTime
+}
+
+// MarshalJSON marshals Schedule to json bytes
+func (s *Schedule) MarshalJSON() ([]byte, error) {
+	return common.MarshalStructWithTag(s, constant.DefaultMarshalTag)
+}
+
+// getNextRunTime parses the cron expression and returns the first matched time which is later than given time
+func getNextRunTime(cronExpr string, t time.Time) (time.Time, error) {
+	ce, err := ParseCronExpr(cronExpr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	nextRunTime := ce.Next(t)
+	if nextRunTime.IsZero() {
+		return time.Time{}, message.NewMessage(msghc.ErrHealthcheckScheduleNoNextRun, cronExpr)
+	}
+
+	return nextRunTime, nil
+}
@@ -0,0 +1,246 @@
+package healthcheck
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/log"
+)
+
+var _ healthcheck.ScheduleRepo = (*ScheduleRepo)(nil)
+
+// ScheduleRepo is the repository of the healthcheck schedules
+type ScheduleRepo struct {
+	Database middleware.Pool
+}
+
+// NewScheduleRepo returns *ScheduleRepo with given middleware.Pool
+func NewScheduleRepo(db middleware.Pool) *ScheduleRepo {
+	return &ScheduleRepo{Database: db}
+}
+
+// NewScheduleRepoWithGlobal returns *ScheduleRepo with global mysql pool
+func NewScheduleRepoWithGlobal() *ScheduleRepo {
+	return NewScheduleRepo(global.DASMySQLPool)
+}
+
+// Execute executes given command and placeholders on the middleware
+func (sr *ScheduleRepo) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	conn, err := sr.Database.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		err = conn.Close()
+		if err != nil {
+			log.Errorf("healthcheck ScheduleRepo.Execute(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	return conn.Execute(command, args...)
+}
+
+// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+func (sr *ScheduleRepo) Transaction() (middleware.Transaction, error) {
+	return sr.Database.Transaction()
+}
+
+// GetAll gets all the schedules from the middleware
+func (sr *ScheduleRepo) GetAll() ([]healthcheck.Schedule, error) {
+	sql := `
+		select id, schedule_name, target_type, target_id, cron_expr, window_seconds, step, status,
+		last_run_time, next_run_time, message, del_flag, create_time, last_update_time
+		from t_hc_schedule_info
+		where del_flag = 0
+		order by id;
+	`
+	log.Debugf("healthCheck ScheduleRepo.GetAll() select sql: \n%s", sql)
+
+	return sr.getSchedules(sql)
+}
+
+// GetByID gets the schedule by the identity from the middleware
+func (sr *ScheduleRepo) GetByID(id int) (healthcheck.Schedule, error) {
+	sql := `
+		select id, schedule_name, target_type, target_id, cron_expr, window_seconds, step, status,
+		last_run_time, next_run_time, message, del_flag, create_time, last_update_time
+		from t_hc_schedule_info
+		where del_flag = 0
+		and id = ?;
+	`
+	log.Debugf("healthCheck ScheduleRepo.GetByID() select sql: \n%s\nplaceholders: %s", sql, id)
+
+	result, err := sr.Execute(sql, id)
+	if err != nil {
+		return nil, err
+	}
+	switch result.RowNumber() {
+	case 0:
+		return nil, fmt.Errorf("healthCheck ScheduleRepo.GetByID(): data does not exists, id: %d", id)
+	case 1:
+		schedule := NewEmptySchedule()
+		// map to struct
+		err = result.MapToStructByRowIndex(schedule, constant.ZeroInt, constant.DefaultMiddlewareTag)
+		if err != nil {
+			return nil, err
+		}
+
+		return schedule, nil
+	default:
+		return nil, fmt.Errorf("healthCheck ScheduleRepo.GetByID(): duplicate key exists, id: %d", id)
+	}
+}
+
+// GetDue gets the active schedules whose next run time is not later than given time from the middleware
+func (sr *ScheduleRepo) GetDue(t time.Time) ([]healthcheck.Schedule, error) {
+	sql := `
+		select id, schedule_name, target_type, target_id, cron_expr, window_seconds, step, status,
+		last_run_time, next_run_time, message, del_flag, create_time, last_update_time
+		from t_hc_schedule_info
+		where del_flag = 0
+		and status = ?
+		and next_run_time <= ?
+		order by next_run_time;
+	`
+	tStr := t.Format(constant.TimeLayoutSecond)
+	log.Debugf("healthCheck ScheduleRepo.GetDue() select sql: \n%s\nplaceholders: %s, %s", sql, ScheduleStatusActive, tStr)
+
+	return sr.getSchedules(sql, ScheduleStatusActive, tStr)
+}
+
+// Create creates a schedule in the middleware
+func (sr *ScheduleRepo) Create(schedule healthcheck.Schedule) (healthcheck.Schedule, error) {
+	nextRunTimeStr := schedule.GetNextRunTime().Format(constant.TimeLayoutSecond)
+	windowSeconds := int(schedule.GetWindow().Seconds())
+	step := int(schedule.GetStep().Seconds())
+
+	sql := `
+		insert into t_hc_schedule_info(schedule_name, target_type, target_id, cron_expr, window_seconds, step, status, next_run_time)
+		values(?, ?, ?, ?, ?, ?, ?, ?);
+	`
+	log.Debugf("healthCheck ScheduleRepo.Create() insert sql: \n%s\nplaceholders: %s, %s, %s, %s, %s, %s, %s, %s",
+		sql, schedule.GetScheduleName(), schedule.GetTargetType(), schedule.GetTargetID(), schedule.GetCronExpr(),
+		windowSeconds, step, schedule.GetStatus(), nextRunTimeStr)
+
+	_, err := sr.Execute(sql, schedule.GetScheduleName(), schedule.GetTargetType(), schedule.GetTargetID(), schedule.GetCronExpr(),
+		windowSeconds, step, schedule.GetStatus(), nextRunTimeStr)
+	if err != nil {
+		return nil, err
+	}
+	// get id
+	sql = `select id from t_hc_schedule_info where del_flag = 0 and schedule_name = ?;`
+	log.Debugf("healthCheck ScheduleRepo.Create() select sql: \n%s\nplaceholders: %s", sql, schedule.GetScheduleName())
+	result, err := sr.Execute(sql, schedule.GetScheduleName())
+	if err != nil {
+		return nil, err
+	}
+	id, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return nil, err
+	}
+	// get entity
+	return sr.GetByID(id)
+}
+
+// UpdateStatus updates the status and the next run time of the schedule
+func (sr *ScheduleRepo) UpdateStatus(id, status int, nextRunTime time.Time) error {
+	nextRunTimeStr := nextRunTime.Format(constant.TimeLayoutSecond)
+	sql := `update t_hc_schedule_info set status = ?, next_run_time = ? where id = ?;`
+	log.Debugf("healthCheck ScheduleRepo.UpdateStatus() update sql: \n%s\nplaceholders: %s, %s, %s", sql, status, nextRunTimeStr, id)
+	_, err := sr.Execute(sql, status, nextRunTimeStr, id)
+
+	return err
+}
+
+// Claim moves the next run time of the schedule forward only if it is still the expected one,
+// as the row is locked in the transaction, only one das server could claim the same run of the schedule
+func (sr *ScheduleRepo) Claim(id int, expectedNextRunTime, lastRunTime, nextRunTime time.Time) (bool, error) {
+	expectedNextRunTimeStr := expectedNextRunTime.Format(constant.TimeLayoutSecond)
+	lastRunTimeStr := lastRunTime.Format(constant.TimeLayoutSecond)
+	nextRunTimeStr := nextRunTime.Format(constant.TimeLayoutSecond)
+
+	tx, err := sr.Transaction()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		err = tx.Close()
+		if err != nil {
+			log.Errorf("healthcheck ScheduleRepo.Claim(): close database connection failed.\n%s", err.Error())
+		}
+	}()
+
+	err = tx.Begin()
+	if err != nil {
+		return false, err
+	}
+	sql := `select count(1) from t_hc_schedule_info where del_flag = 0 and id = ? and status = ? and next_run_time = ? for update;`
+	log.Debugf("healthCheck ScheduleRepo.Claim() select sql: \n%s\nplaceholders: %s, %s, %s", sql, id, ScheduleStatusActive, expectedNextRunTimeStr)
+	result, err := tx.Execute(sql, id, ScheduleStatusActive, expectedNextRunTimeStr)
+	if err != nil {
+		return false, err
+	}
+	count, err := result.GetInt(constant.ZeroInt, constant.ZeroInt)
+	if err != nil {
+		return false, err
+	}
+	if count == constant.ZeroInt {
+		// claimed by others, paused or deleted
+		return false, tx.Rollback()
+	}
+	sql = `update t_hc_schedule_info set last_run_time = ?, next_run_time = ? where id = ?;`
+	log.Debugf("healthCheck ScheduleRepo.Claim() update sql: \n%s\nplaceholders: %s, %s, %s", sql, lastRunTimeStr, nextRunTimeStr, id)
+	_, err = tx.Execute(sql, lastRunTimeStr, nextRunTimeStr, id)
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// UpdateMessage updates the message of the last run
+func (sr *ScheduleRepo) UpdateMessage(id int, message string) error {
+	sql := `update t_hc_schedule_info set message = ? where id = ?;`
+	log.Debugf("healthCheck ScheduleRepo.UpdateMessage() update sql: \n%s\nplaceholders: %s, %s", sql, message, id)
+	_, err := sr.Execute(sql, message, id)
+
+	return err
+}
+
+// Delete deletes the schedule in the middleware
+func (sr *ScheduleRepo) Delete(id int) error {
+	sql := `delete from t_hc_schedule_info where id = ?;`
+	log.Debugf("healthCheck ScheduleRepo.Delete() delete sql: \n%s\nplaceholders: %s", sql, id)
+	_, err := sr.Execute(sql, id)
+
+	return err
+}
+
+// getSchedules executes given select sql and maps the result to the schedules
+func (sr *ScheduleRepo) getSchedules(sql string, args ...interface{}) ([]healthcheck.Schedule, error) {
+	result, err := sr.Execute(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	// init []*Schedule
+	scheduleList := make([]*Schedule, result.RowNumber())
+	for i := range scheduleList {
+		scheduleList[i] = NewEmptySchedule()
+	}
+	// map to struct
+	err = result.MapToStructSlice(scheduleList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+	// init []healthcheck.Schedule
+	schedules := make([]healthcheck.Schedule, result.RowNumber())
+	for i := range schedules {
+		schedules[i] = scheduleList[i]
+	}
+
+	return schedules, nil
+}
@@ -0,0 +1,137 @@
+package healthcheck
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	defaultScheduleName     = "test_schedule"
+	defaultScheduleCronExpr = "0 8 * * *"
+	defaultScheduleWindow   = constant.Day
+)
+
+func initScheduleRepo() *ScheduleRepo {
+	return NewScheduleRepoWithGlobal()
+}
+
+func createSchedule() (int, error) {
+	schedule, err := NewSchedule(defaultScheduleName, ScheduleTargetTypeMySQLCluster, defaultMySQLClusterID,
+		defaultScheduleCronExpr, defaultScheduleWindow, defaultStep)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+	entity, err := testScheduleRepo.Create(schedule)
+	if err != nil {
+		return constant.ZeroInt, err
+	}
+
+	return entity.Identity(), nil
+}
+
+func TestScheduleRepoAll(t *testing.T) {
+	TestScheduleRepo_Create(t)
+	TestScheduleRepo_GetAll(t)
+	TestScheduleRepo_GetDue(t)
+	TestScheduleRepo_UpdateStatus(t)
+	TestScheduleRepo_Claim(t)
+}
+
+func TestScheduleRepo_Create(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := createSchedule()
+	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
+	schedule, err := testScheduleRepo.GetByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
+	asst.Equal(defaultScheduleName, schedule.GetScheduleName(), "test Create() failed")
+	asst.Equal(ScheduleStatusActive, schedule.GetStatus(), "test Create() failed")
+	asst.Equal(defaultScheduleWindow, schedule.GetWindow(), "test Create() failed")
+	// delete
+	err = testScheduleRepo.Delete(id)
+	asst.Nil(err, common.CombineMessageWithError("test Create() failed", err))
+}
+
+func TestScheduleRepo_GetAll(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := createSchedule()
+	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
+	schedules, err := testScheduleRepo.GetAll()
+	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
+	asst.NotZero(len(schedules), "test GetAll() failed")
+	// delete
+	err = testScheduleRepo.Delete(id)
+	asst.Nil(err, common.CombineMessageWithError("test GetAll() failed", err))
+}
+
+func TestScheduleRepo_GetDue(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := createSchedule()
+	asst.Nil(err, common.CombineMessageWithError("test GetDue() failed", err))
+	schedules, err := testScheduleRepo.GetDue(time.Now())
+	asst.Nil(err, common.CombineMessageWithError("test GetDue() failed", err))
+	for _, schedule := range schedules {
+		asst.NotEqual(id, schedule.Identity(), "test GetDue() failed")
+	}
+	schedules, err = testScheduleRepo.GetDue(time.Now().Add(2 * constant.Day))
+	asst.Nil(err, common.CombineMessageWithError("test GetDue() failed", err))
+	var found bool
+	for _, schedule := range schedules {
+		if schedule.Identity() == id {
+			found = true
+		}
+	}
+	asst.True(found, "test GetDue() failed")
+	// delete
+	err = testScheduleRepo.Delete(id)
+	asst.Nil(err, common.CombineMessageWithError("test GetDue() failed", err))
+}
+
+func TestScheduleRepo_UpdateStatus(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := createSchedule()
+	asst.Nil(err, common.CombineMessageWithError("test UpdateStatus() failed", err))
+	schedule, err := testScheduleRepo.GetByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test UpdateStatus() failed", err))
+	err = testScheduleRepo.UpdateStatus(id, ScheduleStatusPaused, schedule.GetNextRunTime())
+	asst.Nil(err, common.CombineMessageWithError("test UpdateStatus() failed", err))
+	schedule, err = testScheduleRepo.GetByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test UpdateStatus() failed", err))
+	asst.Equal(ScheduleStatusPaused, schedule.GetStatus(), "test UpdateStatus() failed")
+	// delete
+	err = testScheduleRepo.Delete(id)
+	asst.Nil(err, common.CombineMessageWithError("test UpdateStatus() failed", err))
+}
+
+func TestScheduleRepo_Claim(t *testing.T) {
+	insertDASCassette(t)
+	asst := assert.New(t)
+
+	id, err := createSchedule()
+	asst.Nil(err, common.CombineMessageWithError("test Claim() failed", err))
+	schedule, err := testScheduleRepo.GetByID(id)
+	asst.Nil(err, common.CombineMessageWithError("test Claim() failed", err))
+	now := time.Now()
+	nextRunTime := schedule.GetNextRunTime().Add(constant.Day)
+	claimed, err := testScheduleRepo.Claim(id, schedule.GetNextRunTime(), now, nextRunTime)
+	asst.Nil(err, common.CombineMessageWithError("test Claim() failed", err))
+	asst.True(claimed, "test Claim() failed")
+	// the same run could not be claimed twice
+	claimed, err = testScheduleRepo.Claim(id, schedule.GetNextRunTime(), now, nextRunTime)
+	asst.Nil(err, common.CombineMessageWithError("test Claim() failed", err))
+	asst.False(claimed, "test Claim() failed")
+	// delete
+	err = testScheduleRepo.Delete(id)
+	asst.Nil(err, common.CombineMessageWithError("test Claim() failed", err))
+}
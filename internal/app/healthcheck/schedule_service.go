@@ -0,0 +1,110 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+)
+
+const scheduleSchedulesStruct = "Schedules"
+
+var _ healthcheck.ScheduleService = (*ScheduleService)(nil)
+
+// ScheduleService of the healthcheck schedules
+type ScheduleService struct {
+	healthcheck.ScheduleRepo
+	Schedules []healthcheck.Schedule `json:"schedules"`
+}
+
+// NewScheduleService returns a new *ScheduleService
+func NewScheduleService(repo healthcheck.ScheduleRepo) *ScheduleService {
+	return &ScheduleService{repo, []healthcheck.Schedule{}}
+}
+
+// NewScheduleServiceWithDefault returns a new *ScheduleService with default repository
+func NewScheduleServiceWithDefault() *ScheduleService {
+	return NewScheduleService(NewScheduleRepoWithGlobal())
+}
+
+// GetSchedules returns the schedules of the service
+func (ss *ScheduleService) GetSchedules() []healthcheck.Schedule {
+	return ss.Schedules
+}
+
+// GetAll gets all the schedules from the middleware
+func (ss *ScheduleService) GetAll() error {
+	var err error
+
+	ss.Schedules, err = ss.ScheduleRepo.GetAll()
+
+	return err
+}
+
+// GetByID gets the schedule by the identity from the middleware
+func (ss *ScheduleService) GetByID(id int) error {
+	schedule, err := ss.ScheduleRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	ss.Schedules = append(ss.Schedules, schedule)
+
+	return nil
+}
+
+// Create creates a schedule in the middleware, the schedule is active once it is created
+func (ss *ScheduleService) Create(scheduleName string, targetType, targetID int, cronExpr string, window, step time.Duration) error {
+	schedule, err := NewSchedule(scheduleName, targetType, targetID, cronExpr, window, step)
+	if err != nil {
+		return err
+	}
+	entity, err := ss.ScheduleRepo.Create(schedule)
+	if err != nil {
+		return err
+	}
+
+	ss.Schedules = append(ss.Schedules, entity)
+
+	return nil
+}
+
+// Pause pauses the schedule, the scheduler will not trigger it until it is resumed
+func (ss *ScheduleService) Pause(id int) error {
+	schedule, err := ss.ScheduleRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	return ss.ScheduleRepo.UpdateStatus(id, ScheduleStatusPaused, schedule.GetNextRunTime())
+}
+
+// Resume resumes the schedule, the next run time will be recalculated from now,
+// so the runs missed during the pause will not be triggered
+func (ss *ScheduleService) Resume(id int) error {
+	schedule, err := ss.ScheduleRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	nextRunTime, err := getNextRunTime(schedule.GetCronExpr(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	return ss.ScheduleRepo.UpdateStatus(id, ScheduleStatusActive, nextRunTime)
+}
+
+// Delete deletes the schedule
+func (ss *ScheduleService) Delete(id int) error {
+	return ss.ScheduleRepo.Delete(id)
+}
+
+// Marshal marshals ScheduleService.Schedules to json bytes
+func (ss *ScheduleService) Marshal() ([]byte, error) {
+	return ss.MarshalWithFields(scheduleSchedulesStruct)
+}
+
+// MarshalWithFields marshals only specified fields of the ScheduleService to json bytes
+func (ss *ScheduleService) MarshalWithFields(fields ...string) ([]byte, error) {
+	return common.MarshalStructWithFields(ss, fields...)
+}
@@ -0,0 +1,272 @@
+package healthcheck
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/log"
+)
+
+const (
+	defaultSchedulerInterval = 10 * time.Second
+	// dbClusterTypeSingle means the database is deployed on a single mysql cluster, the cluster id of the database is the mysql cluster id
+	dbClusterTypeSingle = 1
+)
+
+// Scheduler triggers the healthcheck schedules periodically,
+// the schedules and their next run time are stored in the middleware, so they survive the restart of the das server,
+// a run missed during the downtime will be triggered once after the das server starts
+type Scheduler struct {
+	healthcheck.ScheduleRepo
+	interval time.Duration
+	stopOnce sync.Once
+	stopChan chan struct{}
+}
+
+// NewScheduler returns a new *Scheduler
+func NewScheduler(repo healthcheck.ScheduleRepo, interval time.Duration) *Scheduler {
+	return &Scheduler{
+		ScheduleRepo: repo,
+		interval:     interval,
+		stopChan:     make(chan struct{}),
+	}
+}
+
+// NewSchedulerWithDefault returns a new *Scheduler with default repository and interval
+func NewSchedulerWithDefault() *Scheduler {
+	return NewScheduler(NewScheduleRepoWithGlobal(), defaultSchedulerInterval)
+}
+
+// Start starts the scheduler asynchronously
+func (s *Scheduler) Start() {
+	go s.loop()
+}
+
+// Stop stops the scheduler, the healthchecks which have been triggered will not be affected
+func (s *Scheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+	})
+}
+
+// loop triggers the due schedules every interval until the scheduler is stopped
+func (s *Scheduler) loop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.triggerDue(time.Now())
+
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// triggerDue triggers the schedules whose next run time is not later than given time,
+// a schedule will be triggered only if this scheduler claims it successfully
+func (s *Scheduler) triggerDue(now time.Time) {
+	schedules, err := s.ScheduleRepo.GetDue(now)
+	if err != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckScheduleLoad, err.Error()).Error())
+		return
+	}
+
+	for _, schedule := range schedules {
+		nextRunTime, err := getNextRunTime(schedule.GetCronExpr(), now)
+		if err != nil {
+			log.Error(message.NewMessage(msghc.ErrHealthcheckScheduleRun, schedule.Identity(), err.Error()).Error())
+			continue
+		}
+		claimed, err := s.ScheduleRepo.Claim(schedule.Identity(), schedule.GetNextRunTime(), now, nextRunTime)
+		if err != nil {
+			log.Error(message.NewMessage(msghc.ErrHealthcheckScheduleRun, schedule.Identity(), err.Error()).Error())
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		go s.run(schedule, now)
+	}
+}
+
+// run performs the healthcheck of the schedule, the time range of the check ends at given time and lasts for the window,
+// the result of the triggering will be saved as the message of the schedule
+func (s *Scheduler) run(schedule healthcheck.Schedule, endTime time.Time) {
+	startTime := endTime.Add(-schedule.GetWindow())
+	log.Info(message.NewMessage(msghc.InfoHealthcheckScheduleRun, schedule.Identity(), schedule.GetTargetType(), schedule.GetTargetID(),
+		startTime.Format(constant.TimeLayoutSecond), endTime.Format(constant.TimeLayoutSecond)).Error())
+
+	var msg string
+	err := s.check(schedule, startTime, endTime)
+	if err != nil {
+		msg = message.NewMessage(msghc.ErrHealthcheckScheduleRun, schedule.Identity(), err.Error()).Error()
+		log.Error(msg)
+	} else {
+		msg = message.NewMessage(msghc.InfoHealthcheckScheduleRun, schedule.Identity(), schedule.GetTargetType(), schedule.GetTargetID(),
+			startTime.Format(constant.TimeLayoutSecond), endTime.Format(constant.TimeLayoutSecond)).Error()
+	}
+
+	err = s.ScheduleRepo.UpdateMessage(schedule.Identity(), msg)
+	if err != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckScheduleRun, schedule.Identity(), err.Error()).Error())
+	}
+}
+
+// check performs the healthcheck on the target of the schedule
+func (s *Scheduler) check(schedule healthcheck.Schedule, startTime, endTime time.Time) error {
+	if schedule.GetTargetType() == ScheduleTargetTypeMySQLServer {
+		return s.checkMySQLServer(schedule, startTime, endTime)
+	}
+
+	mysqlClusterIDs, err := s.getMySQLClusterIDs(schedule)
+	if err != nil {
+		return err
+	}
+	if len(mysqlClusterIDs) == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrHealthcheckScheduleTargetEmpty, schedule.GetTargetType(), schedule.GetTargetID())
+	}
+
+	var errMessages []string
+	for _, mysqlClusterID := range mysqlClusterIDs {
+		err = s.checkMySQLCluster(schedule, mysqlClusterID, startTime, endTime)
+		if err != nil {
+			errMessages = append(errMessages, message.NewMessage(msghc.ErrHealthcheckCheckByMySQLClusterID, mysqlClusterID, err.Error()).Error())
+		}
+	}
+	if len(errMessages) > constant.ZeroInt {
+		return errors.New(strings.Join(errMessages, constant.CRLFString))
+	}
+
+	return nil
+}
+
+// checkMySQLServer performs the healthcheck on the mysql server of the schedule,
+// it will be skipped if the previous healthcheck of the mysql server is still running
+func (s *Scheduler) checkMySQLServer(schedule healthcheck.Schedule, startTime, endTime time.Time) error {
+	hcs := NewServiceWithDefault()
+	isRunning, err := hcs.GetDASRepo().IsRunning(schedule.GetTargetID())
+	if err != nil {
+		return err
+	}
+	if isRunning {
+		log.Info(message.NewMessage(msghc.InfoHealthcheckScheduleSkipped, schedule.Identity(), schedule.GetTargetID()).Error())
+		return nil
+	}
+
+	return hcs.Check(schedule.GetTargetID(), startTime, endTime, schedule.GetStep())
+}
+
+// checkMySQLCluster performs the healthcheck on the mysql cluster of the schedule,
+// it will be skipped if the previous healthcheck of the mysql cluster or any of its mysql servers is still running
+func (s *Scheduler) checkMySQLCluster(schedule healthcheck.Schedule, mysqlClusterID int, startTime, endTime time.Time) error {
+	hcs := NewServiceWithDefault()
+	isRunning, err := hcs.GetDASRepo().IsClusterRunning(mysqlClusterID)
+	if err != nil {
+		return err
+	}
+	if !isRunning {
+		mss := metadata.NewMySQLServerServiceWithDefault()
+		err = mss.GetByClusterID(mysqlClusterID)
+		if err != nil {
+			return err
+		}
+		mysqlServerIDs := make([]int, len(mss.GetMySQLServers()))
+		for i, mysqlServer := range mss.GetMySQLServers() {
+			mysqlServerIDs[i] = mysqlServer.Identity()
+		}
+		isRunning, err = isAnyMySQLServerRunning(hcs.GetDASRepo(), mysqlServerIDs)
+		if err != nil {
+			return err
+		}
+	}
+	if isRunning {
+		log.Info(message.NewMessage(msghc.InfoHealthcheckScheduleClusterSkipped, schedule.Identity(), mysqlClusterID).Error())
+		return nil
+	}
+
+	return hcs.CheckByMySQLClusterID(mysqlClusterID, startTime, endTime, schedule.GetStep())
+}
+
+// isAnyMySQLServerRunning returns if the healthcheck of any of the given mysql servers is still running
+func isAnyMySQLServerRunning(dasRepo healthcheck.DASRepo, mysqlServerIDs []int) (bool, error) {
+	for _, mysqlServerID := range mysqlServerIDs {
+		isRunning, err := dasRepo.IsRunning(mysqlServerID)
+		if err != nil {
+			return false, err
+		}
+		if isRunning {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getMySQLClusterIDs returns the mysql cluster ids of the target of the schedule
+func (s *Scheduler) getMySQLClusterIDs(schedule healthcheck.Schedule) ([]int, error) {
+	switch schedule.GetTargetType() {
+	case ScheduleTargetTypeMySQLCluster:
+		return []int{schedule.GetTargetID()}, nil
+	case ScheduleTargetTypeEnv:
+		return s.getMySQLClusterIDsByEnv(schedule.GetTargetID())
+	case ScheduleTargetTypeApp:
+		return s.getMySQLClusterIDsByApp(schedule.GetTargetID())
+	default:
+		return nil, message.NewMessage(msghc.ErrScheduleTargetTypeInvalid, schedule.GetTargetType())
+	}
+}
+
+// getMySQLClusterIDsByEnv returns the ids of the mysql clusters which belong to the env
+func (s *Scheduler) getMySQLClusterIDsByEnv(envID int) ([]int, error) {
+	mcs := metadata.NewMySQLClusterServiceWithDefault()
+	err := mcs.GetByEnv(envID)
+	if err != nil {
+		return nil, err
+	}
+
+	mysqlClusterIDs := make([]int, len(mcs.GetMySQLClusters()))
+	for i, mysqlCluster := range mcs.GetMySQLClusters() {
+		mysqlClusterIDs[i] = mysqlCluster.Identity()
+	}
+
+	return mysqlClusterIDs, nil
+}
+
+// getMySQLClusterIDsByApp returns the ids of the mysql clusters which the databases of the app are deployed on,
+// the databases which are deployed on multiple clusters are not supported and will be ignored
+func (s *Scheduler) getMySQLClusterIDsByApp(appID int) ([]int, error) {
+	as := metadata.NewAppServiceWithDefault()
+	err := as.GetDBIDList(appID)
+	if err != nil {
+		return nil, err
+	}
+
+	var mysqlClusterIDs []int
+	exists := make(map[int]bool)
+	for _, dbID := range as.DBIDList {
+		ds := metadata.NewDBServiceWithDefault()
+		err = ds.GetByID(dbID)
+		if err != nil {
+			return nil, err
+		}
+		db := ds.GetDBs()[constant.ZeroInt]
+		if db.GetClusterType() != dbClusterTypeSingle || exists[db.GetClusterID()] {
+			continue
+		}
+		exists[db.GetClusterID()] = true
+		mysqlClusterIDs = append(mysqlClusterIDs, db.GetClusterID())
+	}
+
+	return mysqlClusterIDs, nil
+}
@@ -0,0 +1,35 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRunningDASRepo struct {
+	healthcheck.DASRepo
+	runningMySQLServerIDs map[int]bool
+}
+
+func (trdr *testRunningDASRepo) IsRunning(mysqlServerID int) (bool, error) {
+	return trdr.runningMySQLServerIDs[mysqlServerID], nil
+}
+
+func TestSchedulerAll(t *testing.T) {
+	TestScheduler_isAnyMySQLServerRunning(t)
+}
+
+func TestScheduler_isAnyMySQLServerRunning(t *testing.T) {
+	asst := assert.New(t)
+
+	dasRepo := &testRunningDASRepo{runningMySQLServerIDs: map[int]bool{2: true}}
+	isRunning, err := isAnyMySQLServerRunning(dasRepo, []int{1, 3})
+	asst.Nil(err, common.CombineMessageWithError("test isAnyMySQLServerRunning() failed", err))
+	asst.False(isRunning, "test isAnyMySQLServerRunning() failed")
+	// the cluster is skipped if any of its members is still running
+	isRunning, err = isAnyMySQLServerRunning(dasRepo, []int{1, 2, 3})
+	asst.Nil(err, common.CombineMessageWithError("test isAnyMySQLServerRunning() failed", err))
+	asst.True(isRunning, "test isAnyMySQLServerRunning() failed")
+}
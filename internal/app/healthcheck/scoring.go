@@ -0,0 +1,214 @@
+package healthcheck
+
+import (
+	"sort"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	// ScoringStrategyMean deducts the score with the mean of the samples which are higher than each watermark
+	ScoringStrategyMean = "mean"
+	// ScoringStrategyP95 deducts the score with the 95th percentile of the samples
+	ScoringStrategyP95 = "p95"
+	// ScoringStrategyP99 deducts the score with the 99th percentile of the samples
+	ScoringStrategyP99 = "p99"
+	// ScoringStrategyTimeAboveWatermark deducts the score with the percentage of the samples which are higher than each watermark,
+	// the unit is the percentage of the samples
+	ScoringStrategyTimeAboveWatermark = "time_above_watermark"
+	// ScoringStrategyLongestBreach deducts the score with the longest run of the continuous samples which are higher than each watermark,
+	// the unit is the number of the samples, so it depends on the step of the operation
+	ScoringStrategyLongestBreach = "longest_breach"
+
+	defaultScoringStrategy = ScoringStrategyMean
+)
+
+// scoringStrategy returns the high score deduction and the medium score deduction of the series,
+// the score deductions are not limited by the max score deductions
+type scoringStrategy func(series [][]float64, config healthcheck.ItemConfig) (float64, float64)
+
+// scoringStrategies are the supported scoring strategies, the key is the strategy name
+var scoringStrategies = map[string]scoringStrategy{
+	ScoringStrategyMean:               scoreByMean,
+	ScoringStrategyP95:                newPercentileScoringStrategy(95),
+	ScoringStrategyP99:                newPercentileScoringStrategy(99),
+	ScoringStrategyTimeAboveWatermark: scoreByTimeAboveWatermark,
+	ScoringStrategyLongestBreach:      scoreByLongestBreach,
+}
+
+// isValidScoringStrategy returns if the scoring strategy is supported, empty strategy means the default strategy
+func isValidScoringStrategy(strategy string) bool {
+	if strategy == constant.EmptyString {
+		return true
+	}
+	_, ok := scoringStrategies[strategy]
+
+	return ok
+}
+
+// getScoringStrategy returns the scoring strategy with given name, it returns the default strategy if the name is empty or not supported
+func getScoringStrategy(strategy string) scoringStrategy {
+	s, ok := scoringStrategies[strategy]
+	if !ok {
+		return scoringStrategies[defaultScoringStrategy]
+	}
+
+	return s
+}
+
+// getScoreDeductions returns the score deductions of the values with the scoring strategy of the config,
+// the values are considered as a single series, the score deductions are limited by the max score deductions
+func getScoreDeductions(values []float64, config healthcheck.ItemConfig) (float64, float64) {
+	return getSeriesScoreDeductions([][]float64{values}, config)
+}
+
+// getSeriesScoreDeductions returns the score deductions of the series with the scoring strategy of the config,
+// the score deductions are limited by the max score deductions
+func getSeriesScoreDeductions(series [][]float64, config healthcheck.ItemConfig) (float64, float64) {
+	if len(flattenSeries(series)) == constant.ZeroInt {
+		return constant.ZeroInt, constant.ZeroInt
+	}
+
+	scoreDeductionHigh, scoreDeductionMedium := getScoringStrategy(config.GetScoringStrategy())(series, config)
+	if scoreDeductionHigh > config.GetMaxScoreDeductionHigh() {
+		scoreDeductionHigh = config.GetMaxScoreDeductionHigh()
+	}
+	if scoreDeductionMedium > config.GetMaxScoreDeductionMedium() {
+		scoreDeductionMedium = config.GetMaxScoreDeductionMedium()
+	}
+
+	return scoreDeductionHigh, scoreDeductionMedium
+}
+
+// getPrometheusSeries groups the values of the prometheus datas by the series which they belong to, the order of the series is kept
+func getPrometheusSeries(datas []healthcheck.PrometheusData) [][]float64 {
+	var series [][]float64
+	indexes := make(map[string]int)
+	for _, data := range datas {
+		i, exists := indexes[data.GetSeries()]
+		if !exists {
+			i = len(series)
+			indexes[data.GetSeries()] = i
+			series = append(series, nil)
+		}
+		series[i] = append(series[i], data.GetValue())
+	}
+
+	return series
+}
+
+// flattenSeries returns the values of all the series
+func flattenSeries(series [][]float64) []float64 {
+	var values []float64
+	for _, s := range series {
+		values = append(values, s...)
+	}
+
+	return values
+}
+
+// scoreByMean deducts the score with the mean of the samples which are higher than the high watermark,
+// and the mean of the samples which are between the low watermark and the high watermark,
+// no score will be deducted if there is no sample in the range
+func scoreByMean(series [][]float64, config healthcheck.ItemConfig) (float64, float64) {
+	var (
+		highSum     float64
+		highCount   int
+		mediumSum   float64
+		mediumCount int
+	)
+	for _, value := range flattenSeries(series) {
+		switch {
+		case value >= config.GetHighWatermark():
+			highSum += value
+			highCount++
+		case value >= config.GetLowWatermark():
+			mediumSum += value
+			mediumCount++
+		}
+	}
+
+	var scoreDeductionHigh, scoreDeductionMedium float64
+	if highCount > constant.ZeroInt {
+		scoreDeductionHigh = (highSum/float64(highCount) - config.GetHighWatermark()) / config.GetUnit() * config.GetScoreDeductionPerUnitHigh()
+	}
+	if mediumCount > constant.ZeroInt {
+		scoreDeductionMedium = (mediumSum/float64(mediumCount) - config.GetLowWatermark()) / config.GetUnit() * config.GetScoreDeductionPerUnitMedium()
+	}
+
+	return scoreDeductionHigh, scoreDeductionMedium
+}
+
+// newPercentileScoringStrategy returns a scoring strategy which deducts the score with the percentile of the samples,
+// the percentile which is higher than the high watermark deducts the high score,
+// and the percentile which is between the low watermark and the high watermark deducts the medium score
+func newPercentileScoringStrategy(percent float64) scoringStrategy {
+	return func(series [][]float64, config healthcheck.ItemConfig) (float64, float64) {
+		sortedValues := flattenSeries(series)
+		sort.Float64s(sortedValues)
+		percentile := getPercentile(sortedValues, percent)
+
+		switch {
+		case percentile >= config.GetHighWatermark():
+			return (percentile - config.GetHighWatermark()) / config.GetUnit() * config.GetScoreDeductionPerUnitHigh(), constant.ZeroInt
+		case percentile >= config.GetLowWatermark():
+			return constant.ZeroInt, (percentile - config.GetLowWatermark()) / config.GetUnit() * config.GetScoreDeductionPerUnitMedium()
+		default:
+			return constant.ZeroInt, constant.ZeroInt
+		}
+	}
+}
+
+// scoreByTimeAboveWatermark deducts the score with the percentage of the samples which are higher than the high watermark,
+// and the percentage of the samples which are between the low watermark and the high watermark
+func scoreByTimeAboveWatermark(series [][]float64, config healthcheck.ItemConfig) (float64, float64) {
+	values := flattenSeries(series)
+	var highCount, mediumCount int
+	for _, value := range values {
+		switch {
+		case value >= config.GetHighWatermark():
+			highCount++
+		case value >= config.GetLowWatermark():
+			mediumCount++
+		}
+	}
+
+	highPercent := float64(highCount) / float64(len(values)) * defaultHundred
+	mediumPercent := float64(mediumCount) / float64(len(values)) * defaultHundred
+
+	return highPercent / config.GetUnit() * config.GetScoreDeductionPerUnitHigh(),
+		mediumPercent / config.GetUnit() * config.GetScoreDeductionPerUnitMedium()
+}
+
+// scoreByLongestBreach deducts the score with the longest run of the continuous samples which are higher than the high watermark,
+// and the longest run of the continuous samples which are between the low watermark and the high watermark,
+// the runs are computed within each series, so that a run never spans the boundary of the series
+func scoreByLongestBreach(series [][]float64, config healthcheck.ItemConfig) (float64, float64) {
+	var longestHighRun, longestMediumRun int
+	for _, values := range series {
+		var highRun, mediumRun int
+		for _, value := range values {
+			switch {
+			case value >= config.GetHighWatermark():
+				highRun++
+				mediumRun = constant.ZeroInt
+			case value >= config.GetLowWatermark():
+				mediumRun++
+				highRun = constant.ZeroInt
+			default:
+				highRun = constant.ZeroInt
+				mediumRun = constant.ZeroInt
+			}
+			if highRun > longestHighRun {
+				longestHighRun = highRun
+			}
+			if mediumRun > longestMediumRun {
+				longestMediumRun = mediumRun
+			}
+		}
+	}
+
+	return float64(longestHighRun) / config.GetUnit() * config.GetScoreDeductionPerUnitHigh(),
+		float64(longestMediumRun) / config.GetUnit() * config.GetScoreDeductionPerUnitMedium()
+}
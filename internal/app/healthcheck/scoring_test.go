@@ -0,0 +1,243 @@
+package healthcheck
+
+import (
+	"testing"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestScoringConfig(strategy string) *DefaultItemConfig {
+	return &DefaultItemConfig{
+		ItemName:                    defaultCPUUsageItemName,
+		ItemWeight:                  5,
+		LowWatermark:                60,
+		HighWatermark:               80,
+		Unit:                        1,
+		ScoreDeductionPerUnitHigh:   1,
+		MaxScoreDeductionHigh:       50,
+		ScoreDeductionPerUnitMedium: 1,
+		MaxScoreDeductionMedium:     30,
+		ScoringStrategy:             strategy,
+	}
+}
+
+// newTestSeries returns a series of which the values are from 0 to num - 1
+func newTestSeries(num int) []float64 {
+	values := make([]float64, num)
+	for i := constant.ZeroInt; i < num; i++ {
+		values[i] = float64(i)
+	}
+
+	return values
+}
+
+// newTestRepeatedSeries returns a series which contains num values of given value
+func newTestRepeatedSeries(value float64, num int) []float64 {
+	values := make([]float64, num)
+	for i := range values {
+		values[i] = value
+	}
+
+	return values
+}
+
+func TestScoringAll(t *testing.T) {
+	TestScoring_isValidScoringStrategy(t)
+	TestScoring_getScoreDeductions(t)
+	TestScoring_scoreByMean(t)
+	TestScoring_scoreByPercentile(t)
+	TestScoring_scoreByTimeAboveWatermark(t)
+	TestScoring_scoreByLongestBreach(t)
+	TestScoring_PrometheusItemScore(t)
+	TestScoring_PrometheusItemScoreWithSeries(t)
+	TestScoring_ValidateUnit(t)
+}
+
+func TestScoring_isValidScoringStrategy(t *testing.T) {
+	asst := assert.New(t)
+
+	for strategy := range scoringStrategies {
+		asst.True(isValidScoringStrategy(strategy), "test isValidScoringStrategy() failed, strategy: %s", strategy)
+	}
+	asst.True(isValidScoringStrategy(constant.EmptyString), "test isValidScoringStrategy() failed")
+	asst.False(isValidScoringStrategy("p50"), "test isValidScoringStrategy() failed")
+}
+
+func TestScoring_getScoreDeductions(t *testing.T) {
+	asst := assert.New(t)
+
+	// empty series
+	high, medium := getScoreDeductions(nil, newTestScoringConfig(ScoringStrategyMean))
+	asst.Equal(0.0, high, "test getScoreDeductions() failed")
+	asst.Equal(0.0, medium, "test getScoreDeductions() failed")
+	// empty and unknown strategy fall back to the mean strategy
+	values := []float64{70, 90, 100, 10}
+	for _, strategy := range []string{constant.EmptyString, "unknown"} {
+		high, medium = getScoreDeductions(values, newTestScoringConfig(strategy))
+		asst.Equal(15.0, high, "test getScoreDeductions() failed, strategy: %s", strategy)
+		asst.Equal(10.0, medium, "test getScoreDeductions() failed, strategy: %s", strategy)
+	}
+	// the score deductions are limited by the max score deductions
+	high, medium = getScoreDeductions(newTestRepeatedSeries(200, 10), newTestScoringConfig(ScoringStrategyMean))
+	asst.Equal(50.0, high, "test getScoreDeductions() failed")
+	asst.Equal(0.0, medium, "test getScoreDeductions() failed")
+}
+
+func TestScoring_scoreByMean(t *testing.T) {
+	asst := assert.New(t)
+
+	config := newTestScoringConfig(ScoringStrategyMean)
+	cases := []struct {
+		name           string
+		values         []float64
+		expectedHigh   float64
+		expectedMedium float64
+	}{
+		{"no breach", []float64{10, 20, 30}, 0, 0},
+		{"only medium", []float64{10, 65, 75}, 0, 10},
+		{"high and medium", []float64{70, 90, 100, 10}, 15, 10},
+		{"single spike", append(newTestRepeatedSeries(10, 9), 100), 20, 0},
+		{"sustained breach", newTestRepeatedSeries(100, 10), 20, 0},
+	}
+	for _, c := range cases {
+		high, medium := scoreByMean([][]float64{c.values}, config)
+		asst.Equal(c.expectedHigh, high, "test scoreByMean() failed, case: %s", c.name)
+		asst.Equal(c.expectedMedium, medium, "test scoreByMean() failed, case: %s", c.name)
+	}
+}
+
+func TestScoring_scoreByPercentile(t *testing.T) {
+	asst := assert.New(t)
+
+	config := newTestScoringConfig(ScoringStrategyP95)
+	cases := []struct {
+		name           string
+		strategy       string
+		values         []float64
+		expectedHigh   float64
+		expectedMedium float64
+	}{
+		{"p95 linear", ScoringStrategyP95, newTestSeries(100), 14, 0},
+		{"p99 linear", ScoringStrategyP99, newTestSeries(100), 18, 0},
+		{"p95 single spike", ScoringStrategyP95, append(newTestRepeatedSeries(10, 19), 100), 0, 0},
+		{"p99 single spike", ScoringStrategyP99, append(newTestRepeatedSeries(10, 19), 100), 20, 0},
+		{"p95 medium", ScoringStrategyP95, newTestRepeatedSeries(70, 20), 0, 10},
+		{"p95 no breach", ScoringStrategyP95, newTestRepeatedSeries(10, 20), 0, 0},
+	}
+	for _, c := range cases {
+		high, medium := scoringStrategies[c.strategy]([][]float64{c.values}, config)
+		asst.Equal(c.expectedHigh, high, "test percentile scoring strategy failed, case: %s", c.name)
+		asst.Equal(c.expectedMedium, medium, "test percentile scoring strategy failed, case: %s", c.name)
+	}
+}
+
+func TestScoring_scoreByTimeAboveWatermark(t *testing.T) {
+	asst := assert.New(t)
+
+	config := newTestScoringConfig(ScoringStrategyTimeAboveWatermark)
+	cases := []struct {
+		name           string
+		values         []float64
+		expectedHigh   float64
+		expectedMedium float64
+	}{
+		{"no breach", newTestRepeatedSeries(10, 10), 0, 0},
+		{"high and medium", []float64{10, 70, 90, 100, 10, 10, 70, 90, 10, 10}, 30, 20},
+		{"single spike", append(newTestRepeatedSeries(10, 9), 100), 10, 0},
+		{"sustained breach", newTestRepeatedSeries(100, 10), 100, 0},
+	}
+	for _, c := range cases {
+		high, medium := scoreByTimeAboveWatermark([][]float64{c.values}, config)
+		asst.Equal(c.expectedHigh, high, "test scoreByTimeAboveWatermark() failed, case: %s", c.name)
+		asst.Equal(c.expectedMedium, medium, "test scoreByTimeAboveWatermark() failed, case: %s", c.name)
+	}
+}
+
+func TestScoring_scoreByLongestBreach(t *testing.T) {
+	asst := assert.New(t)
+
+	config := newTestScoringConfig(ScoringStrategyLongestBreach)
+	cases := []struct {
+		name           string
+		values         []float64
+		expectedHigh   float64
+		expectedMedium float64
+	}{
+		{"no breach", newTestRepeatedSeries(10, 10), 0, 0},
+		{"high and medium", []float64{90, 100, 10, 70, 70, 70, 90, 90, 90, 90}, 4, 3},
+		{"interrupted breach", []float64{90, 10, 90, 10, 90, 10, 90}, 1, 0},
+		{"single spike", append(newTestRepeatedSeries(10, 9), 100), 1, 0},
+		{"sustained breach", newTestRepeatedSeries(100, 10), 10, 0},
+	}
+	for _, c := range cases {
+		high, medium := scoreByLongestBreach([][]float64{c.values}, config)
+		asst.Equal(c.expectedHigh, high, "test scoreByLongestBreach() failed, case: %s", c.name)
+		asst.Equal(c.expectedMedium, medium, "test scoreByLongestBreach() failed, case: %s", c.name)
+	}
+	// the run never spans the boundary of the series
+	high, medium := scoreByLongestBreach([][]float64{{10, 90, 90}, {90, 90, 90, 10}}, config)
+	asst.Equal(3.0, high, "test scoreByLongestBreach() failed")
+	asst.Equal(0.0, medium, "test scoreByLongestBreach() failed")
+}
+
+func TestScoring_PrometheusItemScore(t *testing.T) {
+	asst := assert.New(t)
+
+	cases := []struct {
+		name          string
+		strategy      string
+		values        []float64
+		expected      int
+		expectedHighs int
+	}{
+		{"mean no breach", ScoringStrategyMean, []float64{10, 20, 30}, 100, 0},
+		{"mean single spike", ScoringStrategyMean, append(newTestRepeatedSeries(10, 9), 100), 80, 1},
+		{"mean sustained breach", ScoringStrategyMean, newTestRepeatedSeries(100, 10), 80, 10},
+		{"time above watermark single spike", ScoringStrategyTimeAboveWatermark, append(newTestRepeatedSeries(10, 9), 100), 90, 1},
+		{"time above watermark sustained breach", ScoringStrategyTimeAboveWatermark, newTestRepeatedSeries(100, 10), 50, 10},
+		{"empty series", ScoringStrategyP99, nil, 100, 0},
+	}
+	for _, c := range cases {
+		pi := &PrometheusItem{datas: newTestPrometheusDatas(c.values...)}
+		score, err := pi.Score(newTestScoringConfig(c.strategy))
+		asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+		asst.Equal(c.expected, score, "test Score() failed, case: %s", c.name)
+		asst.Equal(c.expectedHighs, len(pi.highDatas), "test Score() failed, case: %s", c.name)
+	}
+}
+
+func TestScoring_PrometheusItemScoreWithSeries(t *testing.T) {
+	asst := assert.New(t)
+
+	// each device breaches for 2 samples, the concatenated samples would breach for 4 samples
+	pi := &PrometheusItem{datas: []healthcheck.PrometheusData{
+		NewPrometheusDataWithSeries(`{device="sda"}`, "1", 10),
+		NewPrometheusDataWithSeries(`{device="sda"}`, "2", 90),
+		NewPrometheusDataWithSeries(`{device="sda"}`, "3", 90),
+		NewPrometheusDataWithSeries(`{device="sdb"}`, "1", 90),
+		NewPrometheusDataWithSeries(`{device="sdb"}`, "2", 90),
+		NewPrometheusDataWithSeries(`{device="sdb"}`, "3", 10),
+	}}
+	asst.Equal([][]float64{{10, 90, 90}, {90, 90, 10}}, getPrometheusSeries(pi.datas), "test getPrometheusSeries() failed")
+	score, err := pi.Score(newTestScoringConfig(ScoringStrategyLongestBreach))
+	asst.Nil(err, common.CombineMessageWithError("test Score() failed", err))
+	asst.Equal(98, score, "test Score() failed")
+}
+
+func TestScoring_ValidateUnit(t *testing.T) {
+	asst := assert.New(t)
+
+	engineConfig := DefaultEngineConfig{
+		defaultDBConfigItemName: &DefaultItemConfig{ItemName: defaultDBConfigItemName, ItemWeight: 50, ScoreDeductionPerUnitHigh: 10, MaxScoreDeductionHigh: 50},
+		defaultCPUUsageItemName: newTestScoringConfig(ScoringStrategyLongestBreach),
+	}
+	engineConfig[defaultCPUUsageItemName].ItemWeight = 50
+	// the item which does not use the watermarks does not need the unit
+	asst.Nil(engineConfig.Validate(), "test Validate() failed")
+	// the item which uses the watermarks divides the values by the unit
+	engineConfig[defaultCPUUsageItemName].Unit = 0
+	asst.NotNil(engineConfig.Validate(), "test Validate() failed")
+}
@@ -1,11 +1,17 @@
 package healthcheck
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"sync"
 	"time"
 
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/internal/app/metadata"
+	depmeta "github.com/romberli/das/internal/dependency/metadata"
 	"github.com/romberli/das/internal/dependency/healthcheck"
 	"github.com/romberli/das/pkg/message"
 	msghc "github.com/romberli/das/pkg/message/healthcheck"
@@ -22,8 +28,10 @@ const (
 	resultStruct                   = "Result"
 	defaultMonitorClickhouseDBName = "pmm"
 	defaultMonitorMySQLDBName      = "pmm"
+	defaultRunningStatus           = 1
 	defaultSuccessStatus           = 2
 	defaultFailedStatus            = 3
+	defaultCanceledStatus          = 4
 )
 
 var _ healthcheck.Service = (*Service)(nil)
@@ -31,9 +39,18 @@ var _ healthcheck.Service = (*Service)(nil)
 // Service of health check
 type Service struct {
 	healthcheck.DASRepo
-	OperationInfo *OperationInfo
-	Engine        healthcheck.Engine
-	Result        healthcheck.Result `json:"result"`
+	OperationInfo         *OperationInfo
+	ClusterOperationID    int
+	MiddlewareOperationID int
+	Engine                healthcheck.Engine
+	Result                healthcheck.Result           `json:"result"`
+	ClusterResult         healthcheck.ClusterResult    `json:"cluster_result"`
+	MiddlewareResult      healthcheck.MiddlewareResult `json:"middleware_result"`
+	ScoreTrend            []*ScorePoint                `json:"score_trend"`
+	ResultDiff            *ResultDiff                  `json:"result_diff"`
+	Operations            []healthcheck.Operation      `json:"operations"`
+	OperationStatus       *OperationStatus             `json:"operation_status"`
+	Report                *Report                      `json:"report"`
 }
 
 // NewService returns a new *Service
@@ -70,6 +87,16 @@ func (s *Service) GetResult() healthcheck.Result {
 	return s.Result
 }
 
+// GetClusterResult returns the cluster healthcheck result
+func (s *Service) GetClusterResult() healthcheck.ClusterResult {
+	return s.ClusterResult
+}
+
+// GetMiddlewareResult returns the middleware healthcheck result
+func (s *Service) GetMiddlewareResult() healthcheck.MiddlewareResult {
+	return s.MiddlewareResult
+}
+
 // GetResultByOperationID gets the result of given operation id
 func (s *Service) GetResultByOperationID(id int) error {
 	var err error
@@ -82,6 +109,121 @@ func (s *Service) GetResultByOperationID(id int) error {
 	return err
 }
 
+// GetScoreTrendByMySQLServerID gets the score trend of the mysql server, which consists of the results checked in given time range
+func (s *Service) GetScoreTrendByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) error {
+	results, err := s.DASRepo.GetResultsByMySQLServerID(mysqlServerID, startTime, endTime)
+	if err != nil {
+		return err
+	}
+
+	s.ScoreTrend = NewScoreTrend(results)
+
+	return nil
+}
+
+// GetResultDiffByOperationIDs compares the result of the target operation with the result of the base operation
+func (s *Service) GetResultDiffByOperationIDs(baseOperationID, targetOperationID int) error {
+	base, err := s.DASRepo.GetResultByOperationID(baseOperationID)
+	if err != nil {
+		return err
+	}
+	target, err := s.DASRepo.GetResultByOperationID(targetOperationID)
+	if err != nil {
+		return err
+	}
+
+	s.ResultDiff, err = NewResultDiff(base, target)
+
+	return err
+}
+
+// GetReportByOperationID gets the result of given operation id and generates the report
+func (s *Service) GetReportByOperationID(operationID int) error {
+	operation, err := s.DASRepo.GetOperationByID(operationID)
+	if err != nil {
+		return err
+	}
+	result, err := s.DASRepo.GetResultByOperationID(operationID)
+	if err != nil {
+		return err
+	}
+
+	s.Report = NewReport(operation, result)
+
+	return nil
+}
+
+// GetOperations gets the operations which were created in given time range,
+// mysql server id and status are optional filters, they will be ignored if they are negative
+func (s *Service) GetOperations(mysqlServerID, status int, startTime, endTime time.Time) error {
+	var err error
+
+	s.Operations, err = s.DASRepo.GetOperations(mysqlServerID, status, startTime, endTime)
+
+	return err
+}
+
+// GetOperationStatus gets the status of the operation,
+// the progress of each check item is only available when the operation is running on this das server
+func (s *Service) GetOperationStatus(operationID int) error {
+	operation, err := s.DASRepo.GetOperationByID(operationID)
+	if err != nil {
+		return err
+	}
+
+	s.OperationStatus = &OperationStatus{Operation: operation}
+	engine, isRunning := runningOperations.getEngine(operationID)
+	if isRunning {
+		s.OperationStatus.ItemStatuses = engine.GetItemStatuses()
+	}
+
+	return nil
+}
+
+// Cancel cancels the operation which is running on this das server,
+// the engine stops as soon as possible and updates the operation status to canceled
+func (s *Service) Cancel(operationID int) error {
+	if runningOperations.cancel(operationID) {
+		return nil
+	}
+
+	operation, err := s.DASRepo.GetOperationByID(operationID)
+	if err != nil {
+		return err
+	}
+
+	return message.NewMessage(msghc.ErrHealthcheckOperationNotRunning, operationID, operation.GetStatus())
+}
+
+// CancelMiddleware cancels the middleware operation which is running on this das server,
+// the engine stops as soon as possible and updates the middleware operation status to canceled
+func (s *Service) CancelMiddleware(middlewareOperationID int) error {
+	if runningMiddlewareOperations.cancel(middlewareOperationID) {
+		return nil
+	}
+
+	return message.NewMessage(msghc.ErrHealthcheckMiddlewareOperationNotRunning, middlewareOperationID)
+}
+
+// Retry re-runs the failed or canceled operation with the same mysql server, check range and step,
+// a new operation will be created, initiating is synchronous, actual running is asynchronous
+func (s *Service) Retry(operationID int) error {
+	operation, err := s.DASRepo.GetOperationByID(operationID)
+	if err != nil {
+		return err
+	}
+	if operation.GetStatus() != defaultFailedStatus && operation.GetStatus() != defaultCanceledStatus {
+		return message.NewMessage(msghc.ErrHealthcheckOperationNotRetryable, operationID, operation.GetStatus())
+	}
+
+	return s.check(operation.GetMySQLServerID(), operation.GetStartTime(), operation.GetEndTime(), operation.GetStep())
+}
+
+// GetOperationID returns the operation id of the latest check, it returns 0 if no operation has been initiated
+func (s *Service) GetOperationID() int {
+	return s.getOperationID()
+}
+
 // Check performs healthcheck on the mysql server with given mysql server id,
 // initiating is synchronous, actual running is asynchronous
 func (s *Service) Check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
@@ -102,51 +244,352 @@ func (s *Service) CheckByHostInfo(hostIP string, portNum int, startTime, endTime
 	return s.check(mysqlServerID, startTime, endTime, step)
 }
 
+// GetClusterResultByOperationID gets the cluster result of given cluster operation id
+func (s *Service) GetClusterResultByOperationID(id int) error {
+	var err error
+
+	s.ClusterResult, err = s.DASRepo.GetClusterResultByOperationID(id)
+
+	return err
+}
+
+// CheckByMySQLClusterID performs healthcheck on all the mysql servers of the mysql cluster with given mysql cluster id,
+// every mysql server runs its own operation under the cluster operation,
+// initiating is synchronous, actual running and summarizing are asynchronous
+func (s *Service) CheckByMySQLClusterID(mysqlClusterID int, startTime, endTime time.Time, step time.Duration) error {
+	// check if operation with the same mysql cluster id is still running
+	isRunning, err := s.DASRepo.IsClusterRunning(mysqlClusterID)
+	if err != nil {
+		return err
+	}
+	if isRunning {
+		return message.NewMessage(msghc.ErrHealthcheckMySQLClusterIsRunning, mysqlClusterID)
+	}
+	// get mysql servers
+	mss := metadata.NewMySQLServerServiceWithDefault()
+	err = mss.GetByClusterID(mysqlClusterID)
+	if err != nil {
+		return err
+	}
+	mysqlServers := mss.GetMySQLServers()
+	if len(mysqlServers) == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrHealthcheckMySQLClusterEmpty, mysqlClusterID)
+	}
+	// init cluster operation
+	clusterOperationID, err := s.DASRepo.InitClusterOperation(mysqlClusterID, startTime, endTime, step)
+	if err != nil {
+		return err
+	}
+	s.ClusterOperationID = clusterOperationID
+	// init member operations, the cluster healthcheck is aborted if any of the mysql servers failed to initiate
+	members := make([]*Service, len(mysqlServers))
+	serverResults := make([]*ServerResult, len(mysqlServers))
+	for i, mysqlServer := range mysqlServers {
+		members[i] = newService(s.GetDASRepo())
+		err = members[i].initWithClusterOperationID(clusterOperationID, mysqlServer.Identity(), startTime, endTime, step)
+		if err != nil {
+			return s.abortCluster(members[:i+1], mysqlServer.Identity(), err)
+		}
+		serverResults[i] = NewServerResult(mysqlServer.Identity(), mysqlServer.GetHostIP(), mysqlServer.GetPortNum(), members[i].getOperationID())
+	}
+	// run asynchronously
+	go s.runCluster(members, serverResults)
+
+	return nil
+}
+
+// abortCluster closes the connections of the engines of the initiated member operations,
+// and updates the status of the member operations and the cluster operation to failed,
+// it returns the error which contains the mysql server id which failed to initiate
+func (s *Service) abortCluster(members []*Service, mysqlServerID int, err error) error {
+	err = message.NewMessage(msghc.ErrHealthcheckMySQLClusterMemberInit, s.ClusterOperationID, mysqlServerID, err.Error())
+	for _, member := range members {
+		member.closeEngine()
+		member.updateFailedStatus(err)
+	}
+	updateErr := s.DASRepo.UpdateClusterOperationStatus(s.ClusterOperationID, defaultFailedStatus, err.Error())
+	if updateErr != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
+	}
+
+	return err
+}
+
+// runCluster runs the engines of the member operations concurrently, and then summarizes the cluster result
+func (s *Service) runCluster(members []*Service, serverResults []*ServerResult) {
+	var wg sync.WaitGroup
+	wg.Add(len(members))
+	for _, member := range members {
+		go func(member *Service) {
+			defer wg.Done()
+			member.run()
+		}(member)
+	}
+	wg.Wait()
+
+	// get the results of the member operations
+	for _, serverResult := range serverResults {
+		result, err := s.DASRepo.GetResultByOperationID(serverResult.OperationID)
+		if err != nil {
+			serverResult.setError(s.getMemberError(serverResult.OperationID, err))
+			continue
+		}
+		serverResult.setResult(result)
+	}
+	// summarize
+	status := defaultSuccessStatus
+	msg := fmt.Sprintf("cluster healthcheck completed successfully. cluster_operation_id: %d", s.ClusterOperationID)
+	clusterResult, err := NewClusterResultWithServerResults(s.ClusterOperationID, serverResults)
+	if err == nil {
+		err = s.DASRepo.SaveClusterResult(clusterResult)
+	}
+	if err != nil {
+		status = defaultFailedStatus
+		msg = err.Error()
+		log.Error(message.NewMessage(msghc.ErrHealthcheckSummarizeCluster, s.ClusterOperationID, err.Error()).Error())
+	} else if err = getAllFailedError(s.ClusterOperationID, serverResults); err != nil {
+		status = defaultFailedStatus
+		msg = err.Error()
+	}
+	// update cluster operation status
+	updateErr := s.DASRepo.UpdateClusterOperationStatus(s.ClusterOperationID, status, msg)
+	if updateErr != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
+	}
+}
+
+// getMemberError returns the error of the member operation which does not have a result,
+// the message of the member operation is preferred, as it tells why the member operation failed
+func (s *Service) getMemberError(operationID int, err error) error {
+	operation, getErr := s.DASRepo.GetOperationByID(operationID)
+	if getErr != nil || operation.GetMessage() == constant.EmptyString {
+		return err
+	}
+
+	return errors.New(operation.GetMessage())
+}
+
+// GetMiddlewareResultByOperationID gets the middleware result of given middleware operation id
+func (s *Service) GetMiddlewareResultByOperationID(id int) error {
+	var err error
+
+	s.MiddlewareResult, err = s.DASRepo.GetMiddlewareResultByOperationID(id)
+
+	return err
+}
+
+// CheckByMiddlewareClusterID performs healthcheck on all the middleware servers of the middleware cluster with given middleware cluster id,
+// the backends are verified against the mysql servers of the mysql clusters which use the middleware cluster,
+// initiating is synchronous, actual running is asynchronous
+func (s *Service) CheckByMiddlewareClusterID(middlewareClusterID int) error {
+	// check if operation with the same middleware cluster id is still running
+	isRunning, err := s.DASRepo.IsMiddlewareClusterRunning(middlewareClusterID)
+	if err != nil {
+		return err
+	}
+	if isRunning {
+		return message.NewMessage(msghc.ErrHealthcheckMiddlewareClusterIsRunning, middlewareClusterID)
+	}
+	// get middleware servers
+	middlewareServerService := metadata.NewMiddlewareServerServiceWithDefault()
+	err = middlewareServerService.GetByClusterID(middlewareClusterID)
+	if err != nil {
+		return err
+	}
+	middlewareServers := middlewareServerService.GetMiddlewareServers()
+	if len(middlewareServers) == constant.ZeroInt {
+		return message.NewMessage(msghc.ErrHealthcheckMiddlewareClusterEmpty, middlewareClusterID)
+	}
+	// get the mysql clusters which use the middleware cluster
+	mysqlClusterService := metadata.NewMySQLClusterServiceWithDefault()
+	err = mysqlClusterService.GetAll()
+	if err != nil {
+		return err
+	}
+	var mysqlClusterIDs []int
+	for _, mysqlCluster := range mysqlClusterService.GetMySQLClusters() {
+		if mysqlCluster.GetMiddlewareClusterID() == middlewareClusterID {
+			mysqlClusterIDs = append(mysqlClusterIDs, mysqlCluster.Identity())
+		}
+	}
+	// get registered mysql servers
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err = mysqlServerService.GetAll()
+	if err != nil {
+		return err
+	}
+	// init middleware operation
+	middlewareOperationID, err := s.DASRepo.InitMiddlewareOperation(middlewareClusterID)
+	if err != nil {
+		return err
+	}
+	s.MiddlewareOperationID = middlewareOperationID
+	// init proxy repositories, the admin interface is not available if the admin port is 0
+	proxyRepos := make([]healthcheck.MiddlewareProxyRepo, len(middlewareServers))
+	for i, middlewareServer := range middlewareServers {
+		addr := fmt.Sprintf("%s:%d", middlewareServer.GetHostIP(), middlewareServer.GetPortNum())
+		adminAddr := constant.EmptyString
+		if s.getMiddlewareAdminPort() != constant.ZeroInt {
+			adminAddr = fmt.Sprintf("%s:%d", middlewareServer.GetHostIP(), s.getMiddlewareAdminPort())
+		}
+		proxyRepos[i] = NewMiddlewareProxyRepo(addr, s.getApplicationMySQLUser(), s.getApplicationMySQLPass(),
+			adminAddr, s.getMiddlewareAdminUser(), s.getMiddlewareAdminPass())
+	}
+	s.Engine = NewMiddlewareEngine(middlewareOperationID, s.GetDASRepo(), middlewareServers, proxyRepos,
+		mysqlServerService.GetMySQLServers(), mysqlClusterIDs)
+	// run asynchronously
+	go s.runMiddleware()
+
+	return nil
+}
+
 // check performs healthcheck on the mysql server with given mysql server id,
 // initiating is synchronous, actual running is asynchronous
 func (s *Service) check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
 	// init
 	err := s.init(mysqlServerID, startTime, endTime, step)
 	if err != nil {
-		updateErr := s.DASRepo.UpdateOperationStatus(s.OperationInfo.operationID, defaultFailedStatus, err.Error())
-		if updateErr != nil {
-			log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
-		}
+		s.updateFailedStatus(err)
 
 		return err
 	}
 	// run asynchronously
-	go s.Engine.Run()
+	go s.run()
 
 	return nil
 }
 
-// init initiates healthcheck operation and engine
-func (s *Service) init(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
-	// check if operation with the same mysql server id is still running
-	isRunning, err := s.DASRepo.IsRunning(mysqlServerID)
+// CheckBySnapshot checks the health status of the mysql server with the captured snapshot instead of the live connections,
+// the mysql server must exist in the metadata, so that the operation and the result could be recorded
+func (s *Service) CheckBySnapshot(mysqlServerID int, data []byte) error {
+	snapshot, err := NewSnapshotWithJSON(data)
 	if err != nil {
 		return err
 	}
-	if isRunning {
-		return fmt.Errorf("healthcheck of mysql server is still running. mysql server id: %d", mysqlServerID)
-	}
-	// insert operation message
-	operationID, err := s.DASRepo.InitOperation(mysqlServerID, startTime, endTime, step)
+	// init
+	err = s.initWithSnapshot(mysqlServerID, snapshot)
 	if err != nil {
+		s.updateFailedStatus(err)
+
 		return err
 	}
-	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
-	err = mysqlServerService.GetByID(mysqlServerID)
+	// run asynchronously
+	go s.run()
+
+	return nil
+}
+
+// run runs the engine synchronously, the operation could be inspected and canceled on this das server while it is running
+func (s *Service) run() {
+	s.runWithRegistry(runningOperations, s.getOperationID(), s.notify)
+}
+
+// runMiddleware runs the middleware engine synchronously, the middleware operation could be canceled on this das server while it is running,
+// the notify rules only match the mysql operations, so nothing will be notified
+func (s *Service) runMiddleware() {
+	s.runWithRegistry(runningMiddlewareOperations, s.MiddlewareOperationID, nil)
+}
+
+// runWithRegistry registers the engine to the given registry and runs it synchronously,
+// after the operation is unregistered, the outcome is notified asynchronously if notify is not nil,
+// so the retries of the notification will not delay the caller or keep the operation running
+func (s *Service) runWithRegistry(registry *operationRegistry, operationID int, notify func(operationID int)) {
+	s.runRegistered(registry, operationID)
+
+	if notify != nil {
+		go notify(operationID)
+	}
+}
+
+// runRegistered registers the engine to the given registry, runs it synchronously and unregisters it when it finishes
+func (s *Service) runRegistered(registry *operationRegistry, operationID int) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	registry.register(operationID, s.Engine, cancel)
+	defer registry.unregister(operationID)
+
+	s.Engine.Run(ctx)
+}
+
+// notify notifies the outcome of the operation to the matched notify rules, the failure of the notification is only logged
+func (s *Service) notify(operationID int) {
+	if operationID == constant.ZeroInt {
+		return
+	}
+
+	err := NewNotifierWithDefault().Notify(operationID)
+	if err != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckNotify, operationID, err.Error()).Error())
+	}
+}
+
+// getOperationID returns the operation id, it returns 0 if the operation has not been initiated
+func (s *Service) getOperationID() int {
+	if s.GetOperationInfo() == nil {
+		return constant.ZeroInt
+	}
+
+	return s.GetOperationInfo().GetOperationID()
+}
+
+// updateFailedStatus updates the operation status to failed if the operation has been initiated
+func (s *Service) updateFailedStatus(err error) {
+	if s.getOperationID() == constant.ZeroInt {
+		return
+	}
+
+	updateErr := s.DASRepo.UpdateOperationStatus(s.getOperationID(), defaultFailedStatus, err.Error())
+	if updateErr != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckUpdateOperationStatus, updateErr.Error()).Error())
+	}
+}
+
+// closeEngine closes the connections of the engine which will not run, the failure of closing is only logged
+func (s *Service) closeEngine() {
+	de, ok := s.Engine.(*DefaultEngine)
+	if !ok {
+		return
+	}
+
+	err := de.closeConnections()
+	if err != nil {
+		log.Error(message.NewMessage(msghc.ErrHealthcheckCloseConnection, err.Error()).Error())
+	}
+}
+
+// closeRepos closes the connections of the repositories, the nil repositories are skipped and the failure of closing is only logged
+func closeRepos(repos ...io.Closer) {
+	for _, repo := range repos {
+		if repo == nil {
+			continue
+		}
+		err := repo.Close()
+		if err != nil {
+			log.Error(message.NewMessage(msghc.ErrHealthcheckCloseConnection, err.Error()).Error())
+		}
+	}
+}
+
+// init initiates healthcheck operation and engine
+func (s *Service) init(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error {
+	return s.initWithClusterOperationID(constant.ZeroInt, mysqlServerID, startTime, endTime, step)
+}
+
+// initWithClusterOperationID initiates healthcheck operation which belongs to given cluster operation and engine,
+// cluster operation id is 0 if the operation does not belong to any cluster operation,
+// the connections which have been created will be closed if the initiation failed
+func (s *Service) initWithClusterOperationID(clusterOperationID, mysqlServerID int, startTime, endTime time.Time, step time.Duration) (err error) {
+	mysqlServer, err := s.initOperation(clusterOperationID, mysqlServerID, startTime, endTime, step)
 	if err != nil {
 		return err
 	}
-	// get mysql server
-	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
 	// get monitor system
 	monitorSystem, err := mysqlServer.GetMonitorSystem()
-	// init operation information
-	s.OperationInfo = NewOperationInfo(operationID, mysqlServer, monitorSystem, startTime, endTime, step)
+	if err != nil {
+		return err
+	}
+	s.OperationInfo = NewOperationInfo(s.getOperationID(), mysqlServer, monitorSystem, startTime, endTime, step)
 
 	// init application mysql connection
 	mysqlServerAddr := fmt.Sprintf("%s:%d", mysqlServer.GetHostIP(), mysqlServer.GetPortNum())
@@ -157,45 +600,120 @@ func (s *Service) init(mysqlServerID int, startTime, endTime time.Time, step tim
 	// init application mysql repository
 	applicationMySQLRepo := NewApplicationMySQLRepo(s.GetOperationInfo(), applicationMySQLConn)
 
-	var (
-		prometheusConfig prometheus.Config
-		queryRepo        healthcheck.QueryRepo
-	)
+	var queryRepo healthcheck.QueryRepo
+	defer func() {
+		if err != nil {
+			closeRepos(applicationMySQLRepo, queryRepo)
+		}
+	}()
 
-	prometheusAddr := fmt.Sprintf("%s:%d%s", monitorSystem.GetHostIP(), monitorSystem.GetPortNum(), monitorSystem.GetBaseURL())
 	slowQueryAddr := fmt.Sprintf("%s:%d", monitorSystem.GetHostIP(), monitorSystem.GetPortNumSlow())
 
 	switch monitorSystem.GetSystemType() {
-	case 1:
+	case MonitorSystemTypePMMV1:
 		// pmm 1.x
-		// init prometheus config
-		prometheusConfig = prometheus.NewConfig(prometheusAddr, prometheus.DefaultRoundTripper)
 		// init mysql connection
 		conn, err := mysql.NewConn(slowQueryAddr, defaultMonitorMySQLDBName, s.getMonitorMySQLUser(), s.getMonitorMySQLPass())
 		if err != nil {
 			return err
 		}
 		queryRepo = NewMySQLQueryRepo(s.GetOperationInfo(), conn)
-	case 2:
+	case MonitorSystemTypePMMV2:
 		// pmm 2.x
-		// init prometheus config
-		prometheusConfig = prometheus.NewConfigWithBasicAuth(prometheusAddr, s.getMonitorPrometheusUser(), s.getMonitorPrometheusPass())
 		// init clickhouse connection
 		conn, err := clickhouse.NewConnWithDefault(slowQueryAddr, defaultMonitorClickhouseDBName, s.getMonitorClickhouseUser(), s.getMonitorClickhousePass())
 		if err != nil {
 			return err
 		}
 		queryRepo = NewClickhouseQueryRepo(s.GetOperationInfo(), conn)
+	case MonitorSystemTypePrometheus:
+		// plain prometheus, there is no query analytics
+		queryRepo = NewEmptyQueryRepo()
 	default:
-		return fmt.Errorf("healthcheck: monitor system type should be either 1 or 2, %d is not valid", monitorSystem.GetSystemType())
+		return message.NewMessage(msghc.ErrPrometheusCatalogueSystemTypeInvalid, monitorSystem.GetSystemType())
+	}
+	// init prometheus repository
+	prometheusRepo, err := NewPrometheusRepoWithOperationInfo(s.GetOperationInfo())
+	if err != nil {
+		return err
+	}
+	s.Engine = NewDefaultEngine(s.GetOperationInfo(), s.GetDASRepo(), applicationMySQLRepo, prometheusRepo, queryRepo)
+
+	return nil
+}
+
+// NewPrometheusRepoWithOperationInfo returns a new *PrometheusRepo which connects to the prometheus of the monitor system of the operation,
+// the queries are rendered with the prometheus catalogue of the monitor system
+func NewPrometheusRepoWithOperationInfo(operationInfo *OperationInfo) (*PrometheusRepo, error) {
+	var prometheusConfig prometheus.Config
+
+	monitorSystem := operationInfo.GetMonitorSystem()
+	prometheusAddr := fmt.Sprintf("%s:%d%s", monitorSystem.GetHostIP(), monitorSystem.GetPortNum(), monitorSystem.GetBaseURL())
+
+	switch monitorSystem.GetSystemType() {
+	case MonitorSystemTypePMMV1, MonitorSystemTypePrometheus:
+		// pmm 1.x and plain prometheus
+		prometheusConfig = prometheus.NewConfig(prometheusAddr, prometheus.DefaultRoundTripper)
+	case MonitorSystemTypePMMV2:
+		// pmm 2.x
+		prometheusConfig = prometheus.NewConfigWithBasicAuth(prometheusAddr,
+			viper.GetString(config.DBMonitorPrometheusUserKey), viper.GetString(config.DBMonitorPrometheusPassKey))
+	default:
+		return nil, message.NewMessage(msghc.ErrPrometheusCatalogueSystemTypeInvalid, monitorSystem.GetSystemType())
+	}
+	// init prometheus catalogue
+	catalogueService := NewPrometheusCatalogueServiceWithDefault()
+	err := catalogueService.getByMonitorSystem(monitorSystem)
+	if err != nil {
+		return nil, err
 	}
 
 	prometheusConn, err := prometheus.NewConnWithConfig(prometheusConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrometheusRepoWithCatalogue(operationInfo, prometheusConn, catalogueService.GetCatalogue()), nil
+}
+
+// initOperation initiates healthcheck operation and returns the mysql server,
+// the operation information does not contain the monitor system yet
+func (s *Service) initOperation(clusterOperationID, mysqlServerID int, startTime, endTime time.Time, step time.Duration) (depmeta.MySQLServer, error) {
+	// check if operation with the same mysql server id is still running
+	isRunning, err := s.DASRepo.IsRunning(mysqlServerID)
+	if err != nil {
+		return nil, err
+	}
+	if isRunning {
+		return nil, fmt.Errorf("healthcheck of mysql server is still running. mysql server id: %d", mysqlServerID)
+	}
+	// insert operation message
+	operationID, err := s.DASRepo.InitMemberOperation(clusterOperationID, mysqlServerID, startTime, endTime, step)
+	if err != nil {
+		return nil, err
+	}
+	// keep the operation id, so that the operation status could be updated if the following steps failed
+	s.OperationInfo = NewOperationInfo(operationID, nil, nil, startTime, endTime, step)
+	mysqlServerService := metadata.NewMySQLServerServiceWithDefault()
+	err = mysqlServerService.GetByID(mysqlServerID)
+	if err != nil {
+		return nil, err
+	}
+	// get mysql server
+	mysqlServer := mysqlServerService.GetMySQLServers()[constant.ZeroInt]
+	s.OperationInfo = NewOperationInfo(operationID, mysqlServer, nil, startTime, endTime, step)
+
+	return mysqlServer, nil
+}
+
+// initWithSnapshot initiates healthcheck operation and the engine which runs against the snapshot instead of the live connections
+func (s *Service) initWithSnapshot(mysqlServerID int, snapshot *Snapshot) error {
+	_, err := s.initOperation(constant.ZeroInt, mysqlServerID, snapshot.GetStartTime(), snapshot.GetEndTime(), snapshot.GetStep())
 	if err != nil {
 		return err
 	}
-	prometheusRepo := NewPrometheusRepo(s.GetOperationInfo(), prometheusConn)
-	s.Engine = NewDefaultEngine(s.GetOperationInfo(), s.GetDASRepo(), applicationMySQLRepo, prometheusRepo, queryRepo)
+
+	s.Engine = NewSnapshotEngine(s.GetOperationInfo(), s.GetDASRepo(), snapshot)
 
 	return nil
 }
@@ -210,16 +728,6 @@ func (s *Service) getApplicationMySQLPass() string {
 	return viper.GetString(config.DBApplicationMySQLPassKey)
 }
 
-// getMonitorPrometheusUser returns prometheus username of monitor system
-func (s *Service) getMonitorPrometheusUser() string {
-	return viper.GetString(config.DBMonitorPrometheusUserKey)
-}
-
-// getMonitorPrometheusPass returns prometheus password of monitor system
-func (s *Service) getMonitorPrometheusPass() string {
-	return viper.GetString(config.DBMonitorPrometheusPassKey)
-}
-
 // getMonitorClickhouseUser returns clickhouse username of monitor system
 func (s *Service) getMonitorClickhouseUser() string {
 	return viper.GetString(config.DBMonitorClickhouseUserKey)
@@ -240,6 +748,21 @@ func (s *Service) getMonitorMySQLPass() string {
 	return viper.GetString(config.DBMonitorMySQLPassKey)
 }
 
+// getMiddlewareAdminPort returns the admin port of the middleware servers
+func (s *Service) getMiddlewareAdminPort() int {
+	return viper.GetInt(config.HealthcheckMiddlewareAdminPortKey)
+}
+
+// getMiddlewareAdminUser returns the admin username of the middleware servers
+func (s *Service) getMiddlewareAdminUser() string {
+	return viper.GetString(config.HealthcheckMiddlewareAdminUserKey)
+}
+
+// getMiddlewareAdminPass returns the admin password of the middleware servers
+func (s *Service) getMiddlewareAdminPass() string {
+	return viper.GetString(config.HealthcheckMiddlewareAdminPassKey)
+}
+
 // ReviewAccuracy updates accuracy review with given operation id
 func (s *Service) ReviewAccuracy(id, review int) error {
 	return s.DASRepo.UpdateAccuracyReviewByOperationID(id, review)
@@ -250,6 +773,51 @@ func (s *Service) MarshalJSON() ([]byte, error) {
 	return s.MarshalJSONWithFields(resultStruct)
 }
 
+// MarshalClusterResultJSON marshals the cluster result of the Service to json bytes
+func (s *Service) MarshalClusterResultJSON() ([]byte, error) {
+	return json.Marshal(s.ClusterResult)
+}
+
+// MarshalMiddlewareResultJSON marshals the middleware result of the Service to json bytes
+func (s *Service) MarshalMiddlewareResultJSON() ([]byte, error) {
+	return json.Marshal(s.MiddlewareResult)
+}
+
+// MarshalScoreTrendJSON marshals the score trend of the Service to json bytes
+func (s *Service) MarshalScoreTrendJSON() ([]byte, error) {
+	return json.Marshal(s.ScoreTrend)
+}
+
+// MarshalResultDiffJSON marshals the result diff of the Service to json bytes
+func (s *Service) MarshalResultDiffJSON() ([]byte, error) {
+	return json.Marshal(s.ResultDiff)
+}
+
+// MarshalReportJSON marshals the report of the Service to json bytes
+func (s *Service) MarshalReportJSON() ([]byte, error) {
+	return json.Marshal(s.Report)
+}
+
+// MarshalReportHTML renders the report of the Service as a html page
+func (s *Service) MarshalReportHTML() ([]byte, error) {
+	return s.Report.RenderHTML()
+}
+
+// MarshalReportMarkdown renders the report of the Service as a markdown document
+func (s *Service) MarshalReportMarkdown() ([]byte, error) {
+	return s.Report.RenderMarkdown()
+}
+
+// MarshalOperationsJSON marshals the operations of the Service to json bytes
+func (s *Service) MarshalOperationsJSON() ([]byte, error) {
+	return json.Marshal(s.Operations)
+}
+
+// MarshalOperationStatusJSON marshals the operation status of the Service to json bytes
+func (s *Service) MarshalOperationStatusJSON() ([]byte, error) {
+	return json.Marshal(s.OperationStatus)
+}
+
 // MarshalJSONWithFields marshals only specified fields of the Service to json bytes
 func (s *Service) MarshalJSONWithFields(fields ...string) ([]byte, error) {
 	return common.MarshalStructWithFields(s.Result, fields...)
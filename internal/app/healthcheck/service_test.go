@@ -1,12 +1,13 @@
 package healthcheck
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
-	"time"
 
 	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/dependency/healthcheck"
 	"github.com/romberli/go-util/common"
-	"github.com/romberli/go-util/constant"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 )
@@ -90,8 +91,13 @@ func initViper() {
 }
 
 func deleteHCResultByOperationID(operationID int) error {
-	sql := `delete from t_hc_result where operation_id = ?`
+	sql := `delete from t_hc_result_item where operation_id = ?`
 	_, err := testDASRepo.Execute(sql, operationID)
+	if err != nil {
+		return err
+	}
+	sql = `delete from t_hc_result where operation_id = ?`
+	_, err = testDASRepo.Execute(sql, operationID)
 	return err
 }
 
@@ -102,9 +108,14 @@ func TestServiceAll(t *testing.T) {
 	TestService_ReviewAccuracy(t)
 	TestService_MarshalJSON(t)
 	TestService_MarshalJSONWithFields(t)
+	TestService_closeRepos(t)
+	TestService_closeEngine(t)
+	TestService_runMiddleware(t)
+	TestService_runWithRegistry(t)
 }
 
 func TestService_GetResult(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := createService()
@@ -118,6 +129,7 @@ func TestService_GetResult(t *testing.T) {
 }
 
 func TestService_GetResultByOperationID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := createService()
@@ -132,22 +144,33 @@ func TestService_GetResultByOperationID(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
 }
 
-// bug
+// TestService_Check checks the operation with the golden file of the engine,
+// the live application mysql, prometheus and query connections could not be replayed,
+// so the operation is initiated with the snapshot and the engine runs synchronously
 func TestService_Check(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
-	err := initGlobalMySQLPool()
-	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
-
 	service, err := createService()
-	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
-
-	err = service.Check(defaultMysqlServerID, time.Now().Add(-constant.Week), time.Now(), defaultStep)
-	asst.Nil(err, common.CombineMessageWithError("test Check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) failed", err))
+	asst.Nil(err, common.CombineMessageWithError("test Check() failed", err))
+	snapshot, err := NewSnapshotWithFile(filepath.Join(testDataDir, testSnapshotFile))
+	asst.Nil(err, common.CombineMessageWithError("test Check() failed", err))
+
+	err = service.initWithSnapshot(defaultMysqlServerID, snapshot)
+	asst.Nil(err, common.CombineMessageWithError("test Check() failed", err))
+	operationID := service.getOperationID()
+	service.runWithRegistry(runningOperations, operationID, nil)
+	err = service.GetOperationStatus(operationID)
+	asst.Nil(err, common.CombineMessageWithError("test Check() failed", err))
+	asst.Equal(defaultSuccessStatus, service.OperationStatus.Operation.GetStatus(), "test Check() failed")
 
 	// delete
 	err = deleteHCResultByOperationID(defaultResultOperationID)
-	asst.Nil(err, common.CombineMessageWithError("test GetResultByOperationID() failed", err))
+	asst.Nil(err, common.CombineMessageWithError("test Check() failed", err))
+	err = deleteHCResultByOperationID(operationID)
+	asst.Nil(err, common.CombineMessageWithError("test Check() failed", err))
+	err = deleteOperationInfoByID(operationID)
+	asst.Nil(err, common.CombineMessageWithError("test Check() failed", err))
 }
 
 // bug
@@ -170,6 +193,7 @@ func TestService_CheckByHostInfo(t *testing.T) {
 }
 
 func TestService_ReviewAccuracy(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := createService()
@@ -187,6 +211,7 @@ func TestService_ReviewAccuracy(t *testing.T) {
 }
 
 func TestService_MarshalJSON(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := createService()
@@ -199,6 +224,7 @@ func TestService_MarshalJSON(t *testing.T) {
 }
 
 func TestService_MarshalJSONWithFields(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	service, err := createService()
@@ -210,4 +236,101 @@ func TestService_MarshalJSONWithFields(t *testing.T) {
 	asst.Nil(err, common.CombineMessageWithError("test MarshalJSONWithFields(fields ...string) failed", err))
 }
 
+type testCloser struct {
+	closed bool
+}
+
+func (tc *testCloser) Close() error {
+	tc.closed = true
+
+	return nil
+}
+
+// testBlockingEngine is the engine which runs until the context is canceled
+type testBlockingEngine struct {
+	started chan struct{}
+}
+
+func (tbe *testBlockingEngine) Run(ctx context.Context) {
+	close(tbe.started)
+	<-ctx.Done()
+}
+
+func (tbe *testBlockingEngine) GetItemStatuses() map[string]string {
+	return nil
+}
+
+func TestService_runMiddleware(t *testing.T) {
+	asst := assert.New(t)
+
+	service := newService(nil)
+	service.MiddlewareOperationID = testOperationID
+	engine := &testBlockingEngine{started: make(chan struct{})}
+	service.Engine = engine
+	done := make(chan struct{})
+	go func() {
+		service.runMiddleware()
+		close(done)
+	}()
+	<-engine.started
+
+	// the middleware operation is kept apart from the mysql operation with the same id
+	_, isRunning := runningOperations.getEngine(testOperationID)
+	asst.False(isRunning, "test runMiddleware() failed")
+	_, isRunning = runningMiddlewareOperations.getEngine(testOperationID)
+	asst.True(isRunning, "test runMiddleware() failed")
+
+	err := service.CancelMiddleware(testOperationID)
+	asst.Nil(err, common.CombineMessageWithError("test runMiddleware() failed", err))
+	<-done
+	_, isRunning = runningMiddlewareOperations.getEngine(testOperationID)
+	asst.False(isRunning, "test runMiddleware() failed")
+	asst.NotNil(service.CancelMiddleware(testOperationID), "test runMiddleware() failed")
+}
+
+func TestService_runWithRegistry(t *testing.T) {
+	asst := assert.New(t)
+
+	service := newService(nil)
+	engine := &testBlockingEngine{started: make(chan struct{})}
+	service.Engine = engine
+	registry := newOperationRegistry()
+	notified := make(chan bool)
+	release := make(chan struct{})
+	go func() {
+		<-engine.started
+		_ = registry.cancel(testOperationID)
+	}()
+	// the notification is sent after the operation is unregistered and does not block the caller
+	service.runWithRegistry(registry, testOperationID, func(operationID int) {
+		_, isRunning := registry.getEngine(operationID)
+		<-release
+		notified <- isRunning
+	})
+	close(release)
+	asst.False(<-notified, "test runWithRegistry() failed")
+}
+
+func TestService_closeRepos(t *testing.T) {
+	asst := assert.New(t)
+
+	var queryRepo healthcheck.QueryRepo
+	closer := &testCloser{}
+	closeRepos(closer, queryRepo)
+	asst.True(closer.closed, "test closeRepos() failed")
+}
+
+func TestService_closeEngine(t *testing.T) {
+	asst := assert.New(t)
+
+	// the engine of the member which failed to initiate is nil
+	service := newService(nil)
+	asst.NotPanics(service.closeEngine, "test closeEngine() failed")
+
+	snapshot, err := NewSnapshotWithFile(filepath.Join(testDataDir, testSnapshotFile))
+	asst.Nil(err, common.CombineMessageWithError("test closeEngine() failed", err))
+	service.Engine = newTestSnapshotEngine(snapshot)
+	asst.NotPanics(service.closeEngine, "test closeEngine() failed")
+}
+
 // go test ./service_test.go ./service.go ./query.go ./default_engine.go ./result.go
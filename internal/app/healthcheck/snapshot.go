@@ -0,0 +1,365 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/romberli/das/internal/app/query"
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	depquery "github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/das/pkg/message"
+	msghc "github.com/romberli/das/pkg/message/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+var (
+	_ healthcheck.ApplicationMySQLRepo = (*SnapshotApplicationMySQLRepo)(nil)
+	_ healthcheck.PrometheusRepo       = (*SnapshotPrometheusRepo)(nil)
+	_ healthcheck.QueryRepo            = (*SnapshotQueryRepo)(nil)
+)
+
+// Snapshot is the captured data of a mysql server, the engine could run against it without any live connection,
+// the data should be captured with the same queries as the live repositories use
+type Snapshot struct {
+	StartTime   string              `json:"start_time"`
+	EndTime     string              `json:"end_time"`
+	Step        string              `json:"step"`
+	MySQL       *MySQLSnapshot      `json:"mysql"`
+	Prometheus  *PrometheusSnapshot `json:"prometheus"`
+	SlowQueries []*query.Query      `json:"slow_queries"`
+	startTime   time.Time
+	endTime     time.Time
+	step        time.Duration
+}
+
+// MySQLSnapshot is the captured data of the application mysql
+type MySQLSnapshot struct {
+	Variables               []*GlobalVariable  `json:"variables"`
+	MySQLDirs               []string           `json:"mysql_dirs"`
+	ReplicationStatus       *ReplicationStatus `json:"replication_status"`
+	GTIDExecuted            string             `json:"gtid_executed"`
+	PrimaryGTIDExecuted     string             `json:"primary_gtid_executed"`
+	LockWaits               []*LockWait        `json:"lock_waits"`
+	InnoDBStatus            string             `json:"innodb_status"`
+	UnusedIndexes           []*IndexFinding    `json:"unused_indexes"`
+	RedundantIndexes        []*IndexFinding    `json:"redundant_indexes"`
+	TablesWithoutPrimaryKey []*IndexFinding    `json:"tables_without_primary_key"`
+	NonInnoDBTables         []*IndexFinding    `json:"non_innodb_tables"`
+	Tables                  []*Table           `json:"tables"`
+}
+
+// PrometheusSnapshot is the captured range query results of the prometheus
+type PrometheusSnapshot struct {
+	FileSystems                  []*FileSystem     `json:"file_systems"`
+	CPUUsage                     []*PrometheusData `json:"cpu_usage"`
+	IOUtil                       []*PrometheusData `json:"io_util"`
+	DiskCapacityUsage            []*PrometheusData `json:"disk_capacity_usage"`
+	ConnectionUsage              []*PrometheusData `json:"connection_usage"`
+	AverageActiveSessionPercents []*PrometheusData `json:"average_active_session_percents"`
+	CacheMissRatio               []*PrometheusData `json:"cache_miss_ratio"`
+	ReplicationLag               []*PrometheusData `json:"replication_lag"`
+	InnoDBRowLockWaits           []*PrometheusData `json:"innodb_row_lock_waits"`
+	InnoDBRowLockTime            []*PrometheusData `json:"innodb_row_lock_time"`
+}
+
+// NewSnapshotWithJSON unmarshals the json bytes to a new *Snapshot and validates it,
+// the time layout of the start time and the end time is the same as the check api
+func NewSnapshotWithJSON(data []byte) (*Snapshot, error) {
+	s := &Snapshot{}
+	err := json.Unmarshal(data, s)
+	if err != nil {
+		return nil, message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid, err.Error())
+	}
+
+	err = s.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// validate validates the snapshot and parses the check range
+func (s *Snapshot) validate() error {
+	var err error
+
+	s.startTime, err = time.ParseInLocation(constant.TimeLayoutSecond, s.StartTime, time.Local)
+	if err != nil {
+		return message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid, err.Error())
+	}
+	s.endTime, err = time.ParseInLocation(constant.TimeLayoutSecond, s.EndTime, time.Local)
+	if err != nil {
+		return message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid, err.Error())
+	}
+	if !s.startTime.Before(s.endTime) {
+		return message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid,
+			fmt.Sprintf("start time must be earlier than end time. start_time: %s, end_time: %s", s.StartTime, s.EndTime))
+	}
+	s.step, err = time.ParseDuration(s.Step)
+	if err != nil {
+		return message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid, err.Error())
+	}
+	if s.step <= constant.ZeroInt {
+		return message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid, fmt.Sprintf("step must be positive. step: %s", s.Step))
+	}
+	if s.MySQL == nil {
+		return message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid, "mysql data does not exist")
+	}
+	if s.Prometheus == nil {
+		return message.NewMessage(msghc.ErrHealthcheckSnapshotInvalid, "prometheus data does not exist")
+	}
+
+	return nil
+}
+
+// GetStartTime returns the start time of the check range
+func (s *Snapshot) GetStartTime() time.Time {
+	return s.startTime
+}
+
+// GetEndTime returns the end time of the check range
+func (s *Snapshot) GetEndTime() time.Time {
+	return s.endTime
+}
+
+// GetStep returns the step of the check range
+func (s *Snapshot) GetStep() time.Duration {
+	return s.step
+}
+
+// NewSnapshotEngine returns a new *DefaultEngine which runs against the snapshot instead of the live connections,
+// the primary address of the replica comes from the snapshot, so the engine never connects to it
+func NewSnapshotEngine(operationInfo *OperationInfo, dasRepo healthcheck.DASRepo, snapshot *Snapshot) *DefaultEngine {
+	de := NewDefaultEngine(operationInfo, dasRepo,
+		NewSnapshotApplicationMySQLRepo(snapshot.MySQL),
+		NewSnapshotPrometheusRepo(snapshot.Prometheus),
+		NewSnapshotQueryRepo(snapshot.SlowQueries))
+	de.setPrimaryGTIDExecuted(snapshot.getPrimaryGTIDExecuted)
+
+	return de
+}
+
+// getPrimaryGTIDExecuted returns the captured executed gtid set of the primary, it never connects to the primary,
+// so the gtid gap will not be checked if the executed gtid set of the primary was not captured
+func (s *Snapshot) getPrimaryGTIDExecuted(hostIP string, portNum int) (string, error) {
+	if s.MySQL.PrimaryGTIDExecuted == constant.EmptyString {
+		return constant.EmptyString, message.NewMessage(msghc.ErrHealthcheckSnapshotPrimaryGTIDNotCaptured, hostIP, portNum)
+	}
+
+	return s.MySQL.PrimaryGTIDExecuted, nil
+}
+
+// SnapshotApplicationMySQLRepo returns the captured application mysql data of the snapshot
+type SnapshotApplicationMySQLRepo struct {
+	snapshot *MySQLSnapshot
+}
+
+// NewSnapshotApplicationMySQLRepo returns a new *SnapshotApplicationMySQLRepo
+func NewSnapshotApplicationMySQLRepo(snapshot *MySQLSnapshot) *SnapshotApplicationMySQLRepo {
+	return &SnapshotApplicationMySQLRepo{snapshot: snapshot}
+}
+
+// Close does nothing, there is no connection to close
+func (samr *SnapshotApplicationMySQLRepo) Close() error {
+	return nil
+}
+
+// GetVariables returns the captured variables of given items, the variable names are case-insensitive
+func (samr *SnapshotApplicationMySQLRepo) GetVariables(items []string) ([]healthcheck.Variable, error) {
+	itemMap := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		itemMap[strings.ToLower(item)] = struct{}{}
+	}
+
+	var variables []healthcheck.Variable
+	for _, variable := range samr.snapshot.Variables {
+		_, exists := itemMap[strings.ToLower(variable.GetName())]
+		if exists {
+			variables = append(variables, variable)
+		}
+	}
+
+	return variables, nil
+}
+
+// GetMySQLDirs returns the captured mysql data directory and binlog directory
+func (samr *SnapshotApplicationMySQLRepo) GetMySQLDirs() ([]string, error) {
+	return samr.snapshot.MySQLDirs, nil
+}
+
+// IsReplica returns if the replication status was captured
+func (samr *SnapshotApplicationMySQLRepo) IsReplica() (bool, error) {
+	return samr.snapshot.ReplicationStatus != nil, nil
+}
+
+// GetReplicationStatus returns the captured replication status, it returns nil if the mysql server is not a replica
+func (samr *SnapshotApplicationMySQLRepo) GetReplicationStatus() (healthcheck.ReplicationStatus, error) {
+	if samr.snapshot.ReplicationStatus == nil {
+		return nil, nil
+	}
+
+	return samr.snapshot.ReplicationStatus, nil
+}
+
+// GetGTIDExecuted returns the captured executed gtid set
+func (samr *SnapshotApplicationMySQLRepo) GetGTIDExecuted() (string, error) {
+	return samr.snapshot.GTIDExecuted, nil
+}
+
+// GetLockWaits returns the captured innodb lock waits
+func (samr *SnapshotApplicationMySQLRepo) GetLockWaits() ([]healthcheck.LockWait, error) {
+	lockWaits := make([]healthcheck.LockWait, len(samr.snapshot.LockWaits))
+	for i := range lockWaits {
+		lockWaits[i] = samr.snapshot.LockWaits[i]
+	}
+
+	return lockWaits, nil
+}
+
+// GetInnoDBStatus returns the captured output of show engine innodb status
+func (samr *SnapshotApplicationMySQLRepo) GetInnoDBStatus() (string, error) {
+	return samr.snapshot.InnoDBStatus, nil
+}
+
+// GetUnusedIndexes returns the captured unused indexes
+func (samr *SnapshotApplicationMySQLRepo) GetUnusedIndexes() ([]healthcheck.IndexFinding, error) {
+	return convertSnapshotIndexFindings(samr.snapshot.UnusedIndexes), nil
+}
+
+// GetRedundantIndexes returns the captured redundant indexes
+func (samr *SnapshotApplicationMySQLRepo) GetRedundantIndexes() ([]healthcheck.IndexFinding, error) {
+	return convertSnapshotIndexFindings(samr.snapshot.RedundantIndexes), nil
+}
+
+// GetTablesWithoutPrimaryKey returns the captured tables which do not have a primary key
+func (samr *SnapshotApplicationMySQLRepo) GetTablesWithoutPrimaryKey() ([]healthcheck.IndexFinding, error) {
+	return convertSnapshotIndexFindings(samr.snapshot.TablesWithoutPrimaryKey), nil
+}
+
+// GetNonInnoDBTables returns the captured tables of which the storage engine is not innodb
+func (samr *SnapshotApplicationMySQLRepo) GetNonInnoDBTables() ([]healthcheck.IndexFinding, error) {
+	return convertSnapshotIndexFindings(samr.snapshot.NonInnoDBTables), nil
+}
+
+// GetLargeTables returns the captured tables
+func (samr *SnapshotApplicationMySQLRepo) GetLargeTables() ([]healthcheck.Table, error) {
+	tables := make([]healthcheck.Table, len(samr.snapshot.Tables))
+	for i := range tables {
+		tables[i] = samr.snapshot.Tables[i]
+	}
+
+	return tables, nil
+}
+
+// SnapshotPrometheusRepo returns the captured prometheus data of the snapshot
+type SnapshotPrometheusRepo struct {
+	snapshot *PrometheusSnapshot
+}
+
+// NewSnapshotPrometheusRepo returns a new *SnapshotPrometheusRepo
+func NewSnapshotPrometheusRepo(snapshot *PrometheusSnapshot) *SnapshotPrometheusRepo {
+	return &SnapshotPrometheusRepo{snapshot: snapshot}
+}
+
+// GetFileSystems returns the captured file systems
+func (spr *SnapshotPrometheusRepo) GetFileSystems() ([]healthcheck.FileSystem, error) {
+	fileSystems := make([]healthcheck.FileSystem, len(spr.snapshot.FileSystems))
+	for i := range fileSystems {
+		fileSystems[i] = spr.snapshot.FileSystems[i]
+	}
+
+	return fileSystems, nil
+}
+
+// GetCPUUsage returns the captured cpu usage
+func (spr *SnapshotPrometheusRepo) GetCPUUsage() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.CPUUsage), nil
+}
+
+// GetIOUtil returns the captured io util
+func (spr *SnapshotPrometheusRepo) GetIOUtil() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.IOUtil), nil
+}
+
+// GetDiskCapacityUsage returns the captured disk capacity usage, the mount points are ignored
+// as the data was captured with the mount points of the mysql server
+func (spr *SnapshotPrometheusRepo) GetDiskCapacityUsage(mountPoints []string) ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.DiskCapacityUsage), nil
+}
+
+// GetConnectionUsage returns the captured connection usage
+func (spr *SnapshotPrometheusRepo) GetConnectionUsage() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.ConnectionUsage), nil
+}
+
+// GetAverageActiveSessionPercents returns the captured average active session percents
+func (spr *SnapshotPrometheusRepo) GetAverageActiveSessionPercents() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.AverageActiveSessionPercents), nil
+}
+
+// GetCacheMissRatio returns the captured cache miss ratio
+func (spr *SnapshotPrometheusRepo) GetCacheMissRatio() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.CacheMissRatio), nil
+}
+
+// GetReplicationLag returns the captured replication lag
+func (spr *SnapshotPrometheusRepo) GetReplicationLag() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.ReplicationLag), nil
+}
+
+// GetInnoDBRowLockWaits returns the captured innodb row lock waits per second
+func (spr *SnapshotPrometheusRepo) GetInnoDBRowLockWaits() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.InnoDBRowLockWaits), nil
+}
+
+// GetInnoDBRowLockTime returns the captured innodb row lock time per second in milliseconds
+func (spr *SnapshotPrometheusRepo) GetInnoDBRowLockTime() ([]healthcheck.PrometheusData, error) {
+	return convertSnapshotPrometheusDatas(spr.snapshot.InnoDBRowLockTime), nil
+}
+
+// SnapshotQueryRepo returns the captured slow queries of the snapshot
+type SnapshotQueryRepo struct {
+	slowQueries []*query.Query
+}
+
+// NewSnapshotQueryRepo returns a new *SnapshotQueryRepo
+func NewSnapshotQueryRepo(slowQueries []*query.Query) *SnapshotQueryRepo {
+	return &SnapshotQueryRepo{slowQueries: slowQueries}
+}
+
+// Close does nothing, there is no connection to close
+func (sqr *SnapshotQueryRepo) Close() error {
+	return nil
+}
+
+// GetSlowQuery returns the captured slow queries
+func (sqr *SnapshotQueryRepo) GetSlowQuery() ([]depquery.Query, error) {
+	queries := make([]depquery.Query, len(sqr.slowQueries))
+	for i := range queries {
+		queries[i] = sqr.slowQueries[i]
+	}
+
+	return queries, nil
+}
+
+// convertSnapshotIndexFindings converts the captured index findings to []healthcheck.IndexFinding
+func convertSnapshotIndexFindings(findings []*IndexFinding) []healthcheck.IndexFinding {
+	result := make([]healthcheck.IndexFinding, len(findings))
+	for i := range result {
+		result[i] = findings[i]
+	}
+
+	return result
+}
+
+// convertSnapshotPrometheusDatas converts the captured prometheus data to []healthcheck.PrometheusData
+func convertSnapshotPrometheusDatas(datas []*PrometheusData) []healthcheck.PrometheusData {
+	result := make([]healthcheck.PrometheusData, len(datas))
+	for i := range result {
+		result[i] = datas[i]
+	}
+
+	return result
+}
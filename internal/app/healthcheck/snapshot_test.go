@@ -0,0 +1,150 @@
+package healthcheck
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSnapshot = `{
+	"start_time": "2021-05-21 10:00:00",
+	"end_time": "2021-05-21 11:00:00",
+	"step": "10s",
+	"mysql": {
+		"variables": [
+			{"variable_name": "MAX_CONNECTIONS", "variable_value": "1000"},
+			{"variable_name": "innodb_buffer_pool_size", "variable_value": "1073741824"}
+		],
+		"mysql_dirs": ["/data/mysql/data", "/data/mysql/binlog"],
+		"tables": [
+			{"table_schema": "das", "table_name": "t01", "table_rows": 120000000, "table_size": 80.5},
+			{"table_schema": "das", "table_name": "t02", "table_rows": 40000000, "table_size": 10.2}
+		],
+		"tables_without_primary_key": [
+			{"finding_type": "no_primary_key", "table_schema": "das", "table_name": "t03", "engine": "InnoDB"}
+		]
+	},
+	"prometheus": {
+		"file_systems": [{"mount_point": "/", "device": "/dev/sda1"}, {"mount_point": "/data", "device": "/dev/sdb1"}],
+		"cpu_usage": [
+			{"timestamp": "2021-05-21 10:00:00", "value": 20.5},
+			{"timestamp": "2021-05-21 10:00:10", "value": 85.5},
+			{"timestamp": "2021-05-21 10:00:20", "value": 30}
+		]
+	},
+	"slow_queries": [
+		{"sql_id": "F9A57DD5A41825CA", "fingerprint": "select * from t01 where id = ?", "db_name": "das", "exec_count": 10, "rows_examined_max": 120000000}
+	]
+}`
+
+func newTestSnapshotEngine(snapshot *Snapshot) *DefaultEngine {
+	operationInfo := NewOperationInfo(1, nil, nil, snapshot.GetStartTime(), snapshot.GetEndTime(), snapshot.GetStep())
+	de := NewSnapshotEngine(operationInfo, nil, snapshot)
+	de.engineConfig[defaultCPUUsageItemName] = NewDefaultItemConfig(defaultCPUUsageItemName, 25, 50, 70, 10, 20, 100, 10, 50)
+	de.engineConfig[defaultTableRowsItemName] = NewDefaultItemConfig(defaultTableRowsItemName, 25, 50000000, 100000000, 10000000, 10, 50, 5, 30)
+	de.engineConfig[defaultTableSizeItemName] = NewDefaultItemConfig(defaultTableSizeItemName, 25, 50, 70, 10, 10, 50, 5, 30)
+	de.engineConfig[defaultIndexHealthItemName] = newTestIndexHealthConfig()
+
+	return de
+}
+
+func newTestSnapshotItems(de *DefaultEngine) []healthcheck.CheckItem {
+	return []healthcheck.CheckItem{
+		NewPrometheusItem(defaultCPUUsageItemName, de.GetPrometheusRepo().GetCPUUsage),
+		NewTableRowsItem(de),
+		NewTableSizeItem(de),
+		NewIndexHealthItem(de),
+	}
+}
+
+func TestSnapshotAll(t *testing.T) {
+	TestSnapshot_NewSnapshotWithJSON(t)
+	TestSnapshot_SnapshotApplicationMySQLRepo(t)
+	TestSnapshot_SnapshotQueryRepo(t)
+	TestSnapshot_checkItems(t)
+}
+
+func TestSnapshot_NewSnapshotWithJSON(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot, err := NewSnapshotWithJSON([]byte(testSnapshot))
+	asst.Nil(err, common.CombineMessageWithError("test NewSnapshotWithJSON() failed", err))
+	asst.Equal(time.Hour, snapshot.GetEndTime().Sub(snapshot.GetStartTime()), "test NewSnapshotWithJSON() failed")
+	asst.Equal(10*time.Second, snapshot.GetStep(), "test NewSnapshotWithJSON() failed")
+
+	for _, invalid := range []string{
+		`[]`,
+		`{"start_time": "2021-05-21 11:00:00", "end_time": "2021-05-21 10:00:00", "step": "10s", "mysql": {}, "prometheus": {}}`,
+		`{"start_time": "2021-05-21 10:00:00", "end_time": "2021-05-21 11:00:00", "step": "0s", "mysql": {}, "prometheus": {}}`,
+		`{"start_time": "2021-05-21 10:00:00", "end_time": "2021-05-21 11:00:00", "step": "10s", "prometheus": {}}`,
+		`{"start_time": "2021-05-21 10:00:00", "end_time": "2021-05-21 11:00:00", "step": "10s", "mysql": {}}`,
+	} {
+		_, err = NewSnapshotWithJSON([]byte(invalid))
+		asst.NotNil(err, "test NewSnapshotWithJSON() failed, snapshot: %s", invalid)
+	}
+}
+
+func TestSnapshot_SnapshotApplicationMySQLRepo(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot, err := NewSnapshotWithJSON([]byte(testSnapshot))
+	asst.Nil(err, common.CombineMessageWithError("test SnapshotApplicationMySQLRepo failed", err))
+	repo := NewSnapshotApplicationMySQLRepo(snapshot.MySQL)
+
+	variables, err := repo.GetVariables([]string{"max_connections", "sync_binlog"})
+	asst.Nil(err, common.CombineMessageWithError("test GetVariables() failed", err))
+	asst.Equal(1, len(variables), "test GetVariables() failed")
+	asst.Equal("1000", variables[0].GetValue(), "test GetVariables() failed")
+
+	isReplica, err := repo.IsReplica()
+	asst.Nil(err, common.CombineMessageWithError("test IsReplica() failed", err))
+	asst.False(isReplica, "test IsReplica() failed")
+	replicationStatus, err := repo.GetReplicationStatus()
+	asst.Nil(err, common.CombineMessageWithError("test GetReplicationStatus() failed", err))
+	asst.Nil(replicationStatus, "test GetReplicationStatus() failed")
+
+	tables, err := repo.GetLargeTables()
+	asst.Nil(err, common.CombineMessageWithError("test GetLargeTables() failed", err))
+	asst.Equal(2, len(tables), "test GetLargeTables() failed")
+	asst.Equal("t01", tables[0].GetName(), "test GetLargeTables() failed")
+}
+
+func TestSnapshot_SnapshotQueryRepo(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot, err := NewSnapshotWithJSON([]byte(testSnapshot))
+	asst.Nil(err, common.CombineMessageWithError("test SnapshotQueryRepo failed", err))
+	queries, err := NewSnapshotQueryRepo(snapshot.SlowQueries).GetSlowQuery()
+	asst.Nil(err, common.CombineMessageWithError("test GetSlowQuery() failed", err))
+	asst.Equal(1, len(queries), "test GetSlowQuery() failed")
+	asst.Equal(120000000, queries[0].GetRowsExaminedMax(), "test GetSlowQuery() failed")
+}
+
+func TestSnapshot_checkItems(t *testing.T) {
+	asst := assert.New(t)
+
+	snapshot, err := NewSnapshotWithJSON([]byte(testSnapshot))
+	asst.Nil(err, common.CombineMessageWithError("test checkItems() with snapshot failed", err))
+
+	var itemResults [][]*ItemResult
+	for i := 0; i < 2; i++ {
+		de := newTestSnapshotEngine(snapshot)
+		de.checkItems(context.Background(), newTestSnapshotItems(de))
+		for _, itemResult := range de.GetItemResults() {
+			asst.True(itemResult.IsAvailable(), "test checkItems() with snapshot failed, item: %s, error: %s", itemResult.GetItemName(), itemResult.Error)
+		}
+		itemResults = append(itemResults, de.GetItemResults())
+	}
+	// the same snapshot always gets the same results
+	asst.Equal(itemResults[0], itemResults[1], "test checkItems() with snapshot failed")
+	// t01 is higher than the high watermark of the table rows
+	tableRowsResult := itemResults[0][1]
+	asst.Equal(defaultTableRowsItemName, tableRowsResult.GetItemName(), "test checkItems() with snapshot failed")
+	asst.True(tableRowsResult.Score < defaultHundred, "test checkItems() with snapshot failed")
+	asst.True(strings.Contains(tableRowsResult.High, "t01"), "test checkItems() with snapshot failed")
+}
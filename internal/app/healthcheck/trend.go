@@ -0,0 +1,280 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	ItemDiffStatusCompared = "compared"
+	ItemDiffStatusAdded    = "added"
+	ItemDiffStatusRemoved  = "removed"
+)
+
+// ScorePoint is the scores of a healthcheck operation, it is a point of the score trend of the mysql server
+type ScorePoint struct {
+	OperationID          int            `json:"operation_id"`
+	CheckTime            time.Time      `json:"check_time"`
+	WeightedAverageScore int            `json:"weighted_average_score"`
+	ItemScores           map[string]int `json:"item_scores"`
+}
+
+// NewScorePoint returns a new *ScorePoint with given result
+func NewScorePoint(result healthcheck.Result) *ScorePoint {
+	return &ScorePoint{
+		OperationID:          result.GetOperationID(),
+		CheckTime:            result.GetCreateTime(),
+		WeightedAverageScore: result.GetWeightedAverageScore(),
+		ItemScores:           getItemScores(result),
+	}
+}
+
+// NewScoreTrend returns the score trend with given results, the points are sorted by the check time
+func NewScoreTrend(results []healthcheck.Result) []*ScorePoint {
+	scoreTrend := make([]*ScorePoint, len(results))
+	for i, result := range results {
+		scoreTrend[i] = NewScorePoint(result)
+	}
+	sort.SliceStable(scoreTrend, func(i, j int) bool {
+		return scoreTrend[i].CheckTime.Before(scoreTrend[j].CheckTime)
+	})
+
+	return scoreTrend
+}
+
+// ItemScoreDiff is the score difference of a check item between two operations,
+// the item which is only available in one of the operations is reported as added or removed without the score delta
+type ItemScoreDiff struct {
+	ItemName    string `json:"item_name"`
+	Status      string `json:"status"`
+	BaseScore   int    `json:"base_score"`
+	TargetScore int    `json:"target_score"`
+	Delta       int    `json:"delta"`
+	Regressed   bool   `json:"regressed"`
+}
+
+// ConfigChange is a database variable whose value changed between two operations,
+// the value is empty if the variable was not collected in the operation
+type ConfigChange struct {
+	VariableName string `json:"variable_name"`
+	BaseValue    string `json:"base_value"`
+	TargetValue  string `json:"target_value"`
+}
+
+// TableGrowth is the growth of a large table between two operations,
+// the table which only exists in one of the operations is considered as zero rows and zero size in the other one
+type TableGrowth struct {
+	TableSchema string  `json:"table_schema"`
+	TableName   string  `json:"table_name"`
+	BaseRows    int     `json:"base_rows"`
+	TargetRows  int     `json:"target_rows"`
+	RowsDelta   int     `json:"rows_delta"`
+	BaseSize    float64 `json:"base_size"`
+	TargetSize  float64 `json:"target_size"`
+	SizeDelta   float64 `json:"size_delta"`
+}
+
+// ResultDiff is the comparison between the results of two operations
+type ResultDiff struct {
+	BaseOperationID   int              `json:"base_operation_id"`
+	TargetOperationID int              `json:"target_operation_id"`
+	ScoreDelta        int              `json:"score_delta"`
+	ItemDiffs         []*ItemScoreDiff `json:"item_diffs"`
+	ConfigChanges     []*ConfigChange  `json:"config_changes"`
+	TableGrowths      []*TableGrowth   `json:"table_growths"`
+}
+
+// NewResultDiff compares the target result with the base result and returns a new *ResultDiff
+func NewResultDiff(base, target healthcheck.Result) (*ResultDiff, error) {
+	configChanges, err := getConfigChanges(base.GetDBConfigData(), target.GetDBConfigData())
+	if err != nil {
+		return nil, err
+	}
+	tableGrowths, err := getTableGrowths(base, target)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ResultDiff{
+		BaseOperationID:   base.GetOperationID(),
+		TargetOperationID: target.GetOperationID(),
+		ScoreDelta:        target.GetWeightedAverageScore() - base.GetWeightedAverageScore(),
+		ItemDiffs:         getItemScoreDiffs(base, target),
+		ConfigChanges:     configChanges,
+		TableGrowths:      tableGrowths,
+	}, nil
+}
+
+// getItemScores returns the scores of the available check items of the result, the key is the item name,
+// the item which was not checked or was unavailable in the operation is not included
+func getItemScores(result healthcheck.Result) map[string]int {
+	itemScores := make(map[string]int)
+	for _, item := range result.GetItems() {
+		if !item.IsAvailable() {
+			continue
+		}
+		itemScores[item.GetItemName()] = item.GetScore()
+	}
+
+	return itemScores
+}
+
+// getItemScoreDiffs compares the item scores of the results, the regressed items are sorted first,
+// the items which are only available in one of the results are reported as added or removed
+func getItemScoreDiffs(base, target healthcheck.Result) []*ItemScoreDiff {
+	baseScores := getItemScores(base)
+	targetScores := getItemScores(target)
+
+	itemDiffs := make([]*ItemScoreDiff, constant.ZeroInt, len(baseScores))
+	for itemName, baseScore := range baseScores {
+		targetScore, exists := targetScores[itemName]
+		if !exists {
+			itemDiffs = append(itemDiffs, &ItemScoreDiff{ItemName: itemName, Status: ItemDiffStatusRemoved, BaseScore: baseScore})
+			continue
+		}
+		delta := targetScore - baseScore
+		itemDiffs = append(itemDiffs, &ItemScoreDiff{
+			ItemName:    itemName,
+			Status:      ItemDiffStatusCompared,
+			BaseScore:   baseScore,
+			TargetScore: targetScore,
+			Delta:       delta,
+			Regressed:   delta < constant.ZeroInt,
+		})
+	}
+	for itemName, targetScore := range targetScores {
+		_, exists := baseScores[itemName]
+		if !exists {
+			itemDiffs = append(itemDiffs, &ItemScoreDiff{ItemName: itemName, Status: ItemDiffStatusAdded, TargetScore: targetScore})
+		}
+	}
+	sort.Slice(itemDiffs, func(i, j int) bool {
+		if itemDiffs[i].Delta != itemDiffs[j].Delta {
+			return itemDiffs[i].Delta < itemDiffs[j].Delta
+		}
+
+		return itemDiffs[i].ItemName < itemDiffs[j].ItemName
+	})
+
+	return itemDiffs
+}
+
+// getConfigChanges compares the database variables of the results and returns the variables which changed
+func getConfigChanges(baseData, targetData string) ([]*ConfigChange, error) {
+	baseValues, err := unmarshalGlobalVariables(baseData)
+	if err != nil {
+		return nil, err
+	}
+	targetValues, err := unmarshalGlobalVariables(targetData)
+	if err != nil {
+		return nil, err
+	}
+
+	configChanges := make([]*ConfigChange, constant.ZeroInt)
+	for variableName, baseValue := range baseValues {
+		targetValue, exists := targetValues[variableName]
+		if exists && targetValue == baseValue {
+			continue
+		}
+		configChanges = append(configChanges, &ConfigChange{VariableName: variableName, BaseValue: baseValue, TargetValue: targetValue})
+	}
+	for variableName, targetValue := range targetValues {
+		_, exists := baseValues[variableName]
+		if exists {
+			continue
+		}
+		configChanges = append(configChanges, &ConfigChange{VariableName: variableName, TargetValue: targetValue})
+	}
+	sort.Slice(configChanges, func(i, j int) bool {
+		return configChanges[i].VariableName < configChanges[j].VariableName
+	})
+
+	return configChanges, nil
+}
+
+// unmarshalGlobalVariables unmarshals the database configuration data to a map, the key is the variable name
+func unmarshalGlobalVariables(data string) (map[string]string, error) {
+	values := make(map[string]string)
+	if data == constant.EmptyString {
+		return values, nil
+	}
+
+	var variables []*GlobalVariable
+	err := json.Unmarshal([]byte(data), &variables)
+	if err != nil {
+		return nil, err
+	}
+	for _, variable := range variables {
+		values[variable.GetName()] = variable.GetValue()
+	}
+
+	return values, nil
+}
+
+// getTableGrowths compares the large tables of the results and returns the tables which grew, sorted by the size delta
+func getTableGrowths(base, target healthcheck.Result) ([]*TableGrowth, error) {
+	baseTables, err := unmarshalTables(base.GetTableRowsData(), base.GetTableSizeData())
+	if err != nil {
+		return nil, err
+	}
+	targetTables, err := unmarshalTables(target.GetTableRowsData(), target.GetTableSizeData())
+	if err != nil {
+		return nil, err
+	}
+
+	tableGrowths := make([]*TableGrowth, constant.ZeroInt)
+	for key, targetTable := range targetTables {
+		baseTable, exists := baseTables[key]
+		if !exists {
+			baseTable = NewTable(targetTable.TableSchema, targetTable.TableName, constant.ZeroInt, constant.ZeroInt)
+		}
+		if targetTable.TableRows <= baseTable.TableRows && targetTable.TableSize <= baseTable.TableSize {
+			continue
+		}
+		tableGrowths = append(tableGrowths, &TableGrowth{
+			TableSchema: targetTable.TableSchema,
+			TableName:   targetTable.TableName,
+			BaseRows:    baseTable.TableRows,
+			TargetRows:  targetTable.TableRows,
+			RowsDelta:   targetTable.TableRows - baseTable.TableRows,
+			BaseSize:    baseTable.TableSize,
+			TargetSize:  targetTable.TableSize,
+			SizeDelta:   targetTable.TableSize - baseTable.TableSize,
+		})
+	}
+	sort.Slice(tableGrowths, func(i, j int) bool {
+		if tableGrowths[i].SizeDelta != tableGrowths[j].SizeDelta {
+			return tableGrowths[i].SizeDelta > tableGrowths[j].SizeDelta
+		}
+
+		return tableGrowths[i].RowsDelta > tableGrowths[j].RowsDelta
+	})
+
+	return tableGrowths, nil
+}
+
+// unmarshalTables unmarshals the table rows data and the table size data to a map, the key is schema.table,
+// as both of the data are large tables, the table which exists in either of them will be kept
+func unmarshalTables(datas ...string) (map[string]*Table, error) {
+	tables := make(map[string]*Table)
+	for _, data := range datas {
+		if data == constant.EmptyString {
+			continue
+		}
+		var tableList []*Table
+		err := json.Unmarshal([]byte(data), &tableList)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tableList {
+			tables[fmt.Sprintf("%s.%s", table.TableSchema, table.TableName)] = table
+		}
+	}
+
+	return tables, nil
+}
@@ -0,0 +1,93 @@
+package healthcheck
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/healthcheck"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestTrendResult(operationID, score int, createTime time.Time, variables []*GlobalVariable, tables []*Table) *Result {
+	variablesBytes, _ := json.Marshal(variables)
+	tablesBytes, _ := json.Marshal(tables)
+
+	r := NewEmptyResult()
+	r.OperationID = operationID
+	r.WeightedAverageScore = score
+	r.setItemResult(NewItemResult(defaultDBConfigItemName, score, string(variablesBytes), "", ""))
+	r.setItemResult(NewItemResult(defaultCPUUsageItemName, score, "[]", "[]", ""))
+	r.setItemResult(NewItemResult(defaultTableRowsItemName, score, string(tablesBytes), "[]", ""))
+	r.setItemResult(NewItemResult(defaultTableSizeItemName, score, string(tablesBytes), "[]", ""))
+	r.CreateTime = createTime
+
+	return r
+}
+
+func TestTrendAll(t *testing.T) {
+	TestTrend_NewScoreTrend(t)
+	TestTrend_NewResultDiff(t)
+}
+
+func TestTrend_NewScoreTrend(t *testing.T) {
+	asst := assert.New(t)
+
+	now := time.Now()
+	results := []healthcheck.Result{
+		newTestTrendResult(2, 80, now, nil, nil),
+		newTestTrendResult(1, 90, now.Add(-time.Hour), nil, nil),
+	}
+	scoreTrend := NewScoreTrend(results)
+	asst.Equal(2, len(scoreTrend), "test NewScoreTrend() failed")
+	asst.Equal(1, scoreTrend[0].OperationID, "test NewScoreTrend() failed")
+	asst.Equal(90, scoreTrend[0].ItemScores[defaultDBConfigItemName], "test NewScoreTrend() failed")
+	// the items which were not checked are not taken as a score of 0
+	_, exists := scoreTrend[0].ItemScores[defaultReplicationItemName]
+	asst.False(exists, "test NewScoreTrend() failed")
+	asst.Equal(80, scoreTrend[1].WeightedAverageScore, "test NewScoreTrend() failed")
+}
+
+func TestTrend_NewResultDiff(t *testing.T) {
+	asst := assert.New(t)
+
+	base := newTestTrendResult(1, 90, time.Now(),
+		[]*GlobalVariable{
+			{VariableName: dbConfigSyncBinlog, VariableValue: "1"},
+			{VariableName: dbConfigLogBin, VariableValue: "ON"},
+		},
+		[]*Table{NewTable("db1", "t01", 100, 1), NewTable("db1", "t02", 100, 1)})
+	target := newTestTrendResult(2, 80, time.Now(),
+		[]*GlobalVariable{
+			{VariableName: dbConfigSyncBinlog, VariableValue: "0"},
+			{VariableName: dbConfigLogBin, VariableValue: "ON"},
+		},
+		[]*Table{NewTable("db1", "t01", 200, 3), NewTable("db1", "t02", 100, 1), NewTable("db1", "t03", 100, 1)})
+	base.setItemResult(NewItemResult(defaultReplicationItemName, 100, "{}", "[]", ""))
+	target.setItemResult(NewUnavailableItemResult(defaultReplicationItemName, errors.New("test error")))
+	target.setItemResult(NewItemResult(defaultIndexHealthItemName, 100, "[]", "", ""))
+
+	diff, err := NewResultDiff(base, target)
+	asst.Nil(err, common.CombineMessageWithError("test NewResultDiff() failed", err))
+	asst.Equal(-10, diff.ScoreDelta, "test NewResultDiff() failed")
+	asst.True(diff.ItemDiffs[0].Regressed, "test NewResultDiff() failed")
+	asst.Equal(defaultCPUUsageItemName, diff.ItemDiffs[0].ItemName, "test NewResultDiff() failed")
+	asst.False(diff.ItemDiffs[len(diff.ItemDiffs)-1].Regressed, "test NewResultDiff() failed")
+	itemDiffs := make(map[string]*ItemScoreDiff)
+	for _, itemDiff := range diff.ItemDiffs {
+		itemDiffs[itemDiff.ItemName] = itemDiff
+	}
+	asst.Equal(6, len(itemDiffs), "test NewResultDiff() failed")
+	// the unavailable item is reported as removed instead of regressed
+	asst.Equal(ItemDiffStatusRemoved, itemDiffs[defaultReplicationItemName].Status, "test NewResultDiff() failed")
+	asst.False(itemDiffs[defaultReplicationItemName].Regressed, "test NewResultDiff() failed")
+	asst.Equal(ItemDiffStatusAdded, itemDiffs[defaultIndexHealthItemName].Status, "test NewResultDiff() failed")
+	asst.Equal(1, len(diff.ConfigChanges), "test NewResultDiff() failed")
+	asst.Equal(dbConfigSyncBinlog, diff.ConfigChanges[0].VariableName, "test NewResultDiff() failed")
+	asst.Equal(2, len(diff.TableGrowths), "test NewResultDiff() failed")
+	asst.Equal("t01", diff.TableGrowths[0].TableName, "test NewResultDiff() failed")
+	asst.Equal(100, diff.TableGrowths[0].RowsDelta, "test NewResultDiff() failed")
+	asst.Equal("t03", diff.TableGrowths[1].TableName, "test NewResultDiff() failed")
+}
@@ -11,72 +11,16 @@ import (
 )
 
 const (
-	dbConfigMaxUserConnection         = "max_user_connection"
-	dbConfigLogBin                    = "log_bin"
-	dbConfigBinlogFormat              = "binlog_format"
-	dbConfigBinlogRowImage            = "binlog_row_image"
-	dbConfigSyncBinlog                = "sync_binlog"
-	dbConfigInnodbFlushLogAtTrxCommit = "innodb_flush_log_at_trx_commit"
-	dbConfigGTIDMode                  = "gtid_mode"
-	dbConfigEnforceGTIDConsistency    = "enforce_gtid_consistency"
-	dbConfigSlaveParallelType         = "slave_parallel_type"
-	dbConfigSlaveParallelWorkers      = "slave_parallel_workers"
-	dbConfigMasterInfoRepository      = "master_info_repository"
-	dbConfigRelayLogInfoRepository    = "relay_log_info_repository"
-	dbConfigReportHost                = "report_host"
-	dbConfigReportPort                = "report_port"
-	dbConfigInnodbFlushMethod         = "innodb_flush_method"
-	dbConfigInnodbMonitorEnable       = "innodb_monitor_enable"
-	dbConfigInnodbPrintAllDeadlocks   = "innodb_print_all_deadlocks"
-	dbConfigSlowQueryLog              = "slow_query_log"
-	dbConfigPerformanceSchema         = "performance_schema"
-
-	dbConfigMaxUserConnectionValid         = 2000
-	dbConfigLogBinValid                    = "ON"
-	dbConfigBinlogFormatValid              = "ROW"
-	dbConfigBinlogRowImageValid            = "FULL"
-	dbConfigSyncBinlogValid                = "1"
-	dbConfigInnodbFlushLogAtTrxCommitValid = "1"
-	dbConfigGTIDModeValid                  = "ON"
-	dbConfigEnforceGTIDConsistencyValid    = "ON"
-	dbConfigSlaveParallelTypeValid         = "LOGICAL_CLOCK"
-	dbConfigSlaveParallelWorkersValid      = "16"
-	dbConfigMasterInfoRepositoryValid      = "TABLE"
-	dbConfigRelayLogInfoRepositoryValid    = "TABLE"
-	dbConfigReportHostValid                = constant.EmptyString
-	dbConfigReportPortValid                = constant.EmptyString
-	dbConfigInnodbFlushMethodValid         = "O_DIRECT"
-	dbConfigInnodbMonitorEnableValid       = "all"
-	dbConfigInnodbPrintAllDeadlocksValid   = "ON"
-	dbConfigSlowQueryLogValid              = "ON"
-	dbConfigPerformanceSchemaValid         = "ON"
+	dbConfigLogBin     = "log_bin"
+	dbConfigSyncBinlog = "sync_binlog"
+	dbConfigReportHost = "report_host"
+	dbConfigReportPort = "report_port"
 )
 
 var (
 	_ healthcheck.Variable       = (*GlobalVariable)(nil)
 	_ healthcheck.Table          = (*Table)(nil)
 	_ healthcheck.PrometheusData = (*PrometheusData)(nil)
-
-	dbConfigVariableNames = map[string]string{
-		dbConfigLogBin:                    dbConfigLogBinValid,
-		dbConfigBinlogFormat:              dbConfigBinlogFormatValid,
-		dbConfigBinlogRowImage:            dbConfigBinlogRowImageValid,
-		dbConfigSyncBinlog:                dbConfigSyncBinlogValid,
-		dbConfigInnodbFlushLogAtTrxCommit: dbConfigInnodbFlushLogAtTrxCommitValid,
-		dbConfigGTIDMode:                  dbConfigGTIDModeValid,
-		dbConfigEnforceGTIDConsistency:    dbConfigEnforceGTIDConsistencyValid,
-		dbConfigSlaveParallelType:         dbConfigSlaveParallelTypeValid,
-		dbConfigSlaveParallelWorkers:      dbConfigSlaveParallelWorkersValid,
-		dbConfigMasterInfoRepository:      dbConfigMasterInfoRepositoryValid,
-		dbConfigRelayLogInfoRepository:    dbConfigRelayLogInfoRepositoryValid,
-		dbConfigReportHost:                dbConfigReportHostValid,
-		dbConfigReportPort:                dbConfigReportPortValid,
-		dbConfigInnodbFlushMethod:         dbConfigInnodbFlushMethodValid,
-		dbConfigInnodbMonitorEnable:       dbConfigInnodbMonitorEnableValid,
-		dbConfigInnodbPrintAllDeadlocks:   dbConfigInnodbPrintAllDeadlocksValid,
-		dbConfigSlowQueryLog:              dbConfigSlowQueryLogValid,
-		dbConfigPerformanceSchema:         dbConfigPerformanceSchemaValid,
-	}
 )
 
 type OperationInfo struct {
@@ -127,6 +71,7 @@ func (oi *OperationInfo) GetStep() time.Duration {
 // DefaultItemConfig include all data for a item
 type DefaultItemConfig struct {
 	ID                          int       `middleware:"id" json:"id"`
+	ConfigVersion               int       `middleware:"config_version" json:"config_version"`
 	ItemName                    string    `middleware:"item_name" json:"item_name"`
 	ItemWeight                  int       `middleware:"item_weight" json:"item_weight"`
 	LowWatermark                float64   `middleware:"low_watermark" json:"low_watermark"`
@@ -136,6 +81,7 @@ type DefaultItemConfig struct {
 	MaxScoreDeductionHigh       float64   `middleware:"max_score_deduction_high" json:"max_score_deduction_high"`
 	ScoreDeductionPerUnitMedium float64   `middleware:"score_deduction_per_unit_medium" json:"score_deduction_per_unit_medium"`
 	MaxScoreDeductionMedium     float64   `middleware:"max_score_deduction_medium" json:"max_score_deduction_medium"`
+	ScoringStrategy             string    `middleware:"scoring_strategy" json:"scoring_strategy"`
 	DelFlag                     int       `middleware:"del_flag" json:"del_flag"`
 	CreateTime                  time.Time `middleware:"create_time" json:"create_time"`
 	LastUpdateTime              time.Time `middleware:"last_update_time" json:"last_update_time"`
@@ -167,6 +113,11 @@ func (dic *DefaultItemConfig) GetID() int {
 	return dic.ID
 }
 
+// GetConfigVersion returns the config version
+func (dic *DefaultItemConfig) GetConfigVersion() int {
+	return dic.ConfigVersion
+}
+
 // GetItemName returns the item name
 func (dic *DefaultItemConfig) GetItemName() string {
 	return dic.ItemName
@@ -199,7 +150,7 @@ func (dic *DefaultItemConfig) GetScoreDeductionPerUnitHigh() float64 {
 
 // GetMaxScoreDeductionHigh returns the max score deduction high
 func (dic *DefaultItemConfig) GetMaxScoreDeductionHigh() float64 {
-	return dic.ScoreDeductionPerUnitHigh
+	return dic.MaxScoreDeductionHigh
 }
 
 // GetScoreDeductionPerUnitMedium returns the score deduction per unit medium
@@ -212,6 +163,11 @@ func (dic *DefaultItemConfig) GetMaxScoreDeductionMedium() float64 {
 	return dic.MaxScoreDeductionMedium
 }
 
+// GetScoringStrategy returns the scoring strategy
+func (dic *DefaultItemConfig) GetScoringStrategy() string {
+	return dic.ScoringStrategy
+}
+
 // GetDelFlag returns the delete flag
 func (dic *DefaultItemConfig) GetDelFlag() int {
 	return dic.DelFlag
@@ -245,6 +201,18 @@ func (dec DefaultEngineConfig) getItemConfig(item string) *DefaultItemConfig {
 	return dec[item]
 }
 
+// GetVersion returns the config version, all the item configs of the engine config have the same version
+func (dec DefaultEngineConfig) GetVersion() int {
+	version := constant.ZeroInt
+	for _, defaultItemConfig := range dec {
+		if defaultItemConfig.ConfigVersion > version {
+			version = defaultItemConfig.ConfigVersion
+		}
+	}
+
+	return version
+}
+
 // Validate validates if engine configuration is valid
 func (dec DefaultEngineConfig) Validate() error {
 	itemWeightSummary := constant.ZeroInt
@@ -261,12 +229,14 @@ func (dec DefaultEngineConfig) Validate() error {
 		if defaultItemConfig.LowWatermark < constant.ZeroInt {
 			return message.NewMessage(msghc.ErrLowWatermarkItemInvalid, itemName, defaultItemConfig.LowWatermark)
 		}
-		// validate high watermark
-		if defaultItemConfig.HighWatermark < defaultItemConfig.LowWatermark {
+		// validate high watermark, both watermarks are 0 if the item does not use the watermarks
+		if defaultItemConfig.HighWatermark < defaultItemConfig.LowWatermark ||
+			(defaultItemConfig.HighWatermark == defaultItemConfig.LowWatermark && defaultItemConfig.HighWatermark != constant.ZeroInt) {
 			return message.NewMessage(msghc.ErrHighWatermarkItemInvalid, itemName, defaultItemConfig.HighWatermark)
 		}
-		// validate unit
-		if defaultItemConfig.Unit < constant.ZeroInt {
+		// validate unit, the item which uses the watermarks divides the values by the unit, so the unit must be positive
+		if defaultItemConfig.Unit < constant.ZeroInt ||
+			(defaultItemConfig.HighWatermark != constant.ZeroInt && defaultItemConfig.Unit == constant.ZeroInt) {
 			return message.NewMessage(msghc.ErrUnitItemInvalid, itemName, defaultItemConfig.Unit)
 		}
 		// validate score deduction per unit high
@@ -285,6 +255,10 @@ func (dec DefaultEngineConfig) Validate() error {
 		if defaultItemConfig.MaxScoreDeductionMedium > defaultHundred || defaultItemConfig.MaxScoreDeductionMedium < constant.ZeroInt {
 			return message.NewMessage(msghc.ErrMaxScoreDeductionMediumItemInvalid, itemName, defaultItemConfig.MaxScoreDeductionMedium)
 		}
+		// validate scoring strategy
+		if !isValidScoringStrategy(defaultItemConfig.ScoringStrategy) {
+			return message.NewMessage(msghc.ErrScoringStrategyItemInvalid, itemName, defaultItemConfig.ScoringStrategy)
+		}
 		itemWeightSummary += defaultItemConfig.ItemWeight
 	}
 	// validate item weigh count is 100
@@ -323,9 +297,11 @@ func (gv *GlobalVariable) GetValue() string {
 }
 
 type Variable struct {
-	Name   string `middleware:"name" json:"name"`
-	Value  string `middleware:"value" json:"value"`
-	Advice string `middleware:"advice" json:"advice"`
+	Name          string `middleware:"name" json:"name"`
+	Value         string `middleware:"value" json:"value"`
+	Advice        string `middleware:"advice" json:"advice"`
+	ExpectedValue string `middleware:"expected_value" json:"expected_value"`
+	Severity      int    `middleware:"severity" json:"severity"`
 }
 
 func NewVariable(variableName, currentValue, advice string) *Variable {
@@ -336,6 +312,23 @@ func NewVariable(variableName, currentValue, advice string) *Variable {
 	}
 }
 
+// NewInvalidVariable returns a new *Variable which does not satisfy the db config rule,
+// the expected value will be used as the advice if the rule does not have any advice
+func NewInvalidVariable(rule healthcheck.DBConfigRule, currentValue, expectedValue string) *Variable {
+	advice := rule.GetAdvice()
+	if advice == constant.EmptyString {
+		advice = expectedValue
+	}
+
+	return &Variable{
+		Name:          rule.GetVariableName(),
+		Value:         currentValue,
+		Advice:        advice,
+		ExpectedValue: expectedValue,
+		Severity:      rule.GetSeverity(),
+	}
+}
+
 type Table struct {
 	TableSchema string  `middleware:"table_schema" json:"table_schema"`
 	TableName   string  `middleware:"table_name" json:"table_name"`
@@ -373,6 +366,7 @@ func (t *Table) GetSize() float64 {
 }
 
 type PrometheusData struct {
+	Series    string  `json:"series,omitempty"`
 	Timestamp string  `middleware:"timestamp" json:"timestamp"`
 	Value     float64 `middleware:"value" json:"value"`
 }
@@ -384,10 +378,23 @@ func NewPrometheusData(ts string, value float64) *PrometheusData {
 	}
 }
 
+// NewPrometheusDataWithSeries returns a new *PrometheusData which belongs to given series
+func NewPrometheusDataWithSeries(series, ts string, value float64) *PrometheusData {
+	return &PrometheusData{
+		Series:    series,
+		Timestamp: ts,
+		Value:     value,
+	}
+}
+
 func NewEmptyPrometheusData() *PrometheusData {
 	return &PrometheusData{}
 }
 
+func (pd *PrometheusData) GetSeries() string {
+	return pd.Series
+}
+
 func (pd *PrometheusData) GetTimestamp() string {
 	return pd.Timestamp
 }
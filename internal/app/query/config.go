@@ -10,6 +10,7 @@ const (
 	defaultLimit    = 5
 	defaultOffset   = 0
 	minRowsExamined = 100000
+	defaultOrderBy  = OrderByRowsExaminedMax
 
 	maxDuration = 30 * constant.Day
 	maxLimit    = 100
@@ -17,11 +18,40 @@ const (
 
 type OrderType int
 
+// the metrics which the queries could be ordered by, the queries are always ordered descending
+const (
+	OrderByExecCount       = "exec_count"
+	OrderByTotalExecTime   = "total_exec_time"
+	OrderByAvgExecTime     = "avg_exec_time"
+	OrderByRowsExaminedMax = "rows_examined_max"
+	OrderByTotalLockTime   = "total_lock_time"
+	OrderByRowsSentSum     = "rows_sent_sum"
+	OrderByP99ExecTime     = "p99_exec_time"
+	OrderByTmpTableCount   = "tmp_table_count"
+	OrderByFullScanCount   = "full_scan_count"
+	OrderByErrorCount      = "error_count"
+)
+
+// validOrderBys is used to validate the order by, the order by is formatted into the sql, so it must be one of them
+var validOrderBys = map[string]bool{
+	OrderByExecCount:       true,
+	OrderByTotalExecTime:   true,
+	OrderByAvgExecTime:     true,
+	OrderByRowsExaminedMax: true,
+	OrderByTotalLockTime:   true,
+	OrderByRowsSentSum:     true,
+	OrderByP99ExecTime:     true,
+	OrderByTmpTableCount:   true,
+	OrderByFullScanCount:   true,
+	OrderByErrorCount:      true,
+}
+
 type Config struct {
 	startTime time.Time
 	endTime   time.Time
 	limit     int
 	offset    int
+	orderBy   string
 }
 
 func NewConfig(startTime, endTime time.Time, limit, offset int) *Config {
@@ -38,6 +68,7 @@ func newConfig(startTime, endTime time.Time, limit, offset int) *Config {
 		endTime:   endTime,
 		limit:     limit,
 		offset:    offset,
+		orderBy:   defaultOrderBy,
 	}
 }
 
@@ -57,6 +88,10 @@ func (c *Config) GetOffset() int {
 	return c.offset
 }
 
+func (c *Config) GetOrderBy() string {
+	return c.orderBy
+}
+
 func (c *Config) SetStartTime(startTime time.Time) {
 	c.startTime = startTime
 }
@@ -73,6 +108,10 @@ func (c *Config) SetOffset(offset int) {
 	c.offset = offset
 }
 
+func (c *Config) SetOrderBy(orderBy string) {
+	c.orderBy = orderBy
+}
+
 func (c *Config) IsValid() bool {
 	duration := c.GetEndTime().Sub(c.GetStartTime())
 	if duration > maxDuration {
@@ -83,5 +122,9 @@ func (c *Config) IsValid() bool {
 		return false
 	}
 
+	if !validOrderBys[c.GetOrderBy()] {
+		return false
+	}
+
 	return true
 }
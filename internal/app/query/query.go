@@ -2,6 +2,7 @@ package query
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/internal/app/metadata"
@@ -10,6 +11,7 @@ import (
 	"github.com/romberli/das/pkg/message"
 	msgquery "github.com/romberli/das/pkg/message/query"
 	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/go-util/middleware/clickhouse"
 	"github.com/romberli/go-util/middleware/mysql"
 	"github.com/romberli/log"
@@ -33,6 +35,14 @@ type Query struct {
 	TotalExecTime   float64 `middleware:"total_exec_time" json:"total_exec_time"`
 	AvgExecTime     float64 `middleware:"avg_exec_time" json:"avg_exec_time"`
 	RowsExaminedMax int     `middleware:"rows_examined_max" json:"rows_examined_max"`
+	// the following metrics may be not collected by the monitor system, they are nil in that case,
+	// they are set by setNullableMetrics() as the middleware could not map null to the struct
+	TotalLockTime *float64 `json:"total_lock_time"`
+	RowsSentSum   *int     `json:"rows_sent_sum"`
+	P99ExecTime   *float64 `json:"p99_exec_time"`
+	TmpTableCount *int     `json:"tmp_table_count"`
+	FullScanCount *int     `json:"full_scan_count"`
+	ErrorCount    *int     `json:"error_count"`
 }
 
 // NewEmptyQuery return *Query
@@ -40,6 +50,26 @@ func NewEmptyQuery() *Query {
 	return &Query{}
 }
 
+// NewQueryWithQuery returns a new *Query which copies given query.Query
+func NewQueryWithQuery(q query.Query) *Query {
+	return &Query{
+		SQLID:           q.GetSQLID(),
+		Fingerprint:     q.GetFingerprint(),
+		Example:         q.GetExample(),
+		DBName:          q.GetDBName(),
+		ExecCount:       q.GetExecCount(),
+		TotalExecTime:   q.GetTotalExecTime(),
+		AvgExecTime:     q.GetAvgExecTime(),
+		RowsExaminedMax: q.GetRowsExaminedMax(),
+		TotalLockTime:   q.GetTotalLockTime(),
+		RowsSentSum:     q.GetRowsSentSum(),
+		P99ExecTime:     q.GetP99ExecTime(),
+		TmpTableCount:   q.GetTmpTableCount(),
+		FullScanCount:   q.GetFullScanCount(),
+		ErrorCount:      q.GetErrorCount(),
+	}
+}
+
 // GetSQLID returns the sql identity
 func (q *Query) GetSQLID() string {
 	return q.SQLID
@@ -80,26 +110,122 @@ func (q *Query) GetRowsExaminedMax() int {
 	return q.RowsExaminedMax
 }
 
-// Querier include config of query and connection pool of DAS repo
+// GetTotalLockTime returns the total lock time, it is nil if the monitor system does not collect it
+func (q *Query) GetTotalLockTime() *float64 {
+	return q.TotalLockTime
+}
+
+// GetRowsSentSum returns the total rows sent, it is nil if the monitor system does not collect it
+func (q *Query) GetRowsSentSum() *int {
+	return q.RowsSentSum
+}
+
+// GetP99ExecTime returns the 99th percentile execution time, it is nil if the monitor system does not collect it
+func (q *Query) GetP99ExecTime() *float64 {
+	return q.P99ExecTime
+}
+
+// GetTmpTableCount returns the number of the temporary tables, it is nil if the monitor system does not collect it
+func (q *Query) GetTmpTableCount() *int {
+	return q.TmpTableCount
+}
+
+// GetFullScanCount returns the number of the full scans, it is nil if the monitor system does not collect it
+func (q *Query) GetFullScanCount() *int {
+	return q.FullScanCount
+}
+
+// GetErrorCount returns the number of the executions with errors, it is nil if the monitor system does not collect it
+func (q *Query) GetErrorCount() *int {
+	return q.ErrorCount
+}
+
+// setNullableMetrics sets the nullable metrics with given row of the result
+func (q *Query) setNullableMetrics(result middleware.Result, row int) error {
+	var err error
+
+	q.TotalLockTime, err = getNullableFloat(result, row, OrderByTotalLockTime)
+	if err != nil {
+		return err
+	}
+	q.RowsSentSum, err = getNullableInt(result, row, OrderByRowsSentSum)
+	if err != nil {
+		return err
+	}
+	q.P99ExecTime, err = getNullableFloat(result, row, OrderByP99ExecTime)
+	if err != nil {
+		return err
+	}
+	q.TmpTableCount, err = getNullableInt(result, row, OrderByTmpTableCount)
+	if err != nil {
+		return err
+	}
+	q.FullScanCount, err = getNullableInt(result, row, OrderByFullScanCount)
+	if err != nil {
+		return err
+	}
+	q.ErrorCount, err = getNullableInt(result, row, OrderByErrorCount)
+
+	return err
+}
+
+// getNullableFloat returns the float value of given row and column of the result, it returns nil if the value is null
+func getNullableFloat(result middleware.Result, row int, column string) (*float64, error) {
+	isNull, err := result.IsNullByName(row, column)
+	if err != nil || isNull {
+		return nil, err
+	}
+	value, err := result.GetFloatByName(row, column)
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+// getNullableInt returns the int value of given row and column of the result, it returns nil if the value is null
+func getNullableInt(result middleware.Result, row int, column string) (*int, error) {
+	isNull, err := result.IsNullByName(row, column)
+	if err != nil || isNull {
+		return nil, err
+	}
+	value, err := result.GetIntByName(row, column)
+	if err != nil {
+		return nil, err
+	}
+
+	return &value, nil
+}
+
+// Querier include config of query and connection pool of DAS repo,
+// if the monitor repository is specified, it is used for all the queries instead of connecting to the monitor systems
 type Querier struct {
-	config  *Config
-	dasRepo *DASRepo
+	config      *Config
+	dasRepo     *DASRepo
+	monitorRepo query.MonitorRepo
 }
 
 // NewQuerier return *Querier
 func NewQuerier(config *Config, dasRepo *DASRepo) *Querier {
-	return newQuerier(config, dasRepo)
+	return newQuerier(config, dasRepo, nil)
 }
 
 // NewQuerierWithGlobal return *Querier with global DASRepo
 func NewQuerierWithGlobal(config *Config) *Querier {
-	return newQuerier(config, NewDASRepoWithGlobal())
+	return newQuerier(config, NewDASRepoWithGlobal(), nil)
+}
+
+// NewQuerierWithMonitorRepo returns *Querier with global DASRepo and given monitor repository,
+// the monitor repository is owned by the caller, the querier will not close it
+func NewQuerierWithMonitorRepo(config *Config, monitorRepo query.MonitorRepo) *Querier {
+	return newQuerier(config, NewDASRepoWithGlobal(), monitorRepo)
 }
 
-func newQuerier(config *Config, dasRepo *DASRepo) *Querier {
+func newQuerier(config *Config, dasRepo *DASRepo, monitorRepo query.MonitorRepo) *Querier {
 	return &Querier{
-		config:  config,
-		dasRepo: dasRepo,
+		config:      config,
+		dasRepo:     dasRepo,
+		monitorRepo: monitorRepo,
 	}
 }
 
@@ -138,12 +264,7 @@ func (q *Querier) GetByMySQLServerID(mysqlServerID int) ([]query.Query, error) {
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err = monitorRepo.Close()
-		if err != nil {
-			log.Error(message.NewMessage(msgquery.ErrQueryCloseMonitorRepo, err.Error()).Error())
-		}
-	}()
+	defer q.closeMonitorRepo(monitorRepo)
 	// get mysql server
 	mysqlServer, err := q.getMySQLServerByID(mysqlServerID)
 	if err != nil {
@@ -164,12 +285,7 @@ func (q *Querier) GetByDBID(mysqlServerID int, dbID int) ([]query.Query, error)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err = monitorRepo.Close()
-		if err != nil {
-			log.Error(message.NewMessage(msgquery.ErrQueryCloseMonitorRepo, err.Error()).Error())
-		}
-	}()
+	defer q.closeMonitorRepo(monitorRepo)
 	// get mysql server
 	mysqlServer, err := q.getMySQLServerByID(mysqlServerID)
 	if err != nil {
@@ -195,12 +311,7 @@ func (q *Querier) GetBySQLID(mysqlServerID int, sqlID string) ([]query.Query, er
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		err = monitorRepo.Close()
-		if err != nil {
-			log.Error(message.NewMessage(msgquery.ErrQueryCloseMonitorRepo, err.Error()).Error())
-		}
-	}()
+	defer q.closeMonitorRepo(monitorRepo)
 	// get mysql server
 	mysqlServer, err := q.getMySQLServerByID(mysqlServerID)
 	if err != nil {
@@ -212,6 +323,27 @@ func (q *Querier) GetBySQLID(mysqlServerID int, sqlID string) ([]query.Query, er
 	return []query.Query{queryResult}, err
 }
 
+// GetTrendBySQLID gets the time-bucketed series of the sql by mysql server id and sql id
+func (q *Querier) GetTrendBySQLID(mysqlServerID int, sqlID string, step time.Duration) ([]query.TrendPoint, error) {
+	// init monitor repos
+	monitorSystem, err := q.getMonitorSystemByMySQLServerID(mysqlServerID)
+	if err != nil {
+		return nil, err
+	}
+	monitorRepo, err := q.getMonitorRepo(monitorSystem)
+	if err != nil {
+		return nil, err
+	}
+	defer q.closeMonitorRepo(monitorRepo)
+	// get mysql server
+	mysqlServer, err := q.getMySQLServerByID(mysqlServerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return monitorRepo.GetTrendBySQLID(mysqlServer.GetServiceName(), sqlID, step)
+}
+
 func (q *Querier) getMySQLServersByClusterID(mysqlClusterID int) ([]depmeta.MySQLServer, error) {
 	service := metadata.NewMySQLServerServiceWithDefault()
 	err := service.GetByClusterID(mysqlClusterID)
@@ -272,7 +404,13 @@ func (q *Querier) getMonitorSystemByMySQLServerID(mysqlServerID int) (depmeta.Mo
 	return q.getMonitorSystemByMySQLClusterID(mysqlClusterID)
 }
 
+// getMonitorRepo returns the monitor repository of the querier if it is specified,
+// otherwise, it connects to given monitor system
 func (q *Querier) getMonitorRepo(monitorSystem depmeta.MonitorSystem) (query.MonitorRepo, error) {
+	if q.monitorRepo != nil {
+		return q.monitorRepo, nil
+	}
+
 	var monitorRepo query.MonitorRepo
 
 	addr := fmt.Sprintf("%s:%d", monitorSystem.GetHostIP(), monitorSystem.GetPortNumSlow())
@@ -298,6 +436,18 @@ func (q *Querier) getMonitorRepo(monitorSystem depmeta.MonitorSystem) (query.Mon
 	return monitorRepo, nil
 }
 
+// closeMonitorRepo closes the monitor repository which was connected by the querier
+func (q *Querier) closeMonitorRepo(monitorRepo query.MonitorRepo) {
+	if q.monitorRepo != nil {
+		return
+	}
+
+	err := monitorRepo.Close()
+	if err != nil {
+		log.Error(message.NewMessage(msgquery.ErrQueryCloseMonitorRepo, err.Error()).Error())
+	}
+}
+
 // getMonitorMySQLUser returns mysql username of monitor system
 func (q *Querier) getMonitorMySQLUser() string {
 	return viper.GetString(config.DBMonitorMySQLUserKey)
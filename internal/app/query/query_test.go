@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/romberli/das/config"
+	"github.com/romberli/das/internal/app/metadata"
+	"github.com/romberli/das/internal/dependency/query"
 	"github.com/romberli/go-util/common"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
@@ -27,14 +29,17 @@ const (
 	defaultQueryInfoTotalExecTime   = 2.1
 	defaultQueryInfoAvgExecTime     = 3.2
 	defaultQueryInfoRowsExaminedMax = 4
+	defaultQueryInfoTotalLockTime   = 0.05
+	defaultQueryInfoRowsSentSum     = 5
 
-	defaultQuerierPMM1MySQLClusterID = 1
-	defaultQuerierPMM1MySQLServerID  = 2
+	// the metadata of the pmm versions are initialized by test/sql/init.sql
+	defaultQuerierPMM1MySQLClusterID = 2
+	defaultQuerierPMM1MySQLServerID  = 3
 	defaultQuerierPMM1DBID           = 1
 	defaultQuerierPMM1SQLID          = "999ECD050D719733"
 
-	defaultQuerierPMM2MySQLClusterID = 3
-	defaultQuerierPMM2MySQLServerID  = 4
+	defaultQuerierPMM2MySQLClusterID = 1
+	defaultQuerierPMM2MySQLServerID  = 1
 	defaultQuerierPMM2DBID           = 2
 	defaultQuerierPMM2SQLID          = "999ECD050D719733"
 )
@@ -88,6 +93,9 @@ func TestQueryAll(t *testing.T) {
 	TestQuery_GetTotalExecTime(t)
 	TestQuery_GetAvgExecTime(t)
 	TestQuery_GetRowsExaminedMax(t)
+	TestQuery_GetTotalLockTime(t)
+	TestQuery_GetRowsSentSum(t)
+	TestQuery_GetP99ExecTime(t)
 
 	// Test PMM1.x
 	pmmVersion = 1
@@ -102,18 +110,25 @@ func TestQueryAll(t *testing.T) {
 	TestQuerier_GetByMySQLServerID(t)
 	TestQuerier_GetByDBID(t)
 	TestQuerier_GetBySQLID(t)
+
+	TestQuerier_getMonitorRepo(t)
 }
 
 func initNewQueryInfo() *Query {
+	totalLockTime := defaultQueryInfoTotalLockTime
+	rowsSentSum := defaultQueryInfoRowsSentSum
+
 	return &Query{
-		defaultQueryInfoSQLID,
-		defaultQueryInfoFingerprint,
-		defaultQueryInfoExample,
-		defaultQueryInfoDBName,
-		defaultQueryInfoExecCount,
-		defaultQueryInfoTotalExecTime,
-		defaultQueryInfoAvgExecTime,
-		defaultQueryInfoRowsExaminedMax,
+		SQLID:           defaultQueryInfoSQLID,
+		Fingerprint:     defaultQueryInfoFingerprint,
+		Example:         defaultQueryInfoExample,
+		DBName:          defaultQueryInfoDBName,
+		ExecCount:       defaultQueryInfoExecCount,
+		TotalExecTime:   defaultQueryInfoTotalExecTime,
+		AvgExecTime:     defaultQueryInfoAvgExecTime,
+		RowsExaminedMax: defaultQueryInfoRowsExaminedMax,
+		TotalLockTime:   &totalLockTime,
+		RowsSentSum:     &rowsSentSum,
 	}
 }
 
@@ -165,11 +180,31 @@ func TestQuery_GetRowsExaminedMax(t *testing.T) {
 	queryInfo := initNewQueryInfo()
 	asst.Equal(defaultQueryInfoRowsExaminedMax, queryInfo.GetRowsExaminedMax(), "test GetUserName() failed")
 }
+func TestQuery_GetTotalLockTime(t *testing.T) {
+	asst := assert.New(t)
+
+	queryInfo := initNewQueryInfo()
+	asst.Equal(defaultQueryInfoTotalLockTime, *queryInfo.GetTotalLockTime(), "test GetTotalLockTime() failed")
+}
+func TestQuery_GetRowsSentSum(t *testing.T) {
+	asst := assert.New(t)
+
+	queryInfo := initNewQueryInfo()
+	asst.Equal(defaultQueryInfoRowsSentSum, *queryInfo.GetRowsSentSum(), "test GetRowsSentSum() failed")
+}
+func TestQuery_GetP99ExecTime(t *testing.T) {
+	asst := assert.New(t)
+
+	// pmm 1.x does not collect the percentiles
+	queryInfo := initNewQueryInfo()
+	asst.Nil(queryInfo.GetP99ExecTime(), "test GetP99ExecTime() failed")
+}
 
 func TestQuerier_GetByMySQLClusterID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
-	querier := NewQuerierWithGlobal(NewConfigWithDefault())
+	querier := NewQuerierWithMonitorRepo(NewConfigWithDefault(), getTestMonitorRepo())
 
 	querierMySQLClusterID := 0
 	switch pmmVersion {
@@ -189,6 +224,7 @@ func TestQuerier_GetByMySQLClusterID(t *testing.T) {
 }
 
 func TestQuerier_GetByMySQLServerID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	querierMySQLServerID := 0
@@ -202,7 +238,7 @@ func TestQuerier_GetByMySQLServerID(t *testing.T) {
 		asst.Nil(err, common.CombineMessageWithError("test GetByMySQLServerID() failed", err))
 	}
 
-	querier := NewQuerierWithGlobal(NewConfigWithDefault())
+	querier := NewQuerierWithMonitorRepo(NewConfigWithDefault(), getTestMonitorRepo())
 	queries, err := querier.GetByMySQLServerID(querierMySQLServerID)
 	asst.Nil(err, common.CombineMessageWithError("test GetByMySQLServerID() failed", err))
 
@@ -210,6 +246,7 @@ func TestQuerier_GetByMySQLServerID(t *testing.T) {
 }
 
 func TestQuerier_GetByDBID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	querierDBID := 0
@@ -219,20 +256,21 @@ func TestQuerier_GetByDBID(t *testing.T) {
 		querierMySQLServerID = defaultQuerierPMM1MySQLServerID
 		querierDBID = defaultQuerierPMM1DBID
 	case 2:
-		querierMySQLServerID = defaultQuerierPMM1MySQLServerID
+		querierMySQLServerID = defaultQuerierPMM2MySQLServerID
 		querierDBID = defaultQuerierPMM2DBID
 	default:
 		err := fmt.Errorf("PMM with version:%d is not supported for now", pmmVersion)
 		asst.Nil(err, common.CombineMessageWithError("test GetByDBID() failed", err))
 	}
 
-	querier := NewQuerierWithGlobal(NewConfigWithDefault())
+	querier := NewQuerierWithMonitorRepo(NewConfigWithDefault(), getTestMonitorRepo())
 	queries, err := querier.GetByDBID(querierMySQLServerID, querierDBID)
 	asst.Nil(err, common.CombineMessageWithError("test GetByDBID() failed", err))
 
 	asst.NotZero(len(queries), "test GetByDBID() failed")
 }
 func TestQuerier_GetBySQLID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	querierSQLID := ""
@@ -242,16 +280,30 @@ func TestQuerier_GetBySQLID(t *testing.T) {
 		querierMySQLServerID = defaultQuerierPMM1MySQLServerID
 		querierSQLID = defaultQuerierPMM1SQLID
 	case 2:
-		querierMySQLServerID = defaultQuerierPMM1MySQLServerID
+		querierMySQLServerID = defaultQuerierPMM2MySQLServerID
 		querierSQLID = defaultQuerierPMM2SQLID
 	default:
 		err := fmt.Errorf("PMM with version:%d is not supported for now", pmmVersion)
 		asst.Nil(err, common.CombineMessageWithError("test GetByDBID() failed", err))
 	}
 
-	querier := NewQuerierWithGlobal(NewConfigWithDefault())
+	querier := NewQuerierWithMonitorRepo(NewConfigWithDefault(), getTestMonitorRepo())
 	queries, err := querier.GetBySQLID(querierMySQLServerID, querierSQLID)
 	asst.Nil(err, common.CombineMessageWithError("test GetBySQLID() failed", err))
 
 	asst.NotZero(len(queries), "test GetBySQLID() failed")
 }
+
+func TestQuerier_getMonitorRepo(t *testing.T) {
+	asst := assert.New(t)
+
+	// the monitor system is not reachable, the querier must not connect to it
+	monitorSystem := &metadata.MonitorSystemInfo{MonitorSystemType: 1, MonitorSystemHostIP: "127.0.0.1", MonitorSystemPortNumSlow: 1}
+	monitorRepo := &testMonitorRepo{queries: []query.Query{initNewQueryInfo()}}
+	querier := NewQuerierWithMonitorRepo(NewConfigWithDefault(), monitorRepo)
+	repo, err := querier.getMonitorRepo(monitorSystem)
+	asst.Nil(err, common.CombineMessageWithError("test getMonitorRepo() failed", err))
+	asst.Equal(monitorRepo, repo, "test getMonitorRepo() failed")
+	querier.closeMonitorRepo(repo)
+	asst.False(monitorRepo.closed, "test getMonitorRepo() failed")
+}
@@ -0,0 +1,228 @@
+package query
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/das/pkg/message"
+	msgquery "github.com/romberli/das/pkg/message/query"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	recordMethodGetByServiceNames = "GetByServiceNames"
+	recordMethodGetByDBName       = "GetByDBName"
+	recordMethodGetBySQLID        = "GetBySQLID"
+	recordMethodGetTrendBySQLID   = "GetTrendBySQLID"
+
+	recorderGoldenFileMode = 0644
+	recorderJSONIndent     = "  "
+)
+
+var (
+	_ query.MonitorRepo = (*RecordingMonitorRepo)(nil)
+	_ query.MonitorRepo = (*ReplayMonitorRepo)(nil)
+)
+
+// getRecordKey returns the key of the recorded response, it consists of the method name and the arguments
+func getRecordKey(method string, args ...string) string {
+	return fmt.Sprintf("%s(%s)", method, strings.Join(args, constant.CommaString))
+}
+
+// getTrendRecordKey returns the key of the recorded time-bucketed series, the step is in seconds
+func getTrendRecordKey(serviceName, sqlID string, step time.Duration) string {
+	return getRecordKey(recordMethodGetTrendBySQLID, serviceName, sqlID, strconv.Itoa(int(step/time.Second)))
+}
+
+// RecordingMonitorRepo records the responses of the monitor repository, the responses could be saved as a golden file
+// and replayed with ReplayMonitorRepo later
+type RecordingMonitorRepo struct {
+	repo         query.MonitorRepo
+	mutex        sync.Mutex
+	Records      map[string][]*Query      `json:"records"`
+	TrendRecords map[string][]*TrendPoint `json:"trend_records"`
+}
+
+// NewRecordingMonitorRepo returns a new *RecordingMonitorRepo which decorates given monitor repository
+func NewRecordingMonitorRepo(repo query.MonitorRepo) *RecordingMonitorRepo {
+	return &RecordingMonitorRepo{
+		repo:         repo,
+		Records:      make(map[string][]*Query),
+		TrendRecords: make(map[string][]*TrendPoint),
+	}
+}
+
+// Close closes the decorated repository
+func (rmr *RecordingMonitorRepo) Close() error {
+	return rmr.repo.Close()
+}
+
+// GetByServiceNames gets the query slice by the service names of the mysql servers and records it
+func (rmr *RecordingMonitorRepo) GetByServiceNames(serviceNames []string) ([]query.Query, error) {
+	queries, err := rmr.repo.GetByServiceNames(serviceNames)
+	if err != nil {
+		return nil, err
+	}
+
+	rmr.record(getRecordKey(recordMethodGetByServiceNames, serviceNames...), queries...)
+
+	return queries, nil
+}
+
+// GetByDBName gets the query slice by the service name and db name of the mysql server and records it
+func (rmr *RecordingMonitorRepo) GetByDBName(serviceName, dbName string) ([]query.Query, error) {
+	queries, err := rmr.repo.GetByDBName(serviceName, dbName)
+	if err != nil {
+		return nil, err
+	}
+
+	rmr.record(getRecordKey(recordMethodGetByDBName, serviceName, dbName), queries...)
+
+	return queries, nil
+}
+
+// GetBySQLID gets the query by the service name of the mysql server and sql identity and records it
+func (rmr *RecordingMonitorRepo) GetBySQLID(serviceName, sqlID string) (query.Query, error) {
+	q, err := rmr.repo.GetBySQLID(serviceName, sqlID)
+	if err != nil {
+		return nil, err
+	}
+
+	rmr.record(getRecordKey(recordMethodGetBySQLID, serviceName, sqlID), q)
+
+	return q, nil
+}
+
+// GetTrendBySQLID gets the time-bucketed series by the service name of the mysql server and sql identity and records it
+func (rmr *RecordingMonitorRepo) GetTrendBySQLID(serviceName, sqlID string, step time.Duration) ([]query.TrendPoint, error) {
+	points, err := rmr.repo.GetTrendBySQLID(serviceName, sqlID, step)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := make([]*TrendPoint, len(points))
+	for i, point := range points {
+		recorded[i] = NewTrendPointWithTrendPoint(point)
+	}
+
+	rmr.mutex.Lock()
+	defer rmr.mutex.Unlock()
+
+	rmr.TrendRecords[getTrendRecordKey(serviceName, sqlID, step)] = recorded
+
+	return points, nil
+}
+
+// Save saves the recorded responses to the golden file
+func (rmr *RecordingMonitorRepo) Save(fileName string) error {
+	rmr.mutex.Lock()
+	defer rmr.mutex.Unlock()
+
+	data, err := json.MarshalIndent(rmr, constant.EmptyString, recorderJSONIndent)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(fileName, data, recorderGoldenFileMode)
+}
+
+// record records the queries with given key, the previous response of the same key will be replaced
+func (rmr *RecordingMonitorRepo) record(key string, queries ...query.Query) {
+	recorded := make([]*Query, len(queries))
+	for i, q := range queries {
+		recorded[i] = NewQueryWithQuery(q)
+	}
+
+	rmr.mutex.Lock()
+	defer rmr.mutex.Unlock()
+
+	rmr.Records[key] = recorded
+}
+
+// ReplayMonitorRepo replays the responses which were recorded by RecordingMonitorRepo,
+// it returns an error if the request was not recorded
+type ReplayMonitorRepo struct {
+	Records      map[string][]*Query      `json:"records"`
+	TrendRecords map[string][]*TrendPoint `json:"trend_records"`
+}
+
+// NewReplayMonitorRepoWithFile reads the golden file and returns a new *ReplayMonitorRepo
+func NewReplayMonitorRepoWithFile(fileName string) (*ReplayMonitorRepo, error) {
+	data, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	rmr := &ReplayMonitorRepo{}
+	err = json.Unmarshal(data, rmr)
+	if err != nil {
+		return nil, err
+	}
+
+	return rmr, nil
+}
+
+// Close does nothing, there is no connection to close
+func (rmr *ReplayMonitorRepo) Close() error {
+	return nil
+}
+
+// GetByServiceNames returns the recorded query slice of the service names
+func (rmr *ReplayMonitorRepo) GetByServiceNames(serviceNames []string) ([]query.Query, error) {
+	return rmr.replay(getRecordKey(recordMethodGetByServiceNames, serviceNames...))
+}
+
+// GetByDBName returns the recorded query slice of the service name and db name
+func (rmr *ReplayMonitorRepo) GetByDBName(serviceName, dbName string) ([]query.Query, error) {
+	return rmr.replay(getRecordKey(recordMethodGetByDBName, serviceName, dbName))
+}
+
+// GetBySQLID returns the recorded query of the service name and sql identity
+func (rmr *ReplayMonitorRepo) GetBySQLID(serviceName, sqlID string) (query.Query, error) {
+	queries, err := rmr.replay(getRecordKey(recordMethodGetBySQLID, serviceName, sqlID))
+	if err != nil {
+		return nil, err
+	}
+	if len(queries) == constant.ZeroInt {
+		return nil, message.NewMessage(msgquery.ErrQueryResponseNotRecorded, getRecordKey(recordMethodGetBySQLID, serviceName, sqlID))
+	}
+
+	return queries[constant.ZeroInt], nil
+}
+
+// GetTrendBySQLID returns the recorded time-bucketed series of the service name, sql identity and step
+func (rmr *ReplayMonitorRepo) GetTrendBySQLID(serviceName, sqlID string, step time.Duration) ([]query.TrendPoint, error) {
+	key := getTrendRecordKey(serviceName, sqlID, step)
+	recorded, exists := rmr.TrendRecords[key]
+	if !exists {
+		return nil, message.NewMessage(msgquery.ErrQueryResponseNotRecorded, key)
+	}
+
+	points := make([]query.TrendPoint, len(recorded))
+	for i := range points {
+		points[i] = recorded[i]
+	}
+
+	return points, nil
+}
+
+// replay returns the recorded queries of given key
+func (rmr *ReplayMonitorRepo) replay(key string) ([]query.Query, error) {
+	recorded, exists := rmr.Records[key]
+	if !exists {
+		return nil, message.NewMessage(msgquery.ErrQueryResponseNotRecorded, key)
+	}
+
+	queries := make([]query.Query, len(recorded))
+	for i := range queries {
+		queries[i] = recorded[i]
+	}
+
+	return queries, nil
+}
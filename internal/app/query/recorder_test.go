@@ -0,0 +1,102 @@
+package query
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type testMonitorRepo struct {
+	queries []query.Query
+	points  []query.TrendPoint
+	closed  bool
+}
+
+func (tmr *testMonitorRepo) Close() error {
+	tmr.closed = true
+
+	return nil
+}
+
+func (tmr *testMonitorRepo) GetByServiceNames(serviceNames []string) ([]query.Query, error) {
+	return tmr.queries, nil
+}
+
+func (tmr *testMonitorRepo) GetByDBName(serviceName, dbName string) ([]query.Query, error) {
+	return tmr.queries[:1], nil
+}
+
+func (tmr *testMonitorRepo) GetBySQLID(serviceName, sqlID string) (query.Query, error) {
+	for _, q := range tmr.queries {
+		if q.GetSQLID() == sqlID {
+			return q, nil
+		}
+	}
+
+	return nil, errors.New("sql id not found")
+}
+
+func (tmr *testMonitorRepo) GetTrendBySQLID(serviceName, sqlID string, step time.Duration) ([]query.TrendPoint, error) {
+	return tmr.points, nil
+}
+
+func TestRecorderAll(t *testing.T) {
+	TestRecorder_RecordAndReplay(t)
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	asst := assert.New(t)
+
+	q1 := initNewQueryInfo()
+	q2 := initNewQueryInfo()
+	q2.SQLID = defaultQuerierPMM1SQLID
+	p1 := &TrendPoint{BucketTime: 1609430400, ExecCount: 10, TotalExecTime: 1.5, AvgExecTime: 0.15, RowsExaminedSum: 1000, RowsExaminedMax: 100}
+	p2 := &TrendPoint{BucketTime: 1609434000, ExecCount: 20, TotalExecTime: 6, AvgExecTime: 0.3, RowsExaminedSum: 2000, RowsExaminedMax: 100}
+	repo := NewRecordingMonitorRepo(&testMonitorRepo{queries: []query.Query{q1, q2}, points: []query.TrendPoint{p1, p2}})
+
+	serviceNames := []string{"192-168-10-219-3306", "192-168-10-220-3306"}
+	queries, err := repo.GetByServiceNames(serviceNames)
+	asst.Nil(err, common.CombineMessageWithError("test GetByServiceNames() with recording monitor repo failed", err))
+	asst.Equal(2, len(queries), "test GetByServiceNames() with recording monitor repo failed")
+	_, err = repo.GetByDBName(serviceNames[0], defaultQueryInfoDBName)
+	asst.Nil(err, common.CombineMessageWithError("test GetByDBName() with recording monitor repo failed", err))
+	_, err = repo.GetBySQLID(serviceNames[0], defaultQuerierPMM1SQLID)
+	asst.Nil(err, common.CombineMessageWithError("test GetBySQLID() with recording monitor repo failed", err))
+	_, err = repo.GetTrendBySQLID(serviceNames[0], defaultQuerierPMM1SQLID, time.Hour)
+	asst.Nil(err, common.CombineMessageWithError("test GetTrendBySQLID() with recording monitor repo failed", err))
+	// the failed request is not recorded
+	_, err = repo.GetBySQLID(serviceNames[0], "not_exists")
+	asst.NotNil(err, "test GetBySQLID() with recording monitor repo failed")
+
+	fileName := filepath.Join(t.TempDir(), "monitor_repo.json")
+	err = repo.Save(fileName)
+	asst.Nil(err, common.CombineMessageWithError("test Save() failed", err))
+
+	replay, err := NewReplayMonitorRepoWithFile(fileName)
+	asst.Nil(err, common.CombineMessageWithError("test NewReplayMonitorRepoWithFile() failed", err))
+	queries, err = replay.GetByServiceNames(serviceNames)
+	asst.Nil(err, common.CombineMessageWithError("test GetByServiceNames() with replay monitor repo failed", err))
+	asst.Equal([]query.Query{q1, q2}, queries, "test GetByServiceNames() with replay monitor repo failed")
+	queries, err = replay.GetByDBName(serviceNames[0], defaultQueryInfoDBName)
+	asst.Nil(err, common.CombineMessageWithError("test GetByDBName() with replay monitor repo failed", err))
+	asst.Equal([]query.Query{q1}, queries, "test GetByDBName() with replay monitor repo failed")
+	q, err := replay.GetBySQLID(serviceNames[0], defaultQuerierPMM1SQLID)
+	asst.Nil(err, common.CombineMessageWithError("test GetBySQLID() with replay monitor repo failed", err))
+	asst.Equal(q2, q, "test GetBySQLID() with replay monitor repo failed")
+
+	points, err := replay.GetTrendBySQLID(serviceNames[0], defaultQuerierPMM1SQLID, time.Hour)
+	asst.Nil(err, common.CombineMessageWithError("test GetTrendBySQLID() with replay monitor repo failed", err))
+	asst.Equal([]query.TrendPoint{p1, p2}, points, "test GetTrendBySQLID() with replay monitor repo failed")
+
+	_, err = replay.GetBySQLID(serviceNames[0], "not_exists")
+	asst.NotNil(err, "test GetBySQLID() with replay monitor repo failed")
+	_, err = replay.GetTrendBySQLID(serviceNames[0], defaultQuerierPMM1SQLID, time.Minute)
+	asst.NotNil(err, "test GetTrendBySQLID() with replay monitor repo failed")
+	_, err = replay.GetByDBName(serviceNames[1], defaultQueryInfoDBName)
+	asst.NotNil(err, "test GetByDBName() with replay monitor repo failed")
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/romberli/das/internal/app/metadata"
 	demetadata "github.com/romberli/das/internal/dependency/metadata"
 	"github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/das/pkg/message"
+	msgquery "github.com/romberli/das/pkg/message/query"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
 	"github.com/romberli/go-util/middleware"
@@ -25,13 +27,21 @@ const (
                m.exec_count,
                m.total_exec_time,
                m.avg_exec_time,
-               m.rows_examined_max
+               m.rows_examined_max,
+               m.total_lock_time,
+               m.rows_sent_sum,
+               null        as p99_exec_time,
+               null        as tmp_table_count,
+               null        as full_scan_count,
+               null        as error_count
         from (
                  select qcm.query_class_id,
                         sum(qcm.query_count)                                        as exec_count,
                         truncate(sum(qcm.query_time_sum), 2)                        as total_exec_time,
                         truncate(sum(qcm.query_time_sum) / sum(qcm.query_count), 2) as avg_exec_time,
-                        qcm.rows_examined_max
+                        qcm.rows_examined_max,
+                        truncate(sum(qcm.lock_time_sum), 6)                         as total_lock_time,
+                        cast(sum(qcm.rows_sent_sum) as signed)                      as rows_sent_sum
                  from query_class_metrics qcm
                           inner join instances i on qcm.instance_id = i.instance_id
                  where i.name in (%s)
@@ -39,10 +49,11 @@ const (
                    and qcm.start_ts < ?
 				   and qcm.rows_examined_max >= ?
                  group by qcm.query_class_id
-                 order by qcm.rows_examined_max desc
+                 order by %s desc
                   limit ? offset ?) m
                  inner join query_classes qc on m.query_class_id = qc.query_class_id
-                 left join query_examples qe on m.query_class_id = qe.query_class_id;
+                 left join query_examples qe on m.query_class_id = qe.query_class_id
+        order by m.%s desc;
     `
 	mysqlQueryWithDBName = `
         select qc.checksum as sql_id,
@@ -52,13 +63,21 @@ const (
                m.exec_count,
                m.total_exec_time,
                m.avg_exec_time,
-               m.rows_examined_max
+               m.rows_examined_max,
+               m.total_lock_time,
+               m.rows_sent_sum,
+               null        as p99_exec_time,
+               null        as tmp_table_count,
+               null        as full_scan_count,
+               null        as error_count
         from (
                  select qcm.query_class_id,
                         sum(qcm.query_count)                                        as exec_count,
                         truncate(sum(qcm.query_time_sum), 2)                        as total_exec_time,
                         truncate(sum(qcm.query_time_sum) / sum(qcm.query_count), 2) as avg_exec_time,
-                        qcm.rows_examined_max
+                        qcm.rows_examined_max,
+                        truncate(sum(qcm.lock_time_sum), 6)                         as total_lock_time,
+                        cast(sum(qcm.rows_sent_sum) as signed)                      as rows_sent_sum
                  from query_class_metrics qcm
                           inner join instances i on qcm.instance_id = i.instance_id
                  		  inner join query_examples qe on qcm.query_class_id = qe.query_class_id
@@ -68,10 +87,11 @@ const (
                    and qcm.start_ts < ?
 				   and qcm.rows_examined_max >= ?
                  group by qcm.query_class_id
-                 order by qcm.rows_examined_max desc
+                 order by %s desc
 				  limit ? offset ?) m
                  inner join query_classes qc on m.query_class_id = qc.query_class_id
-                 left join query_examples qe on m.query_class_id = qe.query_class_id;
+                 left join query_examples qe on m.query_class_id = qe.query_class_id
+        order by m.%s desc;
     `
 	mysqlQueryWithSQLID = `
         select qc.checksum as sql_id,
@@ -81,13 +101,21 @@ const (
                m.exec_count,
                m.total_exec_time,
                m.avg_exec_time,
-               m.rows_examined_max
+               m.rows_examined_max,
+               m.total_lock_time,
+               m.rows_sent_sum,
+               null        as p99_exec_time,
+               null        as tmp_table_count,
+               null        as full_scan_count,
+               null        as error_count
         from (
                  select qcm.query_class_id,
                         sum(qcm.query_count)                                        as exec_count,
                         truncate(sum(qcm.query_time_sum), 2)                        as total_exec_time,
                         truncate(sum(qcm.query_time_sum) / sum(qcm.query_count), 2) as avg_exec_time,
-                        qcm.rows_examined_max
+                        qcm.rows_examined_max,
+                        truncate(sum(qcm.lock_time_sum), 6)                         as total_lock_time,
+                        cast(sum(qcm.rows_sent_sum) as signed)                      as rows_sent_sum
                  from query_class_metrics qcm
                           inner join instances i on qcm.instance_id = i.instance_id
 						  inner join query_classes qc on qcm.query_class_id = qc.query_class_id
@@ -108,14 +136,26 @@ const (
                sm.exec_count,
                sm.total_exec_time,
                sm.avg_exec_time,
-               sm.rows_examined_max
+               sm.rows_examined_max,
+               sm.total_lock_time,
+               sm.rows_sent_sum,
+               sm.p99_exec_time,
+               sm.tmp_table_count,
+               sm.full_scan_count,
+               sm.error_count
         
         from (
                  select queryid                                               as sql_id,
                         sum(num_queries)                                      as exec_count,
                         truncate(sum(m_query_time_sum), 2)                    as total_exec_time,
                         truncate(sum(m_query_time_sum) / sum(num_queries), 2) as avg_exec_time,
-                        max(m_rows_examined_max)                              as rows_examined_max
+                        max(m_rows_examined_max)                              as rows_examined_max,
+                        truncate(sum(m_lock_time_sum), 6)                     as total_lock_time,
+                        toInt64(sum(m_rows_sent_sum))                         as rows_sent_sum,
+                        truncate(max(m_query_time_p99), 6)                    as p99_exec_time,
+                        toInt64(sum(m_tmp_tables_sum))                        as tmp_table_count,
+                        toInt64(sum(m_full_scan_sum))                         as full_scan_count,
+                        toInt64(sum(num_queries_with_errors))                 as error_count
                  from metrics
                  where service_type = 'mysql'
                    and service_name in (%s)
@@ -123,7 +163,7 @@ const (
                    and period_start < ?
                    and m_rows_examined_max >= ?
                  group by queryid
-                 order by rows_examined_max desc
+                 order by %s desc
                  limit ? offset ? ) sm
                  left join (select queryid          as sql_id,
                                    max(fingerprint) as fingerprint,
@@ -136,7 +176,8 @@ const (
                               and period_start < ?
                               and m_rows_examined_max >= ?
                             group by queryid) m
-                           on sm.sql_id = m.sql_id;
+                           on sm.sql_id = m.sql_id
+        order by sm.%s desc;
     `
 	clickhouseQueryWithDBName = `
         select sm.sql_id,
@@ -146,14 +187,26 @@ const (
                sm.exec_count,
                sm.total_exec_time,
                sm.avg_exec_time,
-               sm.rows_examined_max
+               sm.rows_examined_max,
+               sm.total_lock_time,
+               sm.rows_sent_sum,
+               sm.p99_exec_time,
+               sm.tmp_table_count,
+               sm.full_scan_count,
+               sm.error_count
         
         from (
                  select queryid                                               as sql_id,
                         sum(num_queries)                                      as exec_count,
                         truncate(sum(m_query_time_sum), 2)                    as total_exec_time,
                         truncate(sum(m_query_time_sum) / sum(num_queries), 2) as avg_exec_time,
-                        max(m_rows_examined_max)                              as rows_examined_max
+                        max(m_rows_examined_max)                              as rows_examined_max,
+                        truncate(sum(m_lock_time_sum), 6)                     as total_lock_time,
+                        toInt64(sum(m_rows_sent_sum))                         as rows_sent_sum,
+                        truncate(max(m_query_time_p99), 6)                    as p99_exec_time,
+                        toInt64(sum(m_tmp_tables_sum))                        as tmp_table_count,
+                        toInt64(sum(m_full_scan_sum))                         as full_scan_count,
+                        toInt64(sum(num_queries_with_errors))                 as error_count
                  from metrics
                  where service_type = 'mysql'
                    and service_name in (%s)
@@ -162,7 +215,7 @@ const (
                    and period_start < ?
                    and m_rows_examined_max >= ?
                  group by queryid
-                 order by rows_examined_max desc
+                 order by %s desc
                  limit ? offset ? ) sm
                  left join (select queryid          as sql_id,
                                    max(fingerprint) as fingerprint,
@@ -176,7 +229,8 @@ const (
                               and period_start < ?
                               and m_rows_examined_max >= ?
                             group by queryid) m
-                           on sm.sql_id = m.sql_id;
+                           on sm.sql_id = m.sql_id
+        order by sm.%s desc;
     `
 	clickhouseQueryWithSQLID = `
         select sm.sql_id,
@@ -186,14 +240,26 @@ const (
                sm.exec_count,
                sm.total_exec_time,
                sm.avg_exec_time,
-               sm.rows_examined_max
+               sm.rows_examined_max,
+               sm.total_lock_time,
+               sm.rows_sent_sum,
+               sm.p99_exec_time,
+               sm.tmp_table_count,
+               sm.full_scan_count,
+               sm.error_count
         
         from (
                  select queryid                                               as sql_id,
                         sum(num_queries)                                      as exec_count,
                         truncate(sum(m_query_time_sum), 2)                    as total_exec_time,
                         truncate(sum(m_query_time_sum) / sum(num_queries), 2) as avg_exec_time,
-                        max(m_rows_examined_max)                              as rows_examined_max
+                        max(m_rows_examined_max)                              as rows_examined_max,
+                        truncate(sum(m_lock_time_sum), 6)                     as total_lock_time,
+                        toInt64(sum(m_rows_sent_sum))                         as rows_sent_sum,
+                        truncate(max(m_query_time_p99), 6)                    as p99_exec_time,
+                        toInt64(sum(m_tmp_tables_sum))                        as tmp_table_count,
+                        toInt64(sum(m_full_scan_sum))                         as full_scan_count,
+                        toInt64(sum(num_queries_with_errors))                 as error_count
                  from metrics
                  where service_type = 'mysql'
                    and service_name in (%s)
@@ -202,7 +268,7 @@ const (
                    and period_start < ?
                    and m_rows_examined_max >= ?
                  group by queryid
-                 order by rows_examined_max desc
+                 order by %s desc
                  limit ? offset ? ) sm
                  left join (select queryid          as sql_id,
                                    max(fingerprint) as fingerprint,
@@ -218,6 +284,39 @@ const (
                             group by queryid) m
                            on sm.sql_id = m.sql_id;
     `
+	mysqlTrendWithSQLID = `
+        select floor(unix_timestamp(qcm.start_ts) / ?) * ?                 as bucket_time,
+               sum(qcm.query_count)                                        as exec_count,
+               truncate(sum(qcm.query_time_sum), 6)                        as total_exec_time,
+               truncate(sum(qcm.query_time_sum) / sum(qcm.query_count), 6) as avg_exec_time,
+               cast(sum(qcm.rows_examined_sum) as signed)                  as rows_examined_sum,
+               max(qcm.rows_examined_max)                                  as rows_examined_max
+        from query_class_metrics qcm
+                 inner join instances i on qcm.instance_id = i.instance_id
+                 inner join query_classes qc on qcm.query_class_id = qc.query_class_id
+        where i.name in (%s)
+          and qc.checksum = ?
+          and qcm.start_ts >= ?
+          and qcm.start_ts < ?
+        group by bucket_time
+        order by bucket_time;
+    `
+	clickhouseTrendWithSQLID = `
+        select toInt64(intDiv(toUnixTimestamp(period_start), ?) * ?) as bucket_time,
+               sum(num_queries)                                      as exec_count,
+               truncate(sum(m_query_time_sum), 6)                    as total_exec_time,
+               truncate(sum(m_query_time_sum) / sum(num_queries), 6) as avg_exec_time,
+               toInt64(sum(m_rows_examined_sum))                     as rows_examined_sum,
+               max(m_rows_examined_max)                              as rows_examined_max
+        from metrics
+        where service_type = 'mysql'
+          and service_name in (%s)
+          and queryid = ?
+          and period_start >= ?
+          and period_start < ?
+        group by bucket_time
+        order by bucket_time;
+    `
 )
 
 var _ query.DASRepo = (*DASRepo)(nil)
@@ -299,7 +398,7 @@ func (r *DASRepo) GetMonitorSystemByClusterID(clusterID int) (demetadata.Monitor
 	monitorSystemID := mcs.GetMonitorSystemID()
 
 	monitorSystemInfo := metadata.NewMonitorSystemServiceWithDefault()
-	err = clusterInfo.GetByID(monitorSystemID)
+	err = monitorSystemInfo.GetByID(monitorSystemID)
 	if err != nil {
 		return nil, err
 	}
@@ -333,6 +432,17 @@ func (mr *MySQLRepo) getConfig() *Config {
 	return mr.config
 }
 
+// getOrderBy returns the order by of the config, pmm 1.x does not collect some metrics, so the queries could not be ordered by them
+func (mr *MySQLRepo) getOrderBy() (string, error) {
+	orderBy := mr.getConfig().GetOrderBy()
+	switch orderBy {
+	case OrderByP99ExecTime, OrderByTmpTableCount, OrderByFullScanCount, OrderByErrorCount:
+		return constant.EmptyString, message.NewMessage(msgquery.ErrQueryOrderByNotSupported, orderBy)
+	default:
+		return orderBy, nil
+	}
+}
+
 // Close closes the connection
 func (mr *MySQLRepo) Close() error {
 	return mr.conn.Close()
@@ -350,7 +460,12 @@ func (mr *MySQLRepo) GetByServiceNames(serviceName []string) ([]query.Query, err
 		return nil, err
 	}
 
-	sql := fmt.Sprintf(mysqlQueryWithServiceNames, services)
+	orderBy, err := mr.getOrderBy()
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf(mysqlQueryWithServiceNames, services, orderBy, orderBy)
 
 	return mr.execute(sql,
 		mr.getConfig().GetStartTime().Format(constant.DefaultTimeLayout),
@@ -373,7 +488,12 @@ func (mr *MySQLRepo) GetByDBName(serviceName, dbName string) ([]query.Query, err
 		return nil, err
 	}
 
-	sql := fmt.Sprintf(mysqlQueryWithDBName, services)
+	orderBy, err := mr.getOrderBy()
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf(mysqlQueryWithDBName, services, orderBy, orderBy)
 
 	return mr.execute(sql,
 		dbName,
@@ -409,6 +529,42 @@ func (mr *MySQLRepo) GetBySQLID(serviceName, sqlID string) (query.Query, error)
 	return queries[constant.ZeroInt], err
 }
 
+// GetTrendBySQLID returns the time-bucketed series of the sql by SQL ID, the buckets are aligned to the multiples of the step since the unix epoch
+func (mr *MySQLRepo) GetTrendBySQLID(serviceName, sqlID string, step time.Duration) ([]query.TrendPoint, error) {
+	interfaces, err := common.ConvertInterfaceToSliceInterface([]string{serviceName})
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := middleware.ConvertSliceToString(interfaces...)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf(mysqlTrendWithSQLID, services)
+	stepSeconds := int64(step / time.Second)
+
+	return mr.executeTrend(sql,
+		stepSeconds,
+		stepSeconds,
+		sqlID,
+		mr.getConfig().GetStartTime().Format(constant.DefaultTimeLayout),
+		mr.getConfig().GetEndTime().Format(constant.DefaultTimeLayout),
+	)
+}
+
+// executeTrend executes the SQL with args and returns the trend points
+func (mr *MySQLRepo) executeTrend(command string, args ...interface{}) ([]query.TrendPoint, error) {
+	log.Debugf("query MySQLRepo.executeTrend() sql: %s, args: %v", command, args)
+
+	result, err := mr.conn.Execute(command, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTrendPointsWithResult(result)
+}
+
 // execute executes the SQL with args
 func (mr *MySQLRepo) execute(command string, args ...interface{}) ([]query.Query, error) {
 	log.Debugf("query MySQLRepo.execute() sql: %s, args: %v", command, args)
@@ -418,18 +574,8 @@ func (mr *MySQLRepo) execute(command string, args ...interface{}) ([]query.Query
 	if err != nil {
 		return nil, err
 	}
-	// init queries
-	queries := make([]query.Query, result.RowNumber())
-	for i := constant.ZeroInt; i < result.RowNumber(); i++ {
-		queries[i] = NewEmptyQuery()
-	}
-	// map result to queries
-	err = result.MapToStructSlice(queries, constant.DefaultMiddlewareTag)
-	if err != nil {
-		return nil, err
-	}
 
-	return queries, nil
+	return getQueriesWithResult(result)
 }
 
 type ClickhouseRepo struct {
@@ -467,7 +613,8 @@ func (cr *ClickhouseRepo) GetByServiceNames(serviceNames []string) ([]query.Quer
 		return nil, err
 	}
 
-	sql := fmt.Sprintf(clickhouseQueryWithServiceNames, services, services)
+	orderBy := cr.getConfig().GetOrderBy()
+	sql := fmt.Sprintf(clickhouseQueryWithServiceNames, services, orderBy, services, orderBy)
 
 	return cr.execute(
 		sql,
@@ -494,7 +641,8 @@ func (cr *ClickhouseRepo) GetByDBName(serviceName, dbName string) ([]query.Query
 		return nil, err
 	}
 
-	sql := fmt.Sprintf(clickhouseQueryWithDBName, services, services)
+	orderBy := cr.getConfig().GetOrderBy()
+	sql := fmt.Sprintf(clickhouseQueryWithDBName, services, orderBy, services, orderBy)
 
 	return cr.execute(sql,
 		dbName,
@@ -524,7 +672,7 @@ func (cr *ClickhouseRepo) GetBySQLID(serviceName, sqlID string) (query.Query, er
 		return nil, err
 	}
 
-	sql := fmt.Sprintf(clickhouseQueryWithSQLID, services, services)
+	sql := fmt.Sprintf(clickhouseQueryWithSQLID, services, cr.getConfig().GetOrderBy(), services)
 
 	queries, err := cr.execute(sql,
 		sqlID,
@@ -545,6 +693,42 @@ func (cr *ClickhouseRepo) GetBySQLID(serviceName, sqlID string) (query.Query, er
 	return queries[constant.ZeroInt], err
 }
 
+// GetTrendBySQLID returns the time-bucketed series of the sql by SQL ID, the buckets are aligned to the multiples of the step since the unix epoch
+func (cr *ClickhouseRepo) GetTrendBySQLID(serviceName, sqlID string, step time.Duration) ([]query.TrendPoint, error) {
+	interfaces, err := common.ConvertInterfaceToSliceInterface([]string{serviceName})
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := middleware.ConvertSliceToString(interfaces...)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf(clickhouseTrendWithSQLID, services)
+	stepSeconds := int64(step / time.Second)
+
+	return cr.executeTrend(sql,
+		stepSeconds,
+		stepSeconds,
+		sqlID,
+		cr.getConfig().GetStartTime(),
+		cr.getConfig().GetEndTime(),
+	)
+}
+
+// executeTrend executes the SQL with args and returns the trend points
+func (cr *ClickhouseRepo) executeTrend(command string, args ...interface{}) ([]query.TrendPoint, error) {
+	log.Debugf("query ClickhouseRepo.executeTrend() sql: %s, args: %v", command, args)
+
+	result, err := cr.conn.Execute(command, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return getTrendPointsWithResult(result)
+}
+
 func (cr *ClickhouseRepo) execute(command string, args ...interface{}) ([]query.Query, error) {
 	log.Debugf("query ClickhouseRepo.execute() sql: %s, args: %v", command, args)
 
@@ -553,16 +737,52 @@ func (cr *ClickhouseRepo) execute(command string, args ...interface{}) ([]query.
 	if err != nil {
 		return nil, err
 	}
+
+	return getQueriesWithResult(result)
+}
+
+// getQueriesWithResult maps the result of the monitor database to the queries
+func getQueriesWithResult(result middleware.Result) ([]query.Query, error) {
 	// init queries
-	queries := make([]query.Query, result.RowNumber())
+	queryList := make([]*Query, result.RowNumber())
 	for i := constant.ZeroInt; i < result.RowNumber(); i++ {
-		queries[i] = NewEmptyQuery()
+		queryList[i] = NewEmptyQuery()
 	}
 	// map result to queries
-	err = result.MapToStructSlice(queries, constant.DefaultMiddlewareTag)
+	err := result.MapToStructSlice(queryList, constant.DefaultMiddlewareTag)
 	if err != nil {
 		return nil, err
 	}
 
+	queries := make([]query.Query, len(queryList))
+	for i := range queries {
+		err = queryList[i].setNullableMetrics(result, i)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = queryList[i]
+	}
+
 	return queries, nil
 }
+
+// getTrendPointsWithResult maps the result of the monitor database to the trend points
+func getTrendPointsWithResult(result middleware.Result) ([]query.TrendPoint, error) {
+	// init trend points
+	pointList := make([]*TrendPoint, result.RowNumber())
+	for i := range pointList {
+		pointList[i] = NewEmptyTrendPoint()
+	}
+	// map result to trend points
+	err := result.MapToStructSlice(pointList, constant.DefaultMiddlewareTag)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]query.TrendPoint, len(pointList))
+	for i := range points {
+		points[i] = pointList[i]
+	}
+
+	return points, nil
+}
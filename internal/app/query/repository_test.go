@@ -35,6 +35,7 @@ func TestQueryRepositoryAll(t *testing.T) {
 }
 
 func TestDASRepo_GetMonitorSystemByDBID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	dbInfo := metadata.NewDBServiceWithDefault()
@@ -50,6 +51,7 @@ func TestDASRepo_GetMonitorSystemByDBID(t *testing.T) {
 }
 
 func TestDASRepo_GetMonitorSystemByClusterID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	clusterInfo := metadata.NewMySQLClusterServiceWithDefault()
@@ -65,6 +67,7 @@ func TestDASRepo_GetMonitorSystemByClusterID(t *testing.T) {
 }
 
 func TestDASRepo_GetMonitorSystemByMySQLServerID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	serverInfo := metadata.NewMySQLServerServiceWithDefault()
@@ -80,6 +83,7 @@ func TestDASRepo_GetMonitorSystemByMySQLServerID(t *testing.T) {
 }
 
 func TestMySQLRepo_GetByServiceNames(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
 	serverInfo := metadata.NewMySQLServerServiceWithDefault()
@@ -87,8 +91,8 @@ func TestMySQLRepo_GetByServiceNames(t *testing.T) {
 	ss := serverInfo.MySQLServers[constant.ZeroInt]
 	serverName := ss.GetServerName()
 
-	var mr *MySQLRepo
-	qu, err := mr.GetByServiceNames([]string{serverName})
+	// the responses of the pmm 1.x are replayed, or recorded in record mode
+	qu, err := testMonitorRepos[testPMM1].GetByServiceNames([]string{serverName})
 	asst.Equal(nil, err, "test MySQLRepo_GetByServiceNames Failed")
 	asst.Equal(true, qu != nil, "test MySQLRepo_GetByServiceNames Failed")
 }
@@ -96,8 +100,8 @@ func TestMySQLRepo_GetByServiceNames(t *testing.T) {
 func TestMySQLRepo_GetByDBName(t *testing.T) {
 	asst := assert.New(t)
 
-	var mr *MySQLRepo
-	qu, err := mr.GetByDBName(testServiceName, testDbName)
+	// the responses of the pmm 1.x are replayed, or recorded in record mode
+	qu, err := testMonitorRepos[testPMM1].GetByDBName(testServiceName, testDbName)
 	asst.Equal(nil, err, "test MySQLRepo_GetByDBName Failed")
 	asst.Equal(true, qu != nil, "test MySQLRepo_GetByDBName Failed")
 }
@@ -105,8 +109,8 @@ func TestMySQLRepo_GetByDBName(t *testing.T) {
 func TestMySQLRepo_GetBySQLID(t *testing.T) {
 	asst := assert.New(t)
 
-	var mr *MySQLRepo
-	qu, err := mr.GetBySQLID(testServiceName, testSQLID)
+	// the responses of the pmm 1.x are replayed, or recorded in record mode
+	qu, err := testMonitorRepos[testPMM1].GetBySQLID(testServiceName, testSQLID)
 	asst.Equal(nil, err, "test MySQLRepo_GetBySQLID Failed")
 	asst.Equal(true, qu != nil, "test MySQLRepo_GetBySQLID Failed")
 }
@@ -114,8 +118,8 @@ func TestMySQLRepo_GetBySQLID(t *testing.T) {
 func TestClickhouseRepo_GetByDBName(t *testing.T) {
 	asst := assert.New(t)
 
-	var cr *ClickhouseRepo
-	qu, err := cr.GetByDBName(testServiceName, testDbName)
+	// the responses of the pmm 2.x are replayed, or recorded in record mode
+	qu, err := testMonitorRepos[testPMM2].GetByDBName(testServiceName, testDbName)
 	asst.Equal(nil, err, "test ClickhouseRepo_GetByDBName Failed")
 	asst.Equal(true, qu != nil, "test ClickhouseRepo_GetByDBName Failed")
 
@@ -124,8 +128,8 @@ func TestClickhouseRepo_GetByDBName(t *testing.T) {
 func TestClickhouseRepo_GetByServiceNames(t *testing.T) {
 	asst := assert.New(t)
 
-	var cr *ClickhouseRepo
-	qu, err := cr.GetByServiceNames([]string{testServiceName})
+	// the responses of the pmm 2.x are replayed, or recorded in record mode
+	qu, err := testMonitorRepos[testPMM2].GetByServiceNames([]string{testServiceName})
 	asst.Equal(nil, err, "test ClickhouseRepo_GetByServiceNamesFailed")
 	asst.Equal(true, qu != nil, "test ClickhouseRepo_GetByServiceNames Failed")
 }
@@ -133,8 +137,8 @@ func TestClickhouseRepo_GetByServiceNames(t *testing.T) {
 func TestClickhouseRepo_GetBySQLID(t *testing.T) {
 	asst := assert.New(t)
 
-	var cr *ClickhouseRepo
-	qu, err := cr.GetBySQLID(testServiceName, testSQLID)
+	// the responses of the pmm 2.x are replayed, or recorded in record mode
+	qu, err := testMonitorRepos[testPMM2].GetBySQLID(testServiceName, testSQLID)
 	asst.Equal(nil, err, "test ClickhouseRepo_GetBySQLID Failed")
 	asst.Equal(true, qu != nil, "test ClickhouseRepo_GetBySQLID Failed")
 }
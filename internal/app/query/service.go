@@ -1,6 +1,9 @@
 package query
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/romberli/das/internal/dependency/query"
 	"github.com/romberli/go-util/common"
 	"github.com/romberli/go-util/constant"
@@ -11,9 +14,11 @@ const queryQueriesStruct = "Queries"
 var _ query.Service = (*Service)(nil)
 
 type Service struct {
-	config  *Config
-	dasRepo *DASRepo
-	queries []query.Query
+	config      *Config
+	dasRepo     *DASRepo
+	monitorRepo query.MonitorRepo
+	queries     []query.Query
+	trend       *Trend
 }
 
 // NewService returns a new *Service
@@ -26,6 +31,15 @@ func NewServiceWithDefault(config *Config) *Service {
 	return newService(config, NewDASRepoWithGlobal())
 }
 
+// NewServiceWithMonitorRepo returns a new *Service which gets the queries from given monitor repository
+// instead of connecting to the monitor systems of the mysql servers
+func NewServiceWithMonitorRepo(config *Config, dasRepo *DASRepo, monitorRepo query.MonitorRepo) *Service {
+	s := newService(config, dasRepo)
+	s.monitorRepo = monitorRepo
+
+	return s
+}
+
 // newService returns a new *Service
 func newService(config *Config, dasRepo *DASRepo) *Service {
 	return &Service{
@@ -44,11 +58,16 @@ func (s *Service) GetQueries() []query.Query {
 	return s.queries
 }
 
+// GetTrend returns the trend of the sql
+func (s *Service) GetTrend() query.Trend {
+	return s.trend
+}
+
 // GetByMySQLClusterID gets the query slice by the mysql cluster identity
 func (s *Service) GetByMySQLClusterID(mysqlClusterID int) error {
 	var err error
 
-	querier := NewQuerierWithGlobal(s.GetConfig())
+	querier := s.getQuerier()
 	s.queries, err = querier.GetByMySQLClusterID(mysqlClusterID)
 	if err != nil {
 		return err
@@ -61,7 +80,7 @@ func (s *Service) GetByMySQLClusterID(mysqlClusterID int) error {
 func (s *Service) GetByMySQLServerID(mysqlServerID int) error {
 	var err error
 
-	querier := NewQuerierWithGlobal(s.GetConfig())
+	querier := s.getQuerier()
 	s.queries, err = querier.GetByMySQLServerID(mysqlServerID)
 	if err != nil {
 		return err
@@ -74,7 +93,7 @@ func (s *Service) GetByMySQLServerID(mysqlServerID int) error {
 func (s *Service) GetByDBID(mysqlServerID int, dbID int) error {
 	var err error
 
-	querier := NewQuerierWithGlobal(s.GetConfig())
+	querier := s.getQuerier()
 	s.queries, err = querier.GetByDBID(mysqlServerID, dbID)
 	if err != nil {
 		return err
@@ -87,7 +106,7 @@ func (s *Service) GetByDBID(mysqlServerID int, dbID int) error {
 func (s *Service) GetBySQLID(mysqlServerID int, sqlID string) error {
 	var err error
 
-	querier := NewQuerierWithGlobal(s.GetConfig())
+	querier := s.getQuerier()
 	s.queries, err = querier.GetBySQLID(mysqlServerID, sqlID)
 	if err != nil {
 		return err
@@ -96,6 +115,29 @@ func (s *Service) GetBySQLID(mysqlServerID int, sqlID string) error {
 	return s.Save(constant.DefaultRandomInt, mysqlServerID, constant.DefaultRandomInt, sqlID)
 }
 
+// GetTrendBySQLID gets the time-bucketed series of the sql in the time range of the config and detects the regression,
+// if step is zero, the time range is divided into 60 buckets, if splitTime is zero, the split time is detected from the series
+func (s *Service) GetTrendBySQLID(mysqlServerID int, sqlID string, step time.Duration, splitTime time.Time) error {
+	step, err := getTrendStep(s.GetConfig().GetStartTime(), s.GetConfig().GetEndTime(), step)
+	if err != nil {
+		return err
+	}
+
+	querier := s.getQuerier()
+	points, err := querier.GetTrendBySQLID(mysqlServerID, sqlID, step)
+	if err != nil {
+		return err
+	}
+	s.trend = NewTrend(sqlID, step, points, splitTime)
+
+	return s.Save(constant.DefaultRandomInt, mysqlServerID, constant.DefaultRandomInt, sqlID)
+}
+
+// getQuerier returns a new *Querier, it uses the monitor repository of the service if it is specified
+func (s *Service) getQuerier() *Querier {
+	return NewQuerierWithMonitorRepo(s.GetConfig(), s.monitorRepo)
+}
+
 // Save the query info into DAS repo
 func (s *Service) Save(mysqlClusterID, mysqlServerID, dbID int, sqlID string) error {
 
@@ -114,3 +156,8 @@ func (s *Service) MarshalWithFields(fields ...string) ([]byte, error) {
 
 	return common.MarshalStructWithFields(s, fields...)
 }
+
+// MarshalTrend marshals Service.Trend to json bytes
+func (s *Service) MarshalTrend() ([]byte, error) {
+	return json.Marshal(s.trend)
+}
@@ -1,14 +1,25 @@
 package query
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
 	"github.com/romberli/das/config"
 	"github.com/romberli/das/global"
+	"github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/das/pkg/util/replay"
 	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware/clickhouse"
 	"github.com/romberli/go-util/middleware/mysql"
 	"github.com/romberli/log"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
-	"testing"
 )
 
 const (
@@ -29,6 +40,18 @@ const (
 	serviceTestDBDBName = "das"
 	serviceTestDBDBUser = "root"
 	serviceTestDBDBPass = "root"
+
+	// the slow query addresses of the monitor systems
+	serviceTestPMM1Addr = "192.168.10.220:33061"
+	serviceTestPMM2Addr = "192.168.10.219:8123"
+
+	// the golden files of the das database and the monitor repositories
+	testDataDir            = "testdata"
+	testDASCassetteDir     = "das"
+	testMonitorRepoFileFmt = "monitor_repo_pmm%d.json"
+	testDASCassetteNameFmt = "%s_pmm%d"
+	testPMM1               = 1
+	testPMM2               = 2
 )
 
 var pmmVersion = 0
@@ -49,37 +72,157 @@ func init() {
 	}
 }
 
-func initQueryRepo() *DASRepo {
-	var err error
+func initQueryPool() (*mysql.Pool, error) {
 	dbAddr := serviceTestDBAddr
 	dbDBName := serviceTestDBDBName
 	dbDBUser := serviceTestDBDBUser
 	dbDBPass := serviceTestDBDBPass
-	global.DASMySQLPool, err = mysql.NewPoolWithDefault(dbAddr, dbDBName, dbDBUser, dbDBPass)
+
+	return mysql.NewPoolWithDefault(dbAddr, dbDBName, dbDBUser, dbDBPass)
+}
+
+func initNewQuery() *Query {
+	return &Query{
+		SQLID:           defaultQuerySQLID,
+		Fingerprint:     defaultQueryFingerprint,
+		Example:         defaultQueryExample,
+		DBName:          defaultQueryDBName,
+		ExecCount:       defaultQueryExecCount,
+		TotalExecTime:   defaultQueryTotalExecTime,
+		AvgExecTime:     defaultQueryAvgExecTime,
+		RowsExaminedMax: defaultQueryRowsExaminedMax,
+	}
+}
+
+var (
+	// record runs the tests against the live databases and records the golden files,
+	// otherwise the tests replay the golden files, so that they could run offline,
+	// e.g.: go test -v ./internal/app/query/ -record
+	record = flag.Bool("record", false, "run the tests against the live databases and record the golden files")
+
+	testDASPool replay.CassettePool
+	// testMonitorRepos are the monitor repositories of the pmm versions
+	testMonitorRepos map[int]query.MonitorRepo
+	service          *Service
+)
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	err := initTestRepos()
 	if err != nil {
-		log.Error(common.CombineMessageWithError("initQueryRepo() failed", err))
+		log.Error(common.CombineMessageWithError("TestMain() failed", err))
+		os.Exit(1)
+	}
+	service = createService()
+
+	code := m.Run()
+	if *record {
+		err = saveGoldenFiles()
+		if err != nil {
+			log.Error(common.CombineMessageWithError("TestMain() failed", err))
+			code = 1
+		}
+	}
+
+	os.Exit(code)
+}
+
+// initTestRepos initializes the das pool and the monitor repositories which replay the golden files, or record them in record mode
+func initTestRepos() error {
+	dir := filepath.Join(testDataDir, testDASCassetteDir)
+	testMonitorRepos = make(map[int]query.MonitorRepo)
+
+	if !*record {
+		testDASPool = replay.NewPool(dir)
+		global.DASMySQLPool = testDASPool
+		for _, version := range []int{testPMM1, testPMM2} {
+			monitorRepo, err := NewReplayMonitorRepoWithFile(getTestMonitorRepoFile(version))
+			if err != nil {
+				return err
+			}
+			testMonitorRepos[version] = monitorRepo
+		}
+
 		return nil
 	}
-	return newDASRepo(global.DASMySQLPool)
+
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	pool, err := initQueryPool()
+	if err != nil {
+		return err
+	}
+	testDASPool = replay.NewRecordingPool(pool, dir)
+	global.DASMySQLPool = testDASPool
+
+	mysqlConn, err := mysql.NewConn(serviceTestPMM1Addr, pmmMySQLDBName, viper.GetString(config.DBMonitorMySQLUserKey), viper.GetString(config.DBMonitorMySQLPassKey))
+	if err != nil {
+		return err
+	}
+	testMonitorRepos[testPMM1] = NewRecordingMonitorRepo(NewMySQLRepo(NewConfigWithDefault(), mysqlConn))
+	clickhouseConn, err := clickhouse.NewConnWithDefault(serviceTestPMM2Addr, pmmClickhouseDBName,
+		viper.GetString(config.DBMonitorClickhouseUserKey), viper.GetString(config.DBMonitorClickhousePassKey))
+	if err != nil {
+		return err
+	}
+	testMonitorRepos[testPMM2] = NewRecordingMonitorRepo(NewClickHouseRepo(NewConfigWithDefault(), clickhouseConn))
+
+	return nil
 }
 
-func initNewQuery() *Query {
-	return &Query{
-		defaultQuerySQLID,
-		defaultQueryFingerprint,
-		defaultQueryExample,
-		defaultQueryDBName,
-		defaultQueryExecCount,
-		defaultQueryTotalExecTime,
-		defaultQueryAvgExecTime,
-		defaultQueryRowsExaminedMax,
+// getTestMonitorRepoFile returns the golden file of the monitor repository of given pmm version
+func getTestMonitorRepoFile(version int) string {
+	return filepath.Join(testDataDir, fmt.Sprintf(testMonitorRepoFileFmt, version))
+}
+
+// getTestMonitorRepo returns the monitor repository of the pmm version which is under test
+func getTestMonitorRepo() query.MonitorRepo {
+	return testMonitorRepos[pmmVersion]
+}
+
+// insertDASCassette inserts the cassette of the calling test into the das pool and ejects it when the test finishes,
+// the tests are also called by the TestXxxAll tests with the same *testing.T, so the cassette is named after the calling function,
+// the tests query the different mysql servers of the pmm versions, so the cassette is also named after the pmm version
+func insertDASCassette(t *testing.T) {
+	pc, _, _, _ := runtime.Caller(1)
+	name := runtime.FuncForPC(pc).Name()
+	name = fmt.Sprintf(testDASCassetteNameFmt, name[strings.LastIndex(name, constant.DotString)+1:], pmmVersion)
+
+	err := testDASPool.Insert(name)
+	if err != nil {
+		t.Fatal(common.CombineMessageWithError("insert das cassette failed", err))
 	}
+	t.Cleanup(func() {
+		err := testDASPool.Eject()
+		if err != nil {
+			t.Error(common.CombineMessageWithError("eject das cassette failed", err))
+		}
+	})
 }
 
-var service = createService()
+// saveGoldenFiles saves the cassette which is still inserted and the recorded responses of the monitor repositories
+func saveGoldenFiles() error {
+	err := testDASPool.Eject()
+	if err != nil {
+		return err
+	}
+
+	for version, monitorRepo := range testMonitorRepos {
+		err = monitorRepo.(*RecordingMonitorRepo).Save(getTestMonitorRepoFile(version))
+		if err != nil {
+			return err
+		}
+	}
 
+	return nil
+}
+
+// createService returns a new *Service which gets the queries from the monitor repository of the pmm version which is under test
 func createService() *Service {
-	return newService(NewConfigWithDefault(), initQueryRepo())
+	return NewServiceWithMonitorRepo(NewConfigWithDefault(), NewDASRepoWithGlobal(), getTestMonitorRepo())
 }
 
 func TestService_All(t *testing.T) {
@@ -109,8 +252,11 @@ func TestService_GetQueries(t *testing.T) {
 }
 
 func TestService_GetByMySQLClusterID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
+	service := createService()
+
 	switch pmmVersion {
 	case 1:
 		err := service.GetByMySQLClusterID(2)
@@ -122,8 +268,11 @@ func TestService_GetByMySQLClusterID(t *testing.T) {
 }
 
 func TestService_GetByMySQLServerID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
+	service := createService()
+
 	switch pmmVersion {
 	case 1:
 		err := service.GetByMySQLServerID(3)
@@ -135,8 +284,11 @@ func TestService_GetByMySQLServerID(t *testing.T) {
 }
 
 func TestService_GetByDBID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
+	service := createService()
+
 	switch pmmVersion {
 	case 1:
 		err := service.GetByDBID(3, 1)
@@ -148,11 +300,14 @@ func TestService_GetByDBID(t *testing.T) {
 }
 
 func TestService_GetBySQLID(t *testing.T) {
+	insertDASCassette(t)
 	asst := assert.New(t)
 
+	service := createService()
+
 	switch pmmVersion {
 	case 1:
-		err := service.GetBySQLID(6, "999ECD050D719733")
+		err := service.GetBySQLID(3, "999ECD050D719733")
 		asst.Nil(err, common.CombineMessageWithError("test GetBySQLID() failed", err))
 	case 2:
 		err := service.GetBySQLID(2, "999ECD050D719733")
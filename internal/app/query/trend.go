@@ -0,0 +1,280 @@
+package query
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/das/pkg/message"
+	msgquery "github.com/romberli/das/pkg/message/query"
+	"github.com/romberli/go-util/constant"
+)
+
+const (
+	// defaultTrendBucketNum is the number of the buckets when the step is not specified
+	defaultTrendBucketNum = 60
+	// maxTrendBucketNum is the maximum number of the buckets of a trend
+	maxTrendBucketNum = 1440
+	// minTrendStep is the minimum step of the buckets, the query metrics of the monitor system are collected every minute
+	minTrendStep = time.Minute
+	// minRegressionBucketNum is the minimum number of the buckets on each side of the split time to detect the regression
+	minRegressionBucketNum = 2
+	// regressedRatio is the ratio of the average execution time which means the sql regressed
+	regressedRatio = 2.0
+	// improvedRatio is the ratio of the average execution time which means the sql improved
+	improvedRatio = 0.5
+
+	RegressionStatusRegressed    = "regressed"
+	RegressionStatusImproved     = "improved"
+	RegressionStatusUnchanged    = "unchanged"
+	RegressionStatusInsufficient = "insufficient"
+)
+
+var (
+	_ query.TrendPoint = (*TrendPoint)(nil)
+	_ query.Regression = (*Regression)(nil)
+	_ query.Trend      = (*Trend)(nil)
+)
+
+// TrendPoint is the metrics of a sql in a bucket, the bucket time is the unix time in seconds
+type TrendPoint struct {
+	BucketTime      int64   `middleware:"bucket_time" json:"bucket_time"`
+	ExecCount       int     `middleware:"exec_count" json:"exec_count"`
+	TotalExecTime   float64 `middleware:"total_exec_time" json:"total_exec_time"`
+	AvgExecTime     float64 `middleware:"avg_exec_time" json:"avg_exec_time"`
+	RowsExaminedSum int     `middleware:"rows_examined_sum" json:"rows_examined_sum"`
+	RowsExaminedMax int     `middleware:"rows_examined_max" json:"rows_examined_max"`
+}
+
+// NewEmptyTrendPoint returns a new empty *TrendPoint
+func NewEmptyTrendPoint() *TrendPoint {
+	return &TrendPoint{}
+}
+
+// NewTrendPointWithTrendPoint returns a new *TrendPoint which copies given query.TrendPoint
+func NewTrendPointWithTrendPoint(tp query.TrendPoint) *TrendPoint {
+	return &TrendPoint{
+		BucketTime:      tp.GetBucketTime().Unix(),
+		ExecCount:       tp.GetExecCount(),
+		TotalExecTime:   tp.GetTotalExecTime(),
+		AvgExecTime:     tp.GetAvgExecTime(),
+		RowsExaminedSum: tp.GetRowsExaminedSum(),
+		RowsExaminedMax: tp.GetRowsExaminedMax(),
+	}
+}
+
+// GetBucketTime returns the start time of the bucket
+func (tp *TrendPoint) GetBucketTime() time.Time {
+	return time.Unix(tp.BucketTime, constant.ZeroInt)
+}
+
+// GetExecCount returns the execution count in the bucket
+func (tp *TrendPoint) GetExecCount() int {
+	return tp.ExecCount
+}
+
+// GetTotalExecTime returns the total execution time in the bucket
+func (tp *TrendPoint) GetTotalExecTime() float64 {
+	return tp.TotalExecTime
+}
+
+// GetAvgExecTime returns the average execution time in the bucket
+func (tp *TrendPoint) GetAvgExecTime() float64 {
+	return tp.AvgExecTime
+}
+
+// GetRowsExaminedSum returns the total rows examined in the bucket
+func (tp *TrendPoint) GetRowsExaminedSum() int {
+	return tp.RowsExaminedSum
+}
+
+// GetRowsExaminedMax returns the maximum rows examined in the bucket
+func (tp *TrendPoint) GetRowsExaminedMax() int {
+	return tp.RowsExaminedMax
+}
+
+// Regression compares the average execution time of the sql before and after the split time,
+// the split time is the unix time in seconds
+type Regression struct {
+	SplitTime         int64   `json:"split_time"`
+	Detected          bool    `json:"detected"`
+	BeforeExecCount   int     `json:"before_exec_count"`
+	AfterExecCount    int     `json:"after_exec_count"`
+	BeforeAvgExecTime float64 `json:"before_avg_exec_time"`
+	AfterAvgExecTime  float64 `json:"after_avg_exec_time"`
+	Ratio             float64 `json:"ratio"`
+	Status            string  `json:"status"`
+}
+
+// NewRegressionWithSplitTime compares the points before the split time with the points after it
+func NewRegressionWithSplitTime(points []query.TrendPoint, splitTime time.Time) *Regression {
+	index := sort.Search(len(points), func(i int) bool {
+		return !points[i].GetBucketTime().Before(splitTime)
+	})
+
+	r := newRegression(points, index)
+	r.SplitTime = splitTime.Unix()
+
+	return r
+}
+
+// NewRegressionWithDetection detects the split time of the points, it is the bucket time which changes the average execution time most,
+// at least 2 buckets are required on each side of the split time
+func NewRegressionWithDetection(points []query.TrendPoint) *Regression {
+	var best *Regression
+	for i := minRegressionBucketNum; i <= len(points)-minRegressionBucketNum; i++ {
+		r := newRegression(points, i)
+		if r.Status == RegressionStatusInsufficient {
+			continue
+		}
+		if best == nil || math.Abs(math.Log(r.Ratio)) > math.Abs(math.Log(best.Ratio)) {
+			best = r
+		}
+	}
+	if best == nil {
+		return &Regression{Detected: true, Status: RegressionStatusInsufficient}
+	}
+	best.Detected = true
+
+	return best
+}
+
+// newRegression compares the points before the index with the points since the index, the points must be sorted by the bucket time
+func newRegression(points []query.TrendPoint, index int) *Regression {
+	r := &Regression{Status: RegressionStatusInsufficient}
+	if index < len(points) {
+		r.SplitTime = points[index].GetBucketTime().Unix()
+	}
+	if index < minRegressionBucketNum || len(points)-index < minRegressionBucketNum {
+		return r
+	}
+
+	var beforeExecTime, afterExecTime float64
+	for i, point := range points {
+		if i < index {
+			r.BeforeExecCount += point.GetExecCount()
+			beforeExecTime += point.GetTotalExecTime()
+			continue
+		}
+		r.AfterExecCount += point.GetExecCount()
+		afterExecTime += point.GetTotalExecTime()
+	}
+	if r.BeforeExecCount == constant.ZeroInt || r.AfterExecCount == constant.ZeroInt || beforeExecTime <= constant.ZeroInt || afterExecTime <= constant.ZeroInt {
+		return r
+	}
+
+	r.BeforeAvgExecTime = beforeExecTime / float64(r.BeforeExecCount)
+	r.AfterAvgExecTime = afterExecTime / float64(r.AfterExecCount)
+	r.Ratio = r.AfterAvgExecTime / r.BeforeAvgExecTime
+	switch {
+	case r.Ratio >= regressedRatio:
+		r.Status = RegressionStatusRegressed
+	case r.Ratio <= improvedRatio:
+		r.Status = RegressionStatusImproved
+	default:
+		r.Status = RegressionStatusUnchanged
+	}
+
+	return r
+}
+
+// GetSplitTime returns the time which splits the series into the before and after parts
+func (r *Regression) GetSplitTime() time.Time {
+	return time.Unix(r.SplitTime, constant.ZeroInt)
+}
+
+// GetBeforeAvgExecTime returns the average execution time before the split time
+func (r *Regression) GetBeforeAvgExecTime() float64 {
+	return r.BeforeAvgExecTime
+}
+
+// GetAfterAvgExecTime returns the average execution time after the split time
+func (r *Regression) GetAfterAvgExecTime() float64 {
+	return r.AfterAvgExecTime
+}
+
+// GetRatio returns the ratio of the average execution time after the split time to the one before it
+func (r *Regression) GetRatio() float64 {
+	return r.Ratio
+}
+
+// GetStatus returns the status of the regression, it is one of regressed, improved, unchanged and insufficient
+func (r *Regression) GetStatus() string {
+	return r.Status
+}
+
+// Trend is the time-bucketed series of a sql and the regression detected in it, the step is in seconds
+type Trend struct {
+	SQLID      string        `json:"sql_id"`
+	Step       int           `json:"step"`
+	Points     []*TrendPoint `json:"points"`
+	Regression *Regression   `json:"regression"`
+}
+
+// NewTrend sorts the points by the bucket time and detects the regression, if splitTime is zero, the split time is detected from the points
+func NewTrend(sqlID string, step time.Duration, points []query.TrendPoint, splitTime time.Time) *Trend {
+	sorted := make([]query.TrendPoint, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GetBucketTime().Before(sorted[j].GetBucketTime())
+	})
+
+	t := &Trend{
+		SQLID:  sqlID,
+		Step:   int(step / time.Second),
+		Points: make([]*TrendPoint, len(sorted)),
+	}
+	for i, point := range sorted {
+		t.Points[i] = NewTrendPointWithTrendPoint(point)
+	}
+	if splitTime.IsZero() {
+		t.Regression = NewRegressionWithDetection(sorted)
+	} else {
+		t.Regression = NewRegressionWithSplitTime(sorted, splitTime)
+	}
+
+	return t
+}
+
+// GetSQLID returns the sql identity
+func (t *Trend) GetSQLID() string {
+	return t.SQLID
+}
+
+// GetStep returns the step of the buckets
+func (t *Trend) GetStep() time.Duration {
+	return time.Duration(t.Step) * time.Second
+}
+
+// GetPoints returns the time-bucketed series
+func (t *Trend) GetPoints() []query.TrendPoint {
+	points := make([]query.TrendPoint, len(t.Points))
+	for i := range t.Points {
+		points[i] = t.Points[i]
+	}
+
+	return points
+}
+
+// GetRegression returns the regression detected in the series
+func (t *Trend) GetRegression() query.Regression {
+	return t.Regression
+}
+
+// getTrendStep returns the step of the buckets in given time range, if step is zero, the time range is divided into 60 buckets,
+// the step must be a multiple of a minute, and there must be no more than 1440 buckets
+func getTrendStep(startTime, endTime time.Time, step time.Duration) (time.Duration, error) {
+	duration := endTime.Sub(startTime)
+	if step == constant.ZeroInt {
+		step = (duration/defaultTrendBucketNum + minTrendStep - 1) / minTrendStep * minTrendStep
+		if step < minTrendStep {
+			step = minTrendStep
+		}
+	}
+	if step < minTrendStep || step%minTrendStep != constant.ZeroInt || duration/step > maxTrendBucketNum {
+		return constant.ZeroInt, message.NewMessage(msgquery.ErrQueryTrendStepNotValid, maxTrendBucketNum, int(step/time.Second))
+	}
+
+	return step, nil
+}
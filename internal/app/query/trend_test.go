@@ -0,0 +1,104 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/romberli/das/internal/dependency/query"
+	"github.com/romberli/go-util/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTrendPoints returns hourly trend points, the average execution time of each point is given
+func newTestTrendPoints(startTime time.Time, avgExecTimes ...float64) []query.TrendPoint {
+	points := make([]query.TrendPoint, len(avgExecTimes))
+	for i, avgExecTime := range avgExecTimes {
+		points[i] = &TrendPoint{
+			BucketTime:    startTime.Add(time.Duration(i) * time.Hour).Unix(),
+			ExecCount:     10,
+			TotalExecTime: avgExecTime * 10,
+			AvgExecTime:   avgExecTime,
+		}
+	}
+
+	return points
+}
+
+func TestTrendAll(t *testing.T) {
+	TestTrend_NewRegressionWithSplitTime(t)
+	TestTrend_NewRegressionWithDetection(t)
+	TestTrend_NewTrend(t)
+	TestTrend_GetTrendStep(t)
+}
+
+func TestTrend_NewRegressionWithSplitTime(t *testing.T) {
+	asst := assert.New(t)
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	points := newTestTrendPoints(startTime, 0.1, 0.1, 0.1, 0.3, 0.3, 0.3)
+
+	r := NewRegressionWithSplitTime(points, startTime.Add(3*time.Hour))
+	asst.Equal(RegressionStatusRegressed, r.GetStatus(), "test NewRegressionWithSplitTime() failed")
+	asst.InDelta(3.0, r.GetRatio(), 1e-9, "test NewRegressionWithSplitTime() failed")
+	asst.Equal(30, r.BeforeExecCount, "test NewRegressionWithSplitTime() failed")
+	asst.Equal(startTime.Add(3*time.Hour).Unix(), r.GetSplitTime().Unix(), "test NewRegressionWithSplitTime() failed")
+	// the split time between the buckets belongs to the next bucket
+	r = NewRegressionWithSplitTime(points, startTime.Add(150*time.Minute))
+	asst.Equal(RegressionStatusRegressed, r.GetStatus(), "test NewRegressionWithSplitTime() failed")
+	// the index change helped
+	r = NewRegressionWithSplitTime(newTestTrendPoints(startTime, 0.4, 0.4, 0.1, 0.1), startTime.Add(2*time.Hour))
+	asst.Equal(RegressionStatusImproved, r.GetStatus(), "test NewRegressionWithSplitTime() failed")
+	r = NewRegressionWithSplitTime(newTestTrendPoints(startTime, 0.1, 0.12, 0.11, 0.1), startTime.Add(2*time.Hour))
+	asst.Equal(RegressionStatusUnchanged, r.GetStatus(), "test NewRegressionWithSplitTime() failed")
+	// not enough buckets after the split time
+	r = NewRegressionWithSplitTime(points, startTime.Add(5*time.Hour))
+	asst.Equal(RegressionStatusInsufficient, r.GetStatus(), "test NewRegressionWithSplitTime() failed")
+}
+
+func TestTrend_NewRegressionWithDetection(t *testing.T) {
+	asst := assert.New(t)
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	r := NewRegressionWithDetection(newTestTrendPoints(startTime, 0.1, 0.1, 0.1, 0.1, 0.25, 0.25, 0.25))
+	asst.True(r.Detected, "test NewRegressionWithDetection() failed")
+	asst.Equal(RegressionStatusRegressed, r.GetStatus(), "test NewRegressionWithDetection() failed")
+	asst.Equal(startTime.Add(4*time.Hour).Unix(), r.GetSplitTime().Unix(), "test NewRegressionWithDetection() failed")
+
+	r = NewRegressionWithDetection(newTestTrendPoints(startTime, 0.1, 0.1, 0.1))
+	asst.Equal(RegressionStatusInsufficient, r.GetStatus(), "test NewRegressionWithDetection() failed")
+}
+
+func TestTrend_NewTrend(t *testing.T) {
+	asst := assert.New(t)
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	points := newTestTrendPoints(startTime, 0.1, 0.1, 0.3, 0.3)
+	// reverse the points, the trend sorts them by the bucket time
+	reversed := []query.TrendPoint{points[3], points[2], points[1], points[0]}
+
+	trend := NewTrend(defaultQueryInfoSQLID, time.Hour, reversed, time.Time{})
+	asst.Equal(defaultQueryInfoSQLID, trend.GetSQLID(), "test NewTrend() failed")
+	asst.Equal(time.Hour, trend.GetStep(), "test NewTrend() failed")
+	asst.Equal(points, trend.GetPoints(), "test NewTrend() failed")
+	asst.Equal(RegressionStatusRegressed, trend.GetRegression().GetStatus(), "test NewTrend() failed")
+
+	jsonBytes, err := common.MarshalStructWithFields(trend, "SQLID", "Step")
+	asst.Nil(err, common.CombineMessageWithError("test NewTrend() failed", err))
+	asst.JSONEq(`{"sql_id":"sql_id","step":3600}`, string(jsonBytes), "test NewTrend() failed")
+}
+
+func TestTrend_GetTrendStep(t *testing.T) {
+	asst := assert.New(t)
+
+	startTime := time.Date(2021, 1, 1, 0, 0, 0, 0, time.Local)
+	step, err := getTrendStep(startTime, startTime.Add(24*time.Hour), 0)
+	asst.Nil(err, common.CombineMessageWithError("test getTrendStep() failed", err))
+	asst.Equal(24*time.Minute, step, "test getTrendStep() failed")
+	step, err = getTrendStep(startTime, startTime.Add(10*time.Minute), 0)
+	asst.Nil(err, common.CombineMessageWithError("test getTrendStep() failed", err))
+	asst.Equal(time.Minute, step, "test getTrendStep() failed")
+	_, err = getTrendStep(startTime, startTime.Add(24*time.Hour), 90*time.Second)
+	asst.NotNil(err, "test getTrendStep() failed")
+	_, err = getTrendStep(startTime, startTime.Add(30*24*time.Hour), time.Minute)
+	asst.NotNil(err, "test getTrendStep() failed")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/romberli/das/global"
 	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/middleware"
 	"github.com/romberli/go-util/middleware/mysql"
 	"github.com/romberli/log"
 	"github.com/stretchr/testify/assert"
@@ -28,7 +29,7 @@ const (
 
 var repository = initRepository()
 
-func initDASMySQLPool() *mysql.Pool {
+func initDASMySQLPool() middleware.Pool {
 	var err error
 
 	global.DASMySQLPool, err = mysql.NewPoolWithDefault(defaultDASMySQLAddr, defaultDASMySQLName, defaultDASMySQLUser, defaultDASMySQLPass)
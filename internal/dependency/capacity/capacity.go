@@ -0,0 +1,146 @@
+package capacity
+
+import (
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type TableSize interface {
+	// GetMySQLServerID returns the mysql server id
+	GetMySQLServerID() int
+	// GetDBName returns the db name
+	GetDBName() string
+	// GetTableName returns the table name
+	GetTableName() string
+	// GetTableRows returns the estimated row count of the table
+	GetTableRows() int64
+	// GetDataLength returns the data length of the table in bytes
+	GetDataLength() int64
+	// GetIndexLength returns the index length of the table in bytes
+	GetIndexLength() int64
+	// GetSnapshotTime returns the time when the snapshot was taken
+	GetSnapshotTime() time.Time
+}
+
+type DASRepo interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(command string, args ...interface{}) (middleware.Result, error)
+	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+	Transaction() (middleware.Transaction, error)
+	// SaveTableSizes saves the table size snapshot of the mysql server in the middleware
+	SaveTableSizes(mysqlServerID int, snapshotTime time.Time, tableSizes []TableSize) error
+	// GetTableSizesByMySQLServerID gets the table size snapshots of the mysql server which were taken in given time range
+	GetTableSizesByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) ([]TableSize, error)
+	// GetTableSizesByDBName gets the table size snapshots of the db on the mysql server which were taken in given time range
+	GetTableSizesByDBName(mysqlServerID int, dbName string, startTime, endTime time.Time) ([]TableSize, error)
+	// GetLatestSnapshotTime gets the time of the latest table size snapshot of the mysql server, it returns zero time if there is no snapshot
+	GetLatestSnapshotTime(mysqlServerID int) (time.Time, error)
+	// DeleteTableSizesBefore deletes the table size snapshots which were taken before given time
+	DeleteTableSizesBefore(snapshotTime time.Time) error
+}
+
+type ApplicationMySQLRepo interface {
+	// Close closes the mysql connection
+	Close() error
+	// GetTableSizes gets the current size of all the user tables from information_schema.tables
+	GetTableSizes() ([]TableSize, error)
+}
+
+type Service interface {
+	// GetForecast returns the capacity forecast
+	GetForecast() Forecast
+	// GetTableGrowths returns the table growths
+	GetTableGrowths() []TableGrowth
+	// GetDBGrowth returns the db growth
+	GetDBGrowth() DBGrowth
+	// GetAppGrowth returns the app growth
+	GetAppGrowth() AppGrowth
+	// ForecastByMySQLServerID forecasts the disk space usage of the mysql server
+	ForecastByMySQLServerID(mysqlServerID int) error
+	// GetTableGrowthsByMySQLServerID gets the growth of every table of the mysql server in given time range
+	GetTableGrowthsByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) error
+	// GetDBGrowthByID gets the growth of the db in given time range
+	GetDBGrowthByID(dbID int, startTime, endTime time.Time) error
+	// GetAppGrowthByID gets the growth of the app in given time range, it is the sum of the growth of the dbs of the app
+	GetAppGrowthByID(appID int, startTime, endTime time.Time) error
+	// Marshal marshals Service.Forecast to json bytes
+	Marshal() ([]byte, error)
+	// MarshalWithFields marshals only specified fields of the Service to json bytes
+	MarshalWithFields(fields ...string) ([]byte, error)
+}
+
+type Forecast interface {
+	// GetMySQLServerID returns the mysql server id
+	GetMySQLServerID() int
+	// GetDiskForecasts returns the days-to-full predictions of the mount points
+	GetDiskForecasts() []DiskForecast
+	// GetTableGrowths returns the growth of the tables which grow fastest
+	GetTableGrowths() []TableGrowth
+}
+
+type DiskForecast interface {
+	// GetMountPoint returns the mount point
+	GetMountPoint() string
+	// GetCurrentUsage returns the current usage ratio of the mount point, it is between 0 and 1
+	GetCurrentUsage() float64
+	// GetGrowthPerDay returns how much the usage ratio grows per day
+	GetGrowthPerDay() float64
+	// GetDaysToFull returns the predicted days until the mount point is full, it is -1 if the usage does not grow
+	GetDaysToFull() float64
+	// GetDaysToFullLower returns the lower confidence bound of the days to full
+	GetDaysToFullLower() float64
+	// GetDaysToFullUpper returns the upper confidence bound of the days to full, it is -1 if the usage may not grow
+	GetDaysToFullUpper() float64
+}
+
+type TableGrowth interface {
+	// GetDBName returns the db name
+	GetDBName() string
+	// GetTableName returns the table name
+	GetTableName() string
+	// GetTableRows returns the latest estimated row count of the table
+	GetTableRows() int64
+	// GetTableSize returns the latest size of the table in bytes, it contains both data and indexes
+	GetTableSize() int64
+	// GetRowsPerDay returns how many rows the table grows per day
+	GetRowsPerDay() float64
+	// GetBytesPerDay returns how many bytes the table grows per day
+	GetBytesPerDay() float64
+}
+
+type DBGrowth interface {
+	// GetDBID returns the db id
+	GetDBID() int
+	// GetDBName returns the db name
+	GetDBName() string
+	// GetMySQLServerID returns the id of the mysql server whose snapshots are used
+	GetMySQLServerID() int
+	// GetTableRows returns the latest estimated row count of the db
+	GetTableRows() int64
+	// GetTableSize returns the latest size of the db in bytes
+	GetTableSize() int64
+	// GetRowsPerDay returns how many rows the db grows per day
+	GetRowsPerDay() float64
+	// GetBytesPerDay returns how many bytes the db grows per day
+	GetBytesPerDay() float64
+	// GetTableGrowths returns the growth of the tables of the db which grow fastest
+	GetTableGrowths() []TableGrowth
+}
+
+type AppGrowth interface {
+	// GetAppID returns the app id
+	GetAppID() int
+	// GetAppName returns the app name
+	GetAppName() string
+	// GetTableRows returns the latest estimated row count of the app
+	GetTableRows() int64
+	// GetTableSize returns the latest size of the app in bytes
+	GetTableSize() int64
+	// GetRowsPerDay returns how many rows the app grows per day
+	GetRowsPerDay() float64
+	// GetBytesPerDay returns how many bytes the app grows per day
+	GetBytesPerDay() float64
+	// GetDBGrowths returns the growth of the dbs of the app
+	GetDBGrowths() []DBGrowth
+}
@@ -0,0 +1,42 @@
+package healthcheck
+
+import (
+	"time"
+)
+
+type DBConfigRule interface {
+	// Identity returns the identity
+	Identity() int
+	// GetRuleSetVersion returns the version of the rule set which the rule belongs to
+	GetRuleSetVersion() int
+	// GetVariableName returns the variable name
+	GetVariableName() string
+	// GetOperator returns the comparison operator
+	GetOperator() string
+	// GetExpectedValue returns the expected value
+	GetExpectedValue() string
+	// GetMinMySQLVersion returns the minimum mysql version which the rule applies to, it is empty if there is no lower limit
+	GetMinMySQLVersion() string
+	// GetMaxMySQLVersion returns the maximum mysql version which the rule applies to, it is empty if there is no upper limit
+	GetMaxMySQLVersion() string
+	// GetEnvID returns the env id which the rule applies to, it is 0 if the rule applies to all the envs
+	GetEnvID() int
+	// GetRole returns the role of the mysql server which the rule applies to, it is 0 if the rule applies to all the roles
+	GetRole() int
+	// GetSeverity returns the severity
+	GetSeverity() int
+	// GetAdvice returns the advice
+	GetAdvice() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+	// Validate validates if the operator, severity and role of the rule are valid
+	Validate() error
+	// IsApplicable returns if the rule applies to the mysql server with given mysql version, env id and role
+	IsApplicable(mysqlVersion string, envID, role int) (bool, error)
+	// Match returns if the value of the variable satisfies the rule with given expected value
+	Match(value, expectedValue string) (bool, error)
+}
@@ -0,0 +1,74 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type EngineConfigOverride interface {
+	// Identity returns the identity
+	Identity() int
+	// GetScopeType returns the scope type
+	GetScopeType() int
+	// GetScopeID returns the scope id, it is the env id, the app level or the mysql cluster id depending on the scope type
+	GetScopeID() int
+	// GetItemName returns the item name
+	GetItemName() string
+	// GetConfig returns the overridden item config as a json string
+	GetConfig() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
+
+type EngineConfigRepo interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(command string, args ...interface{}) (middleware.Result, error)
+	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+	Transaction() (middleware.Transaction, error)
+	// GetLatestVersion gets the latest engine config version from the middleware, it returns 0 if there is no config
+	GetLatestVersion() (int, error)
+	// GetByVersion gets the item configs of given engine config version from the middleware
+	GetByVersion(version int) ([]ItemConfig, error)
+	// Create creates a new engine config version with given item configs in the middleware, it returns the new version
+	Create(itemConfigs []ItemConfig) (int, error)
+	// GetOverrides gets all the engine config overrides from the middleware
+	GetOverrides() ([]EngineConfigOverride, error)
+	// GetOverrideByID gets the engine config override by the identity from the middleware
+	GetOverrideByID(id int) (EngineConfigOverride, error)
+	// SaveOverride saves the engine config override in the middleware,
+	// it replaces the config of the existing override which has the same scope and item name
+	SaveOverride(override EngineConfigOverride) error
+	// DeleteOverride deletes the engine config override in the middleware
+	DeleteOverride(id int) error
+}
+
+type EngineConfigService interface {
+	// GetItemConfigs returns the item configs of the service
+	GetItemConfigs() []ItemConfig
+	// GetLatest gets the item configs of the latest engine config version from the middleware
+	GetLatest() error
+	// GetByVersion gets the item configs of given engine config version from the middleware
+	GetByVersion(version int) error
+	// AddItems adds the item configs as a new engine config version, the items must not exist in the latest version
+	AddItems(itemConfigs []ItemConfig) error
+	// UpdateItems updates the item configs as a new engine config version, the items must exist in the latest version
+	UpdateItems(itemConfigs []ItemConfig) error
+	// DeleteItems deletes the items as a new engine config version, the item configs will be updated in the same version,
+	// so that the weights of the remaining items could be rebalanced
+	DeleteItems(itemNames []string, itemConfigs []ItemConfig) error
+	// GetOverrides returns the engine config overrides of the service
+	GetOverrides() []EngineConfigOverride
+	// GetAllOverrides gets all the engine config overrides from the middleware
+	GetAllOverrides() error
+	// SaveOverride validates and saves the engine config override
+	SaveOverride(scopeType, scopeID int, itemName, config string) error
+	// DeleteOverride deletes the engine config override
+	DeleteOverride(id int) error
+	// Marshal marshals the service to json bytes
+	Marshal() ([]byte, error)
+}
@@ -1,6 +1,7 @@
 package healthcheck
 
 import (
+	"context"
 	"time"
 
 	depquery "github.com/romberli/das/internal/dependency/query"
@@ -14,18 +15,57 @@ type DASRepo interface {
 	Transaction() (middleware.Transaction, error)
 	// LoadEngineConfig loads engine config from the middleware
 	LoadEngineConfig() (EngineConfig, error)
+	// GetAppLevelByMySQLClusterID returns the highest level of the apps which use the mysql cluster, it returns 0 if there is no app
+	GetAppLevelByMySQLClusterID(mysqlClusterID int) (int, error)
+	// GetEngineConfigOverrides returns the engine config overrides of given env, app level and mysql cluster in the layering order
+	GetEngineConfigOverrides(envID, appLevel, mysqlClusterID int) ([]EngineConfigOverride, error)
+	// GetDBConfigRules returns the database config rules of the latest rule set version
+	GetDBConfigRules() ([]DBConfigRule, error)
 	// GetResultByOperationID returns the result
 	GetResultByOperationID(operationID int) (Result, error)
+	// GetResultsByMySQLServerID returns the results of the mysql server which were checked in given time range
+	GetResultsByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) ([]Result, error)
+	// GetResultItemsByOperationIDs returns the item results of given operations
+	GetResultItemsByOperationIDs(operationIDs ...int) ([]ResultItem, error)
+	// GetOperationByID returns the operation
+	GetOperationByID(operationID int) (Operation, error)
+	// GetOperations returns the operations which were created in given time range,
+	// mysql server id and status are optional filters, they will be ignored if they are negative
+	GetOperations(mysqlServerID, status int, startTime, endTime time.Time) ([]Operation, error)
 	// IsRunning returns if the healthcheck of given mysql server is still running
 	IsRunning(mysqlServerID int) (bool, error)
 	// InitOperation initiates the operation
 	InitOperation(mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error)
 	// UpdateOperationStatus updates operation status
 	UpdateOperationStatus(operationID int, status int, message string) error
+	// UpdateOperationEngineConfigVersion updates the version of the engine config which the operation is scored with
+	UpdateOperationEngineConfigVersion(operationID int, version int) error
 	// SaveResult saves result into the middleware
 	SaveResult(result Result) error
 	// UpdateAccuracyReviewByOperationID updates the accuracy review
 	UpdateAccuracyReviewByOperationID(operationID int, review int) error
+	// IsClusterRunning returns if the healthcheck of given mysql cluster is still running
+	IsClusterRunning(mysqlClusterID int) (bool, error)
+	// InitClusterOperation initiates the cluster operation
+	InitClusterOperation(mysqlClusterID int, startTime, endTime time.Time, step time.Duration) (int, error)
+	// InitMemberOperation initiates the operation of the mysql server which belongs to the cluster operation
+	InitMemberOperation(clusterOperationID, mysqlServerID int, startTime, endTime time.Time, step time.Duration) (int, error)
+	// UpdateClusterOperationStatus updates cluster operation status
+	UpdateClusterOperationStatus(clusterOperationID int, status int, message string) error
+	// GetClusterResultByOperationID returns the cluster result
+	GetClusterResultByOperationID(clusterOperationID int) (ClusterResult, error)
+	// SaveClusterResult saves cluster result into the middleware
+	SaveClusterResult(clusterResult ClusterResult) error
+	// IsMiddlewareClusterRunning returns if the healthcheck of given middleware cluster is still running
+	IsMiddlewareClusterRunning(middlewareClusterID int) (bool, error)
+	// InitMiddlewareOperation initiates the middleware operation
+	InitMiddlewareOperation(middlewareClusterID int) (int, error)
+	// UpdateMiddlewareOperationStatus updates middleware operation status
+	UpdateMiddlewareOperationStatus(middlewareOperationID int, status int, message string) error
+	// GetMiddlewareResultByOperationID returns the middleware result
+	GetMiddlewareResultByOperationID(middlewareOperationID int) (MiddlewareResult, error)
+	// SaveMiddlewareResult saves middleware result into the middleware
+	SaveMiddlewareResult(middlewareResult MiddlewareResult) error
 }
 
 type ApplicationMySQLRepo interface {
@@ -35,6 +75,24 @@ type ApplicationMySQLRepo interface {
 	GetVariables(items []string) ([]Variable, error)
 	// GetMySQLDirs gets the mysql data directory and binlog directory
 	GetMySQLDirs() ([]string, error)
+	// IsReplica returns if the mysql server is a replica
+	IsReplica() (bool, error)
+	// GetReplicationStatus returns the replication status, it returns nil if the mysql server is not a replica
+	GetReplicationStatus() (ReplicationStatus, error)
+	// GetGTIDExecuted returns the executed gtid set of the mysql server
+	GetGTIDExecuted() (string, error)
+	// GetLockWaits returns the innodb lock waits which are waiting now
+	GetLockWaits() ([]LockWait, error)
+	// GetInnoDBStatus returns the output of show engine innodb status
+	GetInnoDBStatus() (string, error)
+	// GetUnusedIndexes returns the indexes which have not been used since the mysql server started
+	GetUnusedIndexes() ([]IndexFinding, error)
+	// GetRedundantIndexes returns the indexes which are covered by other indexes
+	GetRedundantIndexes() ([]IndexFinding, error)
+	// GetTablesWithoutPrimaryKey returns the tables which do not have a primary key
+	GetTablesWithoutPrimaryKey() ([]IndexFinding, error)
+	// GetNonInnoDBTables returns the tables of which the storage engine is not innodb
+	GetNonInnoDBTables() ([]IndexFinding, error)
 	// GetTables gets the tables
 	GetLargeTables() ([]Table, error)
 }
@@ -54,6 +112,12 @@ type PrometheusRepo interface {
 	GetAverageActiveSessionPercents() ([]PrometheusData, error)
 	// GetCacheMissRatio gets the cache miss ratio
 	GetCacheMissRatio() ([]PrometheusData, error)
+	// GetReplicationLag gets the replication lag
+	GetReplicationLag() ([]PrometheusData, error)
+	// GetInnoDBRowLockWaits gets the innodb row lock waits per second
+	GetInnoDBRowLockWaits() ([]PrometheusData, error)
+	// GetInnoDBRowLockTime gets the innodb row lock time per second in milliseconds
+	GetInnoDBRowLockTime() ([]PrometheusData, error)
 }
 
 type QueryRepo interface {
@@ -74,6 +138,36 @@ type Service interface {
 	Check(mysqlServerID int, startTime, endTime time.Time, step time.Duration) error
 	// Check checks the server health status
 	CheckByHostInfo(hostIP string, portNum int, startTime, endTime time.Time, step time.Duration) error
+	// CheckByMySQLClusterID checks the health status of all the mysql servers of the mysql cluster
+	CheckByMySQLClusterID(mysqlClusterID int, startTime, endTime time.Time, step time.Duration) error
+	// CheckBySnapshot checks the health status of the mysql server with the captured snapshot instead of the live connections
+	CheckBySnapshot(mysqlServerID int, data []byte) error
+	// GetClusterResult returns the cluster result
+	GetClusterResult() ClusterResult
+	// GetClusterResultByOperationID gets the cluster result by cluster operation id from the middleware
+	GetClusterResultByOperationID(id int) error
+	// CheckByMiddlewareClusterID checks the connectivity, backends and routing health of the middleware servers of the middleware cluster
+	CheckByMiddlewareClusterID(middlewareClusterID int) error
+	// GetMiddlewareResult returns the middleware result
+	GetMiddlewareResult() MiddlewareResult
+	// GetMiddlewareResultByOperationID gets the middleware result by middleware operation id from the middleware
+	GetMiddlewareResultByOperationID(id int) error
+	// CancelMiddleware cancels the running middleware operation
+	CancelMiddleware(middlewareOperationID int) error
+	// GetScoreTrendByMySQLServerID gets the score trend of the mysql server in given time range from the middleware
+	GetScoreTrendByMySQLServerID(mysqlServerID int, startTime, endTime time.Time) error
+	// GetResultDiffByOperationIDs compares the result of the target operation with the result of the base operation
+	GetResultDiffByOperationIDs(baseOperationID, targetOperationID int) error
+	// GetReportByOperationID gets the result of the operation and generates the human readable report
+	GetReportByOperationID(operationID int) error
+	// GetOperations gets the operations which were created in given time range from the middleware
+	GetOperations(mysqlServerID, status int, startTime, endTime time.Time) error
+	// GetOperationStatus gets the status of the operation and the progress of each check item
+	GetOperationStatus(operationID int) error
+	// Cancel cancels the running operation
+	Cancel(operationID int) error
+	// Retry re-runs the failed or canceled operation with the same check range and step
+	Retry(operationID int) error
 	// ReviewAccuracy reviews the accuracy of the check
 	ReviewAccuracy(id, review int) error
 	// MarshalJSON marshals Service to json string
@@ -83,6 +177,19 @@ type Service interface {
 }
 
 type Engine interface {
-	// Run checks the server health status
-	Run()
+	// Run checks the server health status, it stops as soon as possible when the context is canceled
+	Run(ctx context.Context)
+	// GetItemStatuses returns the progress of each check item, the key is the item name
+	GetItemStatuses() map[string]string
+}
+
+type CheckItem interface {
+	// GetName returns the item name, it must be identical to the item name of the engine config
+	GetName() string
+	// Collect collects the data of the item
+	Collect() error
+	// Score scores the collected data with given item config
+	Score(config ItemConfig) (int, error)
+	// Advice returns the collected data, the data which is higher than the watermark and the advice as json strings
+	Advice() (string, string, string, error)
 }
@@ -0,0 +1,58 @@
+package healthcheck
+
+import (
+	"time"
+)
+
+type MiddlewareBackend interface {
+	// GetHostGroupID returns the host group id which the backend belongs to
+	GetHostGroupID() int
+	// GetHostIP returns the host ip of the backend
+	GetHostIP() string
+	// GetPortNum returns the port number of the backend
+	GetPortNum() int
+	// GetStatus returns the status of the backend, e.g. ONLINE, SHUNNED, OFFLINE_SOFT, OFFLINE_HARD
+	GetStatus() string
+	// GetConnUsed returns the number of the connections which are in use
+	GetConnUsed() int
+	// GetConnFree returns the number of the idle connections in the connection pool
+	GetConnFree() int
+	// GetConnOK returns the number of the connections which were established successfully
+	GetConnOK() int
+	// GetConnErr returns the number of the connections which were failed to establish
+	GetConnErr() int
+}
+
+type MiddlewareProxyRepo interface {
+	// Ping probes the connectivity through the middleware server, it returns the round trip latency
+	Ping() (time.Duration, error)
+	// IsAdminAvailable returns if the admin interface of the middleware server is available
+	IsAdminAvailable() bool
+	// GetBackends gets the connection pool and status of the backends from the admin interface
+	GetBackends() ([]MiddlewareBackend, error)
+}
+
+type MiddlewareResult interface {
+	// Identity returns the identity
+	Identity() int
+	// GetMiddlewareOperationID returns the middleware operation id
+	GetMiddlewareOperationID() int
+	// GetWeightedAverageScore returns the weighted average score of the middleware cluster
+	GetWeightedAverageScore() int
+	// GetConnectivityScore returns the score of the connectivity through the middleware servers
+	GetConnectivityScore() int
+	// GetBackendScore returns the score of the backend status and mapping
+	GetBackendScore() int
+	// GetRoutingScore returns the score of the routing health
+	GetRoutingScore() int
+	// GetServerResults returns the results of the middleware servers as a json string
+	GetServerResults() string
+	// GetMissingBackends returns the registered mysql servers which are not routed by any middleware server as a json string
+	GetMissingBackends() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
@@ -0,0 +1,112 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type NotifyRule interface {
+	// Identity returns the identity
+	Identity() int
+	// GetRuleName returns the rule name
+	GetRuleName() string
+	// GetMySQLClusterID returns the mysql cluster id, 0 means the rule applies to all the mysql clusters
+	GetMySQLClusterID() int
+	// GetTriggerType returns the trigger type
+	GetTriggerType() int
+	// GetThreshold returns the threshold
+	GetThreshold() int
+	// GetSinkType returns the sink type
+	GetSinkType() int
+	// GetTarget returns the target
+	GetTarget() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
+
+type NotifyLog interface {
+	// Identity returns the identity
+	Identity() int
+	// GetRuleID returns the rule id
+	GetRuleID() int
+	// GetOperationID returns the operation id
+	GetOperationID() int
+	// GetSinkType returns the sink type
+	GetSinkType() int
+	// GetTarget returns the target which the notification was delivered to
+	GetTarget() string
+	// GetStatus returns the delivery status
+	GetStatus() int
+	// GetAttempts returns the number of the delivery attempts, including the retries
+	GetAttempts() int
+	// GetMessage returns the error message of the last failed attempt
+	GetMessage() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
+
+type Notification interface {
+	// GetSubject returns the subject of the notification
+	GetSubject() string
+	// GetContent returns the human readable content of the notification
+	GetContent() string
+	// Marshal marshals the notification to json bytes
+	Marshal() ([]byte, error)
+}
+
+type NotifySink interface {
+	// Send sends the notification to the target
+	Send(target string, notification Notification) error
+}
+
+type NotifyRepo interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(command string, args ...interface{}) (middleware.Result, error)
+	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+	Transaction() (middleware.Transaction, error)
+	// GetAll gets all the notify rules from the middleware
+	GetAll() ([]NotifyRule, error)
+	// GetByID gets the notify rule by the identity from the middleware
+	GetByID(id int) (NotifyRule, error)
+	// GetByMySQLClusterID gets the notify rules which apply to the mysql cluster from the middleware
+	GetByMySQLClusterID(mysqlClusterID int) ([]NotifyRule, error)
+	// Create creates a notify rule in the middleware
+	Create(rule NotifyRule) (NotifyRule, error)
+	// Delete deletes the notify rule in the middleware
+	Delete(id int) error
+	// GetOwnerEmail gets the email of the owner of the mysql cluster from the middleware, it returns empty string if there is no owner
+	GetOwnerEmail(mysqlClusterID int) (string, error)
+	// GetPreviousScore gets the weighted average score of the last succeeded operation of the mysql server before given operation,
+	// it returns false if there is no such operation
+	GetPreviousScore(mysqlServerID, operationID int) (int, bool, error)
+	// GetLogsByOperationID gets the notify logs of the operation from the middleware
+	GetLogsByOperationID(operationID int) ([]NotifyLog, error)
+	// SaveLog saves the notify log in the middleware
+	SaveLog(notifyLog NotifyLog) error
+}
+
+type NotifyService interface {
+	// GetRules returns the notify rules of the service
+	GetRules() []NotifyRule
+	// GetLogs returns the notify logs of the service
+	GetLogs() []NotifyLog
+	// GetAll gets all the notify rules from the middleware
+	GetAll() error
+	// Create creates a notify rule in the middleware
+	Create(ruleName string, mysqlClusterID, triggerType, threshold, sinkType int, target string) error
+	// Delete deletes the notify rule
+	Delete(id int) error
+	// GetLogsByOperationID gets the notify logs of the operation from the middleware
+	GetLogsByOperationID(operationID int) error
+	// Marshal marshals the service to json bytes
+	Marshal() ([]byte, error)
+}
@@ -0,0 +1,33 @@
+package healthcheck
+
+import (
+	"time"
+)
+
+type Operation interface {
+	// Identity returns the identity
+	Identity() int
+	// GetClusterOperationID returns the cluster operation id, it is 0 if the operation does not belong to any cluster operation
+	GetClusterOperationID() int
+	// GetMySQLServerID returns the mysql server id
+	GetMySQLServerID() int
+	// GetStartTime returns the start time of the check range
+	GetStartTime() time.Time
+	// GetEndTime returns the end time of the check range
+	GetEndTime() time.Time
+	// GetStep returns the step
+	GetStep() time.Duration
+	// GetEngineConfigVersion returns the version of the engine config which the operation was scored with,
+	// it is 0 if the engine config has not been loaded
+	GetEngineConfigVersion() int
+	// GetStatus returns the status
+	GetStatus() int
+	// GetMessage returns the message
+	GetMessage() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
@@ -0,0 +1,55 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type PrometheusCatalogueOverride interface {
+	// Identity returns the identity
+	Identity() int
+	// GetMonitorSystemID returns the monitor system id
+	GetMonitorSystemID() int
+	// GetName returns the name of the label mapping or the query in the catalogue
+	GetName() string
+	// GetTemplate returns the overridden template
+	GetTemplate() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
+
+type PrometheusCatalogueRepo interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(command string, args ...interface{}) (middleware.Result, error)
+	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+	Transaction() (middleware.Transaction, error)
+	// GetOverridesByMonitorSystemID gets the prometheus catalogue overrides of given monitor system from the middleware
+	GetOverridesByMonitorSystemID(monitorSystemID int) ([]PrometheusCatalogueOverride, error)
+	// GetOverrideByID gets the prometheus catalogue override by the identity from the middleware
+	GetOverrideByID(id int) (PrometheusCatalogueOverride, error)
+	// SaveOverride saves the prometheus catalogue override in the middleware,
+	// it replaces the template of the existing override which has the same monitor system and name
+	SaveOverride(override PrometheusCatalogueOverride) error
+	// DeleteOverride deletes the prometheus catalogue override in the middleware
+	DeleteOverride(id int) error
+}
+
+type PrometheusCatalogueService interface {
+	// GetTemplates returns the templates of the catalogue, the overrides are already applied
+	GetTemplates() map[string]string
+	// GetOverrides returns the prometheus catalogue overrides of the service
+	GetOverrides() []PrometheusCatalogueOverride
+	// GetByMonitorSystemID gets the catalogue of given monitor system, the overrides are applied to the default catalogue of the system type
+	GetByMonitorSystemID(monitorSystemID int) error
+	// SaveOverride validates and saves the prometheus catalogue override
+	SaveOverride(monitorSystemID int, name, template string) error
+	// DeleteOverride deletes the prometheus catalogue override
+	DeleteOverride(id int) error
+	// Marshal marshals the service to json bytes
+	Marshal() ([]byte, error)
+}
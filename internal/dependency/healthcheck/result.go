@@ -11,6 +11,8 @@ type Result interface {
 	GetOperationID() int
 	// GetWeightedAverageScore returns the weighted average score
 	GetWeightedAverageScore() int
+	// GetEngineConfig returns the item configs which the result was scored with, the overrides have been applied
+	GetEngineConfig() string
 	// GetDBConfigScore returns the database configuration score
 	GetDBConfigScore() int
 	// GetDBConfigData returns the database configuration data
@@ -71,6 +73,8 @@ type Result interface {
 	GetSlowQueryData() string
 	// GetSlowQueryAdvice returns the slow query advice
 	GetSlowQueryAdvice() string
+	// GetItems returns the results of all the check items, including the items which do not have a legacy field
+	GetItems() []ResultItem
 	// GetAccuracyReview returns the accuracy review
 	GetAccuracyReview() int
 	// GetDelFlag returns the delete flag
@@ -86,3 +90,53 @@ type Result interface {
 	// MarshalJSON marshals only specified field of the Result to json string
 	MarshalJSONWithFields(fields ...string) ([]byte, error)
 }
+
+type ResultItem interface {
+	// Identity returns the identity
+	Identity() int
+	// GetOperationID returns the operation id
+	GetOperationID() int
+	// GetItemName returns the item name
+	GetItemName() string
+	// GetScore returns the score of the item
+	GetScore() int
+	// GetData returns the data of the item
+	GetData() string
+	// GetHigh returns the high data of the item
+	GetHigh() string
+	// GetAdvice returns the advice of the item
+	GetAdvice() string
+	// GetStatus returns the status of the item, it is one of available and unavailable
+	GetStatus() string
+	// GetError returns the error message of the unavailable item
+	GetError() string
+	// IsAvailable returns if the check item completed successfully
+	IsAvailable() bool
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
+
+type ClusterResult interface {
+	// Identity returns the identity
+	Identity() int
+	// GetClusterOperationID returns the cluster operation id
+	GetClusterOperationID() int
+	// GetWorstScore returns the worst weighted average score of the mysql servers
+	GetWorstScore() int
+	// GetWorstMySQLServerID returns the mysql server id which has the worst score
+	GetWorstMySQLServerID() int
+	// GetServerResults returns the results of the mysql servers as a json string
+	GetServerResults() string
+	// GetConfigDrift returns the database configuration drift between the mysql servers as a json string
+	GetConfigDrift() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
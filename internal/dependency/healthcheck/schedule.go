@@ -0,0 +1,81 @@
+package healthcheck
+
+import (
+	"time"
+
+	"github.com/romberli/go-util/middleware"
+)
+
+type Schedule interface {
+	// Identity returns the identity
+	Identity() int
+	// GetScheduleName returns the schedule name
+	GetScheduleName() string
+	// GetTargetType returns the target type
+	GetTargetType() int
+	// GetTargetID returns the target id
+	GetTargetID() int
+	// GetCronExpr returns the cron expression
+	GetCronExpr() string
+	// GetWindow returns the time range of the healthcheck
+	GetWindow() time.Duration
+	// GetStep returns the step of the healthcheck
+	GetStep() time.Duration
+	// GetStatus returns the status
+	GetStatus() int
+	// GetLastRunTime returns the last run time
+	GetLastRunTime() time.Time
+	// GetNextRunTime returns the next run time
+	GetNextRunTime() time.Time
+	// GetMessage returns the message of the last run
+	GetMessage() string
+	// GetDelFlag returns the delete flag
+	GetDelFlag() int
+	// GetCreateTime returns the create time
+	GetCreateTime() time.Time
+	// GetLastUpdateTime returns the last update time
+	GetLastUpdateTime() time.Time
+}
+
+type ScheduleRepo interface {
+	// Execute executes given command and placeholders on the middleware
+	Execute(command string, args ...interface{}) (middleware.Result, error)
+	// Transaction returns a middleware.Transaction that could execute multiple commands as a transaction
+	Transaction() (middleware.Transaction, error)
+	// GetAll gets all the schedules from the middleware
+	GetAll() ([]Schedule, error)
+	// GetByID gets the schedule by the identity from the middleware
+	GetByID(id int) (Schedule, error)
+	// GetDue gets the active schedules whose next run time is not later than given time from the middleware
+	GetDue(t time.Time) ([]Schedule, error)
+	// Create creates a schedule in the middleware
+	Create(schedule Schedule) (Schedule, error)
+	// UpdateStatus updates the status and the next run time of the schedule
+	UpdateStatus(id, status int, nextRunTime time.Time) error
+	// Claim moves the next run time of the schedule forward only if it is still the expected one,
+	// it returns false if the schedule has been claimed by others
+	Claim(id int, expectedNextRunTime, lastRunTime, nextRunTime time.Time) (bool, error)
+	// UpdateMessage updates the message of the last run
+	UpdateMessage(id int, message string) error
+	// Delete deletes the schedule in the middleware
+	Delete(id int) error
+}
+
+type ScheduleService interface {
+	// GetSchedules returns the schedules of the service
+	GetSchedules() []Schedule
+	// GetAll gets all the schedules from the middleware
+	GetAll() error
+	// GetByID gets the schedule by the identity from the middleware
+	GetByID(id int) error
+	// Create creates a schedule in the middleware
+	Create(scheduleName string, targetType, targetID int, cronExpr string, window, step time.Duration) error
+	// Pause pauses the schedule
+	Pause(id int) error
+	// Resume resumes the schedule, the next run time will be recalculated from now
+	Resume(id int) error
+	// Delete deletes the schedule
+	Delete(id int) error
+	// Marshal marshals the service to json bytes
+	Marshal() ([]byte, error)
+}
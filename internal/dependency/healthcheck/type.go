@@ -7,6 +7,8 @@ import (
 type ItemConfig interface {
 	// GetID returns the identity
 	GetID() int
+	// GetConfigVersion returns the config version
+	GetConfigVersion() int
 	// GetItemName returns the item name
 	GetItemName() string
 	// GetItemWeight returns the item weight
@@ -25,6 +27,8 @@ type ItemConfig interface {
 	GetScoreDeductionPerUnitMedium() float64
 	// GetMaxScoreDeductionMedium returns the max score deduction medium
 	GetMaxScoreDeductionMedium() float64
+	// GetScoringStrategy returns the scoring strategy, empty means the default strategy
+	GetScoringStrategy() string
 	// GetDelFlag returns the delete flag
 	GetDelFlag() int
 	// GetCreateTime returns the create time
@@ -36,6 +40,8 @@ type ItemConfig interface {
 type EngineConfig interface {
 	// GetItemConfig returns the item config
 	GetItemConfig(item string) ItemConfig
+	// GetVersion returns the config version
+	GetVersion() int
 	// Validate validates if engine configuration is valid
 	Validate() error
 }
@@ -58,14 +64,71 @@ type Table interface {
 	GetSize() float64
 }
 
+type LockWait interface {
+	// GetWaitAgeSecs returns how many seconds the lock has been waited for
+	GetWaitAgeSecs() int
+	// GetLockedTable returns the locked table
+	GetLockedTable() string
+	// GetLockedIndex returns the locked index
+	GetLockedIndex() string
+	// GetLockedType returns the lock type
+	GetLockedType() string
+	// GetWaitingPID returns the processlist id of the waiting session
+	GetWaitingPID() int
+	// GetWaitingQuery returns the waiting query
+	GetWaitingQuery() string
+	// GetBlockingPID returns the processlist id of the blocking session
+	GetBlockingPID() int
+	// GetBlockingQuery returns the blocking query, it is empty if the blocking session is idle in the transaction
+	GetBlockingQuery() string
+}
+
+type IndexFinding interface {
+	// GetFindingType returns the finding type
+	GetFindingType() string
+	// GetTableSchema returns the table schema
+	GetTableSchema() string
+	// GetTableName returns the table name
+	GetTableName() string
+	// GetIndexName returns the index name, it is empty if the finding is about the table
+	GetIndexName() string
+	// GetColumns returns the columns of the index
+	GetColumns() string
+	// GetDominantIndexName returns the name of the index which covers the redundant index
+	GetDominantIndexName() string
+	// GetEngine returns the storage engine of the table
+	GetEngine() string
+}
+
 type FileSystem interface {
 	GetMountPoint() string
 	GetDevice() string
 }
 
 type PrometheusData interface {
+	// GetSeries returns the identity of the time series which the data belongs to
+	GetSeries() string
 	// GetTimestamp returns the timestamp
 	GetTimestamp() string
 	// GetValue returns the value
 	GetValue() float64
 }
+
+type ReplicationStatus interface {
+	// GetMasterHost returns the host of the primary
+	GetMasterHost() string
+	// GetMasterPort returns the port of the primary
+	GetMasterPort() int
+	// IsIOThreadRunning returns if the io thread is running
+	IsIOThreadRunning() bool
+	// IsSQLThreadRunning returns if the sql thread is running
+	IsSQLThreadRunning() bool
+	// GetSecondsBehindMaster returns the seconds behind the primary, it returns -1 if the sql thread is not running
+	GetSecondsBehindMaster() int
+	// GetLastIOError returns the last error of the io thread
+	GetLastIOError() string
+	// GetLastSQLError returns the last error of the sql thread
+	GetLastSQLError() string
+	// GetExecutedGTIDSet returns the executed gtid set
+	GetExecutedGTIDSet() string
+}
@@ -1,6 +1,8 @@
 package query
 
 import (
+	"time"
+
 	"github.com/romberli/das/internal/dependency/metadata"
 	"github.com/romberli/go-util/middleware"
 )
@@ -22,6 +24,57 @@ type Query interface {
 	GetAvgExecTime() float64
 	// GetRowsExaminedMax returns the maximum row examined
 	GetRowsExaminedMax() int
+	// GetTotalLockTime returns the total lock time, it is nil if the monitor system does not collect it
+	GetTotalLockTime() *float64
+	// GetRowsSentSum returns the total rows sent, it is nil if the monitor system does not collect it
+	GetRowsSentSum() *int
+	// GetP99ExecTime returns the 99th percentile execution time, it is nil if the monitor system does not collect it
+	GetP99ExecTime() *float64
+	// GetTmpTableCount returns the number of the temporary tables, it is nil if the monitor system does not collect it
+	GetTmpTableCount() *int
+	// GetFullScanCount returns the number of the full scans, it is nil if the monitor system does not collect it
+	GetFullScanCount() *int
+	// GetErrorCount returns the number of the executions with errors, it is nil if the monitor system does not collect it
+	GetErrorCount() *int
+}
+
+type TrendPoint interface {
+	// GetBucketTime returns the start time of the bucket
+	GetBucketTime() time.Time
+	// GetExecCount returns the execution count in the bucket
+	GetExecCount() int
+	// GetTotalExecTime returns the total execution time in the bucket
+	GetTotalExecTime() float64
+	// GetAvgExecTime returns the average execution time in the bucket
+	GetAvgExecTime() float64
+	// GetRowsExaminedSum returns the total rows examined in the bucket
+	GetRowsExaminedSum() int
+	// GetRowsExaminedMax returns the maximum rows examined in the bucket
+	GetRowsExaminedMax() int
+}
+
+type Regression interface {
+	// GetSplitTime returns the time which splits the series into the before and after parts
+	GetSplitTime() time.Time
+	// GetBeforeAvgExecTime returns the average execution time before the split time
+	GetBeforeAvgExecTime() float64
+	// GetAfterAvgExecTime returns the average execution time after the split time
+	GetAfterAvgExecTime() float64
+	// GetRatio returns the ratio of the average execution time after the split time to the one before it
+	GetRatio() float64
+	// GetStatus returns the status of the regression, it is one of regressed, improved, unchanged and insufficient
+	GetStatus() string
+}
+
+type Trend interface {
+	// GetSQLID returns the sql identity
+	GetSQLID() string
+	// GetStep returns the step of the buckets
+	GetStep() time.Duration
+	// GetPoints returns the time-bucketed series
+	GetPoints() []TrendPoint
+	// GetRegression returns the regression detected in the series
+	GetRegression() Regression
 }
 
 type DASRepo interface {
@@ -44,6 +97,8 @@ type MonitorRepo interface {
 	GetByDBName(serviceName, dbName string) ([]Query, error)
 	// GetBySQLID gets the query by the service name of the mysql server and sql identity
 	GetBySQLID(serviceName, sqlID string) (Query, error)
+	// GetTrendBySQLID gets the time-bucketed series by the service name of the mysql server and sql identity
+	GetTrendBySQLID(serviceName, sqlID string, step time.Duration) ([]TrendPoint, error)
 }
 
 type Service interface {
@@ -57,8 +112,15 @@ type Service interface {
 	GetByDBID(mysqlServerID, dbID int) error
 	// GetBySQLID gets the query by the mysql server identity and the sql identity
 	GetBySQLID(mysqlServerID int, sqlID string) error
+	// GetTrend returns the trend of the sql
+	GetTrend() Trend
+	// GetTrendBySQLID gets the time-bucketed series of the sql and detects the regression,
+	// if splitTime is zero, the split time is detected from the series
+	GetTrendBySQLID(mysqlServerID int, sqlID string, step time.Duration, splitTime time.Time) error
 	// Marshal marshals Service.Queries to json bytes
 	Marshal() ([]byte, error)
 	// MarshalWithFields marshals only specified fields of the Service to json bytes
 	MarshalWithFields(fields ...string) ([]byte, error)
+	// MarshalTrend marshals Service.Trend to json bytes
+	MarshalTrend() ([]byte, error)
 }
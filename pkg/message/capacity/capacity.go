@@ -0,0 +1,99 @@
+package capacity
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initCapacityDebugMessage()
+	initCapacityInfoMessage()
+	initCapacityErrorMessage()
+}
+
+const (
+	// debug
+	DebugCapacityForecastByMySQLServerID        = 104001
+	DebugCapacityGetTableGrowthsByMySQLServerID = 104002
+	DebugCapacityGetDBGrowthByID                = 104003
+	DebugCapacityGetAppGrowthByID               = 104004
+	// info
+	InfoCapacityForecastByMySQLServerID        = 204001
+	InfoCapacityGetTableGrowthsByMySQLServerID = 204002
+	InfoCapacityGetDBGrowthByID                = 204003
+	InfoCapacityGetAppGrowthByID               = 204004
+	InfoCapacityCollectorCompleted             = 204005
+	InfoCapacityCollectorDisabled              = 204006
+	// error
+	ErrCapacityForecastByMySQLServerID        = 404001
+	ErrCapacitySnapshotTableSizes             = 404002
+	ErrCapacityGetTableGrowthsByMySQLServerID = 404003
+	ErrCapacityGetDBGrowthByID                = 404004
+	ErrCapacityGetAppGrowthByID               = 404005
+	ErrCapacityDBClusterTypeNotSupported      = 404006
+	ErrCapacityCollectorLoad                  = 404007
+	ErrCapacityCollectorPurge                 = 404008
+)
+
+func initCapacityDebugMessage() {
+	message.Messages[DebugCapacityForecastByMySQLServerID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugCapacityForecastByMySQLServerID,
+		"capacity: forecast by mysql server id message: %s")
+	message.Messages[DebugCapacityGetTableGrowthsByMySQLServerID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugCapacityGetTableGrowthsByMySQLServerID,
+		"capacity: get table growths by mysql server id message: %s")
+	message.Messages[DebugCapacityGetDBGrowthByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugCapacityGetDBGrowthByID,
+		"capacity: get db growth by id message: %s")
+	message.Messages[DebugCapacityGetAppGrowthByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugCapacityGetAppGrowthByID,
+		"capacity: get app growth by id message: %s")
+}
+
+func initCapacityInfoMessage() {
+	message.Messages[InfoCapacityForecastByMySQLServerID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoCapacityForecastByMySQLServerID,
+		"capacity: forecast by mysql server id completed. mysql_server_id: %d")
+	message.Messages[InfoCapacityGetTableGrowthsByMySQLServerID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoCapacityGetTableGrowthsByMySQLServerID,
+		"capacity: get table growths by mysql server id completed. mysql_server_id: %d")
+	message.Messages[InfoCapacityGetDBGrowthByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoCapacityGetDBGrowthByID,
+		"capacity: get db growth by id completed. db_id: %d")
+	message.Messages[InfoCapacityGetAppGrowthByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoCapacityGetAppGrowthByID,
+		"capacity: get app growth by id completed. app_id: %d")
+	message.Messages[InfoCapacityCollectorCompleted] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoCapacityCollectorCompleted,
+		"capacity: table size snapshots collected. snapshot_num: %d, mysql_server_num: %d")
+	message.Messages[InfoCapacityCollectorDisabled] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoCapacityCollectorDisabled,
+		"capacity: table size collector is disabled as the interval is 0")
+}
+
+func initCapacityErrorMessage() {
+	message.Messages[ErrCapacityForecastByMySQLServerID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacityForecastByMySQLServerID,
+		"capacity: forecast by mysql server id failed. mysql_server_id: %d\n%s")
+	message.Messages[ErrCapacitySnapshotTableSizes] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacitySnapshotTableSizes,
+		"capacity: take table size snapshot failed. mysql_server_id: %d\n%s")
+	message.Messages[ErrCapacityGetTableGrowthsByMySQLServerID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacityGetTableGrowthsByMySQLServerID,
+		"capacity: get table growths by mysql server id failed. mysql_server_id: %d\n%s")
+	message.Messages[ErrCapacityGetDBGrowthByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacityGetDBGrowthByID,
+		"capacity: get db growth by id failed. db_id: %d\n%s")
+	message.Messages[ErrCapacityGetAppGrowthByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacityGetAppGrowthByID,
+		"capacity: get app growth by id failed. app_id: %d\n%s")
+	message.Messages[ErrCapacityDBClusterTypeNotSupported] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacityDBClusterTypeNotSupported,
+		"capacity: only the db which is deployed on a single mysql cluster is supported. db_id: %d, cluster_type: %d")
+	message.Messages[ErrCapacityCollectorLoad] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacityCollectorLoad,
+		"capacity: load mysql servers of the table size collector failed.\n%s")
+	message.Messages[ErrCapacityCollectorPurge] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrCapacityCollectorPurge,
+		"capacity: purge expired table size snapshots failed.\n%s")
+}
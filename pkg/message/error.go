@@ -5,61 +5,73 @@ import (
 )
 
 const (
-	ErrPrintHelpInfo                    = 400001
-	ErrEmptyLogFileName                 = 400002
-	ErrNotValidLogFileName              = 400003
-	ErrNotValidLogLevel                 = 400004
-	ErrNotValidLogFormat                = 400005
-	ErrNotValidLogMaxSize               = 400006
-	ErrNotValidLogMaxDays               = 400007
-	ErrNotValidLogMaxBackups            = 400008
-	ErrNotValidServerPort               = 400009
-	ErrNotValidPidFile                  = 400010
-	ErrValidateConfig                   = 400011
-	ErrInitDefaultConfig                = 400012
-	ErrReadConfigFile                   = 400013
-	ErrOverrideCommandLineArgs          = 400014
-	ErrAbsoluteLogFilePath              = 400015
-	ErrInitLogger                       = 400016
-	ErrBaseDir                          = 400017
-	ErrInitConfig                       = 400018
-	ErrCheckServerPid                   = 400019
-	ErrCheckServerRunningStatus         = 400020
-	ErrServerIsRunning                  = 400021
-	ErrStartAsForeground                = 400022
-	ErrSavePidToFile                    = 400023
-	ErrKillServerWithPid                = 400024
-	ErrKillServerWithPidFile            = 400025
-	ErrGetPidFromPidFile                = 400026
-	ErrSetSid                           = 400027
-	ErrRemovePidFile                    = 400028
-	ErrNotValidDBAddr                   = 400029
-	ErrNotValidDBName                   = 400030
-	ErrNotValidDBUser                   = 400031
-	ErrNotValidDBPass                   = 400032
-	ErrNotValidDBPoolMaxConnections     = 400033
-	ErrNotValidDBPoolInitConnections    = 400034
-	ErrNotValidDBPoolMaxIdleConnections = 400035
-	ErrNotValidDBPoolMaxIdleTime        = 400036
-	ErrNotValidDBPoolKeepAliveInterval  = 400037
-	ErrInitConnectionPool               = 400038
-	ErrNotValidServerReadTimeout        = 400039
-	ErrNotValidServerWriteTimeout       = 400040
-	ErrNotValidServerAddr               = 400041
-	ErrFieldNotExists                   = 400042
-	ErrGetRawData                       = 400043
-	ErrUnmarshalRawData                 = 400044
-	ErrGenerateNewMapWithTag            = 400045
-	ErrMarshalData                      = 400046
-	ErrTypeConversion                   = 400047
-	ErrNotValidTimeLayout               = 400048
-	ErrNotValidTimeDuration             = 400049
-	ErrEmptySoarBin                     = 400050
-	ErrNotValidSoarBin                  = 400051
-	ErrEmptySoarConfig                  = 400052
-	ErrNotValidSoarConfig               = 400053
-	ErrEmptySoarBlacklist               = 400054
-	ErrNotValidSoarBlacklist            = 400055
+	ErrPrintHelpInfo                      = 400001
+	ErrEmptyLogFileName                   = 400002
+	ErrNotValidLogFileName                = 400003
+	ErrNotValidLogLevel                   = 400004
+	ErrNotValidLogFormat                  = 400005
+	ErrNotValidLogMaxSize                 = 400006
+	ErrNotValidLogMaxDays                 = 400007
+	ErrNotValidLogMaxBackups              = 400008
+	ErrNotValidServerPort                 = 400009
+	ErrNotValidPidFile                    = 400010
+	ErrValidateConfig                     = 400011
+	ErrInitDefaultConfig                  = 400012
+	ErrReadConfigFile                     = 400013
+	ErrOverrideCommandLineArgs            = 400014
+	ErrAbsoluteLogFilePath                = 400015
+	ErrInitLogger                         = 400016
+	ErrBaseDir                            = 400017
+	ErrInitConfig                         = 400018
+	ErrCheckServerPid                     = 400019
+	ErrCheckServerRunningStatus           = 400020
+	ErrServerIsRunning                    = 400021
+	ErrStartAsForeground                  = 400022
+	ErrSavePidToFile                      = 400023
+	ErrKillServerWithPid                  = 400024
+	ErrKillServerWithPidFile              = 400025
+	ErrGetPidFromPidFile                  = 400026
+	ErrSetSid                             = 400027
+	ErrRemovePidFile                      = 400028
+	ErrNotValidDBAddr                     = 400029
+	ErrNotValidDBName                     = 400030
+	ErrNotValidDBUser                     = 400031
+	ErrNotValidDBPass                     = 400032
+	ErrNotValidDBPoolMaxConnections       = 400033
+	ErrNotValidDBPoolInitConnections      = 400034
+	ErrNotValidDBPoolMaxIdleConnections   = 400035
+	ErrNotValidDBPoolMaxIdleTime          = 400036
+	ErrNotValidDBPoolKeepAliveInterval    = 400037
+	ErrInitConnectionPool                 = 400038
+	ErrNotValidServerReadTimeout          = 400039
+	ErrNotValidServerWriteTimeout         = 400040
+	ErrNotValidServerAddr                 = 400041
+	ErrFieldNotExists                     = 400042
+	ErrGetRawData                         = 400043
+	ErrUnmarshalRawData                   = 400044
+	ErrGenerateNewMapWithTag              = 400045
+	ErrMarshalData                        = 400046
+	ErrTypeConversion                     = 400047
+	ErrNotValidTimeLayout                 = 400048
+	ErrNotValidTimeDuration               = 400049
+	ErrEmptySoarBin                       = 400050
+	ErrNotValidSoarBin                    = 400051
+	ErrEmptySoarConfig                    = 400052
+	ErrNotValidSoarConfig                 = 400053
+	ErrEmptySoarBlacklist                 = 400054
+	ErrNotValidSoarBlacklist              = 400055
+	ErrNotValidHealthcheckItemTimeout     = 400056
+	ErrNotValidNotifyRetryCount           = 400057
+	ErrNotValidNotifyRetryInterval        = 400058
+	ErrNotValidNotifyTimeout              = 400059
+	ErrEmptyNotifySMTPFrom                = 400060
+	ErrNotValidMiddlewareAdminPort        = 400061
+	ErrNotValidCapacityCollectorInterval  = 400062
+	ErrNotValidCapacityCollectorRetention = 400063
+	ErrEmptyNotifyFileDir                 = 400064
+	ErrReplayNoCassette                   = 400065
+	ErrReplayInteractionNotRecorded       = 400066
+	ErrReplayValueTypeNotSupported        = 400067
 )
 
 func initErrorMessage() {
@@ -118,4 +130,16 @@ func initErrorMessage() {
 	Messages[ErrNotValidSoarConfig] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidSoarConfig, "soar config path must be either unix or windows path format, %s is not valid")
 	Messages[ErrEmptySoarBlacklist] = config.NewErrMessage(DefaultMessageHeader, ErrEmptySoarBlacklist, "soar blacklist path could not be an empty string")
 	Messages[ErrNotValidSoarBlacklist] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidSoarBlacklist, "soar blacklist path must be either unix or windows path format, %s is not valid")
+	Messages[ErrNotValidHealthcheckItemTimeout] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidHealthcheckItemTimeout, "healthcheck item timeout must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidNotifyRetryCount] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidNotifyRetryCount, "healthcheck notify retry count must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidNotifyRetryInterval] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidNotifyRetryInterval, "healthcheck notify retry interval must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidNotifyTimeout] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidNotifyTimeout, "healthcheck notify timeout must be between %d and %d, %d is not valid")
+	Messages[ErrEmptyNotifySMTPFrom] = config.NewErrMessage(DefaultMessageHeader, ErrEmptyNotifySMTPFrom, "healthcheck notify smtp from address could not be an empty string if the smtp address is specified")
+	Messages[ErrNotValidMiddlewareAdminPort] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidMiddlewareAdminPort, "healthcheck middleware admin port must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidCapacityCollectorInterval] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidCapacityCollectorInterval, "capacity collector interval must be between %d and %d, %d is not valid")
+	Messages[ErrNotValidCapacityCollectorRetention] = config.NewErrMessage(DefaultMessageHeader, ErrNotValidCapacityCollectorRetention, "capacity collector retention must be between %d and %d, %d is not valid")
+	Messages[ErrEmptyNotifyFileDir] = config.NewErrMessage(DefaultMessageHeader, ErrEmptyNotifyFileDir, "healthcheck notify file directory could not be an empty string")
+	Messages[ErrReplayNoCassette] = config.NewErrMessage(DefaultMessageHeader, ErrReplayNoCassette, "no cassette is inserted into the pool. command: %s")
+	Messages[ErrReplayInteractionNotRecorded] = config.NewErrMessage(DefaultMessageHeader, ErrReplayInteractionNotRecorded, "interaction is not recorded in the cassette or has been replayed. cassette: %s, command: %s")
+	Messages[ErrReplayValueTypeNotSupported] = config.NewErrMessage(DefaultMessageHeader, ErrReplayValueTypeNotSupported, "value type %s of column %s is not supported or does not match the other values of the column")
 }
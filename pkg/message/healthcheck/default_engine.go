@@ -29,6 +29,19 @@ const (
 	ErrMaxScoreDeductionMediumItemInvalid     = 401010
 	ErrItemWeightSummaryInvalid               = 401011
 	ErrPmmVersionInvalid                      = 401012
+	ErrItemConfigNotFound                     = 401019
+	ErrCheckItemAlreadyRegistered             = 401020
+	ErrCheckItemTimeout                       = 401021
+	ErrCheckItemUnavailable                   = 401022
+	ErrAllCheckItemsUnavailable               = 401023
+	ErrCheckItemCanceled                      = 401045
+	ErrHealthcheckOperationCanceled           = 401046
+	ErrDBConfigRuleOperatorInvalid            = 401053
+	ErrDBConfigRuleSeverityInvalid            = 401054
+	ErrDBConfigRuleRoleInvalid                = 401055
+	ErrDBConfigRuleEmpty                      = 401056
+	ErrReplicationGTIDSetInvalid              = 401057
+	ErrScoringStrategyItemInvalid             = 401060
 )
 
 func initDefaultEngineDebugMessage() {
@@ -52,4 +65,17 @@ func initDefaultEngineErrorMessage() {
 	message.Messages[ErrMaxScoreDeductionMediumItemInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrMaxScoreDeductionMediumItemInvalid, "max score deduction medium of %s must be in [1, 100], %f is not valid")
 	message.Messages[ErrItemWeightSummaryInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrItemWeightSummaryInvalid, "summary of all item weights should be 100, %d is not valid")
 	message.Messages[ErrPmmVersionInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrPmmVersionInvalid, "pmm version should be 1 or 2, %d is not valid")
+	message.Messages[ErrItemConfigNotFound] = config.NewErrMessage(message.DefaultMessageHeader, ErrItemConfigNotFound, "config of check item %s could not be found in the engine config")
+	message.Messages[ErrCheckItemAlreadyRegistered] = config.NewErrMessage(message.DefaultMessageHeader, ErrCheckItemAlreadyRegistered, "check item %s has already been registered")
+	message.Messages[ErrCheckItemTimeout] = config.NewErrMessage(message.DefaultMessageHeader, ErrCheckItemTimeout, "check item %s did not complete in %d seconds")
+	message.Messages[ErrCheckItemUnavailable] = config.NewErrMessage(message.DefaultMessageHeader, ErrCheckItemUnavailable, "check item %s is unavailable. operation_id: %d\n%s")
+	message.Messages[ErrAllCheckItemsUnavailable] = config.NewErrMessage(message.DefaultMessageHeader, ErrAllCheckItemsUnavailable, "all check items are unavailable, could not summarize the score")
+	message.Messages[ErrCheckItemCanceled] = config.NewErrMessage(message.DefaultMessageHeader, ErrCheckItemCanceled, "check item %s was canceled")
+	message.Messages[ErrHealthcheckOperationCanceled] = config.NewErrMessage(message.DefaultMessageHeader, ErrHealthcheckOperationCanceled, "healthcheck was canceled. operation_id: %d")
+	message.Messages[ErrDBConfigRuleOperatorInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrDBConfigRuleOperatorInvalid, "operator of db config rule must be one of [eq, gte, in, regex], %s is not valid. rule_id: %d")
+	message.Messages[ErrDBConfigRuleSeverityInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrDBConfigRuleSeverityInvalid, "severity of db config rule must be in [1, 3], %d is not valid. rule_id: %d")
+	message.Messages[ErrDBConfigRuleRoleInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrDBConfigRuleRoleInvalid, "role of db config rule must be in [0, 2], %d is not valid. rule_id: %d")
+	message.Messages[ErrDBConfigRuleEmpty] = config.NewErrMessage(message.DefaultMessageHeader, ErrDBConfigRuleEmpty, "db config rule set should not be empty")
+	message.Messages[ErrReplicationGTIDSetInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrReplicationGTIDSetInvalid, "gtid set is not valid. gtid set: %s")
+	message.Messages[ErrScoringStrategyItemInvalid] = config.NewErrMessage(message.DefaultMessageHeader, ErrScoringStrategyItemInvalid, "scoring strategy of %s must be one of mean, p95, p99, time_above_watermark and longest_breach, %s is not valid")
 }
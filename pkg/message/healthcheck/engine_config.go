@@ -0,0 +1,147 @@
+package healthcheck
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initEngineConfigDebugMessage()
+	initEngineConfigInfoMessage()
+	initEngineConfigErrorMessage()
+}
+
+const (
+	// debug
+	DebugHealthcheckGetEngineConfig            = 101020
+	DebugHealthcheckAddEngineConfigItems       = 101021
+	DebugHealthcheckUpdateEngineConfigItems    = 101022
+	DebugHealthcheckDeleteEngineConfigItems    = 101023
+	DebugHealthcheckGetEngineConfigOverrides   = 101024
+	DebugHealthcheckSaveEngineConfigOverride   = 101025
+	DebugHealthcheckDeleteEngineConfigOverride = 101026
+	// info
+	InfoHealthcheckGetEngineConfig            = 201022
+	InfoHealthcheckAddEngineConfigItems       = 201023
+	InfoHealthcheckUpdateEngineConfigItems    = 201024
+	InfoHealthcheckDeleteEngineConfigItems    = 201025
+	InfoHealthcheckGetEngineConfigOverrides   = 201026
+	InfoHealthcheckSaveEngineConfigOverride   = 201027
+	InfoHealthcheckDeleteEngineConfigOverride = 201028
+	// error
+	ErrHealthcheckGetEngineConfig            = 401061
+	ErrHealthcheckAddEngineConfigItems       = 401062
+	ErrHealthcheckUpdateEngineConfigItems    = 401063
+	ErrHealthcheckDeleteEngineConfigItems    = 401064
+	ErrEngineConfigVersionNotExists          = 401065
+	ErrEngineConfigChangeEmpty               = 401066
+	ErrEngineConfigItemAlreadyExists         = 401067
+	ErrEngineConfigItemNotExists             = 401068
+	ErrEngineConfigItemDuplicated            = 401069
+	ErrEngineConfigItemNameInvalid           = 401070
+	ErrHealthcheckGetEngineConfigOverrides   = 401071
+	ErrHealthcheckSaveEngineConfigOverride   = 401072
+	ErrHealthcheckDeleteEngineConfigOverride = 401073
+	ErrEngineConfigOverrideScopeTypeInvalid  = 401074
+	ErrEngineConfigOverrideConfigInvalid     = 401075
+	ErrEngineConfigOverrideItemIgnored       = 401076
+)
+
+func initEngineConfigDebugMessage() {
+	message.Messages[DebugHealthcheckGetEngineConfig] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetEngineConfig,
+		"healthcheck: get engine config message: %s")
+	message.Messages[DebugHealthcheckAddEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckAddEngineConfigItems,
+		"healthcheck: add engine config items message: %s")
+	message.Messages[DebugHealthcheckUpdateEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckUpdateEngineConfigItems,
+		"healthcheck: update engine config items message: %s")
+	message.Messages[DebugHealthcheckDeleteEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckDeleteEngineConfigItems,
+		"healthcheck: delete engine config items message: %s")
+	message.Messages[DebugHealthcheckGetEngineConfigOverrides] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetEngineConfigOverrides,
+		"healthcheck: get engine config overrides message: %s")
+	message.Messages[DebugHealthcheckSaveEngineConfigOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckSaveEngineConfigOverride,
+		"healthcheck: save engine config override message: %s")
+	message.Messages[DebugHealthcheckDeleteEngineConfigOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckDeleteEngineConfigOverride,
+		"healthcheck: delete engine config override message: %s")
+}
+
+func initEngineConfigInfoMessage() {
+	message.Messages[InfoHealthcheckGetEngineConfig] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetEngineConfig,
+		"healthcheck: get engine config completed. version: %d")
+	message.Messages[InfoHealthcheckAddEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckAddEngineConfigItems,
+		"healthcheck: add engine config items completed. version: %d")
+	message.Messages[InfoHealthcheckUpdateEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckUpdateEngineConfigItems,
+		"healthcheck: update engine config items completed. version: %d")
+	message.Messages[InfoHealthcheckDeleteEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckDeleteEngineConfigItems,
+		"healthcheck: delete engine config items completed. version: %d")
+	message.Messages[InfoHealthcheckGetEngineConfigOverrides] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetEngineConfigOverrides,
+		"healthcheck: get engine config overrides completed")
+	message.Messages[InfoHealthcheckSaveEngineConfigOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckSaveEngineConfigOverride,
+		"healthcheck: save engine config override completed. scope_type: %d, scope_id: %d, item_name: %s")
+	message.Messages[InfoHealthcheckDeleteEngineConfigOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckDeleteEngineConfigOverride,
+		"healthcheck: delete engine config override completed. id: %d")
+}
+
+func initEngineConfigErrorMessage() {
+	message.Messages[ErrHealthcheckGetEngineConfig] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetEngineConfig,
+		"healthcheck: get engine config failed.\n%s")
+	message.Messages[ErrHealthcheckAddEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckAddEngineConfigItems,
+		"healthcheck: add engine config items failed.\n%s")
+	message.Messages[ErrHealthcheckUpdateEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckUpdateEngineConfigItems,
+		"healthcheck: update engine config items failed.\n%s")
+	message.Messages[ErrHealthcheckDeleteEngineConfigItems] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckDeleteEngineConfigItems,
+		"healthcheck: delete engine config items failed.\n%s")
+	message.Messages[ErrEngineConfigVersionNotExists] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigVersionNotExists,
+		"healthcheck: engine config version does not exist. version: %d")
+	message.Messages[ErrEngineConfigChangeEmpty] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigChangeEmpty,
+		"healthcheck: engine config change must contain at least one item")
+	message.Messages[ErrEngineConfigItemAlreadyExists] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigItemAlreadyExists,
+		"healthcheck: engine config item already exists in the latest version. item_name: %s")
+	message.Messages[ErrEngineConfigItemNotExists] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigItemNotExists,
+		"healthcheck: engine config item does not exist in the latest version. item_name: %s")
+	message.Messages[ErrEngineConfigItemDuplicated] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigItemDuplicated,
+		"healthcheck: engine config item could be changed only once in a change. item_name: %s")
+	message.Messages[ErrEngineConfigItemNameInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigItemNameInvalid,
+		"healthcheck: engine config item name must be one of the registered check items(%s), %s is not valid")
+	message.Messages[ErrHealthcheckGetEngineConfigOverrides] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetEngineConfigOverrides,
+		"healthcheck: get engine config overrides failed.\n%s")
+	message.Messages[ErrHealthcheckSaveEngineConfigOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckSaveEngineConfigOverride,
+		"healthcheck: save engine config override failed. scope_type: %d, scope_id: %d, item_name: %s\n%s")
+	message.Messages[ErrHealthcheckDeleteEngineConfigOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckDeleteEngineConfigOverride,
+		"healthcheck: delete engine config override failed. id: %d\n%s")
+	message.Messages[ErrEngineConfigOverrideScopeTypeInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigOverrideScopeTypeInvalid,
+		"healthcheck: engine config override scope type must be one of 1(env), 2(app level), 3(mysql cluster), %d is not valid")
+	message.Messages[ErrEngineConfigOverrideConfigInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigOverrideConfigInvalid,
+		"healthcheck: engine config override must be a json object of the watermarks, unit, score deductions and scoring strategy, item weight could not be overridden. config: %s\n%s")
+	message.Messages[ErrEngineConfigOverrideItemIgnored] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrEngineConfigOverrideItemIgnored,
+		"healthcheck: item of the engine config override does not exist in the engine config, ignored. id: %d, item_name: %s")
+}
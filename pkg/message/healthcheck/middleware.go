@@ -0,0 +1,79 @@
+package healthcheck
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initMiddlewareDebugMessage()
+	initMiddlewareInfoMessage()
+	initMiddlewareErrorMessage()
+}
+
+const (
+	// debug
+	DebugHealthcheckCheckByMiddlewareClusterID = 101035
+	DebugHealthcheckGetMiddlewareResult        = 101036
+	DebugHealthcheckCancelMiddlewareOperation  = 101037
+	// info
+	InfoHealthcheckCheckByMiddlewareClusterID = 201038
+	InfoHealthcheckGetMiddlewareResult        = 201039
+	InfoHealthcheckCancelMiddlewareOperation  = 201040
+	// error
+	ErrHealthcheckCheckByMiddlewareClusterID    = 401099
+	ErrHealthcheckGetMiddlewareResult           = 401100
+	ErrHealthcheckMiddlewareClusterIsRunning    = 401101
+	ErrHealthcheckMiddlewareClusterEmpty        = 401102
+	ErrHealthcheckSummarizeMiddleware           = 401103
+	ErrHealthcheckCancelMiddlewareOperation     = 401105
+	ErrHealthcheckMiddlewareOperationNotRunning = 401106
+)
+
+func initMiddlewareDebugMessage() {
+	message.Messages[DebugHealthcheckCheckByMiddlewareClusterID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckCheckByMiddlewareClusterID,
+		"healthcheck: check by middleware cluster id message: %s")
+	message.Messages[DebugHealthcheckGetMiddlewareResult] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetMiddlewareResult,
+		"healthcheck: get middleware result message: %s")
+	message.Messages[DebugHealthcheckCancelMiddlewareOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckCancelMiddlewareOperation,
+		"healthcheck: cancel middleware operation message: %s")
+}
+
+func initMiddlewareInfoMessage() {
+	message.Messages[InfoHealthcheckCheckByMiddlewareClusterID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckCheckByMiddlewareClusterID,
+		"healthcheck: check by middleware cluster id started. middleware_cluster_id: %d, middleware_operation_id: %d")
+	message.Messages[InfoHealthcheckGetMiddlewareResult] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetMiddlewareResult,
+		"healthcheck: get middleware result by middleware operation id completed. middleware_operation_id: %d")
+	message.Messages[InfoHealthcheckCancelMiddlewareOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckCancelMiddlewareOperation,
+		"healthcheck: cancel middleware operation requested. middleware_operation_id: %d")
+}
+
+func initMiddlewareErrorMessage() {
+	message.Messages[ErrHealthcheckCheckByMiddlewareClusterID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckCheckByMiddlewareClusterID,
+		"healthcheck: check by middleware cluster id failed. middleware_cluster_id: %d\n%s")
+	message.Messages[ErrHealthcheckGetMiddlewareResult] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetMiddlewareResult,
+		"healthcheck: get middleware result by middleware operation id failed. middleware_operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckMiddlewareClusterIsRunning] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckMiddlewareClusterIsRunning,
+		"healthcheck: healthcheck of middleware cluster is still running. middleware_cluster_id: %d")
+	message.Messages[ErrHealthcheckMiddlewareClusterEmpty] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckMiddlewareClusterEmpty,
+		"healthcheck: middleware cluster does not have any middleware server. middleware_cluster_id: %d")
+	message.Messages[ErrHealthcheckSummarizeMiddleware] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckSummarizeMiddleware,
+		"healthcheck: summarize middleware result failed. middleware_operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckCancelMiddlewareOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckCancelMiddlewareOperation,
+		"healthcheck: cancel middleware operation failed. middleware_operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckMiddlewareOperationNotRunning] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckMiddlewareOperationNotRunning,
+		"healthcheck: middleware operation is not running on this server. middleware_operation_id: %d")
+}
@@ -0,0 +1,127 @@
+package healthcheck
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initNotifyDebugMessage()
+	initNotifyInfoMessage()
+	initNotifyErrorMessage()
+}
+
+const (
+	// debug
+	DebugHealthcheckGetNotifyRuleAll = 101027
+	DebugHealthcheckCreateNotifyRule = 101028
+	DebugHealthcheckDeleteNotifyRule = 101029
+	DebugHealthcheckGetNotifyLogs    = 101030
+	// info
+	InfoHealthcheckGetNotifyRuleAll = 201029
+	InfoHealthcheckCreateNotifyRule = 201030
+	InfoHealthcheckDeleteNotifyRule = 201031
+	InfoHealthcheckGetNotifyLogs    = 201032
+	InfoHealthcheckNotifyDelivered  = 201033
+	// error
+	ErrNotifyRuleTriggerTypeInvalid   = 401077
+	ErrNotifyRuleSinkTypeInvalid      = 401078
+	ErrNotifyRuleThresholdInvalid     = 401079
+	ErrNotifyRuleTargetInvalid        = 401080
+	ErrHealthcheckGetNotifyRuleAll    = 401081
+	ErrHealthcheckCreateNotifyRule    = 401082
+	ErrHealthcheckDeleteNotifyRule    = 401083
+	ErrHealthcheckGetNotifyLogs       = 401084
+	ErrHealthcheckNotify              = 401085
+	ErrHealthcheckNotifyDeliver       = 401086
+	ErrNotifySinkNotExists            = 401087
+	ErrNotifySMTPAddrEmpty            = 401088
+	ErrNotifyOwnerEmailNotExists      = 401089
+	ErrNotifyWebhookStatusCodeInvalid = 401090
+	ErrNotifyRuleNameInvalid          = 401109
+	ErrNotifyEmailHeaderInvalid       = 401110
+)
+
+func initNotifyDebugMessage() {
+	message.Messages[DebugHealthcheckGetNotifyRuleAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetNotifyRuleAll,
+		"healthcheck: get all notify rules message: %s")
+	message.Messages[DebugHealthcheckCreateNotifyRule] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckCreateNotifyRule,
+		"healthcheck: create notify rule message: %s")
+	message.Messages[DebugHealthcheckDeleteNotifyRule] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckDeleteNotifyRule,
+		"healthcheck: delete notify rule message: %s")
+	message.Messages[DebugHealthcheckGetNotifyLogs] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetNotifyLogs,
+		"healthcheck: get notify logs message: %s")
+}
+
+func initNotifyInfoMessage() {
+	message.Messages[InfoHealthcheckGetNotifyRuleAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetNotifyRuleAll,
+		"healthcheck: get all notify rules completed")
+	message.Messages[InfoHealthcheckCreateNotifyRule] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckCreateNotifyRule,
+		"healthcheck: create notify rule completed. rule_name: %s")
+	message.Messages[InfoHealthcheckDeleteNotifyRule] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckDeleteNotifyRule,
+		"healthcheck: delete notify rule completed. id: %d")
+	message.Messages[InfoHealthcheckGetNotifyLogs] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetNotifyLogs,
+		"healthcheck: get notify logs completed. operation_id: %d")
+	message.Messages[InfoHealthcheckNotifyDelivered] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckNotifyDelivered,
+		"healthcheck: notification delivered. rule_id: %d, operation_id: %d, target: %s, attempts: %d")
+}
+
+func initNotifyErrorMessage() {
+	message.Messages[ErrNotifyRuleTriggerTypeInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyRuleTriggerTypeInvalid,
+		"healthcheck: notify rule trigger type must be one of 1(failure), 2(score below threshold), 3(score regression), %d is not valid")
+	message.Messages[ErrNotifyRuleSinkTypeInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyRuleSinkTypeInvalid,
+		"healthcheck: notify rule sink type must be one of 1(webhook), 2(email), 3(file), %d is not valid")
+	message.Messages[ErrNotifyRuleThresholdInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyRuleThresholdInvalid,
+		"healthcheck: notify rule threshold must be larger than 0 and not larger than 100 if the trigger type is 2 or 3, %d is not valid")
+	message.Messages[ErrNotifyRuleTargetInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyRuleTargetInvalid,
+		"healthcheck: notify rule target must be a http(s) url if the sink type is 1(webhook), must be empty or an email address if the sink type is 2(email), and must be a relative path without .. if the sink type is 3(file), %s is not valid")
+	message.Messages[ErrHealthcheckGetNotifyRuleAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetNotifyRuleAll,
+		"healthcheck: get all notify rules failed.\n%s")
+	message.Messages[ErrHealthcheckCreateNotifyRule] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckCreateNotifyRule,
+		"healthcheck: create notify rule failed. rule_name: %s\n%s")
+	message.Messages[ErrHealthcheckDeleteNotifyRule] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckDeleteNotifyRule,
+		"healthcheck: delete notify rule failed. id: %d\n%s")
+	message.Messages[ErrHealthcheckGetNotifyLogs] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetNotifyLogs,
+		"healthcheck: get notify logs failed. operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckNotify] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckNotify,
+		"healthcheck: notify the outcome of the operation failed. operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckNotifyDeliver] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckNotifyDeliver,
+		"healthcheck: deliver notification failed. rule_id: %d, operation_id: %d, target: %s, attempts: %d\n%s")
+	message.Messages[ErrNotifySinkNotExists] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifySinkNotExists,
+		"healthcheck: notify sink does not exist. sink_type: %d")
+	message.Messages[ErrNotifySMTPAddrEmpty] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifySMTPAddrEmpty,
+		"healthcheck: smtp address is not configured, email notification could not be sent")
+	message.Messages[ErrNotifyOwnerEmailNotExists] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyOwnerEmailNotExists,
+		"healthcheck: email of the mysql cluster owner does not exist. mysql_cluster_id: %d")
+	message.Messages[ErrNotifyWebhookStatusCodeInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyWebhookStatusCodeInvalid,
+		"healthcheck: webhook responded with unexpected status code. url: %s, status_code: %d, body: %s")
+	message.Messages[ErrNotifyRuleNameInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyRuleNameInvalid,
+		"healthcheck: notify rule name must not contain line breaks, %q is not valid")
+	message.Messages[ErrNotifyEmailHeaderInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrNotifyEmailHeaderInvalid,
+		"healthcheck: email header must not contain line breaks. header: %s, value: %q")
+}
@@ -0,0 +1,75 @@
+package healthcheck
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initPrometheusCatalogueDebugMessage()
+	initPrometheusCatalogueInfoMessage()
+	initPrometheusCatalogueErrorMessage()
+}
+
+const (
+	// debug
+	DebugHealthcheckGetPrometheusCatalogue            = 101032
+	DebugHealthcheckSavePrometheusCatalogueOverride   = 101033
+	DebugHealthcheckDeletePrometheusCatalogueOverride = 101034
+	// info
+	InfoHealthcheckGetPrometheusCatalogue            = 201035
+	InfoHealthcheckSavePrometheusCatalogueOverride   = 201036
+	InfoHealthcheckDeletePrometheusCatalogueOverride = 201037
+	// error
+	ErrHealthcheckGetPrometheusCatalogue            = 401093
+	ErrHealthcheckSavePrometheusCatalogueOverride   = 401094
+	ErrHealthcheckDeletePrometheusCatalogueOverride = 401095
+	ErrPrometheusCatalogueSystemTypeInvalid         = 401096
+	ErrPrometheusCatalogueNameInvalid               = 401097
+	ErrPrometheusCatalogueTemplateInvalid           = 401098
+)
+
+func initPrometheusCatalogueDebugMessage() {
+	message.Messages[DebugHealthcheckGetPrometheusCatalogue] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetPrometheusCatalogue,
+		"healthcheck: get prometheus catalogue message: %s")
+	message.Messages[DebugHealthcheckSavePrometheusCatalogueOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckSavePrometheusCatalogueOverride,
+		"healthcheck: save prometheus catalogue override message: %s")
+	message.Messages[DebugHealthcheckDeletePrometheusCatalogueOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckDeletePrometheusCatalogueOverride,
+		"healthcheck: delete prometheus catalogue override message: %s")
+}
+
+func initPrometheusCatalogueInfoMessage() {
+	message.Messages[InfoHealthcheckGetPrometheusCatalogue] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetPrometheusCatalogue,
+		"healthcheck: get prometheus catalogue completed. monitor_system_id: %d")
+	message.Messages[InfoHealthcheckSavePrometheusCatalogueOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckSavePrometheusCatalogueOverride,
+		"healthcheck: save prometheus catalogue override completed. monitor_system_id: %d, name: %s")
+	message.Messages[InfoHealthcheckDeletePrometheusCatalogueOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckDeletePrometheusCatalogueOverride,
+		"healthcheck: delete prometheus catalogue override completed. id: %d")
+}
+
+func initPrometheusCatalogueErrorMessage() {
+	message.Messages[ErrHealthcheckGetPrometheusCatalogue] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetPrometheusCatalogue,
+		"healthcheck: get prometheus catalogue failed. monitor_system_id: %d\n%s")
+	message.Messages[ErrHealthcheckSavePrometheusCatalogueOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckSavePrometheusCatalogueOverride,
+		"healthcheck: save prometheus catalogue override failed. monitor_system_id: %d, name: %s\n%s")
+	message.Messages[ErrHealthcheckDeletePrometheusCatalogueOverride] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckDeletePrometheusCatalogueOverride,
+		"healthcheck: delete prometheus catalogue override failed. id: %d\n%s")
+	message.Messages[ErrPrometheusCatalogueSystemTypeInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrPrometheusCatalogueSystemTypeInvalid,
+		"healthcheck: monitor system type should be 1(pmm 1.x), 2(pmm 2.x) or 3(prometheus), %d is not valid")
+	message.Messages[ErrPrometheusCatalogueNameInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrPrometheusCatalogueNameInvalid,
+		"healthcheck: prometheus catalogue name is not valid. name: %s")
+	message.Messages[ErrPrometheusCatalogueTemplateInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrPrometheusCatalogueTemplateInvalid,
+		"healthcheck: prometheus catalogue template is not valid. name: %s, template: %s\n%s")
+}
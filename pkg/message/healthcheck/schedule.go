@@ -0,0 +1,143 @@
+package healthcheck
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initScheduleDebugMessage()
+	initScheduleInfoMessage()
+	initScheduleErrorMessage()
+}
+
+const (
+	// debug
+	DebugHealthcheckGetScheduleAll  = 101007
+	DebugHealthcheckGetScheduleByID = 101008
+	DebugHealthcheckCreateSchedule  = 101009
+	DebugHealthcheckPauseSchedule   = 101010
+	DebugHealthcheckResumeSchedule  = 101011
+	DebugHealthcheckDeleteSchedule  = 101012
+	// info
+	InfoHealthcheckGetScheduleAll         = 201007
+	InfoHealthcheckGetScheduleByID        = 201008
+	InfoHealthcheckCreateSchedule         = 201009
+	InfoHealthcheckPauseSchedule          = 201010
+	InfoHealthcheckResumeSchedule         = 201011
+	InfoHealthcheckDeleteSchedule         = 201012
+	InfoHealthcheckScheduleRun            = 201013
+	InfoHealthcheckScheduleSkipped        = 201014
+	InfoHealthcheckScheduleClusterSkipped = 201041
+	// error
+	ErrScheduleCronExprInvalid        = 401029
+	ErrScheduleTargetTypeInvalid      = 401030
+	ErrScheduleWindowInvalid          = 401031
+	ErrScheduleStepInvalid            = 401032
+	ErrHealthcheckGetScheduleAll      = 401033
+	ErrHealthcheckGetScheduleByID     = 401034
+	ErrHealthcheckCreateSchedule      = 401035
+	ErrHealthcheckPauseSchedule       = 401036
+	ErrHealthcheckResumeSchedule      = 401037
+	ErrHealthcheckDeleteSchedule      = 401038
+	ErrHealthcheckScheduleRun         = 401039
+	ErrHealthcheckScheduleLoad        = 401040
+	ErrHealthcheckScheduleNoNextRun   = 401041
+	ErrHealthcheckScheduleTargetEmpty = 401042
+)
+
+func initScheduleDebugMessage() {
+	message.Messages[DebugHealthcheckGetScheduleAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetScheduleAll,
+		"healthcheck: get all schedules message: %s")
+	message.Messages[DebugHealthcheckGetScheduleByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetScheduleByID,
+		"healthcheck: get schedule by id message: %s")
+	message.Messages[DebugHealthcheckCreateSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckCreateSchedule,
+		"healthcheck: create schedule message: %s")
+	message.Messages[DebugHealthcheckPauseSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckPauseSchedule,
+		"healthcheck: pause schedule message: %s")
+	message.Messages[DebugHealthcheckResumeSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckResumeSchedule,
+		"healthcheck: resume schedule message: %s")
+	message.Messages[DebugHealthcheckDeleteSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckDeleteSchedule,
+		"healthcheck: delete schedule message: %s")
+}
+
+func initScheduleInfoMessage() {
+	message.Messages[InfoHealthcheckGetScheduleAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetScheduleAll,
+		"healthcheck: get all schedules completed")
+	message.Messages[InfoHealthcheckGetScheduleByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetScheduleByID,
+		"healthcheck: get schedule by id completed. id: %d")
+	message.Messages[InfoHealthcheckCreateSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckCreateSchedule,
+		"healthcheck: create schedule completed. schedule_name: %s")
+	message.Messages[InfoHealthcheckPauseSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckPauseSchedule,
+		"healthcheck: pause schedule completed. id: %d")
+	message.Messages[InfoHealthcheckResumeSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckResumeSchedule,
+		"healthcheck: resume schedule completed. id: %d")
+	message.Messages[InfoHealthcheckDeleteSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckDeleteSchedule,
+		"healthcheck: delete schedule completed. id: %d")
+	message.Messages[InfoHealthcheckScheduleRun] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckScheduleRun,
+		"healthcheck: schedule triggered. id: %d, target_type: %d, target_id: %d, start_time: %s, end_time: %s")
+	message.Messages[InfoHealthcheckScheduleSkipped] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckScheduleSkipped,
+		"healthcheck: healthcheck of mysql server is still running, skipped. schedule_id: %d, mysql_server_id: %d")
+	message.Messages[InfoHealthcheckScheduleClusterSkipped] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckScheduleClusterSkipped,
+		"healthcheck: healthcheck of mysql cluster or any of its mysql servers is still running, skipped. schedule_id: %d, mysql_cluster_id: %d")
+}
+
+func initScheduleErrorMessage() {
+	message.Messages[ErrScheduleCronExprInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrScheduleCronExprInvalid,
+		"healthcheck: cron expression must have 5 fields(minute, hour, day of month, month, day of week), each field supports *, value, list, range and step, %s is not valid")
+	message.Messages[ErrScheduleTargetTypeInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrScheduleTargetTypeInvalid,
+		"healthcheck: schedule target type must be one of 1(mysql server), 2(mysql cluster), 3(env), 4(app), %d is not valid")
+	message.Messages[ErrScheduleWindowInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrScheduleWindowInvalid,
+		"healthcheck: schedule window must be longer than step, window: %s, step: %s")
+	message.Messages[ErrScheduleStepInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrScheduleStepInvalid,
+		"healthcheck: schedule step must be at least 1 second, %s is not valid")
+	message.Messages[ErrHealthcheckGetScheduleAll] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetScheduleAll,
+		"healthcheck: get all schedules failed.\n%s")
+	message.Messages[ErrHealthcheckGetScheduleByID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetScheduleByID,
+		"healthcheck: get schedule by id failed. id: %d\n%s")
+	message.Messages[ErrHealthcheckCreateSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckCreateSchedule,
+		"healthcheck: create schedule failed. schedule_name: %s\n%s")
+	message.Messages[ErrHealthcheckPauseSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckPauseSchedule,
+		"healthcheck: pause schedule failed. id: %d\n%s")
+	message.Messages[ErrHealthcheckResumeSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckResumeSchedule,
+		"healthcheck: resume schedule failed. id: %d\n%s")
+	message.Messages[ErrHealthcheckDeleteSchedule] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckDeleteSchedule,
+		"healthcheck: delete schedule failed. id: %d\n%s")
+	message.Messages[ErrHealthcheckScheduleRun] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckScheduleRun,
+		"healthcheck: run schedule failed. id: %d\n%s")
+	message.Messages[ErrHealthcheckScheduleLoad] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckScheduleLoad,
+		"healthcheck: load due schedules failed.\n%s")
+	message.Messages[ErrHealthcheckScheduleNoNextRun] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckScheduleNoNextRun,
+		"healthcheck: cron expression will never be triggered. cron_expr: %s")
+	message.Messages[ErrHealthcheckScheduleTargetEmpty] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckScheduleTargetEmpty,
+		"healthcheck: schedule target does not have any mysql cluster. target_type: %d, target_id: %d")
+}
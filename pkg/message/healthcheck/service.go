@@ -17,11 +17,29 @@ const (
 	DebugHealthcheckCheck                  = 101002
 	DebugHealthcheckCheckByHostInfo        = 101003
 	DebugHealthcheckReviewAccuracy         = 101004
+	DebugHealthcheckCheckByMySQLClusterID  = 101005
+	DebugHealthcheckGetClusterResult       = 101006
+	DebugHealthcheckGetScoreTrend          = 101013
+	DebugHealthcheckGetResultDiff          = 101014
+	DebugHealthcheckGetOperations          = 101015
+	DebugHealthcheckGetOperationStatus     = 101016
+	DebugHealthcheckCancelOperation        = 101017
+	DebugHealthcheckRetryOperation         = 101018
+	DebugHealthcheckGetReport              = 101019
 	// info
 	InfoHealthcheckGetResultByOperationID = 201001
 	InfoHealthcheckCheck                  = 201002
 	InfoHealthcheckCheckByHostInfo        = 201003
 	InfoHealthcheckReviewAccuracy         = 201004
+	InfoHealthcheckCheckByMySQLClusterID  = 201005
+	InfoHealthcheckGetClusterResult       = 201006
+	InfoHealthcheckGetScoreTrend          = 201015
+	InfoHealthcheckGetResultDiff          = 201016
+	InfoHealthcheckGetOperations          = 201017
+	InfoHealthcheckGetOperationStatus     = 201018
+	InfoHealthcheckCancelOperation        = 201019
+	InfoHealthcheckRetryOperation         = 201020
+	InfoHealthcheckGetReport              = 201021
 	// error
 	ErrHealthcheckDefaultEngineRun       = 401013
 	ErrHealthcheckGetResultByOperationID = 401014
@@ -29,6 +47,23 @@ const (
 	ErrHealthcheckCheckByHostInfo        = 401016
 	ErrHealthcheckReviewAccuracy         = 401017
 	ErrHealthcheckCloseConnection        = 401018
+	ErrHealthcheckCheckByMySQLClusterID  = 401024
+	ErrHealthcheckGetClusterResult       = 401025
+	ErrHealthcheckMySQLClusterIsRunning  = 401026
+	ErrHealthcheckMySQLClusterEmpty      = 401027
+	ErrHealthcheckSummarizeCluster       = 401028
+	ErrHealthcheckGetScoreTrend          = 401043
+	ErrHealthcheckGetResultDiff          = 401044
+	ErrHealthcheckGetOperations          = 401047
+	ErrHealthcheckGetOperationStatus     = 401048
+	ErrHealthcheckCancelOperation        = 401049
+	ErrHealthcheckRetryOperation         = 401050
+	ErrHealthcheckOperationNotRunning    = 401051
+	ErrHealthcheckOperationNotRetryable  = 401052
+	ErrHealthcheckGetReport              = 401058
+	ErrHealthcheckReportFormatInvalid    = 401059
+	ErrHealthcheckMySQLClusterMemberInit = 401104
+	ErrHealthcheckMySQLClusterAllFailed  = 401108
 )
 
 func initServiceDebugMessage() {
@@ -44,6 +79,33 @@ func initServiceDebugMessage() {
 	message.Messages[DebugHealthcheckReviewAccuracy] = config.NewErrMessage(
 		message.DefaultMessageHeader, DebugHealthcheckReviewAccuracy,
 		"healthcheck: review accuracy message: %s")
+	message.Messages[DebugHealthcheckCheckByMySQLClusterID] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckCheckByMySQLClusterID,
+		"healthcheck: check by mysql cluster id message: %s")
+	message.Messages[DebugHealthcheckGetClusterResult] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetClusterResult,
+		"healthcheck: get cluster result by cluster operation id message: %s")
+	message.Messages[DebugHealthcheckGetScoreTrend] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetScoreTrend,
+		"healthcheck: get score trend by mysql server id message: %s")
+	message.Messages[DebugHealthcheckGetResultDiff] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetResultDiff,
+		"healthcheck: get result diff by operation ids message: %s")
+	message.Messages[DebugHealthcheckGetOperations] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetOperations,
+		"healthcheck: get operations message: %s")
+	message.Messages[DebugHealthcheckGetOperationStatus] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetOperationStatus,
+		"healthcheck: get operation status message: %s")
+	message.Messages[DebugHealthcheckCancelOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckCancelOperation,
+		"healthcheck: cancel operation message: %s")
+	message.Messages[DebugHealthcheckRetryOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckRetryOperation,
+		"healthcheck: retry operation message: %s")
+	message.Messages[DebugHealthcheckGetReport] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckGetReport,
+		"healthcheck: get report by operation id message: %s")
 }
 
 func initServiceInfoMessage() {
@@ -59,6 +121,33 @@ func initServiceInfoMessage() {
 	message.Messages[InfoHealthcheckReviewAccuracy] = config.NewErrMessage(
 		message.DefaultMessageHeader, InfoHealthcheckReviewAccuracy,
 		"healthcheck: review accuracy completed. %s")
+	message.Messages[InfoHealthcheckCheckByMySQLClusterID] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckCheckByMySQLClusterID,
+		"healthcheck: check by mysql cluster id completed. mysql_cluster_id: %d, cluster_operation_id: %d")
+	message.Messages[InfoHealthcheckGetClusterResult] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetClusterResult,
+		"healthcheck: get cluster result by cluster operation id completed. cluster_operation_id: %d")
+	message.Messages[InfoHealthcheckGetScoreTrend] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetScoreTrend,
+		"healthcheck: get score trend by mysql server id completed. mysql_server_id: %d")
+	message.Messages[InfoHealthcheckGetResultDiff] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetResultDiff,
+		"healthcheck: get result diff by operation ids completed. base_operation_id: %d, target_operation_id: %d")
+	message.Messages[InfoHealthcheckGetOperations] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetOperations,
+		"healthcheck: get operations completed")
+	message.Messages[InfoHealthcheckGetOperationStatus] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetOperationStatus,
+		"healthcheck: get operation status completed. operation_id: %d")
+	message.Messages[InfoHealthcheckCancelOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckCancelOperation,
+		"healthcheck: cancel operation completed. operation_id: %d")
+	message.Messages[InfoHealthcheckRetryOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckRetryOperation,
+		"healthcheck: retry operation completed. operation_id: %d, new_operation_id: %d")
+	message.Messages[InfoHealthcheckGetReport] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckGetReport,
+		"healthcheck: get report by operation id completed. operation_id: %d, format: %s")
 }
 
 func initServiceErrorMessage() {
@@ -77,5 +166,55 @@ func initServiceErrorMessage() {
 	message.Messages[ErrHealthcheckCloseConnection] = config.NewErrMessage(
 		message.DefaultMessageHeader, ErrHealthcheckCloseConnection,
 		"healthcheck: close middleware connection failed.\n%s")
-
+	message.Messages[ErrHealthcheckCheckByMySQLClusterID] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckCheckByMySQLClusterID,
+		"healthcheck: check by mysql cluster id failed. mysql_cluster_id: %d\n%s")
+	message.Messages[ErrHealthcheckGetClusterResult] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetClusterResult,
+		"healthcheck: get cluster result by cluster operation id failed. cluster_operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckMySQLClusterIsRunning] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckMySQLClusterIsRunning,
+		"healthcheck: healthcheck of mysql cluster is still running. mysql_cluster_id: %d")
+	message.Messages[ErrHealthcheckMySQLClusterEmpty] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckMySQLClusterEmpty,
+		"healthcheck: mysql cluster does not have any mysql server. mysql_cluster_id: %d")
+	message.Messages[ErrHealthcheckSummarizeCluster] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckSummarizeCluster,
+		"healthcheck: summarize cluster result failed. cluster_operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckMySQLClusterMemberInit] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckMySQLClusterMemberInit,
+		"healthcheck: init member operation failed, the cluster healthcheck is aborted. cluster_operation_id: %d, mysql_server_id: %d\n%s")
+	message.Messages[ErrHealthcheckMySQLClusterAllFailed] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckMySQLClusterAllFailed,
+		"healthcheck: all the member operations failed. cluster_operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckGetScoreTrend] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetScoreTrend,
+		"healthcheck: get score trend by mysql server id failed. mysql_server_id: %d\n%s")
+	message.Messages[ErrHealthcheckGetResultDiff] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetResultDiff,
+		"healthcheck: get result diff by operation ids failed. base_operation_id: %d, target_operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckGetOperations] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetOperations,
+		"healthcheck: get operations failed.\n%s")
+	message.Messages[ErrHealthcheckGetOperationStatus] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetOperationStatus,
+		"healthcheck: get operation status failed. operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckCancelOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckCancelOperation,
+		"healthcheck: cancel operation failed. operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckRetryOperation] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckRetryOperation,
+		"healthcheck: retry operation failed. operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckOperationNotRunning] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckOperationNotRunning,
+		"healthcheck: operation is not running on this server. operation_id: %d, status: %d")
+	message.Messages[ErrHealthcheckOperationNotRetryable] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckOperationNotRetryable,
+		"healthcheck: only failed or canceled operation could be retried. operation_id: %d, status: %d")
+	message.Messages[ErrHealthcheckGetReport] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckGetReport,
+		"healthcheck: get report by operation id failed. operation_id: %d\n%s")
+	message.Messages[ErrHealthcheckReportFormatInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckReportFormatInvalid,
+		"healthcheck: report format should be one of html, markdown and json. format: %s")
 }
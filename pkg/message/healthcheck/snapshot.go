@@ -0,0 +1,47 @@
+package healthcheck
+
+import (
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/config"
+)
+
+func init() {
+	initSnapshotDebugMessage()
+	initSnapshotInfoMessage()
+	initSnapshotErrorMessage()
+}
+
+const (
+	// debug
+	DebugHealthcheckCheckBySnapshot = 101031
+	// info
+	InfoHealthcheckCheckBySnapshot = 201034
+	// error
+	ErrHealthcheckCheckBySnapshot                = 401091
+	ErrHealthcheckSnapshotInvalid                = 401092
+	ErrHealthcheckSnapshotPrimaryGTIDNotCaptured = 401107
+)
+
+func initSnapshotDebugMessage() {
+	message.Messages[DebugHealthcheckCheckBySnapshot] = config.NewErrMessage(
+		message.DefaultMessageHeader, DebugHealthcheckCheckBySnapshot,
+		"healthcheck: check by snapshot message: %s")
+}
+
+func initSnapshotInfoMessage() {
+	message.Messages[InfoHealthcheckCheckBySnapshot] = config.NewErrMessage(
+		message.DefaultMessageHeader, InfoHealthcheckCheckBySnapshot,
+		"healthcheck: check by snapshot started. mysql_server_id: %d")
+}
+
+func initSnapshotErrorMessage() {
+	message.Messages[ErrHealthcheckCheckBySnapshot] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckCheckBySnapshot,
+		"healthcheck: check by snapshot failed. mysql_server_id: %d\n%s")
+	message.Messages[ErrHealthcheckSnapshotInvalid] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckSnapshotInvalid,
+		"healthcheck: snapshot is not valid. %s")
+	message.Messages[ErrHealthcheckSnapshotPrimaryGTIDNotCaptured] = config.NewErrMessage(
+		message.DefaultMessageHeader, ErrHealthcheckSnapshotPrimaryGTIDNotCaptured,
+		"healthcheck: executed gtid set of the primary was not captured in the snapshot. primary: %s:%d")
+}
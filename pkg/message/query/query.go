@@ -17,11 +17,13 @@ const (
 	DebugQueryGetByMySQLServerID  = 103002
 	DebugQueryGetByDBID           = 103003
 	DebugQueryGetBySQLID          = 103004
+	DebugQueryGetTrendBySQLID     = 103005
 	// info
 	InfoQueryGetByMySQLClusterID = 203001
 	InfoQueryGetByMySQLServerID  = 203002
 	InfoQueryGetByDBID           = 203003
 	InfoQueryGetBySQLID          = 203004
+	InfoQueryGetTrendBySQLID     = 203005
 	// error
 	ErrQueryGetByMySQLClusterID = 403001
 	ErrQueryGetByMySQLServerID  = 403002
@@ -30,6 +32,10 @@ const (
 	ErrQueryConfigNotValid      = 403005
 	ErrQueryMonitorSystemType   = 403006
 	ErrQueryCloseMonitorRepo    = 403007
+	ErrQueryResponseNotRecorded = 403008
+	ErrQueryGetTrendBySQLID     = 403009
+	ErrQueryTrendStepNotValid   = 403010
+	ErrQueryOrderByNotSupported = 403011
 )
 
 func initQueryDebugMessage() {
@@ -37,6 +43,7 @@ func initQueryDebugMessage() {
 	message.Messages[DebugQueryGetByMySQLServerID] = config.NewErrMessage(message.DefaultMessageHeader, DebugQueryGetByMySQLServerID, "get by mysql server id completed. mysql_server_id: %d.\n%s")
 	message.Messages[DebugQueryGetByDBID] = config.NewErrMessage(message.DefaultMessageHeader, DebugQueryGetByDBID, "get by db id completed. db_id: %d.\n%s")
 	message.Messages[DebugQueryGetBySQLID] = config.NewErrMessage(message.DefaultMessageHeader, DebugQueryGetBySQLID, "get by sql id completed. mysql_server_id: %d, sql_id: %s.\n%s")
+	message.Messages[DebugQueryGetTrendBySQLID] = config.NewErrMessage(message.DefaultMessageHeader, DebugQueryGetTrendBySQLID, "get trend by sql id completed. mysql_server_id: %d, sql_id: %s.\n%s")
 }
 
 func initQueryInfoMessage() {
@@ -44,6 +51,7 @@ func initQueryInfoMessage() {
 	message.Messages[InfoQueryGetByMySQLServerID] = config.NewErrMessage(message.DefaultMessageHeader, InfoQueryGetByMySQLServerID, "get by mysql server id completed. mysql_server_id: %d.")
 	message.Messages[InfoQueryGetByDBID] = config.NewErrMessage(message.DefaultMessageHeader, InfoQueryGetByDBID, "get by db id completed. db_id: %d.")
 	message.Messages[InfoQueryGetBySQLID] = config.NewErrMessage(message.DefaultMessageHeader, InfoQueryGetBySQLID, "get by sql id completed. mysql_server_id: %d, sql_id: %s.")
+	message.Messages[InfoQueryGetTrendBySQLID] = config.NewErrMessage(message.DefaultMessageHeader, InfoQueryGetTrendBySQLID, "get trend by sql id completed. mysql_server_id: %d, sql_id: %s.")
 }
 
 func initQueryErrorMessage() {
@@ -51,7 +59,11 @@ func initQueryErrorMessage() {
 	message.Messages[ErrQueryGetByMySQLServerID] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryGetByMySQLServerID, "get by mysql server id failed. mysql_server_id: %d.\n%s")
 	message.Messages[ErrQueryGetByDBID] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryGetByDBID, "get by db id failed. db_id: %d.\n%s")
 	message.Messages[ErrQueryGetBySQLID] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryGetBySQLID, "get by sql id failed. mysql_server_id: %d, sql_id: %s.\n%s")
-	message.Messages[ErrQueryConfigNotValid] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryConfigNotValid, "config is not valid. start_time: %s, end_time: %s, limit: %d")
+	message.Messages[ErrQueryConfigNotValid] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryConfigNotValid, "config is not valid. start_time: %s, end_time: %s, limit: %d, order_by: %s")
 	message.Messages[ErrQueryMonitorSystemType] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryMonitorSystemType, "monitor system type version should be either 1 or 2, %d is not valid")
 	message.Messages[ErrQueryCloseMonitorRepo] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryCloseMonitorRepo, "close monitor repo failed.\n%s")
+	message.Messages[ErrQueryResponseNotRecorded] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryResponseNotRecorded, "response of the monitor repo is not recorded. request: %s")
+	message.Messages[ErrQueryGetTrendBySQLID] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryGetTrendBySQLID, "get trend by sql id failed. mysql_server_id: %d, sql_id: %s.\n%s")
+	message.Messages[ErrQueryTrendStepNotValid] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryTrendStepNotValid, "step of the trend should be a positive multiple of 60 seconds and there should be no more than %d buckets in the time range. step: %d")
+	message.Messages[ErrQueryOrderByNotSupported] = config.NewErrMessage(message.DefaultMessageHeader, ErrQueryOrderByNotSupported, "order by %s is not supported by pmm 1.x, it does not collect the metric")
 }
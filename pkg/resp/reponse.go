@@ -25,3 +25,12 @@ func ResponseOK(c *gin.Context, respMessage string, code int, values ...interfac
 
 	c.String(http.StatusOK, respMessage)
 }
+
+// ResponseOKWithContentType responses the data with given content type,
+// it is used when the response is not a json string, such as a html page
+func ResponseOKWithContentType(c *gin.Context, contentType string, data []byte, code int, values ...interface{}) {
+	msg := message.NewMessage(code, values...).Error()
+	log.Info(msg)
+
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -15,6 +15,7 @@ const (
 	endTimeJSON   = "end_time"
 	limitJSON     = "limit"
 	offsetJSON    = "offset"
+	orderByJSON   = "order_by"
 )
 
 func GetConfig(dataMap map[string]string) (*query.Config, error) {
@@ -60,9 +61,14 @@ func GetConfig(dataMap map[string]string) (*query.Config, error) {
 
 		config.SetLimit(offset)
 	}
+	// get order by
+	orderBy, exists := dataMap[orderByJSON]
+	if exists {
+		config.SetOrderBy(orderBy)
+	}
 	// validate config
 	if !config.IsValid() {
-		return nil, message.NewMessage(msgquery.ErrQueryConfigNotValid, config.GetStartTime(), config.GetEndTime(), config.GetLimit())
+		return nil, message.NewMessage(msgquery.ErrQueryConfigNotValid, config.GetStartTime(), config.GetEndTime(), config.GetLimit(), config.GetOrderBy())
 	}
 
 	return config, nil
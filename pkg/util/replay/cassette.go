@@ -0,0 +1,354 @@
+package replay
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/romberli/go-util/middleware/mysql"
+	"github.com/romberli/go-util/middleware/result"
+)
+
+const (
+	cassetteFileExt  = ".json"
+	cassetteFileMode = 0644
+	cassetteIndent   = "  "
+
+	middlewareType = "mysql"
+
+	valueTypeInt64   = "int64"
+	valueTypeUint64  = "uint64"
+	valueTypeFloat64 = "float64"
+	valueTypeString  = "string"
+	valueTypeBytes   = "bytes"
+
+	decimalBase   = 10
+	uint64BitSize = 64
+)
+
+// Interaction is a command executed on the pool and the response of it
+type Interaction struct {
+	Command      string          `json:"command"`
+	Args         json.RawMessage `json:"args,omitempty"`
+	Columns      []string        `json:"columns,omitempty"`
+	Types        []string        `json:"types,omitempty"`
+	Rows         [][]interface{} `json:"rows,omitempty"`
+	LastInsertID int             `json:"last_insert_id,omitempty"`
+	RowsAffected int             `json:"rows_affected,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	replayed     bool
+}
+
+// newInteraction returns a new *Interaction of given command and args
+func newInteraction(command string, args []interface{}) (*Interaction, error) {
+	argsJSON, err := marshalArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Interaction{
+		Command: normalizeCommand(command),
+		Args:    argsJSON,
+	}, nil
+}
+
+// setResult sets the response of the command
+func (i *Interaction) setResult(res middleware.Result, err error) error {
+	if err != nil {
+		i.Error = err.Error()
+
+		return nil
+	}
+
+	rows, err := getRows(res)
+	if err != nil {
+		return err
+	}
+	i.LastInsertID, err = res.LastInsertID()
+	if err != nil {
+		return err
+	}
+	i.RowsAffected, err = res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	i.Columns = rows.FieldSlice
+	i.Types = make([]string, len(i.Columns))
+	for _, row := range rows.Values {
+		values := make([]interface{}, len(row))
+		for j, value := range row {
+			valueType, jsonValue, err := encodeValue(value)
+			if err != nil {
+				return err
+			}
+			if valueType != constant.EmptyString {
+				if i.Types[j] != constant.EmptyString && i.Types[j] != valueType {
+					return message.NewMessage(message.ErrReplayValueTypeNotSupported, valueType, i.Columns[j])
+				}
+				i.Types[j] = valueType
+			}
+			values[j] = jsonValue
+		}
+		i.Rows = append(i.Rows, values)
+	}
+
+	return nil
+}
+
+// getResult returns the recorded response of the command
+func (i *Interaction) getResult() (middleware.Result, error) {
+	if i.Error != constant.EmptyString {
+		return nil, errors.New(i.Error)
+	}
+
+	fieldMap := make(map[string]int, len(i.Columns))
+	for j, column := range i.Columns {
+		fieldMap[column] = j
+	}
+	values := make([][]driver.Value, len(i.Rows))
+	for j, row := range i.Rows {
+		values[j] = make([]driver.Value, len(row))
+		for k, value := range row {
+			v, err := decodeValue(i.Types[k], value)
+			if err != nil {
+				return nil, err
+			}
+			values[j][k] = v
+		}
+	}
+
+	return &Result{
+		Rows:         result.NewRows(i.Columns, fieldMap, values),
+		Map:          result.NewEmptyMap(middlewareType),
+		lastInsertID: i.LastInsertID,
+		rowsAffected: i.RowsAffected,
+	}, nil
+}
+
+// Cassette is the interactions recorded in a golden file
+type Cassette struct {
+	name         string
+	mutex        sync.Mutex
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// newCassette returns a new empty *Cassette
+func newCassette(name string) *Cassette {
+	return &Cassette{name: name}
+}
+
+// newCassetteWithFile reads the golden file of the cassette from given directory
+func newCassetteWithFile(dir, name string) (*Cassette, error) {
+	data, err := ioutil.ReadFile(getCassetteFileName(dir, name))
+	if err != nil {
+		return nil, err
+	}
+
+	c := newCassette(name)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// keep the numbers as they are, they will be converted with the recorded value types
+	decoder.UseNumber()
+	err = decoder.Decode(c)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range c.Interactions {
+		i.Args, err = compactArgs(i.Args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// GetName returns the name of the cassette
+func (c *Cassette) GetName() string {
+	return c.name
+}
+
+// record appends the interaction to the cassette
+func (c *Cassette) record(i *Interaction) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.Interactions = append(c.Interactions, i)
+}
+
+// replay returns the first interaction of the command which has not been replayed,
+// the interaction with the same args is preferred, as the args may contain the current time, they are not required to match,
+// so that the interactions of the concurrent commands could be replayed in any order
+func (c *Cassette) replay(command string, args []interface{}) (*Interaction, error) {
+	command = normalizeCommand(command)
+	argsJSON, err := marshalArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var matched *Interaction
+	for _, i := range c.Interactions {
+		if i.replayed || i.Command != command {
+			continue
+		}
+		if bytes.Equal(i.Args, argsJSON) {
+			matched = i
+			break
+		}
+		if matched == nil {
+			matched = i
+		}
+	}
+	if matched == nil {
+		return nil, message.NewMessage(message.ErrReplayInteractionNotRecorded, c.GetName(), command)
+	}
+	matched.replayed = true
+
+	return matched, nil
+}
+
+// save saves the cassette as the golden file in given directory
+func (c *Cassette) save(dir string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	data, err := json.MarshalIndent(c, constant.EmptyString, cassetteIndent)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(getCassetteFileName(dir, c.GetName()), data, cassetteFileMode)
+}
+
+var _ middleware.Result = (*Result)(nil)
+
+// Result is the recorded result of the mysql middleware
+type Result struct {
+	*result.Rows
+	result.Map
+	lastInsertID int
+	rowsAffected int
+}
+
+// LastInsertID returns the recorded auto-generated id
+func (r *Result) LastInsertID() (int, error) {
+	return r.lastInsertID, nil
+}
+
+// RowsAffected returns the recorded number of the affected rows
+func (r *Result) RowsAffected() (int, error) {
+	return r.rowsAffected, nil
+}
+
+// GetRaw returns the rows of the result, there is no raw result of the middleware
+func (r *Result) GetRaw() interface{} {
+	return r.Rows
+}
+
+// getRows returns the rows of the result, only the results of the mysql middleware and the replay pool are supported
+func getRows(res middleware.Result) (*result.Rows, error) {
+	switch r := res.(type) {
+	case *mysql.Result:
+		return r.Rows, nil
+	case *Result:
+		return r.Rows, nil
+	default:
+		return nil, message.NewMessage(message.ErrReplayValueTypeNotSupported, fmt.Sprintf("%T", res), constant.EmptyString)
+	}
+}
+
+// getCassetteFileName returns the golden file name of the cassette
+func getCassetteFileName(dir, name string) string {
+	return filepath.Join(dir, name+cassetteFileExt)
+}
+
+// normalizeCommand collapses the white spaces of the command, so that the indentation of the sql does not matter
+func normalizeCommand(command string) string {
+	return strings.Join(strings.Fields(command), constant.SpaceString)
+}
+
+// marshalArgs marshals the args to compact json
+func marshalArgs(args []interface{}) (json.RawMessage, error) {
+	if len(args) == constant.ZeroInt {
+		return nil, nil
+	}
+
+	return json.Marshal(args)
+}
+
+// compactArgs removes the indentation of the recorded args
+func compactArgs(args json.RawMessage) (json.RawMessage, error) {
+	if len(args) == constant.ZeroInt {
+		return nil, nil
+	}
+
+	buffer := &bytes.Buffer{}
+	err := json.Compact(buffer, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// encodeValue returns the type and the json value of the value returned by the mysql middleware,
+// the type is empty if the value is null
+func encodeValue(value driver.Value) (string, interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return constant.EmptyString, nil, nil
+	case int64:
+		return valueTypeInt64, v, nil
+	case uint64:
+		return valueTypeUint64, v, nil
+	case float64:
+		return valueTypeFloat64, v, nil
+	case string:
+		return valueTypeString, v, nil
+	case []byte:
+		return valueTypeBytes, string(v), nil
+	default:
+		return constant.EmptyString, nil, message.NewMessage(message.ErrReplayValueTypeNotSupported, fmt.Sprintf("%T", value), constant.EmptyString)
+	}
+}
+
+// decodeValue converts the json value to the value of given type
+func decodeValue(valueType string, value interface{}) (driver.Value, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch valueType {
+	case valueTypeInt64, valueTypeUint64, valueTypeFloat64:
+		number, ok := value.(json.Number)
+		if !ok {
+			return nil, message.NewMessage(message.ErrReplayValueTypeNotSupported, fmt.Sprintf("%T", value), valueType)
+		}
+		switch valueType {
+		case valueTypeInt64:
+			return number.Int64()
+		case valueTypeUint64:
+			return strconv.ParseUint(number.String(), decimalBase, uint64BitSize)
+		default:
+			return number.Float64()
+		}
+	case valueTypeString:
+		return fmt.Sprint(value), nil
+	case valueTypeBytes:
+		return []byte(fmt.Sprint(value)), nil
+	default:
+		return nil, message.NewMessage(message.ErrReplayValueTypeNotSupported, valueType, constant.EmptyString)
+	}
+}
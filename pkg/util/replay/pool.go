@@ -0,0 +1,404 @@
+package replay
+
+import (
+	"context"
+	"sync"
+
+	"github.com/romberli/das/pkg/message"
+	"github.com/romberli/go-util/middleware"
+)
+
+var (
+	_ CassettePool           = (*Pool)(nil)
+	_ CassettePool           = (*RecordingPool)(nil)
+	_ middleware.Transaction = (*Conn)(nil)
+	_ middleware.Statement   = (*Statement)(nil)
+	_ middleware.Transaction = (*RecordingConn)(nil)
+	_ middleware.Statement   = (*RecordingStatement)(nil)
+)
+
+// CassettePool is the pool which records or replays the interactions with the cassettes
+type CassettePool interface {
+	middleware.Pool
+	// Insert inserts the cassette of given name, the cassette which was inserted is ejected
+	Insert(name string) error
+	// Eject ejects the inserted cassette, it does nothing if no cassette is inserted
+	Eject() error
+}
+
+// cassetteHolder holds the cassette which is currently inserted
+type cassetteHolder struct {
+	mutex    sync.Mutex
+	dir      string
+	cassette *Cassette
+}
+
+// getDir returns the directory of the golden files
+func (ch *cassetteHolder) getDir() string {
+	return ch.dir
+}
+
+// getCassette returns the inserted cassette, it returns error if no cassette is inserted
+func (ch *cassetteHolder) getCassette(command string) (*Cassette, error) {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+
+	if ch.cassette == nil {
+		return nil, message.NewMessage(message.ErrReplayNoCassette, normalizeCommand(command))
+	}
+
+	return ch.cassette, nil
+}
+
+// swap inserts the new cassette and returns the ejected one
+func (ch *cassetteHolder) swap(cassette *Cassette) *Cassette {
+	ch.mutex.Lock()
+	defer ch.mutex.Unlock()
+
+	ejected := ch.cassette
+	ch.cassette = cassette
+
+	return ejected
+}
+
+// Pool replays the interactions recorded in the golden files instead of connecting to the database,
+// the golden file of each test is a cassette, it must be inserted before the test uses the pool
+type Pool struct {
+	cassetteHolder
+	isClosed bool
+}
+
+// NewPool returns a new *Pool which replays the golden files in given directory
+func NewPool(dir string) *Pool {
+	return &Pool{cassetteHolder: cassetteHolder{dir: dir}}
+}
+
+// Insert loads the cassette of given name from the golden file, every interaction of it could be replayed once
+func (p *Pool) Insert(name string) error {
+	cassette, err := newCassetteWithFile(p.getDir(), name)
+	if err != nil {
+		return err
+	}
+
+	p.swap(cassette)
+
+	return nil
+}
+
+// Eject ejects the inserted cassette, it does nothing if no cassette is inserted
+func (p *Pool) Eject() error {
+	p.swap(nil)
+
+	return nil
+}
+
+// Close closes the pool
+func (p *Pool) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.isClosed = true
+
+	return nil
+}
+
+// IsClosed returns if the pool had been closed
+func (p *Pool) IsClosed() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.isClosed
+}
+
+// Get returns a connection which replays the inserted cassette
+func (p *Pool) Get() (middleware.PoolConn, error) {
+	return &Conn{pool: p}, nil
+}
+
+// Transaction returns a connection which replays the inserted cassette, the transaction statements do nothing
+func (p *Pool) Transaction() (middleware.Transaction, error) {
+	return &Conn{pool: p}, nil
+}
+
+// Supply does nothing, there is no connection in the pool
+func (p *Pool) Supply(num int) error {
+	return nil
+}
+
+// Release does nothing, there is no connection in the pool
+func (p *Pool) Release(num int) error {
+	return nil
+}
+
+// execute replays the interaction of given command and args
+func (p *Pool) execute(command string, args ...interface{}) (middleware.Result, error) {
+	cassette, err := p.getCassette(command)
+	if err != nil {
+		return nil, err
+	}
+	interaction, err := cassette.replay(command, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return interaction.getResult()
+}
+
+// Conn is the connection of the replay pool
+type Conn struct {
+	pool *Pool
+}
+
+// Close does nothing, there is no connection to return to the pool
+func (c *Conn) Close() error {
+	return nil
+}
+
+// Disconnect does nothing, there is no connection to the database
+func (c *Conn) Disconnect() error {
+	return nil
+}
+
+// IsValid always returns true
+func (c *Conn) IsValid() bool {
+	return true
+}
+
+// Prepare returns a statement which replays given command
+func (c *Conn) Prepare(command string) (middleware.Statement, error) {
+	return &Statement{pool: c.pool, command: command}, nil
+}
+
+// PrepareContext returns a statement which replays given command
+func (c *Conn) PrepareContext(ctx context.Context, command string) (middleware.Statement, error) {
+	return c.Prepare(command)
+}
+
+// Execute replays the interaction of given command and args
+func (c *Conn) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	return c.pool.execute(command, args...)
+}
+
+// ExecuteContext replays the interaction of given command and args
+func (c *Conn) ExecuteContext(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	return c.Execute(command, args...)
+}
+
+// Begin does nothing, the statements of the transaction are replayed as they were recorded
+func (c *Conn) Begin() error {
+	return nil
+}
+
+// Commit does nothing, the statements of the transaction are replayed as they were recorded
+func (c *Conn) Commit() error {
+	return nil
+}
+
+// Rollback does nothing, the statements of the transaction are replayed as they were recorded
+func (c *Conn) Rollback() error {
+	return nil
+}
+
+// Statement is the prepared statement of the replay pool
+type Statement struct {
+	pool    *Pool
+	command string
+}
+
+// Execute replays the interaction of the command and given args
+func (s *Statement) Execute(args ...interface{}) (middleware.Result, error) {
+	return s.pool.execute(s.command, args...)
+}
+
+// ExecuteContext replays the interaction of the command and given args
+func (s *Statement) ExecuteContext(ctx context.Context, args ...interface{}) (middleware.Result, error) {
+	return s.Execute(args...)
+}
+
+// RecordingPool decorates the pool and records the interactions to the inserted cassette,
+// the cassette is saved as the golden file when it is ejected
+type RecordingPool struct {
+	cassetteHolder
+	pool middleware.Pool
+}
+
+// NewRecordingPool returns a new *RecordingPool which saves the golden files to given directory
+func NewRecordingPool(pool middleware.Pool, dir string) *RecordingPool {
+	return &RecordingPool{
+		cassetteHolder: cassetteHolder{dir: dir},
+		pool:           pool,
+	}
+}
+
+// Insert inserts a new empty cassette of given name, the cassette which was inserted is ejected
+func (rp *RecordingPool) Insert(name string) error {
+	return rp.save(rp.swap(newCassette(name)))
+}
+
+// Eject ejects the inserted cassette and saves it as the golden file, it does nothing if no cassette is inserted
+func (rp *RecordingPool) Eject() error {
+	return rp.save(rp.swap(nil))
+}
+
+// save saves the cassette as the golden file
+func (rp *RecordingPool) save(cassette *Cassette) error {
+	if cassette == nil {
+		return nil
+	}
+
+	return cassette.save(rp.getDir())
+}
+
+// Close closes the decorated pool
+func (rp *RecordingPool) Close() error {
+	return rp.pool.Close()
+}
+
+// IsClosed returns if the decorated pool had been closed
+func (rp *RecordingPool) IsClosed() bool {
+	return rp.pool.IsClosed()
+}
+
+// Get returns a connection of the decorated pool which records the interactions
+func (rp *RecordingPool) Get() (middleware.PoolConn, error) {
+	conn, err := rp.pool.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordingConn{pool: rp, conn: conn}, nil
+}
+
+// Transaction returns a transaction of the decorated pool which records the interactions
+func (rp *RecordingPool) Transaction() (middleware.Transaction, error) {
+	tx, err := rp.pool.Transaction()
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordingConn{pool: rp, conn: tx, tx: tx}, nil
+}
+
+// Supply supplies the connections to the decorated pool
+func (rp *RecordingPool) Supply(num int) error {
+	return rp.pool.Supply(num)
+}
+
+// Release releases the connections of the decorated pool
+func (rp *RecordingPool) Release(num int) error {
+	return rp.pool.Release(num)
+}
+
+// record executes the command with given function and records the interaction to the inserted cassette
+func (rp *RecordingPool) record(command string, args []interface{},
+	execute func(args ...interface{}) (middleware.Result, error)) (middleware.Result, error) {
+	cassette, err := rp.getCassette(command)
+	if err != nil {
+		return nil, err
+	}
+	// the args are marshaled before executing, the decorated connection may change them
+	interaction, err := newInteraction(command, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, executeErr := execute(args...)
+	err = interaction.setResult(result, executeErr)
+	if err != nil {
+		return nil, err
+	}
+	cassette.record(interaction)
+
+	return result, executeErr
+}
+
+// RecordingConn decorates the connection of the pool and records the interactions,
+// the transaction statements are delegated to the decorated transaction and are not recorded
+type RecordingConn struct {
+	pool *RecordingPool
+	conn middleware.PoolConn
+	tx   middleware.Transaction
+}
+
+// Close returns the decorated connection to the pool
+func (rc *RecordingConn) Close() error {
+	return rc.conn.Close()
+}
+
+// Disconnect disconnects the decorated connection
+func (rc *RecordingConn) Disconnect() error {
+	return rc.conn.Disconnect()
+}
+
+// IsValid returns if the decorated connection is valid
+func (rc *RecordingConn) IsValid() bool {
+	return rc.conn.IsValid()
+}
+
+// Prepare prepares the statement on the decorated connection
+func (rc *RecordingConn) Prepare(command string) (middleware.Statement, error) {
+	stmt, err := rc.conn.Prepare(command)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordingStatement{pool: rc.pool, command: command, stmt: stmt}, nil
+}
+
+// PrepareContext prepares the statement with context on the decorated connection
+func (rc *RecordingConn) PrepareContext(ctx context.Context, command string) (middleware.Statement, error) {
+	stmt, err := rc.conn.PrepareContext(ctx, command)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordingStatement{pool: rc.pool, command: command, stmt: stmt}, nil
+}
+
+// Execute executes the command on the decorated connection and records the interaction
+func (rc *RecordingConn) Execute(command string, args ...interface{}) (middleware.Result, error) {
+	return rc.pool.record(command, args, func(args ...interface{}) (middleware.Result, error) {
+		return rc.conn.Execute(command, args...)
+	})
+}
+
+// ExecuteContext executes the command with context on the decorated connection and records the interaction
+func (rc *RecordingConn) ExecuteContext(ctx context.Context, command string, args ...interface{}) (middleware.Result, error) {
+	return rc.pool.record(command, args, func(args ...interface{}) (middleware.Result, error) {
+		return rc.conn.ExecuteContext(ctx, command, args...)
+	})
+}
+
+// Begin begins the decorated transaction
+func (rc *RecordingConn) Begin() error {
+	return rc.tx.Begin()
+}
+
+// Commit commits the decorated transaction
+func (rc *RecordingConn) Commit() error {
+	return rc.tx.Commit()
+}
+
+// Rollback rollbacks the decorated transaction
+func (rc *RecordingConn) Rollback() error {
+	return rc.tx.Rollback()
+}
+
+// RecordingStatement decorates the prepared statement and records the interactions
+type RecordingStatement struct {
+	pool    *RecordingPool
+	command string
+	stmt    middleware.Statement
+}
+
+// Execute executes the decorated statement and records the interaction
+func (rs *RecordingStatement) Execute(args ...interface{}) (middleware.Result, error) {
+	return rs.pool.record(rs.command, args, rs.stmt.Execute)
+}
+
+// ExecuteContext executes the decorated statement with context and records the interaction
+func (rs *RecordingStatement) ExecuteContext(ctx context.Context, args ...interface{}) (middleware.Result, error) {
+	return rs.pool.record(rs.command, args, func(args ...interface{}) (middleware.Result, error) {
+		return rs.stmt.ExecuteContext(ctx, args...)
+	})
+}
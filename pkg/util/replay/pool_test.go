@@ -0,0 +1,148 @@
+package replay
+
+import (
+	"testing"
+
+	"github.com/romberli/go-util/common"
+	"github.com/romberli/go-util/constant"
+	"github.com/romberli/go-util/middleware"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDataDir      = "testdata"
+	testCassetteName = "replay"
+	testRecordedName = "recorded"
+
+	testSelectSQL   = "select id, name, score, create_time from t_test where id = ?"
+	testInsertSQL   = "insert into t_test(name) values(?)"
+	testNotExistSQL = "select id from t_not_exists"
+)
+
+type testEntity struct {
+	ID         int     `middleware:"id"`
+	Name       string  `middleware:"name"`
+	Score      float64 `middleware:"score"`
+	CreateTime string  `middleware:"create_time"`
+}
+
+func TestPoolAll(t *testing.T) {
+	TestPool_Execute(t)
+	TestPool_Transaction(t)
+	TestRecordingPool_Execute(t)
+}
+
+func TestPool_Execute(t *testing.T) {
+	asst := assert.New(t)
+
+	pool := NewPool(testDataDir)
+	conn, err := pool.Get()
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	// no cassette is inserted
+	_, err = conn.Execute(testSelectSQL, 1)
+	asst.NotNil(err, "test Execute() failed")
+
+	err = pool.Insert(testCassetteName)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	// the interaction with the same args is replayed first, the white spaces of the command do not matter
+	result, err := conn.Execute("select id, name, score, create_time\n\tfrom t_test\n\twhere id = ?", 2)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	entities := []*testEntity{{}}
+	err = result.MapToStructSlice(entities, constant.DefaultMiddlewareTag)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	asst.Equal(&testEntity{ID: 2, Name: "b", CreateTime: "2021-01-01 00:00:01"}, entities[0], "test Execute() failed")
+	// the args are not required to match
+	result, err = conn.Execute(testSelectSQL, 3)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	score, err := result.GetFloat(0, 2)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	asst.Equal(1.5, score, "test Execute() failed")
+	// every interaction is replayed once
+	_, err = conn.Execute(testSelectSQL, 1)
+	asst.NotNil(err, "test Execute() failed")
+
+	result, err = conn.Execute(testInsertSQL, "b")
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	id, err := result.LastInsertID()
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	asst.Equal(2, id, "test Execute() failed")
+	_, err = conn.Execute(testNotExistSQL)
+	asst.NotNil(err, "test Execute() failed")
+
+	// the cassette is rewound when it is inserted again
+	err = pool.Insert(testCassetteName)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	_, err = conn.Execute(testSelectSQL, 1)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+
+	err = pool.Eject()
+	asst.Nil(err, common.CombineMessageWithError("test Execute() failed", err))
+	_, err = conn.Execute(testSelectSQL, 2)
+	asst.NotNil(err, "test Execute() failed")
+	// the cassette which does not exist
+	asst.NotNil(pool.Insert("not_exists"), "test Execute() failed")
+}
+
+func TestPool_Transaction(t *testing.T) {
+	asst := assert.New(t)
+
+	pool := NewPool(testDataDir)
+	err := pool.Insert(testCassetteName)
+	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
+	tx, err := pool.Transaction()
+	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
+	err = tx.Begin()
+	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
+	result, err := tx.Execute(testInsertSQL, "b")
+	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
+	rowsAffected, err := result.RowsAffected()
+	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
+	asst.Equal(1, rowsAffected, "test Transaction() failed")
+	err = tx.Commit()
+	asst.Nil(err, common.CombineMessageWithError("test Transaction() failed", err))
+}
+
+func TestRecordingPool_Execute(t *testing.T) {
+	asst := assert.New(t)
+
+	// record the interactions replayed by the replay pool
+	replayPool := NewPool(testDataDir)
+	err := replayPool.Insert(testCassetteName)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+	dir := t.TempDir()
+	pool := NewRecordingPool(replayPool, dir)
+	conn, err := pool.Get()
+	asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+	// no cassette is inserted into the recording pool
+	_, err = conn.Execute(testSelectSQL, 1)
+	asst.NotNil(err, "test Execute() with recording pool failed")
+
+	err = pool.Insert(testRecordedName)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+	execute := func(conn middleware.PoolConn) []middleware.Result {
+		var results []middleware.Result
+		for _, args := range [][]interface{}{{1}, {2}} {
+			result, err := conn.Execute(testSelectSQL, args...)
+			asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+			results = append(results, result)
+		}
+		result, err := conn.Execute(testInsertSQL, "b")
+		asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+		results = append(results, result)
+		_, err = conn.Execute(testNotExistSQL)
+		asst.NotNil(err, "test Execute() with recording pool failed")
+
+		return results
+	}
+	expected := execute(conn)
+	err = pool.Eject()
+	asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+
+	// replay the recorded golden file
+	replayPool = NewPool(dir)
+	err = replayPool.Insert(testRecordedName)
+	asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+	conn, err = replayPool.Get()
+	asst.Nil(err, common.CombineMessageWithError("test Execute() with recording pool failed", err))
+	asst.Equal(expected, execute(conn), "test Execute() with recording pool failed")
+}
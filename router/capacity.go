@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/romberli/das/api/v1/capacity"
+)
+
+// RegisterCapacity is the sub-router of das for capacity
+func RegisterCapacity(group *gin.RouterGroup) {
+	capacityGroup := group.Group("/capacity")
+	{
+		capacityGroup.GET("/forecast/:mysql_server_id", capacity.GetForecastByMySQLServerID)
+		// growth
+		capacityGroup.POST("/growth/table", capacity.GetTableGrowthsByMySQLServerID)
+		capacityGroup.POST("/growth/db", capacity.GetDBGrowthByID)
+		capacityGroup.POST("/growth/app", capacity.GetAppGrowthByID)
+	}
+}
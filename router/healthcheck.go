@@ -10,8 +10,47 @@ func RegisterHealthcheck(group *gin.RouterGroup) {
 	healthcheckGroup := group.Group("/healthcheck")
 	{
 		healthcheckGroup.GET("/result/:operation_id", healthcheck.GetResultByOperationID)
+		healthcheckGroup.GET("/result/cluster/:cluster_operation_id", healthcheck.GetClusterResultByOperationID)
+		healthcheckGroup.GET("/result/middleware/:middleware_operation_id", healthcheck.GetMiddlewareResultByOperationID)
+		healthcheckGroup.GET("/result/diff/:base_operation_id/:target_operation_id", healthcheck.GetResultDiffByOperationIDs)
+		healthcheckGroup.POST("/result/trend", healthcheck.GetScoreTrendByMySQLServerID)
+		healthcheckGroup.GET("/report/:operation_id", healthcheck.GetReportByOperationID)
 		healthcheckGroup.POST("/check", healthcheck.Check)
 		healthcheckGroup.POST("/check/host-info", healthcheck.CheckByHostInfo)
+		healthcheckGroup.POST("/check/cluster", healthcheck.CheckByMySQLClusterID)
+		healthcheckGroup.POST("/check/middleware-cluster", healthcheck.CheckByMiddlewareClusterID)
+		healthcheckGroup.POST("/check/snapshot", healthcheck.CheckBySnapshot)
 		healthcheckGroup.POST("/review", healthcheck.ReviewAccuracy)
+		// operation
+		healthcheckGroup.POST("/operation", healthcheck.GetOperations)
+		healthcheckGroup.GET("/operation/status/:operation_id", healthcheck.GetOperationStatus)
+		healthcheckGroup.POST("/operation/cancel/:operation_id", healthcheck.CancelOperation)
+		healthcheckGroup.POST("/operation/cancel/middleware/:middleware_operation_id", healthcheck.CancelMiddlewareOperation)
+		healthcheckGroup.POST("/operation/retry/:operation_id", healthcheck.RetryOperation)
+		// schedule
+		healthcheckGroup.GET("/schedule", healthcheck.GetSchedule)
+		healthcheckGroup.GET("/schedule/get/:id", healthcheck.GetScheduleByID)
+		healthcheckGroup.POST("/schedule", healthcheck.AddSchedule)
+		healthcheckGroup.POST("/schedule/pause/:id", healthcheck.PauseScheduleByID)
+		healthcheckGroup.POST("/schedule/resume/:id", healthcheck.ResumeScheduleByID)
+		healthcheckGroup.POST("/schedule/delete/:id", healthcheck.DeleteScheduleByID)
+		// engine config
+		healthcheckGroup.GET("/engine-config", healthcheck.GetEngineConfig)
+		healthcheckGroup.GET("/engine-config/version/:config_version", healthcheck.GetEngineConfigByVersion)
+		healthcheckGroup.POST("/engine-config/item", healthcheck.AddEngineConfigItems)
+		healthcheckGroup.POST("/engine-config/item/update", healthcheck.UpdateEngineConfigItems)
+		healthcheckGroup.POST("/engine-config/item/delete", healthcheck.DeleteEngineConfigItems)
+		healthcheckGroup.GET("/engine-config/override", healthcheck.GetEngineConfigOverrides)
+		healthcheckGroup.POST("/engine-config/override", healthcheck.SaveEngineConfigOverride)
+		healthcheckGroup.POST("/engine-config/override/delete/:id", healthcheck.DeleteEngineConfigOverride)
+		// notify
+		healthcheckGroup.GET("/notify-rule", healthcheck.GetNotifyRuleAll)
+		healthcheckGroup.POST("/notify-rule", healthcheck.CreateNotifyRule)
+		healthcheckGroup.POST("/notify-rule/delete/:id", healthcheck.DeleteNotifyRuleByID)
+		healthcheckGroup.GET("/notify-log/operation/:operation_id", healthcheck.GetNotifyLogsByOperationID)
+		// prometheus catalogue
+		healthcheckGroup.GET("/prometheus-catalogue/:monitor_system_id", healthcheck.GetPrometheusCatalogue)
+		healthcheckGroup.POST("/prometheus-catalogue", healthcheck.SavePrometheusCatalogueOverride)
+		healthcheckGroup.POST("/prometheus-catalogue/delete/:id", healthcheck.DeletePrometheusCatalogueOverride)
 	}
 }
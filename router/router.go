@@ -47,6 +47,10 @@ func (gr *GinRouter) Register() {
 		RegisterHealthcheck(v1)
 		// sqladvisor
 		RegisterSQLAdvisor(v1)
+		// capacity
+		RegisterCapacity(v1)
+		// query
+		RegisterQuery(v1)
 	}
 }
 
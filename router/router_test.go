@@ -0,0 +1,60 @@
+package router
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouterAll(t *testing.T) {
+	TestGinRouter_RegisterQuery(t)
+	TestGinRouter_RegisterHealthcheck(t)
+}
+
+func TestGinRouter_RegisterQuery(t *testing.T) {
+	asst := assert.New(t)
+
+	gin.SetMode(gin.TestMode)
+	gr := &GinRouter{gin.New()}
+	gr.Register()
+
+	routes := make(map[string]bool)
+	for _, route := range gr.Engine.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	asst.True(routes["GET /api/v1/query/:sql_id"], "test RegisterQuery() failed")
+	asst.True(routes["POST /api/v1/query/trend/:sql_id"], "test RegisterQuery() failed")
+
+	// the request reaches the handler, which complains about the missing mysql server id
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/query/trend/999ECD050D719733", strings.NewReader("{}"))
+	gr.ServeHTTP(w, req)
+	asst.NotEqual(http.StatusNotFound, w.Code, "test RegisterQuery() failed")
+	asst.Contains(w.Body.String(), "mysql_server_id", "test RegisterQuery() failed")
+}
+
+func TestGinRouter_RegisterHealthcheck(t *testing.T) {
+	asst := assert.New(t)
+
+	gin.SetMode(gin.TestMode)
+	gr := &GinRouter{gin.New()}
+	gr.Register()
+
+	routes := make(map[string]bool)
+	for _, route := range gr.Engine.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	asst.True(routes["POST /api/v1/healthcheck/operation/cancel/:operation_id"], "test RegisterHealthcheck() failed")
+	asst.True(routes["POST /api/v1/healthcheck/operation/cancel/middleware/:middleware_operation_id"], "test RegisterHealthcheck() failed")
+
+	// the request reaches the handler, which complains about the invalid middleware operation id
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/healthcheck/operation/cancel/middleware/abc", nil)
+	gr.ServeHTTP(w, req)
+	asst.NotEqual(http.StatusNotFound, w.Code, "test RegisterHealthcheck() failed")
+	asst.Contains(w.Body.String(), "abc", "test RegisterHealthcheck() failed")
+}
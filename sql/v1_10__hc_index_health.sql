@@ -0,0 +1,8 @@
+ALTER TABLE `t_hc_result`
+  ADD COLUMN `index_health_score` int(11) NOT NULL DEFAULT '0' COMMENT '索引健康评分' AFTER `innodb_lock_advice`,
+  ADD COLUMN `index_health_data` mediumtext DEFAULT NULL COMMENT '索引健康数据' AFTER `index_health_score`,
+  ADD COLUMN `index_health_advice` mediumtext DEFAULT NULL COMMENT '索引优化建议' AFTER `index_health_data`;
+
+update t_hc_default_engine_config set item_weight = 5 where item_name = 'average_active_session_percents' and del_flag = 0;
+insert into t_hc_default_engine_config(item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high, score_deduction_per_unit_medium, max_score_deduction_medium)
+values('index_health', 5, 0, 0, 1, 10, 50, 2, 30);
@@ -0,0 +1,84 @@
+CREATE TABLE `t_hc_result_item` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `operation_id` int(11) NOT NULL COMMENT '操作ID',
+  `item_name` varchar(100) NOT NULL COMMENT '检查项名称',
+  `score` int(11) NOT NULL COMMENT '检查项评分',
+  `data` mediumtext DEFAULT NULL COMMENT '检查项数据',
+  `high` mediumtext DEFAULT NULL COMMENT '检查项高值数据',
+  `advice` mediumtext DEFAULT NULL COMMENT '检查项优化建议',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_operation_id_item_name` (`operation_id`, `item_name`),
+  KEY `idx02_item_name_score` (`item_name`, `score`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '健康检查结果明细表';
+
+insert into t_hc_result_item(operation_id, item_name, score, data, high, advice, del_flag, create_time, last_update_time)
+select operation_id, 'db_config', db_config_score, db_config_data, null, db_config_advice, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'cpu_usage', cpu_usage_score, cpu_usage_data, cpu_usage_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'io_util', io_util_score, io_util_data, io_util_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'disk_capacity_usage', disk_capacity_usage_score, disk_capacity_usage_data, disk_capacity_usage_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'connection_usage', connection_usage_score, connection_usage_data, connection_usage_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'average_active_session_percents', average_active_session_percents_score, average_active_session_percents_data, average_active_session_percents_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'cache_miss_ratio', cache_miss_ratio_score, cache_miss_ratio_data, cache_miss_ratio_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'table_rows', table_rows_score, table_rows_data, table_rows_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'table_size', table_size_score, table_size_data, table_size_high, null, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'slow_query_rows_examined', slow_query_score, slow_query_data, null, slow_query_advice, del_flag, create_time, last_update_time from t_hc_result
+union all
+select operation_id, 'replication', replication_score, replication_data, replication_high, null, del_flag, create_time, last_update_time from t_hc_result where replication_data is not null and replication_data <> ''
+union all
+select operation_id, 'innodb_lock', innodb_lock_score, innodb_lock_data, innodb_lock_high, innodb_lock_advice, del_flag, create_time, last_update_time from t_hc_result where innodb_lock_data is not null and innodb_lock_data <> ''
+union all
+select operation_id, 'index_health', index_health_score, index_health_data, null, index_health_advice, del_flag, create_time, last_update_time from t_hc_result where index_health_data is not null and index_health_data <> '';
+
+ALTER TABLE `t_hc_result`
+  DROP COLUMN `db_config_score`,
+  DROP COLUMN `db_config_data`,
+  DROP COLUMN `db_config_advice`,
+  DROP COLUMN `cpu_usage_score`,
+  DROP COLUMN `cpu_usage_data`,
+  DROP COLUMN `cpu_usage_high`,
+  DROP COLUMN `io_util_score`,
+  DROP COLUMN `io_util_data`,
+  DROP COLUMN `io_util_high`,
+  DROP COLUMN `disk_capacity_usage_score`,
+  DROP COLUMN `disk_capacity_usage_data`,
+  DROP COLUMN `disk_capacity_usage_high`,
+  DROP COLUMN `connection_usage_score`,
+  DROP COLUMN `connection_usage_data`,
+  DROP COLUMN `connection_usage_high`,
+  DROP COLUMN `average_active_session_percents_score`,
+  DROP COLUMN `average_active_session_percents_data`,
+  DROP COLUMN `average_active_session_percents_high`,
+  DROP COLUMN `cache_miss_ratio_score`,
+  DROP COLUMN `cache_miss_ratio_data`,
+  DROP COLUMN `cache_miss_ratio_high`,
+  DROP COLUMN `table_rows_score`,
+  DROP COLUMN `table_rows_data`,
+  DROP COLUMN `table_rows_high`,
+  DROP COLUMN `table_size_score`,
+  DROP COLUMN `table_size_data`,
+  DROP COLUMN `table_size_high`,
+  DROP COLUMN `slow_query_score`,
+  DROP COLUMN `slow_query_data`,
+  DROP COLUMN `slow_query_advice`,
+  DROP COLUMN `replication_score`,
+  DROP COLUMN `replication_data`,
+  DROP COLUMN `replication_high`,
+  DROP COLUMN `innodb_lock_score`,
+  DROP COLUMN `innodb_lock_data`,
+  DROP COLUMN `innodb_lock_high`,
+  DROP COLUMN `innodb_lock_advice`,
+  DROP COLUMN `index_health_score`,
+  DROP COLUMN `index_health_data`,
+  DROP COLUMN `index_health_advice`;
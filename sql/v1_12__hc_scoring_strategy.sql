@@ -0,0 +1,2 @@
+ALTER TABLE `t_hc_default_engine_config`
+  ADD COLUMN `scoring_strategy` varchar(50) NOT NULL DEFAULT 'mean' COMMENT '评分策略: mean-超过水位线样本的平均值, p95-95分位值, p99-99分位值, time_above_watermark-超过水位线的时间占比, longest_breach-最长连续超过水位线的样本数' AFTER `max_score_deduction_medium`;
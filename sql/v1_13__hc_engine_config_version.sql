@@ -0,0 +1,9 @@
+update t_hc_default_engine_config set item_name = 'io_util' where item_name = 'io_usage';
+
+ALTER TABLE `t_hc_default_engine_config`
+  ADD COLUMN `config_version` int(11) NOT NULL DEFAULT '1' COMMENT '配置版本, 每次变更生成新版本, 健康检查使用最新版本的配置' AFTER `id`,
+  DROP KEY `idx01_item_name`,
+  ADD UNIQUE KEY `idx01_config_version_item_name` (`config_version`, `item_name`);
+
+ALTER TABLE `t_hc_operation_info`
+  ADD COLUMN `engine_config_version` int(11) NOT NULL DEFAULT '0' COMMENT '评分使用的引擎配置版本, 0表示尚未加载配置' AFTER `step`;
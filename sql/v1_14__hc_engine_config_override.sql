@@ -0,0 +1,15 @@
+CREATE TABLE `t_hc_engine_config_override` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `scope_type` tinyint(4) NOT NULL COMMENT '覆盖范围类型: 1-环境, 2-应用系统等级, 3-mysql集群, 后者优先级更高',
+  `scope_id` int(11) NOT NULL COMMENT '覆盖范围ID: 环境ID, 应用系统等级(1-A, 2-B, 3-C)或mysql集群ID',
+  `item_name` varchar(100) NOT NULL COMMENT '检查项名称',
+  `config` mediumtext NOT NULL COMMENT '覆盖的检查项配置, json格式, 不能覆盖权重',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_scope_type_scope_id_item_name` (`scope_type`, `scope_id`, `item_name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '健康检查引擎配置覆盖表';
+
+ALTER TABLE `t_hc_result`
+  ADD COLUMN `engine_config` mediumtext DEFAULT NULL COMMENT '评分使用的检查项配置, 已合并覆盖配置' AFTER `weighted_average_score`;
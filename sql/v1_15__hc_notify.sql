@@ -0,0 +1,32 @@
+CREATE TABLE `t_hc_notify_rule` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `rule_name` varchar(100) NOT NULL COMMENT '规则名称',
+  `mysql_cluster_id` int(11) NOT NULL DEFAULT '0' COMMENT 'mysql集群ID, 0表示所有mysql集群',
+  `trigger_type` tinyint(4) NOT NULL COMMENT '触发类型: 1-检查失败, 2-得分低于阈值, 3-得分比上次检查下降超过阈值',
+  `threshold` int(11) NOT NULL DEFAULT '0' COMMENT '分数阈值, 触发类型为1时不使用',
+  `sink_type` tinyint(4) NOT NULL COMMENT '通知方式: 1-webhook, 2-邮件, 3-文件',
+  `target` varchar(500) NOT NULL DEFAULT '' COMMENT '通知目标: webhook地址, 邮箱地址或文件路径, 通知方式为邮件且为空时发送给mysql集群主要负责人',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_rule_name` (`rule_name`),
+  KEY `idx02_mysql_cluster_id` (`mysql_cluster_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '健康检查通知规则表';
+
+CREATE TABLE `t_hc_notify_log` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `rule_id` int(11) NOT NULL COMMENT '通知规则ID',
+  `operation_id` int(11) NOT NULL COMMENT '检查ID',
+  `sink_type` tinyint(4) NOT NULL COMMENT '通知方式: 1-webhook, 2-邮件, 3-文件',
+  `target` varchar(500) NOT NULL COMMENT '实际通知目标',
+  `status` tinyint(4) NOT NULL COMMENT '投递状态: 1-成功, 2-失败',
+  `attempts` int(11) NOT NULL COMMENT '投递次数, 包括重试',
+  `message` mediumtext DEFAULT NULL COMMENT '最后一次投递失败的日志',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx01_operation_id` (`operation_id`),
+  KEY `idx02_rule_id` (`rule_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '健康检查通知投递日志表';
@@ -0,0 +1,14 @@
+CREATE TABLE `t_hc_prometheus_catalogue_override` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `monitor_system_id` int(11) NOT NULL COMMENT '监控系统ID',
+  `name` varchar(100) NOT NULL COMMENT '标签映射或查询的名称, 如: node_label, node_value, service_label, service_value, cpu_usage',
+  `template` mediumtext NOT NULL COMMENT '覆盖的模板, 使用${.Field}作为占位符',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_monitor_system_id_name` (`monitor_system_id`, `name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '健康检查prometheus查询目录覆盖表';
+
+ALTER TABLE `t_meta_monitor_system_info`
+  MODIFY COLUMN `system_type` tinyint(4) NOT NULL COMMENT '监控系统类型: 1-pmm1.x, 2-pmm2.x, 3-prometheus';
@@ -0,0 +1,28 @@
+CREATE TABLE `t_hc_middleware_operation_info` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `middleware_cluster_id` int(11) NOT NULL COMMENT '中间件集群ID',
+  `status` tinyint(4) NOT NULL DEFAULT '0' COMMENT '运行状态: 0-未运行, 1-运行中, 2-已完成, 3-已失败, 4-已取消',
+  `message` mediumtext DEFAULT NULL COMMENT '运行日志',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx01_middleware_cluster_id_status` (`middleware_cluster_id`, `status`),
+  KEY `idx02_create_time` (`create_time`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '中间件集群健康检查操作表';
+
+CREATE TABLE `t_hc_middleware_result` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `middleware_operation_id` int(11) NOT NULL COMMENT '中间件集群操作ID',
+  `weighted_average_score` int(11) NOT NULL COMMENT '加权平均分',
+  `connectivity_score` int(11) NOT NULL COMMENT '连通性评分',
+  `backend_score` int(11) NOT NULL COMMENT '后端状态及映射评分',
+  `routing_score` int(11) NOT NULL COMMENT '路由健康评分',
+  `server_results` mediumtext DEFAULT NULL COMMENT '中间件服务器检查结果明细',
+  `missing_backends` mediumtext DEFAULT NULL COMMENT '未配置为后端的已注册mysql服务器',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_middleware_operation_id` (`middleware_operation_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '中间件集群健康检查结果表';
@@ -0,0 +1,15 @@
+CREATE TABLE `t_cap_table_snapshot` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `mysql_server_id` int(11) NOT NULL COMMENT 'mysql服务器ID',
+  `db_name` varchar(100) NOT NULL COMMENT '数据库名称',
+  `table_name` varchar(100) NOT NULL COMMENT '表名称',
+  `table_rows` bigint(20) NOT NULL DEFAULT '0' COMMENT '估算行数',
+  `data_length` bigint(20) NOT NULL DEFAULT '0' COMMENT '数据大小(字节)',
+  `index_length` bigint(20) NOT NULL DEFAULT '0' COMMENT '索引大小(字节)',
+  `snapshot_time` datetime(6) NOT NULL COMMENT '快照时间',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx01_mysql_server_id_snapshot_time` (`mysql_server_id`, `snapshot_time`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '表容量快照表';
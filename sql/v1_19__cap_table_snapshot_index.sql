@@ -0,0 +1,3 @@
+ALTER TABLE `t_cap_table_snapshot`
+  ADD KEY `idx02_mysql_server_id_db_name_snapshot_time` (`mysql_server_id`, `db_name`, `snapshot_time`),
+  ADD KEY `idx03_snapshot_time` (`snapshot_time`);
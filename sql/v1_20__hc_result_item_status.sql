@@ -0,0 +1,3 @@
+ALTER TABLE `t_hc_result_item`
+  ADD COLUMN `status` varchar(20) NOT NULL DEFAULT 'available' COMMENT '检查项状态: available-可用, unavailable-不可用' AFTER `advice`,
+  ADD COLUMN `error` mediumtext DEFAULT NULL COMMENT '检查项不可用的错误信息' AFTER `status`;
@@ -0,0 +1,33 @@
+CREATE TABLE `t_hc_cluster_operation_info` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `mysql_cluster_id` int(11) NOT NULL COMMENT 'mysql集群ID',
+  `start_time` datetime(6) NOT NULL COMMENT '检查范围开始时间',
+  `end_time` datetime(6) NOT NULL COMMENT '检查范围结束时间',
+  `step` int(11) NOT NULL COMMENT '采样间隔, 单位: 秒',
+  `status` tinyint(4) NOT NULL DEFAULT '0' COMMENT '运行状态: 0-未运行, 1-运行中, 2-已完成, 3-已失败',
+  `message` mediumtext DEFAULT NULL COMMENT '运行日志',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx01_mysql_cluster_id_status` (`mysql_cluster_id`, `status`),
+  KEY `idx02_start_time` (`start_time`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '集群健康检查操作表';
+
+CREATE TABLE `t_hc_cluster_result` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `cluster_operation_id` int(11) NOT NULL COMMENT '集群操作ID',
+  `worst_score` int(11) NOT NULL COMMENT '集群成员中的最低加权平均分',
+  `worst_mysql_server_id` int(11) NOT NULL COMMENT '最低加权平均分的mysql服务器ID',
+  `server_results` mediumtext DEFAULT NULL COMMENT '集群成员检查结果明细',
+  `config_drift` mediumtext DEFAULT NULL COMMENT '集群成员间数据库参数配置差异',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_cluster_operation_id` (`cluster_operation_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '集群健康检查结果表';
+
+ALTER TABLE `t_hc_operation_info`
+  ADD COLUMN `cluster_operation_id` int(11) NOT NULL DEFAULT '0' COMMENT '集群操作ID, 0表示单实例检查' AFTER `id`,
+  ADD KEY `idx03_cluster_operation_id` (`cluster_operation_id`);
@@ -0,0 +1,20 @@
+CREATE TABLE `t_hc_schedule_info` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `schedule_name` varchar(100) NOT NULL COMMENT '计划名称',
+  `target_type` tinyint(4) NOT NULL COMMENT '检查目标类型: 1-mysql服务器, 2-mysql集群, 3-环境, 4-应用系统',
+  `target_id` int(11) NOT NULL COMMENT '检查目标ID',
+  `cron_expr` varchar(100) NOT NULL COMMENT 'cron表达式, 格式: 分 时 日 月 周',
+  `window_seconds` int(11) NOT NULL COMMENT '检查范围时长, 单位: 秒, 检查范围结束时间为计划触发时间',
+  `step` int(11) NOT NULL COMMENT '采样间隔, 单位: 秒',
+  `status` tinyint(4) NOT NULL DEFAULT '1' COMMENT '计划状态: 1-启用, 2-暂停',
+  `last_run_time` datetime(6) NOT NULL DEFAULT '1970-01-01 08:00:01.000000' COMMENT '上次触发时间, 1970-01-01 08:00:01表示从未触发',
+  `next_run_time` datetime(6) NOT NULL COMMENT '下次触发时间',
+  `message` mediumtext DEFAULT NULL COMMENT '上次触发日志',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx01_schedule_name` (`schedule_name`),
+  KEY `idx02_status_next_run_time` (`status`, `next_run_time`),
+  KEY `idx03_target_type_target_id` (`target_type`, `target_id`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '健康检查计划表';
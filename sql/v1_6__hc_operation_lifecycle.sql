@@ -0,0 +1,3 @@
+ALTER TABLE `t_hc_operation_info`
+  MODIFY COLUMN `status` tinyint(4) NOT NULL DEFAULT '0' COMMENT '运行状态: 0-未运行, 1-运行中, 2-已完成, 3-已失败, 4-已取消',
+  ADD KEY `idx04_create_time` (`create_time`);
@@ -0,0 +1,57 @@
+CREATE TABLE `t_hc_db_config_rule` (
+  `id` int(11) NOT NULL AUTO_INCREMENT COMMENT '主键ID',
+  `rule_set_version` int(11) NOT NULL COMMENT '规则集版本, 健康检查使用最新版本的规则集',
+  `variable_name` varchar(100) NOT NULL COMMENT '参数名称',
+  `operator` varchar(20) NOT NULL COMMENT '比较运算符: eq-等于(忽略大小写), gte-大于等于(数值), in-属于(逗号分隔, 忽略大小写), regex-正则匹配(完整匹配)',
+  `expected_value` varchar(500) NOT NULL DEFAULT '' COMMENT '期望值, ${host_ip}和${port_num}会被替换为被检查的mysql服务器地址',
+  `min_mysql_version` varchar(20) NOT NULL DEFAULT '' COMMENT '适用的最低mysql版本(包含), 空表示不限制',
+  `max_mysql_version` varchar(20) NOT NULL DEFAULT '' COMMENT '适用的最高mysql版本(不包含), 空表示不限制',
+  `env_id` int(11) NOT NULL DEFAULT '0' COMMENT '适用的环境ID, 0表示所有环境',
+  `role` tinyint(4) NOT NULL DEFAULT '0' COMMENT '适用的角色: 0-所有, 1-主库, 2-从库',
+  `severity` tinyint(4) NOT NULL DEFAULT '3' COMMENT '严重程度: 1-低(仅建议, 不扣分), 2-中, 3-高',
+  `advice` varchar(500) NOT NULL DEFAULT '' COMMENT '建议',
+  `del_flag` tinyint(4) NOT NULL DEFAULT '0' COMMENT '删除标记: 0-未删除, 1-已删除',
+  `create_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) COMMENT '创建时间',
+  `last_update_time` datetime(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6) COMMENT '最后更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx01_rule_set_version_variable_name` (`rule_set_version`, `variable_name`)
+) ENGINE = InnoDB DEFAULT CHARSET = utf8mb4 COMMENT = '健康检查数据库参数规则表';
+
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'log_bin', 'eq', 'ON', '', '', 0, 3, 'binlog should be enabled');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'binlog_format', 'eq', 'ROW', '', '', 0, 3, 'binlog_format should be ROW');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'binlog_row_image', 'eq', 'FULL', '', '', 0, 2, 'binlog_row_image should be FULL');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'sync_binlog', 'eq', '1', '', '', 0, 3, 'sync_binlog should be 1');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'innodb_flush_log_at_trx_commit', 'eq', '1', '', '', 0, 3, 'innodb_flush_log_at_trx_commit should be 1');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'gtid_mode', 'eq', 'ON', '', '', 0, 3, 'gtid_mode should be ON');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'enforce_gtid_consistency', 'eq', 'ON', '', '', 0, 3, 'enforce_gtid_consistency should be ON');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'slave_parallel_type', 'eq', 'LOGICAL_CLOCK', '', '', 2, 2, 'slave_parallel_type should be LOGICAL_CLOCK on the replica');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'slave_parallel_workers', 'gte', '16', '', '', 2, 2, 'slave_parallel_workers should be at least 16 on the replica');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'master_info_repository', 'eq', 'TABLE', '', '8.0.23', 0, 2, 'master_info_repository should be TABLE');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'relay_log_info_repository', 'eq', 'TABLE', '', '8.0.23', 0, 2, 'relay_log_info_repository should be TABLE');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'report_host', 'eq', '${host_ip}', '', '', 0, 2, 'report_host should be the ip of the mysql server');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'report_port', 'eq', '${port_num}', '', '', 0, 2, 'report_port should be the port of the mysql server');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'innodb_flush_method', 'eq', 'O_DIRECT', '', '', 0, 2, 'innodb_flush_method should be O_DIRECT');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'innodb_monitor_enable', 'eq', 'all', '', '', 0, 1, 'innodb_monitor_enable should be all');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'innodb_print_all_deadlocks', 'eq', 'ON', '', '', 0, 2, 'innodb_print_all_deadlocks should be ON');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'slow_query_log', 'eq', 'ON', '', '', 0, 3, 'slow_query_log should be ON');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'performance_schema', 'eq', 'ON', '', '', 0, 3, 'performance_schema should be ON');
+insert into t_hc_db_config_rule(rule_set_version, variable_name, operator, expected_value, min_mysql_version, max_mysql_version, role, severity, advice)
+values(1, 'max_connections', 'gte', '2000', '', '', 0, 2, 'max_connections should be at least 2000');
@@ -0,0 +1,9 @@
+ALTER TABLE `t_hc_result`
+  ADD COLUMN `replication_score` int(11) NOT NULL DEFAULT '0' COMMENT '复制状态评分' AFTER `slow_query_advice`,
+  ADD COLUMN `replication_data` mediumtext DEFAULT NULL COMMENT '复制状态数据' AFTER `replication_score`,
+  ADD COLUMN `replication_high` mediumtext DEFAULT NULL COMMENT '高复制延迟数据' AFTER `replication_data`;
+
+update t_hc_default_engine_config set item_weight = 15 where item_name = 'disk_capacity_usage' and del_flag = 0;
+update t_hc_default_engine_config set item_weight = 15 where item_name = 'connection_usage' and del_flag = 0;
+insert into t_hc_default_engine_config(item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high, score_deduction_per_unit_medium, max_score_deduction_medium)
+values('replication', 10, 60, 300, 60, 20, 100, 10, 50);
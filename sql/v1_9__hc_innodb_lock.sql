@@ -0,0 +1,9 @@
+ALTER TABLE `t_hc_result`
+  ADD COLUMN `innodb_lock_score` int(11) NOT NULL DEFAULT '0' COMMENT 'innodb锁评分' AFTER `replication_high`,
+  ADD COLUMN `innodb_lock_data` mediumtext DEFAULT NULL COMMENT 'innodb锁数据' AFTER `innodb_lock_score`,
+  ADD COLUMN `innodb_lock_high` mediumtext DEFAULT NULL COMMENT '高行锁等待数据' AFTER `innodb_lock_data`,
+  ADD COLUMN `innodb_lock_advice` mediumtext DEFAULT NULL COMMENT '阻塞语句及死锁' AFTER `innodb_lock_high`;
+
+update t_hc_default_engine_config set item_weight = 15 where item_name = 'slow_query_rows_examined' and del_flag = 0;
+insert into t_hc_default_engine_config(item_name, item_weight, low_watermark, high_watermark, unit, score_deduction_per_unit_high, max_score_deduction_high, score_deduction_per_unit_medium, max_score_deduction_medium)
+values('innodb_lock', 5, 1, 5, 1, 20, 100, 10, 50);
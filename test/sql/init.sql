@@ -1,6 +1,8 @@
 truncate table t_meta_monitor_system_info;
 truncate table t_meta_mysql_cluster_info;
 truncate table t_meta_mysql_server_info;
+truncate table t_meta_db_info;
+truncate table t_hc_operation_info;
 
 insert into t_meta_monitor_system_info(id, system_name, system_type, host_ip, port_num, port_num_slow, base_url, env_id)
 values(1, 'pmm-2', 2, '192.168.10.219', 80, 8123, '/prometheus', 6), (2, 'pmm-1', 1, '192.168.10.220', 9090, 33061, '/prometheus', 6);
@@ -11,3 +13,7 @@ values(1, 1, '192-168-10-219-3306', '192-168-10-219:3306', '192.168.10.219', 330
       (2, 1, '192-168-10-219-3307', '192-168-10-219:3307', '192.168.10.219', 3307, 2, '5.7'),
       (3, 2, '192-168-10-220-3306', '192-168-10-220:3306', '192.168.10.220', 3306, 2, '5.7'),
        (4, 2, '192-168-10-220-3307', '192-168-10-220:3307', '192.168.10.220', 3307, 2, '5.7');
+insert into t_meta_db_info(id, db_name, cluster_id, cluster_type, env_id)
+values(1, 'db1', 2, 1, 6), (2, 'db2', 1, 1, 6);
+insert into t_hc_operation_info(id, mysql_server_id, start_time, end_time, step, status)
+values(1, 1, '2021-05-21 10:00:00', '2021-05-21 11:00:00', 60, 2);